@@ -20,7 +20,9 @@ import (
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/buildinfo"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/cmd"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/i18n"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/loginui"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/managementasset"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/store"
@@ -75,15 +77,35 @@ func main() {
 	var iflowCookie bool
 	var noBrowser bool
 	var oauthCallbackPort int
+	var loginOutput string
 	var antigravityLogin bool
 	var kiroLogin bool
 	var kiroGoogleLogin bool
+	var kiroGitHubLogin bool
+	var kiroCognitoLogin bool
 	var kiroAWSLogin bool
 	var kiroAWSAuthCode bool
+	var kiroIDCLogin bool
+	var kiroStartURL string
+	var kiroRegion string
+	var kiroInvitationCode string
+	var kiroRemoteLogin string
+	var kiroHandler string
 	var kiroImport bool
+	var kiroImportDir string
 	var githubCopilotLogin bool
+	var githubBaseURL string
+	var githubToken string
+	var githubOrg string
+	var githubCopilotLogout string
 	var projectID string
 	var vertexImport string
+	var importFrom string
+	var importPath string
+	var exportCredential string
+	var exportFormat string
+	var exportPath string
+	var resumeLogin bool
 	var configPath string
 	var password string
 	var noIncognito bool
@@ -100,16 +122,36 @@ func main() {
 	flag.BoolVar(&useIncognito, "incognito", false, "Open browser in incognito/private mode for OAuth (useful for multiple accounts)")
 	flag.BoolVar(&noIncognito, "no-incognito", false, "Force disable incognito mode (uses existing browser session)")
 	flag.IntVar(&oauthCallbackPort, "oauth-callback-port", 0, "Override OAuth callback port (defaults to provider-specific port)")
+	flag.StringVar(&loginOutput, "login-output", "text", "Progress output of Codex, GitHub Copilot and Kiro logins: text, quiet or json (one event per line)")
 	flag.BoolVar(&antigravityLogin, "antigravity-login", false, "Login to Antigravity using OAuth")
 	flag.BoolVar(&kiroLogin, "kiro-login", false, "Login to Kiro using Google OAuth")
 	flag.BoolVar(&kiroGoogleLogin, "kiro-google-login", false, "Login to Kiro using Google OAuth (same as --kiro-login)")
+	flag.BoolVar(&kiroGitHubLogin, "kiro-github-login", false, "Login to Kiro using GitHub OAuth")
+	flag.BoolVar(&kiroCognitoLogin, "kiro-cognito-login", false, "Login to Kiro using a Kiro email/password account (Cognito)")
 	flag.BoolVar(&kiroAWSLogin, "kiro-aws-login", false, "Login to Kiro using AWS Builder ID (device code flow)")
 	flag.BoolVar(&kiroAWSAuthCode, "kiro-aws-authcode", false, "Login to Kiro using AWS Builder ID (authorization code flow, better UX)")
+	flag.BoolVar(&kiroIDCLogin, "kiro-idc-login", false, "Login to Kiro using AWS IAM Identity Center (organization SSO, device code flow)")
+	flag.StringVar(&kiroStartURL, "kiro-start-url", "", "IAM Identity Center start URL for --kiro-idc-login")
+	flag.StringVar(&kiroRegion, "kiro-region", "", "IAM Identity Center region for --kiro-idc-login (default: us-east-1)")
+	flag.StringVar(&kiroRemoteLogin, "kiro-remote-login", "", "Login to Kiro from another device's browser via a terminal QR code: google or github")
+	flag.StringVar(&kiroHandler, "kiro-handler", "", "Manage the kiro:// protocol handler used by Kiro Google/GitHub login: install, uninstall, status or repair")
+	flag.StringVar(&kiroInvitationCode, "kiro-invitation-code", "", "Invitation code for waitlisted accounts on Kiro Google/GitHub/Cognito login")
 	flag.BoolVar(&kiroImport, "kiro-import", false, "Import Kiro token from Kiro IDE (~/.aws/sso/cache/kiro-auth-token.json)")
+	flag.StringVar(&kiroImportDir, "kiro-import-dir", "", "Import every Kiro token JSON in a directory, deduplicated by account")
 	flag.BoolVar(&githubCopilotLogin, "github-copilot-login", false, "Login to GitHub Copilot using device flow")
+	flag.StringVar(&githubBaseURL, "github-base-url", "", "GitHub Enterprise Server URL for --github-copilot-login (default: https://github.com)")
+	flag.StringVar(&githubToken, "github-token", "", "Pre-provisioned GitHub token for --github-copilot-login instead of the device flow (or set GITHUB_COPILOT_TOKEN)")
+	flag.StringVar(&githubOrg, "github-org", "", "Organization whose Copilot seat --github-copilot-login uses, skipping the selection prompt")
+	flag.StringVar(&githubCopilotLogout, "github-copilot-logout", "", "Revoke the GitHub token of the GitHub Copilot login in this auth file and delete the file")
 	flag.StringVar(&projectID, "project_id", "", "Project ID (Gemini only, not required)")
 	flag.StringVar(&configPath, "config", DefaultConfigPath, "Configure File Path")
 	flag.StringVar(&vertexImport, "vertex-import", "", "Import Vertex service account key JSON file")
+	flag.BoolVar(&resumeLogin, "resume", false, "Resume an interrupted Claude or Codex login from its pasted callback URL")
+	flag.StringVar(&importFrom, "import-from", "", "Import credentials from another tool: "+strings.Join(sdkAuth.ImportSources(), ", "))
+	flag.StringVar(&importPath, "import-path", "", "Credentials file for --import-from (default: the tool's own location)")
+	flag.StringVar(&exportCredential, "export-credential", "", "Export the stored credential with this ID in a native tool format (see --export-format)")
+	flag.StringVar(&exportFormat, "export-format", "", "Format for --export-credential: "+strings.Join(sdkAuth.ExportFormats(), ", "))
+	flag.StringVar(&exportPath, "export-path", "", "Target file for --export-credential (default: the tool's own location)")
 	flag.StringVar(&password, "password", "", "")
 
 	flag.CommandLine.Usage = func() {
@@ -137,11 +179,19 @@ func main() {
 			}
 			_, _ = fmt.Fprint(out, s+"\n")
 		})
+		_, _ = fmt.Fprint(out, "\n"+cmd.ExitCodeHelp)
 	}
 
 	// Parse the command-line flags.
 	flag.Parse()
 
+	loginMode, errLoginOutput := loginui.ParseMode(loginOutput)
+	if errLoginOutput != nil {
+		log.Errorf("invalid --login-output: %v", errLoginOutput)
+		os.Exit(cmd.ExitConfig)
+	}
+	loginui.SetMode(loginMode)
+
 	// Core application variables.
 	var err error
 	var cfg *config.Config
@@ -171,7 +221,7 @@ func main() {
 	wd, err := os.Getwd()
 	if err != nil {
 		log.Errorf("failed to get working directory: %v", err)
-		return
+		os.Exit(cmd.ExitFailure)
 	}
 
 	// Load environment variables from .env if present.
@@ -266,14 +316,14 @@ func main() {
 		cancel()
 		if err != nil {
 			log.Errorf("failed to initialize postgres token store: %v", err)
-			return
+			os.Exit(cmd.ExitCodeFor(err, cmd.ExitConfig))
 		}
 		examplePath := filepath.Join(wd, "config.example.yaml")
 		ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
 		if errBootstrap := pgStoreInst.Bootstrap(ctx, examplePath); errBootstrap != nil {
 			cancel()
 			log.Errorf("failed to bootstrap postgres-backed config: %v", errBootstrap)
-			return
+			os.Exit(cmd.ExitCodeFor(errBootstrap, cmd.ExitConfig))
 		}
 		cancel()
 		configFilePath = pgStoreInst.ConfigPath()
@@ -297,7 +347,7 @@ func main() {
 			parsed, errParse := url.Parse(resolvedEndpoint)
 			if errParse != nil {
 				log.Errorf("failed to parse object store endpoint %q: %v", objectStoreEndpoint, errParse)
-				return
+				os.Exit(cmd.ExitConfig)
 			}
 			switch strings.ToLower(parsed.Scheme) {
 			case "http":
@@ -306,11 +356,11 @@ func main() {
 				useSSL = true
 			default:
 				log.Errorf("unsupported object store scheme %q (only http and https are allowed)", parsed.Scheme)
-				return
+				os.Exit(cmd.ExitConfig)
 			}
 			if parsed.Host == "" {
 				log.Errorf("object store endpoint %q is missing host information", objectStoreEndpoint)
-				return
+				os.Exit(cmd.ExitConfig)
 			}
 			resolvedEndpoint = parsed.Host
 			if parsed.Path != "" && parsed.Path != "/" {
@@ -330,14 +380,14 @@ func main() {
 		objectStoreInst, err = store.NewObjectTokenStore(objCfg)
 		if err != nil {
 			log.Errorf("failed to initialize object token store: %v", err)
-			return
+			os.Exit(cmd.ExitCodeFor(err, cmd.ExitConfig))
 		}
 		examplePath := filepath.Join(wd, "config.example.yaml")
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		if errBootstrap := objectStoreInst.Bootstrap(ctx, examplePath); errBootstrap != nil {
 			cancel()
 			log.Errorf("failed to bootstrap object-backed config: %v", errBootstrap)
-			return
+			os.Exit(cmd.ExitCodeFor(errBootstrap, cmd.ExitConfig))
 		}
 		cancel()
 		configFilePath = objectStoreInst.ConfigPath()
@@ -363,7 +413,7 @@ func main() {
 		gitStoreInst.SetBaseDir(authDir)
 		if errRepo := gitStoreInst.EnsureRepository(); errRepo != nil {
 			log.Errorf("failed to prepare git token store: %v", errRepo)
-			return
+			os.Exit(cmd.ExitCodeFor(errRepo, cmd.ExitConfig))
 		}
 		configFilePath = gitStoreInst.ConfigPath()
 		if configFilePath == "" {
@@ -373,20 +423,20 @@ func main() {
 			examplePath := filepath.Join(wd, "config.example.yaml")
 			if _, errExample := os.Stat(examplePath); errExample != nil {
 				log.Errorf("failed to find template config file: %v", errExample)
-				return
+				os.Exit(cmd.ExitConfig)
 			}
 			if errCopy := misc.CopyConfigTemplate(examplePath, configFilePath); errCopy != nil {
 				log.Errorf("failed to bootstrap git-backed config: %v", errCopy)
-				return
+				os.Exit(cmd.ExitFailure)
 			}
 			if errCommit := gitStoreInst.PersistConfig(context.Background()); errCommit != nil {
 				log.Errorf("failed to commit initial git-backed config: %v", errCommit)
-				return
+				os.Exit(cmd.ExitCodeFor(errCommit, cmd.ExitFailure))
 			}
 			log.Infof("git-backed config initialized from template: %s", configFilePath)
 		} else if statErr != nil {
 			log.Errorf("failed to inspect git-backed config: %v", statErr)
-			return
+			os.Exit(cmd.ExitFailure)
 		}
 		cfg, err = config.LoadConfigOptional(configFilePath, isCloudDeploy)
 		if err == nil {
@@ -400,14 +450,14 @@ func main() {
 		wd, err = os.Getwd()
 		if err != nil {
 			log.Errorf("failed to get working directory: %v", err)
-			return
+			os.Exit(cmd.ExitFailure)
 		}
 		configFilePath = filepath.Join(wd, "config.yaml")
 		cfg, err = config.LoadConfigOptional(configFilePath, isCloudDeploy)
 	}
 	if err != nil {
 		log.Errorf("failed to load config: %v", err)
-		return
+		os.Exit(cmd.ExitConfig)
 	}
 	if cfg == nil {
 		cfg = &config.Config{}
@@ -433,12 +483,16 @@ func main() {
 			configFileExists = true
 		}
 	}
+	// Command output uses the configured language, or the terminal locale when none is set; the
+	// server switches to the configured language alone when it starts.
+	i18n.SetDefault(i18n.ParseOr(cfg.Language, i18n.FromEnvironment(i18n.English)))
 	usage.SetStatisticsEnabled(cfg.UsageStatisticsEnabled)
 	coreauth.SetQuotaCooldownDisabled(cfg.DisableCooling)
+	coreauth.SetQuotaHeadroomThreshold(cfg.Routing.QuotaThreshold)
 
 	if err = logging.ConfigureLogOutput(cfg); err != nil {
 		log.Errorf("failed to configure log output: %v", err)
-		return
+		os.Exit(cmd.ExitConfig)
 	}
 
 	log.Infof("CLIProxyAPI Version: %s, Commit: %s, BuiltAt: %s", buildinfo.Version, buildinfo.Commit, buildinfo.BuildDate)
@@ -448,7 +502,7 @@ func main() {
 
 	if resolvedAuthDir, errResolveAuthDir := util.ResolveAuthDir(cfg.AuthDir); errResolveAuthDir != nil {
 		log.Errorf("failed to resolve auth directory: %v", errResolveAuthDir)
-		return
+		os.Exit(cmd.ExitConfig)
 	} else {
 		cfg.AuthDir = resolvedAuthDir
 	}
@@ -456,8 +510,12 @@ func main() {
 
 	// Create login options to be used in authentication flows.
 	options := &cmd.LoginOptions{
-		NoBrowser:    noBrowser,
-		CallbackPort: oauthCallbackPort,
+		NoBrowser:          noBrowser,
+		CallbackPort:       oauthCallbackPort,
+		KiroInvitationCode: kiroInvitationCode,
+		GitHubBaseURL:      githubBaseURL,
+		GitHubToken:        githubToken,
+		GitHubOrg:          githubOrg,
 	}
 
 	// Register the shared token store once so all components use the same persistence backend.
@@ -479,6 +537,14 @@ func main() {
 	if vertexImport != "" {
 		// Handle Vertex service account import
 		cmd.DoVertexImport(cfg, vertexImport)
+	} else if resumeLogin {
+		cmd.DoResumeLogin(cfg, options)
+	} else if exportCredential != "" {
+		// Handle writing a stored credential back for its native tool
+		cmd.DoExportCredential(cfg, exportCredential, exportFormat, exportPath)
+	} else if importFrom != "" {
+		// Handle credentials stored by other tools
+		cmd.DoImportCredentials(cfg, importFrom, importPath, projectID)
 	} else if login {
 		// Handle Google/Gemini login
 		cmd.DoLogin(cfg, projectID, options)
@@ -488,6 +554,9 @@ func main() {
 	} else if githubCopilotLogin {
 		// Handle GitHub Copilot login
 		cmd.DoGitHubCopilotLogin(cfg, options)
+	} else if githubCopilotLogout != "" {
+		// Handle revoking and removing a GitHub Copilot login
+		cmd.DoGitHubCopilotLogout(cfg, githubCopilotLogout)
 	} else if codexLogin {
 		// Handle Codex login
 		cmd.DoCodexLogin(cfg, options)
@@ -513,17 +582,36 @@ func main() {
 		// Note: This config mutation is safe - auth commands exit after completion
 		setKiroIncognitoMode(cfg, useIncognito, noIncognito)
 		cmd.DoKiroGoogleLogin(cfg, options)
+	} else if kiroGitHubLogin {
+		// For Kiro auth, default to incognito mode for multi-account support
+		// Users can explicitly override with --no-incognito
+		setKiroIncognitoMode(cfg, useIncognito, noIncognito)
+		cmd.DoKiroGitHubLogin(cfg, options)
+	} else if kiroCognitoLogin {
+		// For Kiro auth, default to incognito mode for multi-account support
+		// Users can explicitly override with --no-incognito
+		setKiroIncognitoMode(cfg, useIncognito, noIncognito)
+		cmd.DoKiroCognitoLogin(cfg, options)
 	} else if kiroAWSLogin {
 		// For Kiro auth, default to incognito mode for multi-account support
 		// Users can explicitly override with --no-incognito
 		setKiroIncognitoMode(cfg, useIncognito, noIncognito)
 		cmd.DoKiroAWSLogin(cfg, options)
+	} else if kiroHandler != "" {
+		cmd.DoKiroProtocolHandler(cfg, kiroHandler)
+	} else if kiroRemoteLogin != "" {
+		cmd.DoKiroRemoteLogin(cfg, options, kiroRemoteLogin)
+	} else if kiroIDCLogin || kiroStartURL != "" {
+		setKiroIncognitoMode(cfg, useIncognito, noIncognito)
+		cmd.DoKiroIDCLogin(cfg, options, kiroStartURL, kiroRegion)
 	} else if kiroAWSAuthCode {
 		// For Kiro auth with authorization code flow (better UX)
 		setKiroIncognitoMode(cfg, useIncognito, noIncognito)
 		cmd.DoKiroAWSAuthCodeLogin(cfg, options)
 	} else if kiroImport {
 		cmd.DoKiroImport(cfg, options)
+	} else if kiroImportDir != "" {
+		cmd.DoKiroImportDir(cfg, kiroImportDir)
 	} else {
 		// In cloud deploy mode without config file, just wait for shutdown signals
 		if isCloudDeploy && !configFileExists {
@@ -535,4 +623,5 @@ func main() {
 		managementasset.StartAutoUpdater(context.Background(), configFilePath)
 		cmd.StartService(cfg, configFilePath, password)
 	}
+	os.Exit(cmd.ExitCode())
 }
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"sync"
 
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
 	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
 )
@@ -21,7 +22,7 @@ func Register() {
 
 type provider struct {
 	name string
-	keys map[string]struct{}
+	keys []string
 }
 
 func newProvider(cfg *sdkconfig.AccessProvider, _ *sdkconfig.SDKConfig) (sdkaccess.Provider, error) {
@@ -29,12 +30,12 @@ func newProvider(cfg *sdkconfig.AccessProvider, _ *sdkconfig.SDKConfig) (sdkacce
 	if name == "" {
 		name = sdkconfig.DefaultAccessProviderName
 	}
-	keys := make(map[string]struct{}, len(cfg.APIKeys))
+	keys := make([]string, 0, len(cfg.APIKeys))
 	for _, key := range cfg.APIKeys {
 		if key == "" {
 			continue
 		}
-		keys[key] = struct{}{}
+		keys = append(keys, key)
 	}
 	return &provider{name: name, keys: keys}, nil
 }
@@ -83,7 +84,7 @@ func (p *provider) Authenticate(_ context.Context, r *http.Request) (*sdkaccess.
 		if candidate.value == "" {
 			continue
 		}
-		if _, ok := p.keys[candidate.value]; ok {
+		if p.matches(candidate.value) {
 			return &sdkaccess.Result{
 				Provider:  p.Identifier(),
 				Principal: candidate.value,
@@ -97,6 +98,17 @@ func (p *provider) Authenticate(_ context.Context, r *http.Request) (*sdkaccess.
 	return nil, sdkaccess.ErrInvalidCredential
 }
 
+// matches checks value against every configured key, plaintext or hashed, in constant time.
+func (p *provider) matches(value string) bool {
+	matched := false
+	for _, key := range p.keys {
+		if internalconfig.VerifyAPIKey(key, value) {
+			matched = true
+		}
+	}
+	return matched
+}
+
 func extractBearerToken(header string) string {
 	if header == "" {
 		return ""
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers/gemini"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// setupGRPCServer prepares the gRPC ingress for Gemini GenerateContent when enabled.
+// Calls share the data plane's API key authentication and request pipeline.
+func (s *Server) setupGRPCServer() {
+	if s.cfg == nil || !s.cfg.GRPC.Enable || s.cfg.GRPC.Port <= 0 {
+		return
+	}
+	engine := gin.New()
+	engine.Use(logging.GinLogrusLogger(), logging.GinLogrusRecovery())
+	geminiHandlers := gemini.NewGeminiAPIHandler(s.handlers)
+	authMiddleware := AuthMiddleware(s.accessManager)
+	for _, service := range gemini.GRPCServiceNames {
+		engine.POST("/"+service+"/:method", authMiddleware, geminiHandlers.GRPCHandler)
+	}
+
+	handler := http.Handler(engine)
+	if !s.cfg.TLS.Enable {
+		// gRPC clients speak HTTP/2 with prior knowledge on cleartext connections.
+		handler = h2c.NewHandler(engine, &http2.Server{})
+	}
+	s.grpcServer = &http.Server{
+		Addr:        util.JoinHostPort(s.cfg.Host, s.cfg.GRPC.Port),
+		Handler:     handler,
+		ConnContext: s.dataPlaneConnContext,
+	}
+}
+
+// startGRPCListener binds the gRPC port and serves it in the background.
+func (s *Server) startGRPCListener() error {
+	if s.grpcServer == nil {
+		return nil
+	}
+	ln, err := s.listenTCP(s.grpcServer.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to start gRPC server: %w", err)
+	}
+	if s.cfg.TLS.Enable {
+		certificate, errCert := tls.LoadX509KeyPair(strings.TrimSpace(s.cfg.TLS.Cert), strings.TrimSpace(s.cfg.TLS.Key))
+		if errCert != nil {
+			_ = ln.Close()
+			return fmt.Errorf("failed to start gRPC server: %v", errCert)
+		}
+		ln = tls.NewListener(ln, &tls.Config{Certificates: []tls.Certificate{certificate}, NextProtos: []string{http2.NextProtoTLS}})
+	}
+	log.Infof("gRPC ingress listening on %s", s.grpcServer.Addr)
+	go func() {
+		if errServe := s.grpcServer.Serve(ln); errServe != nil && !errors.Is(errServe, http.ErrServerClosed) {
+			log.Errorf("gRPC server stopped: %v", errServe)
+		}
+	}()
+	return nil
+}
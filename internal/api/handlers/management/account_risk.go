@@ -0,0 +1,17 @@
+package management
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAccountRisk returns the risk score of each recently used credential and the pacing delay
+// applied to it.
+func (h *Handler) GetAccountRisk(c *gin.Context) {
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+	c.JSON(http.StatusOK, h.authManager.AccountRiskReport())
+}
@@ -0,0 +1,14 @@
+package management
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/archive"
+)
+
+// GetArchiveStats returns the counters of the conversation archive: records waiting for upload,
+// uploaded, dropped because the queue was full, and dropped after failed uploads.
+func (h *Handler) GetArchiveStats(c *gin.Context) {
+	c.JSON(http.StatusOK, archive.Default().Stats())
+}
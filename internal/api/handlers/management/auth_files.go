@@ -10,7 +10,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -24,6 +23,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/claude"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/codex"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/copilot"
 	geminiAuth "github.com/router-for-me/CLIProxyAPI/v6/internal/auth/gemini"
 	iflowauth "github.com/router-for-me/CLIProxyAPI/v6/internal/auth/iflow"
 	kiroauth "github.com/router-for-me/CLIProxyAPI/v6/internal/auth/kiro"
@@ -144,10 +144,9 @@ func startCallbackForwarder(port int, provider, targetBase string) (*callbackFor
 		stopForwarderInstance(port, prev)
 	}
 
-	addr := fmt.Sprintf("127.0.0.1:%d", port)
-	ln, err := net.Listen("tcp", addr)
+	ln, err := util.ListenLoopback(port)
 	if err != nil {
-		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+		return nil, fmt.Errorf("failed to listen on loopback port %d: %w", port, err)
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -187,7 +186,7 @@ func startCallbackForwarder(port int, provider, targetBase string) (*callbackFor
 	callbackForwarders[port] = forwarder
 	callbackForwardersMu.Unlock()
 
-	log.Infof("callback forwarder for %s listening on %s", provider, addr)
+	log.Infof("callback forwarder for %s listening on localhost:%d", provider, port)
 
 	return forwarder, nil
 }
@@ -255,7 +254,7 @@ func (h *Handler) managementCallbackURL(path string) (string, error) {
 	if h.cfg.TLS.Enable {
 		scheme = "https"
 	}
-	return fmt.Sprintf("%s://127.0.0.1:%d%s", scheme, h.cfg.Port, path), nil
+	return scheme + "://" + util.JoinHostPort(util.LoopbackHost(h.cfg.Host, h.cfg.ListenFamily), h.cfg.Port) + path, nil
 }
 
 func (h *Handler) ListAuthFiles(c *gin.Context) {
@@ -299,10 +298,46 @@ func (h *Handler) GetAuthFileSessionBindings(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"bindings": stickySelector.SessionBindingStatuses()})
 }
 
+// GetAuthFilesNeedingReauth lists the credentials quarantined because their refresh can no longer
+// succeed; each needs the user to log in again.
+func (h *Handler) GetAuthFilesNeedingReauth(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusOK, gin.H{"files": []gin.H{}})
+		return
+	}
+	files := make([]gin.H, 0)
+	for _, auth := range h.authManager.List() {
+		if !auth.NeedsReauth() {
+			continue
+		}
+		entry := gin.H{
+			"id":           auth.ID,
+			"name":         auth.FileName,
+			"provider":     strings.TrimSpace(auth.Provider),
+			"label":        auth.Label,
+			"reason":       auth.LastError.Message,
+			"since":        auth.UpdatedAt,
+			"rechecks":     auth.ReauthChecks,
+			"next_recheck": auth.NextRefreshAfter,
+		}
+		if email := authEmail(auth); email != "" {
+			entry["email"] = email
+		}
+		files = append(files, entry)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		idI, _ := files[i]["id"].(string)
+		idJ, _ := files[j]["id"].(string)
+		return idI < idJ
+	})
+	c.JSON(http.StatusOK, gin.H{"files": files})
+}
+
 type authPriorityUpdateRequest struct {
 	ID       string `json:"id"`
 	Name     string `json:"name"`
-	Priority int    `json:"priority"`
+	Priority *int   `json:"priority"`
+	Weight   *int   `json:"weight"`
 }
 
 type authDisabledUpdateRequest struct {
@@ -322,6 +357,11 @@ type kiroQuotaRefreshRequest struct {
 	Name string `json:"name"`
 }
 
+type copilotQuotaRefreshRequest struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
 // PutAuthFileDisabled enables/disables an auth entry (file-backed or runtime-only).
 //
 // JSON body:
@@ -397,12 +437,14 @@ func (h *Handler) PutAuthFileDisabled(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"auth": h.buildAuthFileEntry(updated)})
 }
 
-// PutAuthFilePriority sets or clears an auth file priority.
-// Priority is stored in auth metadata under key "priority".
+// PutAuthFilePriority sets or clears an auth file priority and weight.
+// They are stored in auth metadata under keys "priority" and "weight"; fields left out of the
+// body keep their value.
 //
 // JSON body:
 //   - id (preferred) or name
 //   - priority: set to > 0 to set, set to 0 to remove
+//   - weight: set to 1-100 to set the share within the priority tier, set to 0 to remove
 func (h *Handler) PutAuthFilePriority(c *gin.Context) {
 	if h == nil || c == nil {
 		return
@@ -424,10 +466,18 @@ func (h *Handler) PutAuthFilePriority(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "id or name is required"})
 		return
 	}
-	if req.Priority < 0 {
+	if req.Priority == nil && req.Weight == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "priority or weight is required"})
+		return
+	}
+	if req.Priority != nil && *req.Priority < 0 {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "priority must be >= 0"})
 		return
 	}
+	if req.Weight != nil && (*req.Weight < 0 || *req.Weight > coreauth.MaxAuthWeight) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("weight must be between 0 and %d", coreauth.MaxAuthWeight)})
+		return
+	}
 
 	authID := req.ID
 	if authID == "" {
@@ -454,10 +504,14 @@ func (h *Handler) PutAuthFilePriority(c *gin.Context) {
 	if auth.Metadata == nil {
 		auth.Metadata = make(map[string]any)
 	}
-	if req.Priority == 0 {
-		delete(auth.Metadata, "priority")
-	} else {
-		auth.Metadata["priority"] = req.Priority
+	for key, value := range map[string]*int{"priority": req.Priority, "weight": req.Weight} {
+		switch {
+		case value == nil:
+		case *value == 0:
+			delete(auth.Metadata, key)
+		default:
+			auth.Metadata[key] = *value
+		}
 	}
 
 	updated, err := h.authManager.Update(c.Request.Context(), auth)
@@ -597,6 +651,70 @@ func (h *Handler) PostAuthFileKiroQuota(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"auth": h.buildAuthFileEntry(auth)})
 }
 
+// PostAuthFileCopilotQuota performs a Copilot token exchange to fetch the account's entitlements
+// (plan, chat quota, token expiry) and GitHub rate-limit headers and cache them in memory.
+//
+// JSON body:
+//   - id (preferred) or name
+func (h *Handler) PostAuthFileCopilotQuota(c *gin.Context) {
+	if h == nil || c == nil {
+		return
+	}
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+
+	var req copilotQuotaRefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json body"})
+		return
+	}
+	req.ID = strings.TrimSpace(req.ID)
+	req.Name = strings.TrimSpace(req.Name)
+	if req.ID == "" && req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id or name is required"})
+		return
+	}
+
+	authID := req.ID
+	if authID == "" {
+		for _, a := range h.authManager.List() {
+			if a == nil {
+				continue
+			}
+			if strings.EqualFold(strings.TrimSpace(a.FileName), req.Name) || strings.EqualFold(strings.TrimSpace(a.ID), req.Name) {
+				authID = a.ID
+				break
+			}
+		}
+	}
+	if authID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "auth not found"})
+		return
+	}
+
+	auth, ok := h.authManager.GetByID(authID)
+	if !ok || auth == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "auth not found"})
+		return
+	}
+	if !strings.EqualFold(strings.TrimSpace(auth.Provider), "github-copilot") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "auth is not github-copilot"})
+		return
+	}
+
+	snap, err := runtimeexecutor.FetchCopilotQuota(c.Request.Context(), auth, h.cfg)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	if snap != nil {
+		usage.UpdateCopilotQuotaSnapshot(auth.ID, snap)
+	}
+	c.JSON(http.StatusOK, gin.H{"auth": h.buildAuthFileEntry(auth)})
+}
+
 // GetAuthFileModels returns the models supported by a specific auth file
 func (h *Handler) GetAuthFileModels(c *gin.Context) {
 	name := c.Query("name")
@@ -675,6 +793,9 @@ func (h *Handler) listAuthFilesFromDisk(c *gin.Context) {
 				if priorityValue > 0 {
 					fileData["priority"] = priorityValue
 				}
+				if weightValue := gjson.GetBytes(data, "weight").Int(); weightValue > 1 {
+					fileData["weight"] = weightValue
+				}
 			}
 
 			files = append(files, fileData)
@@ -719,12 +840,29 @@ func (h *Handler) buildAuthFileEntry(auth *coreauth.Auth) gin.H {
 	if priority, ok := authPriority(auth); ok {
 		entry["priority"] = priority
 	}
+	if weight, ok := authMetadataInt(auth, "weight"); ok && weight > 1 {
+		entry["weight"] = weight
+	}
+	if tags := strings.TrimSpace(authAttribute(auth, "tags")); tags != "" {
+		entry["tags"] = strings.Split(tags, ",")
+	}
+	if auth.Circuit.Tripped() || auth.Circuit.ConsecutiveFailures > 0 {
+		entry["circuit"] = auth.Circuit
+	}
+	if auth.NeedsReauth() {
+		entry["status"] = "needs_reauth"
+		entry["needs_reauth"] = true
+		entry["next_recheck"] = auth.NextRefreshAfter
+	}
 	if snap := usage.GetCodexQuotaSnapshot(auth.ID); snap != nil {
 		entry["codex_quota"] = snap
 	}
 	if snap := usage.GetKiroUsageSnapshot(auth.ID); snap != nil {
 		entry["kiro_usage"] = snap
 	}
+	if snap := usage.GetCopilotQuotaSnapshot(auth.ID); snap != nil {
+		entry["copilot_quota"] = snap
+	}
 	if email := authEmail(auth); email != "" {
 		entry["email"] = email
 	}
@@ -829,11 +967,17 @@ func authEmail(auth *coreauth.Auth) string {
 }
 
 func authPriority(auth *coreauth.Auth) (int, bool) {
+	return authMetadataInt(auth, "priority")
+}
+
+// authMetadataInt reads an integer setting of auth from its metadata, falling back to the
+// attribute synthesized from it.
+func authMetadataInt(auth *coreauth.Auth, key string) (int, bool) {
 	if auth == nil {
 		return 0, false
 	}
 	if auth.Metadata != nil {
-		if v, ok := auth.Metadata["priority"]; ok {
+		if v, ok := auth.Metadata[key]; ok {
 			switch val := v.(type) {
 			case int:
 				return val, true
@@ -853,7 +997,7 @@ func authPriority(auth *coreauth.Auth) (int, bool) {
 		}
 	}
 	if auth.Attributes != nil {
-		if v := strings.TrimSpace(auth.Attributes["priority"]); v != "" {
+		if v := strings.TrimSpace(auth.Attributes[key]); v != "" {
 			if parsed, err := strconv.Atoi(v); err == nil {
 				return parsed, true
 			}
@@ -990,7 +1134,7 @@ func (h *Handler) UploadAuthFile(c *gin.Context) {
 			dst = abs
 		}
 	}
-	if errWrite := os.WriteFile(dst, data, 0o600); errWrite != nil {
+	if errWrite := misc.WriteCredentialFile(dst, data); errWrite != nil {
 		c.JSON(500, gin.H{"error": fmt.Sprintf("failed to write file: %v", errWrite)})
 		return
 	}
@@ -1241,7 +1385,7 @@ func (h *Handler) RequestAnthropicToken(c *gin.Context) {
 		return
 	}
 
-	RegisterOAuthSession(state, "anthropic")
+	RegisterOAuthSession(state, "anthropic", h.cfg.LoginTimeout("claude", 5*time.Minute))
 
 	isWebUI := isWebUIRequest(c)
 	var forwarder *callbackForwarder
@@ -1289,8 +1433,8 @@ func (h *Handler) RequestAnthropicToken(c *gin.Context) {
 		}
 
 		fmt.Println("Waiting for authentication callback...")
-		// Wait up to 5 minutes
-		resultMap, errWait := waitForFile(waitFile, 5*time.Minute)
+		// Wait up to the login timeout (5 minutes by default)
+		resultMap, errWait := waitForFile(waitFile, h.cfg.LoginTimeout("claude", 5*time.Minute))
 		if errWait != nil {
 			if errors.Is(errWait, errOAuthSessionNotPending) {
 				return
@@ -1433,7 +1577,7 @@ func (h *Handler) RequestGeminiCLIToken(c *gin.Context) {
 	state := fmt.Sprintf("gem-%d", time.Now().UnixNano())
 	authURL := conf.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.SetAuthURLParam("prompt", "consent"))
 
-	RegisterOAuthSession(state, "gemini")
+	RegisterOAuthSession(state, "gemini", h.cfg.LoginTimeout("gemini", 5*time.Minute))
 
 	isWebUI := isWebUIRequest(c)
 	var forwarder *callbackForwarder
@@ -1460,7 +1604,7 @@ func (h *Handler) RequestGeminiCLIToken(c *gin.Context) {
 		// Wait for callback file written by server route
 		waitFile := filepath.Join(h.cfg.AuthDir, fmt.Sprintf(".oauth-gemini-%s.oauth", state))
 		fmt.Println("Waiting for authentication callback...")
-		deadline := time.Now().Add(5 * time.Minute)
+		deadline := time.Now().Add(h.cfg.LoginTimeout("gemini", 5*time.Minute))
 		var authCode string
 		for {
 			if !IsOAuthSessionPending(state, "gemini") {
@@ -1680,7 +1824,7 @@ func (h *Handler) RequestCodexToken(c *gin.Context) {
 		return
 	}
 
-	RegisterOAuthSession(state, "codex")
+	RegisterOAuthSession(state, "codex", h.cfg.LoginTimeout("codex", 5*time.Minute))
 
 	isWebUI := isWebUIRequest(c)
 	var forwarder *callbackForwarder
@@ -1706,7 +1850,7 @@ func (h *Handler) RequestCodexToken(c *gin.Context) {
 
 		// Wait for callback file
 		waitFile := filepath.Join(h.cfg.AuthDir, fmt.Sprintf(".oauth-codex-%s.oauth", state))
-		deadline := time.Now().Add(5 * time.Minute)
+		deadline := time.Now().Add(h.cfg.LoginTimeout("codex", 5*time.Minute))
 		var code string
 		for {
 			if !IsOAuthSessionPending(state, "codex") {
@@ -1870,7 +2014,7 @@ func (h *Handler) RequestAntigravityToken(c *gin.Context) {
 	params.Set("state", state)
 	authURL := "https://accounts.google.com/o/oauth2/v2/auth?" + params.Encode()
 
-	RegisterOAuthSession(state, "antigravity")
+	RegisterOAuthSession(state, "antigravity", h.cfg.LoginTimeout("antigravity", 5*time.Minute))
 
 	isWebUI := isWebUIRequest(c)
 	var forwarder *callbackForwarder
@@ -1895,7 +2039,7 @@ func (h *Handler) RequestAntigravityToken(c *gin.Context) {
 		}
 
 		waitFile := filepath.Join(h.cfg.AuthDir, fmt.Sprintf(".oauth-antigravity-%s.oauth", state))
-		deadline := time.Now().Add(5 * time.Minute)
+		deadline := time.Now().Add(h.cfg.LoginTimeout("antigravity", 5*time.Minute))
 		var authCode string
 		for {
 			if !IsOAuthSessionPending(state, "antigravity") {
@@ -2092,7 +2236,7 @@ func (h *Handler) RequestQwenToken(c *gin.Context) {
 	}
 	authURL := deviceFlow.VerificationURIComplete
 
-	RegisterOAuthSession(state, "qwen")
+	RegisterOAuthSession(state, "qwen", h.cfg.LoginTimeout("qwen", 5*time.Minute))
 
 	go func() {
 		fmt.Println("Waiting for authentication...")
@@ -2129,6 +2273,83 @@ func (h *Handler) RequestQwenToken(c *gin.Context) {
 	c.JSON(200, gin.H{"status": "ok", "url": authURL, "state": state})
 }
 
+// RequestGitHubCopilotToken starts the GitHub device flow for Copilot. The verification URL and
+// user code are returned immediately and stay available through GetAuthStatus while the flow
+// polls for authorization in the background. The optional github_org query binds the login to the
+// Copilot seat of that organization.
+func (h *Handler) RequestGitHubCopilotToken(c *gin.Context) {
+	ctx := context.Background()
+
+	fmt.Println("Initializing GitHub Copilot authentication...")
+
+	state := fmt.Sprintf("copilot-%d", time.Now().UnixNano())
+	authSvc := copilot.NewCopilotAuthWithBaseURL(h.cfg, c.Query("github_base_url"))
+	organization := strings.TrimSpace(c.Query("github_org"))
+
+	var deviceCode *copilot.DeviceCodeResponse
+	var err error
+	if organization != "" {
+		deviceCode, err = authSvc.StartOrganizationDeviceFlow(ctx)
+	} else {
+		deviceCode, err = authSvc.StartDeviceFlow(ctx)
+	}
+	if err != nil {
+		log.Errorf("Failed to start device flow: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start device flow"})
+		return
+	}
+
+	RegisterOAuthSession(state, "github-copilot", h.cfg.LoginTimeout("github-copilot", 5*time.Minute))
+	// Using "|" as separator because URLs contain ":".
+	SetOAuthSessionError(state, "device_code|"+deviceCode.VerificationURI+"|"+deviceCode.UserCode)
+
+	go func() {
+		fmt.Println("Waiting for GitHub authorization...")
+		authBundle, errWait := authSvc.WaitForAuthorization(ctx, deviceCode)
+		if errWait != nil {
+			log.Errorf("Authentication failed: %v", errWait)
+			SetOAuthSessionError(state, copilot.GetUserFriendlyMessage(errWait))
+			return
+		}
+		if organization != "" {
+			verified, errOrg := authSvc.VerifyOrganization(ctx, authBundle.TokenData.AccessToken, organization)
+			if errOrg != nil {
+				log.Errorf("Failed to verify organization membership: %v", errOrg)
+				SetOAuthSessionError(state, fmt.Sprintf("Failed to verify membership in organization %s", organization))
+				return
+			}
+			authBundle.Organization = verified
+		}
+
+		apiToken, errAPIToken := authSvc.GetCopilotAPIToken(ctx, authBundle.TokenData.AccessToken)
+		if errAPIToken != nil {
+			log.Errorf("Failed to verify Copilot access: %v", errAPIToken)
+			SetOAuthSessionError(state, "Failed to verify Copilot access, check the account has an active Copilot subscription")
+			return
+		}
+
+		record := sdkAuth.BuildGitHubCopilotAuth(authSvc, authBundle, apiToken)
+		savedPath, errSave := h.saveTokenRecord(ctx, record)
+		if errSave != nil {
+			log.Errorf("Failed to save authentication tokens: %v", errSave)
+			SetOAuthSessionError(state, "Failed to save authentication tokens")
+			return
+		}
+
+		fmt.Printf("Authentication successful! Token saved to %s\n", savedPath)
+		fmt.Println("You can now use GitHub Copilot services through this CLI")
+		CompleteOAuthSession(state)
+	}()
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":           "ok",
+		"state":            state,
+		"method":           "device_code",
+		"verification_url": deviceCode.VerificationURI,
+		"user_code":        deviceCode.UserCode,
+	})
+}
+
 func (h *Handler) RequestIFlowToken(c *gin.Context) {
 	ctx := context.Background()
 
@@ -2138,7 +2359,7 @@ func (h *Handler) RequestIFlowToken(c *gin.Context) {
 	authSvc := iflowauth.NewIFlowAuth(h.cfg)
 	authURL, redirectURI := authSvc.AuthorizationURL(state, iflowauth.CallbackPort)
 
-	RegisterOAuthSession(state, "iflow")
+	RegisterOAuthSession(state, "iflow", h.cfg.LoginTimeout("iflow", 5*time.Minute))
 
 	isWebUI := isWebUIRequest(c)
 	var forwarder *callbackForwarder
@@ -2164,7 +2385,7 @@ func (h *Handler) RequestIFlowToken(c *gin.Context) {
 		fmt.Println("Waiting for authentication...")
 
 		waitFile := filepath.Join(h.cfg.AuthDir, fmt.Sprintf(".oauth-iflow-%s.oauth", state))
-		deadline := time.Now().Add(5 * time.Minute)
+		deadline := time.Now().Add(h.cfg.LoginTimeout("iflow", 5*time.Minute))
 		var resultMap map[string]string
 		for {
 			if !IsOAuthSessionPending(state, "iflow") {
@@ -2732,7 +2953,7 @@ func (h *Handler) RequestKiroToken(c *gin.Context) {
 
 	switch method {
 	case "aws", "builder-id":
-		RegisterOAuthSession(state, "kiro")
+		RegisterOAuthSession(state, "kiro", h.cfg.LoginTimeout("kiro", 5*time.Minute))
 
 		// AWS Builder ID uses device code flow (no callback needed)
 		go func() {
@@ -2842,7 +3063,7 @@ func (h *Handler) RequestKiroToken(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok", "state": state, "method": "device_code"})
 
 	case "google", "github":
-		RegisterOAuthSession(state, "kiro")
+		RegisterOAuthSession(state, "kiro", h.cfg.LoginTimeout("kiro", 5*time.Minute))
 
 		// Social auth uses protocol handler - for WEB UI we use a callback forwarder
 		provider := "Google"
@@ -2850,6 +3071,9 @@ func (h *Handler) RequestKiroToken(c *gin.Context) {
 			provider = "Github"
 		}
 
+		// Waitlisted accounts finish registration with an invitation code on the token exchange.
+		invitationCode := strings.TrimSpace(c.Query("invitation_code"))
+
 		isWebUI := isWebUIRequest(c)
 		if isWebUI {
 			targetURL, errTarget := h.managementCallbackURL("/kiro/callback")
@@ -2895,7 +3119,7 @@ func (h *Handler) RequestKiroToken(c *gin.Context) {
 
 			// Wait for callback file
 			waitFile := filepath.Join(h.cfg.AuthDir, fmt.Sprintf(".oauth-kiro-%s.oauth", state))
-			deadline := time.Now().Add(5 * time.Minute)
+			deadline := time.Now().Add(h.cfg.LoginTimeout("kiro", 5*time.Minute))
 
 			for {
 				if time.Now().After(deadline) {
@@ -2926,9 +3150,10 @@ func (h *Handler) RequestKiroToken(c *gin.Context) {
 
 					// Exchange code for tokens
 					tokenReq := &kiroauth.CreateTokenRequest{
-						Code:         code,
-						CodeVerifier: codeVerifier,
-						RedirectURI:  kiroauth.KiroRedirectURI,
+						Code:           code,
+						CodeVerifier:   codeVerifier,
+						RedirectURI:    kiroauth.KiroRedirectURI,
+						InvitationCode: invitationCode,
 					}
 
 					tokenResp, errToken := socialClient.CreateToken(ctx, tokenReq)
@@ -112,6 +112,55 @@ func TestPutAuthFilePriority_UpdatesAuthMetadata(t *testing.T) {
 	}
 }
 
+func TestPutAuthFilePriority_UpdatesWeight(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := coreauth.NewManager(nil, nil, nil)
+	cfg := &config.Config{Port: 8317}
+	h := NewHandler(cfg, "config.yaml", manager)
+
+	_, _ = manager.Register(nil, &coreauth.Auth{
+		ID:         "auth-1",
+		Provider:   "codex",
+		Attributes: map[string]string{"path": "does-not-exist.json"},
+		Metadata:   map[string]any{"priority": 3},
+	})
+
+	put := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PUT", "/v0/management/auth-files/priority", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		h.PutAuthFilePriority(c)
+		return w
+	}
+
+	if w := put(`{"id":"auth-1","weight":4}`); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	updated, _ := manager.GetByID("auth-1")
+	if updated.Metadata["weight"] != 4 || updated.Metadata["priority"] != 3 {
+		t.Fatalf("expected weight=4 with priority kept, got %#v", updated.Metadata)
+	}
+	if entry := h.buildAuthFileEntry(updated); entry["weight"] != 4 {
+		t.Fatalf("expected entry weight=4, got %#v", entry["weight"])
+	}
+
+	for _, body := range []string{`{"id":"auth-1"}`, `{"id":"auth-1","weight":101}`, `{"id":"auth-1","weight":-1}`} {
+		if w := put(body); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", body, w.Code)
+		}
+	}
+
+	if w := put(`{"id":"auth-1","weight":0}`); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	updated, _ = manager.GetByID("auth-1")
+	if _, ok := updated.Metadata["weight"]; ok {
+		t.Fatalf("expected weight to be removed, got %#v", updated.Metadata)
+	}
+}
+
 func TestPutAuthFileDisabled_TogglesAuthDisabledState(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	manager := coreauth.NewManager(nil, nil, nil)
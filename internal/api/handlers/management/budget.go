@@ -0,0 +1,17 @@
+package management
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/budget"
+)
+
+// GetBudget returns today's capacity and consumption for every budgeted provider.
+func (h *Handler) GetBudget(c *gin.Context) {
+	tracker := budget.Default()
+	c.JSON(http.StatusOK, gin.H{
+		"providers":        tracker.Snapshot(),
+		"reset_in_seconds": int64(tracker.ResetIn().Seconds()),
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -23,12 +24,27 @@ const (
 	latestReleaseUserAgent = "CLIProxyAPIPlus"
 )
 
+// GetConfig returns the active in-memory config with credential values masked. ?redact=false
+// returns the credentials as configured.
 func (h *Handler) GetConfig(c *gin.Context) {
 	if h == nil || h.cfg == nil {
 		c.JSON(200, gin.H{})
 		return
 	}
 	cfgCopy := *h.cfg
+	redact := true
+	if parsed, err := strconv.ParseBool(c.Query("redact")); err == nil {
+		redact = parsed
+	}
+	if redact {
+		redacted, err := redactedConfig(&cfgCopy)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "encode_failed", "message": err.Error()})
+			return
+		}
+		c.JSON(200, redacted)
+		return
+	}
 	c.JSON(200, &cfgCopy)
 }
 
@@ -267,6 +283,10 @@ func normalizeRoutingStrategy(strategy string) (string, bool) {
 		return "round-robin", true
 	case "fill-first", "fillfirst", "ff":
 		return "fill-first", true
+	case "weighted", "weighted-round-robin", "wrr":
+		return "weighted", true
+	case "latency", "latency-aware", "fastest":
+		return "latency", true
 	default:
 		return "", false
 	}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	log "github.com/sirupsen/logrus"
 )
 
 // Generic helpers for list[string]
@@ -110,10 +111,20 @@ func (h *Handler) PutAPIKeys(c *gin.Context) {
 	h.putStringList(c, func(v []string) {
 		h.cfg.APIKeys = append([]string(nil), v...)
 		h.cfg.Access.Providers = nil
-	}, nil)
+	}, h.hashAPIKeys)
 }
 func (h *Handler) PatchAPIKeys(c *gin.Context) {
-	h.patchStringList(c, &h.cfg.APIKeys, func() { h.cfg.Access.Providers = nil })
+	h.patchStringList(c, &h.cfg.APIKeys, func() {
+		h.cfg.Access.Providers = nil
+		h.hashAPIKeys()
+	})
+}
+
+// hashAPIKeys hashes newly added plaintext api-keys when hash-api-keys is enabled.
+func (h *Handler) hashAPIKeys() {
+	if _, err := h.cfg.HashPlaintextAPIKeys(); err != nil {
+		log.Errorf("failed to hash api keys: %v", err)
+	}
 }
 func (h *Handler) DeleteAPIKeys(c *gin.Context) {
 	h.deleteFromStringList(c, &h.cfg.APIKeys, func() { h.cfg.Access.Providers = nil })
@@ -0,0 +1,29 @@
+package management
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/conversation"
+)
+
+// GetConversations lists tracked conversations by token spend, largest first.
+func (h *Handler) GetConversations(c *gin.Context) {
+	tracker := conversation.Default()
+	maxTokens, action := tracker.Limits()
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":       tracker.Enabled(),
+		"max-tokens":    maxTokens,
+		"action":        action,
+		"conversations": tracker.Snapshot(),
+	})
+}
+
+// DeleteConversation resets a conversation's token counter, lifting its cap.
+func (h *Handler) DeleteConversation(c *gin.Context) {
+	if !conversation.Default().Reset(c.Param("id")) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "conversation not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
@@ -0,0 +1,51 @@
+package management
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const csrfHeader = "X-CSRF-Token"
+
+// newCSRFToken returns a random token valid for the lifetime of the process.
+func newCSRFToken() string {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		panic("management: generate csrf token: " + err.Error())
+	}
+	return hex.EncodeToString(buf)
+}
+
+// CSRFMiddleware hands the CSRF token to authenticated callers and, when
+// remote-management.csrf-protection is enabled, rejects state-changing requests that do not
+// echo it. It must run after Middleware so unauthenticated callers never see the token.
+func (h *Handler) CSRFMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header(csrfHeader, h.csrfToken)
+		cfg := h.cfg
+		if cfg == nil || !cfg.RemoteManagement.CSRFProtection {
+			c.Next()
+			return
+		}
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if h.csrfToken == "" || subtle.ConstantTimeCompare([]byte(c.GetHeader(csrfHeader)), []byte(h.csrfToken)) != 1 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "missing or invalid CSRF token"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// GetCSRFToken returns the token state-changing requests must send in the X-CSRF-Token header.
+func (h *Handler) GetCSRFToken(c *gin.Context) {
+	required := h.cfg != nil && h.cfg.RemoteManagement.CSRFProtection
+	c.JSON(http.StatusOK, gin.H{"token": h.csrfToken, "header": csrfHeader, "required": required})
+}
@@ -0,0 +1,39 @@
+package management
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/features"
+)
+
+// GetFeatureFlags lists every feature flag with its current state and where it comes from.
+func (h *Handler) GetFeatureFlags(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"flags": features.Default().Snapshot()})
+}
+
+// PutFeatureFlag overrides a feature flag at runtime. The body is {"enabled":true|false}; the
+// override lasts until it is deleted or the process restarts.
+func (h *Handler) PutFeatureFlag(c *gin.Context) {
+	var body struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.Enabled == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "body must be {\"enabled\": true|false}"})
+		return
+	}
+	if !features.Default().SetOverride(c.Param("name"), *body.Enabled) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown feature flag"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// DeleteFeatureFlag removes a flag's runtime override so its configured value applies again.
+func (h *Handler) DeleteFeatureFlag(c *gin.Context) {
+	if !features.Default().ClearOverride(c.Param("name")) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no override for feature flag"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
@@ -0,0 +1,65 @@
+package management
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// GetLatencySLO returns rolling first-token latency attainment per provider/model and per credential.
+func (h *Handler) GetLatencySLO(c *gin.Context) {
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+	c.JSON(http.StatusOK, h.authManager.LatencySLOReport())
+}
+
+// GetLatencySLOMetrics exposes the latency objective in the Prometheus text exposition format.
+func (h *Handler) GetLatencySLOMetrics(c *gin.Context) {
+	if h.authManager == nil {
+		c.String(http.StatusServiceUnavailable, "core auth manager unavailable\n")
+		return
+	}
+	report := h.authManager.LatencySLOReport()
+	var b strings.Builder
+	fmt.Fprintf(&b, "# HELP cliproxy_first_token_slo_threshold_seconds First-token latency threshold.\n")
+	fmt.Fprintf(&b, "# TYPE cliproxy_first_token_slo_threshold_seconds gauge\n")
+	fmt.Fprintf(&b, "cliproxy_first_token_slo_threshold_seconds %g\n", float64(report.ThresholdMS)/1000)
+	fmt.Fprintf(&b, "# HELP cliproxy_first_token_slo_target Fraction of requests that must meet the threshold.\n")
+	fmt.Fprintf(&b, "# TYPE cliproxy_first_token_slo_target gauge\n")
+	fmt.Fprintf(&b, "cliproxy_first_token_slo_target %g\n", report.Target)
+	writeLatencySLOSeries(&b, "provider", report.Providers, func(s coreauth.LatencySLOSeries) string { return s.Provider })
+	writeLatencySLOSeries(&b, "auth", report.Auths, func(s coreauth.LatencySLOSeries) string { return s.AuthID })
+	c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(b.String()))
+}
+
+func writeLatencySLOSeries(b *strings.Builder, scope string, series []coreauth.LatencySLOSeries, owner func(coreauth.LatencySLOSeries) string) {
+	metrics := []struct {
+		name  string
+		help  string
+		value func(coreauth.LatencySLOSeries) float64
+	}{
+		{"samples", "First-token latency samples in the rolling window.", func(s coreauth.LatencySLOSeries) float64 { return float64(s.Samples) }},
+		{"attainment", "Fraction of samples meeting the first-token threshold.", func(s coreauth.LatencySLOSeries) float64 { return s.Attainment }},
+		{"avg_seconds", "Average first-token latency in the rolling window.", func(s coreauth.LatencySLOSeries) float64 { return s.AvgMS / 1000 }},
+		{"max_seconds", "Maximum first-token latency in the rolling window.", func(s coreauth.LatencySLOSeries) float64 { return float64(s.MaxMS) / 1000 }},
+		{"violating", "1 when the series violates the objective.", func(s coreauth.LatencySLOSeries) float64 {
+			if s.Violating {
+				return 1
+			}
+			return 0
+		}},
+	}
+	for _, metric := range metrics {
+		name := fmt.Sprintf("cliproxy_first_token_slo_%s_%s", scope, metric.name)
+		fmt.Fprintf(b, "# HELP %s %s\n", name, metric.help)
+		fmt.Fprintf(b, "# TYPE %s gauge\n", name)
+		for _, s := range series {
+			fmt.Fprintf(b, "%s{%s=%q,model=%q} %g\n", name, scope, owner(s), s.Model, metric.value(s))
+		}
+	}
+}
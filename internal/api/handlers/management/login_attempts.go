@@ -0,0 +1,90 @@
+package management
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// attemptFreeFailures is the number of consecutive failures tolerated before lockouts start.
+	attemptFreeFailures = 3
+	// attemptBaseLockout is the lockout after the first failure past the free ones; each further
+	// failure doubles it up to attemptMaxLockout.
+	attemptBaseLockout = 5 * time.Second
+	// attemptBanFailures and attemptBanLockout keep the long-standing ban of 30 minutes after 5
+	// failures as the floor; the lockout keeps doubling from there.
+	attemptBanFailures = 5
+	attemptBanLockout  = 30 * time.Minute
+	attemptMaxLockout  = 24 * time.Hour
+)
+
+// attemptLockout returns how long clientIP has to wait before it may try a key again.
+func (h *Handler) attemptLockout(clientIP string, now time.Time) time.Duration {
+	h.attemptsMu.Lock()
+	defer h.attemptsMu.Unlock()
+	ai := h.failedAttempts[clientIP]
+	if ai == nil || !now.Before(ai.blockedUntil) {
+		return 0
+	}
+	return ai.blockedUntil.Sub(now)
+}
+
+// recordFailedAttempt counts a failed management login from clientIP and locks it out for an
+// exponentially growing period. The count only resets on success or after the idle purge, so
+// waiting out a lockout does not buy a fresh set of attempts.
+func (h *Handler) recordFailedAttempt(clientIP, reason string, now time.Time) {
+	h.attemptsMu.Lock()
+	ai := h.failedAttempts[clientIP]
+	if ai == nil {
+		ai = &attemptInfo{}
+		h.failedAttempts[clientIP] = ai
+	}
+	ai.count++
+	ai.lastActivity = now
+	count := ai.count
+	lockout := failedAttemptLockout(count)
+	if lockout > 0 {
+		ai.blockedUntil = now.Add(lockout)
+	}
+	h.attemptsMu.Unlock()
+
+	if lockout > 0 {
+		log.Warnf("management auth failed from %s (%s): %d consecutive failures, locked out for %s", clientIP, reason, count, lockout)
+		return
+	}
+	log.Warnf("management auth failed from %s (%s): %d consecutive failures", clientIP, reason, count)
+}
+
+// recordSuccessfulAttempt clears the failure history of clientIP.
+func (h *Handler) recordSuccessfulAttempt(clientIP string) {
+	h.attemptsMu.Lock()
+	ai := h.failedAttempts[clientIP]
+	failures := 0
+	if ai != nil {
+		failures = ai.count
+		delete(h.failedAttempts, clientIP)
+	}
+	h.attemptsMu.Unlock()
+	if failures > 0 {
+		log.Infof("management auth succeeded from %s after %d failed attempts", clientIP, failures)
+	}
+}
+
+// failedAttemptLockout returns the lockout imposed after the given number of consecutive failures.
+func failedAttemptLockout(failures int) time.Duration {
+	if failures <= attemptFreeFailures {
+		return 0
+	}
+	lockout, from := attemptBaseLockout, attemptFreeFailures+1
+	if failures >= attemptBanFailures {
+		lockout, from = attemptBanLockout, attemptBanFailures
+	}
+	for i := from; i < failures; i++ {
+		lockout *= 2
+		if lockout >= attemptMaxLockout {
+			return attemptMaxLockout
+		}
+	}
+	return lockout
+}
@@ -0,0 +1,74 @@
+package management
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestFailedAttemptLockout(t *testing.T) {
+	cases := map[int]time.Duration{
+		1:  0,
+		3:  0,
+		4:  5 * time.Second,
+		5:  30 * time.Minute,
+		6:  time.Hour,
+		20: attemptMaxLockout,
+	}
+	for failures, want := range cases {
+		if got := failedAttemptLockout(failures); got != want {
+			t.Errorf("failedAttemptLockout(%d) = %s, want %s", failures, got, want)
+		}
+	}
+}
+
+func TestMiddlewareLocksOutRepeatedFailures(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{RemoteManagement: config.RemoteManagement{AllowRemote: true, SecretKey: string(hash)}}
+	h := &Handler{cfg: cfg, failedAttempts: make(map[string]*attemptInfo)}
+	engine := gin.New()
+	engine.GET("/v0/management/config", h.Middleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	call := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v0/management/config", nil)
+		req.RemoteAddr = "192.168.1.20:50000"
+		req.Header.Set("Authorization", "Bearer "+key)
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < attemptFreeFailures+1; i++ {
+		if rec := call("wrong"); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d status = %d, want 401", i+1, rec.Code)
+		}
+	}
+	rec := call("secret")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "5" {
+		t.Fatalf("locked out status = %d retry-after = %q, want 429 and 5", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	// Expire the lockout; the failure count survives and the fifth failure bans for 30 minutes.
+	h.failedAttempts["192.168.1.20"].blockedUntil = time.Now().Add(-time.Second)
+	call("wrong")
+	if wait := h.attemptLockout("192.168.1.20", time.Now()); wait < attemptBanLockout-time.Minute {
+		t.Fatalf("lockout after the fifth failure = %s, want at least %s", wait, attemptBanLockout)
+	}
+
+	h.failedAttempts["192.168.1.20"].blockedUntil = time.Time{}
+	if rec = call("secret"); rec.Code != http.StatusOK {
+		t.Fatalf("valid key status = %d, want 200", rec.Code)
+	}
+	if _, tracked := h.failedAttempts["192.168.1.20"]; tracked {
+		t.Fatal("successful login must clear the failure history")
+	}
+}
@@ -0,0 +1,60 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/maintenance"
+)
+
+// GetMaintenance lists providers with an upcoming or active maintenance window or an override.
+func (h *Handler) GetMaintenance(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": maintenance.Default().Snapshot()})
+}
+
+// PutMaintenance overrides a provider's maintenance state. The body is
+// {"state":"maintenance"|"active","until":"<RFC 3339>","reason":"..."}; without until the
+// override lasts until it is deleted.
+func (h *Handler) PutMaintenance(c *gin.Context) {
+	var body struct {
+		State  string `json:"state"`
+		Until  string `json:"until"`
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	state := strings.ToLower(strings.TrimSpace(body.State))
+	if state != maintenance.StateMaintenance && state != maintenance.StateActive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "state must be maintenance or active"})
+		return
+	}
+	var until time.Time
+	if raw := strings.TrimSpace(body.Until); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil || !parsed.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "until must be a future RFC 3339 timestamp"})
+			return
+		}
+		until = parsed
+	}
+	provider := strings.TrimSpace(c.Param("provider"))
+	if provider == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "provider is required"})
+		return
+	}
+	maintenance.Default().SetOverride(provider, state, until, body.Reason)
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// DeleteMaintenance removes a provider's override so its configured windows apply again.
+func (h *Handler) DeleteMaintenance(c *gin.Context) {
+	if !maintenance.Default().ClearOverride(c.Param("provider")) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no override for provider"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
@@ -0,0 +1,46 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/modelrename"
+)
+
+// GetModelRenames lists the deprecated model IDs and the models that replace them.
+func (h *Handler) GetModelRenames(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"model-renames": modelrename.Default().Snapshot()})
+}
+
+// PutModelRename renames a model at runtime. The body is {"to":"<successor>","reason":"..."};
+// an empty to suspends the configured rename of the model.
+func (h *Handler) PutModelRename(c *gin.Context) {
+	var body struct {
+		To     string `json:"to"`
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	model := strings.TrimSpace(c.Param("model"))
+	if strings.EqualFold(model, strings.TrimSpace(body.To)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a model cannot be renamed to itself"})
+		return
+	}
+	if !modelrename.Default().SetOverride(model, body.To, body.Reason) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "model is required"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// DeleteModelRename removes the runtime rename of a model so its configured rename applies again.
+func (h *Handler) DeleteModelRename(c *gin.Context) {
+	if !modelrename.Default().ClearOverride(c.Param("model")) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no override for model"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
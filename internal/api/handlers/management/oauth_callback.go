@@ -5,8 +5,10 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
 )
 
 type oauthCallbackRequest struct {
@@ -74,6 +76,14 @@ func (h *Handler) PostOAuthCallback(c *gin.Context) {
 	}
 
 	sessionProvider, _, ok := GetOAuthSession(state)
+	if !ok {
+		// Logins started by a CLI process on this host, such as a Kiro remote login, are persisted
+		// in the auth directory instead of registered here; adopt them so the callback is accepted.
+		if pending, errPending := sdkAuth.LoadPendingLogin(h.cfg, state); errPending == nil && pending.Provider == canonicalProvider {
+			RegisterOAuthSession(state, pending.Provider, h.cfg.LoginTimeout(pending.Provider, 5*time.Minute))
+			sessionProvider, _, ok = GetOAuthSession(state)
+		}
+	}
 	if !ok {
 		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "unknown or expired state"})
 		return
@@ -8,9 +8,11 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
 )
 
 func TestPostOAuthCallback_KiroAuthURLSessionIsStillPending(t *testing.T) {
@@ -20,7 +22,7 @@ func TestPostOAuthCallback_KiroAuthURLSessionIsStillPending(t *testing.T) {
 	h := &Handler{cfg: &config.Config{AuthDir: authDir}}
 
 	state := "kiro-test-state"
-	RegisterOAuthSession(state, "kiro")
+	RegisterOAuthSession(state, "kiro", 0)
 	SetOAuthSessionError(state, "auth_url|https://example.com/login")
 	t.Cleanup(func() { CompleteOAuthSession(state) })
 
@@ -62,3 +64,93 @@ func TestPostOAuthCallback_KiroAuthURLSessionIsStillPending(t *testing.T) {
 		t.Fatalf("expected code %q, got %q", "abc123", payload["code"])
 	}
 }
+
+func TestGetAuthStatus_CopilotDeviceCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := &Handler{cfg: &config.Config{AuthDir: t.TempDir()}}
+
+	state := "copilot-test-state"
+	RegisterOAuthSession(state, "github-copilot", 0)
+	SetOAuthSessionError(state, "device_code|https://github.com/login/device|ABCD-1234")
+	t.Cleanup(func() { CompleteOAuthSession(state) })
+
+	if !IsOAuthSessionPending(state, "github-copilot") {
+		t.Fatal("device code session must still be pending")
+	}
+
+	router := gin.New()
+	router.GET("/get-auth-status", h.GetAuthStatus)
+
+	req := httptest.NewRequest(http.MethodGet, "/get-auth-status?state="+state, nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var resp map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if resp["status"] != "device_code" || resp["verification_url"] != "https://github.com/login/device" || resp["user_code"] != "ABCD-1234" {
+		t.Fatalf("unexpected status response: %v", resp)
+	}
+}
+
+func TestPostOAuthCallback_AdoptsPersistedCLILogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{AuthDir: t.TempDir()}
+	h := &Handler{cfg: cfg}
+
+	state := "remote-login-state"
+	sdkAuth.SavePendingLogin(cfg, sdkAuth.PendingLogin{Provider: "kiro", IdentityProvider: "Google", State: state, CodeVerifier: "v"})
+	t.Cleanup(func() { CompleteOAuthSession(state) })
+
+	body, err := json.Marshal(map[string]any{
+		"provider":     "kiro",
+		"redirect_url": "kiro://kiro.kiroAgent/authenticate-success?code=remote-code&state=" + state,
+	})
+	if err != nil {
+		t.Fatalf("marshal request body: %v", err)
+	}
+
+	router := gin.New()
+	router.POST("/oauth-callback", h.PostOAuthCallback)
+
+	req := httptest.NewRequest(http.MethodPost, "/oauth-callback", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	data, err := os.ReadFile(filepath.Join(cfg.AuthDir, ".oauth-kiro-"+state+".oauth"))
+	if err != nil {
+		t.Fatalf("expected callback file: %v", err)
+	}
+	var payload map[string]string
+	if err = json.Unmarshal(data, &payload); err != nil {
+		t.Fatalf("unmarshal callback payload: %v", err)
+	}
+	if payload["code"] != "remote-code" {
+		t.Fatalf("unexpected callback payload: %v", payload)
+	}
+}
+
+func TestRegisterOAuthSessionFollowsLoginTimeout(t *testing.T) {
+	state := "kiro-long-login"
+	RegisterOAuthSession(state, "kiro", time.Hour)
+	t.Cleanup(func() { CompleteOAuthSession(state) })
+
+	session, ok := oauthSessions.Get(state)
+	if !ok {
+		t.Fatal("session must be registered")
+	}
+	if lifetime := session.ExpiresAt.Sub(session.CreatedAt); lifetime != time.Hour+oauthSessionGrace {
+		t.Fatalf("session lifetime = %v, want the login timeout plus grace", lifetime)
+	}
+	SetOAuthSessionError(state, "auth_url|https://example.com/login")
+	if session, _ = oauthSessions.Get(state); session.ExpiresAt.Sub(time.Now()) < time.Hour {
+		t.Fatalf("a status update must keep the session's lifetime, expires at %v", session.ExpiresAt)
+	}
+}
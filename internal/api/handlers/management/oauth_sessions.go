@@ -12,7 +12,10 @@ import (
 )
 
 const (
-	oauthSessionTTL     = 10 * time.Minute
+	oauthSessionTTL = 10 * time.Minute
+	// oauthSessionGrace keeps a session past its login timeout so the final status can still be
+	// polled after the login gave up waiting.
+	oauthSessionGrace   = 5 * time.Minute
 	maxOAuthStateLength = 128
 )
 
@@ -27,6 +30,8 @@ type oauthSession struct {
 	Status    string
 	CreatedAt time.Time
 	ExpiresAt time.Time
+	// TTL is how long the session lives after its last update.
+	TTL time.Duration
 }
 
 type oauthSessionStore struct {
@@ -53,12 +58,17 @@ func (s *oauthSessionStore) purgeExpiredLocked(now time.Time) {
 	}
 }
 
-func (s *oauthSessionStore) Register(state, provider string) {
+// Register starts a pending session that expires after ttl, or the store default when ttl is
+// not positive.
+func (s *oauthSessionStore) Register(state, provider string, ttl time.Duration) {
 	state = strings.TrimSpace(state)
 	provider = strings.ToLower(strings.TrimSpace(provider))
 	if state == "" || provider == "" {
 		return
 	}
+	if ttl <= 0 {
+		ttl = s.ttl
+	}
 	now := time.Now()
 
 	s.mu.Lock()
@@ -69,7 +79,8 @@ func (s *oauthSessionStore) Register(state, provider string) {
 		Provider:  provider,
 		Status:    "",
 		CreatedAt: now,
-		ExpiresAt: now.Add(s.ttl),
+		ExpiresAt: now.Add(ttl),
+		TTL:       ttl,
 	}
 }
 
@@ -93,7 +104,10 @@ func (s *oauthSessionStore) SetError(state, message string) {
 		return
 	}
 	session.Status = message
-	session.ExpiresAt = now.Add(s.ttl)
+	if session.TTL <= 0 {
+		session.TTL = s.ttl
+	}
+	session.ExpiresAt = now.Add(session.TTL)
 	s.sessions[state] = session
 }
 
@@ -158,9 +172,7 @@ func (s *oauthSessionStore) IsPending(state, provider string) bool {
 		return false
 	}
 	if session.Status != "" {
-		if !strings.EqualFold(session.Provider, "kiro") {
-			return false
-		}
+		// Device code and auth URL statuses carry data for the frontend; the flow is still running.
 		if !strings.HasPrefix(session.Status, "device_code|") && !strings.HasPrefix(session.Status, "auth_url|") {
 			return false
 		}
@@ -173,7 +185,16 @@ func (s *oauthSessionStore) IsPending(state, provider string) bool {
 
 var oauthSessions = newOAuthSessionStore(oauthSessionTTL)
 
-func RegisterOAuthSession(state, provider string) { oauthSessions.Register(state, provider) }
+// RegisterOAuthSession starts a pending session for a login that waits up to loginTimeout; the
+// session outlives the wait by oauthSessionGrace. A non-positive loginTimeout uses the default
+// session lifetime.
+func RegisterOAuthSession(state, provider string, loginTimeout time.Duration) {
+	var ttl time.Duration
+	if loginTimeout > 0 {
+		ttl = loginTimeout + oauthSessionGrace
+	}
+	oauthSessions.Register(state, provider, ttl)
+}
 
 func SetOAuthSessionError(state, message string) { oauthSessions.SetError(state, message) }
 
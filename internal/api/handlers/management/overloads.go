@@ -0,0 +1,17 @@
+package management
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetOverloads returns how often each provider/model answered as overloaded. These responses
+// are backed off briefly and kept out of the quota cooldown statistics.
+func (h *Handler) GetOverloads(c *gin.Context) {
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"overloads": h.authManager.OverloadReport()})
+}
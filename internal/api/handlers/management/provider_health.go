@@ -0,0 +1,17 @@
+package management
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetProviderHealth returns per-provider error rates, last success, suspended credentials and
+// active cooldowns.
+func (h *Handler) GetProviderHealth(c *gin.Context) {
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"providers": h.authManager.ProviderHealthReport()})
+}
@@ -0,0 +1,74 @@
+package management
+
+import (
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/replay"
+)
+
+// ListReplayQueue returns the captured requests awaiting replay. Payloads are omitted.
+func (h *Handler) ListReplayQueue(c *gin.Context) {
+	queue := replay.Default()
+	entries, err := queue.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	items := make([]gin.H, 0, len(entries))
+	for _, entry := range entries {
+		items = append(items, gin.H{
+			"id":              entry.ID,
+			"created_at":      entry.CreatedAt,
+			"handler_type":    entry.HandlerType,
+			"model":           entry.Model,
+			"path":            entry.Path,
+			"status_code":     entry.StatusCode,
+			"error":           entry.Error,
+			"attempts":        entry.Attempts,
+			"last_attempt_at": entry.LastAttemptAt,
+			"payload_bytes":   len(entry.Payload),
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"entries": items, "replaying": queue.Replaying()})
+}
+
+// DeleteReplayEntry discards a captured request without replaying it.
+func (h *Handler) DeleteReplayEntry(c *gin.Context) {
+	if err := replay.Default().Delete(c.Param("id")); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "entry not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// ReplayQueued replays captured requests in the background. The optional body
+// {"ids": [...]} limits the run to specific entries; otherwise every entry is replayed.
+// Outcomes are delivered to the configured webhook.
+func (h *Handler) ReplayQueued(c *gin.Context) {
+	var body struct {
+		IDs []string `json:"ids"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+	}
+	queued, err := replay.Default().ReplayAsync(body.IDs)
+	if err != nil {
+		if errors.Is(err, replay.ErrReplayInProgress) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"queued": queued})
+}
@@ -0,0 +1,64 @@
+package management
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/sampling"
+)
+
+// ListSamples returns the stored prompt/response samples. Bodies are omitted; use ExportSamples to fetch them.
+func (h *Handler) ListSamples(c *gin.Context) {
+	sampler := sampling.Default()
+	samples, err := sampler.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	items := make([]gin.H, 0, len(samples))
+	for _, sample := range samples {
+		items = append(items, gin.H{
+			"id":             sample.ID,
+			"created_at":     sample.CreatedAt,
+			"handler_type":   sample.HandlerType,
+			"model":          sample.Model,
+			"upstream_model": sample.UpstreamModel,
+			"provider":       sample.Provider,
+			"auth_index":     sample.AuthIndex,
+			"stream":         sample.Stream,
+			"latency_ms":     sample.LatencyMS,
+			"request_bytes":  len(sample.Request),
+			"response_bytes": len(sample.Response),
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"samples": items, "rate": sampler.Rate()})
+}
+
+// ExportSamples streams every stored sample as JSON Lines, oldest first.
+func (h *Handler) ExportSamples(c *gin.Context) {
+	samples, err := sampling.Default().List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", "attachment; filename=samples.jsonl")
+	c.Status(http.StatusOK)
+	encoder := json.NewEncoder(c.Writer)
+	for _, sample := range samples {
+		if errEncode := encoder.Encode(sample); errEncode != nil {
+			return
+		}
+	}
+}
+
+// DeleteSamples removes every stored sample.
+func (h *Handler) DeleteSamples(c *gin.Context) {
+	removed, err := sampling.Default().Clear()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"removed": removed})
+}
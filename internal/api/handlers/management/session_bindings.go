@@ -0,0 +1,110 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+type sessionBindingRequest struct {
+	SessionKey string `json:"session_key"`
+	APIKey     string `json:"api_key"`
+	AuthID     string `json:"auth_id"`
+	Model      string `json:"model"`
+}
+
+func (r sessionBindingRequest) target() coreauth.SessionPinTarget {
+	return coreauth.SessionPinTarget{SessionKey: r.SessionKey, APIKey: r.APIKey, Model: r.Model}
+}
+
+// stickySelector returns the sticky selector pins are stored in, or writes the error response.
+func (h *Handler) stickySelector(c *gin.Context) (*coreauth.StickySelector, bool) {
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return nil, false
+	}
+	sticky, ok := h.authManager.Selector().(*coreauth.StickySelector)
+	if !ok || sticky == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "session bindings require the sticky routing strategy"})
+		return nil, false
+	}
+	return sticky, true
+}
+
+// GetSessionBindings lists the sessions and client API keys pinned to an auth.
+func (h *Handler) GetSessionBindings(c *gin.Context) {
+	var sticky *coreauth.StickySelector
+	if h.authManager != nil {
+		sticky, _ = h.authManager.Selector().(*coreauth.StickySelector)
+	}
+	if sticky == nil {
+		c.JSON(http.StatusOK, gin.H{"pins": []coreauth.SessionPin{}})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"pins": sticky.PinnedSessions()})
+}
+
+// PutSessionBinding pins a session key or a client API key to an auth. The body is
+// {"session_key":"...","auth_id":"..."} or {"api_key":"...","auth_id":"..."}, with "model" when
+// sessions are bound per model. The pin replaces the sticky binding and lasts until it is deleted,
+// so an operator can move sessions off an account before maintenance.
+func (h *Handler) PutSessionBinding(c *gin.Context) {
+	var body sessionBindingRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	sticky, ok := h.stickySelector(c)
+	if !ok {
+		return
+	}
+	authID := strings.TrimSpace(body.AuthID)
+	if authID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "auth_id is required"})
+		return
+	}
+	auth, found := h.authManager.GetByID(authID)
+	if !found || auth == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "auth not found"})
+		return
+	}
+	if err := sticky.PinSession(body.target(), auth); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// DeleteSessionBinding removes the pins of a session key or client API key, given in the body or
+// as session_key, api_key and model query parameters. Without a model the pins of every model are
+// removed.
+func (h *Handler) DeleteSessionBinding(c *gin.Context) {
+	var body sessionBindingRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+	}
+	if body.SessionKey == "" && body.APIKey == "" {
+		body.SessionKey = c.Query("session_key")
+		body.APIKey = c.Query("api_key")
+		body.Model = c.Query("model")
+	}
+	sticky, ok := h.stickySelector(c)
+	if !ok {
+		return
+	}
+	removed, err := sticky.UnpinSession(body.target())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if removed == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no pin for session"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "removed": removed})
+}
@@ -0,0 +1,63 @@
+package management
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/runtime/executor"
+)
+
+type tokenizeRequest struct {
+	Model      string          `json:"model"`
+	Text       *string         `json:"text"`
+	Messages   json.RawMessage `json:"messages"`
+	IncludeIDs bool            `json:"include_ids"`
+}
+
+// Tokenize counts tokens of text or OpenAI-style chat messages with the tokenizer the proxy uses
+// for the model's local estimates.
+//
+// Example:
+//
+//	curl -sS -X POST "http://127.0.0.1:8317/v0/management/tokenize" \
+//	  -H "Authorization: Bearer <MANAGEMENT_KEY>" \
+//	  -H "Content-Type: application/json" \
+//	  -d '{"model":"claude-sonnet-4-5","text":"Hello, world","include_ids":true}'
+func (h *Handler) Tokenize(c *gin.Context) {
+	var body tokenizeRequest
+	if errBindJSON := c.ShouldBindJSON(&body); errBindJSON != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	model := strings.TrimSpace(body.Model)
+	if body.Text == nil && len(body.Messages) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing text or messages"})
+		return
+	}
+
+	resp := gin.H{"model": model}
+	if body.Text != nil {
+		count, err := executor.TokenizeText(model, *body.Text, body.IncludeIDs)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		resp["encoding"] = count.Encoding
+		resp["tokens"] = count.Tokens
+		resp["raw_tokens"] = count.RawTokens
+		if body.IncludeIDs {
+			resp["ids"] = count.IDs
+		}
+	}
+	if len(body.Messages) > 0 {
+		count, err := executor.CountMessageTokens(model, body.Messages)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		resp["message_tokens"] = count
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,45 @@
+package management
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTokenize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &Handler{}
+
+	call := func(body string) (int, map[string]any) {
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v0/management/tokenize", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		h.Tokenize(c)
+		var out map[string]any
+		_ = json.Unmarshal(rec.Body.Bytes(), &out)
+		return rec.Code, out
+	}
+
+	code, out := call(`{"model":"gpt-4o","text":"Hello, world","include_ids":true}`)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, body %v", code, out)
+	}
+	ids, _ := out["ids"].([]any)
+	if out["encoding"] != "o200k_base" || out["tokens"].(float64) != float64(len(ids)) || len(ids) == 0 {
+		t.Fatalf("unexpected response: %v", out)
+	}
+
+	code, out = call(`{"model":"claude-sonnet-4-5","messages":[{"role":"user","content":"Hello, world"}]}`)
+	if code != http.StatusOK || out["message_tokens"].(float64) <= 0 || out["tokens"] != nil {
+		t.Fatalf("unexpected messages response: %d %v", code, out)
+	}
+
+	if code, _ = call(`{"model":"gpt-4o"}`); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without text or messages, got %d", code)
+	}
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// unixSocketMode restricts socket access to the owning user; operators can widen it with chmod.
+const unixSocketMode fs.FileMode = 0o600
+
+// listenUnix binds a unix domain socket at path, replacing any stale socket file
+// left behind by a previous run. Regular files are never removed.
+func listenUnix(path string) (net.Listener, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, fmt.Errorf("unix socket path is empty")
+	}
+	if info, errStat := os.Lstat(path); errStat == nil {
+		if info.Mode()&fs.ModeSocket == 0 {
+			return nil, fmt.Errorf("unix socket path %s exists and is not a socket", path)
+		}
+		if errRemove := os.Remove(path); errRemove != nil {
+			return nil, fmt.Errorf("failed to remove stale unix socket %s: %w", path, errRemove)
+		}
+	} else if !errors.Is(errStat, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to inspect unix socket path %s: %w", path, errStat)
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if errMkdir := os.MkdirAll(dir, 0o700); errMkdir != nil {
+			return nil, fmt.Errorf("failed to create unix socket directory %s: %w", dir, errMkdir)
+		}
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if errChmod := os.Chmod(path, unixSocketMode); errChmod != nil {
+		_ = ln.Close()
+		return nil, fmt.Errorf("failed to set permissions on unix socket %s: %w", path, errChmod)
+	}
+	return ln, nil
+}
+
+// listenTCP binds addr in the address family configured by listen-family.
+func (s *Server) listenTCP(addr string) (net.Listener, error) {
+	family := ""
+	if s.cfg != nil {
+		family = s.cfg.ListenFamily
+	}
+	if !util.ValidListenFamily(family) {
+		return nil, fmt.Errorf("invalid listen-family %q: use dual, ipv4 or ipv6", family)
+	}
+	return net.Listen(util.ListenNetwork(family), addr)
+}
+
+// localListenerPrincipal identifies requests admitted over a local listener without an API key.
+const localListenerPrincipal = "local-listener"
+
+type localAuthExemptKey struct{}
+
+// localAuthExempt reports whether API key checks are waived for the request's connection.
+func localAuthExempt(ctx context.Context) bool {
+	exempt, _ := ctx.Value(localAuthExemptKey{}).(bool)
+	return exempt
+}
+
+// dataPlaneConnContext tags unix socket and named pipe connections as local and, unless
+// local-listener.require-api-key is set, exempts them from API key authentication.
+func (s *Server) dataPlaneConnContext(ctx context.Context, conn net.Conn) context.Context {
+	ctx = util.LocalConnContext(ctx, conn)
+	if !util.IsLocalConnection(ctx) {
+		return ctx
+	}
+	if cfg := s.cfg; cfg != nil && cfg.LocalListener.RequireAPIKey {
+		return ctx
+	}
+	return context.WithValue(ctx, localAuthExemptKey{}, true)
+}
+
+// startLocalListeners binds the configured unix socket and named pipe and serves the data plane on them.
+// The listeners share the main http.Server, so Stop shuts them down together with the TCP port.
+func (s *Server) startLocalListeners() error {
+	if s.cfg == nil {
+		return nil
+	}
+	listeners := make([]net.Listener, 0, 2)
+	closeAll := func() {
+		for _, ln := range listeners {
+			_ = ln.Close()
+		}
+	}
+	if socketPath := s.cfg.LocalListener.UnixSocket; socketPath != "" {
+		ln, err := listenUnix(socketPath)
+		if err != nil {
+			return fmt.Errorf("failed to start API server: %w", err)
+		}
+		log.Infof("API server listening on unix socket %s", socketPath)
+		listeners = append(listeners, ln)
+	}
+	if pipeName := s.cfg.LocalListener.NamedPipe; pipeName != "" {
+		ln, err := listenPipe(pipeName)
+		if err != nil {
+			closeAll()
+			return fmt.Errorf("failed to start API server: %w", err)
+		}
+		log.Infof("API server listening on named pipe %s", ln.Addr())
+		listeners = append(listeners, ln)
+	}
+	for _, ln := range listeners {
+		go func(ln net.Listener) {
+			if errServe := s.server.Serve(ln); errServe != nil && !errors.Is(errServe, http.ErrServerClosed) {
+				log.Errorf("local listener %s stopped: %v", ln.Addr(), errServe)
+			}
+		}(ln)
+	}
+	return nil
+}
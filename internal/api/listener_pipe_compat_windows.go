@@ -0,0 +1,13 @@
+//go:build !go1.25
+
+package api
+
+import (
+	"fmt"
+	"net"
+)
+
+// listenPipe needs os.NewFile support for overlapped handles, which arrived in Go 1.25.
+func listenPipe(name string) (net.Listener, error) {
+	return nil, fmt.Errorf("named pipe %s: named pipe support requires building with Go 1.25 or newer", name)
+}
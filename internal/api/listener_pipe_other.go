@@ -0,0 +1,13 @@
+//go:build !windows
+
+package api
+
+import (
+	"fmt"
+	"net"
+)
+
+// listenPipe is unavailable outside Windows; use a unix socket instead.
+func listenPipe(name string) (net.Listener, error) {
+	return nil, fmt.Errorf("named pipe %s: named pipes are only supported on Windows", name)
+}
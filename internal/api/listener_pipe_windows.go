@@ -0,0 +1,192 @@
+//go:build go1.25
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	pipePrefix     = `\\.\pipe\`
+	pipeBufferSize = 64 * 1024
+)
+
+// pipeAddr implements net.Addr for a named pipe endpoint.
+type pipeAddr string
+
+func (a pipeAddr) Network() string { return "pipe" }
+func (a pipeAddr) String() string  { return string(a) }
+
+// pipeConn adapts an overlapped pipe handle, registered with the runtime poller via os.NewFile, to net.Conn.
+type pipeConn struct {
+	*os.File
+	addr pipeAddr
+}
+
+func (c *pipeConn) LocalAddr() net.Addr  { return c.addr }
+func (c *pipeConn) RemoteAddr() net.Addr { return c.addr }
+
+// pipeListener accepts clients on a Windows named pipe, creating a new pipe instance per connection.
+type pipeListener struct {
+	addr pipeAddr
+	sa   *windows.SecurityAttributes
+	// closeEvent is signalled by Close to abort a blocked Accept.
+	closeEvent windows.Handle
+
+	mu      sync.Mutex
+	closed  bool
+	first   bool
+	pending windows.Handle
+}
+
+// listenPipe creates a named pipe listener restricted to the current user and local clients.
+func listenPipe(name string) (net.Listener, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("named pipe name is empty")
+	}
+	if !strings.HasPrefix(strings.ToLower(name), pipePrefix) {
+		name = pipePrefix + name
+	}
+	sa, err := currentUserSecurityAttributes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build named pipe security descriptor: %w", err)
+	}
+	closeEvent, err := windows.CreateEvent(nil, 1, 0, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create named pipe close event: %w", err)
+	}
+	l := &pipeListener{addr: pipeAddr(name), sa: sa, closeEvent: closeEvent, first: true, pending: windows.InvalidHandle}
+	// Create the first instance eagerly so name conflicts surface at startup.
+	h, err := l.newInstance()
+	if err != nil {
+		_ = windows.CloseHandle(closeEvent)
+		return nil, err
+	}
+	l.pending = h
+	return l, nil
+}
+
+// currentUserSecurityAttributes grants pipe access to SYSTEM and the process owner only,
+// mirroring the 0600 permissions applied to unix sockets.
+func currentUserSecurityAttributes() (*windows.SecurityAttributes, error) {
+	user, err := windows.GetCurrentProcessToken().GetTokenUser()
+	if err != nil {
+		return nil, err
+	}
+	sd, err := windows.SecurityDescriptorFromString(fmt.Sprintf("D:P(A;;GA;;;SY)(A;;GA;;;%s)", user.User.Sid.String()))
+	if err != nil {
+		return nil, err
+	}
+	sa := &windows.SecurityAttributes{SecurityDescriptor: sd}
+	sa.Length = uint32(unsafe.Sizeof(*sa))
+	return sa, nil
+}
+
+func (l *pipeListener) newInstance() (windows.Handle, error) {
+	name, err := windows.UTF16PtrFromString(string(l.addr))
+	if err != nil {
+		return windows.InvalidHandle, err
+	}
+	openMode := uint32(windows.PIPE_ACCESS_DUPLEX | windows.FILE_FLAG_OVERLAPPED)
+	if l.first {
+		openMode |= windows.FILE_FLAG_FIRST_PIPE_INSTANCE
+	}
+	pipeMode := uint32(windows.PIPE_TYPE_BYTE | windows.PIPE_READMODE_BYTE | windows.PIPE_WAIT | windows.PIPE_REJECT_REMOTE_CLIENTS)
+	h, err := windows.CreateNamedPipe(name, openMode, pipeMode, windows.PIPE_UNLIMITED_INSTANCES, pipeBufferSize, pipeBufferSize, 0, l.sa)
+	if err != nil {
+		return windows.InvalidHandle, fmt.Errorf("failed to create named pipe %s: %w", l.addr, err)
+	}
+	l.first = false
+	return h, nil
+}
+
+// Accept waits for a client to connect to the next pipe instance and returns it as a net.Conn.
+func (l *pipeListener) Accept() (net.Conn, error) {
+	for {
+		l.mu.Lock()
+		if l.closed {
+			l.mu.Unlock()
+			return nil, net.ErrClosed
+		}
+		h := l.pending
+		l.pending = windows.InvalidHandle
+		if h == windows.InvalidHandle {
+			var err error
+			if h, err = l.newInstance(); err != nil {
+				l.mu.Unlock()
+				return nil, err
+			}
+		}
+		l.mu.Unlock()
+
+		err := l.connect(h)
+		if err == nil {
+			return &pipeConn{File: os.NewFile(uintptr(h), string(l.addr)), addr: l.addr}, nil
+		}
+		_ = windows.CloseHandle(h)
+		switch {
+		case errors.Is(err, windows.ERROR_NO_DATA):
+			// The client went away before the connection completed; wait for the next one.
+			continue
+		case errors.Is(err, net.ErrClosed):
+			return nil, err
+		default:
+			return nil, fmt.Errorf("failed to accept named pipe client: %w", err)
+		}
+	}
+}
+
+// connect blocks until a client connects to h or the listener is closed.
+func (l *pipeListener) connect(h windows.Handle) error {
+	event, err := windows.CreateEvent(nil, 1, 0, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = windows.CloseHandle(event) }()
+	overlapped := windows.Overlapped{HEvent: event}
+	err = windows.ConnectNamedPipe(h, &overlapped)
+	switch {
+	case err == nil, errors.Is(err, windows.ERROR_PIPE_CONNECTED):
+		return nil
+	case !errors.Is(err, windows.ERROR_IO_PENDING):
+		return err
+	}
+	signalled, err := windows.WaitForMultipleObjects([]windows.Handle{event, l.closeEvent}, false, windows.INFINITE)
+	if err != nil {
+		return err
+	}
+	var transferred uint32
+	if signalled == windows.WAIT_OBJECT_0+1 {
+		_ = windows.CancelIoEx(h, &overlapped)
+		_ = windows.GetOverlappedResult(h, &overlapped, &transferred, true)
+		return net.ErrClosed
+	}
+	return windows.GetOverlappedResult(h, &overlapped, &transferred, false)
+}
+
+// Close stops accepting clients. Established connections are unaffected.
+func (l *pipeListener) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	if l.pending != windows.InvalidHandle {
+		_ = windows.CloseHandle(l.pending)
+		l.pending = windows.InvalidHandle
+	}
+	return windows.SetEvent(l.closeEvent)
+}
+
+// Addr returns the pipe name.
+func (l *pipeListener) Addr() net.Addr { return l.addr }
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+func TestLocalUnixSocketListenerAuth(t *testing.T) {
+	testCases := []struct {
+		name          string
+		requireAPIKey bool
+		wantPrincipal string
+	}{
+		{name: "socket permissions replace api keys", requireAPIKey: false, wantPrincipal: localListenerPrincipal},
+		{name: "api key still required", requireAPIKey: true, wantPrincipal: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Keep the socket path short; unix socket paths are limited to ~100 bytes.
+			socketDir, err := os.MkdirTemp("", "cpa-sock")
+			if err != nil {
+				t.Fatalf("failed to create socket dir: %v", err)
+			}
+			t.Cleanup(func() { _ = os.RemoveAll(socketDir) })
+			socketPath := filepath.Join(socketDir, "api.sock")
+
+			server := newTestServer(t)
+			server.cfg.LocalListener.UnixSocket = socketPath
+			server.cfg.LocalListener.RequireAPIKey = tc.requireAPIKey
+			server.engine.GET("/test/principal", AuthMiddleware(server.accessManager), func(c *gin.Context) {
+				c.String(http.StatusOK, c.GetString("apiKey"))
+			})
+			if err = server.startLocalListeners(); err != nil {
+				t.Fatalf("startLocalListeners: %v", err)
+			}
+			t.Cleanup(func() {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				_ = server.server.Shutdown(ctx)
+			})
+
+			info, err := os.Stat(socketPath)
+			if err != nil {
+				t.Fatalf("stat socket: %v", err)
+			}
+			if perm := info.Mode().Perm(); perm != unixSocketMode {
+				t.Fatalf("socket permissions = %o, want %o", perm, unixSocketMode)
+			}
+
+			client := &http.Client{Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", socketPath)
+				},
+			}}
+			resp, err := client.Get("http://unix/test/principal")
+			if err != nil {
+				t.Fatalf("request over unix socket failed: %v", err)
+			}
+			body, err := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			if err != nil {
+				t.Fatalf("read body: %v", err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+			}
+			if got := string(body); got != tc.wantPrincipal {
+				t.Fatalf("principal = %q, want %q", got, tc.wantPrincipal)
+			}
+		})
+	}
+}
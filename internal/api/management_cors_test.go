@@ -0,0 +1,122 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	proxyconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestManagementCORSAndCSRF(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	secretHash, err := bcrypt.GenerateFromPassword([]byte("mgmt-secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to hash secret: %v", err)
+	}
+	tmpDir := t.TempDir()
+	cfg := &proxyconfig.Config{
+		SDKConfig: sdkconfig.SDKConfig{APIKeys: []string{"test-key"}},
+		AuthDir:   tmpDir,
+		RemoteManagement: proxyconfig.RemoteManagement{
+			SecretKey:      string(secretHash),
+			AllowedOrigins: []string{"https://dash.example.com"},
+			CSRFProtection: true,
+		},
+	}
+	server := NewServer(cfg, auth.NewManager(nil, nil, nil), sdkaccess.NewManager(), filepath.Join(tmpDir, "config.yaml"))
+
+	serve := func(method, path, origin, csrf string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.RemoteAddr = "127.0.0.1:40000"
+		req.Header.Set("Authorization", "Bearer mgmt-secret")
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if method == http.MethodOptions {
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		}
+		if csrf != "" {
+			req.Header.Set("X-CSRF-Token", csrf)
+		}
+		rr := httptest.NewRecorder()
+		server.engine.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve(http.MethodOptions, "/v0/management/tokenize", "https://dash.example.com", "", "")
+	if rr.Code != http.StatusNoContent || rr.Header().Get("Access-Control-Allow-Origin") != "https://dash.example.com" || rr.Header().Get("Access-Control-Allow-Credentials") != "true" {
+		t.Fatalf("allowed preflight: status=%d headers=%v", rr.Code, rr.Header())
+	}
+	if rr = serve(http.MethodOptions, "/v0/management/tokenize", "https://evil.example.com", "", ""); rr.Code != http.StatusForbidden {
+		t.Fatalf("disallowed preflight status = %d, want 403", rr.Code)
+	}
+	if rr = serve(http.MethodOptions, "/v1/models", "https://evil.example.com", "", ""); rr.Code != http.StatusNoContent || rr.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Fatalf("data plane keeps wildcard CORS: status=%d headers=%v", rr.Code, rr.Header())
+	}
+
+	rr = serve(http.MethodGet, "/v0/management/csrf-token", "", "", "")
+	var payload struct {
+		Token    string `json:"token"`
+		Required bool   `json:"required"`
+	}
+	if rr.Code != http.StatusOK || json.Unmarshal(rr.Body.Bytes(), &payload) != nil || payload.Token == "" || !payload.Required {
+		t.Fatalf("csrf token endpoint: status=%d body=%s", rr.Code, rr.Body.String())
+	}
+	if rr.Header().Get("X-CSRF-Token") != payload.Token {
+		t.Fatalf("token header %q does not match body token", rr.Header().Get("X-CSRF-Token"))
+	}
+
+	body := `{"model":"gpt-4o","text":"hello"}`
+	if rr = serve(http.MethodPost, "/v0/management/tokenize", "https://dash.example.com", "", body); rr.Code != http.StatusForbidden {
+		t.Fatalf("missing csrf token status = %d, want 403", rr.Code)
+	}
+	if rr = serve(http.MethodPost, "/v0/management/tokenize", "https://dash.example.com", "wrong", body); rr.Code != http.StatusForbidden {
+		t.Fatalf("invalid csrf token status = %d, want 403", rr.Code)
+	}
+	if rr = serve(http.MethodPost, "/v0/management/tokenize", "https://dash.example.com", payload.Token, body); rr.Code != http.StatusOK {
+		t.Fatalf("valid csrf token status = %d, body=%s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestManagementCORSWildcardNeverAllowsCredentials(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	preflight := func(origin string, allowed ...string) *httptest.ResponseRecorder {
+		engine := gin.New()
+		engine.Use(func(c *gin.Context) {
+			if !applyManagementCORS(c, allowed) {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+		})
+		req := httptest.NewRequest(http.MethodOptions, "/v0/management/config", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		rr := httptest.NewRecorder()
+		engine.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := preflight("https://evil.example.com", "*", "https://dash.example.com")
+	if rr.Code != http.StatusNoContent || rr.Header().Get("Access-Control-Allow-Origin") != "*" || rr.Header().Get("Access-Control-Allow-Credentials") != "" {
+		t.Fatalf("wildcard entry must grant only uncredentialed access: status=%d headers=%v", rr.Code, rr.Header())
+	}
+	rr = preflight("https://dash.example.com", "*", "https://dash.example.com")
+	if rr.Header().Get("Access-Control-Allow-Origin") != "https://dash.example.com" || rr.Header().Get("Access-Control-Allow-Credentials") != "true" {
+		t.Fatalf("explicit origin must be reflected with credentials: headers=%v", rr.Header())
+	}
+	if rr = preflight("https://evil.example.com", "https://dash.example.com"); rr.Code != http.StatusForbidden || rr.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("unlisted origin must be refused: status=%d headers=%v", rr.Code, rr.Header())
+	}
+}
@@ -0,0 +1,224 @@
+// Package middleware provides HTTP middleware components for the CLI Proxy API server.
+// This file contains the response compression middleware that applies negotiated
+// brotli or gzip encoding to non-streaming responses.
+package middleware
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultCompressionMinSize is the body size below which responses are sent uncompressed.
+const DefaultCompressionMinSize = 1024
+
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+)
+
+// CompressionMiddleware creates a Gin middleware that compresses response bodies with brotli or
+// gzip, whichever the client prefers via Accept-Encoding (brotli wins ties). Bodies are buffered
+// until minSize bytes are seen, so small responses go out untouched. Streaming responses bypass
+// compression: a text/event-stream content type or a Flush before the threshold sends the body
+// as-is, keeping SSE chunks and keep-alives unbuffered.
+func CompressionMiddleware(minSize int) gin.HandlerFunc {
+	if minSize <= 0 {
+		minSize = DefaultCompressionMinSize
+	}
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead || c.GetHeader("Upgrade") != "" {
+			c.Next()
+			return
+		}
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		writer := &compressResponseWriter{ResponseWriter: c.Writer, encoding: encoding, minSize: minSize}
+		c.Writer = writer
+		defer func() {
+			writer.finish()
+			if c.Writer == writer {
+				c.Writer = writer.ResponseWriter
+			}
+		}()
+		c.Next()
+	}
+}
+
+// negotiateEncoding picks the response encoding from an Accept-Encoding header, or "" when
+// neither brotli nor gzip is acceptable.
+func negotiateEncoding(header string) string {
+	if header == "" {
+		return ""
+	}
+	quality := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		quality[name] = q
+	}
+	pick := func(name string) float64 {
+		if q, ok := quality[name]; ok {
+			return q
+		}
+		return quality["*"]
+	}
+	br, gz := pick(encodingBrotli), pick(encodingGzip)
+	switch {
+	case br > 0 && br >= gz:
+		return encodingBrotli
+	case gz > 0:
+		return encodingGzip
+	default:
+		return ""
+	}
+}
+
+// compressResponseWriter buffers the start of a response until it can decide whether to
+// compress, then either streams through an encoder or passes the body through unchanged.
+type compressResponseWriter struct {
+	gin.ResponseWriter
+	encoding string
+	minSize  int
+
+	buf     []byte
+	decided bool
+	encoder io.WriteCloser
+	flusher interface{ Flush() error }
+}
+
+// Write buffers or compresses data depending on the compression decision.
+func (w *compressResponseWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		if w.shouldBypass() {
+			if err := w.passThrough(); err != nil {
+				return 0, err
+			}
+		} else {
+			w.buf = append(w.buf, data...)
+			if len(w.buf) < w.minSize {
+				return len(data), nil
+			}
+			pending := w.buf
+			w.buf = nil
+			if err := w.startCompression(); err != nil {
+				return 0, err
+			}
+			if _, err := w.encoder.Write(pending); err != nil {
+				return 0, err
+			}
+			return len(data), nil
+		}
+	}
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString implements gin.ResponseWriter.
+func (w *compressResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Written reports true once the handler produced body bytes, even while they are still buffered.
+func (w *compressResponseWriter) Written() bool {
+	return len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+// Flush sends buffered data to the client. A flush before the compression decision marks the
+// response as streaming, so it goes out uncompressed.
+func (w *compressResponseWriter) Flush() {
+	if !w.decided {
+		_ = w.passThrough()
+	}
+	if w.flusher != nil {
+		_ = w.flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Hijack hands the connection over without compression.
+func (w *compressResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.decided = true
+	return w.ResponseWriter.Hijack()
+}
+
+// shouldBypass reports whether the response must not be compressed based on its headers.
+func (w *compressResponseWriter) shouldBypass() bool {
+	status := w.Status()
+	if status == http.StatusNoContent || status == http.StatusNotModified || status < http.StatusOK {
+		return true
+	}
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" {
+		return true
+	}
+	return strings.HasPrefix(strings.ToLower(header.Get("Content-Type")), "text/event-stream")
+}
+
+// passThrough decides against compression and writes any buffered bytes unchanged.
+func (w *compressResponseWriter) passThrough() error {
+	w.decided = true
+	if len(w.buf) == 0 {
+		return nil
+	}
+	pending := w.buf
+	w.buf = nil
+	_, err := w.ResponseWriter.Write(pending)
+	return err
+}
+
+// startCompression sets the encoding headers and installs the encoder.
+func (w *compressResponseWriter) startCompression() error {
+	w.decided = true
+	header := w.Header()
+	header.Set("Content-Encoding", w.encoding)
+	header.Add("Vary", "Accept-Encoding")
+	header.Del("Content-Length")
+	switch w.encoding {
+	case encodingBrotli:
+		encoder := brotli.NewWriterLevel(w.ResponseWriter, brotli.DefaultCompression)
+		w.encoder, w.flusher = encoder, encoder
+	default:
+		encoder, err := gzip.NewWriterLevel(w.ResponseWriter, gzip.DefaultCompression)
+		if err != nil {
+			return err
+		}
+		w.encoder, w.flusher = encoder, encoder
+	}
+	return nil
+}
+
+// finish completes the response after the handler chain returns.
+func (w *compressResponseWriter) finish() {
+	if !w.decided {
+		_ = w.passThrough()
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Close()
+		w.encoder = nil
+	}
+}
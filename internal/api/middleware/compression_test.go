@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+func newCompressionEngine() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(CompressionMiddleware(64))
+	engine.GET("/large", func(c *gin.Context) {
+		c.String(http.StatusOK, strings.Repeat("model-list ", 100))
+	})
+	engine.GET("/small", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	engine.GET("/stream", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		for i := 0; i < 20; i++ {
+			_, _ = c.Writer.WriteString("data: chunk chunk chunk chunk\n\n")
+			c.Writer.Flush()
+		}
+	})
+	return engine
+}
+
+func serveCompression(t *testing.T, engine *gin.Engine, path, acceptEncoding string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCompressionMiddlewareNegotiatesEncoding(t *testing.T) {
+	engine := newCompressionEngine()
+	want := strings.Repeat("model-list ", 100)
+
+	cases := []struct {
+		acceptEncoding string
+		encoding       string
+		decode         func(io.Reader) (io.Reader, error)
+	}{
+		{"gzip", "gzip", func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }},
+		{"gzip, br", "br", func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil }},
+		{"br;q=0, gzip;q=0.5", "gzip", func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }},
+	}
+	for _, tc := range cases {
+		rec := serveCompression(t, engine, "/large", tc.acceptEncoding)
+		if got := rec.Header().Get("Content-Encoding"); got != tc.encoding {
+			t.Fatalf("Accept-Encoding %q: Content-Encoding = %q, want %q", tc.acceptEncoding, got, tc.encoding)
+		}
+		if rec.Header().Get("Vary") != "Accept-Encoding" {
+			t.Fatalf("Accept-Encoding %q: missing Vary header", tc.acceptEncoding)
+		}
+		reader, err := tc.decode(rec.Body)
+		if err != nil {
+			t.Fatalf("Accept-Encoding %q: decoder: %v", tc.acceptEncoding, err)
+		}
+		body, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("Accept-Encoding %q: decode: %v", tc.acceptEncoding, err)
+		}
+		if string(body) != want {
+			t.Fatalf("Accept-Encoding %q: body mismatch after decoding", tc.acceptEncoding)
+		}
+	}
+}
+
+func TestCompressionMiddlewareBypass(t *testing.T) {
+	engine := newCompressionEngine()
+
+	cases := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+	}{
+		{"no accept-encoding", "/large", ""},
+		{"unsupported encoding", "/large", "deflate"},
+		{"below min size", "/small", "gzip, br"},
+		{"event stream", "/stream", "gzip, br"},
+	}
+	for _, tc := range cases {
+		rec := serveCompression(t, engine, tc.path, tc.acceptEncoding)
+		if got := rec.Header().Get("Content-Encoding"); got != "" {
+			t.Fatalf("%s: Content-Encoding = %q, want none", tc.name, got)
+		}
+		if rec.Body.Len() == 0 {
+			t.Fatalf("%s: empty body", tc.name)
+		}
+	}
+	if rec := serveCompression(t, engine, "/stream", "gzip"); !strings.HasPrefix(rec.Body.String(), "data: chunk") {
+		t.Fatalf("event stream body altered: %q", rec.Body.String())
+	}
+}
@@ -7,11 +7,15 @@ package api
 import (
 	"context"
 	"crypto/subtle"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -23,9 +27,14 @@ import (
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/api/middleware"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/api/modules"
 	ampmodule "github.com/router-for-me/CLIProxyAPI/v6/internal/api/modules/amp"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/archive"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/features"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/i18n"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/managementasset"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/replay"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/sampling"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
@@ -119,6 +128,16 @@ type Server struct {
 	// server is the underlying HTTP server.
 	server *http.Server
 
+	// mgmtEngine serves management routes when remote-management configures a dedicated listener.
+	// It is nil when management shares the data plane engine.
+	mgmtEngine *gin.Engine
+
+	// mgmtServer is the HTTP server backing mgmtEngine.
+	mgmtServer *http.Server
+
+	// grpcServer serves the Gemini gRPC ingress when grpc.enable is set.
+	grpcServer *http.Server
+
 	// handlers contains the API handlers for processing requests.
 	handlers *handlers.BaseAPIHandler
 
@@ -195,6 +214,7 @@ func NewServer(cfg *config.Config, authManager *auth.Manager, accessManager *sdk
 
 	// Create gin engine
 	engine := gin.New()
+	applyTrustedProxies(engine, cfg.TrustedProxies)
 	if optionState.engineConfigurator != nil {
 		optionState.engineConfigurator(engine)
 	}
@@ -205,6 +225,10 @@ func NewServer(cfg *config.Config, authManager *auth.Manager, accessManager *sdk
 	for _, mw := range optionState.extraMiddleware {
 		engine.Use(mw)
 	}
+	// Compression wraps the writer before request logging so logged bodies stay plaintext.
+	if cfg.ResponseCompression.Enable {
+		engine.Use(middleware.CompressionMiddleware(cfg.ResponseCompression.MinSize))
+	}
 
 	// Add request logging middleware (positioned after recovery, before auth)
 	// Resolve logs directory relative to the configuration file directory.
@@ -222,7 +246,6 @@ func NewServer(cfg *config.Config, authManager *auth.Manager, accessManager *sdk
 		}
 	}
 
-	engine.Use(corsMiddleware())
 	wd, err := os.Getwd()
 	if err != nil {
 		wd = configFilePath
@@ -246,6 +269,12 @@ func NewServer(cfg *config.Config, authManager *auth.Manager, accessManager *sdk
 		wsRoutes:            make(map[string]struct{}),
 	}
 	s.wsAuthEnabled.Store(cfg.WebsocketAuth)
+	engine.Use(s.corsMiddleware())
+	if cfg.RemoteManagement.DedicatedListener() {
+		s.mgmtEngine = gin.New()
+		applyTrustedProxies(s.mgmtEngine, cfg.TrustedProxies)
+		s.mgmtEngine.Use(logging.GinLogrusLogger(), logging.GinLogrusRecovery(), s.corsMiddleware())
+	}
 	// Save initial YAML snapshot
 	s.oldConfigYaml, _ = yaml.Marshal(cfg)
 	s.applyAccessConfig(nil, cfg)
@@ -254,6 +283,11 @@ func NewServer(cfg *config.Config, authManager *auth.Manager, accessManager *sdk
 	}
 	managementasset.SetCurrentConfig(cfg)
 	auth.SetQuotaCooldownDisabled(cfg.DisableCooling)
+	auth.SetQuotaHeadroomThreshold(cfg.Routing.QuotaThreshold)
+	i18n.SetDefault(i18n.ParseOr(cfg.Language, i18n.English))
+	s.configureReplayQueue(cfg)
+	s.configureSampling(cfg)
+	archive.Default().Configure(cfg.Archive)
 	// Initialize management handler
 	s.mgmt = managementHandlers.NewHandler(cfg, configFilePath, authManager)
 	if optionState.localPassword != "" {
@@ -299,17 +333,38 @@ func NewServer(cfg *config.Config, authManager *auth.Manager, accessManager *sdk
 
 	// Create HTTP server
 	s.server = &http.Server{
-		Addr:    fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
-		Handler: engine,
+		Addr:        util.JoinHostPort(cfg.Host, cfg.Port),
+		Handler:     engine,
+		ConnContext: s.dataPlaneConnContext,
+	}
+	if s.mgmtEngine != nil {
+		mgmtHost := cfg.RemoteManagement.Host
+		if mgmtHost == "" {
+			mgmtHost = util.LoopbackHost("", cfg.ListenFamily)
+		}
+		s.mgmtServer = &http.Server{
+			Addr:        util.JoinHostPort(mgmtHost, cfg.RemoteManagement.Port),
+			Handler:     s.mgmtEngine,
+			ConnContext: util.LocalConnContext,
+		}
 	}
+	s.setupGRPCServer()
 
 	return s
 }
 
+// managementEngine returns the engine that hosts management routes.
+func (s *Server) managementEngine() *gin.Engine {
+	if s.mgmtEngine != nil {
+		return s.mgmtEngine
+	}
+	return s.engine
+}
+
 // setupRoutes configures the API routes for the server.
 // It defines the endpoints and associates them with their respective handlers.
 func (s *Server) setupRoutes() {
-	s.engine.GET("/management.html", s.serveManagementControlPanel)
+	s.managementEngine().GET("/management.html", s.serveManagementControlPanel)
 	openaiHandlers := openai.NewOpenAIAPIHandler(s.handlers)
 	geminiHandlers := gemini.NewGeminiAPIHandler(s.handlers)
 	geminiCLIHandlers := gemini.NewGeminiCLIAPIHandler(s.handlers)
@@ -321,10 +376,18 @@ func (s *Server) setupRoutes() {
 	v1.Use(AuthMiddleware(s.accessManager))
 	{
 		v1.GET("/models", s.unifiedModelsHandler(openaiHandlers, claudeCodeHandlers))
+		v1.GET("/capabilities", openaiHandlers.Capabilities)
 		v1.POST("/chat/completions", openaiHandlers.ChatCompletions)
 		v1.POST("/completions", openaiHandlers.Completions)
+		v1.POST("/embeddings", openaiHandlers.Embeddings)
 		v1.POST("/messages", claudeCodeHandlers.ClaudeMessages)
 		v1.POST("/messages/count_tokens", claudeCodeHandlers.ClaudeCountTokens)
+		v1.POST("/messages/batches", claudeCodeHandlers.CreateMessageBatch)
+		v1.GET("/messages/batches", claudeCodeHandlers.ListMessageBatches)
+		v1.GET("/messages/batches/:id", claudeCodeHandlers.GetMessageBatch)
+		v1.DELETE("/messages/batches/:id", claudeCodeHandlers.DeleteMessageBatch)
+		v1.POST("/messages/batches/:id/cancel", claudeCodeHandlers.CancelMessageBatch)
+		v1.GET("/messages/batches/:id/results", claudeCodeHandlers.MessageBatchResults)
 		v1.POST("/responses", openaiResponsesHandlers.Responses)
 	}
 
@@ -349,6 +412,9 @@ func (s *Server) setupRoutes() {
 		})
 	})
 
+	// Health check endpoint for load balancers and orchestrators
+	s.engine.GET("/healthz", s.handleHealthz)
+
 	// Event logging endpoint - handles Claude Code telemetry requests
 	// Returns 200 OK to prevent 404 errors in logs
 	s.engine.POST("/api/event_logging/batch", func(c *gin.Context) {
@@ -493,13 +559,40 @@ func (s *Server) registerManagementRoutes() {
 
 	log.Info("management routes registered after secret key configuration")
 
-	mgmt := s.engine.Group("/v0/management")
-	mgmt.Use(s.managementAvailabilityMiddleware(), s.mgmt.Middleware())
+	mgmt := s.managementEngine().Group("/v0/management")
+	mgmt.Use(s.managementAvailabilityMiddleware(), s.mgmt.Middleware(), s.mgmt.CSRFMiddleware())
 	{
+		mgmt.GET("/csrf-token", s.mgmt.GetCSRFToken)
 		mgmt.GET("/usage", s.mgmt.GetUsageStatistics)
 		mgmt.GET("/usage/export", s.mgmt.ExportUsageStatistics)
 		mgmt.POST("/usage/import", s.mgmt.ImportUsageStatistics)
+		mgmt.GET("/budget", s.mgmt.GetBudget)
+		mgmt.GET("/maintenance", s.mgmt.GetMaintenance)
+		mgmt.PUT("/maintenance/:provider", s.mgmt.PutMaintenance)
+		mgmt.DELETE("/maintenance/:provider", s.mgmt.DeleteMaintenance)
+		mgmt.GET("/feature-flags", s.mgmt.GetFeatureFlags)
+		mgmt.PUT("/feature-flags/:name", s.mgmt.PutFeatureFlag)
+		mgmt.DELETE("/feature-flags/:name", s.mgmt.DeleteFeatureFlag)
+		mgmt.GET("/model-renames", s.mgmt.GetModelRenames)
+		mgmt.PUT("/model-renames/:model", s.mgmt.PutModelRename)
+		mgmt.DELETE("/model-renames/:model", s.mgmt.DeleteModelRename)
+		mgmt.GET("/conversations", s.mgmt.GetConversations)
+		mgmt.DELETE("/conversations/:id", s.mgmt.DeleteConversation)
+		mgmt.GET("/latency-slo", s.mgmt.GetLatencySLO)
+		mgmt.GET("/latency-slo/metrics", s.mgmt.GetLatencySLOMetrics)
+		mgmt.GET("/overloads", s.mgmt.GetOverloads)
+		mgmt.GET("/account-risk", s.mgmt.GetAccountRisk)
+		mgmt.GET("/providers/health", s.mgmt.GetProviderHealth)
+		mgmt.POST("/tokenize", s.mgmt.Tokenize)
+		mgmt.GET("/replay-queue", s.mgmt.ListReplayQueue)
+		mgmt.DELETE("/replay-queue/:id", s.mgmt.DeleteReplayEntry)
+		mgmt.POST("/replay-queue/replay", s.mgmt.ReplayQueued)
+		mgmt.GET("/samples", s.mgmt.ListSamples)
+		mgmt.GET("/samples/export", s.mgmt.ExportSamples)
+		mgmt.DELETE("/samples", s.mgmt.DeleteSamples)
+		mgmt.GET("/archive", s.mgmt.GetArchiveStats)
 		mgmt.GET("/config", s.mgmt.GetConfig)
+		mgmt.GET("/config/diff", s.mgmt.GetConfigDiff)
 		mgmt.GET("/config.yaml", s.mgmt.GetConfigYAML)
 		mgmt.PUT("/config.yaml", s.mgmt.PutConfigYAML)
 		mgmt.GET("/latest-version", s.mgmt.GetLatestVersion)
@@ -630,9 +723,14 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.GET("/auth-files/models", s.mgmt.GetAuthFileModels)
 		mgmt.GET("/auth-files/download", s.mgmt.DownloadAuthFile)
 		mgmt.GET("/auth-files/session-bindings", s.mgmt.GetAuthFileSessionBindings)
+		mgmt.GET("/session-bindings", s.mgmt.GetSessionBindings)
+		mgmt.PUT("/session-bindings", s.mgmt.PutSessionBinding)
+		mgmt.DELETE("/session-bindings", s.mgmt.DeleteSessionBinding)
+		mgmt.GET("/auth-files/needs-reauth", s.mgmt.GetAuthFilesNeedingReauth)
 		mgmt.POST("/auth-files", s.mgmt.UploadAuthFile)
 			mgmt.POST("/auth-files/codex-quota", s.mgmt.PostAuthFileCodexQuota)
 			mgmt.POST("/auth-files/kiro-quota", s.mgmt.PostAuthFileKiroQuota)
+			mgmt.POST("/auth-files/copilot-quota", s.mgmt.PostAuthFileCopilotQuota)
 		mgmt.PUT("/auth-files/disabled", s.mgmt.PutAuthFileDisabled)
 		mgmt.PUT("/auth-files/priority", s.mgmt.PutAuthFilePriority)
 		mgmt.DELETE("/auth-files", s.mgmt.DeleteAuthFile)
@@ -646,6 +744,7 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.GET("/iflow-auth-url", s.mgmt.RequestIFlowToken)
 		mgmt.POST("/iflow-auth-url", s.mgmt.RequestIFlowCookieToken)
 		mgmt.GET("/kiro-auth-url", s.mgmt.RequestKiroToken)
+		mgmt.GET("/github-copilot-auth-url", s.mgmt.RequestGitHubCopilotToken)
 		mgmt.POST("/oauth-callback", s.mgmt.PostOAuthCallback)
 		mgmt.GET("/get-auth-status", s.mgmt.GetAuthStatus)
 	}
@@ -704,6 +803,18 @@ func (s *Server) enableKeepAlive(timeout time.Duration, onTimeout func()) {
 	go s.watchKeepAlive()
 }
 
+// handleHealthz reports that the server is serving, with the feature flags in effect.
+func (s *Server) handleHealthz(c *gin.Context) {
+	flags := make(map[string]bool)
+	for _, flag := range features.Default().Snapshot() {
+		flags[flag.Name] = flag.Enabled
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "ok",
+		"details": gin.H{"feature_flags": flags},
+	})
+}
+
 func (s *Server) handleKeepAlive(c *gin.Context) {
 	if s.localPassword != "" {
 		provided := strings.TrimSpace(c.GetHeader("Authorization"))
@@ -795,6 +906,16 @@ func (s *Server) Start() error {
 		return fmt.Errorf("failed to start HTTP server: server not initialized")
 	}
 
+	if errMgmt := s.startManagementListeners(); errMgmt != nil {
+		return errMgmt
+	}
+	if errLocal := s.startLocalListeners(); errLocal != nil {
+		return errLocal
+	}
+	if errGRPC := s.startGRPCListener(); errGRPC != nil {
+		return errGRPC
+	}
+
 	useTLS := s.cfg != nil && s.cfg.TLS.Enable
 	if useTLS {
 		cert := strings.TrimSpace(s.cfg.TLS.Cert)
@@ -802,21 +923,73 @@ func (s *Server) Start() error {
 		if cert == "" || key == "" {
 			return fmt.Errorf("failed to start HTTPS server: tls.cert or tls.key is empty")
 		}
+		ln, errListen := s.listenTCP(s.server.Addr)
+		if errListen != nil {
+			return fmt.Errorf("failed to start HTTPS server: %w", errListen)
+		}
 		log.Debugf("Starting API server on %s with TLS", s.server.Addr)
-		if errServeTLS := s.server.ListenAndServeTLS(cert, key); errServeTLS != nil && !errors.Is(errServeTLS, http.ErrServerClosed) {
-			return fmt.Errorf("failed to start HTTPS server: %v", errServeTLS)
+		if errServeTLS := s.server.ServeTLS(ln, cert, key); errServeTLS != nil && !errors.Is(errServeTLS, http.ErrServerClosed) {
+			return fmt.Errorf("failed to start HTTPS server: %w", errServeTLS)
 		}
 		return nil
 	}
 
+	ln, errListen := s.listenTCP(s.server.Addr)
+	if errListen != nil {
+		return fmt.Errorf("failed to start HTTP server: %w", errListen)
+	}
 	log.Debugf("Starting API server on %s", s.server.Addr)
-	if errServe := s.server.ListenAndServe(); errServe != nil && !errors.Is(errServe, http.ErrServerClosed) {
-		return fmt.Errorf("failed to start HTTP server: %v", errServe)
+	if errServe := s.server.Serve(ln); errServe != nil && !errors.Is(errServe, http.ErrServerClosed) {
+		return fmt.Errorf("failed to start HTTP server: %w", errServe)
 	}
 
 	return nil
 }
 
+// startManagementListeners binds the dedicated management TCP and unix socket listeners, if configured.
+// Binding happens synchronously so address conflicts surface before the data plane starts.
+func (s *Server) startManagementListeners() error {
+	if s.mgmtServer == nil || s.cfg == nil {
+		return nil
+	}
+	listeners := make([]net.Listener, 0, 2)
+	if s.cfg.RemoteManagement.Port > 0 {
+		ln, err := s.listenTCP(s.mgmtServer.Addr)
+		if err != nil {
+			return fmt.Errorf("failed to start management server: %w", err)
+		}
+		if s.cfg.TLS.Enable {
+			certificate, errCert := tls.LoadX509KeyPair(strings.TrimSpace(s.cfg.TLS.Cert), strings.TrimSpace(s.cfg.TLS.Key))
+			if errCert != nil {
+				_ = ln.Close()
+				return fmt.Errorf("failed to start management server: %v", errCert)
+			}
+			ln = tls.NewListener(ln, &tls.Config{Certificates: []tls.Certificate{certificate}})
+		}
+		log.Infof("management API listening on %s", s.mgmtServer.Addr)
+		listeners = append(listeners, ln)
+	}
+	if socketPath := s.cfg.RemoteManagement.UnixSocket; socketPath != "" {
+		ln, err := listenUnix(socketPath)
+		if err != nil {
+			for _, opened := range listeners {
+				_ = opened.Close()
+			}
+			return fmt.Errorf("failed to start management server: %w", err)
+		}
+		log.Infof("management API listening on unix socket %s", socketPath)
+		listeners = append(listeners, ln)
+	}
+	for _, ln := range listeners {
+		go func(ln net.Listener) {
+			if errServe := s.mgmtServer.Serve(ln); errServe != nil && !errors.Is(errServe, http.ErrServerClosed) {
+				log.Errorf("management server stopped: %v", errServe)
+			}
+		}(ln)
+	}
+	return nil
+}
+
 // Stop gracefully shuts down the API server without interrupting any
 // active connections.
 //
@@ -835,25 +1008,45 @@ func (s *Server) Stop(ctx context.Context) error {
 		}
 	}
 
+	if s.mgmtServer != nil {
+		if err := s.mgmtServer.Shutdown(ctx); err != nil {
+			log.Errorf("failed to shutdown management server: %v", err)
+		}
+	}
+	if s.grpcServer != nil {
+		if err := s.grpcServer.Shutdown(ctx); err != nil {
+			log.Errorf("failed to shutdown gRPC server: %v", err)
+		}
+	}
+
 	// Shutdown the HTTP server.
 	if err := s.server.Shutdown(ctx); err != nil {
 		return fmt.Errorf("failed to shutdown HTTP server: %v", err)
 	}
+	if err := archive.Default().Close(ctx); err != nil {
+		log.Errorf("failed to flush conversation archive: %v", err)
+	}
 
 	log.Debug("API server stopped")
 	return nil
 }
 
 // corsMiddleware returns a Gin middleware handler that adds CORS headers
-// to every response, allowing cross-origin requests.
+// to every response, allowing cross-origin requests. Management routes follow
+// the remote-management.allowed-origins policy instead of the wildcard.
 //
 // Returns:
 //   - gin.HandlerFunc: The CORS middleware handler
-func corsMiddleware() gin.HandlerFunc {
+func (s *Server) corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "*")
+		if strings.HasPrefix(c.Request.URL.Path, "/v0/management") && len(s.cfg.RemoteManagement.AllowedOrigins) > 0 {
+			if !applyManagementCORS(c, s.cfg.RemoteManagement.AllowedOrigins) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "origin not allowed"})
+				return
+			}
+		} else {
+			applyWildcardCORS(c)
+		}
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusNoContent)
@@ -864,6 +1057,77 @@ func corsMiddleware() gin.HandlerFunc {
 	}
 }
 
+// applyWildcardCORS lets any origin call without credentials.
+func applyWildcardCORS(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+	c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+	c.Header("Access-Control-Allow-Headers", "*")
+}
+
+// applyManagementCORS sets credentialed CORS headers for an explicitly allowed or same-origin
+// caller. A "*" entry only grants other origins the uncredentialed wildcard policy, since
+// credentials must never be combined with any origin. It reports false for a cross-origin
+// preflight from an origin that is not allowed; other requests from such origins proceed without
+// CORS headers, so browsers withhold the response.
+func applyManagementCORS(c *gin.Context, allowed []string) bool {
+	origin := strings.TrimSpace(c.GetHeader("Origin"))
+	c.Header("Vary", "Origin")
+	if origin == "" {
+		return true
+	}
+	if !originAllowed(origin, c.Request.Host, allowed) {
+		if slices.Contains(allowed, "*") {
+			applyWildcardCORS(c)
+			return true
+		}
+		return c.Request.Method != http.MethodOptions
+	}
+	c.Header("Access-Control-Allow-Origin", origin)
+	c.Header("Access-Control-Allow-Credentials", "true")
+	c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+	c.Header("Access-Control-Expose-Headers", "X-CSRF-Token, X-CPA-VERSION, X-CPA-COMMIT, X-CPA-BUILD-DATE")
+	if requested := c.GetHeader("Access-Control-Request-Headers"); requested != "" {
+		c.Header("Access-Control-Allow-Headers", requested)
+	} else {
+		c.Header("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Management-Key, X-CSRF-Token")
+	}
+	c.Header("Access-Control-Max-Age", "600")
+	return true
+}
+
+// originAllowed matches origin against the explicit origins of the configured list, accepting the
+// embedded panel's own origin. A "*" entry matches nothing here.
+func originAllowed(origin, host string, allowed []string) bool {
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, host) {
+		return true
+	}
+	for _, candidate := range allowed {
+		candidate = strings.TrimRight(strings.TrimSpace(candidate), "/")
+		if strings.EqualFold(candidate, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyTrustedProxies limits which peers may set the client IP through forwarding headers.
+// Invalid entries disable forwarding headers altogether rather than trusting everyone.
+func applyTrustedProxies(engine *gin.Engine, proxies []string) {
+	trusted := make([]string, 0, len(proxies))
+	for _, proxy := range proxies {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trusted = append(trusted, proxy)
+		}
+	}
+	if len(trusted) == 0 {
+		trusted = nil
+	}
+	if err := engine.SetTrustedProxies(trusted); err != nil {
+		log.Errorf("invalid trusted-proxies, ignoring forwarding headers: %v", err)
+		_ = engine.SetTrustedProxies(nil)
+	}
+}
+
 func (s *Server) applyAccessConfig(oldCfg, newCfg *config.Config) {
 	if s == nil || s.accessManager == nil || newCfg == nil {
 		return
@@ -873,6 +1137,42 @@ func (s *Server) applyAccessConfig(oldCfg, newCfg *config.Config) {
 	}
 }
 
+// configureReplayQueue applies the replay queue settings and points its executor at the
+// server's handlers. Entries are stored in the server's data directory unless a directory is
+// configured.
+func (s *Server) configureReplayQueue(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	queue := replay.Default()
+	queue.Configure(cfg.ReplayQueue, s.dataDirectory())
+	if s.handlers != nil {
+		queue.SetExecutor(s.handlers.ExecuteReplay)
+	}
+}
+
+// configureSampling applies the prompt/response sampling settings. Samples are stored in the
+// server's data directory unless an explicit directory is configured.
+func (s *Server) configureSampling(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	sampling.Default().Configure(cfg.Sampling, s.dataDirectory())
+}
+
+// dataDirectory returns where runtime data such as samples and replay entries is kept by default: WRITABLE_PATH when
+// set, the directory of the config file otherwise. It is never the auth directory, whose files the
+// token stores load and sync as credentials.
+func (s *Server) dataDirectory() string {
+	if base := util.WritablePath(); base != "" {
+		return base
+	}
+	if s.configFilePath != "" {
+		return filepath.Dir(s.configFilePath)
+	}
+	return s.currentPath
+}
+
 // UpdateClients updates the server's client list and configuration.
 // This method is called when the configuration or authentication tokens change.
 //
@@ -930,6 +1230,14 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 		}
 	}
 
+	if oldCfg == nil || oldCfg.Routing.QuotaThreshold != cfg.Routing.QuotaThreshold {
+		auth.SetQuotaHeadroomThreshold(cfg.Routing.QuotaThreshold)
+	}
+
+	if oldCfg == nil || oldCfg.Language != cfg.Language {
+		i18n.SetDefault(i18n.ParseOr(cfg.Language, i18n.English))
+	}
+
 	if oldCfg == nil || oldCfg.DisableCooling != cfg.DisableCooling {
 		auth.SetQuotaCooldownDisabled(cfg.DisableCooling)
 		if oldCfg != nil {
@@ -995,6 +1303,9 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 	s.oldConfigYaml, _ = yaml.Marshal(cfg)
 
 	s.handlers.UpdateClients(&cfg.SDKConfig)
+	s.configureReplayQueue(cfg)
+	s.configureSampling(cfg)
+	archive.Default().Configure(cfg.Archive)
 
 	if !cfg.RemoteManagement.DisableControlPanel {
 		staticDir := managementasset.StaticDir(s.configFilePath)
@@ -1062,6 +1373,13 @@ func AuthMiddleware(manager *sdkaccess.Manager) gin.HandlerFunc {
 			return
 		}
 
+		if localAuthExempt(c.Request.Context()) {
+			c.Set("apiKey", localListenerPrincipal)
+			c.Set("accessProvider", localListenerPrincipal)
+			c.Next()
+			return
+		}
+
 		result, err := manager.Authenticate(c.Request.Context(), c.Request)
 		if err == nil {
 			if result != nil {
@@ -1075,14 +1393,15 @@ func AuthMiddleware(manager *sdkaccess.Manager) gin.HandlerFunc {
 			return
 		}
 
+		lang := i18n.FromAcceptLanguage(c.GetHeader("Accept-Language"), i18n.Default())
 		switch {
 		case errors.Is(err, sdkaccess.ErrNoCredentials):
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing API key"})
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": i18n.T(lang, i18n.MsgMissingAPIKey)})
 		case errors.Is(err, sdkaccess.ErrInvalidCredential):
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": i18n.T(lang, i18n.MsgInvalidAPIKey)})
 		default:
 			log.Errorf("authentication middleware error: %v", err)
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Authentication service error"})
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": i18n.T(lang, i18n.MsgAuthServiceError)})
 		}
 	}
 }
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -10,9 +11,11 @@ import (
 
 	gin "github.com/gin-gonic/gin"
 	proxyconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/features"
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func newTestServer(t *testing.T) *Server {
@@ -109,3 +112,142 @@ func TestAmpProviderModelRoutes(t *testing.T) {
 		})
 	}
 }
+
+func TestManagementRoutesMoveToDedicatedListener(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	secretHash, err := bcrypt.GenerateFromPassword([]byte("mgmt-secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to hash secret: %v", err)
+	}
+	tmpDir := t.TempDir()
+	cfg := &proxyconfig.Config{
+		SDKConfig: sdkconfig.SDKConfig{APIKeys: []string{"test-key"}},
+		AuthDir:   tmpDir,
+		RemoteManagement: proxyconfig.RemoteManagement{
+			SecretKey: string(secretHash),
+			Port:      18318,
+		},
+	}
+	server := NewServer(cfg, auth.NewManager(nil, nil, nil), sdkaccess.NewManager(), filepath.Join(tmpDir, "config.yaml"))
+	if server.mgmtServer == nil {
+		t.Fatal("expected dedicated management server")
+	}
+	if server.mgmtServer.Addr != "127.0.0.1:18318" {
+		t.Fatalf("unexpected management address %q", server.mgmtServer.Addr)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v0/management/config", nil)
+	req.Header.Set("Authorization", "Bearer mgmt-secret")
+	rr := httptest.NewRecorder()
+	server.engine.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("data plane status = %d, want %d", rr.Code, http.StatusNotFound)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v0/management/config", nil)
+	req.Header.Set("Authorization", "Bearer mgmt-secret")
+	req.RemoteAddr = "127.0.0.1:40000"
+	rr = httptest.NewRecorder()
+	server.mgmtEngine.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("management listener status = %d, want %d; body=%s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+}
+
+func TestListenAddressesBracketIPv6(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	secretHash, err := bcrypt.GenerateFromPassword([]byte("mgmt-secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to hash secret: %v", err)
+	}
+	tmpDir := t.TempDir()
+	cfg := &proxyconfig.Config{
+		SDKConfig:    sdkconfig.SDKConfig{APIKeys: []string{"test-key"}},
+		Host:         "[::1]",
+		Port:         8317,
+		ListenFamily: "ipv6",
+		AuthDir:      tmpDir,
+		RemoteManagement: proxyconfig.RemoteManagement{
+			SecretKey: string(secretHash),
+			Port:      18318,
+		},
+	}
+	server := NewServer(cfg, auth.NewManager(nil, nil, nil), sdkaccess.NewManager(), filepath.Join(tmpDir, "config.yaml"))
+	if server.server.Addr != "[::1]:8317" {
+		t.Fatalf("unexpected API address %q", server.server.Addr)
+	}
+	if server.mgmtServer.Addr != "[::1]:18318" {
+		t.Fatalf("management must default to the IPv6 loopback, got %q", server.mgmtServer.Addr)
+	}
+
+	cfg.ListenFamily = "ipv5"
+	if _, err := server.listenTCP("[::1]:0"); err == nil || !strings.Contains(err.Error(), "listen-family") {
+		t.Fatalf("an unknown listen-family must be rejected, got %v", err)
+	}
+	cfg.ListenFamily = "ipv4"
+	if ln, err := server.listenTCP("127.0.0.1:0"); err == nil {
+		_ = ln.Close()
+	} else {
+		t.Skipf("IPv4 loopback unavailable: %v", err)
+	}
+	if ln, err := server.listenTCP("[::1]:0"); err == nil {
+		_ = ln.Close()
+		t.Fatal("an ipv4 listen-family must not bind IPv6 addresses")
+	}
+}
+
+func TestTrustedProxiesControlClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	clientIP := func(proxies []string, remoteAddr string) string {
+		engine := gin.New()
+		applyTrustedProxies(engine, proxies)
+		engine.GET("/ip", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
+		req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		rr := httptest.NewRecorder()
+		engine.ServeHTTP(rr, req)
+		return rr.Body.String()
+	}
+
+	if got := clientIP(nil, "127.0.0.1:4000"); got != "127.0.0.1" {
+		t.Fatalf("without trusted proxies client IP = %s, want peer address", got)
+	}
+	if got := clientIP([]string{"10.0.0.0/8"}, "10.1.2.3:4000"); got != "203.0.113.7" {
+		t.Fatalf("trusted hop client IP = %s, want forwarded address", got)
+	}
+	if got := clientIP([]string{"10.0.0.0/8"}, "198.51.100.1:4000"); got != "198.51.100.1" {
+		t.Fatalf("untrusted hop client IP = %s, want peer address", got)
+	}
+	if got := clientIP([]string{"not-an-ip"}, "10.1.2.3:4000"); got != "10.1.2.3" {
+		t.Fatalf("invalid config client IP = %s, want peer address", got)
+	}
+}
+
+func TestHealthzReportsFeatureFlags(t *testing.T) {
+	server := newTestServer(t)
+	features.Default().SetOverride(features.ThinkingExtraction, false)
+	t.Cleanup(func() { features.Default().ClearOverride(features.ThinkingExtraction) })
+
+	rr := httptest.NewRecorder()
+	server.engine.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rr.Code, rr.Body.String())
+	}
+	var body struct {
+		Status  string `json:"status"`
+		Details struct {
+			FeatureFlags map[string]bool `json:"feature_flags"`
+		} `json:"details"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	flags := body.Details.FeatureFlags
+	if body.Status != "ok" || flags[features.ThinkingExtraction] || !flags[features.CrossProviderFailover] {
+		t.Fatalf("unexpected healthz body: %s", rr.Body.String())
+	}
+}
@@ -0,0 +1,412 @@
+// Package archive uploads completed request/response exchanges to S3-compatible object storage
+// for compliance. The request path only queues records; a background worker hashes or redacts
+// them according to the configured content policy, batches them into JSON Lines objects and
+// uploads each batch with retries, so storage latency and outages never delay responses.
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// ContentMetadata archives the hashes of the request and response without their text.
+	ContentMetadata = "metadata"
+	// ContentFull archives the request and response text with credentials redacted.
+	ContentFull = "full"
+
+	defaultBatchSize     = 100
+	defaultFlushInterval = time.Minute
+	defaultQueueSize     = 10000
+	defaultMaxRetries    = 5
+	uploadTimeout        = time.Minute
+	maxRetryDelay        = time.Minute
+)
+
+// Record is one archived exchange.
+type Record struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	// ConversationID groups the exchanges of one conversation; empty when the request carries no
+	// session key.
+	ConversationID string `json:"conversation_id,omitempty"`
+	// APIKey is the masked client API key.
+	APIKey      string `json:"api_key,omitempty"`
+	HandlerType string `json:"handler_type"`
+	// Model is the model the client requested; UpstreamModel is the one sent to the provider.
+	Model          string          `json:"model"`
+	UpstreamModel  string          `json:"upstream_model,omitempty"`
+	Provider       string          `json:"provider,omitempty"`
+	AuthIndex      string          `json:"auth_index,omitempty"`
+	Stream         bool            `json:"stream"`
+	LatencyMS      int64           `json:"latency_ms"`
+	RequestSHA256  string          `json:"request_sha256"`
+	ResponseSHA256 string          `json:"response_sha256"`
+	Request        json.RawMessage `json:"request,omitempty"`
+	Response       json.RawMessage `json:"response,omitempty"`
+}
+
+// Uploader stores one archive object.
+type Uploader interface {
+	Upload(ctx context.Context, key string, data []byte) error
+}
+
+// Stats counts the records handled since the process started.
+type Stats struct {
+	Enabled  bool  `json:"enabled"`
+	Queued   int   `json:"queued"`
+	Uploaded int64 `json:"uploaded"`
+	Dropped  int64 `json:"dropped"`
+	Failed   int64 `json:"failed"`
+}
+
+// Archiver queues records and runs the worker that uploads them.
+type Archiver struct {
+	mu      sync.RWMutex
+	cfg     config.ArchiveConfig
+	keys    map[string]struct{}
+	current *worker
+
+	// newUploader creates the uploader of a configuration; tests replace it.
+	newUploader func(cfg config.ArchiveConfig) (Uploader, error)
+	// retryDelay is the delay before the first retry, doubled for every further retry.
+	retryDelay time.Duration
+
+	uploaded atomic.Int64
+	dropped  atomic.Int64
+	failed   atomic.Int64
+}
+
+var defaultArchiver = &Archiver{newUploader: newS3Uploader, retryDelay: time.Second}
+
+// Default returns the process-wide archiver.
+func Default() *Archiver { return defaultArchiver }
+
+// Configure applies cfg. A change of the storage, content or batching settings starts a new
+// worker; the previous one uploads what it already queued in the background and exits.
+func (a *Archiver) Configure(cfg config.ArchiveConfig) {
+	if a == nil {
+		return
+	}
+	keys := make(map[string]struct{}, len(cfg.APIKeys))
+	for _, key := range cfg.APIKeys {
+		if key = strings.TrimSpace(key); key != "" {
+			keys[key] = struct{}{}
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.keys = keys
+	if a.current != nil && sameWorkerSettings(a.cfg, cfg) {
+		a.cfg = cfg
+		return
+	}
+	previous := a.current
+	a.current = nil
+	a.cfg = cfg
+	if previous != nil {
+		previous.stop()
+	}
+	if !cfg.Enabled {
+		return
+	}
+	uploader, err := a.newUploader(cfg)
+	if err != nil {
+		log.Errorf("archive: disabled: %v", err)
+		return
+	}
+	a.current = a.startWorker(cfg, uploader)
+	log.Infof("archive: uploading %s records to bucket %s", contentPolicy(cfg.Content), cfg.Bucket)
+}
+
+// Enabled reports whether requests authenticated with apiKey are archived.
+func (a *Archiver) Enabled(apiKey string) bool {
+	if a == nil {
+		return false
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.current == nil {
+		return false
+	}
+	if len(a.keys) == 0 {
+		return true
+	}
+	_, ok := a.keys[strings.TrimSpace(apiKey)]
+	return ok
+}
+
+// Enqueue queues record for upload, assigning its ID and creation time. Request and Response
+// hold the raw bodies. Unless the content policy is full, they are hashed and dropped here so the
+// queue never holds them; otherwise the worker redacts them. A full queue drops the record
+// instead of blocking the caller.
+func (a *Archiver) Enqueue(record Record) {
+	if a == nil {
+		return
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		id = uuid.New()
+	}
+	record.ID = id.String()
+	record.CreatedAt = time.Now().UTC()
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.current == nil {
+		return
+	}
+	if !a.current.full {
+		record.RequestSHA256 = digest(record.Request)
+		record.ResponseSHA256 = digest(record.Response)
+		record.Request, record.Response = nil, nil
+	}
+	select {
+	case a.current.queue <- record:
+	default:
+		if dropped := a.dropped.Add(1); dropped == 1 || dropped%1000 == 0 {
+			log.Warnf("archive: queue full, %d records dropped so far", dropped)
+		}
+	}
+}
+
+// Stats returns the record counters and the current queue length.
+func (a *Archiver) Stats() Stats {
+	if a == nil {
+		return Stats{}
+	}
+	a.mu.RLock()
+	stats := Stats{Enabled: a.current != nil}
+	if a.current != nil {
+		stats.Queued = len(a.current.queue)
+	}
+	a.mu.RUnlock()
+	stats.Uploaded = a.uploaded.Load()
+	stats.Dropped = a.dropped.Load()
+	stats.Failed = a.failed.Load()
+	return stats
+}
+
+// Close stops the worker after it uploaded the queued records, or when ctx ends, whichever comes
+// first. Records enqueued afterwards are ignored.
+func (a *Archiver) Close(ctx context.Context) error {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	current := a.current
+	a.current = nil
+	a.mu.Unlock()
+	if current == nil {
+		return nil
+	}
+	current.stop()
+	select {
+	case <-current.done:
+		return nil
+	case <-ctx.Done():
+		current.cancel()
+		<-current.done
+		return fmt.Errorf("archive: queued records not uploaded: %w", ctx.Err())
+	}
+}
+
+// sameWorkerSettings reports whether a and b can share a worker.
+func sameWorkerSettings(a, b config.ArchiveConfig) bool {
+	return a.Enabled == b.Enabled && a.Content == b.Content && a.Endpoint == b.Endpoint &&
+		a.Bucket == b.Bucket && a.Prefix == b.Prefix && a.Region == b.Region &&
+		a.AccessKey == b.AccessKey && a.SecretKey == b.SecretKey && a.PathStyle == b.PathStyle &&
+		a.BatchSize == b.BatchSize && a.FlushIntervalSeconds == b.FlushIntervalSeconds &&
+		a.QueueSize == b.QueueSize && a.MaxRetries == b.MaxRetries
+}
+
+func contentPolicy(content string) string {
+	if strings.EqualFold(strings.TrimSpace(content), ContentFull) {
+		return ContentFull
+	}
+	return ContentMetadata
+}
+
+// worker batches and uploads the records of one configuration.
+type worker struct {
+	archiver      *Archiver
+	uploader      Uploader
+	full          bool
+	prefix        string
+	batchSize     int
+	flushInterval time.Duration
+	maxRetries    int
+
+	queue    chan Record
+	quit     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+func (a *Archiver) startWorker(cfg config.ArchiveConfig, uploader Uploader) *worker {
+	w := &worker{
+		archiver:      a,
+		uploader:      uploader,
+		full:          contentPolicy(cfg.Content) == ContentFull,
+		prefix:        strings.Trim(strings.TrimSpace(cfg.Prefix), "/"),
+		batchSize:     cfg.BatchSize,
+		flushInterval: time.Duration(cfg.FlushIntervalSeconds) * time.Second,
+		maxRetries:    cfg.MaxRetries,
+		quit:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	if w.batchSize <= 0 {
+		w.batchSize = defaultBatchSize
+	}
+	if w.flushInterval <= 0 {
+		w.flushInterval = defaultFlushInterval
+	}
+	if w.maxRetries <= 0 {
+		w.maxRetries = defaultMaxRetries
+	}
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+	w.queue = make(chan Record, queueSize)
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	go w.run()
+	return w
+}
+
+// stop asks the worker to upload what is queued and exit.
+func (w *worker) stop() {
+	w.stopOnce.Do(func() { close(w.quit) })
+}
+
+func (w *worker) run() {
+	defer close(w.done)
+	defer w.cancel()
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+	batch := make([]Record, 0, w.batchSize)
+	flush := func() {
+		if len(batch) > 0 {
+			w.upload(batch)
+			batch = make([]Record, 0, w.batchSize)
+		}
+	}
+	for {
+		select {
+		case record := <-w.queue:
+			batch = append(batch, record)
+			if len(batch) >= w.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-w.quit:
+			for {
+				select {
+				case record := <-w.queue:
+					batch = append(batch, record)
+					if len(batch) >= w.batchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// upload stores batch as one object, retrying with exponential backoff.
+func (w *worker) upload(batch []Record) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, record := range batch {
+		if err := encoder.Encode(w.prepare(record)); err != nil {
+			log.Errorf("archive: encode record %s: %v", record.ID, err)
+		}
+	}
+	key := objectKey(w.prefix, batch[0])
+
+	delay := w.archiver.retryDelay
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(w.ctx, uploadTimeout)
+		err := w.uploader.Upload(ctx, key, buf.Bytes())
+		cancel()
+		if err == nil {
+			w.archiver.uploaded.Add(int64(len(batch)))
+			return
+		}
+		if attempt >= w.maxRetries || w.ctx.Err() != nil {
+			w.archiver.failed.Add(int64(len(batch)))
+			log.Errorf("archive: dropped %d records after %d attempts to upload %s: %v", len(batch), attempt+1, key, err)
+			return
+		}
+		log.Warnf("archive: upload of %s failed, retrying in %s: %v", key, delay, err)
+		select {
+		case <-time.After(delay):
+		case <-w.ctx.Done():
+		}
+		delay = min(delay*2, maxRetryDelay)
+	}
+}
+
+// prepare masks the API key of record and, under the full content policy, hashes and redacts
+// the bodies. Under the metadata policy Enqueue already replaced them with their hashes.
+func (w *worker) prepare(record Record) Record {
+	record.APIKey = util.HideAPIKey(record.APIKey)
+	if !w.full {
+		return record
+	}
+	record.RequestSHA256 = digest(record.Request)
+	record.ResponseSHA256 = digest(record.Response)
+	record.Request = redact(record.Request)
+	record.Response = redact(record.Response)
+	return record
+}
+
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// redact masks credentials in a body. The result is the redacted JSON, or a JSON string holding
+// the redacted text when data is not JSON (such as a captured event stream).
+func redact(data []byte) json.RawMessage {
+	if len(data) == 0 {
+		return nil
+	}
+	text := logging.RedactSecrets(string(data))
+	if json.Valid([]byte(text)) {
+		return json.RawMessage(text)
+	}
+	raw, _ := json.Marshal(text)
+	return raw
+}
+
+// objectKey names the object of a batch after the day and ID of its first record.
+func objectKey(prefix string, first Record) string {
+	name := first.CreatedAt.Format("2006/01/02") + "/" + first.ID + ".jsonl"
+	if prefix == "" {
+		return name
+	}
+	return path.Join(prefix, name)
+}
@@ -0,0 +1,233 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+type fakeUploader struct {
+	mu       sync.Mutex
+	failures int
+	objects  map[string][]byte
+	attempts int
+}
+
+func (u *fakeUploader) Upload(_ context.Context, key string, data []byte) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.attempts++
+	if u.failures > 0 {
+		u.failures--
+		return errors.New("storage unavailable")
+	}
+	if u.objects == nil {
+		u.objects = make(map[string][]byte)
+	}
+	u.objects[key] = bytes.Clone(data)
+	return nil
+}
+
+func (u *fakeUploader) records(t *testing.T) (map[string]int, []Record) {
+	t.Helper()
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	perObject := make(map[string]int, len(u.objects))
+	var records []Record
+	for key, data := range u.objects {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			var record Record
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				t.Fatalf("object %s holds invalid JSON: %v", key, err)
+			}
+			perObject[key]++
+			records = append(records, record)
+		}
+	}
+	return perObject, records
+}
+
+func newTestArchiver(uploader *fakeUploader) *Archiver {
+	return &Archiver{
+		newUploader: func(config.ArchiveConfig) (Uploader, error) { return uploader, nil },
+		retryDelay:  time.Millisecond,
+	}
+}
+
+func TestArchiverBatchesAndAppliesContentPolicy(t *testing.T) {
+	uploader := &fakeUploader{}
+	archiver := newTestArchiver(uploader)
+	archiver.Configure(config.ArchiveConfig{Enabled: true, Prefix: "/compliance/", BatchSize: 2, FlushIntervalSeconds: 3600})
+	for i := 0; i < 3; i++ {
+		archiver.Enqueue(Record{
+			ConversationID: "conv-1",
+			APIKey:         "sk-client-secret-key",
+			Model:          "gpt-4o",
+			Request:        []byte(`{"messages":[{"role":"user","content":"hi"}]}`),
+			Response:       []byte(`{"choices":[]}`),
+		})
+	}
+	if err := archiver.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	perObject, records := uploader.records(t)
+	if len(perObject) != 2 || len(records) != 3 {
+		t.Fatalf("expected 3 records in 2 objects, got %v", perObject)
+	}
+	for key := range perObject {
+		if !strings.HasPrefix(key, "compliance/") || !strings.HasSuffix(key, ".jsonl") {
+			t.Fatalf("unexpected object key %q", key)
+		}
+	}
+	for _, record := range records {
+		if record.ID == "" || record.ConversationID != "conv-1" || record.CreatedAt.IsZero() {
+			t.Fatalf("record is missing its identity: %+v", record)
+		}
+		if len(record.RequestSHA256) != 64 || len(record.ResponseSHA256) != 64 {
+			t.Fatalf("metadata records must carry body hashes: %+v", record)
+		}
+		if record.Request != nil || record.Response != nil {
+			t.Fatalf("metadata records must not carry bodies: %+v", record)
+		}
+		if record.APIKey == "sk-client-secret-key" {
+			t.Fatal("the client API key must be masked")
+		}
+	}
+	if stats := archiver.Stats(); stats.Uploaded != 3 || stats.Enabled {
+		t.Fatalf("unexpected stats after close: %+v", stats)
+	}
+}
+
+func TestArchiverFullContentRedactsCredentials(t *testing.T) {
+	uploader := &fakeUploader{}
+	archiver := newTestArchiver(uploader)
+	archiver.Configure(config.ArchiveConfig{Enabled: true, Content: "full"})
+	archiver.Enqueue(Record{
+		Request:  []byte(`{"api_key":"sk-ant-REDACTED","prompt":"hello"}`),
+		Response: []byte("data: {\"text\":\"hi\"}\n\n"),
+	})
+	if err := archiver.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	_, records := uploader.records(t)
+	if len(records) != 1 {
+		t.Fatalf("expected one record, got %d", len(records))
+	}
+	record := records[0]
+	if !strings.Contains(string(record.Request), "hello") || strings.Contains(string(record.Request), "abcdefghijklmnopqrstuvwxyz") {
+		t.Fatalf("full records keep the text with credentials redacted, got %s", record.Request)
+	}
+	var streamed string
+	if err := json.Unmarshal(record.Response, &streamed); err != nil || !strings.HasPrefix(streamed, "data: ") {
+		t.Fatalf("non-JSON bodies are archived as strings, got %s", record.Response)
+	}
+}
+
+func TestArchiverRetriesFailedUploads(t *testing.T) {
+	uploader := &fakeUploader{failures: 2}
+	archiver := newTestArchiver(uploader)
+	archiver.Configure(config.ArchiveConfig{Enabled: true, MaxRetries: 3})
+	archiver.Enqueue(Record{Model: "gpt-4o"})
+	if err := archiver.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if stats := archiver.Stats(); stats.Uploaded != 1 || stats.Failed != 0 || uploader.attempts != 3 {
+		t.Fatalf("upload must succeed on the third attempt, stats %+v after %d attempts", stats, uploader.attempts)
+	}
+
+	uploader = &fakeUploader{failures: 10}
+	archiver = newTestArchiver(uploader)
+	archiver.Configure(config.ArchiveConfig{Enabled: true, MaxRetries: 1})
+	archiver.Enqueue(Record{Model: "gpt-4o"})
+	if err := archiver.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if stats := archiver.Stats(); stats.Failed != 1 || uploader.attempts != 2 {
+		t.Fatalf("batch must be dropped after the retries, stats %+v after %d attempts", stats, uploader.attempts)
+	}
+}
+
+func TestArchiverEnabledAndQueueLimit(t *testing.T) {
+	archiver := newTestArchiver(&fakeUploader{})
+	if archiver.Enabled("key") {
+		t.Fatal("an unconfigured archiver must be disabled")
+	}
+	archiver.Configure(config.ArchiveConfig{Enabled: true, APIKeys: []string{" audited "}})
+	if !archiver.Enabled("audited") || archiver.Enabled("other") {
+		t.Fatal("api-keys must limit archival to the listed keys")
+	}
+	archiver.Configure(config.ArchiveConfig{Enabled: true})
+	if !archiver.Enabled("other") {
+		t.Fatal("without api-keys every request is archived")
+	}
+	archiver.Configure(config.ArchiveConfig{})
+	if archiver.Enabled("other") {
+		t.Fatal("disabling the archive must stop archival")
+	}
+
+	blocked := make(chan struct{})
+	archiver = &Archiver{
+		newUploader: func(config.ArchiveConfig) (Uploader, error) { return blockingUploader(blocked), nil },
+		retryDelay:  time.Millisecond,
+	}
+	archiver.Configure(config.ArchiveConfig{Enabled: true, BatchSize: 1, QueueSize: 1})
+	for i := 0; i < 5; i++ {
+		archiver.Enqueue(Record{})
+	}
+	if stats := archiver.Stats(); stats.Dropped == 0 {
+		t.Fatalf("a full queue must drop records instead of blocking, got %+v", stats)
+	}
+	close(blocked)
+	if err := archiver.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+func TestArchiverMetadataQueueHoldsNoBodies(t *testing.T) {
+	blocked := make(chan struct{})
+	archiver := &Archiver{
+		newUploader: func(config.ArchiveConfig) (Uploader, error) { return blockingUploader(blocked), nil },
+		retryDelay:  time.Millisecond,
+	}
+	archiver.Configure(config.ArchiveConfig{Enabled: true, BatchSize: 1, QueueSize: 4})
+	archiver.Enqueue(Record{})
+	deadline := time.Now().Add(time.Second)
+	for archiver.Stats().Queued != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	archiver.Enqueue(Record{Request: []byte(`{"prompt":"hi"}`), Response: []byte(`{"text":"hello"}`)})
+
+	queued := <-archiver.current.queue
+	if queued.Request != nil || queued.Response != nil {
+		t.Fatalf("metadata records must not hold bodies while queued: %+v", queued)
+	}
+	if len(queued.RequestSHA256) != 64 || len(queued.ResponseSHA256) != 64 {
+		t.Fatalf("queued metadata records must carry body hashes: %+v", queued)
+	}
+	close(blocked)
+	if err := archiver.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+type blockingUploader chan struct{}
+
+func (u blockingUploader) Upload(ctx context.Context, _ string, _ []byte) error {
+	select {
+	case <-u:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,72 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+// s3Uploader stores archive objects in an S3-compatible bucket.
+type s3Uploader struct {
+	client *minio.Client
+	bucket string
+}
+
+func newS3Uploader(cfg config.ArchiveConfig) (Uploader, error) {
+	bucket := strings.TrimSpace(cfg.Bucket)
+	if bucket == "" {
+		return nil, fmt.Errorf("bucket is required")
+	}
+	endpoint := strings.TrimSpace(cfg.Endpoint)
+	if endpoint == "" {
+		return nil, fmt.Errorf("endpoint is required")
+	}
+	secure := true
+	if strings.Contains(endpoint, "://") {
+		parsed, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("parse endpoint %q: %w", endpoint, err)
+		}
+		switch strings.ToLower(parsed.Scheme) {
+		case "http":
+			secure = false
+		case "https":
+		default:
+			return nil, fmt.Errorf("endpoint %q must use http or https", endpoint)
+		}
+		endpoint = parsed.Host
+	}
+	if endpoint == "" {
+		return nil, fmt.Errorf("endpoint %q is missing host information", cfg.Endpoint)
+	}
+
+	options := &minio.Options{
+		Creds:  credentials.NewStaticV4(strings.TrimSpace(cfg.AccessKey), strings.TrimSpace(cfg.SecretKey), ""),
+		Secure: secure,
+		Region: strings.TrimSpace(cfg.Region),
+	}
+	if cfg.PathStyle {
+		options.BucketLookup = minio.BucketLookupPath
+	}
+	client, err := minio.New(endpoint, options)
+	if err != nil {
+		return nil, fmt.Errorf("create storage client: %w", err)
+	}
+	return &s3Uploader{client: client, bucket: bucket}, nil
+}
+
+func (u *s3Uploader) Upload(ctx context.Context, key string, data []byte) error {
+	_, err := u.client.PutObject(ctx, u.bucket, key, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType: "application/x-ndjson",
+	})
+	if err != nil {
+		return fmt.Errorf("put object %s: %w", key, err)
+	}
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/i18n"
 )
 
 // OAuthError represents an OAuth-specific error.
@@ -55,6 +57,11 @@ func (e *AuthenticationError) Error() string {
 	return fmt.Sprintf("%s: %s", e.Type, e.Message)
 }
 
+// Unwrap returns the underlying cause of the error.
+func (e *AuthenticationError) Unwrap() error {
+	return e.Cause
+}
+
 // Common authentication error types.
 var (
 	// ErrTokenExpired = &AuthenticationError{
@@ -130,42 +137,44 @@ func IsOAuthError(err error) bool {
 	return ok
 }
 
-// GetUserFriendlyMessage returns a user-friendly error message based on the error type.
+// GetUserFriendlyMessage returns a user-friendly error message based on the error type, in
+// the default language of the i18n package.
 func GetUserFriendlyMessage(err error) string {
+	lang := i18n.Default()
 	switch {
 	case IsAuthenticationError(err):
 		var authErr *AuthenticationError
 		errors.As(err, &authErr)
 		switch authErr.Type {
 		case "token_expired":
-			return "Your authentication has expired. Please log in again."
+			return i18n.T(lang, i18n.MsgAuthExpired)
 		case "token_invalid":
-			return "Your authentication is invalid. Please log in again."
+			return i18n.T(lang, i18n.MsgAuthInvalid)
 		case "authentication_required":
-			return "Please log in to continue."
+			return i18n.T(lang, i18n.MsgAuthRequired)
 		case "port_in_use":
-			return "The required port is already in use. Please close any applications using port 3000 and try again."
+			return i18n.T(lang, i18n.MsgAuthPortInUse)
 		case "callback_timeout":
-			return "Authentication timed out. Please try again."
+			return i18n.T(lang, i18n.MsgAuthTimedOut)
 		case "browser_open_failed":
-			return "Could not open your browser automatically. Please copy and paste the URL manually."
+			return i18n.T(lang, i18n.MsgAuthBrowserFailed)
 		default:
-			return "Authentication failed. Please try again."
+			return i18n.T(lang, i18n.MsgAuthFailed)
 		}
 	case IsOAuthError(err):
 		var oauthErr *OAuthError
 		errors.As(err, &oauthErr)
 		switch oauthErr.Code {
 		case "access_denied":
-			return "Authentication was cancelled or denied."
+			return i18n.T(lang, i18n.MsgAuthDenied)
 		case "invalid_request":
-			return "Invalid authentication request. Please try again."
+			return i18n.T(lang, i18n.MsgAuthInvalidRequest)
 		case "server_error":
-			return "Authentication server error. Please try again later."
+			return i18n.T(lang, i18n.MsgAuthServerError)
 		default:
-			return fmt.Sprintf("Authentication failed: %s", oauthErr.Description)
+			return i18n.T(lang, i18n.MsgAuthFailedReason, oauthErr.Description)
 		}
 	default:
-		return "An unexpected error occurred. Please try again."
+		return i18n.T(lang, i18n.MsgUnexpectedError)
 	}
 }
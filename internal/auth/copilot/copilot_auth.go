@@ -16,7 +16,7 @@ import (
 )
 
 const (
-	// copilotAPITokenURL is the endpoint for getting Copilot API tokens from GitHub token.
+	// copilotAPITokenURL is the github.com endpoint for getting Copilot API tokens from GitHub token.
 	copilotAPITokenURL = "https://api.github.com/copilot_internal/v2/token"
 	// copilotAPIEndpoint is the base URL for making API requests.
 	copilotAPIEndpoint = "https://api.githubcopilot.com"
@@ -48,30 +48,57 @@ type CopilotAPIToken struct {
 		Message          string `json:"message"`
 		DocumentationURL string `json:"documentation_url"`
 	} `json:"error_details,omitempty"`
+	// Raw is the undecoded response body, which also carries the account's entitlements.
+	Raw []byte `json:"-"`
+	// Headers are the response headers, including GitHub's rate-limit headers.
+	Headers http.Header `json:"-"`
 }
 
 // CopilotAuth handles GitHub Copilot authentication flow.
 // It provides methods for device flow authentication and token management.
 type CopilotAuth struct {
-	httpClient   *http.Client
-	deviceClient *DeviceFlowClient
-	cfg          *config.Config
+	httpClient    *http.Client
+	deviceClient  *DeviceFlowClient
+	cfg           *config.Config
+	githubBaseURL string
+	endpoints     githubEndpoints
 }
 
-// NewCopilotAuth creates a new CopilotAuth service instance.
+// NewCopilotAuth creates a new CopilotAuth service instance for github.com.
 // It initializes an HTTP client with proxy settings from the provided configuration.
 func NewCopilotAuth(cfg *config.Config) *CopilotAuth {
+	return NewCopilotAuthWithBaseURL(cfg, "")
+}
+
+// NewCopilotAuthWithBaseURL creates a CopilotAuth that authenticates and exchanges tokens against
+// the GitHub instance at githubBaseURL, such as a GitHub Enterprise Server used with Copilot
+// Business. An empty URL selects github.com.
+func NewCopilotAuthWithBaseURL(cfg *config.Config, githubBaseURL string) *CopilotAuth {
+	githubBaseURL = NormalizeGitHubBaseURL(githubBaseURL)
 	return &CopilotAuth{
-		httpClient:   util.SetProxy(&cfg.SDKConfig, &http.Client{Timeout: 30 * time.Second}),
-		deviceClient: NewDeviceFlowClient(cfg),
-		cfg:          cfg,
+		httpClient:    util.SetProxy(&cfg.SDKConfig, &http.Client{Timeout: 30 * time.Second}),
+		deviceClient:  NewDeviceFlowClientWithBaseURL(cfg, githubBaseURL),
+		cfg:           cfg,
+		githubBaseURL: githubBaseURL,
+		endpoints:     endpointsFor(githubBaseURL),
 	}
 }
 
+// GitHubBaseURL returns the GitHub Enterprise base URL, or "" for github.com.
+func (c *CopilotAuth) GitHubBaseURL() string {
+	return c.githubBaseURL
+}
+
 // StartDeviceFlow initiates the device flow authentication.
 // Returns the device code response containing the user code and verification URI.
 func (c *CopilotAuth) StartDeviceFlow(ctx context.Context) (*DeviceCodeResponse, error) {
-	return c.deviceClient.RequestDeviceCode(ctx)
+	return c.deviceClient.RequestDeviceCode(ctx, false)
+}
+
+// StartOrganizationDeviceFlow initiates the device flow of a login bound to an organization's
+// Copilot seat. The token is also granted read:org so VerifyOrganization can check membership.
+func (c *CopilotAuth) StartOrganizationDeviceFlow(ctx context.Context) (*DeviceCodeResponse, error) {
+	return c.deviceClient.RequestDeviceCode(ctx, true)
 }
 
 // WaitForAuthorization polls for user authorization and returns the auth bundle.
@@ -101,7 +128,7 @@ func (c *CopilotAuth) GetCopilotAPIToken(ctx context.Context, githubAccessToken
 		return nil, NewAuthenticationError(ErrTokenExchangeFailed, fmt.Errorf("github access token is empty"))
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, copilotAPITokenURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoints.apiTokenURL, nil)
 	if err != nil {
 		return nil, NewAuthenticationError(ErrTokenExchangeFailed, err)
 	}
@@ -140,6 +167,8 @@ func (c *CopilotAuth) GetCopilotAPIToken(ctx context.Context, githubAccessToken
 	if apiToken.Token == "" {
 		return nil, NewAuthenticationError(ErrTokenExchangeFailed, fmt.Errorf("empty copilot api token"))
 	}
+	apiToken.Raw = bodyBytes
+	apiToken.Headers = resp.Header.Clone()
 
 	return &apiToken, nil
 }
@@ -158,14 +187,27 @@ func (c *CopilotAuth) ValidateToken(ctx context.Context, accessToken string) (bo
 	return true, username, nil
 }
 
+// VerifyOrganization checks that the GitHub user is an active member of organization and returns
+// its login as GitHub spells it.
+func (c *CopilotAuth) VerifyOrganization(ctx context.Context, accessToken, organization string) (string, error) {
+	return c.deviceClient.FetchOrganizationMembership(ctx, accessToken, organization)
+}
+
+// RevokeToken asks GitHub to revoke the GitHub access token of a login.
+func (c *CopilotAuth) RevokeToken(ctx context.Context, accessToken string) error {
+	return c.deviceClient.RevokeToken(ctx, accessToken)
+}
+
 // CreateTokenStorage creates a new CopilotTokenStorage from auth bundle.
 func (c *CopilotAuth) CreateTokenStorage(bundle *CopilotAuthBundle) *CopilotTokenStorage {
 	return &CopilotTokenStorage{
-		AccessToken: bundle.TokenData.AccessToken,
-		TokenType:   bundle.TokenData.TokenType,
-		Scope:       bundle.TokenData.Scope,
-		Username:    bundle.Username,
-		Type:        "github-copilot",
+		AccessToken:   bundle.TokenData.AccessToken,
+		TokenType:     bundle.TokenData.TokenType,
+		Scope:         bundle.TokenData.Scope,
+		Username:      bundle.Username,
+		GitHubBaseURL: c.githubBaseURL,
+		Organization:  bundle.Organization,
+		Type:          "github-copilot",
 	}
 }
 
@@ -0,0 +1,69 @@
+package copilot
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DefaultGitHubBaseURL is the GitHub instance used when no github_base_url is configured.
+const DefaultGitHubBaseURL = "https://github.com"
+
+// githubEndpoints holds the OAuth and API endpoints of one GitHub instance.
+type githubEndpoints struct {
+	deviceCodeURL    string
+	tokenURL         string
+	userInfoURL      string
+	orgMembershipURL string
+	revokeURL        string
+	apiTokenURL      string
+}
+
+// NormalizeGitHubBaseURL returns the canonical form of a GitHub Enterprise base URL, such as
+// https://github.example.com. It returns "" for github.com, which needs no override.
+func NormalizeGitHubBaseURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return strings.TrimRight(raw, "/")
+	}
+	host := strings.ToLower(parsed.Host)
+	if host == "github.com" || host == "www.github.com" || host == "api.github.com" {
+		return ""
+	}
+	return parsed.Scheme + "://" + host + strings.TrimRight(parsed.Path, "/")
+}
+
+// endpointsFor returns the endpoints of the GitHub instance at baseURL. GitHub Enterprise Server
+// serves its REST API under /api/v3, while GitHub Enterprise Cloud with data residency (*.ghe.com)
+// serves it from the api. subdomain.
+func endpointsFor(baseURL string) githubEndpoints {
+	base := NormalizeGitHubBaseURL(baseURL)
+	if base == "" {
+		return githubEndpoints{
+			deviceCodeURL:    copilotDeviceCodeURL,
+			tokenURL:         copilotTokenURL,
+			userInfoURL:      copilotUserInfoURL,
+			orgMembershipURL: copilotOrgMembershipURL,
+			revokeURL:        copilotRevokeURL,
+			apiTokenURL:      copilotAPITokenURL,
+		}
+	}
+	apiBase := base + "/api/v3"
+	if parsed, err := url.Parse(base); err == nil && strings.HasSuffix(parsed.Host, ".ghe.com") {
+		apiBase = parsed.Scheme + "://api." + parsed.Host
+	}
+	return githubEndpoints{
+		deviceCodeURL:    base + "/login/device/code",
+		tokenURL:         base + "/login/oauth/access_token",
+		userInfoURL:      apiBase + "/user",
+		orgMembershipURL: apiBase + "/user/memberships/orgs/",
+		revokeURL:        apiBase + "/credentials/revoke",
+		apiTokenURL:      apiBase + "/copilot_internal/v2/token",
+	}
+}
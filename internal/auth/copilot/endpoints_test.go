@@ -0,0 +1,109 @@
+package copilot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestNormalizeGitHubBaseURL(t *testing.T) {
+	cases := map[string]string{
+		"":                              "",
+		"https://github.com/":           "",
+		"github.com":                    "",
+		"github.example.com":            "https://github.example.com",
+		"https://GitHub.Example.com/":   "https://github.example.com",
+		"http://ghe.internal:8080/git/": "http://ghe.internal:8080/git",
+	}
+	for raw, want := range cases {
+		if got := NormalizeGitHubBaseURL(raw); got != want {
+			t.Errorf("NormalizeGitHubBaseURL(%q) = %q, want %q", raw, got, want)
+		}
+	}
+
+	if got := endpointsFor("https://octo.ghe.com").apiTokenURL; got != "https://api.octo.ghe.com/copilot_internal/v2/token" {
+		t.Errorf("ghe.com api token URL = %q", got)
+	}
+	if got := endpointsFor("").deviceCodeURL; got != copilotDeviceCodeURL {
+		t.Errorf("default device code URL = %q", got)
+	}
+}
+
+func TestCopilotAuthUsesEnterpriseInstance(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/login/device/code":
+			_, _ = w.Write([]byte(`{"device_code":"dc","user_code":"UC","verification_uri":"https://ghe/login/device","expires_in":900,"interval":5}`))
+		case "/api/v3/copilot_internal/v2/token":
+			_, _ = w.Write([]byte(`{"token":"copilot-token","expires_at":4102444800,"endpoints":{"api":"https://copilot-api.example.com"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	authSvc := NewCopilotAuthWithBaseURL(&config.Config{}, server.URL)
+	if authSvc.GitHubBaseURL() != server.URL {
+		t.Fatalf("GitHubBaseURL() = %q, want %q", authSvc.GitHubBaseURL(), server.URL)
+	}
+	deviceCode, err := authSvc.StartDeviceFlow(context.Background())
+	if err != nil || deviceCode.UserCode != "UC" {
+		t.Fatalf("device flow: %+v, %v", deviceCode, err)
+	}
+	apiToken, err := authSvc.GetCopilotAPIToken(context.Background(), "gho_token")
+	if err != nil || apiToken.Token != "copilot-token" || apiToken.Endpoints.API != "https://copilot-api.example.com" {
+		t.Fatalf("api token: %+v, %v", apiToken, err)
+	}
+	if len(paths) != 2 {
+		t.Fatalf("requests = %v, want device code and api token on the enterprise instance", paths)
+	}
+	storage := authSvc.CreateTokenStorage(&CopilotAuthBundle{TokenData: &CopilotTokenData{AccessToken: "gho_token"}, Username: "octocat"})
+	if storage.GitHubBaseURL != server.URL {
+		t.Fatalf("storage must remember the instance, got %q", storage.GitHubBaseURL)
+	}
+}
+
+func TestCopilotAuthRevokeToken(t *testing.T) {
+	var revoked []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/credentials/revoke" || r.Method != http.MethodPost {
+			http.NotFound(w, r)
+			return
+		}
+		var body struct {
+			Credentials []string `json:"credentials"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		revoked = append(revoked, body.Credentials...)
+		if len(body.Credentials) == 1 && body.Credentials[0] == "gho_bad" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	authSvc := NewCopilotAuthWithBaseURL(&config.Config{}, server.URL)
+	if err := authSvc.RevokeToken(context.Background(), "gho_token"); err != nil {
+		t.Fatalf("RevokeToken: %v", err)
+	}
+	if len(revoked) != 1 || revoked[0] != "gho_token" {
+		t.Fatalf("revoked %v, want [gho_token]", revoked)
+	}
+	err := authSvc.RevokeToken(context.Background(), "gho_bad")
+	var authErr *AuthenticationError
+	if !errors.As(err, &authErr) || authErr.Type != ErrTokenRevocationFailed.Type {
+		t.Fatalf("a rejected revocation must fail with %s, got %v", ErrTokenRevocationFailed.Type, err)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/i18n"
 )
 
 // OAuthError represents an OAuth-specific error.
@@ -117,6 +119,13 @@ var (
 		Message: "Failed to fetch GitHub user information",
 		Code:    http.StatusBadRequest,
 	}
+
+	// ErrTokenRevocationFailed represents an error when GitHub does not accept a token revocation.
+	ErrTokenRevocationFailed = &AuthenticationError{
+		Type:    "token_revocation_failed",
+		Message: "Failed to revoke the GitHub token",
+		Code:    http.StatusBadGateway,
+	}
 )
 
 // NewAuthenticationError creates a new authentication error with a cause based on a base error.
@@ -143,29 +152,33 @@ func IsOAuthError(err error) bool {
 	return ok
 }
 
-// GetUserFriendlyMessage returns a user-friendly error message based on the error type.
+// GetUserFriendlyMessage returns a user-friendly error message based on the error type, in
+// the default language of the i18n package.
 func GetUserFriendlyMessage(err error) string {
+	lang := i18n.Default()
 	var authErr *AuthenticationError
 	if errors.As(err, &authErr) {
 		switch authErr.Type {
 		case "device_code_failed":
-			return "Failed to start GitHub authentication. Please check your network connection and try again."
+			return i18n.T(lang, i18n.MsgCopilotDeviceCodeFailed)
 		case "device_code_expired":
-			return "The authentication code has expired. Please try again."
+			return i18n.T(lang, i18n.MsgCopilotCodeExpired)
 		case "authorization_pending":
-			return "Waiting for you to authorize the application on GitHub."
+			return i18n.T(lang, i18n.MsgCopilotPending)
 		case "slow_down":
-			return "Please wait a moment before trying again."
+			return i18n.T(lang, i18n.MsgCopilotSlowDown)
 		case "access_denied":
-			return "Authentication was cancelled or denied."
+			return i18n.T(lang, i18n.MsgAuthDenied)
 		case "token_exchange_failed":
-			return "Failed to complete authentication. Please try again."
+			return i18n.T(lang, i18n.MsgCopilotTokenExchangeFailed)
 		case "polling_timeout":
-			return "Authentication timed out. Please try again."
+			return i18n.T(lang, i18n.MsgAuthTimedOut)
 		case "user_info_failed":
-			return "Failed to get your GitHub account information. Please try again."
+			return i18n.T(lang, i18n.MsgCopilotUserInfoFailed)
+		case "token_revocation_failed":
+			return i18n.T(lang, i18n.MsgCopilotRevocationFailed)
 		default:
-			return "Authentication failed. Please try again."
+			return i18n.T(lang, i18n.MsgAuthFailed)
 		}
 	}
 
@@ -173,15 +186,15 @@ func GetUserFriendlyMessage(err error) string {
 	if errors.As(err, &oauthErr) {
 		switch oauthErr.Code {
 		case "access_denied":
-			return "Authentication was cancelled or denied."
+			return i18n.T(lang, i18n.MsgAuthDenied)
 		case "invalid_request":
-			return "Invalid authentication request. Please try again."
+			return i18n.T(lang, i18n.MsgAuthInvalidRequest)
 		case "server_error":
-			return "GitHub server error. Please try again later."
+			return i18n.T(lang, i18n.MsgCopilotServerError)
 		default:
-			return fmt.Sprintf("Authentication failed: %s", oauthErr.Description)
+			return i18n.T(lang, i18n.MsgAuthFailedReason, oauthErr.Description)
 		}
 	}
 
-	return "An unexpected error occurred. Please try again."
+	return i18n.T(lang, i18n.MsgUnexpectedError)
 }
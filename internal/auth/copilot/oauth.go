@@ -1,6 +1,7 @@
 package copilot
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -19,15 +20,25 @@ import (
 const (
 	// copilotClientID is GitHub's Copilot CLI OAuth client ID.
 	copilotClientID = "Iv1.b507a08c87ecfe98"
-	// copilotDeviceCodeURL is the endpoint for requesting device codes.
+	// copilotDeviceCodeURL is the github.com endpoint for requesting device codes.
 	copilotDeviceCodeURL = "https://github.com/login/device/code"
-	// copilotTokenURL is the endpoint for exchanging device codes for tokens.
+	// copilotTokenURL is the github.com endpoint for exchanging device codes for tokens.
 	copilotTokenURL = "https://github.com/login/oauth/access_token"
-	// copilotUserInfoURL is the endpoint for fetching GitHub user information.
+	// copilotUserInfoURL is the github.com endpoint for fetching GitHub user information.
 	copilotUserInfoURL = "https://api.github.com/user"
+	// copilotOrgMembershipURL is the github.com endpoint for the user's membership in an
+	// organization; the organization login is appended.
+	copilotOrgMembershipURL = "https://api.github.com/user/memberships/orgs/"
+	// copilotScope is the OAuth scope of a login to the user's own Copilot seat.
+	copilotScope = "user:email"
+	// copilotOrgScope adds read:org, which the organization membership check requires.
+	copilotOrgScope = "user:email read:org"
+	// copilotRevokeURL is the github.com credential revocation endpoint.
+	copilotRevokeURL = "https://api.github.com/credentials/revoke"
 	// defaultPollInterval is the default interval for polling token endpoint.
 	defaultPollInterval = 5 * time.Second
-	// maxPollDuration is the maximum time to wait for user authorization.
+	// maxPollDuration is the maximum time to wait for user authorization unless login-timeouts
+	// overrides it.
 	maxPollDuration = 15 * time.Minute
 )
 
@@ -35,10 +46,17 @@ const (
 type DeviceFlowClient struct {
 	httpClient *http.Client
 	cfg        *config.Config
+	endpoints  githubEndpoints
 }
 
-// NewDeviceFlowClient creates a new device flow client.
+// NewDeviceFlowClient creates a new device flow client for github.com.
 func NewDeviceFlowClient(cfg *config.Config) *DeviceFlowClient {
+	return NewDeviceFlowClientWithBaseURL(cfg, "")
+}
+
+// NewDeviceFlowClientWithBaseURL creates a device flow client for the GitHub instance at
+// githubBaseURL, e.g. a GitHub Enterprise Server. An empty URL selects github.com.
+func NewDeviceFlowClientWithBaseURL(cfg *config.Config, githubBaseURL string) *DeviceFlowClient {
 	client := &http.Client{Timeout: 30 * time.Second}
 	if cfg != nil {
 		client = util.SetProxy(&cfg.SDKConfig, client)
@@ -46,16 +64,22 @@ func NewDeviceFlowClient(cfg *config.Config) *DeviceFlowClient {
 	return &DeviceFlowClient{
 		httpClient: client,
 		cfg:        cfg,
+		endpoints:  endpointsFor(githubBaseURL),
 	}
 }
 
-// RequestDeviceCode initiates the device flow by requesting a device code from GitHub.
-func (c *DeviceFlowClient) RequestDeviceCode(ctx context.Context) (*DeviceCodeResponse, error) {
+// RequestDeviceCode initiates the device flow by requesting a device code from GitHub. With
+// organization set the login also asks for read:org, so the membership can be verified.
+func (c *DeviceFlowClient) RequestDeviceCode(ctx context.Context, organization bool) (*DeviceCodeResponse, error) {
 	data := url.Values{}
 	data.Set("client_id", copilotClientID)
-	data.Set("scope", "user:email")
+	if organization {
+		data.Set("scope", copilotOrgScope)
+	} else {
+		data.Set("scope", copilotScope)
+	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, copilotDeviceCodeURL, strings.NewReader(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoints.deviceCodeURL, strings.NewReader(data.Encode()))
 	if err != nil {
 		return nil, NewAuthenticationError(ErrDeviceCodeFailed, err)
 	}
@@ -96,7 +120,7 @@ func (c *DeviceFlowClient) PollForToken(ctx context.Context, deviceCode *DeviceC
 		interval = defaultPollInterval
 	}
 
-	deadline := time.Now().Add(maxPollDuration)
+	deadline := time.Now().Add(c.cfg.LoginTimeout("github-copilot", maxPollDuration))
 	if deviceCode.ExpiresIn > 0 {
 		codeDeadline := time.Now().Add(time.Duration(deviceCode.ExpiresIn) * time.Second)
 		if codeDeadline.Before(deadline) {
@@ -149,7 +173,7 @@ func (c *DeviceFlowClient) exchangeDeviceCode(ctx context.Context, deviceCode st
 	data.Set("device_code", deviceCode)
 	data.Set("grant_type", "urn:ietf:params:oauth:grant-type:device_code")
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, copilotTokenURL, strings.NewReader(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoints.tokenURL, strings.NewReader(data.Encode()))
 	if err != nil {
 		return nil, NewAuthenticationError(ErrTokenExchangeFailed, err)
 	}
@@ -217,7 +241,7 @@ func (c *DeviceFlowClient) FetchUserInfo(ctx context.Context, accessToken string
 		return "", NewAuthenticationError(ErrUserInfoFailed, fmt.Errorf("access token is empty"))
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, copilotUserInfoURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoints.userInfoURL, nil)
 	if err != nil {
 		return "", NewAuthenticationError(ErrUserInfoFailed, err)
 	}
@@ -253,3 +277,89 @@ func (c *DeviceFlowClient) FetchUserInfo(ctx context.Context, accessToken string
 
 	return userInfo.Login, nil
 }
+
+// FetchOrganizationMembership verifies that the authenticated user is an active member of
+// organization and returns the organization's login as GitHub spells it. The token needs the
+// read:org scope.
+func (c *DeviceFlowClient) FetchOrganizationMembership(ctx context.Context, accessToken, organization string) (string, error) {
+	if accessToken == "" {
+		return "", NewAuthenticationError(ErrUserInfoFailed, fmt.Errorf("access token is empty"))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoints.orgMembershipURL+url.PathEscape(organization), nil)
+	if err != nil {
+		return "", NewAuthenticationError(ErrUserInfoFailed, err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "CLIProxyAPI")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", NewAuthenticationError(ErrUserInfoFailed, err)
+	}
+	defer func() {
+		if errClose := resp.Body.Close(); errClose != nil {
+			log.Errorf("copilot org membership: close body error: %v", errClose)
+		}
+	}()
+
+	if !isHTTPSuccess(resp.StatusCode) {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return "", NewAuthenticationError(ErrUserInfoFailed, fmt.Errorf("not a member of organization %s (status %d: %s)", organization, resp.StatusCode, string(bodyBytes)))
+	}
+
+	var membership struct {
+		State        string `json:"state"`
+		Organization struct {
+			Login string `json:"login"`
+		} `json:"organization"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&membership); err != nil {
+		return "", NewAuthenticationError(ErrUserInfoFailed, err)
+	}
+	if membership.State != "active" {
+		return "", NewAuthenticationError(ErrUserInfoFailed, fmt.Errorf("membership in organization %s is %s", organization, membership.State))
+	}
+	if membership.Organization.Login == "" {
+		return organization, nil
+	}
+	return membership.Organization.Login, nil
+}
+
+// RevokeToken asks GitHub to revoke accessToken through the credential revocation API, which
+// accepts any GitHub token without authenticating the caller. GitHub revokes asynchronously, so
+// an accepted request does not guarantee the token is already unusable.
+func (c *DeviceFlowClient) RevokeToken(ctx context.Context, accessToken string) error {
+	if accessToken == "" {
+		return NewAuthenticationError(ErrTokenRevocationFailed, fmt.Errorf("access token is empty"))
+	}
+
+	body, err := json.Marshal(map[string][]string{"credentials": {accessToken}})
+	if err != nil {
+		return NewAuthenticationError(ErrTokenRevocationFailed, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoints.revokeURL, bytes.NewReader(body))
+	if err != nil {
+		return NewAuthenticationError(ErrTokenRevocationFailed, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "CLIProxyAPI")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return NewAuthenticationError(ErrTokenRevocationFailed, err)
+	}
+	defer func() {
+		if errClose := resp.Body.Close(); errClose != nil {
+			log.Errorf("copilot token revoke: close body error: %v", errClose)
+		}
+	}()
+
+	if !isHTTPSuccess(resp.StatusCode) {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return NewAuthenticationError(ErrTokenRevocationFailed, fmt.Errorf("status %d: %s", resp.StatusCode, string(bodyBytes)))
+	}
+	return nil
+}
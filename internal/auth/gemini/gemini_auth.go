@@ -124,7 +124,7 @@ func (g *GeminiAuth) GetAuthenticatedClient(ctx context.Context, ts *GeminiToken
 	// If no token is found in storage, initiate the web-based OAuth flow.
 	if ts.Token == nil {
 		fmt.Printf("Could not load token from file, starting OAuth flow.\n")
-		token, err = g.getTokenFromWeb(ctx, conf, opts)
+		token, err = g.getTokenFromWeb(ctx, conf, opts, cfg.LoginTimeout("gemini", 5*time.Minute))
 		if err != nil {
 			return nil, fmt.Errorf("failed to get token from web: %w", err)
 		}
@@ -190,19 +190,11 @@ func (g *GeminiAuth) createTokenStorage(ctx context.Context, config *oauth2.Conf
 		fmt.Println("Failed to get user email from token")
 	}
 
-	var ifToken map[string]any
-	jsonData, _ := json.Marshal(token)
-	err = json.Unmarshal(jsonData, &ifToken)
+	ifToken, err := TokenMap(token)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal token: %w", err)
+		return nil, err
 	}
 
-	ifToken["token_uri"] = "https://oauth2.googleapis.com/token"
-	ifToken["client_id"] = geminiOauthClientID
-	ifToken["client_secret"] = geminiOauthClientSecret
-	ifToken["scopes"] = geminiOauthScopes
-	ifToken["universe_domain"] = "googleapis.com"
-
 	ts := GeminiTokenStorage{
 		Token:     ifToken,
 		ProjectID: projectID,
@@ -212,6 +204,23 @@ func (g *GeminiAuth) createTokenStorage(ctx context.Context, config *oauth2.Conf
 	return &ts, nil
 }
 
+// TokenMap converts an OAuth2 token into the map stored in GeminiTokenStorage.Token, adding the
+// client details needed to refresh it.
+func TokenMap(token *oauth2.Token) (map[string]any, error) {
+	var ifToken map[string]any
+	jsonData, _ := json.Marshal(token)
+	if err := json.Unmarshal(jsonData, &ifToken); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal token: %w", err)
+	}
+
+	ifToken["token_uri"] = "https://oauth2.googleapis.com/token"
+	ifToken["client_id"] = geminiOauthClientID
+	ifToken["client_secret"] = geminiOauthClientSecret
+	ifToken["scopes"] = geminiOauthScopes
+	ifToken["universe_domain"] = "googleapis.com"
+	return ifToken, nil
+}
+
 // getTokenFromWeb initiates the web-based OAuth2 authorization flow.
 // It starts a local HTTP server to listen for the callback from Google's auth server,
 // opens the user's browser to the authorization URL, and exchanges the received
@@ -225,7 +234,7 @@ func (g *GeminiAuth) createTokenStorage(ctx context.Context, config *oauth2.Conf
 // Returns:
 //   - *oauth2.Token: The OAuth2 token obtained from the authorization flow
 //   - error: An error if the token acquisition fails, nil otherwise
-func (g *GeminiAuth) getTokenFromWeb(ctx context.Context, config *oauth2.Config, opts *WebLoginOptions) (*oauth2.Token, error) {
+func (g *GeminiAuth) getTokenFromWeb(ctx context.Context, config *oauth2.Config, opts *WebLoginOptions, timeout time.Duration) (*oauth2.Token, error) {
 	callbackPort := geminiDefaultCallbackPort
 	if opts != nil && opts.CallbackPort > 0 {
 		callbackPort = opts.CallbackPort
@@ -316,7 +325,7 @@ func (g *GeminiAuth) getTokenFromWeb(ctx context.Context, config *oauth2.Config,
 
 	// Wait for the authorization code or an error.
 	var authCode string
-	timeoutTimer := time.NewTimer(5 * time.Minute)
+	timeoutTimer := time.NewTimer(timeout)
 	defer timeoutTimer.Stop()
 
 	var manualPromptTimer *time.Timer
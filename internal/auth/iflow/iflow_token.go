@@ -3,8 +3,6 @@ package iflow
 import (
 	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
 )
@@ -27,18 +25,12 @@ type IFlowTokenStorage struct {
 func (ts *IFlowTokenStorage) SaveTokenToFile(authFilePath string) error {
 	misc.LogSavingCredentials(authFilePath)
 	ts.Type = "iflow"
-	if err := os.MkdirAll(filepath.Dir(authFilePath), 0o700); err != nil {
-		return fmt.Errorf("iflow token: create directory failed: %w", err)
-	}
-
-	f, err := os.Create(authFilePath)
+	data, err := json.Marshal(ts)
 	if err != nil {
-		return fmt.Errorf("iflow token: create file failed: %w", err)
-	}
-	defer func() { _ = f.Close() }()
-
-	if err = json.NewEncoder(f).Encode(ts); err != nil {
 		return fmt.Errorf("iflow token: encode token failed: %w", err)
 	}
+	if err = misc.WriteCredentialFile(authFilePath, data); err != nil {
+		return fmt.Errorf("iflow token: write file failed: %w", err)
+	}
 	return nil
 }
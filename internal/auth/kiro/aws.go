@@ -87,61 +87,55 @@ const KiroIDETokenFile = ".aws/sso/cache/kiro-auth-token.json"
 
 // LoadKiroIDEToken loads token data from Kiro IDE's token file.
 func LoadKiroIDEToken() (*KiroTokenData, error) {
+	tokenPath, err := ResolveKiroIDETokenPath()
+	if err != nil {
+		return nil, err
+	}
+	return LoadKiroTokenFromPath(tokenPath)
+}
+
+// ResolveKiroIDETokenPath returns the path of the Kiro IDE token file LoadKiroIDEToken reads:
+// the KIRO_TOKEN_FILE or KIRO_IDE_TOKEN_FILE override, the file in the home directory, or under
+// WSL the single token file found below /mnt/c/Users.
+func ResolveKiroIDETokenPath() (string, error) {
 	if override := strings.TrimSpace(os.Getenv("KIRO_TOKEN_FILE")); override != "" {
-		return LoadKiroTokenFromPath(override)
+		return override, nil
 	}
 	if override := strings.TrimSpace(os.Getenv("KIRO_IDE_TOKEN_FILE")); override != "" {
-		return LoadKiroTokenFromPath(override)
+		return override, nil
 	}
 
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return "", fmt.Errorf("failed to get home directory: %w", err)
 	}
 
 	tokenPath := filepath.Join(homeDir, KiroIDETokenFile)
-	data, err := os.ReadFile(tokenPath)
-	if err != nil {
+	if _, err = os.Stat(tokenPath); err != nil {
 		// Best-effort: if running under WSL and Windows drive mounts are available, search C:\Users\*\.
 		// This only works if /mnt/c is visible inside the current environment (e.g. WSL host, or a container with /mnt/c mounted).
 		if wslUsersRoot := "/mnt/c/Users"; strings.HasPrefix(tokenPath, "/root/") || strings.HasPrefix(tokenPath, "/home/") {
 			if st, errStat := os.Stat(wslUsersRoot); errStat == nil && st != nil && st.IsDir() {
 				candidates, errFind := findWSLTokenFiles(wslUsersRoot)
 				if errFind == nil && len(candidates) == 1 {
-					return LoadKiroTokenFromPath(candidates[0])
+					return candidates[0], nil
 				}
 				if errFind == nil && len(candidates) > 1 {
-					return nil, fmt.Errorf("multiple Kiro IDE token files found under %s; set kiro.token-file in config or KIRO_TOKEN_FILE env var", wslUsersRoot)
+					return "", fmt.Errorf("multiple Kiro IDE token files found under %s; set kiro.token-file in config or KIRO_TOKEN_FILE env var", wslUsersRoot)
 				}
 			}
 		}
-		return nil, fmt.Errorf("failed to read Kiro IDE token file (%s): %w", tokenPath, err)
-	}
-
-	var token KiroTokenData
-	if err := json.Unmarshal(data, &token); err != nil {
-		return nil, fmt.Errorf("failed to parse Kiro IDE token: %w", err)
-	}
-
-	if token.AccessToken == "" {
-		return nil, fmt.Errorf("access token is empty in Kiro IDE token file")
+		return "", fmt.Errorf("failed to read Kiro IDE token file (%s): %w", tokenPath, err)
 	}
-
-	return &token, nil
+	return tokenPath, nil
 }
 
 // LoadKiroTokenFromPath loads token data from a custom path.
 // This supports multiple accounts by allowing different token files.
 func LoadKiroTokenFromPath(tokenPath string) (*KiroTokenData, error) {
-	tokenPath = normalizeWindowsPathToWSL(tokenPath)
-
-	// Expand ~ to home directory
-	if len(tokenPath) > 0 && tokenPath[0] == '~' {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get home directory: %w", err)
-		}
-		tokenPath = filepath.Join(homeDir, tokenPath[1:])
+	tokenPath, err := ExpandKiroTokenPath(tokenPath)
+	if err != nil {
+		return nil, err
 	}
 
 	data, err := os.ReadFile(tokenPath)
@@ -15,6 +15,7 @@ import (
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -86,7 +87,8 @@ func (k *KiroAuth) LoadTokenFromFile(tokenFile string) (*KiroTokenData, error) {
 }
 
 // IsTokenExpired checks if the token has expired.
-// This method parses the expiration timestamp and compares it with the current time.
+// This method parses the expiration timestamp and compares it with the current time,
+// less the expiry safety margin.
 //
 // Parameters:
 //   - tokenData: The token data to check
@@ -107,7 +109,7 @@ func (k *KiroAuth) IsTokenExpired(tokenData *KiroTokenData) bool {
 		}
 	}
 
-	return time.Now().After(expiresAt)
+	return cliproxyauth.TokenExpired(expiresAt, time.Now())
 }
 
 // makeRequest sends a request to the CodeWhisperer API.
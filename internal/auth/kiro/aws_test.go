@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"testing"
+	"time"
 )
 
 func TestExtractEmailFromJWT(t *testing.T) {
@@ -159,3 +160,15 @@ func createTestJWT(claims map[string]any) string {
 	
 	return header + "." + payload + "." + signature
 }
+
+func TestIsTokenExpiredAppliesSafetyMargin(t *testing.T) {
+	k := &KiroAuth{}
+	soon := &KiroTokenData{ExpiresAt: time.Now().Add(30 * time.Second).Format(time.RFC3339)}
+	if !k.IsTokenExpired(soon) {
+		t.Fatal("a token expiring within the safety margin must be treated as expired")
+	}
+	later := &KiroTokenData{ExpiresAt: time.Now().Add(time.Hour).Format(time.RFC3339)}
+	if k.IsTokenExpired(later) {
+		t.Fatal("a token expiring in an hour must not be treated as expired")
+	}
+}
@@ -0,0 +1,190 @@
+package kiro
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+)
+
+// CallbackServerOptions controls where the login callback server listens and the URL the
+// browser is redirected to. The zero value is a plain HTTP server on the loopback addresses.
+type CallbackServerOptions struct {
+	// Bind is the listen address; empty means both 127.0.0.1 and ::1.
+	Bind string
+	// Host is the host put into the redirect URL; empty derives it from Bind.
+	Host string
+	// PathSecret prefixes the callback path.
+	PathSecret string
+	// TLS serves HTTPS, using CertFile and KeyFile when both are set and a self-signed
+	// certificate otherwise.
+	TLS      bool
+	CertFile string
+	KeyFile  string
+}
+
+// CallbackServerOptionsFromConfig reads kiro-callback-server. A wildcard bind without a host
+// redirects to the address of the outbound network interface, and fails when that cannot be
+// determined. An exposed server without a configured path secret gets a random one for this login.
+func CallbackServerOptionsFromConfig(cfg *config.Config) (CallbackServerOptions, error) {
+	if cfg == nil {
+		return CallbackServerOptions{}, nil
+	}
+	server := cfg.KiroCallbackServer
+	opts := CallbackServerOptions{
+		Bind:       util.TrimHostBrackets(server.Bind),
+		Host:       util.TrimHostBrackets(server.Host),
+		PathSecret: strings.Trim(strings.TrimSpace(server.PathSecret), "/"),
+		TLS:        server.TLS,
+		CertFile:   strings.TrimSpace(server.TLSCert),
+		KeyFile:    strings.TrimSpace(server.TLSKey),
+	}
+	if ip := net.ParseIP(opts.bindHost()); opts.Host == "" && ip != nil && ip.IsUnspecified() {
+		host, err := util.OutboundIPAddress()
+		if err != nil {
+			return CallbackServerOptions{}, fmt.Errorf("kiro-callback-server: set host for the wildcard bind %s, the outbound address could not be determined: %w", opts.Bind, err)
+		}
+		opts.Host = host
+	}
+	if opts.Exposed() && opts.PathSecret == "" {
+		secret := make([]byte, 16)
+		if _, err := rand.Read(secret); err != nil {
+			return CallbackServerOptions{}, fmt.Errorf("failed to generate callback path secret: %w", err)
+		}
+		opts.PathSecret = hex.EncodeToString(secret)
+	}
+	return opts, nil
+}
+
+// Exposed reports whether the server listens on more than the loopback interface.
+func (o CallbackServerOptions) Exposed() bool {
+	bind := o.bindHost()
+	if bind == "localhost" {
+		return false
+	}
+	ip := net.ParseIP(bind)
+	return ip == nil || !ip.IsLoopback()
+}
+
+// Path returns the served path for path, behind the path secret when one is set.
+func (o CallbackServerOptions) Path(path string) string {
+	if o.PathSecret == "" {
+		return path
+	}
+	return "/" + o.PathSecret + path
+}
+
+// URL returns the URL the browser reaches the server's path on.
+func (o CallbackServerOptions) URL(port int, path string) string {
+	scheme := "http"
+	if o.TLS {
+		scheme = "https"
+	}
+	return scheme + "://" + util.JoinHostPort(o.redirectHost(), port) + o.Path(path)
+}
+
+// Warnings describes the risks of the configured exposure, one sentence per entry.
+func (o CallbackServerOptions) Warnings() []string {
+	if !o.Exposed() {
+		return nil
+	}
+	warnings := []string{
+		fmt.Sprintf("the login callback server listens on %s and is reachable from other machines; anyone who learns the callback URL can complete a pending login", o.bindHost()),
+	}
+	if !o.TLS {
+		warnings = append(warnings, "the callback is served over plain HTTP, so the authorization code and path secret cross the network unencrypted; enable tls")
+	} else if o.CertFile == "" || o.KeyFile == "" {
+		warnings = append(warnings, "the callback uses a self-signed certificate; the browser shows a certificate warning that must be accepted to finish the login")
+	}
+	return warnings
+}
+
+// tlsConfig returns the server TLS configuration, or nil when TLS is disabled.
+func (o CallbackServerOptions) tlsConfig() (*tls.Config, error) {
+	if !o.TLS {
+		return nil, nil
+	}
+	var certificate tls.Certificate
+	var err error
+	if o.CertFile != "" && o.KeyFile != "" {
+		certificate, err = tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+	} else {
+		certificate, err = selfSignedCertificate(o.redirectHost())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare callback TLS certificate: %w", err)
+	}
+	return &tls.Config{Certificates: []tls.Certificate{certificate}, MinVersion: tls.VersionTLS12}, nil
+}
+
+// listen opens the listener on port, wrapped in TLS when tlsConfig is set. Without Bind it
+// listens on both loopback addresses, so it also works on IPv6-only hosts.
+func (o CallbackServerOptions) listen(port int, tlsConfig *tls.Config) (net.Listener, error) {
+	var listener net.Listener
+	var err error
+	if o.Bind == "" {
+		listener, err = util.ListenLoopback(port)
+	} else {
+		listener, err = net.Listen("tcp", util.JoinHostPort(o.Bind, port))
+	}
+	if err != nil || tlsConfig == nil {
+		return listener, err
+	}
+	return tls.NewListener(listener, tlsConfig), nil
+}
+
+func (o CallbackServerOptions) bindHost() string {
+	if o.Bind == "" {
+		return "127.0.0.1"
+	}
+	return o.Bind
+}
+
+func (o CallbackServerOptions) redirectHost() string {
+	if o.Host != "" {
+		return o.Host
+	}
+	return o.bindHost()
+}
+
+// selfSignedCertificate creates a short-lived certificate for host.
+func selfSignedCertificate(host string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: host},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	} else {
+		template.DNSNames = []string{host}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
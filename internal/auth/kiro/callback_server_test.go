@@ -0,0 +1,104 @@
+package kiro
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestCallbackServerOptionsFromConfig(t *testing.T) {
+	opts, err := CallbackServerOptionsFromConfig(&config.Config{})
+	if err != nil {
+		t.Fatalf("CallbackServerOptionsFromConfig: %v", err)
+	}
+	if opts.Exposed() || opts.PathSecret != "" || opts.Warnings() != nil {
+		t.Fatalf("default options must stay on loopback without a secret: %+v", opts)
+	}
+	if got := opts.URL(19876, "/oauth/callback"); got != "http://127.0.0.1:19876/oauth/callback" {
+		t.Fatalf("default URL = %q", got)
+	}
+
+	cfg := &config.Config{KiroCallbackServer: config.KiroCallbackServerConfig{Bind: "10.0.0.5", TLS: true}}
+	opts, err = CallbackServerOptionsFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("CallbackServerOptionsFromConfig: %v", err)
+	}
+	if !opts.Exposed() || len(opts.PathSecret) != 32 {
+		t.Fatalf("exposed server must get a generated path secret: %+v", opts)
+	}
+	if got, want := opts.URL(19876, "/oauth/callback"), "https://10.0.0.5:19876/"+opts.PathSecret+"/oauth/callback"; got != want {
+		t.Fatalf("URL = %q, want %q", got, want)
+	}
+	if warnings := opts.Warnings(); len(warnings) != 2 || !strings.Contains(warnings[1], "self-signed") {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+
+	cfg.KiroCallbackServer = config.KiroCallbackServerConfig{Bind: "10.0.0.5", Host: "vm.example.com", PathSecret: "/s3cret/"}
+	opts, err = CallbackServerOptionsFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("CallbackServerOptionsFromConfig: %v", err)
+	}
+	if got := opts.URL(19876, "/oauth/callback"); got != "http://vm.example.com:19876/s3cret/oauth/callback" {
+		t.Fatalf("URL = %q", got)
+	}
+	if warnings := opts.Warnings(); len(warnings) != 2 || !strings.Contains(warnings[1], "plain HTTP") {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+
+	cfg.KiroCallbackServer = config.KiroCallbackServerConfig{Bind: "[::]", Host: "[2001:db8::5]", PathSecret: "s3cret"}
+	opts, err = CallbackServerOptionsFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("CallbackServerOptionsFromConfig: %v", err)
+	}
+	if got := opts.URL(19876, "/oauth/callback"); got != "http://[2001:db8::5]:19876/s3cret/oauth/callback" {
+		t.Fatalf("IPv6 hosts must be bracketed once, got %q", got)
+	}
+	if opts := (CallbackServerOptions{Bind: "::1"}); opts.Exposed() || opts.URL(19876, "/cb") != "http://[::1]:19876/cb" {
+		t.Fatalf("::1 must count as loopback and be bracketed, got %q", opts.URL(19876, "/cb"))
+	}
+}
+
+func TestProtocolHandlerServesSecretPathOverTLS(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	handler := NewProtocolHandler()
+	handler.SetServerOptions(CallbackServerOptions{PathSecret: "s3cret", TLS: true})
+	if _, err := handler.Start(ctx); err != nil {
+		t.Skipf("callback server unavailable: %v", err)
+	}
+	t.Cleanup(handler.Stop)
+
+	callbackURL := handler.CallbackURL()
+	if !strings.HasPrefix(callbackURL, "https://127.0.0.1:") || !strings.HasSuffix(callbackURL, "/s3cret/oauth/callback") {
+		t.Fatalf("CallbackURL() = %q", callbackURL)
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+
+	resp, err := client.Get(strings.Replace(callbackURL, "/s3cret", "", 1) + "?code=abc&state=st1")
+	if err != nil {
+		t.Fatalf("request without secret failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("callback without the path secret status = %d, want 404", resp.StatusCode)
+	}
+
+	resp, err = client.Get(callbackURL + "?code=abc&state=st1")
+	if err != nil {
+		t.Fatalf("callback request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	cb, err := handler.WaitForCallback(ctx)
+	if err != nil {
+		t.Fatalf("WaitForCallback: %v", err)
+	}
+	if cb.Code != "abc" || cb.State != "st1" {
+		t.Fatalf("unexpected callback: %#v", cb)
+	}
+}
@@ -1,11 +1,57 @@
 package kiro
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
+// ExpandKiroTokenPath maps Windows paths to their WSL mount and expands a leading ~ to the home
+// directory.
+func ExpandKiroTokenPath(tokenPath string) (string, error) {
+	tokenPath = normalizeWindowsPathToWSL(tokenPath)
+	if len(tokenPath) > 0 && tokenPath[0] == '~' {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		tokenPath = filepath.Join(homeDir, tokenPath[1:])
+	}
+	return tokenPath, nil
+}
+
+// KiroIDETokenPaths lists every Kiro IDE token file this host may use: the environment
+// overrides, the file in the home directory and, under WSL, the files of all Windows users.
+// Paths are returned whether or not the file exists yet, except for the WSL search.
+func KiroIDETokenPaths() []string {
+	var paths []string
+	seen := make(map[string]struct{})
+	add := func(path string) {
+		expanded, err := ExpandKiroTokenPath(strings.TrimSpace(path))
+		if err != nil || expanded == "" {
+			return
+		}
+		expanded = filepath.Clean(expanded)
+		if _, ok := seen[expanded]; ok {
+			return
+		}
+		seen[expanded] = struct{}{}
+		paths = append(paths, expanded)
+	}
+	add(os.Getenv("KIRO_TOKEN_FILE"))
+	add(os.Getenv("KIRO_IDE_TOKEN_FILE"))
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		add(filepath.Join(homeDir, KiroIDETokenFile))
+	}
+	if candidates, err := findWSLTokenFiles("/mnt/c/Users"); err == nil {
+		for _, candidate := range candidates {
+			add(candidate)
+		}
+	}
+	return paths
+}
+
 func normalizeWindowsPathToWSL(path string) string {
 	trimmed := strings.TrimSpace(path)
 	if len(trimmed) < 3 {
@@ -4,6 +4,13 @@ package kiro
 // It is intentionally small to avoid import cycles with higher-level auth packages.
 type InteractiveLoginOptions struct {
 	NoBrowser bool
-	Prompt    func(prompt string) (string, error)
+	// Headless skips the kiro:// protocol handler and redirects social logins to the local
+	// callback server, so the flow works over SSH. It is implied on hosts without a display.
+	Headless bool
+	// LoginHint pre-fills the account email on providers that accept it (Cognito).
+	LoginHint string
+	// InvitationCode is sent with the token exchange so waitlisted accounts can finish
+	// registration.
+	InvitationCode string
+	Prompt         func(prompt string) (string, error)
 }
-
@@ -27,7 +27,7 @@ const (
 	// Default callback port
 	defaultCallbackPort = 9876
 	
-	// Auth timeout
+	// Auth timeout, unless login-timeouts overrides it
 	authTimeout = 10 * time.Minute
 )
 
@@ -91,11 +91,11 @@ type AuthResult struct {
 // startCallbackServer starts a local HTTP server to receive the OAuth callback.
 func (o *KiroOAuth) startCallbackServer(ctx context.Context, expectedState string) (string, <-chan AuthResult, error) {
 	// Try to find an available port - use localhost like Kiro does
-	listener, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", defaultCallbackPort))
+	listener, err := util.ListenLoopback(defaultCallbackPort)
 	if err != nil {
 		// Try with dynamic port (RFC 8252 allows dynamic ports for native apps)
 		log.Warnf("kiro oauth: default port %d is busy, falling back to dynamic port", defaultCallbackPort)
-		listener, err = net.Listen("tcp", "localhost:0")
+		listener, err = util.ListenLoopback(0)
 		if err != nil {
 			return "", nil, fmt.Errorf("failed to start callback server: %w", err)
 		}
@@ -148,7 +148,7 @@ func (o *KiroOAuth) startCallbackServer(ctx context.Context, expectedState strin
 	go func() {
 		select {
 		case <-ctx.Done():
-		case <-time.After(authTimeout):
+		case <-time.After(o.cfg.LoginTimeout("kiro", authTimeout)):
 		case <-resultChan:
 		}
 		_ = server.Shutdown(context.Background())
@@ -263,7 +263,7 @@ func (o *KiroOAuth) RefreshToken(ctx context.Context, refreshToken string) (*Kir
 
 	if resp.StatusCode != http.StatusOK {
 		log.Debugf("token refresh failed (status %d): %s", resp.StatusCode, string(respBody))
-		return nil, fmt.Errorf("token refresh failed (status %d)", resp.StatusCode)
+		return nil, refreshFailure(resp.StatusCode, respBody)
 	}
 
 	var tokenResp KiroTokenResponse
@@ -280,7 +280,7 @@ func (o *KiroOAuth) RefreshToken(ctx context.Context, refreshToken string) (*Kir
 
 	return &KiroTokenData{
 		AccessToken:  tokenResp.AccessToken,
-		RefreshToken: tokenResp.RefreshToken,
+		RefreshToken: rotatedRefreshToken(refreshToken, tokenResp.RefreshToken),
 		ProfileArn:   tokenResp.ProfileArn,
 		ExpiresAt:    expiresAt.Format(time.RFC3339),
 		AuthMethod:   "social",
@@ -301,3 +301,10 @@ func (o *KiroOAuth) LoginWithGitHub(ctx context.Context, opts *InteractiveLoginO
 	socialClient := NewSocialAuthClient(o.cfg)
 	return socialClient.LoginWithSocial(ctx, ProviderGitHub, opts)
 }
+
+// LoginWithCognito performs email/password login using Kiro's Cognito user pool.
+// This uses a custom protocol handler (kiro://) to receive the callback.
+func (o *KiroOAuth) LoginWithCognito(ctx context.Context, opts *InteractiveLoginOptions) (*KiroTokenData, error) {
+	socialClient := NewSocialAuthClient(o.cfg)
+	return socialClient.LoginWithSocial(ctx, ProviderCognito, opts)
+}
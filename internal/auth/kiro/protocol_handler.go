@@ -13,10 +13,13 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf16"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -36,19 +39,28 @@ const (
 	// DefaultHandlerPort is the default port for the local callback server
 	DefaultHandlerPort = 19876
 
-	// HandlerTimeout is how long to wait for the OAuth callback
+	// DefaultHandlerPortCount is the default number of consecutive callback ports tried
+	DefaultHandlerPortCount = 5
+
+	// HandlerTimeout is how long to wait for the OAuth callback unless SetTimeout changes it
 	HandlerTimeout = 10 * time.Minute
 )
 
 // ProtocolHandler manages the custom kiro:// protocol handler for OAuth callbacks.
 type ProtocolHandler struct {
 	port       int
+	ports      []int
 	server     *http.Server
 	listener   net.Listener
 	resultChan chan *AuthCallback
 	stopChan   chan struct{}
 	mu         sync.Mutex
 	running    bool
+	options    CallbackServerOptions
+	timeout    time.Duration
+	// loopbackHost is the loopback address the running server was bound to when options.Bind is
+	// empty; it replaces the default redirect host.
+	loopbackHost string
 }
 
 // AuthCallback contains the OAuth callback parameters.
@@ -58,15 +70,111 @@ type AuthCallback struct {
 	Error string
 }
 
-// NewProtocolHandler creates a new protocol handler.
+// NewProtocolHandler creates a new protocol handler on the default callback ports.
 func NewProtocolHandler() *ProtocolHandler {
+	return NewProtocolHandlerWithPorts(HandlerPorts(nil))
+}
+
+// NewProtocolHandlerWithPorts creates a protocol handler that listens on the first free port of
+// ports. The installed handler scripts must forward to the same ports.
+func NewProtocolHandlerWithPorts(ports []int) *ProtocolHandler {
+	if len(ports) == 0 {
+		ports = HandlerPorts(nil)
+	}
 	return &ProtocolHandler{
-		port:       DefaultHandlerPort,
+		port:       ports[0],
+		ports:      ports,
 		resultChan: make(chan *AuthCallback, 1),
 		stopChan:   make(chan struct{}),
 	}
 }
 
+// HandlerPorts returns the callback ports configured by kiro-callback-ports, in the order they
+// are tried.
+func HandlerPorts(cfg *config.Config) []int {
+	start, count := DefaultHandlerPort, DefaultHandlerPortCount
+	if cfg != nil {
+		if cfg.KiroCallbackPorts.Start > 0 {
+			start = cfg.KiroCallbackPorts.Start
+		}
+		if cfg.KiroCallbackPorts.Count > 0 {
+			count = cfg.KiroCallbackPorts.Count
+		}
+	}
+	if start > 65535 {
+		start = DefaultHandlerPort
+	}
+	count = min(count, 65536-start)
+	ports := make([]int, count)
+	for i := range ports {
+		ports[i] = start + i
+	}
+	return ports
+}
+
+// Ports returns the callback ports the handler tries.
+func (h *ProtocolHandler) Ports() []int {
+	return h.ports
+}
+
+// joinPorts formats ports for the handler scripts.
+func joinPorts(ports []int, sep string) string {
+	parts := make([]string, len(ports))
+	for i, port := range ports {
+		parts[i] = strconv.Itoa(port)
+	}
+	return strings.Join(parts, sep)
+}
+
+// handlerPortsMarker is written into every handler script so a changed port range can be detected.
+func handlerPortsMarker(ports []int) string {
+	return "# Callback ports: " + joinPorts(ports, " ")
+}
+
+// handlerHostsMarker is written into handler scripts that try both loopback addresses, so scripts
+// that only reach 127.0.0.1 are regenerated for IPv6-only hosts.
+const handlerHostsMarker = "# Callback hosts: 127.0.0.1 [::1]"
+
+// handlerMarkers returns the marker lines of a handler script forwarding to ports.
+func handlerMarkers(ports []int) string {
+	return handlerPortsMarker(ports) + "\n" + handlerHostsMarker
+}
+
+// SetServerOptions changes where the callback server listens from the next Start on. The
+// installed handler scripts forward to the loopback addresses, so options that move the server off
+// loopback or behind TLS or a path secret are only for redirects straight to the server.
+func (h *ProtocolHandler) SetServerOptions(options CallbackServerOptions) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.options = options
+}
+
+// SetTimeout changes how long the callback server waits for the OAuth callback; zero restores
+// HandlerTimeout.
+func (h *ProtocolHandler) SetTimeout(timeout time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.timeout = timeout
+}
+
+func (h *ProtocolHandler) waitTimeout() time.Duration {
+	if h.timeout > 0 {
+		return h.timeout
+	}
+	return HandlerTimeout
+}
+
+// CallbackURL returns the URL a browser reaches the running callback server on.
+func (h *ProtocolHandler) CallbackURL() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	options := h.options
+	if options.Host == "" && h.loopbackHost != "" {
+		options.Host = h.loopbackHost
+	}
+	return options.URL(h.port, "/oauth/callback")
+}
+
 // Start starts the local callback server that receives redirects from the protocol handler.
 func (h *ProtocolHandler) Start(ctx context.Context) (int, error) {
 	h.mu.Lock()
@@ -93,28 +201,35 @@ func (h *ProtocolHandler) Start(ctx context.Context) (int, error) {
 	}
 	h.stopChan = make(chan struct{})
 
-	// Try ports in known range (must match handler script port range)
+	tlsConfig, err := h.options.tlsConfig()
+	if err != nil {
+		return 0, err
+	}
+
+	// Try ports in the configured range (must match handler script port range)
 	var listener net.Listener
-	var err error
-	portRange := []int{DefaultHandlerPort, DefaultHandlerPort + 1, DefaultHandlerPort + 2, DefaultHandlerPort + 3, DefaultHandlerPort + 4}
-	
-	for _, port := range portRange {
-		listener, err = net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	for _, port := range h.ports {
+		listener, err = h.options.listen(port, tlsConfig)
 		if err == nil {
 			break
 		}
 		log.Debugf("kiro protocol handler: port %d busy, trying next", port)
 	}
-	
+
 	if listener == nil {
-		return 0, fmt.Errorf("failed to start callback server: all ports %d-%d are busy", DefaultHandlerPort, DefaultHandlerPort+4)
+		return 0, fmt.Errorf("failed to start callback server: all ports %s are busy", joinPorts(h.ports, ", "))
 	}
 
 	h.listener = listener
 	h.port = listener.Addr().(*net.TCPAddr).Port
+	h.loopbackHost = ""
+	if h.options.Bind == "" {
+		// 127.0.0.1 unless only ::1 could be bound, as on IPv6-only hosts.
+		h.loopbackHost = listener.Addr().(*net.TCPAddr).IP.String()
+	}
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/oauth/callback", h.handleCallback)
+	mux.HandleFunc(h.options.Path("/oauth/callback"), h.handleCallback)
 
 	h.server = &http.Server{
 		Handler:           mux,
@@ -135,10 +250,11 @@ func (h *ProtocolHandler) Start(ctx context.Context) (int, error) {
 	currentStopChan := h.stopChan
 	currentServer := h.server
 	currentListener := h.listener
+	timeout := h.waitTimeout()
 	go func() {
 		select {
 		case <-ctx.Done():
-		case <-time.After(HandlerTimeout):
+		case <-time.After(timeout):
 		case <-currentStopChan:
 			return // Already stopped, exit goroutine
 		}
@@ -186,10 +302,13 @@ func (h *ProtocolHandler) Stop() {
 
 // WaitForCallback waits for the OAuth callback and returns the result.
 func (h *ProtocolHandler) WaitForCallback(ctx context.Context) (*AuthCallback, error) {
+	h.mu.Lock()
+	timeout := h.waitTimeout()
+	h.mu.Unlock()
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
-	case <-time.After(HandlerTimeout):
+	case <-time.After(timeout):
 		return nil, fmt.Errorf("timeout waiting for OAuth callback")
 	case result := <-h.resultChan:
 		return result, nil
@@ -260,15 +379,75 @@ func IsProtocolHandlerInstalled() bool {
 	}
 }
 
+// protocolHandlerScriptPath returns the path of the handler script on the current platform.
+func protocolHandlerScriptPath() string {
+	switch runtime.GOOS {
+	case "linux":
+		return getLinuxHandlerScriptPath()
+	case "windows":
+		return getWindowsHandlerScriptPath()
+	case "darwin":
+		return getDarwinHandlerScriptPath()
+	default:
+		return ""
+	}
+}
+
+// ProtocolHandlerPortsMatch reports whether the installed handler script forwards to ports on both
+// loopback addresses.
+func ProtocolHandlerPortsMatch(ports []int) bool {
+	scriptPath := protocolHandlerScriptPath()
+	if scriptPath == "" {
+		return false
+	}
+	content, err := os.ReadFile(scriptPath)
+	if err != nil {
+		return false
+	}
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	return strings.Contains(text, handlerPortsMarker(ports)+"\n") && strings.Contains(text, handlerHostsMarker+"\n")
+}
+
+// InstalledProtocolHandlerPorts returns the callback ports the installed handler script forwards
+// to, read from its ports marker. Scripts written before the marker existed report no ports.
+func InstalledProtocolHandlerPorts() ([]int, error) {
+	scriptPath := protocolHandlerScriptPath()
+	if scriptPath == "" {
+		return nil, fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+	}
+	content, err := os.ReadFile(scriptPath)
+	if err != nil {
+		return nil, err
+	}
+	prefix := handlerPortsMarker(nil)
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+		var ports []int
+		for _, field := range strings.Fields(strings.TrimPrefix(line, prefix)) {
+			port, errAtoi := strconv.Atoi(field)
+			if errAtoi != nil {
+				return nil, fmt.Errorf("invalid callback port %q in %s", field, scriptPath)
+			}
+			ports = append(ports, port)
+		}
+		return ports, nil
+	}
+	return nil, nil
+}
+
 // InstallProtocolHandler installs the kiro:// protocol handler for the current platform.
-func InstallProtocolHandler(handlerPort int) error {
+// The handler scripts forward callbacks to ports.
+func InstallProtocolHandler(ports []int) error {
 	switch runtime.GOOS {
 	case "linux":
-		return installLinuxHandler(handlerPort)
+		return installLinuxHandler(ports)
 	case "windows":
-		return installWindowsHandler(handlerPort)
+		return installWindowsHandler(ports)
 	case "darwin":
-		return installDarwinHandler(handlerPort)
+		return installDarwinHandler(ports)
 	default:
 		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
 	}
@@ -306,7 +485,7 @@ func isLinuxHandlerInstalled() bool {
 	return err == nil
 }
 
-func installLinuxHandler(handlerPort int) error {
+func installLinuxHandler(ports []int) error {
 	// Create directories
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -328,6 +507,7 @@ func installLinuxHandler(handlerPort int) error {
 	scriptContent := fmt.Sprintf(`#!/bin/bash
 # Kiro OAuth Protocol Handler
 # Handles kiro:// URIs - tries CLI first, then forwards to Kiro IDE
+%s
 
 URL="$1"
 
@@ -342,21 +522,23 @@ fi
 [[ "$URL" =~ state=([^&]+) ]] && STATE="${BASH_REMATCH[1]}"
 [[ "$URL" =~ error=([^&]+) ]] && ERROR="${BASH_REMATCH[1]}"
 
-# Try CLI proxy on multiple possible ports (default + dynamic range)
+# Try CLI proxy on multiple possible ports (default + dynamic range), over IPv4 and IPv6 loopback
 CLI_OK=0
-for PORT in %d %d %d %d %d; do
-    if [ -n "$ERROR" ]; then
-        curl -sf --connect-timeout 1 "http://127.0.0.1:$PORT/oauth/callback?error=$ERROR" && CLI_OK=1 && break
-    elif [ -n "$CODE" ] && [ -n "$STATE" ]; then
-        curl -sf --connect-timeout 1 "http://127.0.0.1:$PORT/oauth/callback?code=$CODE&state=$STATE" && CLI_OK=1 && break
-    fi
+for PORT in %s; do
+    for HOST in 127.0.0.1 "[::1]"; do
+        if [ -n "$ERROR" ]; then
+            curl -gsf --connect-timeout 1 "http://$HOST:$PORT/oauth/callback?error=$ERROR" && CLI_OK=1 && break 2
+        elif [ -n "$CODE" ] && [ -n "$STATE" ]; then
+            curl -gsf --connect-timeout 1 "http://$HOST:$PORT/oauth/callback?code=$CODE&state=$STATE" && CLI_OK=1 && break 2
+        fi
+    done
 done
 
 # If CLI not available, forward to Kiro IDE
 if [ $CLI_OK -eq 0 ] && [ -x "/usr/share/kiro/kiro" ]; then
     /usr/share/kiro/kiro --open-url "$URL" &
 fi
-`, handlerPort, handlerPort+1, handlerPort+2, handlerPort+3, handlerPort+4)
+`, handlerMarkers(ports), joinPorts(ports, " "))
 
 	if err := os.WriteFile(scriptPath, []byte(scriptContent), 0755); err != nil {
 		return fmt.Errorf("failed to write handler script: %w", err)
@@ -410,13 +592,83 @@ func uninstallLinuxHandler() error {
 
 // --- Windows Implementation ---
 
+const (
+	// protocolClassesKey is the per-user ProgID of the kiro:// scheme under HKEY_CURRENT_USER.
+	protocolClassesKey = `Software\Classes\kiro`
+	protocolCommandKey = protocolClassesKey + `\shell\open\command`
+)
+
+// RegFileError reports that the kiro:// scheme could not be registered directly and a .reg file
+// was written in its place. The handler works once the file is imported.
+type RegFileError struct {
+	// Path is the written .reg file.
+	Path string
+	// Err is why the registry could not be updated.
+	Err error
+}
+
+func (e *RegFileError) Error() string {
+	return fmt.Sprintf("registry update failed (%v); wrote %s to import manually", e.Err, e.Path)
+}
+
+func (e *RegFileError) Unwrap() error { return e.Err }
+
+func getWindowsHandlerScriptPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".cliproxyapi", "kiro-oauth-handler.ps1")
+}
+
+// WindowsRegFilePath returns where the .reg file for manual import is written.
+func WindowsRegFilePath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".cliproxyapi", "kiro-protocol-handler.reg")
+}
+
 func isWindowsHandlerInstalled() bool {
-	// Check registry key existence
-	cmd := exec.Command("reg", "query", `HKCU\Software\Classes\kiro`, "/ve")
-	return cmd.Run() == nil
+	return protocolKeysExist()
 }
 
-func installWindowsHandler(handlerPort int) error {
+// PendingRegFile returns the .reg file written by a Windows install that could not update the
+// registry, or "" when there is none. The file stays after it is imported, until an install
+// through the registry API succeeds.
+func PendingRegFile() string {
+	if runtime.GOOS != "windows" {
+		return ""
+	}
+	path := WindowsRegFilePath()
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// windowsRegFile returns a .reg file registering command as the per-user kiro:// handler,
+// encoded as UTF-16 with a byte order mark like the files regedit exports.
+func windowsRegFile(command string) []byte {
+	quote := func(value string) string {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+	}
+	text := strings.Join([]string{
+		"Windows Registry Editor Version 5.00",
+		"",
+		`[HKEY_CURRENT_USER\` + protocolClassesKey + `]`,
+		"@=" + quote("URL:Kiro Protocol"),
+		quote("URL Protocol") + "=" + quote(""),
+		"",
+		`[HKEY_CURRENT_USER\` + protocolCommandKey + `]`,
+		"@=" + quote(command),
+		"",
+	}, "\r\n")
+	encoded := utf16.Encode([]rune(text))
+	out := make([]byte, 0, 2+2*len(encoded))
+	out = append(out, 0xFF, 0xFE)
+	for _, unit := range encoded {
+		out = append(out, byte(unit), byte(unit>>8))
+	}
+	return out
+}
+
+func installWindowsHandler(ports []int) error {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return err
@@ -430,6 +682,7 @@ func installWindowsHandler(handlerPort int) error {
 
 	scriptPath := filepath.Join(scriptDir, "kiro-oauth-handler.ps1")
 	scriptContent := fmt.Sprintf(`# Kiro OAuth Protocol Handler for Windows
+%s
 param([string]$url)
 
 # Load required assembly for HttpUtility
@@ -442,28 +695,31 @@ $code = $query["code"]
 $state = $query["state"]
 $errorParam = $query["error"]
 
-# Try multiple ports (default + dynamic range)
-$ports = @(%d, %d, %d, %d, %d)
+# Try multiple ports (default + dynamic range), over IPv4 and IPv6 loopback
+$ports = @(%s)
+$hosts = @("127.0.0.1", "[::1]")
 $success = $false
 
 foreach ($port in $ports) {
-    if ($success) { break }
-    $callbackUrl = "http://127.0.0.1:$port/oauth/callback"
-    try {
-        if ($errorParam) {
-            $fullUrl = $callbackUrl + "?error=" + $errorParam
-            Invoke-WebRequest -Uri $fullUrl -UseBasicParsing -TimeoutSec 1 -ErrorAction Stop | Out-Null
-            $success = $true
-        } elseif ($code -and $state) {
-            $fullUrl = $callbackUrl + "?code=" + $code + "&state=" + $state
-            Invoke-WebRequest -Uri $fullUrl -UseBasicParsing -TimeoutSec 1 -ErrorAction Stop | Out-Null
-            $success = $true
+    foreach ($callbackHost in $hosts) {
+        if ($success) { break }
+        $callbackUrl = "http://" + $callbackHost + ":$port/oauth/callback"
+        try {
+            if ($errorParam) {
+                $fullUrl = $callbackUrl + "?error=" + $errorParam
+                Invoke-WebRequest -Uri $fullUrl -UseBasicParsing -TimeoutSec 1 -ErrorAction Stop | Out-Null
+                $success = $true
+            } elseif ($code -and $state) {
+                $fullUrl = $callbackUrl + "?code=" + $code + "&state=" + $state
+                Invoke-WebRequest -Uri $fullUrl -UseBasicParsing -TimeoutSec 1 -ErrorAction Stop | Out-Null
+                $success = $true
+            }
+        } catch {
+            # Try next address
         }
-    } catch {
-        # Try next port
     }
 }
-`, handlerPort, handlerPort+1, handlerPort+2, handlerPort+3, handlerPort+4)
+`, handlerMarkers(ports), joinPorts(ports, ", "))
 
 	if err := os.WriteFile(scriptPath, []byte(scriptContent), 0644); err != nil {
 		return fmt.Errorf("failed to write handler script: %w", err)
@@ -477,21 +733,17 @@ foreach ($port in $ports) {
 		return fmt.Errorf("failed to write batch wrapper: %w", err)
 	}
 
-	// Register in Windows registry
-	commands := [][]string{
-		{"reg", "add", `HKCU\Software\Classes\kiro`, "/ve", "/d", "URL:Kiro Protocol", "/f"},
-		{"reg", "add", `HKCU\Software\Classes\kiro`, "/v", "URL Protocol", "/d", "", "/f"},
-		{"reg", "add", `HKCU\Software\Classes\kiro\shell`, "/f"},
-		{"reg", "add", `HKCU\Software\Classes\kiro\shell\open`, "/f"},
-		{"reg", "add", `HKCU\Software\Classes\kiro\shell\open\command`, "/ve", "/d", fmt.Sprintf("\"%s\" \"%%1\"", batchPath), "/f"},
-	}
-
-	for _, args := range commands {
-		cmd := exec.Command(args[0], args[1:]...)
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("failed to run registry command: %w", err)
+	// Register through the registry API rather than reg.exe, which locked-down machines block.
+	// When even that is denied, leave a .reg file the user or an administrator can import.
+	command := fmt.Sprintf("\"%s\" \"%%1\"", batchPath)
+	regPath := WindowsRegFilePath()
+	if errRegistry := writeProtocolKeys(command); errRegistry != nil {
+		if err := os.WriteFile(regPath, windowsRegFile(command), 0644); err != nil {
+			return fmt.Errorf("failed to update the registry (%v) and to write %s: %w", errRegistry, regPath, err)
 		}
+		return &RegFileError{Path: regPath, Err: errRegistry}
 	}
+	_ = os.Remove(regPath)
 
 	log.Info("Kiro protocol handler installed for Windows")
 	return nil
@@ -499,8 +751,7 @@ foreach ($port in $ports) {
 
 func uninstallWindowsHandler() error {
 	// Remove registry keys
-	cmd := exec.Command("reg", "delete", `HKCU\Software\Classes\kiro`, "/f")
-	if err := cmd.Run(); err != nil {
+	if err := deleteProtocolKeys(); err != nil {
 		log.Warnf("failed to remove registry key: %v", err)
 	}
 
@@ -509,6 +760,7 @@ func uninstallWindowsHandler() error {
 	scriptDir := filepath.Join(homeDir, ".cliproxyapi")
 	_ = os.Remove(filepath.Join(scriptDir, "kiro-oauth-handler.ps1"))
 	_ = os.Remove(filepath.Join(scriptDir, "kiro-oauth-handler.bat"))
+	_ = os.Remove(WindowsRegFilePath())
 
 	log.Info("Kiro protocol handler uninstalled")
 	return nil
@@ -521,13 +773,17 @@ func getDarwinAppPath() string {
 	return filepath.Join(homeDir, "Applications", "KiroOAuthHandler.app")
 }
 
+func getDarwinHandlerScriptPath() string {
+	return filepath.Join(getDarwinAppPath(), "Contents", "MacOS", "kiro-oauth-handler")
+}
+
 func isDarwinHandlerInstalled() bool {
 	appPath := getDarwinAppPath()
 	_, err := os.Stat(appPath)
 	return err == nil
 }
 
-func installDarwinHandler(handlerPort int) error {
+func installDarwinHandler(ports []int) error {
 	// Create app bundle structure
 	appPath := getDarwinAppPath()
 	contentsPath := filepath.Join(appPath, "Contents")
@@ -572,9 +828,10 @@ func installDarwinHandler(handlerPort int) error {
 	}
 
 	// Create executable script - tries multiple ports to handle dynamic port allocation
-	execPath := filepath.Join(macOSPath, "kiro-oauth-handler")
+	execPath := getDarwinHandlerScriptPath()
 	execContent := fmt.Sprintf(`#!/bin/bash
 # Kiro OAuth Protocol Handler for macOS
+%s
 
 URL="$1"
 
@@ -589,15 +846,17 @@ fi
 [[ "$URL" =~ state=([^&]+) ]] && STATE="${BASH_REMATCH[1]}"
 [[ "$URL" =~ error=([^&]+) ]] && ERROR="${BASH_REMATCH[1]}"
 
-# Try multiple ports (default + dynamic range)
-for PORT in %d %d %d %d %d; do
-    if [ -n "$ERROR" ]; then
-        /usr/bin/curl -sf --connect-timeout 1 "http://127.0.0.1:$PORT/oauth/callback?error=$ERROR" && exit 0
-    elif [ -n "$CODE" ] && [ -n "$STATE" ]; then
-        /usr/bin/curl -sf --connect-timeout 1 "http://127.0.0.1:$PORT/oauth/callback?code=$CODE&state=$STATE" && exit 0
-    fi
+# Try multiple ports (default + dynamic range), over IPv4 and IPv6 loopback
+for PORT in %s; do
+    for HOST in 127.0.0.1 "[::1]"; do
+        if [ -n "$ERROR" ]; then
+            /usr/bin/curl -gsf --connect-timeout 1 "http://$HOST:$PORT/oauth/callback?error=$ERROR" && exit 0
+        elif [ -n "$CODE" ] && [ -n "$STATE" ]; then
+            /usr/bin/curl -gsf --connect-timeout 1 "http://$HOST:$PORT/oauth/callback?code=$CODE&state=$STATE" && exit 0
+        fi
+    done
 done
-`, handlerPort, handlerPort+1, handlerPort+2, handlerPort+3, handlerPort+4)
+`, handlerMarkers(ports), joinPorts(ports, " "))
 
 	if err := os.WriteFile(execPath, []byte(execContent), 0755); err != nil {
 		return fmt.Errorf("failed to write executable: %w", err)
@@ -678,6 +937,10 @@ func GetHandlerInstructions() string {
 	case "windows":
 		return `To manually set up the Kiro protocol handler on Windows:
 
+If the installer wrote ` + WindowsRegFilePath() + `, import it by
+double-clicking it. Where registry editing is disabled by policy, ask your
+administrator to deploy that file for your account. Otherwise:
+
 1. Open Registry Editor (regedit.exe)
 2. Create key: HKEY_CURRENT_USER\Software\Classes\kiro
 3. Set default value to: URL:Kiro Protocol
@@ -698,11 +961,16 @@ func GetHandlerInstructions() string {
 	}
 }
 
-// SetupProtocolHandlerIfNeeded checks and installs the protocol handler if needed.
-func SetupProtocolHandlerIfNeeded(handlerPort int) error {
+// SetupProtocolHandlerIfNeeded checks and installs the protocol handler if needed. An installed
+// handler forwarding to other ports is regenerated for ports.
+func SetupProtocolHandlerIfNeeded(ports []int) error {
 	if IsProtocolHandlerInstalled() {
-		log.Debug("Kiro protocol handler already installed")
-		return nil
+		if ProtocolHandlerPortsMatch(ports) {
+			log.Debug("Kiro protocol handler already installed")
+			return nil
+		}
+		log.Infof("Kiro protocol handler forwards to other callback ports, reinstalling for ports %s", joinPorts(ports, ", "))
+		return InstallProtocolHandler(ports)
 	}
 
 	fmt.Println("\n╔══════════════════════════════════════════════════════════╗")
@@ -712,7 +980,7 @@ func SetupProtocolHandlerIfNeeded(handlerPort int) error {
 	fmt.Println("This allows your browser to redirect back to the CLI after authentication.")
 	fmt.Println("\nInstalling protocol handler...")
 
-	if err := InstallProtocolHandler(handlerPort); err != nil {
+	if err := InstallProtocolHandler(ports); err != nil {
 		fmt.Printf("\n⚠ Automatic installation failed: %v\n", err)
 		fmt.Println("\nManual setup instructions:")
 		fmt.Println(strings.Repeat("-", 60))
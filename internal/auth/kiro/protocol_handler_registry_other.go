@@ -0,0 +1,16 @@
+//go:build !windows
+
+package kiro
+
+import "fmt"
+
+// writeProtocolKeys is unavailable outside Windows.
+func writeProtocolKeys(string) error {
+	return fmt.Errorf("the registry is only available on Windows")
+}
+
+// protocolKeysExist is always false outside Windows.
+func protocolKeysExist() bool { return false }
+
+// deleteProtocolKeys is a no-op outside Windows.
+func deleteProtocolKeys() error { return nil }
@@ -0,0 +1,52 @@
+//go:build windows
+
+package kiro
+
+import (
+	"errors"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// writeProtocolKeys registers the kiro:// scheme for the current user through the registry API,
+// which keeps working where policy blocks reg.exe and regedit.
+func writeProtocolKeys(command string) error {
+	key, _, err := registry.CreateKey(registry.CURRENT_USER, protocolClassesKey, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = key.Close() }()
+	if err = key.SetStringValue("", "URL:Kiro Protocol"); err != nil {
+		return err
+	}
+	if err = key.SetStringValue("URL Protocol", ""); err != nil {
+		return err
+	}
+	commandKey, _, err := registry.CreateKey(registry.CURRENT_USER, protocolCommandKey, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = commandKey.Close() }()
+	return commandKey.SetStringValue("", command)
+}
+
+// protocolKeysExist reports whether the kiro:// scheme is registered for the current user.
+func protocolKeysExist() bool {
+	key, err := registry.OpenKey(registry.CURRENT_USER, protocolCommandKey, registry.QUERY_VALUE)
+	if err != nil {
+		return false
+	}
+	_ = key.Close()
+	return true
+}
+
+// deleteProtocolKeys removes the kiro:// registration, deepest key first since the API only
+// deletes keys without subkeys.
+func deleteProtocolKeys() error {
+	for _, path := range []string{protocolCommandKey, protocolClassesKey + `\shell\open`, protocolClassesKey + `\shell`, protocolClassesKey} {
+		if err := registry.DeleteKey(registry.CURRENT_USER, path); err != nil && !errors.Is(err, registry.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package kiro
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestHandlerPorts(t *testing.T) {
+	if got, want := HandlerPorts(nil), []int{19876, 19877, 19878, 19879, 19880}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("default ports = %v, want %v", got, want)
+	}
+	cfg := &config.Config{KiroCallbackPorts: config.KiroCallbackPortsConfig{Start: 40100, Count: 3}}
+	if got, want := HandlerPorts(cfg), []int{40100, 40101, 40102}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("configured ports = %v, want %v", got, want)
+	}
+	cfg.KiroCallbackPorts = config.KiroCallbackPortsConfig{Start: 65534, Count: 5}
+	if got, want := HandlerPorts(cfg), []int{65534, 65535}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ports past 65535 must be dropped, got %v", got)
+	}
+}
+
+func TestLinuxHandlerScriptUsesConfiguredPorts(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("linux handler script")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("PATH", "")
+
+	ports := []int{40100, 40101}
+	if err := installLinuxHandler(ports); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(getLinuxHandlerScriptPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "for PORT in 40100 40101; do") {
+		t.Fatalf("script does not loop over the configured ports:\n%s", content)
+	}
+	if !ProtocolHandlerPortsMatch(ports) {
+		t.Fatal("installed script must match its ports")
+	}
+	if ProtocolHandlerPortsMatch([]int{40100}) || ProtocolHandlerPortsMatch(HandlerPorts(nil)) {
+		t.Fatal("a different port range must be detected")
+	}
+	if got, err := InstalledProtocolHandlerPorts(); err != nil || !reflect.DeepEqual(got, ports) {
+		t.Fatalf("installed ports = %v, %v; want %v", got, err, ports)
+	}
+	if !strings.Contains(string(content), `for HOST in 127.0.0.1 "[::1]"; do`) {
+		t.Fatalf("script does not try both loopback addresses:\n%s", content)
+	}
+	ipv4Only := strings.Replace(string(content), handlerHostsMarker+"\n", "", 1)
+	if err := os.WriteFile(getLinuxHandlerScriptPath(), []byte(ipv4Only), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if ProtocolHandlerPortsMatch(ports) {
+		t.Fatal("a script that only reaches 127.0.0.1 must be regenerated")
+	}
+}
+
+func TestWindowsHandlerFallsBackToRegFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the registry is writable on Windows")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	err := installWindowsHandler([]int{40100})
+	var regErr *RegFileError
+	if !errors.As(err, &regErr) || regErr.Path != WindowsRegFilePath() {
+		t.Fatalf("install error = %v, want a RegFileError for %s", err, WindowsRegFilePath())
+	}
+	data, err := os.ReadFile(regErr.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) < 2 || data[0] != 0xFF || data[1] != 0xFE || len(data)%2 != 0 {
+		t.Fatalf("reg file is not UTF-16LE with a byte order mark")
+	}
+	units := make([]uint16, 0, len(data)/2-1)
+	for i := 2; i < len(data); i += 2 {
+		units = append(units, uint16(data[i])|uint16(data[i+1])<<8)
+	}
+	text := string(utf16.Decode(units))
+	batchPath := strings.ReplaceAll(strings.TrimSuffix(getWindowsHandlerScriptPath(), ".ps1")+".bat", `\`, `\\`)
+	for _, want := range []string{
+		"Windows Registry Editor Version 5.00\r\n",
+		`[HKEY_CURRENT_USER\Software\Classes\kiro]`,
+		`"URL Protocol"=""`,
+		`[HKEY_CURRENT_USER\Software\Classes\kiro\shell\open\command]`,
+		`@="\"` + batchPath + `\" \"%1\""`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("reg file misses %q:\n%s", want, text)
+		}
+	}
+	if _, err = os.Stat(getWindowsHandlerScriptPath()); err != nil {
+		t.Fatalf("handler script must be written before the registration: %v", err)
+	}
+}
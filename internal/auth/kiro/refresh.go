@@ -0,0 +1,46 @@
+package kiro
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrRefreshTokenRevoked reports a refresh token the auth service no longer accepts, typically
+// because a rotated token was used again. Retrying cannot succeed; the user has to log in again.
+var ErrRefreshTokenRevoked = errors.New("refresh token revoked")
+
+// revokedRefreshMarkers are lower-cased fragments of refresh error bodies that mean the
+// refresh token itself is dead rather than the request being transiently rejected.
+var revokedRefreshMarkers = []string{
+	"invalid_grant",
+	"invalidgrantexception",
+	"revoked",
+	"reuse",
+	"invalid refresh token",
+	"refresh token is invalid",
+}
+
+// refreshFailure builds the error for a non-200 refresh response, wrapping
+// ErrRefreshTokenRevoked when the response says the refresh token was invalidated.
+func refreshFailure(status int, body []byte) error {
+	if status == http.StatusBadRequest || status == http.StatusUnauthorized || status == http.StatusForbidden {
+		lower := strings.ToLower(string(body))
+		for _, marker := range revokedRefreshMarkers {
+			if strings.Contains(lower, marker) {
+				return fmt.Errorf("token refresh failed (status %d): %w", status, ErrRefreshTokenRevoked)
+			}
+		}
+	}
+	return fmt.Errorf("token refresh failed (status %d)", status)
+}
+
+// rotatedRefreshToken returns the refresh token to keep after a refresh. Services that rotate
+// tokens return a new one which must replace the old; others omit it and the old one stays valid.
+func rotatedRefreshToken(current, issued string) string {
+	if issued = strings.TrimSpace(issued); issued != "" {
+		return issued
+	}
+	return current
+}
@@ -0,0 +1,42 @@
+package kiro
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestRefreshFailure(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		revoked bool
+	}{
+		{name: "reused token", status: http.StatusBadRequest, body: `{"message":"Refresh token was reused"}`, revoked: true},
+		{name: "sso invalid grant", status: http.StatusBadRequest, body: `{"error":"InvalidGrantException"}`, revoked: true},
+		{name: "oauth invalid grant", status: http.StatusUnauthorized, body: `{"error":"invalid_grant"}`, revoked: true},
+		{name: "plain bad request", status: http.StatusBadRequest, body: `{"message":"missing field"}`},
+		{name: "server error", status: http.StatusInternalServerError, body: `refresh token revoked`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := refreshFailure(tt.status, []byte(tt.body))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := errors.Is(err, ErrRefreshTokenRevoked); got != tt.revoked {
+				t.Errorf("revoked = %v, want %v (%v)", got, tt.revoked, err)
+			}
+		})
+	}
+}
+
+func TestRotatedRefreshToken(t *testing.T) {
+	if got := rotatedRefreshToken("old", "new"); got != "new" {
+		t.Errorf("rotated token = %q, want new", got)
+	}
+	if got := rotatedRefreshToken("old", " "); got != "old" {
+		t.Errorf("missing token = %q, want old", got)
+	}
+}
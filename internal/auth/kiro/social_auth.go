@@ -8,6 +8,7 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -20,6 +21,7 @@ import (
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/browser"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/loginui"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/term"
@@ -29,7 +31,7 @@ const (
 	// Kiro AuthService endpoint
 	kiroAuthServiceEndpoint = "https://prod.us-east-1.auth.desktop.kiro.dev"
 
-	// OAuth timeout
+	// OAuth timeout, unless login-timeouts overrides it
 	socialAuthTimeout = 10 * time.Minute
 )
 
@@ -41,6 +43,8 @@ const (
 	ProviderGoogle SocialProvider = "Google"
 	// ProviderGitHub is GitHub OAuth provider
 	ProviderGitHub SocialProvider = "Github"
+	// ProviderCognito is Kiro's own email/password user pool
+	ProviderCognito SocialProvider = "Cognito"
 	// Note: AWS Builder ID is NOT supported by Kiro's auth service.
 	// It only supports: Google, Github, Cognito
 	// AWS Builder ID must use device code flow via SSO OIDC.
@@ -83,7 +87,7 @@ func NewSocialAuthClient(cfg *config.Config) *SocialAuthClient {
 	return &SocialAuthClient{
 		httpClient:      client,
 		cfg:             cfg,
-		protocolHandler: NewProtocolHandler(),
+		protocolHandler: NewProtocolHandlerWithPorts(HandlerPorts(cfg)),
 	}
 }
 
@@ -116,14 +120,25 @@ func generateStateParam() (string, error) {
 // The login endpoint expects a GET request with query parameters.
 // Format: /login?idp=Google&redirect_uri=...&code_challenge=...&code_challenge_method=S256&state=...&prompt=select_account
 // The prompt=select_account parameter forces the account selection screen even if already logged in.
-func (c *SocialAuthClient) buildLoginURL(provider, redirectURI, codeChallenge, state string) string {
-	return fmt.Sprintf("%s/login?idp=%s&redirect_uri=%s&code_challenge=%s&code_challenge_method=S256&state=%s&prompt=select_account",
+// The Cognito user pool has no account chooser; it takes prompt=login, which always asks for the
+// password, and an optional login_hint that pre-fills the email.
+func (c *SocialAuthClient) buildLoginURL(provider, redirectURI, codeChallenge, state, loginHint string) string {
+	prompt := "select_account"
+	if provider == string(ProviderCognito) {
+		prompt = "login"
+	}
+	loginURL := fmt.Sprintf("%s/login?idp=%s&redirect_uri=%s&code_challenge=%s&code_challenge_method=S256&state=%s&prompt=%s",
 		kiroAuthServiceEndpoint,
 		provider,
 		url.QueryEscape(redirectURI),
 		codeChallenge,
 		state,
+		prompt,
 	)
+	if provider == string(ProviderCognito) && strings.TrimSpace(loginHint) != "" {
+		loginURL += "&login_hint=" + url.QueryEscape(strings.TrimSpace(loginHint))
+	}
+	return loginURL
 }
 
 // CreateToken exchanges the authorization code for tokens.
@@ -195,7 +210,7 @@ func (c *SocialAuthClient) RefreshSocialToken(ctx context.Context, refreshToken
 
 	if resp.StatusCode != http.StatusOK {
 		log.Debugf("token refresh failed (status %d): %s", resp.StatusCode, string(respBody))
-		return nil, fmt.Errorf("token refresh failed (status %d)", resp.StatusCode)
+		return nil, refreshFailure(resp.StatusCode, respBody)
 	}
 
 	var tokenResp SocialTokenResponse
@@ -212,7 +227,7 @@ func (c *SocialAuthClient) RefreshSocialToken(ctx context.Context, refreshToken
 
 	return &KiroTokenData{
 		AccessToken:  tokenResp.AccessToken,
-		RefreshToken: tokenResp.RefreshToken,
+		RefreshToken: rotatedRefreshToken(refreshToken, tokenResp.RefreshToken),
 		ProfileArn:   tokenResp.ProfileArn,
 		ExpiresAt:    expiresAt.Format(time.RFC3339),
 		AuthMethod:   "social",
@@ -220,16 +235,32 @@ func (c *SocialAuthClient) RefreshSocialToken(ctx context.Context, refreshToken
 	}, nil
 }
 
-// LoginWithSocial performs OAuth login with Google.
+// LoginWithSocial performs OAuth login with the given identity provider.
 func (c *SocialAuthClient) LoginWithSocial(ctx context.Context, provider SocialProvider, opts *InteractiveLoginOptions) (*KiroTokenData, error) {
 	providerName := string(provider)
 
-	fmt.Println("\n╔══════════════════════════════════════════════════════════╗")
-	fmt.Printf("║         Kiro Authentication (%s)                    ║\n", providerName)
-	fmt.Println("╚══════════════════════════════════════════════════════════╝")
+	progress := loginui.FromContext(ctx)
+
+	headless := (opts != nil && opts.Headless) || isHeadlessEnvironment()
+
+	serverOptions, err := CallbackServerOptionsFromConfig(c.cfg)
+	if err != nil {
+		return nil, err
+	}
+	// Redirect straight to the callback server when the kiro:// handler cannot run here, or when
+	// the server is exposed for a browser on another machine.
+	direct := headless || serverOptions.Exposed()
+	if !direct {
+		serverOptions = CallbackServerOptions{}
+	}
+	c.protocolHandler.SetServerOptions(serverOptions)
+	c.protocolHandler.SetTimeout(c.cfg.LoginTimeout("kiro", socialAuthTimeout))
+	for _, warning := range serverOptions.Warnings() {
+		progress.Warn("%s", warning)
+	}
 
 	// Step 1: Setup protocol handler
-	fmt.Println("\nSetting up authentication...")
+	progress.Step("Starting %s sign-in callback server", providerName)
 
 	// Start the local callback server
 	handlerPort, err := c.protocolHandler.Start(ctx)
@@ -238,11 +269,18 @@ func (c *SocialAuthClient) LoginWithSocial(ctx context.Context, provider SocialP
 	}
 	defer c.protocolHandler.Stop()
 
-	// Ensure protocol handler is installed and set as default
-	if err := SetupProtocolHandlerIfNeeded(handlerPort); err != nil {
-		fmt.Println("\n⚠ Protocol handler setup failed. Trying alternative method...")
-		fmt.Println("  If you see a browser 'Open with' dialog, select your default browser.")
-		fmt.Println("  For manual setup instructions, run: cliproxy kiro --help-protocol")
+	redirectURI := KiroRedirectURI
+	if direct {
+		// Without a desktop the kiro:// scheme cannot be registered, so redirect straight to the
+		// callback server instead.
+		redirectURI = c.protocolHandler.CallbackURL()
+		log.Debugf("kiro: direct callback login, using redirect %s", redirectURI)
+	} else if err := SetupProtocolHandlerIfNeeded(c.protocolHandler.Ports()); err != nil {
+		progress.Warn("Protocol handler setup failed. Trying alternative method...")
+		progress.Info(
+			"If you see a browser 'Open with' dialog, select your default browser.",
+			"For manual setup instructions, run: cliproxy kiro --help-protocol",
+		)
 		log.Debugf("kiro: protocol handler setup error: %v", err)
 		// Continue anyway - user might have set it up manually or select browser manually
 	} else {
@@ -263,7 +301,11 @@ func (c *SocialAuthClient) LoginWithSocial(ctx context.Context, provider SocialP
 	}
 
 	// Step 4: Build the login URL (Kiro uses GET request with query params)
-	authURL := c.buildLoginURL(providerName, KiroRedirectURI, codeChallenge, state)
+	loginHint := ""
+	if opts != nil {
+		loginHint = opts.LoginHint
+	}
+	authURL := c.buildLoginURL(providerName, redirectURI, codeChallenge, state, loginHint)
 
 	// Set incognito mode based on config (defaults to true for Kiro, can be overridden with --no-incognito)
 	// Incognito mode enables multi-account support by bypassing cached sessions
@@ -280,34 +322,47 @@ func (c *SocialAuthClient) LoginWithSocial(ctx context.Context, provider SocialP
 	}
 
 	// Step 5: Open browser for user authentication
-	fmt.Println("\n════════════════════════════════════════════════════════════")
-	fmt.Printf("  Opening browser for %s authentication...\n", providerName)
-	fmt.Println("════════════════════════════════════════════════════════════")
-	fmt.Printf("\n  URL: %s\n\n", authURL)
-
-	if err := browser.OpenURL(authURL); err != nil {
-		log.Warnf("Could not open browser automatically: %v", err)
-		fmt.Println("  ⚠ Could not open browser automatically.")
-		fmt.Println("  Please open the URL above in your browser manually.")
+	if headless {
+		progress.Action(fmt.Sprintf("Open this URL in a browser to sign in with %s", providerName), authURL)
+		if serverOptions.Exposed() {
+			progress.Info(
+				fmt.Sprintf("The browser is redirected to %s. If the page fails to", util.JoinHostPort(serverOptions.redirectHost(), handlerPort)),
+				"load, paste the URL from the browser's address bar below.",
+			)
+		} else {
+			progress.Info(
+				fmt.Sprintf("The browser is redirected to localhost:%d. Forward the port with", handlerPort),
+				fmt.Sprintf("`ssh -L %d:localhost:%d <host>` to finish automatically, or paste the", handlerPort, handlerPort),
+				"URL from the browser's address bar below if the page fails to load.",
+			)
+		}
 	} else {
-		fmt.Println("  (Browser opened automatically)")
+		progress.Step("Opening browser for %s authentication", providerName)
+		if err := browser.OpenURL(authURL); err != nil {
+			progress.Warn("Could not open browser automatically: %v", err)
+			progress.Action("Open this URL in your browser", authURL)
+		} else {
+			progress.Info("URL: " + authURL)
+		}
 	}
 
-	fmt.Println("\n  Waiting for authentication callback...")
+	progress.Wait("Waiting for authentication callback...")
 
 	// Step 6: Wait for callback
 	var promptFn func(string) (string, error)
-	if opts != nil && opts.NoBrowser && opts.Prompt != nil {
+	if opts != nil && (opts.NoBrowser || headless) && opts.Prompt != nil {
 		promptFn = opts.Prompt
+	} else if headless && isInteractiveTerminal() {
+		promptFn = stdinPrompt
 	}
 	callback, redirectURI, err := waitForOAuthCallback(
 		ctx,
 		state,
-		KiroRedirectURI,
+		redirectURI,
 		func(waitCtx context.Context) (*AuthCallback, error) {
 			return c.protocolHandler.WaitForCallback(waitCtx)
 		},
-		promptFn,
+		progress.WrapPrompt(promptFn),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to receive callback: %w", err)
@@ -327,27 +382,30 @@ func (c *SocialAuthClient) LoginWithSocial(ctx context.Context, provider SocialP
 		return nil, fmt.Errorf("no authorization code received")
 	}
 
-	fmt.Println("\n✓ Authorization received!")
+	progress.Step("Authorization received")
 
 	// Step 7: Exchange code for tokens
-	fmt.Println("Exchanging code for tokens...")
+	progress.Step("Exchanging code for tokens")
 
 	tokenReq := &CreateTokenRequest{
 		Code:         callback.Code,
 		CodeVerifier: codeVerifier,
 		RedirectURI:  redirectURI,
 	}
+	if opts != nil {
+		tokenReq.InvitationCode = strings.TrimSpace(opts.InvitationCode)
+	}
 
 	tokenResp, err := c.CreateToken(ctx, tokenReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange code for tokens: %w", err)
 	}
 
-	fmt.Println("\n✓ Authentication successful!")
-
 	// Close the browser window
-	if err := browser.CloseBrowser(); err != nil {
-		log.Debugf("Failed to close browser: %v", err)
+	if !headless {
+		if err := browser.CloseBrowser(); err != nil {
+			log.Debugf("Failed to close browser: %v", err)
+		}
 	}
 
 	// Validate ExpiresIn - use default 1 hour if invalid
@@ -362,14 +420,11 @@ func (c *SocialAuthClient) LoginWithSocial(ctx context.Context, provider SocialP
 	
 	// If no email in JWT, ask user for account label (only in interactive mode)
 	if email == "" && isInteractiveTerminal() {
-		fmt.Print("\n  Enter account label for file naming (optional, press Enter to skip): ")
-		reader := bufio.NewReader(os.Stdin)
 		var err error
-		email, err = reader.ReadString('\n')
+		email, err = stdinPrompt("Enter account label for file naming (optional, press Enter to skip): ")
 		if err != nil {
 			log.Debugf("Failed to read account label: %v", err)
 		}
-		email = strings.TrimSpace(email)
 	}
 
 	return &KiroTokenData{
@@ -393,6 +448,11 @@ func (c *SocialAuthClient) LoginWithGitHub(ctx context.Context) (*KiroTokenData,
 	return c.LoginWithSocial(ctx, ProviderGitHub, nil)
 }
 
+// LoginWithCognito performs email/password login against Kiro's Cognito user pool.
+func (c *SocialAuthClient) LoginWithCognito(ctx context.Context) (*KiroTokenData, error) {
+	return c.LoginWithSocial(ctx, ProviderCognito, nil)
+}
+
 // forceDefaultProtocolHandler sets our protocol handler as the default for kiro:// URLs.
 // This prevents the "Open with" dialog from appearing on Linux.
 // On non-Linux platforms, this is a no-op as they use different mechanisms.
@@ -408,6 +468,26 @@ func forceDefaultProtocolHandler() {
 	}
 }
 
+// isHeadlessEnvironment reports whether the process runs on a Linux or BSD host without a
+// graphical session, where the kiro:// protocol handler cannot be installed.
+func isHeadlessEnvironment() bool {
+	switch runtime.GOOS {
+	case "windows", "darwin":
+		return false
+	}
+	return os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == ""
+}
+
+// stdinPrompt prints prompt and reads one line from stdin.
+func stdinPrompt(prompt string) (string, error) {
+	fmt.Print(prompt)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
 // isInteractiveTerminal checks if stdin is connected to an interactive terminal.
 // Returns false in CI/automated environments or when stdin is piped.
 func isInteractiveTerminal() bool {
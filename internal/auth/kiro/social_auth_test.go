@@ -0,0 +1,135 @@
+package kiro
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsHeadlessEnvironment(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("desktop platforms are never headless")
+	}
+	t.Setenv("DISPLAY", "")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	if !isHeadlessEnvironment() {
+		t.Fatal("host without a display must be headless")
+	}
+	t.Setenv("WAYLAND_DISPLAY", "wayland-0")
+	if isHeadlessEnvironment() {
+		t.Fatal("host with a Wayland session must not be headless")
+	}
+}
+
+func TestHeadlessRedirectReachesCallbackServer(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	handler := NewProtocolHandler()
+	port, err := handler.Start(ctx)
+	if err != nil {
+		t.Skipf("callback server unavailable: %v", err)
+	}
+	t.Cleanup(handler.Stop)
+
+	// The headless flow registers this URI as the OAuth redirect.
+	redirectURI := fmt.Sprintf("http://127.0.0.1:%d/oauth/callback", port)
+	resp, err := http.Get(redirectURI + "?code=abc&state=st1")
+	if err != nil {
+		t.Fatalf("redirect request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	cb, err := handler.WaitForCallback(ctx)
+	if err != nil {
+		t.Fatalf("WaitForCallback: %v", err)
+	}
+	if cb.Code != "abc" || cb.State != "st1" {
+		t.Fatalf("unexpected callback: %#v", cb)
+	}
+}
+
+func TestBuildLoginURLCognito(t *testing.T) {
+	c := &SocialAuthClient{}
+	parse := func(raw string) url.Values {
+		t.Helper()
+		parsed, err := url.Parse(raw)
+		if err != nil {
+			t.Fatalf("invalid login URL %q: %v", raw, err)
+		}
+		return parsed.Query()
+	}
+
+	query := parse(c.buildLoginURL(string(ProviderCognito), KiroRedirectURI, "challenge", "st1", " dev@example.com "))
+	if query.Get("idp") != "Cognito" || query.Get("prompt") != "login" || query.Get("login_hint") != "dev@example.com" {
+		t.Fatalf("unexpected Cognito login query: %v", query)
+	}
+	if query.Get("redirect_uri") != KiroRedirectURI || query.Get("state") != "st1" {
+		t.Fatalf("unexpected Cognito login query: %v", query)
+	}
+
+	query = parse(c.buildLoginURL(string(ProviderGoogle), KiroRedirectURI, "challenge", "st1", "dev@example.com"))
+	if query.Get("prompt") != "select_account" || query.Has("login_hint") {
+		t.Fatalf("social providers must keep the account chooser and ignore the hint: %v", query)
+	}
+}
+
+type socialRoundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f socialRoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestCreateTokenSendsInvitationCode(t *testing.T) {
+	var body map[string]any
+	client := &SocialAuthClient{httpClient: &http.Client{Transport: socialRoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body = nil
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"accessToken":"at","refreshToken":"rt"}`)),
+			Header:     make(http.Header),
+		}, nil
+	})}}
+
+	_, err := client.CreateToken(context.Background(), &CreateTokenRequest{Code: "c", CodeVerifier: "v", RedirectURI: KiroRedirectURI, InvitationCode: "INVITE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body["invitation_code"] != "INVITE" {
+		t.Fatalf("invitation code missing from token request: %v", body)
+	}
+
+	if _, err = client.CreateToken(context.Background(), &CreateTokenRequest{Code: "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := body["invitation_code"]; ok {
+		t.Fatalf("empty invitation code must be omitted: %v", body)
+	}
+}
+
+func TestStartRemoteLoginUsesKiroRedirect(t *testing.T) {
+	client := &SocialAuthClient{}
+	login, err := client.StartRemoteLogin(ProviderGitHub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := url.Parse(login.AuthURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+	if query.Get("idp") != "Github" || query.Get("redirect_uri") != KiroRedirectURI || query.Get("state") != login.State {
+		t.Fatalf("unexpected remote login URL: %s", login.AuthURL)
+	}
+	if login.CodeVerifier == "" || query.Get("code_challenge") == "" {
+		t.Fatalf("remote login must use PKCE: %+v", login)
+	}
+}
@@ -2,7 +2,6 @@
 package kiro
 
 import (
-	"bufio"
 	"context"
 	"crypto/rand"
 	"crypto/sha256"
@@ -14,12 +13,13 @@ import (
 	"io"
 	"net"
 	"net/http"
-	"os"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/browser"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/loginui"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	log "github.com/sirupsen/logrus"
 )
@@ -40,6 +40,8 @@ const (
 	// Authorization code flow callback
 	authCodeCallbackPath = "/oauth/callback"
 	authCodeCallbackPort = 19877
+	// authCodeCallbackTimeout is how long the callback is awaited unless login-timeouts overrides it
+	authCodeCallbackTimeout = 10 * time.Minute
 
 	// User-Agent to match official Kiro IDE
 	kiroUserAgent = "KiroIDE"
@@ -106,51 +108,40 @@ func getOIDCEndpoint(region string) string {
 	return fmt.Sprintf("https://oidc.%s.amazonaws.com", region)
 }
 
-// promptInput prompts the user for input with an optional default value.
-func promptInput(prompt, defaultValue string) string {
-	reader := bufio.NewReader(os.Stdin)
+// promptInput asks for a value through prompt, returning defaultValue for an empty answer.
+func promptInput(prompt func(string) (string, error), message, defaultValue string) (string, error) {
 	if defaultValue != "" {
-		fmt.Printf("%s [%s]: ", prompt, defaultValue)
+		message = fmt.Sprintf("%s [%s]: ", message, defaultValue)
 	} else {
-		fmt.Printf("%s: ", prompt)
+		message += ": "
 	}
-	input, err := reader.ReadString('\n')
+	input, err := prompt(message)
 	if err != nil {
-		log.Warnf("Error reading input: %v", err)
-		return defaultValue
+		return "", err
 	}
-	input = strings.TrimSpace(input)
-	if input == "" {
-		return defaultValue
+	if input = strings.TrimSpace(input); input == "" {
+		return defaultValue, nil
 	}
-	return input
+	return input, nil
 }
 
-// promptSelect prompts the user to select from options using number input.
-func promptSelect(prompt string, options []string) int {
-	reader := bufio.NewReader(os.Stdin)
-
+// promptSelect lists options through progress and asks through prompt until a valid number is
+// entered. It returns the zero-based index of the selected option.
+func promptSelect(progress *loginui.Progress, prompt func(string) (string, error), title string, options []string) (int, error) {
+	progress.Menu(title, options)
 	for {
-		fmt.Println(prompt)
-		for i, opt := range options {
-			fmt.Printf("  %d) %s\n", i+1, opt)
-		}
-		fmt.Printf("Enter selection (1-%d): ", len(options))
-
-		input, err := reader.ReadString('\n')
+		input, err := prompt(fmt.Sprintf("Enter selection (1-%d): ", len(options)))
 		if err != nil {
-			log.Warnf("Error reading input: %v", err)
-			return 0 // Default to first option on error
+			return 0, err
 		}
 		input = strings.TrimSpace(input)
 
-		// Parse the selection
 		var selection int
-		if _, err := fmt.Sscanf(input, "%d", &selection); err != nil || selection < 1 || selection > len(options) {
-			fmt.Printf("Invalid selection '%s'. Please enter a number between 1 and %d.\n\n", input, len(options))
+		if _, errScan := fmt.Sscanf(input, "%d", &selection); errScan != nil || selection < 1 || selection > len(options) {
+			progress.Warn("Invalid selection '%s'. Please enter a number between 1 and %d.", input, len(options))
 			continue
 		}
-		return selection - 1
+		return selection - 1, nil
 	}
 }
 
@@ -357,7 +348,7 @@ func (c *SSOOIDCClient) RefreshTokenWithRegion(ctx context.Context, clientID, cl
 
 	if resp.StatusCode != http.StatusOK {
 		log.Warnf("IDC token refresh failed (status %d): %s", resp.StatusCode, string(respBody))
-		return nil, fmt.Errorf("token refresh failed (status %d)", resp.StatusCode)
+		return nil, refreshFailure(resp.StatusCode, respBody)
 	}
 
 	var result CreateTokenResponse
@@ -369,7 +360,7 @@ func (c *SSOOIDCClient) RefreshTokenWithRegion(ctx context.Context, clientID, cl
 
 	return &KiroTokenData{
 		AccessToken:  result.AccessToken,
-		RefreshToken: result.RefreshToken,
+		RefreshToken: rotatedRefreshToken(refreshToken, result.RefreshToken),
 		ExpiresAt:    expiresAt.Format(time.RFC3339),
 		AuthMethod:   "idc",
 		Provider:     "AWS",
@@ -380,14 +371,37 @@ func (c *SSOOIDCClient) RefreshTokenWithRegion(ctx context.Context, clientID, cl
 	}, nil
 }
 
+// NormalizeIDCStartURL validates an IAM Identity Center start URL such as
+// https://my-org.awsapps.com/start and returns it without surrounding whitespace or a trailing
+// slash or fragment.
+func NormalizeIDCStartURL(raw string) (string, error) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return "", fmt.Errorf("start URL is required for IDC login")
+	}
+	parsed, err := url.Parse(trimmed)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return "", fmt.Errorf("invalid IDC start URL %q: expected https://<org>.awsapps.com/start", trimmed)
+	}
+	parsed.Fragment = ""
+	parsed.Path = strings.TrimRight(parsed.Path, "/")
+	return parsed.String(), nil
+}
+
 // LoginWithIDC performs the full device code flow for AWS Identity Center (IDC).
 func (c *SSOOIDCClient) LoginWithIDC(ctx context.Context, startURL, region string) (*KiroTokenData, error) {
-	fmt.Println("\n╔══════════════════════════════════════════════════════════╗")
-	fmt.Println("║       Kiro Authentication (AWS Identity Center)          ║")
-	fmt.Println("╚══════════════════════════════════════════════════════════╝")
+	startURL, err := NormalizeIDCStartURL(startURL)
+	if err != nil {
+		return nil, err
+	}
+	if region = strings.TrimSpace(region); region == "" {
+		region = defaultIDCRegion
+	}
+
+	progress := loginui.FromContext(ctx)
 
 	// Step 1: Register client with the specified region
-	fmt.Println("\nRegistering client...")
+	progress.Step("Registering client in %s", region)
 	regResp, err := c.RegisterClientWithRegion(ctx, region)
 	if err != nil {
 		return nil, fmt.Errorf("failed to register client: %w", err)
@@ -395,19 +409,15 @@ func (c *SSOOIDCClient) LoginWithIDC(ctx context.Context, startURL, region strin
 	log.Debugf("Client registered: %s", regResp.ClientID)
 
 	// Step 2: Start device authorization with IDC start URL
-	fmt.Println("Starting device authorization...")
+	progress.Step("Starting device authorization")
 	authResp, err := c.StartDeviceAuthorizationWithIDC(ctx, regResp.ClientID, regResp.ClientSecret, startURL, region)
 	if err != nil {
 		return nil, fmt.Errorf("failed to start device auth: %w", err)
 	}
 
 	// Step 3: Show user the verification URL
-	fmt.Printf("\n")
-	fmt.Println("════════════════════════════════════════════════════════════")
-	fmt.Printf("  Confirm the following code in the browser:\n")
-	fmt.Printf("  Code: %s\n", authResp.UserCode)
-	fmt.Println("════════════════════════════════════════════════════════════")
-	fmt.Printf("\n  Open this URL: %s\n\n", authResp.VerificationURIComplete)
+	progress.Action("Confirm this code in the browser", authResp.UserCode)
+	progress.Action("Open this URL", authResp.VerificationURIComplete)
 
 	// Set incognito mode based on config
 	if c.cfg != nil {
@@ -424,14 +434,13 @@ func (c *SSOOIDCClient) LoginWithIDC(ctx context.Context, startURL, region strin
 
 	// Open browser
 	if err := browser.OpenURL(authResp.VerificationURIComplete); err != nil {
-		log.Warnf("Could not open browser automatically: %v", err)
-		fmt.Println("  Please open the URL manually in your browser.")
+		progress.Warn("Could not open browser automatically: %v; please open the URL manually", err)
 	} else {
-		fmt.Println("  (Browser opened automatically)")
+		progress.Step("Browser opened automatically")
 	}
 
 	// Step 4: Poll for token
-	fmt.Println("Waiting for authorization...")
+	progress.Wait("Waiting for authorization...")
 
 	interval := pollInterval
 	if authResp.Interval > 0 {
@@ -449,7 +458,6 @@ func (c *SSOOIDCClient) LoginWithIDC(ctx context.Context, startURL, region strin
 			tokenResp, err := c.CreateTokenWithRegion(ctx, regResp.ClientID, regResp.ClientSecret, authResp.DeviceCode, region)
 			if err != nil {
 				if errors.Is(err, ErrAuthorizationPending) {
-					fmt.Print(".")
 					continue
 				}
 				if errors.Is(err, ErrSlowDown) {
@@ -460,7 +468,7 @@ func (c *SSOOIDCClient) LoginWithIDC(ctx context.Context, startURL, region strin
 				return nil, fmt.Errorf("token creation failed: %w", err)
 			}
 
-			fmt.Println("\n\n✓ Authorization successful!")
+			progress.Step("Authorization received")
 
 			// Close the browser window
 			if err := browser.CloseBrowser(); err != nil {
@@ -468,13 +476,16 @@ func (c *SSOOIDCClient) LoginWithIDC(ctx context.Context, startURL, region strin
 			}
 
 			// Step 5: Get profile ARN from CodeWhisperer API
-			fmt.Println("Fetching profile information...")
+			progress.Step("Fetching profile information")
 			profileArn := c.fetchProfileArn(ctx, tokenResp.AccessToken)
+			if profileArn == "" {
+				log.Warn("kiro: no Q Developer profile found for this Identity Center user; ask your administrator to enable Kiro for the organization")
+			}
 
 			// Fetch user email
 			email := FetchUserEmailWithFallback(ctx, c.cfg, tokenResp.AccessToken)
 			if email != "" {
-				fmt.Printf("  Logged in as: %s\n", email)
+				progress.Step("Logged in as %s", email)
 			}
 
 			expiresAt := time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
@@ -502,18 +513,23 @@ func (c *SSOOIDCClient) LoginWithIDC(ctx context.Context, startURL, region strin
 	return nil, fmt.Errorf("authorization timed out")
 }
 
-// LoginWithMethodSelection prompts the user to select between Builder ID and IDC, then performs the login.
-func (c *SSOOIDCClient) LoginWithMethodSelection(ctx context.Context) (*KiroTokenData, error) {
-	fmt.Println("\n╔══════════════════════════════════════════════════════════╗")
-	fmt.Println("║              Kiro Authentication (AWS)                    ║")
-	fmt.Println("╚══════════════════════════════════════════════════════════╝")
+// LoginWithMethodSelection asks through prompt whether to log in with Builder ID or IDC, then
+// performs the login. The menu is written through the login progress of ctx.
+func (c *SSOOIDCClient) LoginWithMethodSelection(ctx context.Context, prompt func(string) (string, error)) (*KiroTokenData, error) {
+	if prompt == nil {
+		return nil, fmt.Errorf("login method selection requires a prompt")
+	}
+	progress := loginui.FromContext(ctx)
+	prompt = progress.WrapPrompt(prompt)
 
-	// Prompt for login method
 	options := []string{
 		"Use with Builder ID (personal AWS account)",
 		"Use with IDC Account (organization SSO)",
 	}
-	selection := promptSelect("\n? Select login method:", options)
+	selection, err := promptSelect(progress, prompt, "Select login method:", options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read login method: %w", err)
+	}
 
 	if selection == 0 {
 		// Builder ID flow - use existing implementation
@@ -521,13 +537,18 @@ func (c *SSOOIDCClient) LoginWithMethodSelection(ctx context.Context) (*KiroToke
 	}
 
 	// IDC flow - prompt for start URL and region
-	fmt.Println()
-	startURL := promptInput("? Enter Start URL", "")
+	startURL, err := promptInput(prompt, "Enter Start URL", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read start URL: %w", err)
+	}
 	if startURL == "" {
 		return nil, fmt.Errorf("start URL is required for IDC login")
 	}
 
-	region := promptInput("? Enter Region", defaultIDCRegion)
+	region, err := promptInput(prompt, "Enter Region", defaultIDCRegion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read region: %w", err)
+	}
 
 	return c.LoginWithIDC(ctx, startURL, region)
 }
@@ -717,7 +738,7 @@ func (c *SSOOIDCClient) RefreshToken(ctx context.Context, clientID, clientSecret
 
 	if resp.StatusCode != http.StatusOK {
 		log.Debugf("token refresh failed (status %d): %s", resp.StatusCode, string(respBody))
-		return nil, fmt.Errorf("token refresh failed (status %d)", resp.StatusCode)
+		return nil, refreshFailure(resp.StatusCode, respBody)
 	}
 
 	var result CreateTokenResponse
@@ -729,7 +750,7 @@ func (c *SSOOIDCClient) RefreshToken(ctx context.Context, clientID, clientSecret
 
 	return &KiroTokenData{
 		AccessToken:  result.AccessToken,
-		RefreshToken: result.RefreshToken,
+		RefreshToken: rotatedRefreshToken(refreshToken, result.RefreshToken),
 		ExpiresAt:    expiresAt.Format(time.RFC3339),
 		AuthMethod:   "builder-id",
 		Provider:     "AWS",
@@ -740,12 +761,10 @@ func (c *SSOOIDCClient) RefreshToken(ctx context.Context, clientID, clientSecret
 
 // LoginWithBuilderID performs the full device code flow for AWS Builder ID.
 func (c *SSOOIDCClient) LoginWithBuilderID(ctx context.Context) (*KiroTokenData, error) {
-	fmt.Println("\n╔══════════════════════════════════════════════════════════╗")
-	fmt.Println("║         Kiro Authentication (AWS Builder ID)              ║")
-	fmt.Println("╚══════════════════════════════════════════════════════════╝")
+	progress := loginui.FromContext(ctx)
 
 	// Step 1: Register client
-	fmt.Println("\nRegistering client...")
+	progress.Step("Registering client")
 	regResp, err := c.RegisterClient(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to register client: %w", err)
@@ -753,20 +772,15 @@ func (c *SSOOIDCClient) LoginWithBuilderID(ctx context.Context) (*KiroTokenData,
 	log.Debugf("Client registered: %s", regResp.ClientID)
 
 	// Step 2: Start device authorization
-	fmt.Println("Starting device authorization...")
+	progress.Step("Starting device authorization")
 	authResp, err := c.StartDeviceAuthorization(ctx, regResp.ClientID, regResp.ClientSecret)
 	if err != nil {
 		return nil, fmt.Errorf("failed to start device auth: %w", err)
 	}
 
 	// Step 3: Show user the verification URL
-	fmt.Printf("\n")
-	fmt.Println("════════════════════════════════════════════════════════════")
-	fmt.Printf("  Open this URL in your browser:\n")
-	fmt.Printf("  %s\n", authResp.VerificationURIComplete)
-	fmt.Println("════════════════════════════════════════════════════════════")
-	fmt.Printf("\n  Or go to: %s\n", authResp.VerificationURI)
-	fmt.Printf("  And enter code: %s\n\n", authResp.UserCode)
+	progress.Action("Open this URL in your browser", authResp.VerificationURIComplete)
+	progress.Info(fmt.Sprintf("Or go to %s and enter the code %s", authResp.VerificationURI, authResp.UserCode))
 
 	// Set incognito mode based on config (defaults to true for Kiro, can be overridden with --no-incognito)
 	// Incognito mode enables multi-account support by bypassing cached sessions
@@ -784,14 +798,13 @@ func (c *SSOOIDCClient) LoginWithBuilderID(ctx context.Context) (*KiroTokenData,
 
 	// Open browser using cross-platform browser package
 	if err := browser.OpenURL(authResp.VerificationURIComplete); err != nil {
-		log.Warnf("Could not open browser automatically: %v", err)
-		fmt.Println("  Please open the URL manually in your browser.")
+		progress.Warn("Could not open browser automatically: %v; please open the URL manually", err)
 	} else {
-		fmt.Println("  (Browser opened automatically)")
+		progress.Step("Browser opened automatically")
 	}
 
 	// Step 4: Poll for token
-	fmt.Println("Waiting for authorization...")
+	progress.Wait("Waiting for authorization...")
 
 	interval := pollInterval
 	if authResp.Interval > 0 {
@@ -809,7 +822,6 @@ func (c *SSOOIDCClient) LoginWithBuilderID(ctx context.Context) (*KiroTokenData,
 			tokenResp, err := c.CreateToken(ctx, regResp.ClientID, regResp.ClientSecret, authResp.DeviceCode)
 			if err != nil {
 				if errors.Is(err, ErrAuthorizationPending) {
-					fmt.Print(".")
 					continue
 				}
 				if errors.Is(err, ErrSlowDown) {
@@ -821,7 +833,7 @@ func (c *SSOOIDCClient) LoginWithBuilderID(ctx context.Context) (*KiroTokenData,
 				return nil, fmt.Errorf("token creation failed: %w", err)
 			}
 
-			fmt.Println("\n\n✓ Authorization successful!")
+			progress.Step("Authorization received")
 
 			// Close the browser window
 			if err := browser.CloseBrowser(); err != nil {
@@ -829,13 +841,13 @@ func (c *SSOOIDCClient) LoginWithBuilderID(ctx context.Context) (*KiroTokenData,
 			}
 
 			// Step 5: Get profile ARN from CodeWhisperer API
-			fmt.Println("Fetching profile information...")
+			progress.Step("Fetching profile information")
 			profileArn := c.fetchProfileArn(ctx, tokenResp.AccessToken)
 
 			// Fetch user email (tries CodeWhisperer API first, then userinfo endpoint, then JWT parsing)
 			email := FetchUserEmailWithFallback(ctx, c.cfg, tokenResp.AccessToken)
 			if email != "" {
-				fmt.Printf("  Logged in as: %s\n", email)
+				progress.Step("Logged in as %s", email)
 			}
 
 			expiresAt := time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
@@ -1107,18 +1119,19 @@ type AuthCodeCallbackResult struct {
 // startAuthCodeCallbackServer starts a local HTTP server to receive the authorization code callback.
 func (c *SSOOIDCClient) startAuthCodeCallbackServer(ctx context.Context, expectedState string) (string, <-chan AuthCodeCallbackResult, error) {
 	// Try to find an available port
-	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", authCodeCallbackPort))
+	listener, err := util.ListenLoopback(authCodeCallbackPort)
 	if err != nil {
 		// Try with dynamic port
 		log.Warnf("sso oidc: default port %d is busy, falling back to dynamic port", authCodeCallbackPort)
-		listener, err = net.Listen("tcp", "127.0.0.1:0")
+		listener, err = util.ListenLoopback(0)
 		if err != nil {
 			return "", nil, fmt.Errorf("failed to start callback server: %w", err)
 		}
 	}
 
-	port := listener.Addr().(*net.TCPAddr).Port
-	redirectURI := fmt.Sprintf("http://127.0.0.1:%d%s", port, authCodeCallbackPath)
+	// The redirect names the loopback address that was bound: 127.0.0.1, or [::1] on IPv6-only hosts.
+	addr := listener.Addr().(*net.TCPAddr)
+	redirectURI := "http://" + util.JoinHostPort(addr.IP.String(), addr.Port) + authCodeCallbackPath
 	resultChan := make(chan AuthCodeCallbackResult, 1)
 
 	server := &http.Server{
@@ -1169,7 +1182,7 @@ func (c *SSOOIDCClient) startAuthCodeCallbackServer(ctx context.Context, expecte
 	go func() {
 		select {
 		case <-ctx.Done():
-		case <-time.After(10 * time.Minute):
+		case <-time.After(c.cfg.LoginTimeout("kiro", authCodeCallbackTimeout)):
 		case <-resultChan:
 		}
 		_ = server.Shutdown(context.Background())
@@ -1249,9 +1262,7 @@ func (c *SSOOIDCClient) CreateTokenWithAuthCode(ctx context.Context, clientID, c
 // LoginWithBuilderIDAuthCode performs the authorization code flow for AWS Builder ID.
 // This provides a better UX than device code flow as it uses automatic browser callback.
 func (c *SSOOIDCClient) LoginWithBuilderIDAuthCode(ctx context.Context, opts *InteractiveLoginOptions) (*KiroTokenData, error) {
-	fmt.Println("\n╔══════════════════════════════════════════════════════════╗")
-	fmt.Println("║     Kiro Authentication (AWS Builder ID - Auth Code)      ║")
-	fmt.Println("╚══════════════════════════════════════════════════════════╝")
+	progress := loginui.FromContext(ctx)
 
 	// Step 1: Generate PKCE and state
 	codeVerifier, codeChallenge, err := generatePKCEForAuthCode()
@@ -1265,7 +1276,7 @@ func (c *SSOOIDCClient) LoginWithBuilderIDAuthCode(ctx context.Context, opts *In
 	}
 
 	// Step 2: Start callback server
-	fmt.Println("\nStarting callback server...")
+	progress.Step("Starting callback server")
 	redirectURI, resultChan, err := c.startAuthCodeCallbackServer(ctx, state)
 	if err != nil {
 		return nil, fmt.Errorf("failed to start callback server: %w", err)
@@ -1273,7 +1284,7 @@ func (c *SSOOIDCClient) LoginWithBuilderIDAuthCode(ctx context.Context, opts *In
 	log.Debugf("Callback server started, redirect URI: %s", redirectURI)
 
 	// Step 3: Register client with auth code grant type
-	fmt.Println("Registering client...")
+	progress.Step("Registering client")
 	regResp, err := c.RegisterClientForAuthCode(ctx, redirectURI)
 	if err != nil {
 		return nil, fmt.Errorf("failed to register client: %w", err)
@@ -1292,10 +1303,7 @@ func (c *SSOOIDCClient) LoginWithBuilderIDAuthCode(ctx context.Context, opts *In
 	)
 
 	// Step 5: Open browser
-	fmt.Println("\n════════════════════════════════════════════════════════════")
-	fmt.Println("  Opening browser for authentication...")
-	fmt.Println("════════════════════════════════════════════════════════════")
-	fmt.Printf("\n  URL: %s\n\n", authURL)
+	progress.Step("Opening browser for authentication")
 
 	// Set incognito mode
 	if c.cfg != nil {
@@ -1305,14 +1313,13 @@ func (c *SSOOIDCClient) LoginWithBuilderIDAuthCode(ctx context.Context, opts *In
 	}
 
 	if err := browser.OpenURL(authURL); err != nil {
-		log.Warnf("Could not open browser automatically: %v", err)
-		fmt.Println("  ⚠ Could not open browser automatically.")
-		fmt.Println("  Please open the URL above in your browser manually.")
+		progress.Warn("Could not open browser automatically: %v", err)
+		progress.Action("Open this URL in your browser", authURL)
 	} else {
-		fmt.Println("  (Browser opened automatically)")
+		progress.Info("URL: " + authURL)
 	}
 
-	fmt.Println("\n  Waiting for authorization callback...")
+	progress.Wait("Waiting for authorization callback...")
 
 	var promptFn func(string) (string, error)
 	if opts != nil && opts.NoBrowser && opts.Prompt != nil {
@@ -1327,11 +1334,13 @@ func (c *SSOOIDCClient) LoginWithBuilderIDAuthCode(ctx context.Context, opts *In
 			select {
 			case <-waitCtx.Done():
 				return nil, waitCtx.Err()
+			case <-time.After(c.cfg.LoginTimeout("kiro", authCodeCallbackTimeout)):
+				return nil, fmt.Errorf("timeout waiting for OAuth callback")
 			case result := <-resultChan:
 				return &AuthCallback{Code: result.Code, State: result.State, Error: result.Error}, nil
 			}
 		},
-		promptFn,
+		progress.WrapPrompt(promptFn),
 	)
 	if err != nil {
 		browser.CloseBrowser()
@@ -1342,7 +1351,7 @@ func (c *SSOOIDCClient) LoginWithBuilderIDAuthCode(ctx context.Context, opts *In
 		return nil, fmt.Errorf("authorization failed: %s", cb.Error)
 	}
 
-	fmt.Println("\n✓ Authorization received!")
+	progress.Step("Authorization received")
 
 	// Close browser
 	if err := browser.CloseBrowser(); err != nil {
@@ -1350,22 +1359,20 @@ func (c *SSOOIDCClient) LoginWithBuilderIDAuthCode(ctx context.Context, opts *In
 	}
 
 	// Step 7: Exchange code for tokens
-	fmt.Println("Exchanging code for tokens...")
+	progress.Step("Exchanging code for tokens")
 	tokenResp, err := c.CreateTokenWithAuthCode(ctx, regResp.ClientID, regResp.ClientSecret, cb.Code, codeVerifier, finalRedirectURI)
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange code for tokens: %w", err)
 	}
 
-	fmt.Println("\n✓ Authentication successful!")
-
 	// Step 8: Get profile ARN
-	fmt.Println("Fetching profile information...")
+	progress.Step("Fetching profile information")
 	profileArn := c.fetchProfileArn(ctx, tokenResp.AccessToken)
 
 	// Fetch user email (tries CodeWhisperer API first, then userinfo endpoint, then JWT parsing)
 	email := FetchUserEmailWithFallback(ctx, c.cfg, tokenResp.AccessToken)
 	if email != "" {
-		fmt.Printf("  Logged in as: %s\n", email)
+		progress.Step("Logged in as %s", email)
 	}
 
 	expiresAt := time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
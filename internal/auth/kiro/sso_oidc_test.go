@@ -0,0 +1,73 @@
+package kiro
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/loginui"
+)
+
+func TestNormalizeIDCStartURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{name: "plain", raw: "https://my-org.awsapps.com/start", want: "https://my-org.awsapps.com/start"},
+		{name: "trailing slash and fragment", raw: "  https://my-org.awsapps.com/start/#/  ", want: "https://my-org.awsapps.com/start"},
+		{name: "custom domain", raw: "https://sso.example.com/start", want: "https://sso.example.com/start"},
+		{name: "empty", raw: " ", wantErr: true},
+		{name: "http", raw: "http://my-org.awsapps.com/start", wantErr: true},
+		{name: "no scheme", raw: "my-org.awsapps.com/start", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeIDCStartURL(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPromptSelectWritesThroughProgress(t *testing.T) {
+	var out bytes.Buffer
+	loginui.SetMode(loginui.ModeJSON)
+	loginui.SetOutput(&out)
+	t.Cleanup(func() {
+		loginui.SetMode(loginui.ModeText)
+		loginui.SetOutput(nil)
+	})
+	progress := loginui.New("Kiro login")
+
+	answers := []string{"9", "2"}
+	prompt := func(string) (string, error) {
+		answer := answers[0]
+		answers = answers[1:]
+		return answer, nil
+	}
+	selection, err := promptSelect(progress, prompt, "Select login method:", []string{"Builder ID", "IDC"})
+	if err != nil || selection != 1 {
+		t.Fatalf("promptSelect() = %d, %v; want 1", selection, err)
+	}
+
+	var events []string
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var event struct {
+			Event string `json:"event"`
+		}
+		if errDecode := json.Unmarshal([]byte(line), &event); errDecode != nil {
+			t.Fatalf("output line %q is not a JSON event: %v", line, errDecode)
+		}
+		events = append(events, event.Event)
+	}
+	if strings.Join(events, ",") != "start,menu,warning" {
+		t.Fatalf("events = %v, want start,menu,warning", events)
+	}
+}
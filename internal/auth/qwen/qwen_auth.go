@@ -80,12 +80,15 @@ type QwenTokenResponse struct {
 // QwenAuth manages authentication and token handling for the Qwen API.
 type QwenAuth struct {
 	httpClient *http.Client
+	// pollTimeout bounds how long PollForToken waits for the user to authorize the device.
+	pollTimeout time.Duration
 }
 
 // NewQwenAuth creates a new QwenAuth instance with a proxy-configured HTTP client.
 func NewQwenAuth(cfg *config.Config) *QwenAuth {
 	return &QwenAuth{
-		httpClient: util.SetProxy(&cfg.SDKConfig, &http.Client{}),
+		httpClient:  util.SetProxy(&cfg.SDKConfig, &http.Client{}),
+		pollTimeout: cfg.LoginTimeout("qwen", 5*time.Minute),
 	}
 }
 
@@ -226,7 +229,10 @@ func (qa *QwenAuth) InitiateDeviceFlow(ctx context.Context) (*DeviceFlow, error)
 // PollForToken polls the token endpoint with the device code to obtain an access token.
 func (qa *QwenAuth) PollForToken(deviceCode, codeVerifier string) (*QwenTokenData, error) {
 	pollInterval := 5 * time.Second
-	maxAttempts := 60 // 5 minutes max
+	maxAttempts := int(qa.pollTimeout / pollInterval)
+	if maxAttempts <= 0 {
+		maxAttempts = 60 // 5 minutes max
+	}
 
 	for attempt := 0; attempt < maxAttempts; attempt++ {
 		data := url.Values{}
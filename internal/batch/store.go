@@ -0,0 +1,321 @@
+// Package batch implements a local, in-memory store for asynchronous batch jobs. A job holds a
+// list of independent requests that are executed in the background through a caller-supplied
+// Executor; its outcomes can be polled until the job is deleted or its retention window lapses.
+//
+// Jobs are scoped to the client key that created them and are not persisted across restarts.
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job processing states.
+const (
+	StatusInProgress = "in_progress"
+	StatusCanceling  = "canceling"
+	StatusEnded      = "ended"
+)
+
+// Outcome types recorded per request.
+const (
+	OutcomeSucceeded = "succeeded"
+	OutcomeErrored   = "errored"
+	OutcomeCanceled  = "canceled"
+	OutcomeExpired   = "expired"
+)
+
+const (
+	// DefaultConcurrency is the number of requests of a single job executed in parallel.
+	DefaultConcurrency = 4
+	// DefaultExpiry is how long a job may run before its unfinished requests expire.
+	DefaultExpiry = 24 * time.Hour
+	// DefaultRetention is how long an ended job remains available.
+	DefaultRetention = 24 * time.Hour
+)
+
+var (
+	// ErrNotFound is returned when a job does not exist or belongs to another client.
+	ErrNotFound = errors.New("batch not found")
+	// ErrNotEnded is returned when an operation requires a job that has finished processing.
+	ErrNotEnded = errors.New("batch has not finished processing")
+)
+
+// Request is one unit of work inside a job.
+type Request struct {
+	CustomID string
+	Params   json.RawMessage
+}
+
+// Outcome is the result of executing one request.
+type Outcome struct {
+	CustomID   string
+	Type       string
+	StatusCode int
+	Body       []byte
+	Error      string
+}
+
+// Counts tallies requests by state.
+type Counts struct {
+	Processing int `json:"processing"`
+	Succeeded  int `json:"succeeded"`
+	Errored    int `json:"errored"`
+	Canceled   int `json:"canceled"`
+	Expired    int `json:"expired"`
+}
+
+// Snapshot is a point-in-time view of a job.
+type Snapshot struct {
+	ID                string
+	Status            string
+	Counts            Counts
+	CreatedAt         time.Time
+	ExpiresAt         time.Time
+	EndedAt           *time.Time
+	CancelInitiatedAt *time.Time
+}
+
+// Executor runs a single request of a job created by owner.
+type Executor func(ctx context.Context, owner string, req Request) Outcome
+
+type job struct {
+	id                string
+	owner             string
+	status            string
+	createdAt         time.Time
+	expiresAt         time.Time
+	endedAt           *time.Time
+	cancelInitiatedAt *time.Time
+	requests          []Request
+	outcomes          []*Outcome
+	counts            Counts
+	cancel            context.CancelFunc
+}
+
+// Store keeps batch jobs in memory and executes them in the background.
+type Store struct {
+	mu          sync.Mutex
+	prefix      string
+	concurrency int
+	expiry      time.Duration
+	retention   time.Duration
+	now         func() time.Time
+	jobs        map[string]*job
+}
+
+// NewStore creates a store whose job IDs start with prefix.
+func NewStore(prefix string) *Store {
+	return &Store{
+		prefix:      prefix,
+		concurrency: DefaultConcurrency,
+		expiry:      DefaultExpiry,
+		retention:   DefaultRetention,
+		now:         time.Now,
+		jobs:        make(map[string]*job),
+	}
+}
+
+// Create registers a job for owner and starts executing its requests with exec.
+func (s *Store) Create(owner string, requests []Request, exec Executor) Snapshot {
+	now := s.now().UTC()
+	ctx, cancel := context.WithDeadline(context.Background(), now.Add(s.expiry))
+	j := &job{
+		id:        s.prefix + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		owner:     owner,
+		status:    StatusInProgress,
+		createdAt: now,
+		expiresAt: now.Add(s.expiry),
+		requests:  requests,
+		outcomes:  make([]*Outcome, len(requests)),
+		counts:    Counts{Processing: len(requests)},
+		cancel:    cancel,
+	}
+	s.mu.Lock()
+	s.purgeLocked(now)
+	s.jobs[j.id] = j
+	snapshot := j.snapshot()
+	s.mu.Unlock()
+
+	go s.run(ctx, j, exec)
+	return snapshot
+}
+
+// Get returns the job with id if it belongs to owner.
+func (s *Store) Get(owner, id string) (Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, err := s.lookupLocked(owner, id)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return j.snapshot(), nil
+}
+
+// List returns owner's jobs, newest first.
+func (s *Store) List(owner string) []Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purgeLocked(s.now().UTC())
+	out := make([]Snapshot, 0)
+	for _, j := range s.jobs {
+		if j.owner == owner {
+			out = append(out, j.snapshot())
+		}
+	}
+	sort.Slice(out, func(i, k int) bool {
+		if out[i].CreatedAt.Equal(out[k].CreatedAt) {
+			return out[i].ID > out[k].ID
+		}
+		return out[i].CreatedAt.After(out[k].CreatedAt)
+	})
+	return out
+}
+
+// Cancel stops dispatching new requests of the job; in-flight requests are allowed to finish.
+func (s *Store) Cancel(owner, id string) (Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, err := s.lookupLocked(owner, id)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if j.status == StatusInProgress {
+		now := s.now().UTC()
+		j.status = StatusCanceling
+		j.cancelInitiatedAt = &now
+		j.cancel()
+	}
+	return j.snapshot(), nil
+}
+
+// Delete removes an ended job.
+func (s *Store) Delete(owner, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, err := s.lookupLocked(owner, id)
+	if err != nil {
+		return err
+	}
+	if j.status != StatusEnded {
+		return ErrNotEnded
+	}
+	delete(s.jobs, id)
+	return nil
+}
+
+// Results returns the outcomes of an ended job in request order.
+func (s *Store) Results(owner, id string) ([]Outcome, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, err := s.lookupLocked(owner, id)
+	if err != nil {
+		return nil, err
+	}
+	if j.status != StatusEnded {
+		return nil, ErrNotEnded
+	}
+	out := make([]Outcome, 0, len(j.outcomes))
+	for _, outcome := range j.outcomes {
+		if outcome != nil {
+			out = append(out, *outcome)
+		}
+	}
+	return out, nil
+}
+
+func (s *Store) run(ctx context.Context, j *job, exec Executor) {
+	defer j.cancel()
+	sem := make(chan struct{}, max(1, s.concurrency))
+	var wg sync.WaitGroup
+	for i, req := range j.requests {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			s.finishRequest(j, i, s.stoppedOutcome(ctx, req))
+			continue
+		}
+		wg.Add(1)
+		go func(i int, req Request) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			// Requests already dispatched run to completion even if the job is canceled.
+			outcome := exec(context.WithoutCancel(ctx), j.owner, req)
+			outcome.CustomID = req.CustomID
+			s.finishRequest(j, i, outcome)
+		}(i, req)
+	}
+	wg.Wait()
+
+	s.mu.Lock()
+	now := s.now().UTC()
+	j.status = StatusEnded
+	j.endedAt = &now
+	j.requests = nil
+	s.mu.Unlock()
+}
+
+// stoppedOutcome reports a request that was not completed because the job was canceled or expired.
+func (s *Store) stoppedOutcome(ctx context.Context, req Request) Outcome {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return Outcome{CustomID: req.CustomID, Type: OutcomeExpired}
+	}
+	return Outcome{CustomID: req.CustomID, Type: OutcomeCanceled}
+}
+
+func (s *Store) finishRequest(j *job, index int, outcome Outcome) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j.outcomes[index] = &outcome
+	j.counts.Processing--
+	switch outcome.Type {
+	case OutcomeSucceeded:
+		j.counts.Succeeded++
+	case OutcomeCanceled:
+		j.counts.Canceled++
+	case OutcomeExpired:
+		j.counts.Expired++
+	default:
+		j.counts.Errored++
+	}
+}
+
+func (s *Store) lookupLocked(owner, id string) (*job, error) {
+	j, ok := s.jobs[strings.TrimSpace(id)]
+	if !ok || j.owner != owner {
+		return nil, ErrNotFound
+	}
+	return j, nil
+}
+
+// purgeLocked drops ended jobs whose retention window has lapsed.
+func (s *Store) purgeLocked(now time.Time) {
+	for id, j := range s.jobs {
+		if j.endedAt != nil && now.Sub(*j.endedAt) > s.retention {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+func (j *job) snapshot() Snapshot {
+	return Snapshot{
+		ID:                j.id,
+		Status:            j.status,
+		Counts:            j.counts,
+		CreatedAt:         j.createdAt,
+		ExpiresAt:         j.expiresAt,
+		EndedAt:           j.endedAt,
+		CancelInitiatedAt: j.cancelInitiatedAt,
+	}
+}
@@ -0,0 +1,92 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func waitEnded(t *testing.T, s *Store, owner, id string) Snapshot {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		snapshot, err := s.Get(owner, id)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if snapshot.Status == StatusEnded {
+			return snapshot
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("batch did not end in time")
+	return Snapshot{}
+}
+
+func TestStoreRunsRequestsInOrder(t *testing.T) {
+	s := NewStore("b_")
+	exec := func(_ context.Context, owner string, req Request) Outcome {
+		if owner != "k" {
+			t.Errorf("unexpected owner %q", owner)
+		}
+		if req.CustomID == "bad" {
+			return Outcome{Type: OutcomeErrored, StatusCode: 500, Error: "boom"}
+		}
+		return Outcome{Type: OutcomeSucceeded, Body: []byte(`{}`)}
+	}
+	created := s.Create("k", []Request{{CustomID: "a"}, {CustomID: "bad"}, {CustomID: "c"}}, exec)
+	if created.Counts.Processing != 3 || created.Status != StatusInProgress {
+		t.Fatalf("unexpected initial snapshot: %+v", created)
+	}
+	if _, err := s.Get("other", created.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected other owners to be denied, got %v", err)
+	}
+
+	ended := waitEnded(t, s, "k", created.ID)
+	if ended.Counts != (Counts{Succeeded: 2, Errored: 1}) || ended.EndedAt == nil {
+		t.Fatalf("unexpected final snapshot: %+v", ended)
+	}
+	results, err := s.Results("k", created.ID)
+	if err != nil {
+		t.Fatalf("results: %v", err)
+	}
+	if len(results) != 3 || results[0].CustomID != "a" || results[1].Type != OutcomeErrored || results[2].CustomID != "c" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if err = s.Delete("k", created.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if len(s.List("k")) != 0 {
+		t.Fatal("expected batch to be deleted")
+	}
+}
+
+func TestStoreCancelStopsPendingRequests(t *testing.T) {
+	s := NewStore("b_")
+	s.concurrency = 1
+	started := make(chan struct{})
+	release := make(chan struct{})
+	exec := func(_ context.Context, _ string, req Request) Outcome {
+		if req.CustomID == "first" {
+			close(started)
+			<-release
+		}
+		return Outcome{Type: OutcomeSucceeded}
+	}
+	created := s.Create("k", []Request{{CustomID: "first"}, {CustomID: "second"}, {CustomID: "third"}}, exec)
+	<-started
+	if _, err := s.Results("k", created.ID); !errors.Is(err, ErrNotEnded) {
+		t.Fatalf("expected results to be unavailable while processing, got %v", err)
+	}
+	canceled, err := s.Cancel("k", created.ID)
+	if err != nil || canceled.Status != StatusCanceling || canceled.CancelInitiatedAt == nil {
+		t.Fatalf("unexpected cancel result: %+v, %v", canceled, err)
+	}
+	close(release)
+
+	ended := waitEnded(t, s, "k", created.ID)
+	if ended.Counts != (Counts{Succeeded: 1, Canceled: 2}) {
+		t.Fatalf("unexpected counts after cancel: %+v", ended.Counts)
+	}
+}
@@ -0,0 +1,232 @@
+// Package budget enforces daily per-provider request capacity and the reservations that set
+// part of that capacity aside for specific client key groups.
+//
+// Usage is counted from the records emitted by the runtime executors, so every upstream attempt
+// (including retries and failures) consumes capacity. Keys that belong to a reservation group draw
+// from the shared pool first and fall back to their reservation, which keeps the reserved headroom
+// available for when the shared pool runs out.
+package budget
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+var defaultTracker = NewTracker()
+
+func init() {
+	coreusage.RegisterPlugin(defaultTracker)
+}
+
+// Default returns the process-wide tracker fed by usage records.
+func Default() *Tracker { return defaultTracker }
+
+// Tracker counts daily upstream requests per provider and decides whether a key may use more.
+type Tracker struct {
+	mu    sync.Mutex
+	now   func() time.Time
+	day   string
+	rules map[string]providerRule
+	usage map[string]*providerUsage
+}
+
+// providerRule is the normalized capacity split of one provider.
+type providerRule struct {
+	capacity int64
+	shared   int64
+	// reserved maps group name to reserved request count.
+	reserved map[string]int64
+	// keyGroups maps client API key to the groups it belongs to, in configuration order.
+	keyGroups map[string][]string
+}
+
+type providerUsage struct {
+	shared   int64
+	reserved map[string]int64
+}
+
+// ProviderStatus is a point-in-time view of one provider's budget.
+type ProviderStatus struct {
+	Provider     string           `json:"provider"`
+	Capacity     int64            `json:"capacity"`
+	SharedLimit  int64            `json:"shared_limit"`
+	SharedUsed   int64            `json:"shared_used"`
+	Reserved     map[string]int64 `json:"reserved,omitempty"`
+	ReservedUsed map[string]int64 `json:"reserved_used,omitempty"`
+}
+
+// NewTracker creates an empty tracker; call Configure to enable budgets.
+func NewTracker() *Tracker {
+	return &Tracker{now: time.Now, rules: map[string]providerRule{}, usage: map[string]*providerUsage{}}
+}
+
+// Configure replaces the budget rules. Counters for the current day are preserved.
+func (t *Tracker) Configure(cfg config.BudgetConfig) {
+	if t == nil {
+		return
+	}
+	rules := make(map[string]providerRule, len(cfg.DailyRequests))
+	for provider, capacity := range cfg.DailyRequests {
+		provider = normalizeProvider(provider)
+		if provider == "" || capacity <= 0 {
+			continue
+		}
+		rule := providerRule{capacity: capacity, reserved: map[string]int64{}, keyGroups: map[string][]string{}}
+		remaining := capacity
+		for _, reservation := range cfg.Reservations {
+			name := strings.TrimSpace(reservation.Name)
+			if name == "" || !reservationApplies(reservation, provider) {
+				continue
+			}
+			percent := math.Max(0, math.Min(100, reservation.Percent))
+			// Reservations are granted in configuration order and never exceed total capacity.
+			amount := min(int64(math.Floor(float64(capacity)*percent/100)), remaining)
+			remaining -= amount
+			rule.reserved[name] += amount
+			for _, key := range reservation.APIKeys {
+				if key = strings.TrimSpace(key); key != "" {
+					rule.keyGroups[key] = append(rule.keyGroups[key], name)
+				}
+			}
+		}
+		rule.shared = remaining
+		rules[provider] = rule
+	}
+	t.mu.Lock()
+	t.rules = rules
+	t.mu.Unlock()
+}
+
+// Allow reports whether apiKey may send another request to provider today.
+func (t *Tracker) Allow(apiKey, provider string) bool {
+	if t == nil {
+		return true
+	}
+	provider = normalizeProvider(provider)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rule, ok := t.rules[provider]
+	if !ok {
+		return true
+	}
+	used := t.usageLocked(provider)
+	if used.shared < rule.shared {
+		return true
+	}
+	for _, group := range rule.keyGroups[strings.TrimSpace(apiKey)] {
+		if used.reserved[group] < rule.reserved[group] {
+			return true
+		}
+	}
+	return false
+}
+
+// Record charges one request by apiKey against provider's budget.
+func (t *Tracker) Record(apiKey, provider string) {
+	if t == nil {
+		return
+	}
+	provider = normalizeProvider(provider)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rule, ok := t.rules[provider]
+	if !ok {
+		return
+	}
+	used := t.usageLocked(provider)
+	if used.shared < rule.shared {
+		used.shared++
+		return
+	}
+	for _, group := range rule.keyGroups[strings.TrimSpace(apiKey)] {
+		if used.reserved[group] < rule.reserved[group] {
+			used.reserved[group]++
+			return
+		}
+	}
+	// Requests admitted concurrently can overshoot; account them to the shared pool.
+	used.shared++
+}
+
+// HandleUsage implements coreusage.Plugin.
+func (t *Tracker) HandleUsage(_ context.Context, record coreusage.Record) {
+	t.Record(record.APIKey, record.Provider)
+}
+
+// ResetIn returns the time remaining until the daily counters reset.
+func (t *Tracker) ResetIn() time.Duration {
+	now := time.Now().UTC()
+	if t != nil && t.now != nil {
+		now = t.now().UTC()
+	}
+	next := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	return next.Sub(now)
+}
+
+// Snapshot reports the budget state of every configured provider, sorted by provider.
+func (t *Tracker) Snapshot() []ProviderStatus {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]ProviderStatus, 0, len(t.rules))
+	for provider, rule := range t.rules {
+		used := t.usageLocked(provider)
+		status := ProviderStatus{
+			Provider:    provider,
+			Capacity:    rule.capacity,
+			SharedLimit: rule.shared,
+			SharedUsed:  used.shared,
+		}
+		if len(rule.reserved) > 0 {
+			status.Reserved = make(map[string]int64, len(rule.reserved))
+			status.ReservedUsed = make(map[string]int64, len(rule.reserved))
+			for group, amount := range rule.reserved {
+				status.Reserved[group] = amount
+				status.ReservedUsed[group] = used.reserved[group]
+			}
+		}
+		out = append(out, status)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Provider < out[j].Provider })
+	return out
+}
+
+// usageLocked returns the counters for provider, starting a new window when the UTC day changes.
+func (t *Tracker) usageLocked(provider string) *providerUsage {
+	day := t.now().UTC().Format(time.DateOnly)
+	if day != t.day {
+		t.day = day
+		t.usage = map[string]*providerUsage{}
+	}
+	used, ok := t.usage[provider]
+	if !ok {
+		used = &providerUsage{reserved: map[string]int64{}}
+		t.usage[provider] = used
+	}
+	return used
+}
+
+func reservationApplies(reservation config.BudgetReservation, provider string) bool {
+	if len(reservation.Providers) == 0 {
+		return true
+	}
+	for _, candidate := range reservation.Providers {
+		if normalizeProvider(candidate) == provider {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeProvider(provider string) string {
+	return strings.ToLower(strings.TrimSpace(provider))
+}
@@ -0,0 +1,66 @@
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestTrackerReservations(t *testing.T) {
+	tracker := NewTracker()
+	now := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+	tracker.Configure(config.BudgetConfig{
+		DailyRequests: map[string]int64{"claude": 10},
+		Reservations: []config.BudgetReservation{
+			{Name: "on-call", Percent: 30, APIKeys: []string{"oncall-key"}},
+		},
+	})
+
+	for i := 0; i < 7; i++ {
+		if !tracker.Allow("team-key", "claude") {
+			t.Fatalf("request %d should fit in the shared pool", i+1)
+		}
+		tracker.Record("team-key", "claude")
+	}
+	if tracker.Allow("team-key", "claude") {
+		t.Fatal("unreserved key should be blocked once the shared pool is used up")
+	}
+	for i := 0; i < 3; i++ {
+		if !tracker.Allow("oncall-key", "claude") {
+			t.Fatalf("on-call request %d should use the reservation", i+1)
+		}
+		tracker.Record("oncall-key", "claude")
+	}
+	if tracker.Allow("oncall-key", "claude") {
+		t.Fatal("reservation should be exhausted")
+	}
+	if !tracker.Allow("team-key", "gemini") {
+		t.Fatal("providers without a daily limit must not be restricted")
+	}
+
+	now = now.Add(24 * time.Hour)
+	if !tracker.Allow("team-key", "claude") {
+		t.Fatal("counters should reset on the next UTC day")
+	}
+}
+
+func TestTrackerReservationsNeverExceedCapacity(t *testing.T) {
+	tracker := NewTracker()
+	tracker.Configure(config.BudgetConfig{
+		DailyRequests: map[string]int64{"codex": 100},
+		Reservations: []config.BudgetReservation{
+			{Name: "a", Percent: 80, APIKeys: []string{"a"}},
+			{Name: "b", Percent: 80, APIKeys: []string{"b"}, Providers: []string{"CODEX"}},
+		},
+	})
+	snapshot := tracker.Snapshot()
+	if len(snapshot) != 1 {
+		t.Fatalf("snapshot providers = %d, want 1", len(snapshot))
+	}
+	status := snapshot[0]
+	if status.Reserved["a"] != 80 || status.Reserved["b"] != 20 || status.SharedLimit != 0 {
+		t.Fatalf("unexpected split: reserved=%v shared=%d", status.Reserved, status.SharedLimit)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/hex"
 	"sync"
 	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/features"
 )
 
 // SignatureEntry holds a cached thinking signature with timestamp
@@ -94,10 +96,14 @@ func purgeExpiredSessions() {
 
 // CacheSignature stores a thinking signature for a given session and text.
 // Used for Claude models that require signed thinking blocks in multi-turn conversations.
+// Nothing is stored while the signature-cache feature flag is off.
 func CacheSignature(sessionID, text, signature string) {
 	if sessionID == "" || text == "" || signature == "" {
 		return
 	}
+	if !features.Enabled(features.SignatureCache) {
+		return
+	}
 	if len(signature) < MinValidSignatureLen {
 		return
 	}
@@ -115,9 +121,9 @@ func CacheSignature(sessionID, text, signature string) {
 }
 
 // GetCachedSignature retrieves a cached signature for a given session and text.
-// Returns empty string if not found or expired.
+// Returns empty string if not found, expired, or the signature-cache feature flag is off.
 func GetCachedSignature(sessionID, text string) string {
-	if sessionID == "" || text == "" {
+	if sessionID == "" || text == "" || !features.Enabled(features.SignatureCache) {
 		return ""
 	}
 
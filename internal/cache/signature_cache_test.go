@@ -3,6 +3,8 @@ package cache
 import (
 	"testing"
 	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/features"
 )
 
 func TestCacheSignature_BasicStorageAndRetrieval(t *testing.T) {
@@ -214,3 +216,24 @@ func TestCacheSignature_ExpirationLogic(t *testing.T) {
 	// but the logic is verified by the implementation
 	_ = time.Now() // Acknowledge we're not testing time passage
 }
+
+func TestCacheSignature_FeatureFlagOff(t *testing.T) {
+	ClearSignatureCache("")
+	sig := "flagSig12345678901234567890123456789012345678901234567"
+	CacheSignature("flag-session", "text", sig)
+
+	features.Default().SetOverride(features.SignatureCache, false)
+	defer features.Default().ClearOverride(features.SignatureCache)
+
+	if got := GetCachedSignature("flag-session", "text"); got != "" {
+		t.Errorf("Disabled cache should not return signatures, got '%s'", got)
+	}
+	CacheSignature("flag-session", "other", sig)
+	features.Default().ClearOverride(features.SignatureCache)
+	if got := GetCachedSignature("flag-session", "other"); got != "" {
+		t.Errorf("Disabled cache should not store signatures, got '%s'", got)
+	}
+	if got := GetCachedSignature("flag-session", "text"); got != sig {
+		t.Errorf("Re-enabled cache should serve earlier entries, got '%s'", got)
+	}
+}
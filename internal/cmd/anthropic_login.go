@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/claude"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
@@ -43,12 +42,11 @@ func DoClaudeLogin(cfg *config.Config, options *LoginOptions) {
 		var authErr *claude.AuthenticationError
 		if errors.As(err, &authErr) {
 			log.Error(claude.GetUserFriendlyMessage(authErr))
-			if authErr.Type == claude.ErrPortInUse.Type {
-				os.Exit(claude.ErrPortInUse.Code)
-			}
+			failWith(err, ExitAuth)
 			return
 		}
 		fmt.Printf("Claude authentication failed: %v\n", err)
+		failWith(err, ExitAuth)
 		return
 	}
 
@@ -31,6 +31,7 @@ func DoAntigravityLogin(cfg *config.Config, options *LoginOptions) {
 	record, savedPath, err := manager.Login(context.Background(), "antigravity", cfg, authOpts)
 	if err != nil {
 		log.Errorf("Antigravity authentication failed: %v", err)
+		failWith(err, ExitAuth)
 		return
 	}
 
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/claude"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/codex"
+)
+
+// Exit codes of the login, import, export and serve commands, so scripts can branch on the kind
+// of failure instead of parsing log output.
+const (
+	// ExitOK means the command succeeded.
+	ExitOK = 0
+	// ExitFailure is a failure without a more specific code.
+	ExitFailure = 1
+	// ExitConfig means the configuration, a command-line flag or an input file is invalid.
+	ExitConfig = 2
+	// ExitAuth means the login was rejected, denied or not completed in time.
+	ExitAuth = 3
+	// ExitNetwork means an upstream or storage backend could not be reached or was unavailable.
+	ExitNetwork = 4
+	// ExitQuota means an upstream refused the request because of quota or rate limits.
+	ExitQuota = 5
+	// ExitPartial means a command only partly succeeded, such as a directory import where some
+	// accounts could not be saved.
+	ExitPartial = 6
+	// ExitPortInUse means the OAuth callback or server port is taken by another process.
+	ExitPortInUse = 13
+)
+
+// ExitCodeHelp describes the exit codes for the usage output.
+const ExitCodeHelp = `Exit codes:
+  0   success
+  1   failure without a more specific code
+  2   invalid configuration, flag or input file
+  3   login rejected, denied or not completed in time
+  4   upstream or storage backend unreachable or unavailable
+  5   upstream quota or rate limit reached
+  6   partial success, such as a directory import with unsaved accounts
+  13  OAuth callback or server port in use
+`
+
+var exitCode atomic.Int32
+
+// ExitCode returns the exit code recorded by the command run in this process.
+func ExitCode() int {
+	return int(exitCode.Load())
+}
+
+// setExitCode records code as the exit code of the process.
+func setExitCode(code int) {
+	exitCode.Store(int32(code))
+}
+
+// failWith records the exit code of err, or fallback when err has no more specific code.
+func failWith(err error, fallback int) {
+	setExitCode(ExitCodeFor(err, fallback))
+}
+
+// ExitCodeFor returns the exit code of a command that failed with err: ports in use, network
+// failures and upstream statuses get their own codes, everything else gets fallback.
+func ExitCodeFor(err error, fallback int) int {
+	if err == nil {
+		return ExitOK
+	}
+	if isPortInUse(err) {
+		return ExitPortInUse
+	}
+	if isNetworkError(err) {
+		return ExitNetwork
+	}
+	var statusErr interface{ StatusCode() int }
+	if errors.As(err, &statusErr) {
+		if code := exitCodeForStatus(statusErr.StatusCode()); code != ExitOK {
+			return code
+		}
+	}
+	return fallback
+}
+
+func isPortInUse(err error) bool {
+	var codexErr *codex.AuthenticationError
+	if errors.As(err, &codexErr) && codexErr.Type == codex.ErrPortInUse.Type {
+		return true
+	}
+	var claudeErr *claude.AuthenticationError
+	if errors.As(err, &claudeErr) && claudeErr.Type == claude.ErrPortInUse.Type {
+		return true
+	}
+	return errors.Is(err, syscall.EADDRINUSE)
+}
+
+func isNetworkError(err error) bool {
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	if errors.As(err, &opErr) || errors.As(err, &dnsErr) {
+		return true
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && urlErr.Timeout()
+}
+
+func exitCodeForStatus(status int) int {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return ExitAuth
+	case status == http.StatusPaymentRequired || status == http.StatusTooManyRequests:
+		return ExitQuota
+	case status >= http.StatusInternalServerError:
+		return ExitNetwork
+	default:
+		return ExitOK
+	}
+}
+
+// statusError is an upstream HTTP error whose status selects the exit code.
+type statusError struct {
+	status int
+	err    error
+}
+
+func (e *statusError) Error() string   { return e.err.Error() }
+func (e *statusError) Unwrap() error   { return e.err }
+func (e *statusError) StatusCode() int { return e.status }
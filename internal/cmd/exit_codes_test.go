@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/codex"
+)
+
+func TestExitCodeFor(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: &os.SyscallError{Syscall: "connect", Err: syscall.ECONNREFUSED}}
+	listenErr := &net.OpError{Op: "listen", Net: "tcp", Err: &os.SyscallError{Syscall: "bind", Err: syscall.EADDRINUSE}}
+	cases := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, ExitOK},
+		{"unclassified", errors.New("boom"), ExitAuth},
+		{"port in use", codex.NewAuthenticationError(codex.ErrPortInUse, errors.New("bind")), ExitPortInUse},
+		{"listen", fmt.Errorf("failed to start HTTP server: %w", listenErr), ExitPortInUse},
+		{"dial", fmt.Errorf("token exchange: %w", dialErr), ExitNetwork},
+		{"wrapped in auth error", codex.NewAuthenticationError(codex.ErrCodeExchangeFailed, dialErr), ExitNetwork},
+		{"dns", &net.DNSError{Err: "no such host", Name: "example.invalid"}, ExitNetwork},
+		{"unauthorized", &statusError{status: http.StatusUnauthorized, err: errors.New("denied")}, ExitAuth},
+		{"rate limited", fmt.Errorf("onboard: %w", &statusError{status: http.StatusTooManyRequests, err: errors.New("slow down")}), ExitQuota},
+		{"unavailable", &statusError{status: http.StatusServiceUnavailable, err: errors.New("down")}, ExitNetwork},
+		{"bad request", &statusError{status: http.StatusBadRequest, err: errors.New("bad")}, ExitAuth},
+	}
+	for _, tc := range cases {
+		if got := ExitCodeFor(tc.err, ExitAuth); got != tc.want {
+			t.Errorf("%s: ExitCodeFor() = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+// DoExportCredential writes the stored credential id back in the native format of another tool
+// so it can be used there directly. The file is replaced atomically and an existing target is
+// kept as a timestamped backup next to it; an empty path writes to the tool's own location.
+func DoExportCredential(cfg *config.Config, id, format, path string) {
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	authDir, errResolve := util.ResolveAuthDir(cfg.AuthDir)
+	if errResolve != nil {
+		log.Errorf("export-credential: resolve auth dir failed: %v", errResolve)
+		setExitCode(ExitConfig)
+		return
+	}
+	id = strings.TrimSpace(id)
+	if id == "" || filepath.Base(id) != id {
+		log.Errorf("export-credential: invalid credential id %q", id)
+		setExitCode(ExitConfig)
+		return
+	}
+	if !strings.HasSuffix(id, ".json") {
+		id += ".json"
+	}
+	format = strings.ToLower(strings.TrimSpace(format))
+
+	data, errRead := os.ReadFile(filepath.Join(authDir, id))
+	if errRead != nil {
+		log.Errorf("export-credential: read credential failed: %v", errRead)
+		setExitCode(ExitConfig)
+		return
+	}
+	var metadata map[string]any
+	if errUnmarshal := json.Unmarshal(data, &metadata); errUnmarshal != nil {
+		log.Errorf("export-credential: invalid credential file: %v", errUnmarshal)
+		setExitCode(ExitConfig)
+		return
+	}
+	content, errExport := sdkAuth.ExportCredential(metadata, format)
+	if errExport != nil {
+		log.Errorf("export-credential: %v", errExport)
+		setExitCode(ExitConfig)
+		return
+	}
+
+	if path = strings.TrimSpace(path); path == "" {
+		defaultPath, errPath := sdkAuth.DefaultExportPath(format)
+		if errPath != nil {
+			log.Errorf("export-credential: %v", errPath)
+			setExitCode(ExitConfig)
+			return
+		}
+		path = defaultPath
+	}
+	previousBackup := newestCredentialBackup(path)
+	if errWrite := misc.WriteCredentialFile(path, content); errWrite != nil {
+		log.Errorf("export-credential: write failed: %v", errWrite)
+		setExitCode(ExitFailure)
+		return
+	}
+	if backup := newestCredentialBackup(path); backup != "" && backup != previousBackup {
+		fmt.Printf("Previous credentials backed up to %s\n", backup)
+	}
+	fmt.Printf("Credential %s exported as %s to %s\n", id, format, path)
+	fmt.Println("The proxy and the tool now share one refresh token; disable the credential in the proxy while you use it there.")
+}
+
+// newestCredentialBackup returns the most recent backup of the credential file at path, or an
+// empty string when there is none.
+func newestCredentialBackup(path string) string {
+	backups, err := misc.CredentialBackups(path)
+	if err != nil || len(backups) == 0 {
+		return ""
+	}
+	return backups[0]
+}
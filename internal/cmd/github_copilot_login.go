@@ -1,17 +1,16 @@
 package cmd
 
 import (
-	"context"
-	"fmt"
-
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
-	log "github.com/sirupsen/logrus"
 )
 
 // DoGitHubCopilotLogin triggers the OAuth device flow for GitHub Copilot and saves tokens.
 // It initiates the device flow authentication, displays the user code for the user to enter
-// at GitHub's verification URL, and waits for authorization before saving the tokens.
+// at GitHub's verification URL, and waits for authorization before saving the tokens. A GitHub token
+// provided by --github-token or the GITHUB_COPILOT_TOKEN environment variable skips the device flow.
+// The login asks for the organization whose Copilot seat to use unless --github-org names it; the
+// membership is verified before the login is saved.
 //
 // Parameters:
 //   - cfg: The application configuration containing proxy and auth directory settings
@@ -22,23 +21,33 @@ func DoGitHubCopilotLogin(cfg *config.Config, options *LoginOptions) {
 	}
 
 	manager := newAuthManager()
+	progress, ctx := newLoginProgress("GitHub Copilot login")
+	promptFn := options.Prompt
+	if promptFn == nil {
+		promptFn = defaultProjectPrompt()
+	}
+
 	authOpts := &sdkAuth.LoginOptions{
 		NoBrowser: options.NoBrowser,
 		Metadata:  map[string]string{},
-		Prompt:    options.Prompt,
+		Prompt:    promptFn,
+	}
+	if options.GitHubBaseURL != "" {
+		authOpts.Metadata["github_base_url"] = options.GitHubBaseURL
+	}
+	if options.GitHubToken != "" {
+		authOpts.Metadata["github_token"] = options.GitHubToken
+	}
+	if options.GitHubOrg != "" {
+		authOpts.Metadata["github_org"] = options.GitHubOrg
 	}
 
-	record, savedPath, err := manager.Login(context.Background(), "github-copilot", cfg, authOpts)
+	record, savedPath, err := manager.Login(ctx, "github-copilot", cfg, authOpts)
 	if err != nil {
-		log.Errorf("GitHub Copilot authentication failed: %v", err)
+		progress.Fail(err)
+		failWith(err, ExitAuth)
 		return
 	}
 
-	if savedPath != "" {
-		fmt.Printf("Authentication saved to %s\n", savedPath)
-	}
-	if record != nil && record.Label != "" {
-		fmt.Printf("Authenticated as %s\n", record.Label)
-	}
-	fmt.Println("GitHub Copilot authentication successful!")
+	progress.Success(loginSummary(record, savedPath)...)
 }
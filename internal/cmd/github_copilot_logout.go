@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	copilotauth "github.com/router-for-me/CLIProxyAPI/v6/internal/auth/copilot"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+// DoGitHubCopilotLogout revokes the GitHub token of a stored GitHub Copilot login and deletes the
+// login from the token store. A running proxy drops the credential as soon as its watcher sees
+// the deletion. The login is kept when GitHub does not accept the revocation, so it can be retried.
+//
+// Parameters:
+//   - cfg: The application configuration containing proxy and auth directory settings
+//   - id: The auth file name of the login, with or without the .json extension
+func DoGitHubCopilotLogout(cfg *config.Config, id string) {
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	id = strings.TrimSpace(id)
+	if id == "" || filepath.Base(id) != id {
+		log.Errorf("github-copilot-logout: invalid auth file %q", id)
+		setExitCode(ExitConfig)
+		return
+	}
+	if !strings.HasSuffix(id, ".json") {
+		id += ".json"
+	}
+
+	store := sdkAuth.GetTokenStore()
+	if setter, ok := store.(interface{ SetBaseDir(string) }); ok {
+		setter.SetBaseDir(cfg.AuthDir)
+	}
+	ctx := context.Background()
+	auths, errList := store.List(ctx)
+	if errList != nil {
+		log.Errorf("github-copilot-logout: list auth files failed: %v", errList)
+		failWith(errList, ExitFailure)
+		return
+	}
+	var target *coreauth.Auth
+	for _, auth := range auths {
+		if auth != nil && auth.ID == id {
+			target = auth
+			break
+		}
+	}
+	if target == nil {
+		log.Errorf("github-copilot-logout: auth file %s not found", id)
+		setExitCode(ExitConfig)
+		return
+	}
+	if target.Provider != "github-copilot" {
+		log.Errorf("github-copilot-logout: %s is a %s login, not a GitHub Copilot one", id, target.Provider)
+		setExitCode(ExitConfig)
+		return
+	}
+
+	if accessToken, _ := target.Metadata["access_token"].(string); accessToken != "" {
+		baseURL, _ := target.Metadata["github_base_url"].(string)
+		authSvc := copilotauth.NewCopilotAuthWithBaseURL(cfg, baseURL)
+		if errRevoke := authSvc.RevokeToken(ctx, accessToken); errRevoke != nil {
+			log.Errorf("github-copilot-logout: %v", errRevoke)
+			fmt.Println(copilotauth.GetUserFriendlyMessage(errRevoke))
+			failWith(errRevoke, ExitAuth)
+			return
+		}
+		fmt.Println("GitHub token revoked")
+	}
+
+	if errDelete := store.Delete(ctx, id); errDelete != nil {
+		log.Errorf("github-copilot-logout: delete %s failed: %v", id, errDelete)
+		failWith(errDelete, ExitFailure)
+		return
+	}
+	fmt.Printf("Removed GitHub Copilot login %s\n", id)
+}
@@ -36,6 +36,7 @@ func DoIFlowCookieAuth(cfg *config.Config, options *LoginOptions) {
 	cookie, err := promptForCookie(promptFn)
 	if err != nil {
 		fmt.Printf("Failed to get cookie: %v\n", err)
+		setExitCode(ExitConfig)
 		return
 	}
 
@@ -43,6 +44,7 @@ func DoIFlowCookieAuth(cfg *config.Config, options *LoginOptions) {
 	bxAuth := iflow.ExtractBXAuth(cookie)
 	if existingFile, err := iflow.CheckDuplicateBXAuth(cfg.AuthDir, bxAuth); err != nil {
 		fmt.Printf("Failed to check duplicate: %v\n", err)
+		failWith(err, ExitFailure)
 		return
 	} else if existingFile != "" {
 		fmt.Printf("Duplicate BXAuth found, authentication already exists: %s\n", filepath.Base(existingFile))
@@ -56,6 +58,7 @@ func DoIFlowCookieAuth(cfg *config.Config, options *LoginOptions) {
 	tokenData, err := auth.AuthenticateWithCookie(ctx, cookie)
 	if err != nil {
 		fmt.Printf("iFlow cookie authentication failed: %v\n", err)
+		failWith(err, ExitAuth)
 		return
 	}
 
@@ -68,6 +71,7 @@ func DoIFlowCookieAuth(cfg *config.Config, options *LoginOptions) {
 	// Save token to file
 	if err := tokenStorage.SaveTokenToFile(authFilePath); err != nil {
 		fmt.Printf("Failed to save authentication: %v\n", err)
+		failWith(err, ExitFailure)
 		return
 	}
 
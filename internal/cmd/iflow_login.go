@@ -35,9 +35,11 @@ func DoIFlowLogin(cfg *config.Config, options *LoginOptions) {
 		var emailErr *sdkAuth.EmailRequiredError
 		if errors.As(err, &emailErr) {
 			log.Error(emailErr.Error())
+			setExitCode(ExitConfig)
 			return
 		}
 		fmt.Printf("iFlow authentication failed: %v\n", err)
+		failWith(err, ExitAuth)
 		return
 	}
 
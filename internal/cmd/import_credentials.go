@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+// DoImportCredentials converts the credentials another tool stored on this machine into an auth
+// file of this proxy. source is one of sdkAuth.ImportSources(); an empty path reads the tool's
+// default location.
+func DoImportCredentials(cfg *config.Config, source, path, projectID string) {
+	record, err := sdkAuth.ImportCredentials(context.Background(), source, path, sdkAuth.ImportOptions{ProjectID: projectID})
+	if err != nil {
+		log.Errorf("import from %s failed: %v", strings.TrimSpace(source), err)
+		failWith(err, ExitConfig)
+		return
+	}
+
+	savedPath, err := newAuthManager().SaveAuth(record, cfg)
+	if err != nil {
+		log.Errorf("Failed to save auth: %v", err)
+		failWith(err, ExitFailure)
+		return
+	}
+	if savedPath != "" {
+		fmt.Printf("Authentication saved to %s\n", savedPath)
+	}
+	fmt.Printf("Imported %s credentials as %s\n", strings.TrimSpace(source), record.Provider)
+}
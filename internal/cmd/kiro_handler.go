@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+
+	kiroauth "github.com/router-for-me/CLIProxyAPI/v6/internal/auth/kiro"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// DoKiroProtocolHandler manages the kiro:// protocol handler used by Kiro Google/GitHub logins.
+// The handler scripts forward callbacks to the ports of kiro-callback-ports, so status and repair
+// also check that the installed scripts match the configured range. On Windows machines where
+// the registry cannot be written, install and repair leave a .reg file to import instead.
+//
+// Parameters:
+//   - cfg: The application configuration
+//   - action: One of install, uninstall, status or repair
+func DoKiroProtocolHandler(cfg *config.Config, action string) {
+	ports := kiroauth.HandlerPorts(cfg)
+	switch strings.ToLower(strings.TrimSpace(action)) {
+	case "install":
+		if err := kiroauth.InstallProtocolHandler(ports); err != nil {
+			reportHandlerInstallError("install", err)
+			return
+		}
+		fmt.Printf("Kiro protocol handler installed for callback ports %s\n", joinHandlerPorts(ports))
+	case "uninstall":
+		if !kiroauth.IsProtocolHandlerInstalled() {
+			fmt.Println("Kiro protocol handler is not installed")
+			return
+		}
+		if err := kiroauth.UninstallProtocolHandler(); err != nil {
+			log.Errorf("Failed to uninstall Kiro protocol handler: %v", err)
+			setExitCode(ExitFailure)
+			return
+		}
+		fmt.Println("Kiro protocol handler uninstalled")
+	case "status":
+		if !kiroauth.IsProtocolHandlerInstalled() {
+			fmt.Println("Kiro protocol handler: not installed")
+			if regFile := kiroauth.PendingRegFile(); regFile != "" {
+				fmt.Printf("Pending: import %s to finish the installation\n", regFile)
+			}
+			return
+		}
+		fmt.Println("Kiro protocol handler: installed")
+		if regFile := kiroauth.PendingRegFile(); regFile != "" {
+			fmt.Printf("Registered by: importing %s\n", regFile)
+		} else if runtime.GOOS == "windows" {
+			fmt.Println("Registered by: registry API")
+		}
+		fmt.Printf("Configured callback ports: %s\n", joinHandlerPorts(ports))
+		installed, err := kiroauth.InstalledProtocolHandlerPorts()
+		switch {
+		case err != nil:
+			fmt.Printf("Handler script: unreadable (%v)\n", err)
+		case len(installed) == 0:
+			fmt.Println("Handler script ports: unknown")
+		default:
+			fmt.Printf("Handler script ports: %s\n", joinHandlerPorts(installed))
+		}
+		if kiroauth.ProtocolHandlerPortsMatch(ports) {
+			fmt.Println("Status: up to date")
+		} else {
+			fmt.Println("Status: stale, run with repair to regenerate the handler scripts")
+		}
+	case "repair":
+		if kiroauth.IsProtocolHandlerInstalled() && kiroauth.ProtocolHandlerPortsMatch(ports) {
+			fmt.Println("Kiro protocol handler is up to date")
+			return
+		}
+		if err := kiroauth.InstallProtocolHandler(ports); err != nil {
+			reportHandlerInstallError("repair", err)
+			return
+		}
+		fmt.Printf("Kiro protocol handler regenerated for callback ports %s\n", joinHandlerPorts(ports))
+	default:
+		log.Errorf("Unknown Kiro handler action %q: use install, uninstall, status or repair", action)
+		setExitCode(ExitConfig)
+	}
+}
+
+// reportHandlerInstallError explains a failed install or repair. When a .reg file was written in
+// place of the registry update, the handler scripts are in place and only the import is missing.
+func reportHandlerInstallError(action string, err error) {
+	var regErr *kiroauth.RegFileError
+	if errors.As(err, &regErr) {
+		log.Warnf("Kiro protocol handler %s could not update the registry: %v", action, regErr.Err)
+		fmt.Printf("Registry updates are blocked on this machine. Wrote %s instead.\n", regErr.Path)
+		fmt.Println("Import it by double-clicking it, or ask your administrator to deploy it for your account,")
+		fmt.Println("then check the result with --kiro-handler status.")
+		setExitCode(ExitPartial)
+		return
+	}
+	log.Errorf("Failed to %s Kiro protocol handler: %v", action, err)
+	setExitCode(ExitFailure)
+	fmt.Println(kiroauth.GetHandlerInstructions())
+}
+
+// joinHandlerPorts formats ports for display.
+func joinHandlerPorts(ports []int) string {
+	parts := make([]string, len(ports))
+	for i, port := range ports {
+		parts[i] = fmt.Sprint(port)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -1,11 +1,14 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
+	"strings"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/i18n"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/loginui"
 	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -37,38 +40,90 @@ func DoKiroGoogleLogin(cfg *config.Config, options *LoginOptions) {
 	// Note: Kiro defaults to incognito mode for multi-account support.
 	// Users can override with --no-incognito if they want to use existing browser sessions.
 
-	manager := newAuthManager()
+	progress, ctx := newLoginProgress("Kiro Google login")
 
 	// Use KiroAuthenticator with Google login
 	authenticator := sdkAuth.NewKiroAuthenticator()
-	record, err := authenticator.LoginWithGoogle(context.Background(), cfg, &sdkAuth.LoginOptions{
+	record, err := authenticator.LoginWithGoogle(ctx, cfg, &sdkAuth.LoginOptions{
 		NoBrowser: options.NoBrowser,
-		Metadata:  map[string]string{},
+		Metadata:  map[string]string{"invitation_code": options.KiroInvitationCode},
 		Prompt:    options.Prompt,
 	})
-	if err != nil {
-		log.Errorf("Kiro Google authentication failed: %v", err)
-		fmt.Println("\nTroubleshooting:")
-		fmt.Println("1. Make sure the protocol handler is installed")
-		fmt.Println("2. Complete the Google login in the browser")
-		fmt.Println("3. If callback fails, try: --kiro-import (after logging in via Kiro IDE)")
-		return
+	finishKiroLogin(progress, cfg, record, err,
+		i18n.MsgKiroHintProtocolHandler,
+		i18n.MsgKiroHintGoogleLogin,
+		i18n.MsgKiroHintTryImport,
+	)
+}
+
+// DoKiroGitHubLogin triggers Kiro authentication with GitHub OAuth.
+// This uses a custom protocol handler (kiro://) to receive the callback.
+//
+// Parameters:
+//   - cfg: The application configuration
+//   - options: Login options including prompts
+func DoKiroGitHubLogin(cfg *config.Config, options *LoginOptions) {
+	if options == nil {
+		options = &LoginOptions{}
+	}
+	if options.NoBrowser && options.Prompt == nil {
+		options.Prompt = defaultProjectPrompt()
 	}
 
-	// Save the auth record
-	savedPath, err := manager.SaveAuth(record, cfg)
+	// Note: Kiro defaults to incognito mode for multi-account support.
+	// Users can override with --no-incognito if they want to use existing browser sessions.
+
+	progress, ctx := newLoginProgress("Kiro GitHub login")
+
+	// Use KiroAuthenticator with GitHub login
+	authenticator := sdkAuth.NewKiroAuthenticator()
+	record, err := authenticator.LoginWithGitHub(ctx, cfg, &sdkAuth.LoginOptions{
+		NoBrowser: options.NoBrowser,
+		Metadata:  map[string]string{"invitation_code": options.KiroInvitationCode},
+		Prompt:    options.Prompt,
+	})
+	finishKiroLogin(progress, cfg, record, err,
+		i18n.MsgKiroHintProtocolHandler,
+		i18n.MsgKiroHintGitHubLogin,
+		i18n.MsgKiroHintTryImport,
+	)
+}
+
+// DoKiroCognitoLogin triggers Kiro authentication with a Kiro email/password account.
+// It asks for the account email to pre-fill the Cognito sign-in page.
+//
+// Parameters:
+//   - cfg: The application configuration
+//   - options: Login options including prompts
+func DoKiroCognitoLogin(cfg *config.Config, options *LoginOptions) {
+	if options == nil {
+		options = &LoginOptions{}
+	}
+	if options.Prompt == nil {
+		options.Prompt = defaultProjectPrompt()
+	}
+
+	email, err := options.Prompt("Kiro account email (optional, press Enter to type it in the browser): ")
 	if err != nil {
-		log.Errorf("Failed to save auth: %v", err)
+		log.Errorf("Failed to read account email: %v", err)
+		setExitCode(ExitFailure)
 		return
 	}
 
-	if savedPath != "" {
-		fmt.Printf("Authentication saved to %s\n", savedPath)
-	}
-	if record != nil && record.Label != "" {
-		fmt.Printf("Authenticated as %s\n", record.Label)
-	}
-	fmt.Println("Kiro Google authentication successful!")
+	progress, ctx := newLoginProgress("Kiro Cognito login")
+
+	// Use KiroAuthenticator with Cognito login
+	authenticator := sdkAuth.NewKiroAuthenticator()
+	record, err := authenticator.LoginWithCognito(ctx, cfg, &sdkAuth.LoginOptions{
+		NoBrowser: options.NoBrowser,
+		Metadata:  map[string]string{"email": email, "invitation_code": options.KiroInvitationCode},
+		Prompt:    options.Prompt,
+	})
+	finishKiroLogin(progress, cfg, record, err,
+		i18n.MsgKiroHintProtocolHandler,
+		i18n.MsgKiroHintCognitoLogin,
+		i18n.MsgKiroHintTryImport,
+	)
 }
 
 // DoKiroAWSLogin triggers Kiro authentication with AWS Builder ID.
@@ -81,45 +136,69 @@ func DoKiroAWSLogin(cfg *config.Config, options *LoginOptions) {
 	if options == nil {
 		options = &LoginOptions{}
 	}
-	if options.NoBrowser && options.Prompt == nil {
+	// The login method is always asked for, so a prompt is needed even with a browser.
+	if options.Prompt == nil {
 		options.Prompt = defaultProjectPrompt()
 	}
 
 	// Note: Kiro defaults to incognito mode for multi-account support.
 	// Users can override with --no-incognito if they want to use existing browser sessions.
 
-	manager := newAuthManager()
+	progress, ctx := newLoginProgress("Kiro AWS Builder ID login")
 
 	// Use KiroAuthenticator with AWS Builder ID login (device code flow)
 	authenticator := sdkAuth.NewKiroAuthenticator()
-	record, err := authenticator.Login(context.Background(), cfg, &sdkAuth.LoginOptions{
+	record, err := authenticator.Login(ctx, cfg, &sdkAuth.LoginOptions{
 		NoBrowser: options.NoBrowser,
 		Metadata:  map[string]string{},
 		Prompt:    options.Prompt,
 	})
-	if err != nil {
-		log.Errorf("Kiro AWS authentication failed: %v", err)
-		fmt.Println("\nTroubleshooting:")
-		fmt.Println("1. Make sure you have an AWS Builder ID")
-		fmt.Println("2. Complete the authorization in the browser")
-		fmt.Println("3. If callback fails, try: --kiro-import (after logging in via Kiro IDE)")
-		return
-	}
+	finishKiroLogin(progress, cfg, record, err,
+		i18n.MsgKiroHintBuilderID,
+		i18n.MsgKiroHintAuthorize,
+		i18n.MsgKiroHintTryImport,
+	)
+}
 
-	// Save the auth record
-	savedPath, err := manager.SaveAuth(record, cfg)
-	if err != nil {
-		log.Errorf("Failed to save auth: %v", err)
-		return
+// DoKiroIDCLogin triggers Kiro authentication with an organization's AWS IAM Identity Center
+// using the SSO OIDC device code flow. The start URL is prompted for when empty; the region
+// defaults to us-east-1.
+//
+// Parameters:
+//   - cfg: The application configuration
+//   - options: Login options including prompts
+//   - startURL: The Identity Center start URL, e.g. https://my-org.awsapps.com/start
+//   - region: The AWS region of the Identity Center instance
+func DoKiroIDCLogin(cfg *config.Config, options *LoginOptions, startURL, region string) {
+	if options == nil {
+		options = &LoginOptions{}
 	}
-
-	if savedPath != "" {
-		fmt.Printf("Authentication saved to %s\n", savedPath)
+	if options.Prompt == nil {
+		options.Prompt = defaultProjectPrompt()
 	}
-	if record != nil && record.Label != "" {
-		fmt.Printf("Authenticated as %s\n", record.Label)
+
+	if strings.TrimSpace(startURL) == "" {
+		var err error
+		startURL, err = options.Prompt("IAM Identity Center start URL (e.g. https://my-org.awsapps.com/start): ")
+		if err != nil {
+			log.Errorf("Failed to read start URL: %v", err)
+			setExitCode(ExitFailure)
+			return
+		}
 	}
-	fmt.Println("Kiro AWS authentication successful!")
+
+	progress, ctx := newLoginProgress("Kiro Identity Center login")
+	authenticator := sdkAuth.NewKiroAuthenticator()
+	record, err := authenticator.Login(ctx, cfg, &sdkAuth.LoginOptions{
+		NoBrowser: options.NoBrowser,
+		Metadata:  map[string]string{"start_url": startURL, "region": region},
+		Prompt:    options.Prompt,
+	})
+	finishKiroLogin(progress, cfg, record, err,
+		i18n.MsgKiroHintIDCStartURL,
+		i18n.MsgKiroHintIDCEnabled,
+		i18n.MsgKiroHintAuthorize,
+	)
 }
 
 // DoKiroAWSAuthCodeLogin triggers Kiro authentication with AWS Builder ID using authorization code flow.
@@ -139,38 +218,20 @@ func DoKiroAWSAuthCodeLogin(cfg *config.Config, options *LoginOptions) {
 	// Note: Kiro defaults to incognito mode for multi-account support.
 	// Users can override with --no-incognito if they want to use existing browser sessions.
 
-	manager := newAuthManager()
+	progress, ctx := newLoginProgress("Kiro AWS Builder ID login (auth code)")
 
 	// Use KiroAuthenticator with AWS Builder ID login (authorization code flow)
 	authenticator := sdkAuth.NewKiroAuthenticator()
-	record, err := authenticator.LoginWithAuthCode(context.Background(), cfg, &sdkAuth.LoginOptions{
+	record, err := authenticator.LoginWithAuthCode(ctx, cfg, &sdkAuth.LoginOptions{
 		NoBrowser: options.NoBrowser,
 		Metadata:  map[string]string{},
 		Prompt:    options.Prompt,
 	})
-	if err != nil {
-		log.Errorf("Kiro AWS authentication (auth code) failed: %v", err)
-		fmt.Println("\nTroubleshooting:")
-		fmt.Println("1. Make sure you have an AWS Builder ID")
-		fmt.Println("2. Complete the authorization in the browser")
-		fmt.Println("3. If callback fails, try: --kiro-aws-login (device code flow)")
-		return
-	}
-
-	// Save the auth record
-	savedPath, err := manager.SaveAuth(record, cfg)
-	if err != nil {
-		log.Errorf("Failed to save auth: %v", err)
-		return
-	}
-
-	if savedPath != "" {
-		fmt.Printf("Authentication saved to %s\n", savedPath)
-	}
-	if record != nil && record.Label != "" {
-		fmt.Printf("Authenticated as %s\n", record.Label)
-	}
-	fmt.Println("Kiro AWS authentication successful!")
+	finishKiroLogin(progress, cfg, record, err,
+		i18n.MsgKiroHintBuilderID,
+		i18n.MsgKiroHintAuthorize,
+		i18n.MsgKiroHintTryDeviceCode,
+	)
 }
 
 // DoKiroImport imports Kiro token from Kiro IDE's token file.
@@ -185,33 +246,79 @@ func DoKiroImport(cfg *config.Config, options *LoginOptions) {
 		options = &LoginOptions{}
 	}
 
-	manager := newAuthManager()
+	progress, ctx := newLoginProgress("Kiro IDE token import")
 
 	// Use ImportFromKiroIDE instead of Login
 	authenticator := sdkAuth.NewKiroAuthenticator()
-	record, err := authenticator.ImportFromKiroIDE(context.Background(), cfg)
+	record, err := authenticator.ImportFromKiroIDE(ctx, cfg)
+	finishKiroLogin(progress, cfg, record, err,
+		i18n.MsgKiroHintOpenIDE,
+		i18n.MsgKiroHintIDESignIn,
+		i18n.MsgKiroHintCompleteLogin,
+		i18n.MsgKiroHintRunAgain,
+	)
+}
+
+// DoKiroImportDir imports every Kiro token file in dir, deduplicated by account, and saves each
+// as its own auth file.
+//
+// Parameters:
+//   - cfg: The application configuration
+//   - dir: Directory holding Kiro IDE exports or auth files from another proxy instance
+func DoKiroImportDir(cfg *config.Config, dir string) {
+	progress := loginui.New("Kiro directory import")
+	records, err := sdkAuth.ImportKiroTokenDir(dir)
 	if err != nil {
-		log.Errorf("Kiro token import failed: %v", err)
-		fmt.Println("\nMake sure you have logged in to Kiro IDE first:")
-		fmt.Println("1. Open Kiro IDE")
-		fmt.Println("2. Click 'Sign in with Google' (or GitHub)")
-		fmt.Println("3. Complete the login process")
-		fmt.Println("4. Run this command again")
+		progress.Fail(err)
+		failWith(err, ExitConfig)
+		return
+	}
+	if len(records) == 0 {
+		progress.Fail(fmt.Errorf("no Kiro tokens found in %s", dir))
+		setExitCode(ExitConfig)
 		return
 	}
 
-	// Save the imported auth record
-	savedPath, err := manager.SaveAuth(record, cfg)
-	if err != nil {
-		log.Errorf("Failed to save auth: %v", err)
+	manager := newAuthManager()
+	saved := 0
+	for _, record := range records {
+		savedPath, errSave := manager.SaveAuth(record, cfg)
+		if errSave != nil {
+			progress.Warn("Failed to save %s: %v", record.FileName, errSave)
+			continue
+		}
+		saved++
+		progress.Step("Imported %s", savedPath)
+	}
+	if saved == 0 {
+		progress.Fail(fmt.Errorf("none of the %d accounts could be saved", len(records)))
+		setExitCode(ExitFailure)
 		return
 	}
+	progress.Success(loginui.F("Saved", fmt.Sprintf("%d of %d accounts", saved, len(records))))
+	if saved < len(records) {
+		setExitCode(ExitPartial)
+	}
+}
 
-	if savedPath != "" {
-		fmt.Printf("Authentication saved to %s\n", savedPath)
+// finishKiroLogin saves the record of a finished Kiro login and reports the outcome, with the
+// troubleshooting hints when the login failed.
+func finishKiroLogin(progress *loginui.Progress, cfg *config.Config, record *coreauth.Auth, err error, hints ...i18n.Key) {
+	if err != nil {
+		lang := i18n.Default()
+		translated := make([]string, 0, len(hints))
+		for _, hint := range hints {
+			translated = append(translated, i18n.T(lang, hint))
+		}
+		progress.Fail(err, translated...)
+		failWith(err, ExitAuth)
+		return
 	}
-	if record != nil && record.Label != "" {
-		fmt.Printf("Imported as %s\n", record.Label)
+	savedPath, err := newAuthManager().SaveAuth(record, cfg)
+	if err != nil {
+		progress.Fail(fmt.Errorf("failed to save auth: %w", err))
+		failWith(err, ExitFailure)
+		return
 	}
-	fmt.Println("Kiro token import successful!")
+	progress.Success(loginSummary(record, savedPath)...)
 }
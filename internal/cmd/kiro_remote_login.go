@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mdp/qrterminal/v3"
+	kiroauth "github.com/router-for-me/CLIProxyAPI/v6/internal/auth/kiro"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
+	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+// kiroRemoteLoginTimeout bounds how long a remote login waits for its callback.
+const kiroRemoteLoginTimeout = 10 * time.Minute
+
+// DoKiroRemoteLogin runs a Kiro social login whose browser is on another device, for hosts
+// without a desktop such as a NAS or VPS. The login URL is shown as a terminal QR code. The
+// browser ends on a kiro:// address it cannot open; that address is pasted back here or submitted
+// to the management oauth-callback endpoint of a server sharing the auth directory.
+//
+// Parameters:
+//   - cfg: The application configuration
+//   - options: Login options including prompts and the invitation code
+//   - provider: The identity provider, google (default) or github
+func DoKiroRemoteLogin(cfg *config.Config, options *LoginOptions, provider string) {
+	if options == nil {
+		options = &LoginOptions{}
+	}
+	promptFn := options.Prompt
+	if promptFn == nil {
+		promptFn = defaultProjectPrompt()
+	}
+
+	socialProvider, err := kiroauth.ParseSocialProvider(provider)
+	if err != nil {
+		log.Errorf("Kiro remote login failed: %v", err)
+		setExitCode(ExitConfig)
+		return
+	}
+	login, err := sdkAuth.StartKiroRemoteLogin(cfg, socialProvider)
+	if err != nil {
+		log.Errorf("Kiro remote login failed: %v", err)
+		failWith(err, ExitFailure)
+		return
+	}
+
+	fmt.Printf("\nScan the QR code with the device that should sign in to Kiro (%s), or open this URL there:\n\n", socialProvider)
+	qrterminal.GenerateHalfBlock(login.AuthURL, qrterminal.L, os.Stdout)
+	fmt.Printf("\n%s\n\n", login.AuthURL)
+	fmt.Println("After signing in, the browser tries to open a kiro:// address. Copy that address and")
+	fmt.Println("paste it below, or POST it as redirect_url with provider kiro to the management")
+	fmt.Println("oauth-callback endpoint of the server using this auth directory.")
+
+	code, err := waitForKiroRemoteCallback(cfg.AuthDir, login.State, promptFn)
+	if err != nil {
+		log.Errorf("Kiro remote login failed: %v", err)
+		failWith(err, ExitAuth)
+		return
+	}
+
+	record, err := sdkAuth.CompleteKiroRemoteLogin(context.Background(), cfg, login, code, options.KiroInvitationCode)
+	if err != nil {
+		log.Errorf("%v", err)
+		failWith(err, ExitAuth)
+		return
+	}
+	savedPath, err := newAuthManager().SaveAuth(record, cfg)
+	if err != nil {
+		log.Errorf("Failed to save auth: %v", err)
+		failWith(err, ExitFailure)
+		return
+	}
+	if savedPath != "" {
+		fmt.Printf("Authentication saved to %s\n", savedPath)
+	}
+	fmt.Println("Kiro remote login successful!")
+}
+
+// waitForKiroRemoteCallback returns the authorization code of state from whichever arrives first:
+// a callback address pasted at the prompt or the callback file the management endpoint writes.
+func waitForKiroRemoteCallback(authDir, state string, promptFn func(string) (string, error)) (string, error) {
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 2)
+
+	go func() {
+		for {
+			input, errPrompt := promptFn("Callback address (or wait for the management endpoint): ")
+			if errPrompt != nil {
+				results <- result{err: errPrompt}
+				return
+			}
+			callback, errParse := misc.ParseOAuthCallback(input)
+			if errParse != nil {
+				fmt.Printf("Invalid callback address: %v\n", errParse)
+				continue
+			}
+			if callback == nil {
+				continue
+			}
+			if callback.State != state {
+				fmt.Println("The address belongs to another login; paste the one from this login.")
+				continue
+			}
+			if callback.Error != "" {
+				results <- result{err: fmt.Errorf("authorization failed: %s %s", callback.Error, callback.ErrorDescription)}
+				return
+			}
+			results <- result{code: callback.Code}
+			return
+		}
+	}()
+
+	callbackFile := filepath.Join(authDir, fmt.Sprintf(".oauth-kiro-%s.oauth", state))
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	timeout := time.After(kiroRemoteLoginTimeout)
+	for {
+		select {
+		case r := <-results:
+			return r.code, r.err
+		case <-timeout:
+			return "", fmt.Errorf("timed out waiting for the callback")
+		case <-ticker.C:
+			data, errRead := os.ReadFile(callbackFile)
+			if errRead != nil {
+				continue
+			}
+			_ = os.Remove(callbackFile)
+			var payload struct {
+				Code  string `json:"code"`
+				State string `json:"state"`
+				Error string `json:"error"`
+			}
+			if errUnmarshal := json.Unmarshal(data, &payload); errUnmarshal != nil {
+				return "", fmt.Errorf("invalid callback file: %w", errUnmarshal)
+			}
+			if payload.Error != "" {
+				return "", fmt.Errorf("authorization failed: %s", payload.Error)
+			}
+			if payload.State != state || payload.Code == "" {
+				return "", fmt.Errorf("callback file does not match this login")
+			}
+			fmt.Println("\nCallback received through the management endpoint.")
+			return payload.Code, nil
+		}
+	}
+}
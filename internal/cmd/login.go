@@ -78,12 +78,14 @@ func DoLogin(cfg *config.Config, projectID string, options *LoginOptions) {
 	record, errLogin := authenticator.Login(ctx, cfg, loginOpts)
 	if errLogin != nil {
 		log.Errorf("Gemini authentication failed: %v", errLogin)
+		failWith(errLogin, ExitAuth)
 		return
 	}
 
 	storage, okStorage := record.Storage.(*gemini.GeminiTokenStorage)
 	if !okStorage || storage == nil {
 		log.Error("Gemini authentication failed: unsupported token storage")
+		setExitCode(ExitFailure)
 		return
 	}
 
@@ -95,6 +97,7 @@ func DoLogin(cfg *config.Config, projectID string, options *LoginOptions) {
 	})
 	if errClient != nil {
 		log.Errorf("Gemini authentication failed: %v", errClient)
+		failWith(errClient, ExitAuth)
 		return
 	}
 
@@ -103,6 +106,7 @@ func DoLogin(cfg *config.Config, projectID string, options *LoginOptions) {
 	projects, errProjects := fetchGCPProjects(ctx, httpClient)
 	if errProjects != nil {
 		log.Errorf("Failed to get project list: %v", errProjects)
+		failWith(errProjects, ExitFailure)
 		return
 	}
 
@@ -110,10 +114,12 @@ func DoLogin(cfg *config.Config, projectID string, options *LoginOptions) {
 	projectSelections, errSelection := resolveProjectSelections(selectedProjectID, projects)
 	if errSelection != nil {
 		log.Errorf("Invalid project selection: %v", errSelection)
+		setExitCode(ExitConfig)
 		return
 	}
 	if len(projectSelections) == 0 {
 		log.Error("No project selected; aborting login.")
+		setExitCode(ExitConfig)
 		return
 	}
 
@@ -125,9 +131,11 @@ func DoLogin(cfg *config.Config, projectID string, options *LoginOptions) {
 			if errors.As(errSetup, &projectErr) {
 				log.Error("Failed to start user onboarding: A project ID is required.")
 				showProjectSelectionHelp(storage.Email, projects)
+				setExitCode(ExitConfig)
 				return
 			}
 			log.Errorf("Failed to complete user setup: %v", errSetup)
+			failWith(errSetup, ExitFailure)
 			return
 		}
 		finalID := strings.TrimSpace(storage.ProjectID)
@@ -145,10 +153,12 @@ func DoLogin(cfg *config.Config, projectID string, options *LoginOptions) {
 			isChecked, errCheck := checkCloudAPIIsEnabled(ctx, httpClient, pid)
 			if errCheck != nil {
 				log.Errorf("Failed to check if Cloud AI API is enabled for %s: %v", pid, errCheck)
+				failWith(errCheck, ExitFailure)
 				return
 			}
 			if !isChecked {
 				log.Errorf("Failed to check if Cloud AI API is enabled for project %s. If you encounter an error message, please create an issue.", pid)
+				setExitCode(ExitFailure)
 				return
 			}
 		}
@@ -165,6 +175,7 @@ func DoLogin(cfg *config.Config, projectID string, options *LoginOptions) {
 	savedPath, errSave := store.Save(ctx, record)
 	if errSave != nil {
 		log.Errorf("Failed to save token to file: %v", errSave)
+		failWith(errSave, ExitFailure)
 		return
 	}
 
@@ -319,7 +330,7 @@ func callGeminiCLI(ctx context.Context, httpClient *http.Client, endpoint string
 
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("api request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(bodyBytes)))
+		return &statusError{status: resp.StatusCode, err: fmt.Errorf("api request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(bodyBytes)))}
 	}
 
 	if result == nil {
@@ -352,7 +363,7 @@ func fetchGCPProjects(ctx context.Context, httpClient *http.Client) ([]interface
 
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("project list request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(bodyBytes)))
+		return nil, &statusError{status: resp.StatusCode, err: fmt.Errorf("project list request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(bodyBytes)))}
 	}
 
 	var projects interfaces.GCPProject
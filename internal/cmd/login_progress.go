@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/loginui"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// newLoginProgress starts reporting the login titled title and returns a context carrying it,
+// so the authenticators report their steps to the same progress.
+func newLoginProgress(title string) (*loginui.Progress, context.Context) {
+	progress := loginui.New(title)
+	return progress, loginui.NewContext(context.Background(), progress)
+}
+
+// loginSummary returns the final summary fields of a saved login.
+func loginSummary(record *coreauth.Auth, savedPath string) []loginui.Field {
+	account := ""
+	if record != nil {
+		if email, ok := record.Metadata["email"].(string); ok {
+			account = strings.TrimSpace(email)
+		}
+		if account == "" {
+			account = record.Label
+		}
+	}
+	return []loginui.Field{
+		loginui.F("Account", account),
+		loginui.F("Saved to", savedPath),
+	}
+}
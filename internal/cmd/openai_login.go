@@ -1,15 +1,11 @@
 package cmd
 
 import (
-	"context"
 	"errors"
-	"fmt"
-	"os"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/codex"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
-	log "github.com/sirupsen/logrus"
 )
 
 // LoginOptions contains options for the login processes.
@@ -22,6 +18,18 @@ type LoginOptions struct {
 	// CallbackPort overrides the local OAuth callback port when set (>0).
 	CallbackPort int
 
+	// KiroInvitationCode is passed to Kiro social logins to finish registration for waitlisted accounts.
+	KiroInvitationCode string
+
+	// GitHubBaseURL points GitHub Copilot logins at a GitHub Enterprise instance.
+	GitHubBaseURL string
+
+	// GitHubToken is a pre-provisioned GitHub token used by GitHub Copilot logins instead of the device flow.
+	GitHubToken string
+
+	// GitHubOrg binds GitHub Copilot logins to an organization's Copilot seat without prompting.
+	GitHubOrg string
+
 	// Prompt allows the caller to provide interactive input when needed.
 	Prompt func(prompt string) (string, error)
 }
@@ -44,6 +52,7 @@ func DoCodexLogin(cfg *config.Config, options *LoginOptions) {
 	}
 
 	manager := newAuthManager()
+	progress, ctx := newLoginProgress("Codex login")
 
 	authOpts := &sdkAuth.LoginOptions{
 		NoBrowser:    options.NoBrowser,
@@ -52,22 +61,18 @@ func DoCodexLogin(cfg *config.Config, options *LoginOptions) {
 		Prompt:       promptFn,
 	}
 
-	_, savedPath, err := manager.Login(context.Background(), "codex", cfg, authOpts)
+	record, savedPath, err := manager.Login(ctx, "codex", cfg, authOpts)
 	if err != nil {
 		var authErr *codex.AuthenticationError
 		if errors.As(err, &authErr) {
-			log.Error(codex.GetUserFriendlyMessage(authErr))
-			if authErr.Type == codex.ErrPortInUse.Type {
-				os.Exit(codex.ErrPortInUse.Code)
-			}
+			progress.Fail(errors.New(codex.GetUserFriendlyMessage(authErr)))
+			failWith(err, ExitAuth)
 			return
 		}
-		fmt.Printf("Codex authentication failed: %v\n", err)
+		progress.Fail(err)
+		failWith(err, ExitAuth)
 		return
 	}
 
-	if savedPath != "" {
-		fmt.Printf("Authentication saved to %s\n", savedPath)
-	}
-	fmt.Println("Codex authentication successful!")
+	progress.Success(loginSummary(record, savedPath)...)
 }
@@ -47,9 +47,11 @@ func DoQwenLogin(cfg *config.Config, options *LoginOptions) {
 		var emailErr *sdkAuth.EmailRequiredError
 		if errors.As(err, &emailErr) {
 			log.Error(emailErr.Error())
+			setExitCode(ExitConfig)
 			return
 		}
 		fmt.Printf("Qwen authentication failed: %v\n", err)
+		failWith(err, ExitAuth)
 		return
 	}
 
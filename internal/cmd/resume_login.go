@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+// DoResumeLogin completes a Claude or Codex login that was interrupted after the browser step.
+// It asks for the callback URL the browser was redirected to and exchanges its code with the
+// PKCE verifier saved when the login started.
+func DoResumeLogin(cfg *config.Config, options *LoginOptions) {
+	if options == nil {
+		options = &LoginOptions{}
+	}
+	promptFn := options.Prompt
+	if promptFn == nil {
+		promptFn = defaultProjectPrompt()
+	}
+
+	input, err := promptFn("Paste the callback URL of the interrupted login: ")
+	if err != nil {
+		log.Errorf("Failed to read callback URL: %v", err)
+		setExitCode(ExitFailure)
+		return
+	}
+	record, err := sdkAuth.ResumeLogin(context.Background(), cfg, input)
+	if err != nil {
+		log.Errorf("Resuming login failed: %v", err)
+		failWith(err, ExitAuth)
+		return
+	}
+
+	savedPath, err := newAuthManager().SaveAuth(record, cfg)
+	if err != nil {
+		log.Errorf("Failed to save auth: %v", err)
+		failWith(err, ExitFailure)
+		return
+	}
+	if savedPath != "" {
+		fmt.Printf("Authentication saved to %s\n", savedPath)
+	}
+	fmt.Printf("%s login resumed successfully!\n", record.Provider)
+}
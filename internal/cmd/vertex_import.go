@@ -30,22 +30,26 @@ func DoVertexImport(cfg *config.Config, keyPath string) {
 	rawPath := strings.TrimSpace(keyPath)
 	if rawPath == "" {
 		log.Errorf("vertex-import: missing service account key path")
+		setExitCode(ExitConfig)
 		return
 	}
 	data, errRead := os.ReadFile(rawPath)
 	if errRead != nil {
 		log.Errorf("vertex-import: read file failed: %v", errRead)
+		setExitCode(ExitConfig)
 		return
 	}
 	var sa map[string]any
 	if errUnmarshal := json.Unmarshal(data, &sa); errUnmarshal != nil {
 		log.Errorf("vertex-import: invalid service account json: %v", errUnmarshal)
+		setExitCode(ExitConfig)
 		return
 	}
 	// Validate and normalize private_key before saving
 	normalizedSA, errFix := vertex.NormalizeServiceAccountMap(sa)
 	if errFix != nil {
 		log.Errorf("vertex-import: %v", errFix)
+		setExitCode(ExitConfig)
 		return
 	}
 	sa = normalizedSA
@@ -53,6 +57,7 @@ func DoVertexImport(cfg *config.Config, keyPath string) {
 	projectID, _ := sa["project_id"].(string)
 	if strings.TrimSpace(projectID) == "" {
 		log.Errorf("vertex-import: project_id missing in service account json")
+		setExitCode(ExitConfig)
 		return
 	}
 	if strings.TrimSpace(email) == "" {
@@ -93,6 +98,7 @@ func DoVertexImport(cfg *config.Config, keyPath string) {
 	path, errSave := store.Save(context.Background(), record)
 	if errSave != nil {
 		log.Errorf("vertex-import: save credential failed: %v", errSave)
+		failWith(errSave, ExitFailure)
 		return
 	}
 	fmt.Printf("Vertex credentials imported: %s\n", path)
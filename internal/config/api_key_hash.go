@@ -0,0 +1,101 @@
+package config
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// APIKeyHashPrefix marks a client API key stored as a salted hash. The full format is
+// "cpa-sha256:<hint>:<salt>:<digest>", where hint is the first characters of the key so
+// operators can tell entries apart, and digest is hex SHA-256 over salt and key.
+const APIKeyHashPrefix = "cpa-sha256:"
+
+const (
+	apiKeySaltBytes = 16
+	apiKeyHintChars = 4
+)
+
+// HashAPIKey returns the salted hash form of a client API key. Already hashed values are
+// returned unchanged.
+func HashAPIKey(key string) (string, error) {
+	if IsHashedAPIKey(key) {
+		return key, nil
+	}
+	salt := make([]byte, apiKeySaltBytes)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("generate api key salt: %w", err)
+	}
+	hint := key
+	if len(hint) > apiKeyHintChars {
+		hint = hint[:apiKeyHintChars]
+	}
+	hint = strings.ReplaceAll(hint, ":", "_")
+	return APIKeyHashPrefix + hint + ":" + hex.EncodeToString(salt) + ":" + hex.EncodeToString(apiKeyDigest(salt, key)), nil
+}
+
+// IsHashedAPIKey reports whether stored is in the salted hash format.
+func IsHashedAPIKey(stored string) bool {
+	_, _, ok := parseHashedAPIKey(stored)
+	return ok
+}
+
+// VerifyAPIKey reports whether presented matches the configured entry stored, which may be
+// plaintext or hashed. Both forms are compared in constant time.
+func VerifyAPIKey(stored, presented string) bool {
+	if stored == "" || presented == "" {
+		return false
+	}
+	if salt, digest, ok := parseHashedAPIKey(stored); ok {
+		return subtle.ConstantTimeCompare(apiKeyDigest(salt, presented), digest) == 1
+	}
+	return subtle.ConstantTimeCompare([]byte(stored), []byte(presented)) == 1
+}
+
+// HashPlaintextAPIKeys replaces plaintext client API keys with salted hashes when hash-api-keys
+// is enabled. It reports whether any entry changed so callers can persist the config.
+func (c *SDKConfig) HashPlaintextAPIKeys() (bool, error) {
+	if c == nil || !c.HashAPIKeys {
+		return false, nil
+	}
+	changed := false
+	for i, key := range c.APIKeys {
+		if key == "" || IsHashedAPIKey(key) {
+			continue
+		}
+		hashed, err := HashAPIKey(key)
+		if err != nil {
+			return changed, err
+		}
+		c.APIKeys[i] = hashed
+		changed = true
+	}
+	return changed, nil
+}
+
+func parseHashedAPIKey(stored string) (salt, digest []byte, ok bool) {
+	rest, found := strings.CutPrefix(stored, APIKeyHashPrefix)
+	if !found {
+		return nil, nil, false
+	}
+	parts := strings.Split(rest, ":")
+	if len(parts) != 3 {
+		return nil, nil, false
+	}
+	salt, errSalt := hex.DecodeString(parts[1])
+	digest, errDigest := hex.DecodeString(parts[2])
+	if errSalt != nil || errDigest != nil || len(salt) == 0 || len(digest) != sha256.Size {
+		return nil, nil, false
+	}
+	return salt, digest, true
+}
+
+func apiKeyDigest(salt []byte, key string) []byte {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(key))
+	return h.Sum(nil)
+}
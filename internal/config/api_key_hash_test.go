@@ -0,0 +1,55 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHashAPIKeyVerify(t *testing.T) {
+	hashed, err := HashAPIKey("sk-client-123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hashed, APIKeyHashPrefix+"sk-c:") || !IsHashedAPIKey(hashed) {
+		t.Fatalf("unexpected hash format %q", hashed)
+	}
+	if again, _ := HashAPIKey("sk-client-123456"); again == hashed {
+		t.Fatal("hashes must be salted")
+	}
+	if rehashed, _ := HashAPIKey(hashed); rehashed != hashed {
+		t.Fatal("hashing a hash must be a no-op")
+	}
+	if !VerifyAPIKey(hashed, "sk-client-123456") || VerifyAPIKey(hashed, "sk-client-654321") {
+		t.Fatal("hashed key verification mismatch")
+	}
+	if !VerifyAPIKey("plain-key", "plain-key") || VerifyAPIKey("plain-key", "other") || VerifyAPIKey("", "") {
+		t.Fatal("plaintext key verification mismatch")
+	}
+}
+
+func TestLoadConfigHashesPlaintextAPIKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "# client keys\nhash-api-keys: true\napi-keys:\n  - \"key-one-plaintext\"\n  - \"key-two-plaintext\"\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(cfg.APIKeys) != 2 || !VerifyAPIKey(cfg.APIKeys[0], "key-one-plaintext") || !VerifyAPIKey(cfg.APIKeys[1], "key-two-plaintext") {
+		t.Fatalf("keys not hashed in memory: %v", cfg.APIKeys)
+	}
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(saved), "plaintext") || !strings.Contains(string(saved), APIKeyHashPrefix) {
+		t.Fatalf("config file still holds plaintext keys:\n%s", saved)
+	}
+	if !strings.Contains(string(saved), "# client keys") {
+		t.Fatalf("comments must survive the rewrite:\n%s", saved)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"os"
 	"strings"
 	"syscall"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	"gopkg.in/yaml.v3"
@@ -26,10 +27,26 @@ type Config struct {
 	Host string `yaml:"host" json:"-"`
 	// Port is the network port on which the API server will listen.
 	Port int `yaml:"port" json:"-"`
+	// ListenFamily limits the API, management and gRPC listeners to one address family: "ipv4",
+	// "ipv6" or "dual" (default), which accepts both where the host supports them.
+	ListenFamily string `yaml:"listen-family,omitempty" json:"-"`
+
+	// TrustedProxies lists proxy IPs or CIDR ranges whose X-Forwarded-For and X-Real-IP headers are
+	// honored when resolving client IPs for logs, allowlists and rate limits. Empty trusts no proxy.
+	TrustedProxies []string `yaml:"trusted-proxies,omitempty" json:"-"`
 
 	// TLS config controls HTTPS server settings.
 	TLS TLSConfig `yaml:"tls" json:"tls"`
 
+	// LocalListener configures unix socket and named pipe listeners that serve the same API as Port.
+	LocalListener LocalListenerConfig `yaml:"local-listener" json:"-"`
+
+	// GRPC configures the optional gRPC ingress for Gemini GenerateContent.
+	GRPC GRPCConfig `yaml:"grpc" json:"-"`
+
+	// ResponseCompression configures negotiated gzip/brotli compression of non-streaming responses.
+	ResponseCompression ResponseCompressionConfig `yaml:"response-compression" json:"-"`
+
 	// RemoteManagement nests management-related options under 'remote-management'.
 	RemoteManagement RemoteManagement `yaml:"remote-management" json:"-"`
 
@@ -66,6 +83,33 @@ type Config struct {
 	// Routing controls credential selection behavior.
 	Routing RoutingConfig `yaml:"routing" json:"routing"`
 
+	// LatencySLO tracks first-token latency against an objective per provider/model and can steer
+	// routing away from credentials that violate it.
+	LatencySLO LatencySLOConfig `yaml:"latency-slo,omitempty" json:"latency-slo,omitempty"`
+
+	// AccountRisk scores credentials by ban signals, burstiness and quota velocity and spaces out
+	// requests to high-risk ones.
+	AccountRisk AccountRiskConfig `yaml:"account-risk,omitempty" json:"account-risk,omitempty"`
+
+	// AuthDailyCap limits how much each credential is used per day so consumption spreads evenly
+	// across an account pool.
+	AuthDailyCap AuthDailyCapConfig `yaml:"auth-daily-cap,omitempty" json:"auth-daily-cap,omitempty"`
+
+	// CircuitBreaker skips credentials whose upstream keeps failing for a cooldown, then lets a
+	// single probe request decide whether they are used again.
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuit-breaker,omitempty" json:"circuit-breaker,omitempty"`
+
+	// Fingerprints gives each credential a stable set of client identification headers so accounts
+	// do not all present the same client.
+	Fingerprints FingerprintConfig `yaml:"fingerprints,omitempty" json:"fingerprints,omitempty"`
+
+	// TokenEstimation tunes local input token estimates per dominant script of the prompt.
+	TokenEstimation TokenEstimationConfig `yaml:"token-estimation,omitempty" json:"token-estimation,omitempty"`
+
+	// LocalFallback serves requests from a local OpenAI-compatible server, such as llama.cpp,
+	// when every remote credential is exhausted.
+	LocalFallback LocalFallbackConfig `yaml:"local-fallback,omitempty" json:"local-fallback,omitempty"`
+
 	// WebsocketAuth enables or disables authentication for the WebSocket API.
 	WebsocketAuth bool `yaml:"ws-auth" json:"ws-auth"`
 
@@ -79,6 +123,19 @@ type Config struct {
 	// Values: "ide" (default, CodeWhisperer) or "cli" (Amazon Q).
 	KiroPreferredEndpoint string `yaml:"kiro-preferred-endpoint" json:"kiro-preferred-endpoint"`
 
+	// KiroCallbackPorts sets the local ports the kiro:// protocol handler forwards OAuth callbacks to.
+	KiroCallbackPorts KiroCallbackPortsConfig `yaml:"kiro-callback-ports,omitempty" json:"kiro-callback-ports,omitempty"`
+
+	// KiroCallbackServer exposes the Kiro login callback server beyond loopback for remote and VM setups.
+	KiroCallbackServer KiroCallbackServerConfig `yaml:"kiro-callback-server,omitempty" json:"kiro-callback-server,omitempty"`
+
+	// KiroRefresh schedules the proactive refresh of Kiro OAuth credentials ahead of expiry.
+	KiroRefresh KiroRefreshConfig `yaml:"kiro-refresh,omitempty" json:"kiro-refresh,omitempty"`
+
+	// KiroSystemPromptContext sends the system prompt as a dedicated additionalContext entry of the
+	// current message instead of prepending it to the user message. Disabled by default.
+	KiroSystemPromptContext bool `yaml:"kiro-system-prompt-context" json:"kiro-system-prompt-context"`
+
 	// Codex defines a list of Codex API key configurations as specified in the YAML configuration file.
 	CodexKey []CodexKey `yaml:"codex-api-key" json:"codex-api-key"`
 
@@ -98,6 +155,10 @@ type Config struct {
 	// OAuthExcludedModels defines per-provider global model exclusions applied to OAuth/file-backed auth entries.
 	OAuthExcludedModels map[string][]string `yaml:"oauth-excluded-models,omitempty" json:"oauth-excluded-models,omitempty"`
 
+	// AuthDefaults assigns a default priority, weight and tags to auth files by file name or
+	// provider when they are loaded, so new accounts join the right tier without manual setup.
+	AuthDefaults []AuthDefaultRule `yaml:"auth-defaults,omitempty" json:"auth-defaults,omitempty"`
+
 	// OAuthModelMappings defines global model name mappings for OAuth/file-backed auth channels.
 	// These mappings affect both model listing and model routing for supported channels:
 	// gemini-cli, vertex, aistudio, antigravity, claude, codex, qwen, iflow.
@@ -114,6 +175,11 @@ type Config struct {
 	// from your current session. Default: false.
 	IncognitoBrowser bool `yaml:"incognito-browser" json:"incognito-browser"`
 
+	// LoginTimeouts sets how long interactive logins wait for the user to finish, in seconds,
+	// keyed by provider ("kiro", "github-copilot", "claude", ...). A "default" entry applies to
+	// providers without their own.
+	LoginTimeouts map[string]int `yaml:"login-timeouts,omitempty" json:"login-timeouts,omitempty"`
+
 	legacyMigrationPending bool `yaml:"-" json:"-"`
 }
 
@@ -127,6 +193,34 @@ type TLSConfig struct {
 	Key string `yaml:"key" json:"key"`
 }
 
+// LocalListenerConfig holds settings for local-only transports of the data plane.
+type LocalListenerConfig struct {
+	// UnixSocket is the filesystem path of a unix domain socket to serve the API on.
+	UnixSocket string `yaml:"unix-socket,omitempty"`
+	// NamedPipe is a Windows named pipe to serve the API on, e.g. \\.\pipe\cli-proxy-api.
+	NamedPipe string `yaml:"named-pipe,omitempty"`
+	// RequireAPIKey keeps API key authentication on local listeners. When false, access is
+	// governed by socket file permissions or the pipe ACL and API keys are not checked.
+	RequireAPIKey bool `yaml:"require-api-key,omitempty"`
+}
+
+// GRPCConfig controls the gRPC listener serving google.ai.generativelanguage GenerativeService.
+type GRPCConfig struct {
+	// Enable starts the gRPC listener when Port is also set.
+	Enable bool `yaml:"enable"`
+	// Port is the TCP port of the gRPC listener. It binds to the same host as the API server
+	// and uses the tls settings when those are enabled, otherwise cleartext HTTP/2 (h2c).
+	Port int `yaml:"port"`
+}
+
+// ResponseCompressionConfig controls compression of non-streaming HTTP responses.
+type ResponseCompressionConfig struct {
+	// Enable compresses responses for clients that send a matching Accept-Encoding.
+	Enable bool `yaml:"enable"`
+	// MinSize is the smallest body in bytes worth compressing. Zero uses the default of 1024.
+	MinSize int `yaml:"min-size,omitempty"`
+}
+
 // RemoteManagement holds management API configuration under 'remote-management'.
 type RemoteManagement struct {
 	// AllowRemote toggles remote (non-localhost) access to management API.
@@ -138,6 +232,27 @@ type RemoteManagement struct {
 	// PanelGitHubRepository overrides the GitHub repository used to fetch the management panel asset.
 	// Accepts either a repository URL (https://github.com/org/repo) or an API releases endpoint.
 	PanelGitHubRepository string `yaml:"panel-github-repository"`
+	// Host is the interface the dedicated management listener binds to when Port is set.
+	// Defaults to "127.0.0.1", or "::1" when listen-family is ipv6, so the management surface stays
+	// local unless explicitly exposed.
+	Host string `yaml:"host,omitempty"`
+	// Port moves the management API onto its own TCP listener when > 0.
+	// The data plane port then stops serving /v0/management routes.
+	Port int `yaml:"port,omitempty"`
+	// UnixSocket optionally serves the management API on a unix domain socket path.
+	// Connections over the socket are treated as local clients.
+	UnixSocket string `yaml:"unix-socket,omitempty"`
+	// AllowedOrigins lists browser origins (scheme://host[:port]) allowed to call the management API
+	// cross-origin with credentials. A "*" entry admits other origins without credentials. Empty
+	// keeps the wildcard policy; same-origin calls are always allowed.
+	AllowedOrigins []string `yaml:"allowed-origins,omitempty"`
+	// CSRFProtection requires a valid X-CSRF-Token header on state-changing management requests.
+	CSRFProtection bool `yaml:"csrf-protection,omitempty"`
+}
+
+// DedicatedListener reports whether the management API is served separately from the data plane.
+func (r RemoteManagement) DedicatedListener() bool {
+	return r.Port > 0 || strings.TrimSpace(r.UnixSocket) != ""
 }
 
 // QuotaExceeded defines the behavior when API quota limits are exceeded.
@@ -153,8 +268,170 @@ type QuotaExceeded struct {
 // RoutingConfig configures how credentials are selected for requests.
 type RoutingConfig struct {
 	// Strategy selects the credential selection strategy.
-	// Supported values: "round-robin" (default), "fill-first", "sticky".
+	// Supported values: "round-robin" (default), "fill-first", "sticky", "weighted", "latency".
 	Strategy string `yaml:"strategy,omitempty" json:"strategy,omitempty"`
+
+	// QuotaThreshold is the remaining quota percentage, per the latest Codex or Kiro usage
+	// snapshot, below which a credential is only used when every other one is below it too.
+	// Zero disables quota-aware routing.
+	QuotaThreshold float64 `yaml:"quota-threshold,omitempty" json:"quota-threshold,omitempty"`
+
+	// StickyPerModel scopes sticky bindings by provider and model instead of provider only, so the
+	// small-model background calls of a session can use other credentials than its main model.
+	StickyPerModel bool `yaml:"sticky-per-model,omitempty" json:"sticky-per-model,omitempty"`
+
+	// StickySessionHeader lets clients name their sticky session with the X-CLIProxy-Session-Key
+	// header, which then replaces the session key derived from the request.
+	StickySessionHeader bool `yaml:"sticky-session-header,omitempty" json:"sticky-session-header,omitempty"`
+
+	// StickyTTLSeconds is how long a sticky binding survives without requests (default 3600).
+	StickyTTLSeconds int `yaml:"sticky-ttl-seconds,omitempty" json:"sticky-ttl-seconds,omitempty"`
+
+	// StickySessionKeys lists where the sticky session key is taken from, first match wins:
+	// "conversation" (Codex session_id header or Claude Code metadata.user_id), "api-key",
+	// "user-agent", "header:<name>" and "json:<gjson path>" into the request body.
+	// Default: conversation, api-key, user-agent.
+	StickySessionKeys []string `yaml:"sticky-session-keys,omitempty" json:"sticky-session-keys,omitempty"`
+
+	// StickyProviders overrides the sticky TTL and session keys per provider.
+	StickyProviders map[string]StickyProviderConfig `yaml:"sticky-providers,omitempty" json:"sticky-providers,omitempty"`
+}
+
+// StickyProviderConfig overrides sticky routing settings for one provider.
+type StickyProviderConfig struct {
+	// TTLSeconds replaces sticky-ttl-seconds when positive.
+	TTLSeconds int `yaml:"ttl-seconds,omitempty" json:"ttl-seconds,omitempty"`
+
+	// SessionKeys replaces sticky-session-keys when not empty.
+	SessionKeys []string `yaml:"session-keys,omitempty" json:"session-keys,omitempty"`
+}
+
+// LatencySLOConfig defines the first-token latency objective.
+type LatencySLOConfig struct {
+	// FirstTokenMS is the time-to-first-byte threshold in milliseconds. 0 disables tracking.
+	FirstTokenMS int `yaml:"first-token-ms,omitempty" json:"first-token-ms,omitempty"`
+
+	// Target is the fraction of requests that must meet the threshold (default 0.95).
+	Target float64 `yaml:"target,omitempty" json:"target,omitempty"`
+
+	// WindowSeconds is the rolling window over which attainment is computed (default 900).
+	WindowSeconds int `yaml:"window-seconds,omitempty" json:"window-seconds,omitempty"`
+
+	// MinSamples is the number of samples required before a series can be in violation (default 20).
+	MinSamples int `yaml:"min-samples,omitempty" json:"min-samples,omitempty"`
+
+	// RoutingPenalty skips credentials violating the objective for a model while others are available.
+	RoutingPenalty bool `yaml:"routing-penalty,omitempty" json:"routing-penalty,omitempty"`
+}
+
+// AccountRiskConfig defines per-credential risk scoring and adaptive pacing.
+type AccountRiskConfig struct {
+	// Enabled turns on scoring and pacing.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// WindowSeconds is the rolling window the signals are collected over (default 3600).
+	WindowSeconds int `yaml:"window-seconds,omitempty" json:"window-seconds,omitempty"`
+
+	// PaceThreshold is the score, between 0 and 1, above which requests are paced (default 0.4).
+	PaceThreshold float64 `yaml:"pace-threshold,omitempty" json:"pace-threshold,omitempty"`
+
+	// MaxDelayMS is the gap enforced between requests of a credential scoring 1 (default 10000).
+	// The gap grows linearly from zero at PaceThreshold.
+	MaxDelayMS int `yaml:"max-delay-ms,omitempty" json:"max-delay-ms,omitempty"`
+
+	// MaxQueued is the number of requests that may wait out the pacing delay of one credential at
+	// a time (default 8). Further requests fail with 429 until the queue drains.
+	MaxQueued int `yaml:"max-queued,omitempty" json:"max-queued,omitempty"`
+}
+
+// AuthDailyCapConfig defines per-credential daily usage caps.
+type AuthDailyCapConfig struct {
+	// Requests is the number of upstream requests each credential may serve per day. 0 disables the cap.
+	Requests int64 `yaml:"requests,omitempty" json:"requests,omitempty"`
+
+	// Tokens is the number of tokens each credential may consume per day. 0 disables the cap.
+	Tokens int64 `yaml:"tokens,omitempty" json:"tokens,omitempty"`
+
+	// Timezone is the IANA zone whose midnight resets the counters (default: server local time).
+	Timezone string `yaml:"timezone,omitempty" json:"timezone,omitempty"`
+
+	// Providers restricts the caps to these providers; empty applies them to every OAuth credential.
+	Providers []string `yaml:"providers,omitempty" json:"providers,omitempty"`
+}
+
+// CircuitBreakerConfig defines the per-credential circuit breaker.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive upstream failures (transport errors, 408 and
+	// 5xx responses) that opens the circuit of a credential, once they span more than one model.
+	// 0 disables the breaker.
+	FailureThreshold int `yaml:"failure-threshold,omitempty" json:"failure-threshold,omitempty"`
+
+	// CooldownSeconds is how long an open circuit skips the credential before one probe request
+	// is let through (default: 60).
+	CooldownSeconds int `yaml:"cooldown-seconds,omitempty" json:"cooldown-seconds,omitempty"`
+}
+
+// FingerprintConfig defines the client fingerprint profiles credentials present upstream.
+type FingerprintConfig struct {
+	// Auto assigns credentials that do not name a profile to one of Profiles by a hash of their ID,
+	// so the assignment is stable across restarts.
+	Auto bool `yaml:"auto,omitempty" json:"auto,omitempty"`
+
+	// Profiles lists the available fingerprints. A credential selects one by name with the
+	// fingerprint_profile attribute or auth file field.
+	Profiles []FingerprintProfile `yaml:"profiles,omitempty" json:"profiles,omitempty"`
+}
+
+// FingerprintProfile is a named set of request headers identifying a client. Only headers are
+// changed; the TLS handshake is the one of the Go HTTP client.
+type FingerprintProfile struct {
+	Name            string            `yaml:"name" json:"name"`
+	UserAgent       string            `yaml:"user-agent,omitempty" json:"user-agent,omitempty"`
+	SecChUa         string            `yaml:"sec-ch-ua,omitempty" json:"sec-ch-ua,omitempty"`
+	SecChUaMobile   string            `yaml:"sec-ch-ua-mobile,omitempty" json:"sec-ch-ua-mobile,omitempty"`
+	SecChUaPlatform string            `yaml:"sec-ch-ua-platform,omitempty" json:"sec-ch-ua-platform,omitempty"`
+	AcceptLanguage  string            `yaml:"accept-language,omitempty" json:"accept-language,omitempty"`
+	Headers         map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+}
+
+// TokenEstimationConfig adjusts locally estimated token counts by the dominant script of the text.
+// Scripts are latin, chinese, japanese, korean, cyrillic and other.
+type TokenEstimationConfig struct {
+	// ScriptFactors overrides the built-in factors applied to Claude-family estimates, keyed by script.
+	ScriptFactors map[string]float64 `yaml:"script-factors,omitempty" json:"script-factors,omitempty"`
+
+	// ModelFactors sets factors for models whose lowercase name starts with the key, keyed by script.
+	// The longest matching prefix wins and takes precedence over ScriptFactors.
+	ModelFactors map[string]map[string]float64 `yaml:"model-factors,omitempty" json:"model-factors,omitempty"`
+}
+
+// LocalFallbackConfig configures the local fallback provider. An empty BaseURL disables it.
+type LocalFallbackConfig struct {
+	// BaseURL is the OpenAI-compatible endpoint of the local server, e.g. http://127.0.0.1:8080/v1.
+	BaseURL string `yaml:"base-url,omitempty" json:"base-url,omitempty"`
+
+	// APIKey is sent as a bearer token when the local server requires one.
+	APIKey string `yaml:"api-key,omitempty" json:"api-key,omitempty"`
+
+	// Model is sent upstream instead of the requested model when set.
+	Model string `yaml:"model,omitempty" json:"model,omitempty"`
+}
+
+// AuthDefaultRule supplies defaults for the auth files it matches. The first matching rule
+// applies, and values stored in the auth file itself take precedence.
+type AuthDefaultRule struct {
+	// Match is a glob matched against the auth file name, such as "kiro-google-*.json".
+	// Empty matches every file.
+	Match string `yaml:"match,omitempty" json:"match,omitempty"`
+	// Provider restricts the rule to one provider, such as "kiro". Empty matches every provider.
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty"`
+	// Priority is the selection tier; higher tiers are used first.
+	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
+	// Weight is the share within a tier under the weighted routing strategy; values below 2 mean
+	// an equal share.
+	Weight int `yaml:"weight,omitempty" json:"weight,omitempty"`
+	// Tags label the matched auths in the management API.
+	Tags []string `yaml:"tags,omitempty" json:"tags,omitempty"`
 }
 
 // ModelNameMapping defines a model ID mapping for a specific channel.
@@ -276,6 +553,10 @@ type ClaudeKey struct {
 
 	// ExcludedModels lists model IDs that should be excluded for this provider.
 	ExcludedModels []string `yaml:"excluded-models,omitempty" json:"excluded-models,omitempty"`
+
+	// StripThinkingSignatures removes thinking block signatures and redacted_thinking blocks from
+	// the conversation history, for Claude-compatible endpoints that reject these fields.
+	StripThinkingSignatures bool `yaml:"strip-thinking-signatures,omitempty" json:"strip-thinking-signatures,omitempty"`
 }
 
 // ClaudeModel describes a mapping between an alias and the actual upstream model name.
@@ -373,6 +654,96 @@ type GeminiModel struct {
 func (m GeminiModel) GetName() string  { return m.Name }
 func (m GeminiModel) GetAlias() string { return m.Alias }
 
+// KiroCallbackPortsConfig defines the consecutive port range of the Kiro login callback server.
+type KiroCallbackPortsConfig struct {
+	// Start is the first port tried (default 19876).
+	Start int `yaml:"start,omitempty" json:"start,omitempty"`
+
+	// Count is the number of consecutive ports tried (default 5).
+	Count int `yaml:"count,omitempty" json:"count,omitempty"`
+}
+
+// KiroCallbackServerConfig defines where the Kiro login callback server listens and how the
+// browser reaches it. Binding beyond loopback makes Kiro logins redirect straight to the server
+// instead of going through the kiro:// protocol handler.
+type KiroCallbackServerConfig struct {
+	// Bind is the address the server listens on (default both "127.0.0.1" and "::1"); "0.0.0.0" or
+	// "::" listens on all interfaces.
+	Bind string `yaml:"bind,omitempty" json:"bind,omitempty"`
+
+	// Host is the host name or IP the browser uses in the redirect URL. Defaults to Bind, or to
+	// the address of the outbound network interface when Bind is a wildcard.
+	Host string `yaml:"host,omitempty" json:"host,omitempty"`
+
+	// PathSecret prefixes the callback path so only holders of the login URL can reach it.
+	// A random secret is generated per login when it is empty and the server is exposed.
+	PathSecret string `yaml:"path-secret,omitempty" json:"path-secret,omitempty"`
+
+	// TLS serves the callback over HTTPS, with a self-signed certificate unless TLSCert and TLSKey are set.
+	TLS bool `yaml:"tls,omitempty" json:"tls,omitempty"`
+
+	// TLSCert is the path to a PEM certificate for TLS.
+	TLSCert string `yaml:"tls-cert,omitempty" json:"tls-cert,omitempty"`
+
+	// TLSKey is the path to the PEM private key for TLSCert.
+	TLSKey string `yaml:"tls-key,omitempty" json:"tls-key,omitempty"`
+}
+
+// KiroRefreshConfig defines when Kiro OAuth credentials are refreshed in the background.
+type KiroRefreshConfig struct {
+	// LeadSeconds is how long before expiry a token is refreshed (default 300).
+	LeadSeconds int `yaml:"lead-seconds,omitempty" json:"lead-seconds,omitempty"`
+
+	// JitterSeconds widens the lead by up to this many seconds per credential so a pool of tokens
+	// issued together is not refreshed at the same moment (default 60).
+	JitterSeconds int `yaml:"jitter-seconds,omitempty" json:"jitter-seconds,omitempty"`
+
+	// MaxBackoffSeconds caps the exponential backoff after consecutive failed refreshes of a
+	// credential (default 1800).
+	MaxBackoffSeconds int `yaml:"max-backoff-seconds,omitempty" json:"max-backoff-seconds,omitempty"`
+}
+
+// LoginTimeout returns how long an interactive login for provider waits for the user: the
+// provider's login-timeouts entry, else the "default" entry, else fallback.
+func (cfg *Config) LoginTimeout(provider string, fallback time.Duration) time.Duration {
+	if cfg == nil {
+		return fallback
+	}
+	for _, key := range []string{strings.ToLower(strings.TrimSpace(provider)), "default"} {
+		if seconds := cfg.LoginTimeouts[key]; seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return fallback
+}
+
+// Lead returns the configured refresh lead or its default.
+func (c KiroRefreshConfig) Lead() time.Duration {
+	if c.LeadSeconds <= 0 {
+		return 5 * time.Minute
+	}
+	return time.Duration(c.LeadSeconds) * time.Second
+}
+
+// Jitter returns the configured jitter window or its default.
+func (c KiroRefreshConfig) Jitter() time.Duration {
+	if c.JitterSeconds < 0 {
+		return 0
+	}
+	if c.JitterSeconds == 0 {
+		return time.Minute
+	}
+	return time.Duration(c.JitterSeconds) * time.Second
+}
+
+// MaxBackoff returns the configured backoff cap or its default.
+func (c KiroRefreshConfig) MaxBackoff() time.Duration {
+	if c.MaxBackoffSeconds <= 0 {
+		return 30 * time.Minute
+	}
+	return time.Duration(c.MaxBackoffSeconds) * time.Second
+}
+
 // KiroKey represents the configuration for Kiro (AWS CodeWhisperer) authentication.
 type KiroKey struct {
 	// TokenFile is the path to the Kiro token file (default: ~/.aws/sso/cache/kiro-auth-token.json)
@@ -400,6 +771,10 @@ type KiroKey struct {
 	// PreferredEndpoint sets the preferred Kiro API endpoint/quota.
 	// Values: "codewhisperer" (default, IDE quota) or "amazonq" (CLI quota).
 	PreferredEndpoint string `yaml:"preferred-endpoint,omitempty" json:"preferred-endpoint,omitempty"`
+
+	// DocumentTextExtraction replaces Claude document blocks (PDFs) with locally extracted text,
+	// since Kiro cannot accept file content.
+	DocumentTextExtraction bool `yaml:"document-text-extraction,omitempty" json:"document-text-extraction,omitempty"`
 }
 
 // OpenAICompatibility represents the configuration for OpenAI API compatibility
@@ -435,6 +810,10 @@ type OpenAICompatibilityAPIKey struct {
 
 	// ProxyURL overrides the global proxy setting for this API key if provided.
 	ProxyURL string `yaml:"proxy-url,omitempty" json:"proxy-url,omitempty"`
+
+	// DocumentTextExtraction replaces Claude document blocks (PDFs) with locally extracted text
+	// for providers that cannot accept file content.
+	DocumentTextExtraction bool `yaml:"document-text-extraction,omitempty" json:"document-text-extraction,omitempty"`
 }
 
 // OpenAICompatibilityModel represents a model configuration for OpenAI compatibility,
@@ -465,6 +844,18 @@ func LoadConfig(configFile string) (*Config, error) {
 // If optional is true and the file is missing, it returns an empty Config.
 // If optional is true and the file is empty or invalid, it returns an empty Config.
 func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
+	return loadConfig(configFile, optional, true)
+}
+
+// ReadConfig parses configFile like LoadConfig but never writes to it: plaintext secrets are
+// hashed and legacy keys migrated in memory only.
+func ReadConfig(configFile string) (*Config, error) {
+	return loadConfig(configFile, false, false)
+}
+
+// loadConfig reads and normalizes configFile. With persist, hashed secrets and migrated legacy
+// keys are written back to the file.
+func loadConfig(configFile string, optional, persist bool) (*Config, error) {
 	// Read the entire configuration file into memory.
 	data, err := os.ReadFile(configFile)
 	if err != nil {
@@ -525,7 +916,16 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 
 		// Persist the hashed value back to the config file to avoid re-hashing on next startup.
 		// Preserve YAML comments and ordering; update only the nested key.
-		_ = SaveConfigPreserveCommentsUpdateNestedScalar(configFile, []string{"remote-management", "secret-key"}, hashed)
+		if persist {
+			_ = SaveConfigPreserveCommentsUpdateNestedScalar(configFile, []string{"remote-management", "secret-key"}, hashed)
+		}
+	}
+
+	// Replace plaintext client API keys with salted hashes when requested, and persist them so the
+	// plaintext does not stay on disk.
+	apiKeysHashed, errHashKeys := cfg.HashPlaintextAPIKeys()
+	if errHashKeys != nil {
+		return nil, fmt.Errorf("failed to hash api keys: %w", errHashKeys)
 	}
 
 	cfg.RemoteManagement.PanelGitHubRepository = strings.TrimSpace(cfg.RemoteManagement.PanelGitHubRepository)
@@ -533,6 +933,19 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 		cfg.RemoteManagement.PanelGitHubRepository = DefaultPanelGitHubRepository
 	}
 
+	if cfg.GRPC.Port < 0 {
+		cfg.GRPC.Port = 0
+	}
+
+	cfg.LocalListener.UnixSocket = strings.TrimSpace(cfg.LocalListener.UnixSocket)
+	cfg.LocalListener.NamedPipe = strings.TrimSpace(cfg.LocalListener.NamedPipe)
+
+	cfg.RemoteManagement.Host = strings.TrimSpace(cfg.RemoteManagement.Host)
+	cfg.RemoteManagement.UnixSocket = strings.TrimSpace(cfg.RemoteManagement.UnixSocket)
+	if cfg.RemoteManagement.Port < 0 {
+		cfg.RemoteManagement.Port = 0
+	}
+
 	if cfg.LogsMaxTotalSizeMB < 0 {
 		cfg.LogsMaxTotalSizeMB = 0
 	}
@@ -564,6 +977,19 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 	// Normalize global OAuth model name mappings.
 	cfg.SanitizeOAuthModelMappings()
 
+	if !persist {
+		return &cfg, nil
+	}
+
+	if apiKeysHashed && !cfg.legacyMigrationPending {
+		if !optional && configFile != "" {
+			if err := SaveConfigPreserveComments(configFile, &cfg); err != nil {
+				return nil, fmt.Errorf("failed to persist hashed api keys: %w", err)
+			}
+			fmt.Println("Plaintext api-keys replaced with salted hashes.")
+		}
+	}
+
 	if cfg.legacyMigrationPending {
 		fmt.Println("Detected legacy configuration keys, attempting to persist the normalized config...")
 		if !optional && configFile != "" {
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoginTimeout(t *testing.T) {
+	var nilCfg *Config
+	if got := nilCfg.LoginTimeout("kiro", time.Minute); got != time.Minute {
+		t.Fatalf("nil config LoginTimeout() = %v, want fallback", got)
+	}
+
+	cfg := &Config{LoginTimeouts: map[string]int{"kiro": 3600, "default": 900, "qwen": 0}}
+	cases := map[string]time.Duration{
+		"kiro":   time.Hour,
+		" Kiro ": time.Hour,
+		"claude": 15 * time.Minute,
+		"qwen":   15 * time.Minute,
+	}
+	for provider, want := range cases {
+		if got := cfg.LoginTimeout(provider, time.Minute); got != want {
+			t.Fatalf("LoginTimeout(%q) = %v, want %v", provider, got, want)
+		}
+	}
+
+	cfg.LoginTimeouts = map[string]int{"kiro": 3600}
+	if got := cfg.LoginTimeout("claude", 5*time.Minute); got != 5*time.Minute {
+		t.Fatalf("LoginTimeout without default = %v, want fallback", got)
+	}
+}
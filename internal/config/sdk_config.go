@@ -9,6 +9,11 @@ type SDKConfig struct {
 	// ProxyURL is the URL of an optional proxy server to use for outbound requests.
 	ProxyURL string `yaml:"proxy-url" json:"proxy-url"`
 
+	// Language selects the language of user-facing messages: "en" (default) or "zh". API errors
+	// follow the client's Accept-Language header first; login commands fall back to the locale
+	// of the terminal when it is unset.
+	Language string `yaml:"language,omitempty" json:"language,omitempty"`
+
 	// ForceModelPrefix requires explicit model prefixes (e.g., "teamA/gemini-3-pro-preview")
 	// to target prefixed credentials. When false, unprefixed model requests may use prefixed
 	// credentials as well.
@@ -20,6 +25,10 @@ type SDKConfig struct {
 	// APIKeys is a list of keys for authenticating clients to this proxy server.
 	APIKeys []string `yaml:"api-keys" json:"api-keys"`
 
+	// HashAPIKeys stores api-keys as salted hashes: plaintext entries are hashed on load and when
+	// added through the management API, and the config file is rewritten without them.
+	HashAPIKeys bool `yaml:"hash-api-keys,omitempty" json:"hash-api-keys,omitempty"`
+
 	// Access holds request authentication provider configuration.
 	Access AccessConfig `yaml:"auth,omitempty" json:"auth,omitempty"`
 
@@ -29,6 +38,337 @@ type SDKConfig struct {
 	// NonStreamKeepAliveInterval controls how often blank lines are emitted for non-streaming responses.
 	// <= 0 disables keep-alives. Value is in seconds.
 	NonStreamKeepAliveInterval int `yaml:"nonstream-keepalive-interval,omitempty" json:"nonstream-keepalive-interval,omitempty"`
+
+	// Priority configures request priority classes and the concurrency gate that enforces them.
+	Priority PriorityConfig `yaml:"priority,omitempty" json:"priority,omitempty"`
+
+	// Budget configures daily provider capacity and the share reserved for specific key groups.
+	Budget BudgetConfig `yaml:"budget,omitempty" json:"budget,omitempty"`
+
+	// MaintenanceWindows exclude providers from routing and background token refresh while
+	// their upstream is down for planned maintenance.
+	MaintenanceWindows []MaintenanceWindow `yaml:"maintenance-windows,omitempty" json:"maintenance-windows,omitempty"`
+
+	// FeatureFlags switches optional proxy behavior by flag name. Flags left out keep their
+	// defaults; the management API can override them at runtime.
+	FeatureFlags map[string]bool `yaml:"feature-flags,omitempty" json:"feature-flags,omitempty"`
+
+	// ModelPolicies restrict which models the listed client API keys may request.
+	ModelPolicies []ModelPolicy `yaml:"model-policies,omitempty" json:"model-policies,omitempty"`
+
+	// ModelRenames map deprecated model IDs to their successors, extending the built-in table. A
+	// request for a deprecated ID that no credential serves anymore is sent to the successor.
+	ModelRenames []ModelRename `yaml:"model-renames,omitempty" json:"model-renames,omitempty"`
+
+	// FallbackChains declare model aliases served by an ordered list of models, which may belong
+	// to different providers. A request for an alias moves on to the next model when the current
+	// one fails with a quota or server error.
+	FallbackChains []FallbackChain `yaml:"fallback-chains,omitempty" json:"fallback-chains,omitempty"`
+
+	// ReplayQueue persists non-streaming requests that failed on transient provider errors so
+	// they can be replayed from the management API once the provider recovers.
+	ReplayQueue ReplayQueueConfig `yaml:"replay-queue,omitempty" json:"replay-queue,omitempty"`
+
+	// Sampling stores a fraction of prompt/response pairs, with credentials redacted, for offline
+	// comparison of the backends serving the same workload.
+	Sampling SamplingConfig `yaml:"sampling,omitempty" json:"sampling,omitempty"`
+
+	// Archive streams completed request/response exchanges, grouped by conversation, to
+	// S3-compatible object storage for compliance. Uploads run on a background worker.
+	Archive ArchiveConfig `yaml:"archive,omitempty" json:"archive,omitempty"`
+
+	// Provenance adds the serving provider and credential to the responses of opted-in keys.
+	Provenance ProvenanceConfig `yaml:"provenance,omitempty" json:"provenance,omitempty"`
+
+	// ConversationCap tracks cumulative tokens per conversation and optionally caps them.
+	ConversationCap ConversationCapConfig `yaml:"conversation-cap,omitempty" json:"conversation-cap,omitempty"`
+
+	// LoopDetection detects agents repeating the same tool call and optionally breaks the loop.
+	LoopDetection LoopDetectionConfig `yaml:"loop-detection,omitempty" json:"loop-detection,omitempty"`
+
+	// StructuredOutputValidation validates streamed output incrementally when a JSON response_format
+	// is requested, holding chunks back until the output starts as JSON and aborting the stream
+	// as soon as the model diverges from JSON.
+	StructuredOutputValidation bool `yaml:"structured-output-validation,omitempty" json:"structured-output-validation,omitempty"`
+
+	// ImageDedup replaces repeated copies of the same inline image in a conversation's history
+	// with a text reference to its first occurrence before the request is sent upstream.
+	ImageDedup bool `yaml:"image-dedup,omitempty" json:"image-dedup,omitempty"`
+
+	// ImageDownscale resizes and recompresses inline images that exceed the limits of the
+	// providers serving the request.
+	ImageDownscale ImageDownscaleConfig `yaml:"image-downscale,omitempty" json:"image-downscale,omitempty"`
+
+	// CapabilityChecks skips providers lacking a feature the request needs (vision, tools,
+	// thinking, json_mode) instead of letting them silently drop it.
+	CapabilityChecks CapabilityChecksConfig `yaml:"capability-checks,omitempty" json:"capability-checks,omitempty"`
+
+	// StrictRequestFields rejects OpenAI and Claude requests carrying top-level fields that the
+	// translation to a selected provider would silently drop.
+	StrictRequestFields bool `yaml:"strict-request-fields,omitempty" json:"strict-request-fields,omitempty"`
+}
+
+// CapabilityChecksConfig configures provider feature flags used for capability checks.
+type CapabilityChecksConfig struct {
+	// Enable turns on capability checks.
+	Enable bool `yaml:"enable" json:"enable"`
+
+	// Providers overrides the built-in feature flags per provider name.
+	Providers map[string]ProviderFeatures `yaml:"providers,omitempty" json:"providers,omitempty"`
+}
+
+// ProviderFeatures declares which request features a provider supports. Unset flags fall back to
+// the built-in defaults, which assume support unless a provider is known to lack the feature.
+type ProviderFeatures struct {
+	Vision   *bool `yaml:"vision,omitempty" json:"vision,omitempty"`
+	Tools    *bool `yaml:"tools,omitempty" json:"tools,omitempty"`
+	Thinking *bool `yaml:"thinking,omitempty" json:"thinking,omitempty"`
+	JSONMode *bool `yaml:"json-mode,omitempty" json:"json-mode,omitempty"`
+}
+
+// ImageDownscaleConfig configures automatic downscaling of oversized inline images.
+type ImageDownscaleConfig struct {
+	// Enable turns on image downscaling.
+	Enable bool `yaml:"enable" json:"enable"`
+
+	// Quality is the JPEG quality (1-100) used when an image has to be recompressed (default 85).
+	Quality int `yaml:"quality,omitempty" json:"quality,omitempty"`
+
+	// Limits overrides the built-in limits per provider name. The "default" entry applies to
+	// providers without a built-in or configured limit.
+	Limits map[string]ImageLimit `yaml:"limits,omitempty" json:"limits,omitempty"`
+}
+
+// ImageLimit bounds the size of a single inline image. Zero values leave that bound unchecked.
+type ImageLimit struct {
+	// MaxDimension is the maximum width or height in pixels.
+	MaxDimension int `yaml:"max-dimension,omitempty" json:"max-dimension,omitempty"`
+
+	// MaxBytes is the maximum size of the base64-encoded image data.
+	MaxBytes int `yaml:"max-bytes,omitempty" json:"max-bytes,omitempty"`
+}
+
+// LoopDetectionConfig configures detection of repeated identical tool calls in a conversation.
+type LoopDetectionConfig struct {
+	// Enable turns on loop detection.
+	Enable bool `yaml:"enable" json:"enable"`
+
+	// Threshold is the number of consecutive identical tool calls treated as a loop (default 3).
+	Threshold int `yaml:"threshold,omitempty" json:"threshold,omitempty"`
+
+	// Action is "alert" (default) to log a warning, or "tool-error" to replace the latest tool
+	// result with a synthetic error telling the model to stop repeating the call.
+	Action string `yaml:"action,omitempty" json:"action,omitempty"`
+}
+
+// ConversationCapConfig limits the tokens a single conversation may consume. Conversations are
+// identified by the same session keys the sticky routing strategy uses.
+type ConversationCapConfig struct {
+	// Enable turns on per-conversation token tracking.
+	Enable bool `yaml:"enable" json:"enable"`
+
+	// MaxTokens is the cumulative token cap per conversation. 0 tracks without enforcing.
+	MaxTokens int64 `yaml:"max-tokens,omitempty" json:"max-tokens,omitempty"`
+
+	// Action is applied once a conversation exceeds MaxTokens: "reject" (default) fails the
+	// request, "warn" injects a system note asking the model to wrap up.
+	Action string `yaml:"action,omitempty" json:"action,omitempty"`
+
+	// IdleTTLMinutes forgets conversations idle for this long (default 120).
+	IdleTTLMinutes int `yaml:"idle-ttl-minutes,omitempty" json:"idle-ttl-minutes,omitempty"`
+}
+
+// ReplayQueueConfig controls capture and delivery of replayable requests.
+type ReplayQueueConfig struct {
+	// APIKeys opts client API keys into capture. Requests from other keys are never persisted.
+	APIKeys []string `yaml:"api-keys,omitempty" json:"api-keys,omitempty"`
+
+	// Dir is where queued requests are stored. Defaults to "replay-queue" next to the config file,
+	// or under WRITABLE_PATH when set. It must not be inside auth-dir, whose files are loaded as
+	// credentials.
+	Dir string `yaml:"dir,omitempty" json:"dir,omitempty"`
+
+	// WebhookURL receives the outcome of every replayed request as a JSON POST.
+	WebhookURL string `yaml:"webhook-url,omitempty" json:"webhook-url,omitempty"`
+
+	// WebhookSecret, when set, signs webhook bodies with HMAC-SHA256 in the X-CLIProxy-Signature header.
+	WebhookSecret string `yaml:"webhook-secret,omitempty" json:"-"`
+
+	// MaxEntries caps the number of stored requests; the oldest are dropped first. <= 0 uses 1000.
+	MaxEntries int `yaml:"max-entries,omitempty" json:"max-entries,omitempty"`
+}
+
+// SamplingConfig controls capture of prompt/response pairs for quality evaluation.
+type SamplingConfig struct {
+	// Rate is the fraction of successful requests to sample, between 0 and 1. 0 disables sampling.
+	Rate float64 `yaml:"rate,omitempty" json:"rate,omitempty"`
+
+	// Dir is where samples are stored. Defaults to "samples" next to the config file, or under
+	// WRITABLE_PATH when set. It must not be inside auth-dir, whose files are loaded as credentials.
+	Dir string `yaml:"dir,omitempty" json:"dir,omitempty"`
+
+	// MaxEntries caps the number of stored samples; the oldest are dropped first. <= 0 uses 1000.
+	MaxEntries int `yaml:"max-entries,omitempty" json:"max-entries,omitempty"`
+}
+
+// ArchiveConfig controls the conversation archive. Records are batched into JSON Lines objects
+// and uploaded to an S3-compatible bucket, such as Amazon S3 or Google Cloud Storage through its
+// XML API with HMAC keys.
+type ArchiveConfig struct {
+	// Enabled turns the archive on.
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+
+	// Content selects what is archived: "metadata" (default) keeps the SHA-256 hashes of the
+	// request and response, "full" also keeps their text with credentials redacted.
+	Content string `yaml:"content,omitempty" json:"content,omitempty"`
+
+	// APIKeys limits archival to these client API keys. Empty archives every request.
+	APIKeys []string `yaml:"api-keys,omitempty" json:"api-keys,omitempty"`
+
+	// Endpoint is the URL of the storage service, such as "https://s3.us-east-1.amazonaws.com"
+	// or "https://storage.googleapis.com". An http:// endpoint disables TLS.
+	Endpoint string `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
+
+	// Bucket receives the archive objects; it must already exist.
+	Bucket string `yaml:"bucket,omitempty" json:"bucket,omitempty"`
+
+	// Prefix is prepended to object keys, which are "<prefix>/YYYY/MM/DD/<id>.jsonl".
+	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+
+	// Region of the bucket, when the service needs one.
+	Region string `yaml:"region,omitempty" json:"region,omitempty"`
+
+	// AccessKey and SecretKey authenticate the uploads.
+	AccessKey string `yaml:"access-key,omitempty" json:"access-key,omitempty"`
+	SecretKey string `yaml:"secret-key,omitempty" json:"secret-key,omitempty"`
+
+	// PathStyle addresses the bucket in the URL path instead of the host name.
+	PathStyle bool `yaml:"path-style,omitempty" json:"path-style,omitempty"`
+
+	// BatchSize is the number of records per object. <= 0 uses 100.
+	BatchSize int `yaml:"batch-size,omitempty" json:"batch-size,omitempty"`
+
+	// FlushIntervalSeconds uploads a partial batch after this many seconds. <= 0 uses 60.
+	FlushIntervalSeconds int `yaml:"flush-interval-seconds,omitempty" json:"flush-interval-seconds,omitempty"`
+
+	// QueueSize caps the records waiting for upload; records beyond it are dropped and logged.
+	// <= 0 uses 10000.
+	QueueSize int `yaml:"queue-size,omitempty" json:"queue-size,omitempty"`
+
+	// MaxRetries is the number of upload retries of a batch, with exponential backoff, before it
+	// is dropped. <= 0 uses 5.
+	MaxRetries int `yaml:"max-retries,omitempty" json:"max-retries,omitempty"`
+}
+
+// ProvenanceConfig controls the provenance block added to responses. Non-streaming JSON
+// responses get a "cliproxy" field; streams end with a "cliproxy" SSE comment.
+type ProvenanceConfig struct {
+	// APIKeys opts client API keys into provenance. Responses to other keys are left unchanged.
+	APIKeys []string `yaml:"api-keys,omitempty" json:"api-keys,omitempty"`
+}
+
+// BudgetConfig describes per-provider daily request capacity. Counters reset at 00:00 UTC and
+// are kept in memory, so a restart starts a fresh window.
+type BudgetConfig struct {
+	// DailyRequests maps provider identifiers (e.g. "claude", "gemini-cli") to the number of
+	// upstream requests allowed per day. Providers that are not listed are unbudgeted.
+	DailyRequests map[string]int64 `yaml:"daily-requests,omitempty" json:"daily-requests,omitempty"`
+
+	// Reservations set aside a percentage of each budgeted provider's capacity for a key group.
+	// Capacity not reserved by any group is shared by all keys.
+	Reservations []BudgetReservation `yaml:"reservations,omitempty" json:"reservations,omitempty"`
+}
+
+// BudgetReservation reserves part of the daily capacity for the listed client API keys.
+type BudgetReservation struct {
+	// Name identifies the group in logs and management output.
+	Name string `yaml:"name" json:"name"`
+
+	// Percent is the share of daily capacity reserved for the group, between 0 and 100.
+	Percent float64 `yaml:"percent" json:"percent"`
+
+	// APIKeys lists the client API keys that belong to the group.
+	APIKeys []string `yaml:"api-keys" json:"api-keys"`
+
+	// Providers optionally limits the reservation to specific providers. Empty applies to all budgeted providers.
+	Providers []string `yaml:"providers,omitempty" json:"providers,omitempty"`
+}
+
+// MaintenanceWindow declares a period during which a provider is unavailable.
+type MaintenanceWindow struct {
+	// Provider is the provider identifier, e.g. "claude" or "gemini-cli".
+	Provider string `yaml:"provider" json:"provider"`
+
+	// Start and End bound the window as RFC 3339 timestamps. Routing resumes automatically at End.
+	Start string `yaml:"start" json:"start"`
+	End   string `yaml:"end" json:"end"`
+
+	// Reason is reported to clients and in the management API.
+	Reason string `yaml:"reason,omitempty" json:"reason,omitempty"`
+}
+
+// ModelRename declares that a provider renamed or retired a model.
+type ModelRename struct {
+	// From is the deprecated model ID.
+	From string `yaml:"from" json:"from"`
+
+	// To is the model ID that replaces it. An empty To removes a built-in rename of From.
+	To string `yaml:"to" json:"to"`
+
+	// Reason is reported to clients in the deprecation warning.
+	Reason string `yaml:"reason,omitempty" json:"reason,omitempty"`
+}
+
+// FallbackChain is a model alias served by the first of its models that succeeds.
+type FallbackChain struct {
+	// Alias is the model name clients request.
+	Alias string `yaml:"alias" json:"alias"`
+
+	// Models are tried in order. Each is routed to its own providers, and the request is
+	// translated to the format of whichever provider serves it.
+	Models []string `yaml:"models" json:"models"`
+}
+
+// ModelPolicy limits the models available to a group of client API keys. A key covered by
+// several policies must satisfy all of them.
+type ModelPolicy struct {
+	// Name identifies the policy in logs and error messages.
+	Name string `yaml:"name" json:"name"`
+
+	// APIKeys lists the client API keys the policy applies to.
+	APIKeys []string `yaml:"api-keys" json:"api-keys"`
+
+	// Models holds the allow and deny patterns.
+	Models ModelAccessRules `yaml:"models" json:"models"`
+}
+
+// ModelAccessRules lists model name patterns; "*" matches any run of characters and matching
+// is case-insensitive. Deny takes precedence over Allow.
+type ModelAccessRules struct {
+	// Allow, when non-empty, permits only models matching one of the patterns.
+	Allow []string `yaml:"allow,omitempty" json:"allow,omitempty"`
+
+	// Deny rejects models matching any of the patterns.
+	Deny []string `yaml:"deny,omitempty" json:"deny,omitempty"`
+}
+
+// PriorityConfig controls how requests are admitted when upstream concurrency is saturated.
+// Keys default to the interactive class; keys listed in BatchAPIKeys are treated as batch.
+type PriorityConfig struct {
+	// MaxConcurrency caps in-flight upstream requests across all clients.
+	// <= 0 disables the gate and every request proceeds immediately. Default is 0.
+	MaxConcurrency int `yaml:"max-concurrency,omitempty" json:"max-concurrency,omitempty"`
+
+	// BatchMaxConcurrency caps how many slots batch requests may hold at once so some capacity
+	// stays free for interactive clients. <= 0 lets batch requests use every free slot.
+	BatchMaxConcurrency int `yaml:"batch-max-concurrency,omitempty" json:"batch-max-concurrency,omitempty"`
+
+	// BatchAPIKeys lists client API keys whose requests queue behind interactive traffic.
+	BatchAPIKeys []string `yaml:"batch-api-keys,omitempty" json:"batch-api-keys,omitempty"`
+
+	// BatchStreamThrottleMS delays each batch stream chunk by this many milliseconds while
+	// interactive requests are waiting for a slot. <= 0 disables throttling.
+	BatchStreamThrottleMS int `yaml:"batch-stream-throttle-ms,omitempty" json:"batch-stream-throttle-ms,omitempty"`
 }
 
 // StreamingConfig holds server streaming behavior configuration.
@@ -41,6 +381,20 @@ type StreamingConfig struct {
 	// to allow auth rotation / transient recovery.
 	// <= 0 disables bootstrap retries. Default is 0.
 	BootstrapRetries int `yaml:"bootstrap-retries,omitempty" json:"bootstrap-retries,omitempty"`
+
+	// PartialSalvage ends streams cleanly as truncated when the upstream aborts after substantial
+	// output, instead of sending an error event.
+	PartialSalvage PartialSalvageConfig `yaml:"partial-salvage,omitempty" json:"partial-salvage,omitempty"`
+}
+
+// PartialSalvageConfig controls which client keys get truncated streams finished cleanly.
+type PartialSalvageConfig struct {
+	// APIKeys opts client API keys into salvage. Streams of other keys end with an error event.
+	APIKeys []string `yaml:"api-keys,omitempty" json:"api-keys,omitempty"`
+
+	// MinBytes is how much output must have reached the client before an aborted stream is
+	// salvaged. <= 0 uses 2048.
+	MinBytes int `yaml:"min-bytes,omitempty" json:"min-bytes,omitempty"`
 }
 
 // AccessConfig groups request authentication providers.
@@ -25,6 +25,9 @@ const (
 	// Antigravity represents the Antigravity response format identifier.
 	Antigravity = "antigravity"
 
+	// GitHubCopilotResponses represents the GitHub Copilot Responses API format identifier.
+	GitHubCopilotResponses = "github-copilot-responses"
+
 	// Kiro represents the AWS CodeWhisperer (Kiro) provider identifier.
 	Kiro = "kiro"
 )
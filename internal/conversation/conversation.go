@@ -0,0 +1,255 @@
+// Package conversation tracks cumulative token spend per conversation so runaway agent loops can
+// be detected and capped before they drain an account.
+//
+// Conversations are keyed by client API key plus the session key derived from the request. Token
+// counts come from the usage records emitted by the runtime executors; the conversation a record
+// belongs to is carried on the execution context via WithKey.
+package conversation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+const (
+	// ActionReject fails requests of conversations over their cap.
+	ActionReject = "reject"
+	// ActionWarn lets requests through with an injected system note.
+	ActionWarn = "warn"
+
+	defaultIdleTTL = 2 * time.Hour
+)
+
+var defaultTracker = NewTracker()
+
+func init() {
+	coreusage.RegisterPlugin(defaultTracker)
+}
+
+// Default returns the process-wide tracker fed by usage records.
+func Default() *Tracker { return defaultTracker }
+
+type contextKey struct{}
+
+// WithKey attaches the tracker key of the request's conversation to ctx.
+func WithKey(ctx context.Context, key string) context.Context {
+	if ctx == nil || key == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKey{}, key)
+}
+
+// KeyFromContext returns the tracker key attached by WithKey.
+func KeyFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	key, _ := ctx.Value(contextKey{}).(string)
+	return key
+}
+
+// Key builds the tracker key for a conversation of apiKey. It returns "" without a session key.
+func Key(apiKey, sessionKey string) string {
+	sessionKey = strings.TrimSpace(sessionKey)
+	if sessionKey == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(strings.TrimSpace(apiKey) + "\x00" + sessionKey))
+	return hex.EncodeToString(sum[:12])
+}
+
+// Status is a point-in-time view of one conversation.
+type Status struct {
+	ID           string    `json:"id"`
+	InputTokens  int64     `json:"input_tokens"`
+	OutputTokens int64     `json:"output_tokens"`
+	TotalTokens  int64     `json:"total_tokens"`
+	Requests     int64     `json:"requests"`
+	FirstSeen    time.Time `json:"first_seen"`
+	LastSeen     time.Time `json:"last_seen"`
+	Exceeded     bool      `json:"exceeded"`
+}
+
+type conversation struct {
+	input     int64
+	output    int64
+	total     int64
+	requests  int64
+	firstSeen time.Time
+	lastSeen  time.Time
+}
+
+// Tracker accumulates token usage per conversation.
+type Tracker struct {
+	mu            sync.Mutex
+	now           func() time.Time
+	enabled       bool
+	maxTokens     int64
+	action        string
+	ttl           time.Duration
+	conversations map[string]*conversation
+}
+
+// NewTracker creates a disabled tracker; call Configure to enable it.
+func NewTracker() *Tracker {
+	return &Tracker{now: time.Now, action: ActionReject, ttl: defaultIdleTTL, conversations: map[string]*conversation{}}
+}
+
+// Configure applies cfg. Disabling the tracker forgets every conversation.
+func (t *Tracker) Configure(cfg config.ConversationCapConfig) {
+	if t == nil {
+		return
+	}
+	action := strings.ToLower(strings.TrimSpace(cfg.Action))
+	if action != ActionWarn {
+		action = ActionReject
+	}
+	ttl := time.Duration(cfg.IdleTTLMinutes) * time.Minute
+	if ttl <= 0 {
+		ttl = defaultIdleTTL
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.enabled = cfg.Enable
+	t.maxTokens = max(cfg.MaxTokens, 0)
+	t.action = action
+	t.ttl = ttl
+	if !t.enabled {
+		t.conversations = map[string]*conversation{}
+	}
+}
+
+// Enabled reports whether conversations are being tracked.
+func (t *Tracker) Enabled() bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.enabled
+}
+
+// Check reports the tokens used by the conversation, the configured cap and the action to take
+// when the cap has been reached. exceeded is false when no cap is configured.
+func (t *Tracker) Check(key string) (used, limit int64, action string, exceeded bool) {
+	if t == nil || key == "" {
+		return 0, 0, "", false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.enabled {
+		return 0, 0, "", false
+	}
+	if conv, ok := t.conversations[key]; ok && !t.expiredLocked(conv, t.now()) {
+		used = conv.total
+	}
+	return used, t.maxTokens, t.action, t.maxTokens > 0 && used >= t.maxTokens
+}
+
+// Record adds token usage to the conversation identified by key.
+func (t *Tracker) Record(key string, detail coreusage.Detail) {
+	if t == nil || key == "" {
+		return
+	}
+	total := detail.TotalTokens
+	if total == 0 {
+		total = detail.InputTokens + detail.OutputTokens + detail.ReasoningTokens
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.enabled {
+		return
+	}
+	now := t.now()
+	conv, ok := t.conversations[key]
+	if !ok || t.expiredLocked(conv, now) {
+		t.gcLocked(now)
+		conv = &conversation{firstSeen: now}
+		t.conversations[key] = conv
+	}
+	conv.input += detail.InputTokens
+	conv.output += detail.OutputTokens
+	conv.total += total
+	conv.requests++
+	conv.lastSeen = now
+}
+
+// HandleUsage implements coreusage.Plugin.
+func (t *Tracker) HandleUsage(ctx context.Context, record coreusage.Record) {
+	t.Record(KeyFromContext(ctx), record.Detail)
+}
+
+// Reset forgets the conversation with id, allowing it to continue past its cap.
+func (t *Tracker) Reset(id string) bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.conversations[id]; !ok {
+		return false
+	}
+	delete(t.conversations, id)
+	return true
+}
+
+// Snapshot returns active conversations, largest spend first.
+func (t *Tracker) Snapshot() []Status {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	t.gcLocked(now)
+	out := make([]Status, 0, len(t.conversations))
+	for id, conv := range t.conversations {
+		out = append(out, Status{
+			ID:           id,
+			InputTokens:  conv.input,
+			OutputTokens: conv.output,
+			TotalTokens:  conv.total,
+			Requests:     conv.requests,
+			FirstSeen:    conv.firstSeen,
+			LastSeen:     conv.lastSeen,
+			Exceeded:     t.maxTokens > 0 && conv.total >= t.maxTokens,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].TotalTokens != out[j].TotalTokens {
+			return out[i].TotalTokens > out[j].TotalTokens
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// Limits returns the configured cap and action.
+func (t *Tracker) Limits() (maxTokens int64, action string) {
+	if t == nil {
+		return 0, ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.maxTokens, t.action
+}
+
+func (t *Tracker) expiredLocked(conv *conversation, now time.Time) bool {
+	return now.Sub(conv.lastSeen) > t.ttl
+}
+
+func (t *Tracker) gcLocked(now time.Time) {
+	for id, conv := range t.conversations {
+		if t.expiredLocked(conv, now) {
+			delete(t.conversations, id)
+		}
+	}
+}
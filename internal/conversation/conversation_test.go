@@ -0,0 +1,45 @@
+package conversation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+func TestTrackerCapAndExpiry(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	tracker := NewTracker()
+	tracker.now = func() time.Time { return now }
+	tracker.Configure(config.ConversationCapConfig{Enable: true, MaxTokens: 100, Action: "warn", IdleTTLMinutes: 10})
+
+	key := Key("client", "claude:session")
+	if key == "" || Key("client", "") != "" || key == Key("other", "claude:session") {
+		t.Fatalf("unexpected key derivation")
+	}
+	ctx := WithKey(context.Background(), key)
+	tracker.HandleUsage(ctx, coreusage.Record{Detail: coreusage.Detail{InputTokens: 40, OutputTokens: 20}})
+	if used, _, _, exceeded := tracker.Check(key); used != 60 || exceeded {
+		t.Fatalf("Check() = %d, %v; want 60, false", used, exceeded)
+	}
+	tracker.HandleUsage(ctx, coreusage.Record{Detail: coreusage.Detail{TotalTokens: 50}})
+	used, limit, action, exceeded := tracker.Check(key)
+	if used != 110 || limit != 100 || action != ActionWarn || !exceeded {
+		t.Fatalf("Check() = %d, %d, %q, %v", used, limit, action, exceeded)
+	}
+	// Records without a conversation are ignored.
+	tracker.HandleUsage(context.Background(), coreusage.Record{Detail: coreusage.Detail{TotalTokens: 50}})
+	if snapshot := tracker.Snapshot(); len(snapshot) != 1 || snapshot[0].Requests != 2 || !snapshot[0].Exceeded {
+		t.Fatalf("unexpected snapshot: %+v", snapshot)
+	}
+
+	now = now.Add(11 * time.Minute)
+	if used, _, _, exceeded = tracker.Check(key); used != 0 || exceeded {
+		t.Fatalf("idle conversation should expire, got %d, %v", used, exceeded)
+	}
+	if len(tracker.Snapshot()) != 0 {
+		t.Fatal("expired conversation should be dropped")
+	}
+}
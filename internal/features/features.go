@@ -0,0 +1,179 @@
+// Package features is the registry of feature flags that switch optional proxy behavior.
+//
+// Every flag has a built-in default that configuration can replace. Operators can override a
+// flag at runtime through the management API; an override wins over configuration until it is
+// cleared and is not persisted.
+package features
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Flag names known to the registry.
+const (
+	// CrossProviderFailover lets a request that fails on one provider be retried on another
+	// provider serving the same model. When disabled, a request stays on the provider of the
+	// first credential picked for it.
+	CrossProviderFailover = "cross-provider-failover"
+	// ThinkingExtraction converts inline <thinking> tags in Kiro responses into thinking blocks.
+	// When disabled, the tags are passed through as text.
+	ThinkingExtraction = "thinking-extraction"
+	// SignatureCache caches thinking signatures from responses so later turns of a conversation
+	// can restore them.
+	SignatureCache = "signature-cache"
+)
+
+var definitions = map[string]definition{
+	CrossProviderFailover: {
+		description: "Retry failed requests on other providers serving the same model",
+		enabled:     true,
+	},
+	ThinkingExtraction: {
+		description: "Convert inline <thinking> tags in Kiro responses into thinking blocks",
+		enabled:     true,
+	},
+	SignatureCache: {
+		description: "Cache thinking signatures from responses for later conversation turns",
+		enabled:     true,
+	},
+}
+
+type definition struct {
+	description string
+	enabled     bool
+}
+
+var defaultRegistry = NewRegistry()
+
+// Default returns the process-wide flag registry.
+func Default() *Registry { return defaultRegistry }
+
+// Enabled reports whether name is enabled in the process-wide registry.
+func Enabled(name string) bool { return defaultRegistry.Enabled(name) }
+
+// Registry holds the configured values and runtime overrides of the known flags.
+type Registry struct {
+	mu         sync.RWMutex
+	configured map[string]bool
+	overrides  map[string]bool
+}
+
+// Status describes the state of one flag.
+type Status struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	Default     bool   `json:"default"`
+	// Source is where Enabled comes from: "default", "config" or "override".
+	Source string `json:"source"`
+}
+
+// NewRegistry creates a registry with every flag at its default.
+func NewRegistry() *Registry {
+	return &Registry{configured: map[string]bool{}, overrides: map[string]bool{}}
+}
+
+// Known reports whether name is a flag of the registry.
+func Known(name string) bool {
+	_, ok := definitions[normalizeName(name)]
+	return ok
+}
+
+// Configure replaces the configured flag values. Unknown names are logged and skipped; runtime
+// overrides are kept.
+func (r *Registry) Configure(values map[string]bool) {
+	if r == nil {
+		return
+	}
+	configured := make(map[string]bool, len(values))
+	for name, enabled := range values {
+		normalized := normalizeName(name)
+		if _, ok := definitions[normalized]; !ok {
+			log.Warnf("features: ignoring unknown feature flag %q", name)
+			continue
+		}
+		configured[normalized] = enabled
+	}
+	r.mu.Lock()
+	r.configured = configured
+	r.mu.Unlock()
+}
+
+// Enabled reports whether name is enabled. Unknown flags are disabled.
+func (r *Registry) Enabled(name string) bool {
+	name = normalizeName(name)
+	def, ok := definitions[name]
+	if !ok {
+		return false
+	}
+	if r == nil {
+		return def.enabled
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	enabled, _ := r.stateLocked(name, def)
+	return enabled
+}
+
+// SetOverride forces name to enabled until the override is cleared. It reports false for an
+// unknown flag.
+func (r *Registry) SetOverride(name string, enabled bool) bool {
+	name = normalizeName(name)
+	if r == nil || !Known(name) {
+		return false
+	}
+	r.mu.Lock()
+	r.overrides[name] = enabled
+	r.mu.Unlock()
+	log.Infof("features: %s overridden to %t", name, enabled)
+	return true
+}
+
+// ClearOverride removes the runtime override of name, returning it to its configured value.
+func (r *Registry) ClearOverride(name string) bool {
+	if r == nil {
+		return false
+	}
+	name = normalizeName(name)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.overrides[name]
+	delete(r.overrides, name)
+	return ok
+}
+
+// Snapshot reports every known flag, sorted by name.
+func (r *Registry) Snapshot() []Status {
+	out := make([]Status, 0, len(definitions))
+	if r != nil {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+	}
+	for name, def := range definitions {
+		status := Status{Name: name, Description: def.description, Default: def.enabled, Enabled: def.enabled, Source: "default"}
+		if r != nil {
+			status.Enabled, status.Source = r.stateLocked(name, def)
+		}
+		out = append(out, status)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func (r *Registry) stateLocked(name string, def definition) (bool, string) {
+	if enabled, ok := r.overrides[name]; ok {
+		return enabled, "override"
+	}
+	if enabled, ok := r.configured[name]; ok {
+		return enabled, "config"
+	}
+	return def.enabled, "default"
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,42 @@
+package features
+
+import "testing"
+
+func TestRegistryPrecedence(t *testing.T) {
+	r := NewRegistry()
+	if !r.Enabled(CrossProviderFailover) {
+		t.Fatal("cross-provider failover must default to enabled")
+	}
+	if r.Enabled("no-such-flag") {
+		t.Fatal("unknown flags must be disabled")
+	}
+
+	r.Configure(map[string]bool{"Cross-Provider-Failover": false, "no-such-flag": true})
+	if r.Enabled(CrossProviderFailover) {
+		t.Fatal("configured value must replace the default")
+	}
+
+	if !r.SetOverride(CrossProviderFailover, true) || !r.Enabled(CrossProviderFailover) {
+		t.Fatal("override must win over configuration")
+	}
+	r.Configure(map[string]bool{CrossProviderFailover: false})
+	if !r.Enabled(CrossProviderFailover) {
+		t.Fatal("reloading configuration must keep overrides")
+	}
+	if r.SetOverride("no-such-flag", true) {
+		t.Fatal("unknown flags cannot be overridden")
+	}
+
+	for _, status := range r.Snapshot() {
+		if status.Name == CrossProviderFailover && (status.Source != "override" || !status.Enabled || !status.Default) {
+			t.Fatalf("unexpected snapshot entry: %+v", status)
+		}
+	}
+
+	if !r.ClearOverride(CrossProviderFailover) || r.Enabled(CrossProviderFailover) {
+		t.Fatal("clearing the override must restore the configured value")
+	}
+	if r.ClearOverride(CrossProviderFailover) {
+		t.Fatal("clearing a missing override must report false")
+	}
+}
@@ -0,0 +1,177 @@
+// Package i18n translates the user-facing messages of the proxy: the errors the API returns for
+// requests it rejects itself, and the progress and troubleshooting output of the login
+// commands. English is the default; Chinese is selected by the language setting of the
+// configuration or, for API errors, by the Accept-Language header of the request.
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Lang is a supported message language.
+type Lang string
+
+const (
+	// English is the default language and the source of every message.
+	English Lang = "en"
+	// Chinese is Simplified Chinese.
+	Chinese Lang = "zh"
+)
+
+// Parse returns the supported language named by tag, such as "zh", "zh-CN", "zh_CN.UTF-8" or
+// "en-US". It reports false for empty and unsupported tags.
+func Parse(tag string) (Lang, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_.@"); i >= 0 {
+		tag = tag[:i]
+	}
+	switch Lang(tag) {
+	case English:
+		return English, true
+	case Chinese:
+		return Chinese, true
+	default:
+		return "", false
+	}
+}
+
+// ParseOr returns the supported language named by tag, or fallback.
+func ParseOr(tag string, fallback Lang) Lang {
+	if lang, ok := Parse(tag); ok {
+		return lang
+	}
+	return fallback
+}
+
+// FromAcceptLanguage returns the supported language an Accept-Language header prefers most, or
+// fallback when the header names none.
+func FromAcceptLanguage(header string, fallback Lang) Lang {
+	type candidate struct {
+		lang    Lang
+		quality float64
+		index   int
+	}
+	var candidates []candidate
+	for i, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		lang, ok := Parse(tag)
+		if !ok {
+			continue
+		}
+		quality := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if quality > 0 {
+			candidates = append(candidates, candidate{lang: lang, quality: quality, index: i})
+		}
+	}
+	if len(candidates) == 0 {
+		return fallback
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].quality > candidates[j].quality })
+	return candidates[0].lang
+}
+
+// FromEnvironment returns the language of the process locale from LC_ALL, LC_MESSAGES and LANG,
+// in that order, or fallback when the locale is unset or unsupported.
+func FromEnvironment(fallback Lang) Lang {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+			return ParseOr(value, fallback)
+		}
+	}
+	return fallback
+}
+
+var defaultLang atomic.Value
+
+// SetDefault sets the language used when a message is not rendered for a specific request.
+func SetDefault(lang Lang) {
+	if _, ok := catalog[MsgUnexpectedError][lang]; !ok {
+		lang = English
+	}
+	defaultLang.Store(lang)
+}
+
+// Default returns the language set by SetDefault, English until it is called.
+func Default() Lang {
+	if lang, ok := defaultLang.Load().(Lang); ok {
+		return lang
+	}
+	return English
+}
+
+// T returns the message key in lang, formatted with args. Messages without a translation fall
+// back to English; unknown keys are returned as they are.
+func T(lang Lang, key Key, args ...any) string {
+	translations, ok := catalog[key]
+	if !ok {
+		return string(key)
+	}
+	format, ok := translations[lang]
+	if !ok {
+		format = translations[English]
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Error is an error whose message can be rendered in every supported language. Error returns
+// the English message, so logs stay in English.
+type Error struct {
+	Key  Key
+	Args []any
+}
+
+// Errorf returns an Error for the message key formatted with args. An error argument is
+// unwrapped by errors.Is and errors.As.
+func Errorf(key Key, args ...any) *Error {
+	return &Error{Key: key, Args: args}
+}
+
+func (e *Error) Error() string {
+	return T(English, e.Key, e.Args...)
+}
+
+// Localize returns the message in lang.
+func (e *Error) Localize(lang Lang) string {
+	return T(lang, e.Key, e.Args...)
+}
+
+// Unwrap returns the first error argument.
+func (e *Error) Unwrap() error {
+	for _, arg := range e.Args {
+		if err, ok := arg.(error); ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// Localize returns the message of err in lang when err is an Error, and err.Error() otherwise.
+func Localize(err error, lang Lang) string {
+	if err == nil {
+		return ""
+	}
+	if localized, ok := err.(*Error); ok {
+		return localized.Localize(lang)
+	}
+	var localized *Error
+	if errors.As(err, &localized) && localized.Error() == err.Error() {
+		return localized.Localize(lang)
+	}
+	return err.Error()
+}
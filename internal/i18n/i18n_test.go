@@ -0,0 +1,117 @@
+package i18n
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := map[string]Lang{"en": English, "EN-us": English, "zh": Chinese, "zh-CN": Chinese, "zh_CN.UTF-8": Chinese, " zh-Hans ": Chinese}
+	for tag, want := range cases {
+		if got, ok := Parse(tag); !ok || got != want {
+			t.Fatalf("Parse(%q) = %q, %t; want %q", tag, got, ok, want)
+		}
+	}
+	for _, tag := range []string{"", "C", "fr-FR", "*"} {
+		if _, ok := Parse(tag); ok {
+			t.Fatalf("Parse(%q) must reject unsupported tags", tag)
+		}
+	}
+}
+
+func TestFromAcceptLanguage(t *testing.T) {
+	cases := []struct {
+		header string
+		want   Lang
+	}{
+		{"", English},
+		{"fr-FR, de;q=0.8", English},
+		{"zh-CN,zh;q=0.9,en;q=0.8", Chinese},
+		{"en-US;q=0.5, zh;q=0.7", Chinese},
+		{"fr, en-GB;q=0.9, zh;q=0.8", English},
+		{"zh;q=0, en;q=0.1", English},
+	}
+	for _, tc := range cases {
+		if got := FromAcceptLanguage(tc.header, English); got != tc.want {
+			t.Fatalf("FromAcceptLanguage(%q) = %q, want %q", tc.header, got, tc.want)
+		}
+	}
+	if got := FromAcceptLanguage("fr", Chinese); got != Chinese {
+		t.Fatalf("unsupported header must return the fallback, got %q", got)
+	}
+}
+
+func TestFromEnvironment(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "zh_CN.UTF-8")
+	if got := FromEnvironment(English); got != Chinese {
+		t.Fatalf("LANG=zh_CN.UTF-8 must select Chinese, got %q", got)
+	}
+	t.Setenv("LC_ALL", "C")
+	if got := FromEnvironment(English); got != English {
+		t.Fatalf("LC_ALL overrides LANG, got %q", got)
+	}
+}
+
+func TestCatalogIsComplete(t *testing.T) {
+	for key, translations := range catalog {
+		english, ok := translations[English]
+		if !ok {
+			t.Fatalf("message %s has no English entry", key)
+		}
+		chinese, ok := translations[Chinese]
+		if !ok {
+			t.Fatalf("message %s has no Chinese entry", key)
+		}
+		if countVerbs(english) != countVerbs(chinese) {
+			t.Fatalf("message %s has different arguments across languages", key)
+		}
+	}
+}
+
+func countVerbs(format string) int {
+	count := 0
+	for i := 0; i < len(format)-1; i++ {
+		if format[i] == '%' {
+			if format[i+1] != '%' {
+				count++
+			}
+			i++
+		}
+	}
+	return count
+}
+
+func TestErrorLocalizes(t *testing.T) {
+	err := Errorf(MsgCapacityWaitCanceled, context.Canceled)
+	if got := err.Error(); got != "request canceled while waiting for capacity: context canceled" {
+		t.Fatalf("Error() must be English, got %q", got)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Fatal("error arguments must be unwrapped")
+	}
+	if got := Localize(err, Chinese); got != "等待可用容量时请求已取消：context canceled" {
+		t.Fatalf("Localize(zh) = %q", got)
+	}
+	if got := Localize(fmt.Errorf("upstream: %w", err), Chinese); got != "upstream: request canceled while waiting for capacity: context canceled" {
+		t.Fatalf("wrapped errors with their own text must stay as they are, got %q", got)
+	}
+	if got := Localize(errors.New("plain"), Chinese); got != "plain" {
+		t.Fatalf("plain errors must stay as they are, got %q", got)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	t.Cleanup(func() { SetDefault(English) })
+	SetDefault(Chinese)
+	if got := T(Default(), MsgLoginTroubleshoot); got != "故障排查：" {
+		t.Fatalf("T(Default()) = %q", got)
+	}
+	SetDefault("fr")
+	if Default() != English {
+		t.Fatalf("unsupported default must fall back to English, got %q", Default())
+	}
+}
@@ -0,0 +1,288 @@
+package i18n
+
+// Key identifies a message of the catalog.
+type Key string
+
+// Errors the API returns for requests the proxy rejects itself.
+const (
+	MsgMissingAPIKey           Key = "api.missing_api_key"
+	MsgInvalidAPIKey           Key = "api.invalid_api_key"
+	MsgAuthServiceError        Key = "api.auth_service_error"
+	MsgUnknownProvider         Key = "api.unknown_provider"
+	MsgNoCapableProvider       Key = "api.no_capable_provider"
+	MsgConversationCapExceeded Key = "api.conversation_cap_exceeded"
+	MsgEmbeddingsUnsupported   Key = "api.embeddings_unsupported"
+	MsgDailyCapacityExhausted  Key = "api.daily_capacity_exhausted"
+	MsgProviderMaintenance     Key = "api.provider_maintenance"
+	MsgCapacityWaitCanceled    Key = "api.capacity_wait_canceled"
+	MsgModelPolicyDenied       Key = "api.model_policy_denied"
+	MsgModelPolicyNotAllowed   Key = "api.model_policy_not_allowed"
+	MsgIgnoredFields           Key = "api.ignored_fields"
+)
+
+// Progress output of the login commands.
+const (
+	MsgLoginDefaultTitle  Key = "login.default_title"
+	MsgLoginSucceeded     Key = "login.succeeded"
+	MsgLoginFailed        Key = "login.failed"
+	MsgLoginTroubleshoot  Key = "login.troubleshooting"
+	MsgLoginUnknownError  Key = "login.unknown_error"
+	MsgUnexpectedError    Key = "login.unexpected_error"
+	MsgAuthExpired        Key = "login.auth_expired"
+	MsgAuthInvalid        Key = "login.auth_invalid"
+	MsgAuthRequired       Key = "login.auth_required"
+	MsgAuthPortInUse      Key = "login.port_in_use"
+	MsgAuthTimedOut       Key = "login.timed_out"
+	MsgAuthBrowserFailed  Key = "login.browser_open_failed"
+	MsgAuthFailed         Key = "login.failed_generic"
+	MsgAuthFailedReason   Key = "login.failed_reason"
+	MsgAuthDenied         Key = "login.denied"
+	MsgAuthInvalidRequest Key = "login.invalid_request"
+	MsgAuthServerError    Key = "login.server_error"
+)
+
+// GitHub Copilot login failures.
+const (
+	MsgCopilotDeviceCodeFailed    Key = "copilot.device_code_failed"
+	MsgCopilotCodeExpired         Key = "copilot.code_expired"
+	MsgCopilotPending             Key = "copilot.authorization_pending"
+	MsgCopilotSlowDown            Key = "copilot.slow_down"
+	MsgCopilotTokenExchangeFailed Key = "copilot.token_exchange_failed"
+	MsgCopilotUserInfoFailed      Key = "copilot.user_info_failed"
+	MsgCopilotRevocationFailed    Key = "copilot.token_revocation_failed"
+	MsgCopilotServerError         Key = "copilot.server_error"
+)
+
+// Troubleshooting hints of the Kiro logins.
+const (
+	MsgKiroHintProtocolHandler Key = "kiro.hint_protocol_handler"
+	MsgKiroHintGoogleLogin     Key = "kiro.hint_google_login"
+	MsgKiroHintGitHubLogin     Key = "kiro.hint_github_login"
+	MsgKiroHintCognitoLogin    Key = "kiro.hint_cognito_login"
+	MsgKiroHintTryImport       Key = "kiro.hint_try_import"
+	MsgKiroHintBuilderID       Key = "kiro.hint_builder_id"
+	MsgKiroHintAuthorize       Key = "kiro.hint_authorize"
+	MsgKiroHintTryDeviceCode   Key = "kiro.hint_try_device_code"
+	MsgKiroHintIDCStartURL     Key = "kiro.hint_idc_start_url"
+	MsgKiroHintIDCEnabled      Key = "kiro.hint_idc_enabled"
+	MsgKiroHintOpenIDE         Key = "kiro.hint_open_ide"
+	MsgKiroHintIDESignIn       Key = "kiro.hint_ide_sign_in"
+	MsgKiroHintCompleteLogin   Key = "kiro.hint_complete_login"
+	MsgKiroHintRunAgain        Key = "kiro.hint_run_again"
+)
+
+// catalog holds the format strings of every message by language. Every message has an English
+// entry; arguments keep their order across languages.
+var catalog = map[Key]map[Lang]string{
+	MsgMissingAPIKey: {
+		English: "Missing API key",
+		Chinese: "缺少 API 密钥",
+	},
+	MsgInvalidAPIKey: {
+		English: "Invalid API key",
+		Chinese: "API 密钥无效",
+	},
+	MsgAuthServiceError: {
+		English: "Authentication service error",
+		Chinese: "认证服务出错",
+	},
+	MsgUnknownProvider: {
+		English: "unknown provider for model %s",
+		Chinese: "模型 %s 没有可用的提供商",
+	},
+	MsgNoCapableProvider: {
+		English: "no provider for model %s supports the requested features: %s",
+		Chinese: "模型 %s 的提供商均不支持所请求的功能：%s",
+	},
+	MsgConversationCapExceeded: {
+		English: "conversation token cap exceeded: %d of %d tokens used; start a new conversation to continue",
+		Chinese: "会话 token 上限已超出：已使用 %d / %d 个 token，请开启新会话后继续",
+	},
+	MsgEmbeddingsUnsupported: {
+		English: "model %s does not support embeddings",
+		Chinese: "模型 %s 不支持 embeddings",
+	},
+	MsgDailyCapacityExhausted: {
+		English: "daily capacity for %s is exhausted for this API key",
+		Chinese: "此 API 密钥在 %s 上的每日额度已用尽",
+	},
+	MsgProviderMaintenance: {
+		English: "provider under maintenance (%s)",
+		Chinese: "提供商正在维护（%s）",
+	},
+	MsgCapacityWaitCanceled: {
+		English: "request canceled while waiting for capacity: %v",
+		Chinese: "等待可用容量时请求已取消：%v",
+	},
+	MsgModelPolicyDenied: {
+		English: "model policy %q denies model %s for this API key",
+		Chinese: "模型策略 %q 禁止此 API 密钥使用模型 %s",
+	},
+	MsgModelPolicyNotAllowed: {
+		English: "model policy %q does not allow model %s for this API key",
+		Chinese: "模型策略 %q 不允许此 API 密钥使用模型 %s",
+	},
+	MsgIgnoredFields: {
+		English: "request contains fields that would be ignored by the selected providers: %s",
+		Chinese: "请求包含所选提供商会忽略的字段：%s",
+	},
+
+	MsgLoginDefaultTitle: {
+		English: "Login",
+		Chinese: "登录",
+	},
+	MsgLoginSucceeded: {
+		English: "%s succeeded",
+		Chinese: "%s 成功",
+	},
+	MsgLoginFailed: {
+		English: "%s failed: %s",
+		Chinese: "%s 失败：%s",
+	},
+	MsgLoginTroubleshoot: {
+		English: "Troubleshooting:",
+		Chinese: "故障排查：",
+	},
+	MsgLoginUnknownError: {
+		English: "unknown error",
+		Chinese: "未知错误",
+	},
+	MsgUnexpectedError: {
+		English: "An unexpected error occurred. Please try again.",
+		Chinese: "发生意外错误，请重试。",
+	},
+	MsgAuthExpired: {
+		English: "Your authentication has expired. Please log in again.",
+		Chinese: "登录已过期，请重新登录。",
+	},
+	MsgAuthInvalid: {
+		English: "Your authentication is invalid. Please log in again.",
+		Chinese: "登录凭据无效，请重新登录。",
+	},
+	MsgAuthRequired: {
+		English: "Please log in to continue.",
+		Chinese: "请先登录后继续。",
+	},
+	MsgAuthPortInUse: {
+		English: "The required port is already in use. Please close any applications using port 3000 and try again.",
+		Chinese: "所需端口已被占用，请关闭占用 3000 端口的程序后重试。",
+	},
+	MsgAuthTimedOut: {
+		English: "Authentication timed out. Please try again.",
+		Chinese: "认证超时，请重试。",
+	},
+	MsgAuthBrowserFailed: {
+		English: "Could not open your browser automatically. Please copy and paste the URL manually.",
+		Chinese: "无法自动打开浏览器，请手动复制并打开链接。",
+	},
+	MsgAuthFailed: {
+		English: "Authentication failed. Please try again.",
+		Chinese: "认证失败，请重试。",
+	},
+	MsgAuthFailedReason: {
+		English: "Authentication failed: %s",
+		Chinese: "认证失败：%s",
+	},
+	MsgAuthDenied: {
+		English: "Authentication was cancelled or denied.",
+		Chinese: "认证已取消或被拒绝。",
+	},
+	MsgAuthInvalidRequest: {
+		English: "Invalid authentication request. Please try again.",
+		Chinese: "认证请求无效，请重试。",
+	},
+	MsgAuthServerError: {
+		English: "Authentication server error. Please try again later.",
+		Chinese: "认证服务器出错，请稍后重试。",
+	},
+
+	MsgCopilotDeviceCodeFailed: {
+		English: "Failed to start GitHub authentication. Please check your network connection and try again.",
+		Chinese: "无法发起 GitHub 认证，请检查网络连接后重试。",
+	},
+	MsgCopilotCodeExpired: {
+		English: "The authentication code has expired. Please try again.",
+		Chinese: "认证码已过期，请重试。",
+	},
+	MsgCopilotPending: {
+		English: "Waiting for you to authorize the application on GitHub.",
+		Chinese: "正在等待你在 GitHub 上授权此应用。",
+	},
+	MsgCopilotSlowDown: {
+		English: "Please wait a moment before trying again.",
+		Chinese: "请稍等片刻再重试。",
+	},
+	MsgCopilotTokenExchangeFailed: {
+		English: "Failed to complete authentication. Please try again.",
+		Chinese: "无法完成认证，请重试。",
+	},
+	MsgCopilotUserInfoFailed: {
+		English: "Failed to get your GitHub account information. Please try again.",
+		Chinese: "无法获取你的 GitHub 账号信息，请重试。",
+	},
+	MsgCopilotRevocationFailed: {
+		English: "GitHub did not revoke the token. Revoke it under Settings > Applications on GitHub.",
+		Chinese: "GitHub 未能吊销该令牌，请在 GitHub 的 Settings > Applications 中手动吊销。",
+	},
+	MsgCopilotServerError: {
+		English: "GitHub server error. Please try again later.",
+		Chinese: "GitHub 服务器出错，请稍后重试。",
+	},
+
+	MsgKiroHintProtocolHandler: {
+		English: "Make sure the protocol handler is installed",
+		Chinese: "确认已安装 kiro:// 协议处理程序",
+	},
+	MsgKiroHintGoogleLogin: {
+		English: "Complete the Google login in the browser",
+		Chinese: "在浏览器中完成 Google 登录",
+	},
+	MsgKiroHintGitHubLogin: {
+		English: "Complete the GitHub login in the browser",
+		Chinese: "在浏览器中完成 GitHub 登录",
+	},
+	MsgKiroHintCognitoLogin: {
+		English: "Sign in with your Kiro email and password in the browser",
+		Chinese: "在浏览器中使用 Kiro 邮箱和密码登录",
+	},
+	MsgKiroHintTryImport: {
+		English: "If callback fails, try: --kiro-import (after logging in via Kiro IDE)",
+		Chinese: "如果回调失败，请先在 Kiro IDE 中登录，再尝试 --kiro-import",
+	},
+	MsgKiroHintBuilderID: {
+		English: "Make sure you have an AWS Builder ID",
+		Chinese: "确认你已拥有 AWS Builder ID",
+	},
+	MsgKiroHintAuthorize: {
+		English: "Complete the authorization in the browser",
+		Chinese: "在浏览器中完成授权",
+	},
+	MsgKiroHintTryDeviceCode: {
+		English: "If callback fails, try: --kiro-aws-login (device code flow)",
+		Chinese: "如果回调失败，请尝试 --kiro-aws-login（设备码流程）",
+	},
+	MsgKiroHintIDCStartURL: {
+		English: "Check the start URL and region with your AWS administrator",
+		Chinese: "向 AWS 管理员确认起始 URL 和区域",
+	},
+	MsgKiroHintIDCEnabled: {
+		English: "Make sure Kiro (Q Developer) is enabled for your Identity Center user",
+		Chinese: "确认你的 Identity Center 用户已启用 Kiro（Q Developer）",
+	},
+	MsgKiroHintOpenIDE: {
+		English: "Log in to Kiro IDE first: open Kiro IDE",
+		Chinese: "先登录 Kiro IDE：打开 Kiro IDE",
+	},
+	MsgKiroHintIDESignIn: {
+		English: "Click 'Sign in with Google' (or GitHub)",
+		Chinese: "点击 “Sign in with Google”（或 GitHub）",
+	},
+	MsgKiroHintCompleteLogin: {
+		English: "Complete the login process",
+		Chinese: "完成登录流程",
+	},
+	MsgKiroHintRunAgain: {
+		English: "Run this command again",
+		Chinese: "重新运行此命令",
+	},
+}
@@ -70,7 +70,7 @@ func SetupBaseLogger() {
 		log.SetOutput(os.Stdout)
 		log.SetLevel(log.InfoLevel)
 		log.SetReportCaller(true)
-		log.SetFormatter(&LogFormatter{})
+		log.SetFormatter(&RedactingFormatter{Formatter: &LogFormatter{}})
 
 		ginInfoWriter = log.StandardLogger().Writer()
 		gin.DefaultWriter = ginInfoWriter
@@ -0,0 +1,96 @@
+package logging
+
+import (
+	"regexp"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// secretPattern matches a credential inside free text. Group names the part to mask; group 0
+// masks the whole match.
+type secretPattern struct {
+	name  string
+	re    *regexp.Regexp
+	group int
+}
+
+// secretPatterns covers credentials that reach log lines through error messages and debug
+// dumps of upstream bodies: authorization headers, token fields in JSON, form and query
+// encodings, and the well known key formats of the supported providers.
+var secretPatterns = []secretPattern{
+	{name: "bearer", re: regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9._~+/=:-]{12,})`), group: 1},
+	{name: "json-field", re: regexp.MustCompile(`(?i)"(?:access_?token|refresh_?token|id_?token|api_?key|client_?secret|secret_?key|session_?token|x-api-key|x-goog-api-key|authorization)"\s*:\s*"([^"]{8,})"`), group: 1},
+	{name: "form-field", re: regexp.MustCompile(`(?i)\b(?:access_token|refresh_token|id_token|api_key|client_secret|key)=([^&\s"']{8,})`), group: 1},
+	{name: "jwt", re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)},
+	{name: "openai-key", re: regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`)},
+	{name: "google-api-key", re: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{30,}`)},
+	{name: "google-oauth", re: regexp.MustCompile(`\b(?:ya29\.[0-9A-Za-z_-]{20,}|1//[0-9A-Za-z_-]{20,})`)},
+	{name: "github-token", re: regexp.MustCompile(`\b(?:gh[opsur]_[A-Za-z0-9]{30,}|github_pat_[A-Za-z0-9_]{30,})`)},
+	{name: "kiro-token", re: regexp.MustCompile(`\bao[ar]A[A-Za-z0-9_+/=:-]{20,}`)},
+}
+
+// RedactSecrets masks credentials found in s, keeping only the first and last characters of
+// each the same way API keys are shown elsewhere in the logs.
+func RedactSecrets(s string) string {
+	for _, pattern := range secretPatterns {
+		if !pattern.re.MatchString(s) {
+			continue
+		}
+		s = pattern.re.ReplaceAllStringFunc(s, func(match string) string {
+			if pattern.group == 0 {
+				return util.HideAPIKey(match)
+			}
+			sub := pattern.re.FindStringSubmatchIndex(match)
+			start, end := sub[2*pattern.group], sub[2*pattern.group+1]
+			return match[:start] + util.HideAPIKey(match[start:end]) + match[end:]
+		})
+	}
+	return s
+}
+
+// redactSecretBytes is RedactSecrets for the payloads written to request logs.
+func redactSecretBytes(b []byte) []byte {
+	if len(b) == 0 {
+		return b
+	}
+	return []byte(RedactSecrets(string(b)))
+}
+
+// FindSecrets reports the kinds of credentials present in s. It backs the log scanner test
+// and returns nil for scrubbed output.
+func FindSecrets(s string) []string {
+	var found []string
+	for _, pattern := range secretPatterns {
+		for _, sub := range pattern.re.FindAllStringSubmatch(s, -1) {
+			if isMasked(sub[pattern.group]) {
+				continue
+			}
+			found = append(found, pattern.name+": "+sub[0])
+		}
+	}
+	return found
+}
+
+// maskedValue matches the output of util.HideAPIKey.
+var maskedValue = regexp.MustCompile(`^.{1,4}\.\.\..{1,4}$`)
+
+// isMasked reports whether value already went through util.HideAPIKey.
+func isMasked(value string) bool {
+	return maskedValue.MatchString(value)
+}
+
+// RedactingFormatter wraps a formatter and scrubs credentials from every rendered line, so
+// tokens in messages, fields and dumped bodies never reach log outputs.
+type RedactingFormatter struct {
+	Formatter log.Formatter
+}
+
+// Format renders entry with the wrapped formatter and redacts the result.
+func (f *RedactingFormatter) Format(entry *log.Entry) ([]byte, error) {
+	out, err := f.Formatter.Format(entry)
+	if err != nil {
+		return out, err
+	}
+	return []byte(RedactSecrets(string(out))), nil
+}
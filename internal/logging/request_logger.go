@@ -474,7 +474,7 @@ func (l *FileRequestLogger) writeRequestBodyTempFile(body []byte) (string, error
 	}
 	tmpPath := tmpFile.Name()
 
-	if _, errCopy := io.Copy(tmpFile, bytes.NewReader(body)); errCopy != nil {
+	if _, errCopy := io.Copy(tmpFile, bytes.NewReader(redactSecretBytes(body))); errCopy != nil {
 		_ = tmpFile.Close()
 		_ = os.Remove(tmpPath)
 		return "", errCopy
@@ -512,7 +512,7 @@ func (l *FileRequestLogger) writeNonStreamingLog(
 	if errWrite := writeAPISection(w, "=== API RESPONSE ===\n", "=== API RESPONSE", apiResponse); errWrite != nil {
 		return errWrite
 	}
-	return writeResponseSection(w, statusCode, true, responseHeaders, bytes.NewReader(response), decompressErr, true)
+	return writeResponseSection(w, statusCode, true, responseHeaders, bytes.NewReader(redactSecretBytes(response)), decompressErr, true)
 }
 
 func writeRequestInfoWithBody(
@@ -547,7 +547,7 @@ func writeRequestInfoWithBody(
 	}
 	for key, values := range headers {
 		for _, value := range values {
-			masked := util.MaskSensitiveHeaderValue(key, value)
+			masked := RedactSecrets(util.MaskSensitiveHeaderValue(key, value))
 			if _, errWrite := io.WriteString(w, fmt.Sprintf("%s: %s\n", key, masked)); errWrite != nil {
 				return errWrite
 			}
@@ -573,7 +573,7 @@ func writeRequestInfoWithBody(
 		if errClose := bodyFile.Close(); errClose != nil {
 			log.WithError(errClose).Warn("failed to close request body temp file")
 		}
-	} else if _, errWrite := w.Write(body); errWrite != nil {
+	} else if _, errWrite := w.Write(redactSecretBytes(body)); errWrite != nil {
 		return errWrite
 	}
 
@@ -587,6 +587,7 @@ func writeAPISection(w io.Writer, sectionHeader string, sectionPrefix string, pa
 	if len(payload) == 0 {
 		return nil
 	}
+	payload = redactSecretBytes(payload)
 
 	if bytes.HasPrefix(payload, []byte(sectionPrefix)) {
 		if _, errWrite := w.Write(payload); errWrite != nil {
@@ -627,7 +628,7 @@ func writeAPIErrorResponses(w io.Writer, apiResponseErrors []*interfaces.ErrorMe
 			return errWrite
 		}
 		if apiResponseErrors[i].Error != nil {
-			if _, errWrite := io.WriteString(w, apiResponseErrors[i].Error.Error()); errWrite != nil {
+			if _, errWrite := io.WriteString(w, RedactSecrets(apiResponseErrors[i].Error.Error())); errWrite != nil {
 				return errWrite
 			}
 		}
@@ -651,7 +652,7 @@ func writeResponseSection(w io.Writer, statusCode int, statusWritten bool, respo
 	if responseHeaders != nil {
 		for key, values := range responseHeaders {
 			for _, value := range values {
-				if _, errWrite := io.WriteString(w, fmt.Sprintf("%s: %s\n", key, value)); errWrite != nil {
+				if _, errWrite := io.WriteString(w, fmt.Sprintf("%s: %s\n", key, RedactSecrets(value))); errWrite != nil {
 					return errWrite
 				}
 			}
@@ -753,7 +754,7 @@ func (l *FileRequestLogger) formatLogContent(url, method string, headers map[str
 	content.Write(response)
 	content.WriteString("\n")
 
-	return content.String()
+	return RedactSecrets(content.String())
 }
 
 // decompressResponse decompresses response data based on Content-Encoding header.
@@ -920,7 +921,7 @@ func (l *FileRequestLogger) formatRequestInfo(url, method string, headers map[st
 	content.Write(body)
 	content.WriteString("\n\n")
 
-	return content.String()
+	return RedactSecrets(content.String())
 }
 
 // FileStreamingLogWriter implements StreamingLogWriter for file-based streaming logs.
@@ -1109,7 +1110,7 @@ func (w *FileStreamingLogWriter) asyncWriter() {
 		if w.responseBodyFile == nil {
 			continue
 		}
-		if _, errWrite := w.responseBodyFile.Write(chunk); errWrite != nil {
+		if _, errWrite := w.responseBodyFile.Write(redactSecretBytes(chunk)); errWrite != nil {
 			select {
 			case w.errorChan <- errWrite:
 			default:
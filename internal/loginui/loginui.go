@@ -0,0 +1,352 @@
+// Package loginui renders the progress of interactive login flows: the steps taken, a spinner
+// while waiting on the user, the URLs and codes the user has to act on, and a final summary.
+// Output follows the process-wide mode: human-readable text, quiet, or one JSON event per line
+// for scripts.
+package loginui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/i18n"
+	"golang.org/x/term"
+)
+
+// Mode selects how login progress is written.
+type Mode string
+
+const (
+	// ModeText writes human-readable progress, with a spinner while waiting on a terminal.
+	ModeText Mode = "text"
+	// ModeQuiet writes only what the user must act on, warnings and failures.
+	ModeQuiet Mode = "quiet"
+	// ModeJSON writes one JSON event per line.
+	ModeJSON Mode = "json"
+)
+
+// ParseMode returns the mode named by value; empty selects ModeText.
+func ParseMode(value string) (Mode, error) {
+	switch mode := Mode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "":
+		return ModeText, nil
+	case ModeText, ModeQuiet, ModeJSON:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown login output %q (expected text, quiet or json)", value)
+	}
+}
+
+var (
+	settingsMu sync.RWMutex
+	mode                 = ModeText
+	output     io.Writer = os.Stdout
+)
+
+// SetMode sets the output mode of progress created afterwards.
+func SetMode(m Mode) {
+	settingsMu.Lock()
+	mode = m
+	settingsMu.Unlock()
+}
+
+// SetOutput sets the writer progress created afterwards writes to; nil restores os.Stdout.
+func SetOutput(w io.Writer) {
+	if w == nil {
+		w = os.Stdout
+	}
+	settingsMu.Lock()
+	output = w
+	settingsMu.Unlock()
+}
+
+func settings() (Mode, io.Writer) {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
+	return mode, output
+}
+
+// Field is one line of the final summary.
+type Field struct {
+	Key   string
+	Value string
+}
+
+// F returns a summary field.
+func F(key, value string) Field {
+	return Field{Key: key, Value: value}
+}
+
+// event is the JSON form of a progress call.
+type event struct {
+	Event   string            `json:"event"`
+	Title   string            `json:"title,omitempty"`
+	Message string            `json:"message,omitempty"`
+	Label   string            `json:"label,omitempty"`
+	Value   string            `json:"value,omitempty"`
+	Options []string          `json:"options,omitempty"`
+	Fields  map[string]string `json:"fields,omitempty"`
+	Error   string            `json:"error,omitempty"`
+	Hints   []string          `json:"hints,omitempty"`
+}
+
+var spinnerFrames = []rune("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏")
+
+// Progress reports the progress of one login in the default language of the i18n package. Its
+// methods are safe for concurrent use.
+type Progress struct {
+	title string
+	mode  Mode
+	out   io.Writer
+	lang  i18n.Lang
+	// animate enables the spinner; only progress created by New on a terminal animates.
+	animate bool
+
+	mu   sync.Mutex
+	spin *spinner
+}
+
+type spinner struct {
+	message string
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// New starts reporting a login titled title.
+func New(title string) *Progress {
+	m, out := settings()
+	p := &Progress{title: title, mode: m, out: out, lang: i18n.Default()}
+	if file, ok := out.(*os.File); ok && m == ModeText {
+		p.animate = term.IsTerminal(int(file.Fd()))
+	}
+	switch p.mode {
+	case ModeJSON:
+		p.emit(event{Event: "start", Title: title})
+	case ModeText:
+		p.printf("%s\n", title)
+	}
+	return p
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying p.
+func NewContext(ctx context.Context, p *Progress) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the progress carried by ctx. Without one it returns an untitled progress
+// in the current mode that never animates, so flows started outside the CLI stay plain.
+func FromContext(ctx context.Context) *Progress {
+	if ctx != nil {
+		if p, ok := ctx.Value(contextKey{}).(*Progress); ok && p != nil {
+			return p
+		}
+	}
+	m, out := settings()
+	return &Progress{mode: m, out: out, lang: i18n.Default()}
+}
+
+// Step reports a step of the login.
+func (p *Progress) Step(format string, args ...any) {
+	p.pause()
+	message := fmt.Sprintf(format, args...)
+	switch p.mode {
+	case ModeJSON:
+		p.emit(event{Event: "step", Message: message})
+	case ModeText:
+		p.printf("  • %s\n", message)
+	}
+}
+
+// Wait reports that the login waits on something outside the process, such as the user
+// finishing the browser sign-in. On a terminal a spinner runs until the next call.
+func (p *Progress) Wait(format string, args ...any) {
+	p.pause()
+	message := fmt.Sprintf(format, args...)
+	switch p.mode {
+	case ModeJSON:
+		p.emit(event{Event: "wait", Message: message})
+	case ModeText:
+		if !p.animate {
+			p.printf("  … %s\n", message)
+			return
+		}
+		p.startSpinner(message)
+	}
+}
+
+// Info writes explanatory lines, such as port forwarding instructions, in text mode only.
+func (p *Progress) Info(lines ...string) {
+	p.pause()
+	switch p.mode {
+	case ModeJSON:
+		p.emit(event{Event: "info", Message: strings.Join(lines, "\n")})
+	case ModeText:
+		for _, line := range lines {
+			p.printf("    %s\n", line)
+		}
+	}
+}
+
+// Action reports something the user has to act on, such as a URL to open or a code to enter.
+// Actions are written in every mode.
+func (p *Progress) Action(label, value string) {
+	p.pause()
+	if p.mode == ModeJSON {
+		p.emit(event{Event: "action", Label: label, Value: value})
+		return
+	}
+	p.printf("  → %s: %s\n", label, value)
+}
+
+// Menu lists the options, numbered from 1, of the selection the user is prompted for next.
+// Menus are written in every mode.
+func (p *Progress) Menu(title string, options []string) {
+	p.pause()
+	if p.mode == ModeJSON {
+		p.emit(event{Event: "menu", Message: title, Options: options})
+		return
+	}
+	p.printf("  %s\n", title)
+	for i, option := range options {
+		p.printf("    %d) %s\n", i+1, option)
+	}
+}
+
+// Warn reports a problem the login recovers from. Warnings are written in every mode.
+func (p *Progress) Warn(format string, args ...any) {
+	p.pause()
+	message := fmt.Sprintf(format, args...)
+	if p.mode == ModeJSON {
+		p.emit(event{Event: "warning", Message: message})
+		return
+	}
+	p.printf("  ! %s\n", message)
+}
+
+// Success ends the login with a summary of fields; fields with an empty value are left out.
+func (p *Progress) Success(fields ...Field) {
+	p.pause()
+	fields = nonEmpty(fields)
+	switch p.mode {
+	case ModeJSON:
+		values := make(map[string]string, len(fields))
+		for _, field := range fields {
+			values[field.Key] = field.Value
+		}
+		p.emit(event{Event: "success", Title: p.title, Fields: values})
+	case ModeText:
+		p.printf("✓ %s\n", i18n.T(p.lang, i18n.MsgLoginSucceeded, p.displayTitle()))
+		width := 0
+		for _, field := range fields {
+			width = max(width, len(field.Key))
+		}
+		for _, field := range fields {
+			p.printf("    %-*s  %s\n", width+1, field.Key+":", field.Value)
+		}
+	}
+}
+
+// Fail ends the login with err and optional troubleshooting hints. Failures are written in
+// every mode.
+func (p *Progress) Fail(err error, hints ...string) {
+	p.pause()
+	message := i18n.T(p.lang, i18n.MsgLoginUnknownError)
+	if err != nil {
+		message = i18n.Localize(err, p.lang)
+	}
+	if p.mode == ModeJSON {
+		p.emit(event{Event: "failure", Title: p.title, Error: message, Hints: hints})
+		return
+	}
+	p.printf("✗ %s\n", i18n.T(p.lang, i18n.MsgLoginFailed, p.displayTitle(), message))
+	if len(hints) > 0 && p.mode == ModeText {
+		p.printf("  %s\n", i18n.T(p.lang, i18n.MsgLoginTroubleshoot))
+		for i, hint := range hints {
+			p.printf("    %d. %s\n", i+1, hint)
+		}
+	}
+}
+
+// WrapPrompt returns prompt wrapped to stop the spinner first, so the question is not
+// overwritten while the user types. It returns nil for a nil prompt.
+func (p *Progress) WrapPrompt(prompt func(string) (string, error)) func(string) (string, error) {
+	if prompt == nil {
+		return nil
+	}
+	return func(message string) (string, error) {
+		p.pause()
+		return prompt(message)
+	}
+}
+
+func (p *Progress) displayTitle() string {
+	if p.title == "" {
+		return i18n.T(p.lang, i18n.MsgLoginDefaultTitle)
+	}
+	return p.title
+}
+
+func (p *Progress) startSpinner(message string) {
+	s := &spinner{message: message, stop: make(chan struct{}), done: make(chan struct{})}
+	p.mu.Lock()
+	p.spin = s
+	p.mu.Unlock()
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for frame := 0; ; frame++ {
+			p.mu.Lock()
+			_, _ = fmt.Fprintf(p.out, "\r  %c %s", spinnerFrames[frame%len(spinnerFrames)], s.message)
+			p.mu.Unlock()
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// pause stops a running spinner and leaves its message as a finished line.
+func (p *Progress) pause() {
+	p.mu.Lock()
+	s := p.spin
+	p.spin = nil
+	p.mu.Unlock()
+	if s == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	p.printf("\r\033[K  … %s\n", s.message)
+}
+
+func (p *Progress) printf(format string, args ...any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, _ = fmt.Fprintf(p.out, format, args...)
+}
+
+func (p *Progress) emit(e event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_ = json.NewEncoder(p.out).Encode(e)
+}
+
+func nonEmpty(fields []Field) []Field {
+	kept := fields[:0:0]
+	for _, field := range fields {
+		if strings.TrimSpace(field.Value) != "" {
+			kept = append(kept, field)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,139 @@
+package loginui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/i18n"
+)
+
+func newTestProgress(t *testing.T, m Mode) (*Progress, *bytes.Buffer) {
+	t.Helper()
+	var out bytes.Buffer
+	SetMode(m)
+	SetOutput(&out)
+	t.Cleanup(func() {
+		SetMode(ModeText)
+		SetOutput(nil)
+	})
+	return New("Codex login"), &out
+}
+
+func TestParseMode(t *testing.T) {
+	cases := map[string]Mode{"": ModeText, "text": ModeText, " JSON ": ModeJSON, "quiet": ModeQuiet}
+	for value, want := range cases {
+		if got, err := ParseMode(value); err != nil || got != want {
+			t.Fatalf("ParseMode(%q) = %q, %v; want %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseMode("verbose"); err == nil {
+		t.Fatal("ParseMode must reject unknown modes")
+	}
+}
+
+func TestProgressText(t *testing.T) {
+	progress, out := newTestProgress(t, ModeText)
+	progress.Step("Opening browser")
+	progress.Wait("Waiting for callback...")
+	progress.Action("Open this URL", "https://example.com/auth")
+	progress.Success(F("Account", "user@example.com"), F("Saved to", "/auths/codex.json"), F("Empty", ""))
+
+	want := "Codex login\n" +
+		"  • Opening browser\n" +
+		"  … Waiting for callback...\n" +
+		"  → Open this URL: https://example.com/auth\n" +
+		"✓ Codex login succeeded\n" +
+		"    Account:   user@example.com\n" +
+		"    Saved to:  /auths/codex.json\n"
+	if got := out.String(); got != want {
+		t.Fatalf("text output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestProgressQuiet(t *testing.T) {
+	progress, out := newTestProgress(t, ModeQuiet)
+	progress.Step("Opening browser")
+	progress.Info("ssh -L 1455:127.0.0.1:1455 host")
+	progress.Action("Enter the code", "ABCD-1234")
+	progress.Success(F("Account", "user"))
+	progress.Fail(errors.New("denied"), "try again")
+
+	want := "  → Enter the code: ABCD-1234\n✗ Codex login failed: denied\n"
+	if got := out.String(); got != want {
+		t.Fatalf("quiet output = %q, want %q", got, want)
+	}
+}
+
+func TestProgressLocalized(t *testing.T) {
+	i18n.SetDefault(i18n.Chinese)
+	t.Cleanup(func() { i18n.SetDefault(i18n.English) })
+	progress, out := newTestProgress(t, ModeText)
+	progress.Fail(errors.New("denied"), i18n.T(i18n.Chinese, i18n.MsgKiroHintRunAgain))
+
+	want := "Codex login\n✗ Codex login 失败：denied\n  故障排查：\n    1. 重新运行此命令\n"
+	if got := out.String(); got != want {
+		t.Fatalf("localized output = %q, want %q", got, want)
+	}
+}
+
+func TestProgressJSON(t *testing.T) {
+	progress, out := newTestProgress(t, ModeJSON)
+	progress.Action("Enter the code", "ABCD-1234")
+	progress.Fail(errors.New("denied"), "try again")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 events, got %d: %q", len(lines), out.String())
+	}
+	var events []event
+	for _, line := range lines {
+		var e event
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("invalid JSON event %q: %v", line, err)
+		}
+		events = append(events, e)
+	}
+	if events[0].Event != "start" || events[0].Title != "Codex login" {
+		t.Fatalf("unexpected start event: %+v", events[0])
+	}
+	if events[1].Event != "action" || events[1].Value != "ABCD-1234" {
+		t.Fatalf("unexpected action event: %+v", events[1])
+	}
+	if events[2].Event != "failure" || events[2].Error != "denied" || len(events[2].Hints) != 1 {
+		t.Fatalf("unexpected failure event: %+v", events[2])
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	progress, _ := newTestProgress(t, ModeText)
+	if got := FromContext(NewContext(context.Background(), progress)); got != progress {
+		t.Fatal("FromContext must return the progress carried by the context")
+	}
+	fallback := FromContext(context.Background())
+	if fallback == nil || fallback.animate || fallback.title != "" {
+		t.Fatalf("unexpected fallback progress: %+v", fallback)
+	}
+}
+
+func TestProgressSpinnerStopsBeforeOutput(t *testing.T) {
+	progress, out := newTestProgress(t, ModeText)
+	progress.animate = true
+	progress.Wait("Waiting for callback...")
+	answer, err := progress.WrapPrompt(func(string) (string, error) { return "ok", nil })("Paste: ")
+	if err != nil || answer != "ok" {
+		t.Fatalf("wrapped prompt = %q, %v", answer, err)
+	}
+	progress.Step("Exchanging code")
+
+	got := out.String()
+	if !strings.Contains(got, "\r  ⠋ Waiting for callback...") {
+		t.Fatalf("spinner frame missing from %q", got)
+	}
+	if !strings.HasSuffix(got, "\r\033[K  … Waiting for callback...\n  • Exchanging code\n") {
+		t.Fatalf("spinner was not finished before the next step: %q", got)
+	}
+}
@@ -0,0 +1,204 @@
+// Package maintenance tracks planned provider maintenance windows.
+//
+// While a provider is in maintenance it is excluded from routing and its credentials are not
+// refreshed in the background. Windows come from configuration and end on their own; operators
+// can override a provider's state at runtime through the management API.
+package maintenance
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// Override states accepted by SetOverride.
+const (
+	// StateMaintenance forces a provider into maintenance.
+	StateMaintenance = "maintenance"
+	// StateActive keeps a provider routable even inside a configured window.
+	StateActive = "active"
+)
+
+var defaultSchedule = NewSchedule()
+
+// Default returns the process-wide maintenance schedule.
+func Default() *Schedule { return defaultSchedule }
+
+// Schedule holds the configured windows and runtime overrides.
+type Schedule struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	windows   map[string][]window
+	overrides map[string]override
+}
+
+type window struct {
+	start  time.Time
+	end    time.Time
+	reason string
+}
+
+type override struct {
+	state  string
+	until  time.Time
+	reason string
+}
+
+// Status describes the maintenance state of one provider.
+type Status struct {
+	Provider      string    `json:"provider"`
+	InMaintenance bool      `json:"in_maintenance"`
+	Reason        string    `json:"reason,omitempty"`
+	Until         time.Time `json:"until,omitempty"`
+	Override      string    `json:"override,omitempty"`
+	Windows       []Window  `json:"windows,omitempty"`
+}
+
+// Window is a configured maintenance window that has not ended yet.
+type Window struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// NewSchedule creates an empty schedule; call Configure to declare windows.
+func NewSchedule() *Schedule {
+	return &Schedule{now: time.Now, windows: map[string][]window{}, overrides: map[string]override{}}
+}
+
+// Configure replaces the configured windows. Invalid entries are logged and skipped; runtime
+// overrides are kept.
+func (s *Schedule) Configure(entries []config.MaintenanceWindow) {
+	if s == nil {
+		return
+	}
+	windows := make(map[string][]window, len(entries))
+	for _, entry := range entries {
+		provider := normalizeProvider(entry.Provider)
+		start, errStart := time.Parse(time.RFC3339, strings.TrimSpace(entry.Start))
+		end, errEnd := time.Parse(time.RFC3339, strings.TrimSpace(entry.End))
+		if provider == "" || errStart != nil || errEnd != nil || !end.After(start) {
+			log.Warnf("maintenance: ignoring invalid window for provider %q (%s - %s)", entry.Provider, entry.Start, entry.End)
+			continue
+		}
+		windows[provider] = append(windows[provider], window{start: start, end: end, reason: strings.TrimSpace(entry.Reason)})
+	}
+	for provider := range windows {
+		list := windows[provider]
+		sort.Slice(list, func(i, j int) bool { return list[i].start.Before(list[j].start) })
+	}
+	s.mu.Lock()
+	s.windows = windows
+	s.mu.Unlock()
+}
+
+// InMaintenance reports whether provider is currently in maintenance, with the reason and the
+// time routing is expected to resume. until is zero when the end is unknown.
+func (s *Schedule) InMaintenance(provider string) (reason string, until time.Time, ok bool) {
+	if s == nil {
+		return "", time.Time{}, false
+	}
+	provider = normalizeProvider(provider)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stateLocked(provider, s.now())
+}
+
+// SetOverride forces provider into state until the given time; a zero until keeps the override
+// until it is cleared.
+func (s *Schedule) SetOverride(provider, state string, until time.Time, reason string) {
+	if s == nil {
+		return
+	}
+	provider = normalizeProvider(provider)
+	if provider == "" {
+		return
+	}
+	s.mu.Lock()
+	s.overrides[provider] = override{state: state, until: until, reason: strings.TrimSpace(reason)}
+	s.mu.Unlock()
+}
+
+// ClearOverride removes the runtime override of provider, returning it to its configured windows.
+func (s *Schedule) ClearOverride(provider string) bool {
+	if s == nil {
+		return false
+	}
+	provider = normalizeProvider(provider)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.overrides[provider]
+	delete(s.overrides, provider)
+	return ok
+}
+
+// Snapshot reports every provider with a pending window or an override, sorted by provider.
+func (s *Schedule) Snapshot() []Status {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	providers := make(map[string]struct{}, len(s.windows)+len(s.overrides))
+	for provider := range s.windows {
+		providers[provider] = struct{}{}
+	}
+	for provider := range s.overrides {
+		providers[provider] = struct{}{}
+	}
+	out := make([]Status, 0, len(providers))
+	for provider := range providers {
+		status := Status{Provider: provider}
+		status.Reason, status.Until, status.InMaintenance = s.stateLocked(provider, now)
+		if o, ok := s.overrides[provider]; ok {
+			status.Override = o.state
+		}
+		for _, w := range s.windows[provider] {
+			if now.Before(w.end) {
+				status.Windows = append(status.Windows, Window{Start: w.start, End: w.end, Reason: w.reason})
+			}
+		}
+		if status.Override == "" && len(status.Windows) == 0 {
+			continue
+		}
+		out = append(out, status)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Provider < out[j].Provider })
+	return out
+}
+
+// stateLocked resolves the state of provider at now, dropping an expired override.
+func (s *Schedule) stateLocked(provider string, now time.Time) (string, time.Time, bool) {
+	if o, ok := s.overrides[provider]; ok {
+		if !o.until.IsZero() && !now.Before(o.until) {
+			delete(s.overrides, provider)
+		} else if o.state == StateActive {
+			return "", time.Time{}, false
+		} else {
+			reason := o.reason
+			if reason == "" {
+				reason = "maintenance declared by operator"
+			}
+			return reason, o.until, true
+		}
+	}
+	for _, w := range s.windows[provider] {
+		if !now.Before(w.start) && now.Before(w.end) {
+			reason := w.reason
+			if reason == "" {
+				reason = "scheduled maintenance"
+			}
+			return reason, w.end, true
+		}
+	}
+	return "", time.Time{}, false
+}
+
+func normalizeProvider(provider string) string {
+	return strings.ToLower(strings.TrimSpace(provider))
+}
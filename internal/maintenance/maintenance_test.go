@@ -0,0 +1,70 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestScheduleWindowsResumeAutomatically(t *testing.T) {
+	schedule := NewSchedule()
+	now := time.Date(2025, 6, 1, 1, 0, 0, 0, time.UTC)
+	schedule.now = func() time.Time { return now }
+	schedule.Configure([]config.MaintenanceWindow{
+		{Provider: "Claude", Start: "2025-06-01T02:00:00Z", End: "2025-06-01T04:00:00Z", Reason: "upstream upgrade"},
+		{Provider: "gemini", Start: "not a time", End: "2025-06-01T04:00:00Z"},
+	})
+
+	if _, _, ok := schedule.InMaintenance("claude"); ok {
+		t.Fatal("provider must be routable before the window starts")
+	}
+	now = now.Add(90 * time.Minute)
+	reason, until, ok := schedule.InMaintenance("claude")
+	if !ok || reason != "upstream upgrade" || !until.Equal(time.Date(2025, 6, 1, 4, 0, 0, 0, time.UTC)) {
+		t.Fatalf("InMaintenance = %q, %v, %v; want the configured window", reason, until, ok)
+	}
+	if _, _, ok = schedule.InMaintenance("gemini"); ok {
+		t.Fatal("invalid windows must be ignored")
+	}
+	now = now.Add(2 * time.Hour)
+	if _, _, ok = schedule.InMaintenance("claude"); ok {
+		t.Fatal("routing must resume when the window ends")
+	}
+	if len(schedule.Snapshot()) != 0 {
+		t.Fatal("ended windows must not be reported")
+	}
+}
+
+func TestScheduleOverrides(t *testing.T) {
+	schedule := NewSchedule()
+	now := time.Date(2025, 6, 1, 3, 0, 0, 0, time.UTC)
+	schedule.now = func() time.Time { return now }
+	schedule.Configure([]config.MaintenanceWindow{
+		{Provider: "claude", Start: "2025-06-01T02:00:00Z", End: "2025-06-01T04:00:00Z"},
+	})
+
+	schedule.SetOverride("claude", StateActive, time.Time{}, "")
+	if _, _, ok := schedule.InMaintenance("claude"); ok {
+		t.Fatal("active override must lift a configured window")
+	}
+	schedule.SetOverride("codex", StateMaintenance, now.Add(10*time.Minute), "incident")
+	if reason, _, ok := schedule.InMaintenance("codex"); !ok || reason != "incident" {
+		t.Fatalf("maintenance override not applied: %q %v", reason, ok)
+	}
+	snapshot := schedule.Snapshot()
+	if len(snapshot) != 2 || snapshot[0].Provider != "claude" || snapshot[0].Override != StateActive || !snapshot[1].InMaintenance {
+		t.Fatalf("unexpected snapshot: %+v", snapshot)
+	}
+
+	now = now.Add(15 * time.Minute)
+	if _, _, ok := schedule.InMaintenance("codex"); ok {
+		t.Fatal("override must expire at its until time")
+	}
+	if !schedule.ClearOverride("claude") {
+		t.Fatal("ClearOverride must report the removed override")
+	}
+	if _, _, ok := schedule.InMaintenance("claude"); !ok {
+		t.Fatal("configured window must apply again once the override is cleared")
+	}
+}
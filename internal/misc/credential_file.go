@@ -0,0 +1,139 @@
+package misc
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	credentialBackupSuffix = ".bak"
+	credentialBackupLayout = "20060102T150405Z"
+)
+
+// credentialWriteLocks serializes writers of the same credential file within the process.
+var credentialWriteLocks sync.Map
+
+// WriteCredentialFile replaces the credential file at path with data. The content is written to
+// a temporary file in the same directory, synced and renamed over the target, so a crash leaves
+// either the old or the new credential but never a truncated one. When the content changes, the
+// previous version is kept as a single timestamped backup next to the file.
+func WriteCredentialFile(path string, data []byte) error {
+	path = filepath.Clean(path)
+	lock, _ := credentialWriteLocks.LoadOrStore(path, &sync.Mutex{})
+	mu := lock.(*sync.Mutex)
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	previous, errRead := os.ReadFile(path)
+	switch {
+	case errRead == nil:
+		if bytes.Equal(previous, data) {
+			return nil
+		}
+		if errBackup := backupCredentialFile(path, previous); errBackup != nil {
+			log.Warnf("failed to back up credential file %s: %v", path, errBackup)
+		}
+	case !os.IsNotExist(errRead):
+		return fmt.Errorf("failed to read existing credential file: %w", errRead)
+	}
+	return writeFileAtomic(path, data, 0o600)
+}
+
+// CredentialBackups lists the backups kept for the credential file at path, newest first.
+func CredentialBackups(path string) ([]string, error) {
+	path = filepath.Clean(path)
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	prefix := filepath.Base(path) + "."
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, credentialBackupSuffix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), credentialBackupSuffix)
+		if _, errParse := time.Parse(credentialBackupLayout, stamp); errParse != nil {
+			continue
+		}
+		backups = append(backups, filepath.Join(filepath.Dir(path), name))
+	}
+	// The timestamp layout sorts lexically, so reverse name order is newest first.
+	for i, j := 0, len(backups)-1; i < j; i, j = i+1, j-1 {
+		backups[i], backups[j] = backups[j], backups[i]
+	}
+	return backups, nil
+}
+
+// backupCredentialFile stores previous as the only backup of path, dropping older ones.
+func backupCredentialFile(path string, previous []byte) error {
+	backup := fmt.Sprintf("%s.%s%s", path, time.Now().UTC().Format(credentialBackupLayout), credentialBackupSuffix)
+	if err := writeFileAtomic(backup, previous, 0o600); err != nil {
+		return err
+	}
+	backups, err := CredentialBackups(path)
+	if err != nil {
+		return err
+	}
+	for _, old := range backups {
+		if old != backup {
+			_ = os.Remove(old)
+		}
+	}
+	return nil
+}
+
+func writeFileAtomic(path string, data []byte, perm os.FileMode) (err error) {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpName := tmp.Name()
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmpName)
+		}
+	}()
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to sync temp file: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+	if err = os.Chmod(tmpName, perm); err != nil {
+		return fmt.Errorf("failed to set file mode: %w", err)
+	}
+	if err = os.Rename(tmpName, path); err != nil {
+		return fmt.Errorf("failed to replace file: %w", err)
+	}
+	syncDir(dir)
+	return nil
+}
+
+// syncDir flushes the directory entry after a rename. Platforms that cannot sync directories
+// are ignored; the rename itself is still atomic there.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
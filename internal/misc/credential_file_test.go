@@ -0,0 +1,53 @@
+package misc
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteCredentialFileKeepsOneBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "claude-user.json")
+
+	if err := WriteCredentialFile(path, []byte(`{"v":1}`)); err != nil {
+		t.Fatalf("first write: %v", err)
+	}
+	if backups, _ := CredentialBackups(path); len(backups) != 0 {
+		t.Fatalf("new file must not create a backup, got %v", backups)
+	}
+	if err := WriteCredentialFile(path, []byte(`{"v":2}`)); err != nil {
+		t.Fatalf("second write: %v", err)
+	}
+	// Simulate an older backup left by an earlier write.
+	stale := path + ".20200101T000000Z" + credentialBackupSuffix
+	if err := os.WriteFile(stale, []byte(`{"v":0}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteCredentialFile(path, []byte(`{"v":3}`)); err != nil {
+		t.Fatalf("third write: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil || string(got) != `{"v":3}` {
+		t.Fatalf("current content = %s, %v", got, err)
+	}
+	backups, err := CredentialBackups(path)
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("backups = %v, %v; want exactly one", backups, err)
+	}
+	if previous, _ := os.ReadFile(backups[0]); string(previous) != `{"v":2}` {
+		t.Fatalf("backup content = %s, want previous token", previous)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Fatalf("mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".tmp-") {
+			t.Fatalf("temp file left behind: %s", entry.Name())
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package misc
+
+import "sync"
+
+// AuthLoadConcurrency bounds how many auth files are read and parsed at once. Loading is
+// dominated by file I/O and, for some providers, a network lookup, so it exceeds the CPU count.
+const AuthLoadConcurrency = 16
+
+// ParallelFor calls fn for every index in [0, n) on at most workers goroutines and returns once
+// all calls finished. Callers write results into per-index slots to keep the input order.
+func ParallelFor(n, workers int, fn func(i int)) {
+	if n <= 0 {
+		return
+	}
+	if workers <= 0 || workers > n {
+		workers = n
+	}
+	if workers == 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
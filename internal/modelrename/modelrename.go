@@ -0,0 +1,193 @@
+// Package modelrename maps deprecated model IDs to the models that replace them.
+//
+// Providers rename and retire models while client configurations keep asking for the old IDs.
+// The table starts from built-in renames that configuration can extend or remove; operators can
+// add or drop renames at runtime through the management API. Runtime changes win over
+// configuration until they are cleared and are not persisted.
+package modelrename
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxHops bounds how many renames are followed for one model, guarding against cycles.
+const maxHops = 8
+
+// builtin lists renames announced by providers.
+var builtin = []config.ModelRename{
+	{From: "gemini-2.5-pro-preview-06-05", To: "gemini-2.5-pro", Reason: "preview replaced by the stable release"},
+	{From: "gemini-2.5-pro-preview-05-06", To: "gemini-2.5-pro", Reason: "preview replaced by the stable release"},
+	{From: "gemini-2.5-flash-preview-05-20", To: "gemini-2.5-flash", Reason: "preview replaced by the stable release"},
+	{From: "gemini-2.5-flash-lite-preview-06-17", To: "gemini-2.5-flash-lite", Reason: "preview replaced by the stable release"},
+}
+
+var defaultTable = NewTable()
+
+// Default returns the process-wide rename table.
+func Default() *Table { return defaultTable }
+
+// Table holds the configured renames and runtime overrides.
+type Table struct {
+	mu         sync.RWMutex
+	configured map[string]entry
+	overrides  map[string]entry
+}
+
+type entry struct {
+	from   string
+	to     string
+	reason string
+	source string
+}
+
+// Rename describes one deprecated model ID.
+type Rename struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Reason string `json:"reason,omitempty"`
+	// Source is where the rename comes from: "builtin", "config" or "override".
+	Source string `json:"source"`
+}
+
+// NewTable creates a table holding the built-in renames.
+func NewTable() *Table {
+	t := &Table{overrides: map[string]entry{}}
+	t.Configure(nil)
+	return t
+}
+
+// Configure replaces the configured renames with the built-in ones extended by entries. An entry
+// without a successor removes the rename of its model. Runtime overrides are kept.
+func (t *Table) Configure(entries []config.ModelRename) {
+	if t == nil {
+		return
+	}
+	configured := make(map[string]entry, len(builtin)+len(entries))
+	for _, rename := range builtin {
+		configured[normalize(rename.From)] = newEntry(rename, "builtin")
+	}
+	for _, rename := range entries {
+		key := normalize(rename.From)
+		if key == "" {
+			log.Warnf("model renames: ignoring entry without a model to rename (to %q)", rename.To)
+			continue
+		}
+		if strings.TrimSpace(rename.To) == "" {
+			delete(configured, key)
+			continue
+		}
+		configured[key] = newEntry(rename, "config")
+	}
+	t.mu.Lock()
+	t.configured = configured
+	t.mu.Unlock()
+}
+
+// Successor returns the model that replaces model and the reason for the rename, following
+// chained renames. ok is false when model is not deprecated.
+func (t *Table) Successor(model string) (successor, reason string, ok bool) {
+	if t == nil {
+		return "", "", false
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	current := model
+	seen := map[string]bool{normalize(model): true}
+	for hop := 0; hop < maxHops; hop++ {
+		e, found := t.lookupLocked(current)
+		if !found || e.to == "" {
+			break
+		}
+		if reason == "" {
+			reason = e.reason
+		}
+		current = e.to
+		ok = true
+		if seen[normalize(current)] {
+			log.Warnf("model renames: cycle through %s, using %s", model, current)
+			break
+		}
+		seen[normalize(current)] = true
+	}
+	if !ok {
+		return "", "", false
+	}
+	return current, reason, true
+}
+
+// SetOverride renames from to to at runtime. An empty to suspends the configured rename of from.
+func (t *Table) SetOverride(from, to, reason string) bool {
+	if t == nil || normalize(from) == "" {
+		return false
+	}
+	t.mu.Lock()
+	t.overrides[normalize(from)] = newEntry(config.ModelRename{From: from, To: to, Reason: reason}, "override")
+	t.mu.Unlock()
+	return true
+}
+
+// ClearOverride removes the runtime override of from, restoring its configured rename.
+func (t *Table) ClearOverride(from string) bool {
+	if t == nil {
+		return false
+	}
+	key := normalize(from)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.overrides[key]
+	delete(t.overrides, key)
+	return ok
+}
+
+// Snapshot lists the renames in effect, sorted by deprecated model ID.
+func (t *Table) Snapshot() []Rename {
+	if t == nil {
+		return nil
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	keys := make(map[string]struct{}, len(t.configured)+len(t.overrides))
+	for key := range t.configured {
+		keys[key] = struct{}{}
+	}
+	for key := range t.overrides {
+		keys[key] = struct{}{}
+	}
+	out := make([]Rename, 0, len(keys))
+	for key := range keys {
+		e, _ := t.lookupLocked(key)
+		if e.to == "" {
+			continue
+		}
+		out = append(out, Rename{From: e.from, To: e.to, Reason: e.reason, Source: e.source})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].From < out[j].From })
+	return out
+}
+
+func (t *Table) lookupLocked(model string) (entry, bool) {
+	key := normalize(model)
+	if e, ok := t.overrides[key]; ok {
+		return e, true
+	}
+	e, ok := t.configured[key]
+	return e, ok
+}
+
+func newEntry(rename config.ModelRename, source string) entry {
+	return entry{
+		from:   strings.TrimSpace(rename.From),
+		to:     strings.TrimSpace(rename.To),
+		reason: strings.TrimSpace(rename.Reason),
+		source: source,
+	}
+}
+
+func normalize(model string) string {
+	return strings.ToLower(strings.TrimSpace(model))
+}
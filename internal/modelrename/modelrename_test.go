@@ -0,0 +1,52 @@
+package modelrename
+
+import (
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestTableSuccessor(t *testing.T) {
+	table := NewTable()
+	if to, _, ok := table.Successor("Gemini-2.5-Pro-Preview-06-05"); !ok || to != "gemini-2.5-pro" {
+		t.Fatalf("built-in rename = %q, %v; want gemini-2.5-pro", to, ok)
+	}
+	if _, _, ok := table.Successor("gemini-2.5-pro"); ok {
+		t.Fatal("a current model must not be renamed")
+	}
+
+	table.Configure([]config.ModelRename{
+		{From: "old-model", To: "mid-model", Reason: "renamed upstream"},
+		{From: "mid-model", To: "new-model"},
+		{From: "gemini-2.5-pro-preview-06-05"},
+	})
+	if to, reason, ok := table.Successor("old-model"); !ok || to != "new-model" || reason != "renamed upstream" {
+		t.Fatalf("chained rename = %q, %q, %v; want new-model with the first reason", to, reason, ok)
+	}
+	if _, _, ok := table.Successor("gemini-2.5-pro-preview-06-05"); ok {
+		t.Fatal("an entry without a successor must remove the built-in rename")
+	}
+
+	table.SetOverride("new-model", "old-model", "")
+	if to, _, ok := table.Successor("old-model"); !ok || to != "old-model" {
+		t.Fatalf("a rename cycle must stop where it closes, got %q, %v", to, ok)
+	}
+	table.SetOverride("mid-model", "", "")
+	if to, _, _ := table.Successor("old-model"); to != "mid-model" {
+		t.Fatalf("an override without a successor must suspend the rename, got %q", to)
+	}
+	for _, rename := range table.Snapshot() {
+		if rename.From == "mid-model" {
+			t.Fatalf("a suspended rename must not be listed: %+v", rename)
+		}
+	}
+
+	if !table.ClearOverride("MID-MODEL") || table.ClearOverride("mid-model") {
+		t.Fatal("ClearOverride must report whether an override was removed")
+	}
+	table.ClearOverride("new-model")
+	table.Configure(nil)
+	if to, _, ok := table.Successor("old-model"); ok {
+		t.Fatalf("reconfiguring must drop removed renames, got %q", to)
+	}
+}
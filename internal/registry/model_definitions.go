@@ -823,6 +823,7 @@ func GetGitHubCopilotModels() []*ModelInfo {
 			Description:         "OpenAI GPT-4.1 via GitHub Copilot",
 			ContextLength:       128000,
 			MaxCompletionTokens: 16384,
+			SupportsVision:      true,
 		},
 		{
 			ID:                  "gpt-5",
@@ -834,6 +835,7 @@ func GetGitHubCopilotModels() []*ModelInfo {
 			Description:         "OpenAI GPT-5 via GitHub Copilot",
 			ContextLength:       200000,
 			MaxCompletionTokens: 32768,
+			SupportsVision:      true,
 		},
 		{
 			ID:                  "gpt-5-mini",
@@ -845,6 +847,7 @@ func GetGitHubCopilotModels() []*ModelInfo {
 			Description:         "OpenAI GPT-5 Mini via GitHub Copilot",
 			ContextLength:       128000,
 			MaxCompletionTokens: 16384,
+			SupportsVision:      true,
 		},
 		{
 			ID:                  "gpt-5-codex",
@@ -867,6 +870,7 @@ func GetGitHubCopilotModels() []*ModelInfo {
 			Description:         "OpenAI GPT-5.1 via GitHub Copilot",
 			ContextLength:       200000,
 			MaxCompletionTokens: 32768,
+			SupportsVision:      true,
 		},
 		{
 			ID:                  "gpt-5.1-codex",
@@ -900,6 +904,7 @@ func GetGitHubCopilotModels() []*ModelInfo {
 			Description:         "OpenAI GPT-5.2 via GitHub Copilot",
 			ContextLength:       200000,
 			MaxCompletionTokens: 32768,
+			SupportsVision:      true,
 		},
 		{
 			ID:                  "claude-haiku-4.5",
@@ -911,6 +916,7 @@ func GetGitHubCopilotModels() []*ModelInfo {
 			Description:         "Anthropic Claude Haiku 4.5 via GitHub Copilot",
 			ContextLength:       200000,
 			MaxCompletionTokens: 64000,
+			SupportsVision:      true,
 		},
 		{
 			ID:                  "claude-opus-4.1",
@@ -922,6 +928,7 @@ func GetGitHubCopilotModels() []*ModelInfo {
 			Description:         "Anthropic Claude Opus 4.1 via GitHub Copilot",
 			ContextLength:       200000,
 			MaxCompletionTokens: 32000,
+			SupportsVision:      true,
 		},
 		{
 			ID:                  "claude-opus-4.5",
@@ -933,6 +940,7 @@ func GetGitHubCopilotModels() []*ModelInfo {
 			Description:         "Anthropic Claude Opus 4.5 via GitHub Copilot",
 			ContextLength:       200000,
 			MaxCompletionTokens: 64000,
+			SupportsVision:      true,
 		},
 		{
 			ID:                  "claude-sonnet-4",
@@ -944,6 +952,7 @@ func GetGitHubCopilotModels() []*ModelInfo {
 			Description:         "Anthropic Claude Sonnet 4 via GitHub Copilot",
 			ContextLength:       200000,
 			MaxCompletionTokens: 64000,
+			SupportsVision:      true,
 		},
 		{
 			ID:                  "claude-sonnet-4.5",
@@ -955,6 +964,7 @@ func GetGitHubCopilotModels() []*ModelInfo {
 			Description:         "Anthropic Claude Sonnet 4.5 via GitHub Copilot",
 			ContextLength:       200000,
 			MaxCompletionTokens: 64000,
+			SupportsVision:      true,
 		},
 		{
 			ID:                  "gemini-2.5-pro",
@@ -966,6 +976,7 @@ func GetGitHubCopilotModels() []*ModelInfo {
 			Description:         "Google Gemini 2.5 Pro via GitHub Copilot",
 			ContextLength:       1048576,
 			MaxCompletionTokens: 65536,
+			SupportsVision:      true,
 		},
 		{
 			ID:                  "gemini-3-pro",
@@ -977,6 +988,7 @@ func GetGitHubCopilotModels() []*ModelInfo {
 			Description:         "Google Gemini 3 Pro via GitHub Copilot",
 			ContextLength:       1048576,
 			MaxCompletionTokens: 65536,
+			SupportsVision:      true,
 		},
 		{
 			ID:                  "grok-code-fast-1",
@@ -1000,6 +1012,28 @@ func GetGitHubCopilotModels() []*ModelInfo {
 			ContextLength:       128000,
 			MaxCompletionTokens: 16384,
 		},
+		{
+			ID:                         "text-embedding-3-small",
+			Object:                     "model",
+			Created:                    now,
+			OwnedBy:                    "github-copilot",
+			Type:                       "github-copilot",
+			DisplayName:                "Text Embedding 3 Small",
+			Description:                "OpenAI text-embedding-3-small via GitHub Copilot",
+			ContextLength:              8191,
+			SupportedGenerationMethods: []string{"embeddings"},
+		},
+		{
+			ID:                         "text-embedding-ada-002",
+			Object:                     "model",
+			Created:                    now,
+			OwnedBy:                    "github-copilot",
+			Type:                       "github-copilot",
+			DisplayName:                "Text Embedding Ada 002",
+			Description:                "OpenAI text-embedding-ada-002 via GitHub Copilot",
+			ContextLength:              8191,
+			SupportedGenerationMethods: []string{"embeddings"},
+		},
 	}
 }
 
@@ -9,6 +9,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	misc "github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
@@ -47,6 +48,8 @@ type ModelInfo struct {
 	MaxCompletionTokens int `json:"max_completion_tokens,omitempty"`
 	// SupportedParameters lists supported parameters
 	SupportedParameters []string `json:"supported_parameters,omitempty"`
+	// SupportsVision reports whether the model accepts image inputs
+	SupportsVision bool `json:"supports_vision,omitempty"`
 
 	// Thinking holds provider-specific reasoning/thinking budget capabilities.
 	// This is optional and currently used for Gemini thinking budget normalization.
@@ -107,6 +110,8 @@ type ModelRegistry struct {
 	mutex *sync.RWMutex
 	// hook is an optional callback sink for model registration changes
 	hook ModelRegistryHook
+	// generation increments whenever a client's model set changes
+	generation atomic.Uint64
 }
 
 // Global model registry instance
@@ -182,6 +187,7 @@ func (r *ModelRegistry) triggerModelsUnregistered(provider, clientID string) {
 func (r *ModelRegistry) RegisterClient(clientID, clientProvider string, models []*ModelInfo) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
+	defer r.generation.Add(1)
 
 	provider := strings.ToLower(clientProvider)
 	uniqueModelIDs := make([]string, 0, len(models))
@@ -484,6 +490,7 @@ func cloneModelInfosUnique(models []*ModelInfo) []*ModelInfo {
 func (r *ModelRegistry) UnregisterClient(clientID string) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
+	defer r.generation.Add(1)
 	r.unregisterClientInternal(clientID)
 }
 
@@ -624,6 +631,12 @@ func (r *ModelRegistry) ResumeClientModel(clientID, modelID string) {
 	log.Debugf("Resumed client %s for model %s", clientID, modelID)
 }
 
+// Generation returns a counter that changes whenever any client's supported models change, so
+// callers can cache per-model lookups and drop them when the registry moves on.
+func (r *ModelRegistry) Generation() uint64 {
+	return r.generation.Load()
+}
+
 // ClientSupportsModel reports whether the client registered support for modelID.
 func (r *ModelRegistry) ClientSupportsModel(clientID, modelID string) bool {
 	clientID = strings.TrimSpace(clientID)
@@ -968,6 +981,9 @@ func (r *ModelRegistry) convertModelToMap(model *ModelInfo, handlerType string)
 		if len(model.SupportedParameters) > 0 {
 			result["supported_parameters"] = model.SupportedParameters
 		}
+		if model.SupportsVision {
+			result["supports_vision"] = true
+		}
 		return result
 
 	case "claude", "kiro", "antigravity":
@@ -0,0 +1,490 @@
+// Package replay persists requests that failed because of transient provider outages and replays
+// them on demand, delivering each outcome to a webhook. It is intended for asynchronous agent
+// pipelines that would rather receive a late answer than retry on their own.
+package replay
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultDirName    = "replay-queue"
+	defaultMaxEntries = 1000
+	webhookTimeout    = 30 * time.Second
+	// SignatureHeader carries the hex HMAC-SHA256 of the webhook body when a secret is configured.
+	SignatureHeader = "X-CLIProxy-Signature"
+)
+
+// ErrReplayInProgress is returned when a replay run is requested while another one is active.
+var ErrReplayInProgress = errors.New("replay already in progress")
+
+// Entry is a captured request.
+type Entry struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	// APIKey is the client key the request is replayed for. It is never written to disk: entries
+	// keep APIKeyHash, and the key is resolved from the configured api-keys when replaying.
+	APIKey        string      `json:"api_key,omitempty"`
+	APIKeyHash    string      `json:"api_key_hash,omitempty"`
+	APIKeyLabel   string      `json:"api_key_label,omitempty"`
+	HandlerType   string      `json:"handler_type"`
+	Model         string      `json:"model"`
+	Alt           string      `json:"alt,omitempty"`
+	Path          string      `json:"path,omitempty"`
+	Headers       http.Header `json:"headers,omitempty"`
+	Payload       []byte      `json:"payload"`
+	StatusCode    int         `json:"status_code"`
+	Error         string      `json:"error,omitempty"`
+	Attempts      int         `json:"attempts"`
+	LastAttemptAt *time.Time  `json:"last_attempt_at,omitempty"`
+}
+
+// Result is the outcome of replaying an entry.
+type Result struct {
+	Body       []byte
+	StatusCode int
+	Err        error
+}
+
+// Executor re-runs a captured request through the normal request pipeline.
+type Executor func(ctx context.Context, entry Entry) Result
+
+// webhookPayload is the JSON body POSTed to the configured webhook.
+type webhookPayload struct {
+	ID          string          `json:"id"`
+	Status      string          `json:"status"`
+	Model       string          `json:"model"`
+	HandlerType string          `json:"handler_type"`
+	CreatedAt   time.Time       `json:"created_at"`
+	ReplayedAt  time.Time       `json:"replayed_at"`
+	Attempts    int             `json:"attempts"`
+	StatusCode  int             `json:"status_code"`
+	Response    json.RawMessage `json:"response,omitempty"`
+	Error       string          `json:"error,omitempty"`
+}
+
+// Queue stores replayable requests on disk.
+type Queue struct {
+	mu        sync.Mutex
+	dir       string
+	keys      map[string]struct{}
+	max       int
+	webhook   string
+	secret    string
+	executor  Executor
+	client    *http.Client
+	replaying atomic.Bool
+}
+
+var defaultQueue = &Queue{client: &http.Client{Timeout: webhookTimeout}}
+
+// Default returns the process-wide queue.
+func Default() *Queue { return defaultQueue }
+
+// Configure applies cfg. Entries are stored in a "replay-queue" directory under dataDir unless
+// cfg names a directory.
+func (q *Queue) Configure(cfg config.ReplayQueueConfig, dataDir string) {
+	if q == nil {
+		return
+	}
+	keys := make(map[string]struct{}, len(cfg.APIKeys))
+	for _, key := range cfg.APIKeys {
+		if key = strings.TrimSpace(key); key != "" {
+			keys[key] = struct{}{}
+		}
+	}
+	dir := strings.TrimSpace(cfg.Dir)
+	if dir == "" && strings.TrimSpace(dataDir) != "" {
+		dir = filepath.Join(dataDir, defaultDirName)
+	}
+	maxEntries := cfg.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultMaxEntries
+	}
+	q.mu.Lock()
+	q.keys = keys
+	q.dir = dir
+	q.max = maxEntries
+	q.webhook = strings.TrimSpace(cfg.WebhookURL)
+	q.secret = cfg.WebhookSecret
+	q.mu.Unlock()
+}
+
+// SetExecutor installs the function used to re-run entries.
+func (q *Queue) SetExecutor(executor Executor) {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	q.executor = executor
+	q.mu.Unlock()
+}
+
+// Enabled reports whether requests authenticated with apiKey are captured.
+func (q *Queue) Enabled(apiKey string) bool {
+	if q == nil {
+		return false
+	}
+	apiKey = strings.TrimSpace(apiKey)
+	if apiKey == "" {
+		return false
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.dir == "" {
+		return false
+	}
+	_, ok := q.keys[apiKey]
+	return ok
+}
+
+// IsTransient reports whether an upstream status indicates an outage worth replaying.
+func IsTransient(status int) bool {
+	switch status {
+	case http.StatusRequestTimeout, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout, 529:
+		return true
+	default:
+		return false
+	}
+}
+
+// Capture persists entry, assigning an ID and creation time. Credentials are stripped from headers.
+func (q *Queue) Capture(entry Entry) (Entry, error) {
+	if q == nil {
+		return entry, errors.New("replay queue unavailable")
+	}
+	entry.ID = uuid.NewString()
+	entry.CreatedAt = time.Now().UTC()
+	entry.Headers = sanitizeHeaders(entry.Headers)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.dir == "" {
+		return entry, errors.New("replay queue directory is not configured")
+	}
+	if err := os.MkdirAll(q.dir, 0o700); err != nil {
+		return entry, fmt.Errorf("create replay queue directory: %w", err)
+	}
+	if err := q.writeLocked(entry); err != nil {
+		return entry, err
+	}
+	q.trimLocked()
+	return entry, nil
+}
+
+// List returns every stored entry, oldest first.
+func (q *Queue) List() ([]Entry, error) {
+	if q == nil {
+		return nil, nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.listLocked()
+}
+
+// Delete removes the entry with the given ID.
+func (q *Queue) Delete(id string) error {
+	if q == nil {
+		return os.ErrNotExist
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	path, err := q.pathLocked(id)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// ReplayAsync replays the given entries (or all entries when ids is empty) sequentially in the
+// background. It returns the number of entries scheduled.
+func (q *Queue) ReplayAsync(ids []string) (int, error) {
+	if q == nil {
+		return 0, errors.New("replay queue unavailable")
+	}
+	q.mu.Lock()
+	executor := q.executor
+	entries, err := q.listLocked()
+	q.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	if executor == nil {
+		return 0, errors.New("replay executor is not configured")
+	}
+	if len(ids) > 0 {
+		wanted := make(map[string]struct{}, len(ids))
+		for _, id := range ids {
+			wanted[strings.TrimSpace(id)] = struct{}{}
+		}
+		filtered := entries[:0]
+		for _, entry := range entries {
+			if _, ok := wanted[entry.ID]; ok {
+				filtered = append(filtered, entry)
+			}
+		}
+		entries = filtered
+	}
+	if len(entries) == 0 {
+		return 0, nil
+	}
+	if !q.replaying.CompareAndSwap(false, true) {
+		return 0, ErrReplayInProgress
+	}
+	go func() {
+		defer q.replaying.Store(false)
+		for _, entry := range entries {
+			q.replayOne(executor, entry)
+		}
+	}()
+	return len(entries), nil
+}
+
+// Replaying reports whether a replay run is active.
+func (q *Queue) Replaying() bool { return q != nil && q.replaying.Load() }
+
+func (q *Queue) replayOne(executor Executor, entry Entry) {
+	var result Result
+	if apiKey, ok := q.resolveAPIKey(entry.APIKeyHash); ok {
+		replayed := entry
+		replayed.APIKey = apiKey
+		result = executor(context.Background(), replayed)
+	} else {
+		result = Result{StatusCode: http.StatusUnauthorized, Err: errors.New("client API key is no longer enabled for the replay queue")}
+	}
+	now := time.Now().UTC()
+	entry.Attempts++
+	entry.LastAttemptAt = &now
+
+	payload := webhookPayload{
+		ID:          entry.ID,
+		Model:       entry.Model,
+		HandlerType: entry.HandlerType,
+		CreatedAt:   entry.CreatedAt,
+		ReplayedAt:  now,
+		Attempts:    entry.Attempts,
+		StatusCode:  result.StatusCode,
+	}
+	if result.Err == nil {
+		payload.Status = "succeeded"
+		if payload.StatusCode == 0 {
+			payload.StatusCode = http.StatusOK
+		}
+		if json.Valid(result.Body) {
+			payload.Response = json.RawMessage(result.Body)
+		} else {
+			raw, _ := json.Marshal(string(result.Body))
+			payload.Response = raw
+		}
+	} else {
+		payload.Status = "failed"
+		payload.Error = result.Err.Error()
+	}
+
+	delivered := q.deliver(payload)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	path, errPath := q.pathLocked(entry.ID)
+	if errPath != nil {
+		return
+	}
+	if _, errStat := os.Stat(path); errStat != nil {
+		// Deleted while the replay was running.
+		return
+	}
+	if result.Err == nil && delivered {
+		if errRemove := os.Remove(path); errRemove != nil {
+			log.Warnf("replay queue: failed to remove %s: %v", entry.ID, errRemove)
+		}
+		return
+	}
+	if result.Err != nil {
+		entry.StatusCode = result.StatusCode
+		entry.Error = result.Err.Error()
+	}
+	if errWrite := q.writeLocked(entry); errWrite != nil {
+		log.Warnf("replay queue: failed to update %s: %v", entry.ID, errWrite)
+	}
+}
+
+// deliver posts the outcome to the webhook. It reports true when no webhook is configured.
+func (q *Queue) deliver(payload webhookPayload) bool {
+	q.mu.Lock()
+	url, secret, client := q.webhook, q.secret, q.client
+	q.mu.Unlock()
+	if url == "" {
+		return true
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Warnf("replay queue: failed to encode webhook payload for %s: %v", payload.ID, err)
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		log.Warnf("replay queue: invalid webhook url: %v", err)
+		return false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		_, _ = mac.Write(body)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Warnf("replay queue: webhook delivery for %s failed: %v", payload.ID, err)
+		return false
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		log.Warnf("replay queue: webhook delivery for %s returned status %d", payload.ID, resp.StatusCode)
+		return false
+	}
+	return true
+}
+
+func (q *Queue) writeLocked(entry Entry) error {
+	if entry.APIKey != "" {
+		entry.APIKeyHash = hashAPIKey(entry.APIKey)
+		entry.APIKeyLabel = util.HideAPIKey(entry.APIKey)
+		entry.APIKey = ""
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode replay entry: %w", err)
+	}
+	path, err := q.pathLocked(entry.ID)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write replay entry: %w", err)
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("write replay entry: %w", err)
+	}
+	return nil
+}
+
+func (q *Queue) listLocked() ([]Entry, error) {
+	if q.dir == "" {
+		return nil, nil
+	}
+	files, err := os.ReadDir(q.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(files))
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		data, errRead := os.ReadFile(filepath.Join(q.dir, file.Name()))
+		if errRead != nil {
+			continue
+		}
+		var entry Entry
+		if errUnmarshal := json.Unmarshal(data, &entry); errUnmarshal != nil || entry.ID == "" {
+			continue
+		}
+		if entry.APIKey != "" {
+			// Entries written before keys were hashed store the key itself; scrub them.
+			if errWrite := q.writeLocked(entry); errWrite != nil {
+				log.Warnf("replay queue: failed to scrub the API key of %s: %v", entry.ID, errWrite)
+			}
+			entry.APIKeyHash, entry.APIKeyLabel, entry.APIKey = hashAPIKey(entry.APIKey), util.HideAPIKey(entry.APIKey), ""
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].CreatedAt.Before(entries[j].CreatedAt) })
+	return entries, nil
+}
+
+// trimLocked drops the oldest entries beyond the configured maximum.
+func (q *Queue) trimLocked() {
+	entries, err := q.listLocked()
+	if err != nil || len(entries) <= q.max {
+		return
+	}
+	for _, entry := range entries[:len(entries)-q.max] {
+		if path, errPath := q.pathLocked(entry.ID); errPath == nil {
+			_ = os.Remove(path)
+		}
+	}
+}
+
+func (q *Queue) pathLocked(id string) (string, error) {
+	id = strings.TrimSpace(id)
+	if q.dir == "" {
+		return "", errors.New("replay queue directory is not configured")
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return "", os.ErrNotExist
+	}
+	return filepath.Join(q.dir, id+".json"), nil
+}
+
+// resolveAPIKey returns the configured client key whose hash is hash.
+func (q *Queue) resolveAPIKey(hash string) (string, bool) {
+	if hash == "" {
+		return "", false
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for key := range q.keys {
+		if hmac.Equal([]byte(hashAPIKey(key)), []byte(hash)) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// hashAPIKey identifies a client key on disk without storing it.
+func hashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(apiKey)))
+	return hex.EncodeToString(sum[:])
+}
+
+// sanitizeHeaders drops credentials and hop-by-hop headers before an entry is written to disk.
+func sanitizeHeaders(headers http.Header) http.Header {
+	if len(headers) == 0 {
+		return nil
+	}
+	out := headers.Clone()
+	for _, name := range []string{
+		"Authorization", "X-Api-Key", "X-Goog-Api-Key", "Cookie", "Proxy-Authorization",
+		"Connection", "Content-Length", "Transfer-Encoding", "Accept-Encoding",
+	} {
+		out.Del(name)
+	}
+	return out
+}
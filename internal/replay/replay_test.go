@@ -0,0 +1,165 @@
+package replay
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestQueueCaptureAndReplay(t *testing.T) {
+	delivered := make(chan webhookPayload, 2)
+	var signature string
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write(body)
+		signature = "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		if r.Header.Get(SignatureHeader) != signature {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var payload webhookPayload
+		_ = json.Unmarshal(body, &payload)
+		delivered <- payload
+	}))
+	defer hook.Close()
+
+	q := &Queue{client: hook.Client()}
+	q.Configure(config.ReplayQueueConfig{APIKeys: []string{"k1"}, WebhookURL: hook.URL, WebhookSecret: "secret"}, t.TempDir())
+	if q.Enabled("k2") || !q.Enabled("k1") {
+		t.Fatalf("unexpected opt-in state")
+	}
+
+	headers := http.Header{"Authorization": {"Bearer k1"}, "X-Custom": {"v"}}
+	ok, err := q.Capture(Entry{APIKey: "k1", HandlerType: "openai", Model: "m", Payload: []byte(`{"a":1}`), Headers: headers, StatusCode: 503})
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	if ok.Headers.Get("Authorization") != "" || ok.Headers.Get("X-Custom") != "v" {
+		t.Fatalf("headers not sanitized: %v", ok.Headers)
+	}
+	failing, err := q.Capture(Entry{APIKey: "k1", HandlerType: "openai", Model: "bad", Payload: []byte(`{}`), StatusCode: 502})
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+
+	q.SetExecutor(func(_ context.Context, entry Entry) Result {
+		if entry.Model == "bad" {
+			return Result{StatusCode: http.StatusServiceUnavailable, Err: errors.New("still down")}
+		}
+		return Result{Body: []byte(`{"ok":true}`), StatusCode: http.StatusOK}
+	})
+	queued, err := q.ReplayAsync(nil)
+	if err != nil || queued != 2 {
+		t.Fatalf("ReplayAsync = %d, %v", queued, err)
+	}
+
+	results := map[string]webhookPayload{}
+	for len(results) < 2 {
+		select {
+		case payload := <-delivered:
+			results[payload.ID] = payload
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for webhook")
+		}
+	}
+	if got := results[ok.ID]; got.Status != "succeeded" || string(got.Response) != `{"ok":true}` {
+		t.Fatalf("unexpected success payload: %+v", got)
+	}
+	if got := results[failing.ID]; got.Status != "failed" || got.Error != "still down" {
+		t.Fatalf("unexpected failure payload: %+v", got)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for q.Replaying() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	entries, err := q.List()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(entries) != 1 || entries[0].ID != failing.ID || entries[0].Attempts != 1 {
+		t.Fatalf("expected only the failed entry to remain, got %+v", entries)
+	}
+}
+
+func TestQueueTrimsOldestEntries(t *testing.T) {
+	q := &Queue{client: http.DefaultClient}
+	q.Configure(config.ReplayQueueConfig{APIKeys: []string{"k"}, MaxEntries: 2}, t.TempDir())
+	var ids []string
+	for i := 0; i < 3; i++ {
+		entry, err := q.Capture(Entry{APIKey: "k", Model: "m", Payload: []byte(`{}`)})
+		if err != nil {
+			t.Fatalf("capture: %v", err)
+		}
+		ids = append(ids, entry.ID)
+		time.Sleep(time.Millisecond)
+	}
+	entries, _ := q.List()
+	if len(entries) != 2 || entries[0].ID != ids[1] || entries[1].ID != ids[2] {
+		t.Fatalf("unexpected entries after trim: %+v", entries)
+	}
+}
+
+func TestQueueStoresHashedAPIKey(t *testing.T) {
+	dir := t.TempDir()
+	q := &Queue{client: http.DefaultClient}
+	q.Configure(config.ReplayQueueConfig{APIKeys: []string{"client-secret-key"}}, dir)
+	entry, err := q.Capture(Entry{APIKey: "client-secret-key", Model: "m", Payload: []byte(`{}`)})
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	path, _ := q.pathLocked(entry.ID)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read entry: %v", err)
+	}
+	if strings.Contains(string(data), "client-secret-key") {
+		t.Fatalf("the client API key must not be stored: %s", data)
+	}
+
+	replayed := make(chan string, 2)
+	q.SetExecutor(func(_ context.Context, entry Entry) Result {
+		replayed <- entry.APIKey
+		return Result{StatusCode: http.StatusServiceUnavailable, Err: errors.New("down")}
+	})
+	if _, err := q.ReplayAsync(nil); err != nil {
+		t.Fatalf("ReplayAsync: %v", err)
+	}
+	select {
+	case key := <-replayed:
+		if key != "client-secret-key" {
+			t.Fatalf("replay must resolve the configured key, got %q", key)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for replay")
+	}
+	for q.Replaying() {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	q.Configure(config.ReplayQueueConfig{APIKeys: []string{"other-key"}}, dir)
+	if _, err := q.ReplayAsync(nil); err != nil {
+		t.Fatalf("ReplayAsync: %v", err)
+	}
+	for q.Replaying() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case key := <-replayed:
+		t.Fatalf("an entry whose key is no longer configured must not be replayed, got %q", key)
+	default:
+	}
+}
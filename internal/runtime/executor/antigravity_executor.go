@@ -191,7 +191,7 @@ func (e *AntigravityExecutor) Execute(ctx context.Context, auth *cliproxyauth.Au
 				log.Debugf("antigravity executor: rate limited on base url %s, retrying with fallback base url: %s", baseURL, baseURLs[idx+1])
 				continue
 			}
-			sErr := statusErr{code: httpResp.StatusCode, msg: string(bodyBytes)}
+			sErr := newHTTPStatusErr(httpResp, bodyBytes)
 			if httpResp.StatusCode == http.StatusTooManyRequests {
 				if retryAfter, parseErr := parseRetryDelay(bodyBytes); parseErr == nil && retryAfter != nil {
 					sErr.retryAfter = retryAfter
@@ -318,7 +318,7 @@ func (e *AntigravityExecutor) executeClaudeNonStream(ctx context.Context, auth *
 				log.Debugf("antigravity executor: rate limited on base url %s, retrying with fallback base url: %s", baseURL, baseURLs[idx+1])
 				continue
 			}
-			sErr := statusErr{code: httpResp.StatusCode, msg: string(bodyBytes)}
+			sErr := newHTTPStatusErr(httpResp, bodyBytes)
 			if httpResp.StatusCode == http.StatusTooManyRequests {
 				if retryAfter, parseErr := parseRetryDelay(bodyBytes); parseErr == nil && retryAfter != nil {
 					sErr.retryAfter = retryAfter
@@ -682,7 +682,7 @@ func (e *AntigravityExecutor) ExecuteStream(ctx context.Context, auth *cliproxya
 				log.Debugf("antigravity executor: rate limited on base url %s, retrying with fallback base url: %s", baseURL, baseURLs[idx+1])
 				continue
 			}
-			sErr := statusErr{code: httpResp.StatusCode, msg: string(bodyBytes)}
+			sErr := newHTTPStatusErr(httpResp, bodyBytes)
 			if httpResp.StatusCode == http.StatusTooManyRequests {
 				if retryAfter, parseErr := parseRetryDelay(bodyBytes); parseErr == nil && retryAfter != nil {
 					sErr.retryAfter = retryAfter
@@ -889,7 +889,7 @@ func (e *AntigravityExecutor) CountTokens(ctx context.Context, auth *cliproxyaut
 			log.Debugf("antigravity executor: rate limited on base url %s, retrying with fallback base url: %s", baseURL, baseURLs[idx+1])
 			continue
 		}
-		sErr := statusErr{code: httpResp.StatusCode, msg: string(bodyBytes)}
+		sErr := newHTTPStatusErr(httpResp, bodyBytes)
 		if httpResp.StatusCode == http.StatusTooManyRequests {
 			if retryAfter, parseErr := parseRetryDelay(bodyBytes); parseErr == nil && retryAfter != nil {
 				sErr.retryAfter = retryAfter
@@ -1078,7 +1078,7 @@ func (e *AntigravityExecutor) refreshToken(ctx context.Context, auth *cliproxyau
 	}
 
 	if httpResp.StatusCode < http.StatusOK || httpResp.StatusCode >= http.StatusMultipleChoices {
-		sErr := statusErr{code: httpResp.StatusCode, msg: string(bodyBytes)}
+		sErr := newHTTPStatusErr(httpResp, bodyBytes)
 		if httpResp.StatusCode == http.StatusTooManyRequests {
 			if retryAfter, parseErr := parseRetryDelay(bodyBytes); parseErr == nil && retryAfter != nil {
 				sErr.retryAfter = retryAfter
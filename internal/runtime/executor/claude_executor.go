@@ -119,6 +119,7 @@ func (e *ClaudeExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, r
 
 	// Ensure max_tokens > thinking.budget_tokens when thinking is enabled
 	body = ensureMaxTokensForThinking(model, body)
+	body = applyThinkingSignaturePolicy(auth, body)
 
 	// Extract betas from body and convert to header
 	var extraBetas []string
@@ -164,7 +165,7 @@ func (e *ClaudeExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, r
 		b, _ := io.ReadAll(httpResp.Body)
 		appendAPIResponseChunk(ctx, e.cfg, b)
 		log.Debugf("request error, error status: %d, error body: %s", httpResp.StatusCode, summarizeErrorBody(httpResp.Header.Get("Content-Type"), b))
-		err = statusErr{code: httpResp.StatusCode, msg: string(b)}
+		err = newHTTPStatusErr(httpResp, b)
 		if errClose := httpResp.Body.Close(); errClose != nil {
 			log.Errorf("response body close error: %v", errClose)
 		}
@@ -248,6 +249,7 @@ func (e *ClaudeExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.A
 
 	// Ensure max_tokens > thinking.budget_tokens when thinking is enabled
 	body = ensureMaxTokensForThinking(model, body)
+	body = applyThinkingSignaturePolicy(auth, body)
 
 	// Extract betas from body and convert to header
 	var extraBetas []string
@@ -296,7 +298,7 @@ func (e *ClaudeExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.A
 		if errClose := httpResp.Body.Close(); errClose != nil {
 			log.Errorf("response body close error: %v", errClose)
 		}
-		err = statusErr{code: httpResp.StatusCode, msg: string(b)}
+		err = newHTTPStatusErr(httpResp, b)
 		return nil, err
 	}
 	decodedBody, err := decodeResponseBody(httpResp.Body, httpResp.Header.Get("Content-Encoding"))
@@ -401,6 +403,7 @@ func (e *ClaudeExecutor) CountTokens(ctx context.Context, auth *cliproxyauth.Aut
 	if !strings.HasPrefix(model, "claude-3-5-haiku") {
 		body = checkSystemInstructions(body)
 	}
+	body = applyThinkingSignaturePolicy(auth, body)
 
 	// Extract betas from body and convert to header (for count_tokens too)
 	var extraBetas []string
@@ -446,7 +449,7 @@ func (e *ClaudeExecutor) CountTokens(ctx context.Context, auth *cliproxyauth.Aut
 		if errClose := resp.Body.Close(); errClose != nil {
 			log.Errorf("response body close error: %v", errClose)
 		}
-		return cliproxyexecutor.Response{}, statusErr{code: resp.StatusCode, msg: string(b)}
+		return cliproxyexecutor.Response{}, newHTTPStatusErr(resp, b)
 	}
 	decodedBody, err := decodeResponseBody(resp.Body, resp.Header.Get("Content-Encoding"))
 	if err != nil {
@@ -551,6 +554,29 @@ func disableThinkingIfToolChoiceForced(body []byte) []byte {
 	return body
 }
 
+// applyThinkingSignaturePolicy strips thinking signatures and redacted_thinking blocks from the
+// message history when the auth is configured with strip-thinking-signatures. By default they are
+// passed through untouched, since Anthropic verifies them on multi-turn tool use with thinking.
+func applyThinkingSignaturePolicy(auth *cliproxyauth.Auth, body []byte) []byte {
+	if auth == nil || auth.Attributes == nil || !strings.EqualFold(strings.TrimSpace(auth.Attributes["strip_thinking_signatures"]), "true") {
+		return body
+	}
+	messages := gjson.GetBytes(body, "messages").Array()
+	for i := len(messages) - 1; i >= 0; i-- {
+		content := messages[i].Get("content").Array()
+		for j := len(content) - 1; j >= 0; j-- {
+			path := fmt.Sprintf("messages.%d.content.%d", i, j)
+			switch content[j].Get("type").String() {
+			case "redacted_thinking":
+				body, _ = sjson.DeleteBytes(body, path)
+			case "thinking":
+				body, _ = sjson.DeleteBytes(body, path+".signature")
+			}
+		}
+	}
+	return body
+}
+
 // ensureMaxTokensForThinking ensures max_tokens > thinking.budget_tokens when thinking is enabled.
 // Anthropic API requires this constraint; violating it returns a 400 error.
 // This function should be called after all thinking configuration is finalized.
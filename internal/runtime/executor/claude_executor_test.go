@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"testing"
 
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	"github.com/tidwall/gjson"
 )
 
@@ -49,3 +50,21 @@ func TestStripClaudeToolPrefixFromStreamLine(t *testing.T) {
 		t.Fatalf("content_block.name = %q, want %q", got, "alpha")
 	}
 }
+
+func TestApplyThinkingSignaturePolicy(t *testing.T) {
+	input := []byte(`{"messages":[{"role":"assistant","content":[{"type":"thinking","thinking":"hmm","signature":"sig"},{"type":"redacted_thinking","data":"xyz"},{"type":"text","text":"ok"}]}]}`)
+
+	if out := applyThinkingSignaturePolicy(&cliproxyauth.Auth{}, input); !bytes.Equal(out, input) {
+		t.Fatalf("thinking blocks must pass through by default, got %s", out)
+	}
+
+	auth := &cliproxyauth.Auth{Attributes: map[string]string{"strip_thinking_signatures": "true"}}
+	out := applyThinkingSignaturePolicy(auth, input)
+	content := gjson.GetBytes(out, "messages.0.content")
+	if n := len(content.Array()); n != 2 {
+		t.Fatalf("expected redacted_thinking to be removed, got %s", content.Raw)
+	}
+	if content.Get("0.signature").Exists() || content.Get("0.thinking").String() != "hmm" {
+		t.Fatalf("expected signature to be stripped from thinking block, got %s", content.Get("0").Raw)
+	}
+}
@@ -0,0 +1,80 @@
+package executor
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// prepareDocuments replaces Claude document blocks in payload with locally extracted text when the
+// auth opts into document text extraction. Providers without native file support otherwise drop
+// or reject the attachments.
+func prepareDocuments(auth *cliproxyauth.Auth, from sdktranslator.Format, payload []byte) []byte {
+	if from.String() != "claude" || auth == nil || auth.Attributes == nil {
+		return payload
+	}
+	if !strings.EqualFold(strings.TrimSpace(auth.Attributes["document_text_extraction"]), "true") {
+		return payload
+	}
+	return extractClaudeDocumentText(payload)
+}
+
+// extractClaudeDocumentText replaces every document block of a Claude request with a text block
+// holding its content, prefixed by a notice that the text was extracted locally.
+func extractClaudeDocumentText(payload []byte) []byte {
+	out := payload
+	gjson.GetBytes(payload, "messages").ForEach(func(mi, message gjson.Result) bool {
+		message.Get("content").ForEach(func(ci, block gjson.Result) bool {
+			if block.Get("type").String() != "document" {
+				return true
+			}
+			path := fmt.Sprintf("messages.%d.content.%d", mi.Int(), ci.Int())
+			updated, err := sjson.SetBytes(out, path, map[string]string{"type": "text", "text": documentText(block)})
+			if err != nil {
+				log.Warnf("document text extraction: failed to replace %s: %v", path, err)
+				return true
+			}
+			out = updated
+			return true
+		})
+		return true
+	})
+	return out
+}
+
+// documentText renders a Claude document block as text for the model.
+func documentText(block gjson.Result) string {
+	name := "document"
+	if title := strings.TrimSpace(block.Get("title").String()); title != "" {
+		name = fmt.Sprintf("document %q", title)
+	}
+	source := block.Get("source")
+	switch source.Get("type").String() {
+	case "text":
+		return fmt.Sprintf("[Attached %s]\n%s", name, source.Get("data").String())
+	case "base64":
+		if mediaType := source.Get("media_type").String(); mediaType != "" && mediaType != "application/pdf" {
+			return fmt.Sprintf("[Attached %s could not be read: unsupported media type %s]", name, mediaType)
+		}
+		raw, err := base64.StdEncoding.DecodeString(source.Get("data").String())
+		if err != nil {
+			return fmt.Sprintf("[Attached %s could not be read: invalid base64 data]", name)
+		}
+		text, err := util.ExtractPDFText(raw)
+		if err != nil {
+			log.Debugf("document text extraction: %s: %v", name, err)
+			return fmt.Sprintf("[Attached %s could not be read: %v]", name, err)
+		}
+		log.Debugf("document text extraction: %s: %d bytes of PDF -> %d bytes of text", name, len(raw), len(text))
+		return fmt.Sprintf("[Attached %s: the following text was extracted from the PDF by the proxy; layout, images and tables may be lost]\n%s", name, text)
+	default:
+		return fmt.Sprintf("[Attached %s could not be read: unsupported source type %s]", name, source.Get("type").String())
+	}
+}
@@ -0,0 +1,98 @@
+package executor
+
+import (
+	"hash/fnv"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// fingerprintProfileKey names the auth attribute or metadata field selecting a fingerprint profile.
+const fingerprintProfileKey = "fingerprint_profile"
+
+// fingerprintProfile returns the profile assigned to auth: the one it names, or with auto
+// assignment the one picked by a hash of its ID. ok is false when no profile applies.
+func fingerprintProfile(cfg *config.Config, auth *cliproxyauth.Auth) (profile config.FingerprintProfile, ok bool) {
+	if cfg == nil || auth == nil || len(cfg.Fingerprints.Profiles) == 0 {
+		return profile, false
+	}
+	profiles := cfg.Fingerprints.Profiles
+	name := strings.TrimSpace(auth.Attributes[fingerprintProfileKey])
+	if name == "" && auth.Metadata != nil {
+		name, _ = auth.Metadata[fingerprintProfileKey].(string)
+		name = strings.TrimSpace(name)
+	}
+	if name != "" {
+		for _, candidate := range profiles {
+			if strings.EqualFold(strings.TrimSpace(candidate.Name), name) {
+				return candidate, true
+			}
+		}
+		return profile, false
+	}
+	if !cfg.Fingerprints.Auto || auth.ID == "" {
+		return profile, false
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(auth.ID))
+	return profiles[h.Sum32()%uint32(len(profiles))], true
+}
+
+// fingerprintHeaders returns the headers auth presents upstream. Headers the auth sets explicitly
+// through header: attributes are left out so they keep precedence.
+func fingerprintHeaders(cfg *config.Config, auth *cliproxyauth.Auth) http.Header {
+	profile, ok := fingerprintProfile(cfg, auth)
+	if !ok {
+		return nil
+	}
+	// Header overrides of the auth win; their names are matched case-insensitively.
+	custom := make(map[string]struct{})
+	for key := range auth.Attributes {
+		if name, ok := strings.CutPrefix(key, "header:"); ok {
+			custom[http.CanonicalHeaderKey(strings.TrimSpace(name))] = struct{}{}
+		}
+	}
+	headers := make(http.Header)
+	set := func(name, value string) {
+		if value = strings.TrimSpace(value); value == "" {
+			return
+		}
+		if _, overridden := custom[http.CanonicalHeaderKey(name)]; overridden {
+			return
+		}
+		headers.Set(name, value)
+	}
+	for name, value := range profile.Headers {
+		set(strings.TrimSpace(name), value)
+	}
+	set("User-Agent", profile.UserAgent)
+	set("Sec-Ch-Ua", profile.SecChUa)
+	set("Sec-Ch-Ua-Mobile", profile.SecChUaMobile)
+	set("Sec-Ch-Ua-Platform", profile.SecChUaPlatform)
+	set("Accept-Language", profile.AcceptLanguage)
+	if len(headers) == 0 {
+		return nil
+	}
+	return headers
+}
+
+// fingerprintTransport stamps a credential's fingerprint headers on every request, after the
+// executor has set its defaults.
+type fingerprintTransport struct {
+	base    http.RoundTripper
+	headers http.Header
+}
+
+func (t fingerprintTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	req = req.Clone(req.Context())
+	for name, values := range t.headers {
+		req.Header[name] = append([]string(nil), values...)
+	}
+	return base.RoundTrip(req)
+}
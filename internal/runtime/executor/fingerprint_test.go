@@ -0,0 +1,86 @@
+package executor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestFingerprintProfileAssignment(t *testing.T) {
+	cfg := &config.Config{Fingerprints: config.FingerprintConfig{Profiles: []config.FingerprintProfile{
+		{Name: "chrome", UserAgent: "chrome-ua"},
+		{Name: "edge", UserAgent: "edge-ua"},
+	}}}
+
+	named := &cliproxyauth.Auth{ID: "a", Metadata: map[string]any{"fingerprint_profile": "Edge"}}
+	if profile, ok := fingerprintProfile(cfg, named); !ok || profile.Name != "edge" {
+		t.Fatalf("named profile not selected: %+v %v", profile, ok)
+	}
+	unnamed := &cliproxyauth.Auth{ID: "b"}
+	if _, ok := fingerprintProfile(cfg, unnamed); ok {
+		t.Fatal("without auto, auths that name no profile must keep their headers")
+	}
+
+	cfg.Fingerprints.Auto = true
+	first, ok := fingerprintProfile(cfg, unnamed)
+	if !ok {
+		t.Fatal("auto must assign a profile")
+	}
+	for i := 0; i < 5; i++ {
+		if again, _ := fingerprintProfile(cfg, unnamed); again.Name != first.Name {
+			t.Fatalf("assignment must be stable, got %s then %s", first.Name, again.Name)
+		}
+	}
+	unknown := &cliproxyauth.Auth{ID: "c", Attributes: map[string]string{"fingerprint_profile": "missing"}}
+	if _, ok = fingerprintProfile(cfg, unknown); ok {
+		t.Fatal("an unknown profile name must not fall back to auto assignment")
+	}
+}
+
+func TestFingerprintHeadersAppliedByClient(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{Fingerprints: config.FingerprintConfig{Profiles: []config.FingerprintProfile{{
+		Name:            "chrome",
+		UserAgent:       "chrome-ua",
+		SecChUa:         `"Chromium";v="131"`,
+		SecChUaPlatform: `"macOS"`,
+		AcceptLanguage:  "en-US",
+		Headers:         map[string]string{"X-Extra": "1"},
+	}}}}
+	auth := &cliproxyauth.Auth{
+		ID:         "a",
+		Attributes: map[string]string{"fingerprint_profile": "chrome", "header:accept-language": "de-DE"},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("User-Agent", "executor-default")
+	req.Header.Set("Accept-Language", "de-DE")
+	resp, err := newProxyAwareHTTPClient(context.Background(), cfg, auth, 0).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if got.Get("User-Agent") != "chrome-ua" || got.Get("Sec-Ch-Ua") != `"Chromium";v="131"` ||
+		got.Get("Sec-Ch-Ua-Platform") != `"macOS"` || got.Get("X-Extra") != "1" {
+		t.Fatalf("profile headers not applied: %v", got)
+	}
+	if got.Get("Accept-Language") != "de-DE" {
+		t.Fatalf("custom header attributes must win over the profile in any case, got %q", got.Get("Accept-Language"))
+	}
+	if req.Header.Get("User-Agent") != "executor-default" {
+		t.Fatal("the caller's request must not be modified")
+	}
+}
@@ -7,23 +7,30 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	copilotauth "github.com/router-for-me/CLIProxyAPI/v6/internal/auth/copilot"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
 	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
 	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
+	"golang.org/x/sync/singleflight"
 )
 
 const (
 	githubCopilotBaseURL       = "https://api.githubcopilot.com"
 	githubCopilotChatPath      = "/chat/completions"
+	githubCopilotResponsesPath = "/responses"
+	githubCopilotEmbedPath     = "/embeddings"
 	githubCopilotAuthType      = "github-copilot"
 	githubCopilotTokenCacheTTL = 25 * time.Minute
 	// tokenExpiryBuffer is the time before expiry when we should refresh the token.
@@ -37,6 +44,9 @@ const (
 	copilotPluginVersion = "copilot/1.300.0"
 	copilotIntegrationID = "vscode-chat"
 	copilotOpenAIIntent  = "conversation-panel"
+
+	// copilotOrganizationHeader selects the organization whose Copilot seat serves a request.
+	copilotOrganizationHeader = "Copilot-Organization"
 )
 
 // GitHubCopilotExecutor handles requests to the GitHub Copilot API.
@@ -44,12 +54,18 @@ type GitHubCopilotExecutor struct {
 	cfg   *config.Config
 	mu    sync.RWMutex
 	cache map[string]*cachedAPIToken
+	// exchanges collapses concurrent Copilot token exchanges of one auth into a single request.
+	exchanges singleflight.Group
 }
 
-// cachedAPIToken stores a cached Copilot API token with its expiry.
+// cachedAPIToken stores the Copilot API token of an auth with its expiry.
 type cachedAPIToken struct {
 	token     string
 	expiresAt time.Time
+	// githubToken is the GitHub access token the API token was exchanged from.
+	githubToken string
+	// apiEndpoint is the Copilot API base URL returned with the token.
+	apiEndpoint string
 }
 
 // NewGitHubCopilotExecutor constructs a new executor instance.
@@ -84,7 +100,7 @@ func (e *GitHubCopilotExecutor) HttpRequest(ctx context.Context, auth *cliproxya
 	if err != nil {
 		return nil, err
 	}
-	e.applyHeaders(httpReq, apiToken)
+	e.applyHeaders(httpReq, auth, apiToken)
 	if auth != nil {
 		util.ApplyCustomHeadersFromAttrs(httpReq, auth.Attributes)
 	}
@@ -98,12 +114,18 @@ func (e *GitHubCopilotExecutor) Execute(ctx context.Context, auth *cliproxyauth.
 	if errToken != nil {
 		return resp, errToken
 	}
+	if opts.IsEmbeddings() {
+		return e.executeEmbeddings(ctx, auth, apiToken, req)
+	}
 
 	reporter := newUsageReporter(ctx, e.Identifier(), req.Model, auth)
 	defer reporter.trackFailure(ctx, &err)
 
 	from := opts.SourceFormat
-	to := sdktranslator.FromString("openai")
+	to, path, err := copilotUpstream(auth, req.Model, from, req.Payload)
+	if err != nil {
+		return resp, err
+	}
 	originalPayload := bytes.Clone(req.Payload)
 	if len(opts.OriginalRequest) > 0 {
 		originalPayload = bytes.Clone(opts.OriginalRequest)
@@ -112,14 +134,23 @@ func (e *GitHubCopilotExecutor) Execute(ctx context.Context, auth *cliproxyauth.
 	body := sdktranslator.TranslateRequest(from, to, req.Model, bytes.Clone(req.Payload), false)
 	body = e.normalizeModel(req.Model, body)
 	body = applyPayloadConfigWithRoot(e.cfg, req.Model, to.String(), "", body, originalTranslated)
-	body, _ = sjson.SetBytes(body, "stream", false)
+	body, vision, err := prepareCopilotImages(auth, req.Model, body)
+	if err != nil {
+		return resp, err
+	}
+	if path == githubCopilotChatPath {
+		body, _ = sjson.SetBytes(body, "stream", false)
+	}
 
-	url := githubCopilotBaseURL + githubCopilotChatPath
+	url := e.apiURL(auth, path)
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
 		return resp, err
 	}
-	e.applyHeaders(httpReq, apiToken)
+	e.applyHeaders(httpReq, auth, apiToken)
+	if vision {
+		httpReq.Header.Set(copilotVisionHeader, "true")
+	}
 
 	var authID, authLabel, authType, authValue string
 	if auth != nil {
@@ -157,7 +188,10 @@ func (e *GitHubCopilotExecutor) Execute(ctx context.Context, auth *cliproxyauth.
 		data, _ := io.ReadAll(httpResp.Body)
 		appendAPIResponseChunk(ctx, e.cfg, data)
 		log.Debugf("github-copilot executor: upstream error status: %d, body: %s", httpResp.StatusCode, summarizeErrorBody(httpResp.Header.Get("Content-Type"), data))
-		err = statusErr{code: httpResp.StatusCode, msg: string(data)}
+		if httpResp.StatusCode == http.StatusUnauthorized {
+			e.invalidateAPIToken(auth)
+		}
+		err = newHTTPStatusErr(httpResp, data)
 		return resp, err
 	}
 
@@ -168,6 +202,29 @@ func (e *GitHubCopilotExecutor) Execute(ctx context.Context, auth *cliproxyauth.
 	}
 	appendAPIResponseChunk(ctx, e.cfg, data)
 
+	if path == githubCopilotResponsesPath {
+		// Responses requests are always streamed; the response is carried by response.completed.
+		for _, line := range bytes.Split(data, []byte("\n")) {
+			if !bytes.HasPrefix(line, dataTag) {
+				continue
+			}
+			line = bytes.TrimSpace(line[5:])
+			if gjson.GetBytes(line, "type").String() != "response.completed" {
+				continue
+			}
+			if detail, ok := parseCodexUsage(line); ok {
+				reporter.publish(ctx, detail)
+			}
+			var param any
+			converted := sdktranslator.TranslateNonStream(ctx, to, from, req.Model, bytes.Clone(opts.OriginalRequest), body, line, &param)
+			resp = cliproxyexecutor.Response{Payload: []byte(converted)}
+			reporter.ensurePublished(ctx)
+			return resp, nil
+		}
+		err = statusErr{code: http.StatusBadGateway, msg: "github-copilot: stream closed before response.completed"}
+		return resp, err
+	}
+
 	detail := parseOpenAIUsage(data)
 	if detail.TotalTokens > 0 {
 		reporter.publish(ctx, detail)
@@ -180,6 +237,72 @@ func (e *GitHubCopilotExecutor) Execute(ctx context.Context, auth *cliproxyauth.
 	return resp, nil
 }
 
+// executeEmbeddings proxies an OpenAI-format embeddings request. The Copilot API speaks the
+// same format, so only the model is rewritten to the mapped upstream name.
+func (e *GitHubCopilotExecutor) executeEmbeddings(ctx context.Context, auth *cliproxyauth.Auth, apiToken string, req cliproxyexecutor.Request) (resp cliproxyexecutor.Response, err error) {
+	reporter := newUsageReporter(ctx, e.Identifier(), req.Model, auth)
+	defer reporter.trackFailure(ctx, &err)
+
+	body, _ := sjson.SetBytes(bytes.Clone(req.Payload), "model", req.Model)
+	url := e.apiURL(auth, githubCopilotEmbedPath)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return resp, err
+	}
+	e.applyHeaders(httpReq, auth, apiToken)
+
+	var authID, authLabel, authType, authValue string
+	if auth != nil {
+		authID = auth.ID
+		authLabel = auth.Label
+		authType, authValue = auth.AccountInfo()
+	}
+	recordAPIRequest(ctx, e.cfg, upstreamRequestLog{
+		URL:       url,
+		Method:    http.MethodPost,
+		Headers:   httpReq.Header.Clone(),
+		Body:      body,
+		Provider:  e.Identifier(),
+		AuthID:    authID,
+		AuthLabel: authLabel,
+		AuthType:  authType,
+		AuthValue: authValue,
+	})
+
+	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		recordAPIResponseError(ctx, e.cfg, err)
+		return resp, err
+	}
+	defer func() {
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("github-copilot executor: close response body error: %v", errClose)
+		}
+	}()
+
+	recordAPIResponseMetadata(ctx, e.cfg, httpResp.StatusCode, httpResp.Header.Clone())
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		recordAPIResponseError(ctx, e.cfg, err)
+		return resp, err
+	}
+	appendAPIResponseChunk(ctx, e.cfg, data)
+	if !isHTTPSuccess(httpResp.StatusCode) {
+		log.Debugf("github-copilot executor: upstream embeddings error status: %d, body: %s", httpResp.StatusCode, summarizeErrorBody(httpResp.Header.Get("Content-Type"), data))
+		if httpResp.StatusCode == http.StatusUnauthorized {
+			e.invalidateAPIToken(auth)
+		}
+		err = newHTTPStatusErr(httpResp, data)
+		return resp, err
+	}
+
+	reporter.publish(ctx, parseOpenAIUsage(data))
+	reporter.ensurePublished(ctx)
+	return cliproxyexecutor.Response{Payload: data}, nil
+}
+
 // ExecuteStream handles streaming requests to GitHub Copilot.
 func (e *GitHubCopilotExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (stream <-chan cliproxyexecutor.StreamChunk, err error) {
 	apiToken, errToken := e.ensureAPIToken(ctx, auth)
@@ -191,7 +314,10 @@ func (e *GitHubCopilotExecutor) ExecuteStream(ctx context.Context, auth *cliprox
 	defer reporter.trackFailure(ctx, &err)
 
 	from := opts.SourceFormat
-	to := sdktranslator.FromString("openai")
+	to, path, err := copilotUpstream(auth, req.Model, from, req.Payload)
+	if err != nil {
+		return nil, err
+	}
 	originalPayload := bytes.Clone(req.Payload)
 	if len(opts.OriginalRequest) > 0 {
 		originalPayload = bytes.Clone(opts.OriginalRequest)
@@ -200,16 +326,25 @@ func (e *GitHubCopilotExecutor) ExecuteStream(ctx context.Context, auth *cliprox
 	body := sdktranslator.TranslateRequest(from, to, req.Model, bytes.Clone(req.Payload), true)
 	body = e.normalizeModel(req.Model, body)
 	body = applyPayloadConfigWithRoot(e.cfg, req.Model, to.String(), "", body, originalTranslated)
-	body, _ = sjson.SetBytes(body, "stream", true)
-	// Enable stream options for usage stats in stream
-	body, _ = sjson.SetBytes(body, "stream_options.include_usage", true)
+	body, vision, err := prepareCopilotImages(auth, req.Model, body)
+	if err != nil {
+		return nil, err
+	}
+	if path == githubCopilotChatPath {
+		body, _ = sjson.SetBytes(body, "stream", true)
+		// Enable stream options for usage stats in stream
+		body, _ = sjson.SetBytes(body, "stream_options.include_usage", true)
+	}
 
-	url := githubCopilotBaseURL + githubCopilotChatPath
+	url := e.apiURL(auth, path)
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
-	e.applyHeaders(httpReq, apiToken)
+	e.applyHeaders(httpReq, auth, apiToken)
+	if vision {
+		httpReq.Header.Set(copilotVisionHeader, "true")
+	}
 
 	var authID, authLabel, authType, authValue string
 	if auth != nil {
@@ -249,7 +384,10 @@ func (e *GitHubCopilotExecutor) ExecuteStream(ctx context.Context, auth *cliprox
 		}
 		appendAPIResponseChunk(ctx, e.cfg, data)
 		log.Debugf("github-copilot executor: upstream error status: %d, body: %s", httpResp.StatusCode, summarizeErrorBody(httpResp.Header.Get("Content-Type"), data))
-		err = statusErr{code: httpResp.StatusCode, msg: string(data)}
+		if httpResp.StatusCode == http.StatusUnauthorized {
+			e.invalidateAPIToken(auth)
+		}
+		err = newHTTPStatusErr(httpResp, data)
 		return nil, err
 	}
 
@@ -278,7 +416,13 @@ func (e *GitHubCopilotExecutor) ExecuteStream(ctx context.Context, auth *cliprox
 				if bytes.Equal(data, []byte("[DONE]")) {
 					continue
 				}
-				if detail, ok := parseOpenAIStreamUsage(line); ok {
+				if path == githubCopilotResponsesPath {
+					if gjson.GetBytes(data, "type").String() == "response.completed" {
+						if detail, ok := parseCodexUsage(data); ok {
+							reporter.publish(ctx, detail)
+						}
+					}
+				} else if detail, ok := parseOpenAIStreamUsage(line); ok {
 					reporter.publish(ctx, detail)
 				}
 			}
@@ -301,13 +445,38 @@ func (e *GitHubCopilotExecutor) ExecuteStream(ctx context.Context, auth *cliprox
 	return stream, nil
 }
 
+// copilotUpstream returns the format and API path a request in format from is sent with.
+// Responses API requests go to the native responses endpoint when the model catalog lists it for
+// the model, so their events, reasoning items and tool calls reach the client unchanged; every
+// other request goes through chat completions. Copilot does not store responses, so a Responses
+// request chaining on previous_response_id fails with 400 instead of silently losing its context.
+func copilotUpstream(auth *cliproxyauth.Auth, model string, from sdktranslator.Format, payload []byte) (sdktranslator.Format, string, error) {
+	if from != sdktranslator.FormatOpenAIResponse {
+		return sdktranslator.FromString("openai"), githubCopilotChatPath, nil
+	}
+	if gjson.GetBytes(payload, "previous_response_id").String() != "" {
+		return "", "", statusErr{code: http.StatusBadRequest, msg: "github-copilot: previous_response_id is not supported; send the full conversation in input"}
+	}
+	authID := ""
+	if auth != nil {
+		authID = auth.ID
+	}
+	for _, path := range copilotModelEndpoints(authID, model) {
+		if path == githubCopilotResponsesPath {
+			return sdktranslator.FromString(constant.GitHubCopilotResponses), githubCopilotResponsesPath, nil
+		}
+	}
+	return sdktranslator.FromString("openai"), githubCopilotChatPath, nil
+}
+
 // CountTokens is not supported for GitHub Copilot.
 func (e *GitHubCopilotExecutor) CountTokens(_ context.Context, _ *cliproxyauth.Auth, _ cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
 	return cliproxyexecutor.Response{}, statusErr{code: http.StatusNotImplemented, msg: "count tokens not supported for github-copilot"}
 }
 
 // Refresh validates the GitHub token is still working.
-// GitHub OAuth tokens don't expire traditionally, so we just validate.
+// GitHub OAuth tokens don't expire traditionally, so we just validate. The Copilot API token
+// obtained on the way is cached for the next request.
 func (e *GitHubCopilotExecutor) Refresh(ctx context.Context, auth *cliproxyauth.Auth) (*cliproxyauth.Auth, error) {
 	if auth == nil {
 		return nil, statusErr{code: http.StatusUnauthorized, msg: "missing auth"}
@@ -320,16 +489,16 @@ func (e *GitHubCopilotExecutor) Refresh(ctx context.Context, auth *cliproxyauth.
 	}
 
 	// Validate the token can still get a Copilot API token
-	copilotAuth := copilotauth.NewCopilotAuth(e.cfg)
-	_, err := copilotAuth.GetCopilotAPIToken(ctx, accessToken)
-	if err != nil {
+	e.invalidateAPIToken(auth)
+	if _, err := e.exchangeAPIToken(ctx, auth, accessToken); err != nil {
 		return nil, statusErr{code: http.StatusUnauthorized, msg: fmt.Sprintf("github-copilot token validation failed: %v", err)}
 	}
 
 	return auth, nil
 }
 
-// ensureAPIToken gets or refreshes the Copilot API token.
+// ensureAPIToken returns the cached Copilot API token of auth, exchanging the GitHub token for a
+// new one when none is cached or the cached one expires within tokenExpiryBuffer.
 func (e *GitHubCopilotExecutor) ensureAPIToken(ctx context.Context, auth *cliproxyauth.Auth) (string, error) {
 	if auth == nil {
 		return "", statusErr{code: http.StatusUnauthorized, msg: "missing auth"}
@@ -341,38 +510,117 @@ func (e *GitHubCopilotExecutor) ensureAPIToken(ctx context.Context, auth *clipro
 		return "", statusErr{code: http.StatusUnauthorized, msg: "missing github access token"}
 	}
 
-	// Check for cached API token using thread-safe access
-	e.mu.RLock()
-	if cached, ok := e.cache[accessToken]; ok && cached.expiresAt.After(time.Now().Add(tokenExpiryBuffer)) {
-		e.mu.RUnlock()
+	if cached := e.cachedAPIToken(auth, accessToken); cached != nil {
 		return cached.token, nil
 	}
-	e.mu.RUnlock()
 
-	// Get a new Copilot API token
-	copilotAuth := copilotauth.NewCopilotAuth(e.cfg)
-	apiToken, err := copilotAuth.GetCopilotAPIToken(ctx, accessToken)
+	cached, err := e.exchangeAPIToken(ctx, auth, accessToken)
 	if err != nil {
 		return "", statusErr{code: http.StatusUnauthorized, msg: fmt.Sprintf("failed to get copilot api token: %v", err)}
 	}
+	return cached.token, nil
+}
 
-	// Cache the token with thread-safe access
-	expiresAt := time.Now().Add(githubCopilotTokenCacheTTL)
-	if apiToken.ExpiresAt > 0 {
-		expiresAt = time.Unix(apiToken.ExpiresAt, 0)
+// cachedAPIToken returns the cached API token of auth when it was exchanged from accessToken and
+// stays valid beyond tokenExpiryBuffer.
+func (e *GitHubCopilotExecutor) cachedAPIToken(auth *cliproxyauth.Auth, accessToken string) *cachedAPIToken {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	cached, ok := e.cache[auth.ID]
+	if !ok || cached.githubToken != accessToken || !cached.expiresAt.After(time.Now().Add(tokenExpiryBuffer)) {
+		return nil
 	}
-	e.mu.Lock()
-	e.cache[accessToken] = &cachedAPIToken{
-		token:     apiToken.Token,
-		expiresAt: expiresAt,
+	return cached
+}
+
+// exchangeAPIToken exchanges accessToken for a Copilot API token and caches it for auth.
+// Concurrent callers for the same auth share one exchange, so an expiring token does not
+// trigger a burst of exchanges.
+func (e *GitHubCopilotExecutor) exchangeAPIToken(ctx context.Context, auth *cliproxyauth.Auth, accessToken string) (*cachedAPIToken, error) {
+	result, err, _ := e.exchanges.Do(auth.ID, func() (any, error) {
+		// A concurrent exchange may have finished while this caller was waiting for its turn.
+		if cached := e.cachedAPIToken(auth, accessToken); cached != nil {
+			return cached, nil
+		}
+		copilotAuth := copilotauth.NewCopilotAuthWithBaseURL(e.cfg, metaStringValue(auth.Metadata, "github_base_url"))
+		// The exchange is shared, so one caller going away must not fail it for the others.
+		apiToken, errExchange := copilotAuth.GetCopilotAPIToken(context.WithoutCancel(ctx), accessToken)
+		if errExchange != nil {
+			return nil, errExchange
+		}
+		if snap := usage.ParseCopilotQuotaSnapshot(apiToken.Raw, apiToken.Headers); snap != nil {
+			usage.UpdateCopilotQuotaSnapshot(auth.ID, snap)
+		}
+		expiresAt := time.Now().Add(githubCopilotTokenCacheTTL)
+		if apiToken.ExpiresAt > 0 {
+			expiresAt = time.Unix(apiToken.ExpiresAt, 0)
+		}
+		cached := &cachedAPIToken{
+			token:       apiToken.Token,
+			expiresAt:   expiresAt,
+			githubToken: accessToken,
+			apiEndpoint: apiToken.Endpoints.API,
+		}
+		e.mu.Lock()
+		e.cache[auth.ID] = cached
+		e.mu.Unlock()
+		return cached, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*cachedAPIToken), nil
+}
+
+// FetchCopilotQuota performs a Copilot token exchange to read the account's entitlements (plan,
+// limited-plan quotas, token expiry) and GitHub rate-limit headers for observability.
+func FetchCopilotQuota(ctx context.Context, auth *cliproxyauth.Auth, cfg *config.Config) (*usage.CopilotQuotaSnapshot, error) {
+	if auth == nil {
+		return nil, fmt.Errorf("copilot quota: missing auth")
+	}
+	accessToken := metaStringValue(auth.Metadata, "access_token")
+	if accessToken == "" {
+		return nil, fmt.Errorf("copilot quota: missing access token")
 	}
+	copilotAuth := copilotauth.NewCopilotAuthWithBaseURL(cfg, metaStringValue(auth.Metadata, "github_base_url"))
+	apiToken, err := copilotAuth.GetCopilotAPIToken(ctx, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("copilot quota: %w", err)
+	}
+	if snap := usage.ParseCopilotQuotaSnapshot(apiToken.Raw, apiToken.Headers); snap != nil {
+		return snap, nil
+	}
+	return nil, fmt.Errorf("copilot quota: no entitlement data present")
+}
+
+// invalidateAPIToken drops the cached API token of auth, e.g. after the Copilot API rejected it.
+func (e *GitHubCopilotExecutor) invalidateAPIToken(auth *cliproxyauth.Auth) {
+	if auth == nil {
+		return
+	}
+	e.mu.Lock()
+	delete(e.cache, auth.ID)
 	e.mu.Unlock()
+}
 
-	return apiToken.Token, nil
+// apiURL returns the URL of path on the Copilot API for auth. GitHub Enterprise accounts use the
+// API endpoint their instance returned with the Copilot token; github.com accounts use the public
+// API.
+func (e *GitHubCopilotExecutor) apiURL(auth *cliproxyauth.Auth, path string) string {
+	base := githubCopilotBaseURL
+	if auth != nil && metaStringValue(auth.Metadata, "github_base_url") != "" {
+		e.mu.RLock()
+		if cached, ok := e.cache[auth.ID]; ok && cached.apiEndpoint != "" {
+			base = strings.TrimRight(cached.apiEndpoint, "/")
+		}
+		e.mu.RUnlock()
+	}
+	return base + path
 }
 
-// applyHeaders sets the required headers for GitHub Copilot API requests.
-func (e *GitHubCopilotExecutor) applyHeaders(r *http.Request, apiToken string) {
+// applyHeaders sets the required headers for GitHub Copilot API requests. Requests of a login
+// bound to an organization carry it so the organization's Copilot seat and policies apply.
+func (e *GitHubCopilotExecutor) applyHeaders(r *http.Request, auth *cliproxyauth.Auth, apiToken string) {
 	r.Header.Set("Content-Type", "application/json")
 	r.Header.Set("Authorization", "Bearer "+apiToken)
 	r.Header.Set("Accept", "application/json")
@@ -382,6 +630,11 @@ func (e *GitHubCopilotExecutor) applyHeaders(r *http.Request, apiToken string) {
 	r.Header.Set("Openai-Intent", copilotOpenAIIntent)
 	r.Header.Set("Copilot-Integration-Id", copilotIntegrationID)
 	r.Header.Set("X-Request-Id", uuid.NewString())
+	if auth != nil {
+		if org := metaStringValue(auth.Metadata, "organization"); org != "" {
+			r.Header.Set(copilotOrganizationHeader, org)
+		}
+	}
 }
 
 // normalizeModel is a no-op as GitHub Copilot accepts model names directly.
@@ -0,0 +1,350 @@
+package executor
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/github-copilot/openai/responses"
+	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/openai/openai/responses"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+func TestGitHubCopilotAPITokenCache(t *testing.T) {
+	var exchanges atomic.Int32
+	var expiresIn atomic.Int64
+	expiresIn.Store(int64(time.Hour / time.Second))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := exchanges.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"token":"copilot-%d","expires_at":%d}`, n, time.Now().Unix()+expiresIn.Load())
+	}))
+	defer server.Close()
+
+	e := NewGitHubCopilotExecutor(&config.Config{})
+	auth := &cliproxyauth.Auth{ID: "copilot-1", Provider: "github-copilot", Metadata: map[string]any{
+		"access_token":    "gho_token",
+		"github_base_url": server.URL,
+	}}
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 10)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], _ = e.ensureAPIToken(context.Background(), auth)
+		}(i)
+	}
+	wg.Wait()
+	if got := exchanges.Load(); got != 1 {
+		t.Fatalf("concurrent requests made %d exchanges, want 1", got)
+	}
+	for _, token := range tokens {
+		if token != "copilot-1" {
+			t.Fatalf("tokens = %v, want all copilot-1", tokens)
+		}
+	}
+	if token, _ := e.ensureAPIToken(context.Background(), auth); token != "copilot-1" || exchanges.Load() != 1 {
+		t.Fatalf("a valid cached token must be reused, got %s after %d exchanges", token, exchanges.Load())
+	}
+
+	e.invalidateAPIToken(auth)
+	expiresIn.Store(60)
+	if token, _ := e.ensureAPIToken(context.Background(), auth); token != "copilot-2" {
+		t.Fatalf("an invalidated token must be exchanged again, got %s", token)
+	}
+	if token, _ := e.ensureAPIToken(context.Background(), auth); token != "copilot-3" {
+		t.Fatalf("a token expiring within the buffer must be exchanged again, got %s", token)
+	}
+
+	expiresIn.Store(int64(time.Hour / time.Second))
+	rotated := auth.Clone()
+	rotated.Metadata = map[string]any{"access_token": "gho_rotated", "github_base_url": server.URL}
+	if token, _ := e.ensureAPIToken(context.Background(), rotated); token != "copilot-4" {
+		t.Fatalf("a new GitHub token must not reuse the old API token, got %s", token)
+	}
+}
+
+func TestGitHubCopilotEmbeddings(t *testing.T) {
+	var upstreamModel, upstreamAuth, upstreamOrg string
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/copilot_internal/v2/token":
+			_, _ = fmt.Fprintf(w, `{"token":"copilot-token","expires_at":%d,"endpoints":{"api":%q}}`, time.Now().Add(time.Hour).Unix(), server.URL)
+		case "/embeddings":
+			body, _ := io.ReadAll(r.Body)
+			upstreamModel = gjson.GetBytes(body, "model").String()
+			upstreamAuth = r.Header.Get("Authorization")
+			upstreamOrg = r.Header.Get(copilotOrganizationHeader)
+			_, _ = w.Write([]byte(`{"object":"list","data":[{"object":"embedding","index":0,"embedding":[0.1,0.2]}],"model":"text-embedding-3-small","usage":{"prompt_tokens":4,"total_tokens":4}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	e := NewGitHubCopilotExecutor(&config.Config{})
+	auth := &cliproxyauth.Auth{ID: "copilot-1", Provider: "github-copilot", Metadata: map[string]any{
+		"access_token":    "gho_token",
+		"github_base_url": server.URL,
+		"organization":    "acme",
+	}}
+	req := cliproxyexecutor.Request{Model: "text-embedding-3-small", Payload: []byte(`{"model":"alias","input":"hello"}`)}
+	opts := cliproxyexecutor.Options{Metadata: map[string]any{cliproxyexecutor.OperationMetadataKey: cliproxyexecutor.OperationEmbeddings}}
+
+	resp, err := e.Execute(context.Background(), auth, req, opts)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if upstreamModel != "text-embedding-3-small" || upstreamAuth != "Bearer copilot-token" || upstreamOrg != "acme" {
+		t.Fatalf("upstream got model %q auth %q organization %q", upstreamModel, upstreamAuth, upstreamOrg)
+	}
+	if got := gjson.GetBytes(resp.Payload, "data.0.embedding.1").Float(); got != 0.2 {
+		t.Fatalf("embeddings payload must be passed through, got %s", resp.Payload)
+	}
+}
+
+func TestGitHubCopilotVisionRequest(t *testing.T) {
+	var upstreamVision, upstreamImage string
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/copilot_internal/v2/token":
+			_, _ = fmt.Fprintf(w, `{"token":"copilot-token","expires_at":%d,"endpoints":{"api":%q}}`, time.Now().Add(time.Hour).Unix(), server.URL)
+		case "/chat/completions":
+			body, _ := io.ReadAll(r.Body)
+			upstreamVision = r.Header.Get(copilotVisionHeader)
+			upstreamImage = gjson.GetBytes(body, "messages.0.content.1.image_url.url").String()
+			_, _ = w.Write([]byte(`{"id":"c1","object":"chat.completion","model":"gpt-4.1","choices":[{"index":0,"message":{"role":"assistant","content":"a dot"},"finish_reason":"stop"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	e := NewGitHubCopilotExecutor(&config.Config{})
+	auth := &cliproxyauth.Auth{ID: "copilot-1", Provider: "github-copilot", Metadata: map[string]any{
+		"access_token":    "gho_token",
+		"github_base_url": server.URL,
+	}}
+	opts := cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString("openai")}
+	// A PNG header sent as bare URL-safe base64 without padding.
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\xff\xfe")
+	bare := strings.TrimRight(base64.URLEncoding.EncodeToString(png), "=")
+	payload := fmt.Sprintf(`{"model":"gpt-4.1","messages":[{"role":"user","content":[{"type":"text","text":"what is this?"},{"type":"image_url","image_url":%q}]}]}`, bare)
+
+	if _, err := e.Execute(context.Background(), auth, cliproxyexecutor.Request{Model: "gpt-4.1", Payload: []byte(payload)}, opts); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if want := "data:image/png;base64," + base64.StdEncoding.EncodeToString(png); upstreamImage != want || upstreamVision != "true" {
+		t.Fatalf("upstream got image %q vision header %q, want %q and true", upstreamImage, upstreamVision, want)
+	}
+
+	_, err := e.Execute(context.Background(), auth, cliproxyexecutor.Request{Model: "grok-code-fast-1", Payload: []byte(payload)}, opts)
+	if se, ok := err.(statusErr); !ok || se.StatusCode() != http.StatusBadRequest {
+		t.Fatalf("images for a model without vision support must fail with 400, got %v", err)
+	}
+	oversized := fmt.Sprintf(`{"messages":[{"role":"user","content":[{"type":"image_url","image_url":{"url":"data:image/png;base64,%s"}}]}]}`, base64.StdEncoding.EncodeToString(make([]byte, copilotMaxImageBytes+1)))
+	_, err = e.Execute(context.Background(), auth, cliproxyexecutor.Request{Model: "gpt-4.1", Payload: []byte(oversized)}, opts)
+	if se, ok := err.(statusErr); !ok || se.StatusCode() != http.StatusBadRequest {
+		t.Fatalf("oversized images must fail with 400, got %v", err)
+	}
+}
+
+func TestGitHubCopilotResponsesRequest(t *testing.T) {
+	var upstreamBody []byte
+	var chatCalls atomic.Int32
+	var truncated atomic.Bool
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/copilot_internal/v2/token":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `{"token":"copilot-token","expires_at":%d,"endpoints":{"api":%q}}`, time.Now().Add(time.Hour).Unix(), server.URL)
+		case "/responses":
+			upstreamBody, _ = io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", "text/event-stream")
+			events := "event: response.created\ndata: {\"type\":\"response.created\",\"response\":{\"id\":\"r1\",\"status\":\"in_progress\"}}\n\n" +
+				"event: response.output_text.delta\ndata: {\"type\":\"response.output_text.delta\",\"delta\":\"hi\"}\n\n"
+			if !truncated.Load() {
+				events += "event: response.completed\ndata: {\"type\":\"response.completed\",\"response\":{\"id\":\"r1\",\"status\":\"completed\",\"usage\":{\"input_tokens\":3,\"output_tokens\":1,\"total_tokens\":4}}}\n\n"
+			}
+			_, _ = w.Write([]byte(events))
+		case "/chat/completions":
+			chatCalls.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":"c1","object":"chat.completion","created":1,"model":"gpt-4.1","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	e := NewGitHubCopilotExecutor(&config.Config{})
+	auth := &cliproxyauth.Auth{ID: "copilot-1", Provider: "github-copilot", Metadata: map[string]any{
+		"access_token":    "gho_token",
+		"github_base_url": server.URL,
+	}}
+	copilotModelCatalogsMu.Lock()
+	copilotModelCatalogs[auth.ID] = copilotModelCatalog{
+		endpoints: map[string][]string{"gpt-5": {"/chat/completions", "/responses"}, "gpt-4.1": {"/chat/completions"}},
+		fetchedAt: time.Now(),
+	}
+	copilotModelCatalogsMu.Unlock()
+	defer func() {
+		copilotModelCatalogsMu.Lock()
+		delete(copilotModelCatalogs, auth.ID)
+		copilotModelCatalogsMu.Unlock()
+	}()
+	opts := cliproxyexecutor.Options{SourceFormat: sdktranslator.FormatOpenAIResponse}
+	payload := []byte(`{"model":"gpt-5","input":"hello","stream":false}`)
+
+	resp, err := e.Execute(context.Background(), auth, cliproxyexecutor.Request{Model: "gpt-5", Payload: payload}, opts)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !gjson.GetBytes(upstreamBody, "stream").Bool() || gjson.GetBytes(upstreamBody, "store").Bool() {
+		t.Fatalf("upstream request must stream without storing, got %s", upstreamBody)
+	}
+	if id := gjson.GetBytes(resp.Payload, "id").String(); id != "r1" || gjson.GetBytes(resp.Payload, "status").String() != "completed" {
+		t.Fatalf("Execute returned %s, want the completed response", resp.Payload)
+	}
+
+	stream, err := e.ExecuteStream(context.Background(), auth, cliproxyexecutor.Request{Model: "gpt-5", Payload: payload}, opts)
+	if err != nil {
+		t.Fatalf("ExecuteStream: %v", err)
+	}
+	var events []string
+	for chunk := range stream {
+		if chunk.Err != nil {
+			t.Fatalf("stream error: %v", chunk.Err)
+		}
+		events = append(events, string(chunk.Payload))
+	}
+	if len(events) != 3 || !strings.HasPrefix(events[1], "event: response.output_text.delta\ndata: {") {
+		t.Fatalf("stream events = %q, want one named event per upstream event", events)
+	}
+
+	chained := []byte(`{"model":"gpt-5","input":"hello","previous_response_id":"r0"}`)
+	_, err = e.Execute(context.Background(), auth, cliproxyexecutor.Request{Model: "gpt-5", Payload: chained}, opts)
+	if se, ok := err.(statusErr); !ok || se.StatusCode() != http.StatusBadRequest {
+		t.Fatalf("previous_response_id must fail with 400, got %v", err)
+	}
+
+	truncated.Store(true)
+	_, err = e.Execute(context.Background(), auth, cliproxyexecutor.Request{Model: "gpt-5", Payload: payload}, opts)
+	if se, ok := err.(statusErr); !ok || se.StatusCode() != http.StatusBadGateway {
+		t.Fatalf("a stream closed before response.completed must fail with 502, got %v", err)
+	}
+
+	legacy := []byte(`{"model":"gpt-4.1","input":"hello"}`)
+	if _, err = e.Execute(context.Background(), auth, cliproxyexecutor.Request{Model: "gpt-4.1", Payload: legacy}, opts); err != nil {
+		t.Fatalf("Execute via chat completions: %v", err)
+	}
+	if chatCalls.Load() != 1 {
+		t.Fatalf("a model without the responses endpoint must be served through chat completions")
+	}
+}
+
+func TestGitHubCopilotRefreshModels(t *testing.T) {
+	var listings, exchanges atomic.Int32
+	var failing atomic.Bool
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/copilot_internal/v2/token":
+			exchanges.Add(1)
+			_, _ = fmt.Fprintf(w, `{"token":"copilot-token","expires_at":%d,"endpoints":{"api":%q}}`, time.Now().Add(time.Hour).Unix(), server.URL)
+		case "/models":
+			listings.Add(1)
+			if failing.Load() {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":[
+				{"id":"gpt-4.1","name":"GPT-4.1","version":"gpt-4.1-2025-04-14","capabilities":{"type":"chat","limits":{"max_context_window_tokens":128000,"max_output_tokens":16384},"supports":{"vision":true}},"policy":{"state":"enabled"},"supported_endpoints":["/chat/completions","/responses"]},
+				{"id":"o9-preview","name":"o9 Preview","capabilities":{"type":"chat","supports":{"vision":false}}},
+				{"id":"claude-opus-4.1","name":"Claude Opus 4.1","capabilities":{"type":"chat"},"policy":{"state":"disabled"}},
+				{"id":"text-embedding-3-small","name":"Embedding V3 small","capabilities":{"type":"embeddings","limits":{"max_inputs":512}}}
+			]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	auth := &cliproxyauth.Auth{ID: "copilot-models-1", Provider: "github-copilot", Metadata: map[string]any{
+		"access_token":    "gho_token",
+		"github_base_url": server.URL,
+	}}
+	defer ForgetGitHubCopilotModels(auth.ID)
+
+	copilot := NewGitHubCopilotExecutor(&config.Config{})
+	if err := copilot.RefreshModels(context.Background(), auth); err != nil {
+		t.Fatalf("RefreshModels: %v", err)
+	}
+	models, _ := CachedGitHubCopilotModels(auth.ID)
+	ids := make([]string, 0, len(models))
+	for _, model := range models {
+		ids = append(ids, model.ID)
+	}
+	if got := strings.Join(ids, ","); got != "gpt-4.1,o9-preview,text-embedding-3-small" {
+		t.Fatalf("models = %s, want the enabled chat and embedding models", got)
+	}
+	if models[0].OwnedBy != "github-copilot" || !models[0].SupportsVision || models[0].ContextLength != 128000 {
+		t.Fatalf("unexpected gpt-4.1 entry: %+v", models[0])
+	}
+	if models[2].ContextLength != 8191 || len(models[2].SupportedGenerationMethods) != 1 {
+		t.Fatalf("missing limits must come from the static definition: %+v", models[2])
+	}
+	if supported, known := copilotModelSupportsVision(auth.ID, "o9-preview"); supported || !known {
+		t.Fatalf("vision support must follow the fetched catalog, got supported=%v known=%v", supported, known)
+	}
+
+	if endpoints := copilotModelEndpoints(auth.ID, "GPT-4.1"); len(endpoints) != 2 || endpoints[1] != githubCopilotResponsesPath {
+		t.Fatalf("supported endpoints must follow the fetched catalog, got %v", endpoints)
+	}
+	if endpoints := copilotModelEndpoints(auth.ID, "o9-preview"); endpoints != nil {
+		t.Fatalf("models without supported_endpoints must list none, got %v", endpoints)
+	}
+
+	if cached, fresh := CachedGitHubCopilotModels(auth.ID); !fresh || len(cached) != 3 {
+		t.Fatalf("fetched catalog must be cached as fresh, got %d models fresh=%v", len(cached), fresh)
+	}
+	copilotModelCatalogsMu.Lock()
+	copilotModelCatalogs[auth.ID] = copilotModelCatalog{models: models, fetchedAt: time.Now().Add(-2 * githubCopilotModelsCacheTTL)}
+	copilotModelCatalogsMu.Unlock()
+	failing.Store(true)
+	if err := copilot.RefreshModels(context.Background(), auth); err == nil {
+		t.Fatal("a failed listing must be reported")
+	}
+	if stale, fresh := CachedGitHubCopilotModels(auth.ID); len(stale) != 3 || fresh {
+		t.Fatalf("a failed refresh must keep the previous catalog, got %d models fresh=%v", len(stale), fresh)
+	}
+	if listings.Load() != 2 || exchanges.Load() != 1 {
+		t.Fatalf("refreshes must reuse the executor's API token, got %d listings and %d token exchanges", listings.Load(), exchanges.Load())
+	}
+
+	ForgetGitHubCopilotModels(auth.ID)
+	if cached, _ := CachedGitHubCopilotModels(auth.ID); cached != nil {
+		t.Fatalf("a forgotten catalog must not be served, got %d models", len(cached))
+	}
+}
@@ -0,0 +1,222 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	githubCopilotModelsPath = "/models"
+	// githubCopilotModelsCacheTTL is how long a fetched model catalog is reused before the
+	// Copilot API is asked again.
+	githubCopilotModelsCacheTTL = time.Hour
+)
+
+// copilotModelCatalog is the model catalog fetched for one auth.
+type copilotModelCatalog struct {
+	models []*registry.ModelInfo
+	// endpoints lists the API paths each model accepts, keyed by lower-cased model ID. Models the
+	// response does not list endpoints for are missing.
+	endpoints map[string][]string
+	fetchedAt time.Time
+}
+
+var (
+	copilotModelCatalogsMu sync.RWMutex
+	copilotModelCatalogs   = make(map[string]copilotModelCatalog)
+)
+
+// CachedGitHubCopilotModels returns the catalog last fetched for authID, if any, and whether it
+// is younger than githubCopilotModelsCacheTTL.
+func CachedGitHubCopilotModels(authID string) (models []*registry.ModelInfo, fresh bool) {
+	catalog, ok := cachedCopilotModels(authID)
+	if !ok {
+		return nil, false
+	}
+	return catalog.models, time.Since(catalog.fetchedAt) < githubCopilotModelsCacheTTL
+}
+
+// RefreshModels fetches the model catalog of auth and caches it. Concurrent refreshes of one auth
+// share a request, and the Copilot API token cached by e is reused. On failure the previous
+// catalog is kept.
+func (e *GitHubCopilotExecutor) RefreshModels(ctx context.Context, auth *cliproxyauth.Auth) error {
+	if auth == nil || auth.ID == "" {
+		return fmt.Errorf("github-copilot executor: auth is required")
+	}
+	_, err, _ := e.exchanges.Do("models:"+auth.ID, func() (any, error) {
+		catalog, errFetch := e.fetchModels(ctx, auth)
+		if errFetch != nil {
+			return nil, errFetch
+		}
+		catalog.fetchedAt = time.Now()
+		copilotModelCatalogsMu.Lock()
+		copilotModelCatalogs[auth.ID] = catalog
+		copilotModelCatalogsMu.Unlock()
+		return nil, nil
+	})
+	return err
+}
+
+// ForgetGitHubCopilotModels drops the catalog cached for authID, such as when the auth is removed.
+func ForgetGitHubCopilotModels(authID string) {
+	copilotModelCatalogsMu.Lock()
+	delete(copilotModelCatalogs, authID)
+	copilotModelCatalogsMu.Unlock()
+}
+
+// cachedCopilotModels returns the cached catalog of authID, if any.
+func cachedCopilotModels(authID string) (copilotModelCatalog, bool) {
+	copilotModelCatalogsMu.RLock()
+	defer copilotModelCatalogsMu.RUnlock()
+	catalog, ok := copilotModelCatalogs[authID]
+	return catalog, ok
+}
+
+// copilotModelEndpoints returns the API paths model accepts according to the catalog fetched for
+// authID, or nil when the catalog does not say.
+func copilotModelEndpoints(authID, model string) []string {
+	catalog, ok := cachedCopilotModels(authID)
+	if !ok {
+		return nil
+	}
+	return catalog.endpoints[strings.ToLower(strings.TrimSpace(model))]
+}
+
+// fetchModels requests the model catalog of auth from the Copilot API.
+func (e *GitHubCopilotExecutor) fetchModels(ctx context.Context, auth *cliproxyauth.Auth) (copilotModelCatalog, error) {
+	var catalog copilotModelCatalog
+	apiToken, err := e.ensureAPIToken(ctx, auth)
+	if err != nil {
+		return catalog, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, e.apiURL(auth, githubCopilotModelsPath), nil)
+	if err != nil {
+		return catalog, err
+	}
+	e.applyHeaders(httpReq, auth, apiToken)
+	httpResp, err := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0).Do(httpReq)
+	if err != nil {
+		return catalog, err
+	}
+	defer func() {
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("github-copilot executor: close response body error: %v", errClose)
+		}
+	}()
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return catalog, err
+	}
+	if !isHTTPSuccess(httpResp.StatusCode) {
+		return catalog, fmt.Errorf("status %d: %s", httpResp.StatusCode, summarizeErrorBody(httpResp.Header.Get("Content-Type"), data))
+	}
+	result := gjson.GetBytes(data, "data")
+	if !result.IsArray() {
+		return catalog, fmt.Errorf("unexpected models response")
+	}
+	catalog.models = parseCopilotModels(result)
+	if len(catalog.models) == 0 {
+		return catalog, fmt.Errorf("models response lists no usable models")
+	}
+	catalog.endpoints = parseCopilotEndpoints(result)
+	return catalog, nil
+}
+
+// parseCopilotEndpoints collects the supported_endpoints of each model in the data array of a
+// Copilot /models response.
+func parseCopilotEndpoints(data gjson.Result) map[string][]string {
+	endpoints := make(map[string][]string)
+	data.ForEach(func(_, item gjson.Result) bool {
+		id := strings.ToLower(strings.TrimSpace(item.Get("id").String()))
+		supported := item.Get("supported_endpoints")
+		if id == "" || !supported.IsArray() {
+			return true
+		}
+		paths := make([]string, 0, len(supported.Array()))
+		for _, path := range supported.Array() {
+			if path := strings.TrimSpace(path.String()); path != "" {
+				paths = append(paths, path)
+			}
+		}
+		endpoints[id] = paths
+		return true
+	})
+	return endpoints
+}
+
+// parseCopilotModels converts the data array of a Copilot /models response into registry models.
+// Models disabled by policy are skipped. Limits missing from the response are taken from the
+// static definition of the same model.
+func parseCopilotModels(data gjson.Result) []*registry.ModelInfo {
+	static := make(map[string]*registry.ModelInfo)
+	for _, info := range registry.GetGitHubCopilotModels() {
+		static[info.ID] = info
+	}
+	now := time.Now().Unix()
+	seen := make(map[string]struct{})
+	var models []*registry.ModelInfo
+	data.ForEach(func(_, item gjson.Result) bool {
+		id := strings.TrimSpace(item.Get("id").String())
+		if id == "" {
+			return true
+		}
+		if _, dup := seen[id]; dup {
+			return true
+		}
+		if state := item.Get("policy.state").String(); state != "" && state != "enabled" {
+			return true
+		}
+		capabilities := item.Get("capabilities")
+		kind := capabilities.Get("type").String()
+		if kind != "" && kind != "chat" && kind != "embeddings" {
+			return true
+		}
+		seen[id] = struct{}{}
+		name := strings.TrimSpace(item.Get("name").String())
+		if name == "" {
+			name = id
+		}
+		info := &registry.ModelInfo{
+			ID:                  id,
+			Object:              "model",
+			Created:             now,
+			OwnedBy:             githubCopilotAuthType,
+			Type:                githubCopilotAuthType,
+			DisplayName:         name,
+			Version:             item.Get("version").String(),
+			Description:         name + " via GitHub Copilot",
+			ContextLength:       int(capabilities.Get("limits.max_context_window_tokens").Int()),
+			MaxCompletionTokens: int(capabilities.Get("limits.max_output_tokens").Int()),
+			SupportsVision:      capabilities.Get("supports.vision").Bool(),
+		}
+		if kind == "embeddings" {
+			info.SupportedGenerationMethods = []string{"embeddings"}
+			info.MaxCompletionTokens = 0
+		}
+		if def, ok := static[id]; ok {
+			info.Description = def.Description
+			if info.ContextLength == 0 {
+				info.ContextLength = def.ContextLength
+			}
+			if info.MaxCompletionTokens == 0 {
+				info.MaxCompletionTokens = def.MaxCompletionTokens
+			}
+			if !capabilities.Get("supports.vision").Exists() {
+				info.SupportsVision = def.SupportsVision
+			}
+		}
+		models = append(models, info)
+		return true
+	})
+	return models
+}
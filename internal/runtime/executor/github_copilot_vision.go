@@ -0,0 +1,174 @@
+package executor
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	// copilotVisionHeader tells the Copilot API that a request carries image inputs; requests with
+	// images but without it are rejected.
+	copilotVisionHeader = "Copilot-Vision-Request"
+	// copilotMaxImageBytes is the largest decoded inline image forwarded to Copilot.
+	copilotMaxImageBytes = 20 << 20
+)
+
+// prepareCopilotImages normalizes the image content parts of an OpenAI chat or Responses request
+// for the Copilot API and reports whether the request carries images. Inline images are rewritten as
+// standard base64 data URLs; bare base64 strings get a data URL prefix with a sniffed media type.
+// Oversized or undecodable images, and images sent to a model without vision support, fail with
+// 400 instead of being forwarded.
+func prepareCopilotImages(auth *cliproxyauth.Auth, model string, body []byte) ([]byte, bool, error) {
+	out := body
+	images := 0
+	var errImage error
+	gjson.GetBytes(body, "messages").ForEach(func(mi, message gjson.Result) bool {
+		message.Get("content").ForEach(func(ci, part gjson.Result) bool {
+			if part.Get("type").String() != "image_url" {
+				return true
+			}
+			images++
+			imageURL := part.Get("image_url")
+			raw := imageURL.String()
+			if imageURL.IsObject() {
+				raw = imageURL.Get("url").String()
+			}
+			normalized, err := normalizeCopilotImageURL(raw)
+			if err != nil {
+				errImage = fmt.Errorf("messages[%d].content[%d]: %w", mi.Int(), ci.Int(), err)
+				return false
+			}
+			path := fmt.Sprintf("messages.%d.content.%d.image_url", mi.Int(), ci.Int())
+			if imageURL.IsObject() {
+				out, err = sjson.SetBytes(out, path+".url", normalized)
+			} else {
+				out, err = sjson.SetBytes(out, path, map[string]string{"url": normalized})
+			}
+			if err != nil {
+				errImage = fmt.Errorf("messages[%d].content[%d]: %w", mi.Int(), ci.Int(), err)
+				return false
+			}
+			return true
+		})
+		return errImage == nil
+	})
+	// Responses API requests carry images as input_image parts holding the URL directly.
+	if errImage == nil {
+		gjson.GetBytes(body, "input").ForEach(func(ii, item gjson.Result) bool {
+			item.Get("content").ForEach(func(ci, part gjson.Result) bool {
+				if part.Get("type").String() != "input_image" {
+					return true
+				}
+				images++
+				raw := part.Get("image_url").String()
+				if raw == "" {
+					// Images uploaded as files are resolved by the upstream.
+					return true
+				}
+				normalized, err := normalizeCopilotImageURL(raw)
+				if err == nil {
+					out, err = sjson.SetBytes(out, fmt.Sprintf("input.%d.content.%d.image_url", ii.Int(), ci.Int()), normalized)
+				}
+				if err != nil {
+					errImage = fmt.Errorf("input[%d].content[%d]: %w", ii.Int(), ci.Int(), err)
+					return false
+				}
+				return true
+			})
+			return errImage == nil
+		})
+	}
+	if errImage != nil {
+		return body, false, statusErr{code: http.StatusBadRequest, msg: "github-copilot: invalid image input: " + errImage.Error()}
+	}
+	if images == 0 {
+		return body, false, nil
+	}
+	authID := ""
+	if auth != nil {
+		authID = auth.ID
+	}
+	if supported, known := copilotModelSupportsVision(authID, model); known && !supported {
+		return body, false, statusErr{code: http.StatusBadRequest, msg: fmt.Sprintf("github-copilot: model %s does not accept image inputs", model)}
+	}
+	return out, true, nil
+}
+
+// normalizeCopilotImageURL returns raw as a URL Copilot accepts. Remote URLs are passed through;
+// inline data is decoded to validate it and re-encoded as a standard base64 data URL.
+func normalizeCopilotImageURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("image url is empty")
+	}
+	lower := strings.ToLower(raw)
+	if strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "http://") {
+		return raw, nil
+	}
+	mediaType, encoded := "", raw
+	if strings.HasPrefix(lower, "data:") {
+		header, data, ok := strings.Cut(raw[len("data:"):], ",")
+		if !ok || !strings.HasSuffix(strings.ToLower(header), ";base64") {
+			return "", fmt.Errorf("image data url must be base64 encoded")
+		}
+		mediaType, encoded = strings.TrimSpace(header[:len(header)-len(";base64")]), data
+	}
+	decoded, err := decodeCopilotImageData(encoded)
+	if err != nil {
+		return "", err
+	}
+	if len(decoded) > copilotMaxImageBytes {
+		return "", fmt.Errorf("image is %d bytes, the limit is %d", len(decoded), copilotMaxImageBytes)
+	}
+	if mediaType == "" {
+		mediaType = http.DetectContentType(decoded)
+	}
+	if !strings.HasPrefix(strings.ToLower(mediaType), "image/") {
+		return "", fmt.Errorf("unsupported image media type %s", mediaType)
+	}
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(decoded), nil
+}
+
+// decodeCopilotImageData decodes standard or URL-safe base64, with or without padding and
+// line breaks.
+func decodeCopilotImageData(encoded string) ([]byte, error) {
+	encoded = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\r', '\n':
+			return -1
+		case '-':
+			return '+'
+		case '_':
+			return '/'
+		}
+		return r
+	}, encoded)
+	decoded, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil || len(decoded) == 0 {
+		return nil, fmt.Errorf("image data is not valid base64")
+	}
+	return decoded, nil
+}
+
+// copilotModelSupportsVision looks model up in the catalog fetched for authID, or in the static
+// Copilot model definitions when none was fetched. known is false for models the proxy has no
+// definition for, which are forwarded unchecked.
+func copilotModelSupportsVision(authID, model string) (supported, known bool) {
+	models := registry.GetGitHubCopilotModels()
+	if catalog, ok := cachedCopilotModels(authID); ok && len(catalog.models) > 0 {
+		models = catalog.models
+	}
+	for _, info := range models {
+		if strings.EqualFold(info.ID, model) {
+			return info.SupportsVision, true
+		}
+	}
+	return false, false
+}
@@ -7,10 +7,10 @@ import (
 	"encoding/base64"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -19,6 +19,8 @@ import (
 	"github.com/google/uuid"
 	kiroauth "github.com/router-for-me/CLIProxyAPI/v6/internal/auth/kiro"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/features"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
 	kiroclaude "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/kiro/claude"
 	kirocommon "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/kiro/common"
 	kiroopenai "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/kiro/openai"
@@ -196,18 +198,27 @@ func isIDCAuth(auth *cliproxyauth.Auth) bool {
 // - Claude: tools[].name, tools[].description
 // headers parameter allows checking Anthropic-Beta header for thinking mode detection.
 // Returns the serialized JSON payload and a boolean indicating whether thinking mode was injected.
-func buildKiroPayloadForFormat(body []byte, modelID, profileArn, origin string, isAgentic, isChatOnly bool, sourceFormat sdktranslator.Format, headers http.Header) ([]byte, bool) {
+// metadata carries payload builder options, see payloadMetadata.
+func buildKiroPayloadForFormat(body []byte, modelID, profileArn, origin string, isAgentic, isChatOnly bool, sourceFormat sdktranslator.Format, headers http.Header, metadata map[string]any) ([]byte, bool) {
 	switch sourceFormat.String() {
 	case "openai":
 		log.Debugf("kiro: using OpenAI payload builder for source format: %s", sourceFormat.String())
-		return kiroopenai.BuildKiroPayloadFromOpenAI(body, modelID, profileArn, origin, isAgentic, isChatOnly, headers, nil)
+		return kiroopenai.BuildKiroPayloadFromOpenAI(body, modelID, profileArn, origin, isAgentic, isChatOnly, headers, metadata)
 	default:
-		// Default to Claude format (also handles "claude", "kiro", etc.)
+		// Default to Claude format (also handles "claude", "kiro", and "gemini" translated to Claude, etc.)
 		log.Debugf("kiro: using Claude payload builder for source format: %s", sourceFormat.String())
-		return kiroclaude.BuildKiroPayload(body, modelID, profileArn, origin, isAgentic, isChatOnly, headers, nil)
+		return kiroclaude.BuildKiroPayload(body, modelID, profileArn, origin, isAgentic, isChatOnly, headers, metadata)
 	}
 }
 
+// payloadMetadata returns the payload builder options derived from the configuration.
+func (e *KiroExecutor) payloadMetadata() map[string]any {
+	if e.cfg == nil || !e.cfg.KiroSystemPromptContext {
+		return nil
+	}
+	return map[string]any{kirocommon.MetadataSystemPromptContext: true}
+}
+
 // NewKiroExecutor creates a new Kiro executor instance.
 func NewKiroExecutor(cfg *config.Config) *KiroExecutor {
 	return &KiroExecutor{cfg: cfg}
@@ -275,7 +286,7 @@ func (e *KiroExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, req
 
 	from := opts.SourceFormat
 	to := sdktranslator.FromString("kiro")
-	body := sdktranslator.TranslateRequest(from, to, req.Model, bytes.Clone(req.Payload), true)
+	body := sdktranslator.TranslateRequest(from, to, req.Model, prepareDocuments(auth, from, bytes.Clone(req.Payload)), true)
 
 	kiroModelID := e.mapModelToKiro(req.Model)
 
@@ -308,7 +319,7 @@ func (e *KiroExecutor) executeWithRetry(ctx context.Context, auth *cliproxyauth.
 
 		// Rebuild payload with the correct origin for this endpoint
 		// Each endpoint requires its matching Origin value in the request body
-		kiroPayload, _ = buildKiroPayloadForFormat(body, kiroModelID, profileArn, currentOrigin, isAgentic, isChatOnly, from, opts.Headers)
+		kiroPayload, _ = buildKiroPayloadForFormat(body, kiroModelID, profileArn, currentOrigin, isAgentic, isChatOnly, from, opts.Headers, e.payloadMetadata())
 
 		log.Debugf("kiro: trying endpoint %d/%d: %s (Name: %s, Origin: %s)",
 			endpointIdx+1, len(endpointConfigs), url, endpointConfig.Name, currentOrigin)
@@ -382,7 +393,7 @@ func (e *KiroExecutor) executeWithRetry(ctx context.Context, auth *cliproxyauth.
 				appendAPIResponseChunk(ctx, e.cfg, respBody)
 
 				// Preserve last 429 so callers can correctly backoff when all endpoints are exhausted
-				last429Err = statusErr{code: httpResp.StatusCode, msg: string(respBody)}
+				last429Err = newHTTPStatusErr(httpResp, respBody)
 
 				log.Warnf("kiro: %s endpoint quota exhausted (429), will try next endpoint, body: %s",
 					endpointConfig.Name, summarizeErrorBody(httpResp.Header.Get("Content-Type"), respBody))
@@ -408,7 +419,7 @@ func (e *KiroExecutor) executeWithRetry(ctx context.Context, auth *cliproxyauth.
 					continue
 				}
 				log.Errorf("kiro: server error %d after %d retries", httpResp.StatusCode, maxRetries)
-				return resp, statusErr{code: httpResp.StatusCode, msg: string(respBody)}
+				return resp, newHTTPStatusErr(httpResp, respBody)
 			}
 
 			// Handle 401 errors with token refresh and retry
@@ -424,7 +435,7 @@ func (e *KiroExecutor) executeWithRetry(ctx context.Context, auth *cliproxyauth.
 					refreshedAuth, refreshErr := e.Refresh(ctx, auth)
 					if refreshErr != nil {
 						log.Errorf("kiro: token refresh failed: %v", refreshErr)
-						return resp, statusErr{code: httpResp.StatusCode, msg: string(respBody)}
+						return resp, newHTTPStatusErr(httpResp, respBody)
 					}
 
 					if refreshedAuth != nil {
@@ -436,14 +447,14 @@ func (e *KiroExecutor) executeWithRetry(ctx context.Context, auth *cliproxyauth.
 						}
 						accessToken, profileArn = kiroCredentials(auth)
 						// Rebuild payload with new profile ARN if changed
-						kiroPayload, _ = buildKiroPayloadForFormat(body, kiroModelID, profileArn, currentOrigin, isAgentic, isChatOnly, from, opts.Headers)
+						kiroPayload, _ = buildKiroPayloadForFormat(body, kiroModelID, profileArn, currentOrigin, isAgentic, isChatOnly, from, opts.Headers, e.payloadMetadata())
 						log.Infof("kiro: token refreshed successfully, retrying request")
 						continue
 					}
 				}
 
 				log.Warnf("kiro request error, status: 401, body: %s", summarizeErrorBody(httpResp.Header.Get("Content-Type"), respBody))
-				return resp, statusErr{code: httpResp.StatusCode, msg: string(respBody)}
+				return resp, newHTTPStatusErr(httpResp, respBody)
 			}
 
 			// Handle 402 errors - Monthly Limit Reached
@@ -455,7 +466,7 @@ func (e *KiroExecutor) executeWithRetry(ctx context.Context, auth *cliproxyauth.
 				log.Warnf("kiro: received 402 (monthly limit). Upstream body: %s", string(respBody))
 
 				// Return upstream error body directly
-				return resp, statusErr{code: httpResp.StatusCode, msg: string(respBody)}
+				return resp, newHTTPStatusErr(httpResp, respBody)
 			}
 
 			// Handle 403 errors - Access Denied / Token Expired
@@ -488,7 +499,7 @@ func (e *KiroExecutor) executeWithRetry(ctx context.Context, auth *cliproxyauth.
 					if refreshErr != nil {
 						log.Errorf("kiro: token refresh failed: %v", refreshErr)
 						// Token refresh failed - return error immediately
-						return resp, statusErr{code: httpResp.StatusCode, msg: string(respBody)}
+						return resp, newHTTPStatusErr(httpResp, respBody)
 					}
 					if refreshedAuth != nil {
 						auth = refreshedAuth
@@ -498,7 +509,7 @@ func (e *KiroExecutor) executeWithRetry(ctx context.Context, auth *cliproxyauth.
 							// Continue anyway - the token is valid for this request
 						}
 						accessToken, profileArn = kiroCredentials(auth)
-						kiroPayload, _ = buildKiroPayloadForFormat(body, kiroModelID, profileArn, currentOrigin, isAgentic, isChatOnly, from, opts.Headers)
+						kiroPayload, _ = buildKiroPayloadForFormat(body, kiroModelID, profileArn, currentOrigin, isAgentic, isChatOnly, from, opts.Headers, e.payloadMetadata())
 						log.Infof("kiro: token refreshed for 403, retrying request")
 						continue
 					}
@@ -507,14 +518,14 @@ func (e *KiroExecutor) executeWithRetry(ctx context.Context, auth *cliproxyauth.
 				// For non-token 403 or after max retries, return error immediately
 				// Do NOT switch endpoints for 403 errors
 				log.Warnf("kiro: 403 error, returning immediately (no endpoint switch)")
-				return resp, statusErr{code: httpResp.StatusCode, msg: string(respBody)}
+				return resp, newHTTPStatusErr(httpResp, respBody)
 			}
 
 			if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
 				b, _ := io.ReadAll(httpResp.Body)
 				appendAPIResponseChunk(ctx, e.cfg, b)
 				log.Debugf("kiro request error, status: %d, body: %s", httpResp.StatusCode, summarizeErrorBody(httpResp.Header.Get("Content-Type"), b))
-				err = statusErr{code: httpResp.StatusCode, msg: string(b)}
+				err = newHTTPStatusErr(httpResp, b)
 				if errClose := httpResp.Body.Close(); errClose != nil {
 					log.Errorf("response body close error: %v", errClose)
 				}
@@ -610,7 +621,7 @@ func (e *KiroExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.Aut
 
 	from := opts.SourceFormat
 	to := sdktranslator.FromString("kiro")
-	body := sdktranslator.TranslateRequest(from, to, req.Model, bytes.Clone(req.Payload), true)
+	body := sdktranslator.TranslateRequest(from, to, req.Model, prepareDocuments(auth, from, bytes.Clone(req.Payload)), true)
 
 	kiroModelID := e.mapModelToKiro(req.Model)
 
@@ -641,7 +652,7 @@ func (e *KiroExecutor) executeStreamWithRetry(ctx context.Context, auth *cliprox
 
 		// Rebuild payload with the correct origin for this endpoint
 		// Each endpoint requires its matching Origin value in the request body
-		kiroPayload, thinkingEnabled := buildKiroPayloadForFormat(body, kiroModelID, profileArn, currentOrigin, isAgentic, isChatOnly, from, opts.Headers)
+		kiroPayload, thinkingEnabled := buildKiroPayloadForFormat(body, kiroModelID, profileArn, currentOrigin, isAgentic, isChatOnly, from, opts.Headers, e.payloadMetadata())
 
 		log.Debugf("kiro: stream trying endpoint %d/%d: %s (Name: %s, Origin: %s)",
 			endpointIdx+1, len(endpointConfigs), url, endpointConfig.Name, currentOrigin)
@@ -715,7 +726,7 @@ func (e *KiroExecutor) executeStreamWithRetry(ctx context.Context, auth *cliprox
 				appendAPIResponseChunk(ctx, e.cfg, respBody)
 
 				// Preserve last 429 so callers can correctly backoff when all endpoints are exhausted
-				last429Err = statusErr{code: httpResp.StatusCode, msg: string(respBody)}
+				last429Err = newHTTPStatusErr(httpResp, respBody)
 
 				log.Warnf("kiro: stream %s endpoint quota exhausted (429), will try next endpoint, body: %s",
 					endpointConfig.Name, summarizeErrorBody(httpResp.Header.Get("Content-Type"), respBody))
@@ -741,7 +752,7 @@ func (e *KiroExecutor) executeStreamWithRetry(ctx context.Context, auth *cliprox
 					continue
 				}
 				log.Errorf("kiro: stream server error %d after %d retries", httpResp.StatusCode, maxRetries)
-				return nil, statusErr{code: httpResp.StatusCode, msg: string(respBody)}
+				return nil, newHTTPStatusErr(httpResp, respBody)
 			}
 
 			// Handle 400 errors - Credential/Validation issues
@@ -754,7 +765,7 @@ func (e *KiroExecutor) executeStreamWithRetry(ctx context.Context, auth *cliprox
 				log.Warnf("kiro: received 400 error (attempt %d/%d), body: %s", attempt+1, maxRetries+1, summarizeErrorBody(httpResp.Header.Get("Content-Type"), respBody))
 
 				// 400 errors indicate request validation issues - return immediately without retry
-				return nil, statusErr{code: httpResp.StatusCode, msg: string(respBody)}
+				return nil, newHTTPStatusErr(httpResp, respBody)
 			}
 
 			// Handle 401 errors with token refresh and retry
@@ -770,7 +781,7 @@ func (e *KiroExecutor) executeStreamWithRetry(ctx context.Context, auth *cliprox
 					refreshedAuth, refreshErr := e.Refresh(ctx, auth)
 					if refreshErr != nil {
 						log.Errorf("kiro: token refresh failed: %v", refreshErr)
-						return nil, statusErr{code: httpResp.StatusCode, msg: string(respBody)}
+						return nil, newHTTPStatusErr(httpResp, respBody)
 					}
 
 					if refreshedAuth != nil {
@@ -782,14 +793,14 @@ func (e *KiroExecutor) executeStreamWithRetry(ctx context.Context, auth *cliprox
 						}
 						accessToken, profileArn = kiroCredentials(auth)
 						// Rebuild payload with new profile ARN if changed
-						kiroPayload, _ = buildKiroPayloadForFormat(body, kiroModelID, profileArn, currentOrigin, isAgentic, isChatOnly, from, opts.Headers)
+						kiroPayload, _ = buildKiroPayloadForFormat(body, kiroModelID, profileArn, currentOrigin, isAgentic, isChatOnly, from, opts.Headers, e.payloadMetadata())
 						log.Infof("kiro: token refreshed successfully, retrying stream request")
 						continue
 					}
 				}
 
 				log.Warnf("kiro stream error, status: 401, body: %s", string(respBody))
-				return nil, statusErr{code: httpResp.StatusCode, msg: string(respBody)}
+				return nil, newHTTPStatusErr(httpResp, respBody)
 			}
 
 			// Handle 402 errors - Monthly Limit Reached
@@ -801,7 +812,7 @@ func (e *KiroExecutor) executeStreamWithRetry(ctx context.Context, auth *cliprox
 				log.Warnf("kiro: stream received 402 (monthly limit). Upstream body: %s", string(respBody))
 
 				// Return upstream error body directly
-				return nil, statusErr{code: httpResp.StatusCode, msg: string(respBody)}
+				return nil, newHTTPStatusErr(httpResp, respBody)
 			}
 
 			// Handle 403 errors - Access Denied / Token Expired
@@ -834,7 +845,7 @@ func (e *KiroExecutor) executeStreamWithRetry(ctx context.Context, auth *cliprox
 					if refreshErr != nil {
 						log.Errorf("kiro: token refresh failed: %v", refreshErr)
 						// Token refresh failed - return error immediately
-						return nil, statusErr{code: httpResp.StatusCode, msg: string(respBody)}
+						return nil, newHTTPStatusErr(httpResp, respBody)
 					}
 					if refreshedAuth != nil {
 						auth = refreshedAuth
@@ -844,7 +855,7 @@ func (e *KiroExecutor) executeStreamWithRetry(ctx context.Context, auth *cliprox
 							// Continue anyway - the token is valid for this request
 						}
 						accessToken, profileArn = kiroCredentials(auth)
-						kiroPayload, _ = buildKiroPayloadForFormat(body, kiroModelID, profileArn, currentOrigin, isAgentic, isChatOnly, from, opts.Headers)
+						kiroPayload, _ = buildKiroPayloadForFormat(body, kiroModelID, profileArn, currentOrigin, isAgentic, isChatOnly, from, opts.Headers, e.payloadMetadata())
 						log.Infof("kiro: token refreshed for 403, retrying stream request")
 						continue
 					}
@@ -853,7 +864,7 @@ func (e *KiroExecutor) executeStreamWithRetry(ctx context.Context, auth *cliprox
 				// For non-token 403 or after max retries, return error immediately
 				// Do NOT switch endpoints for 403 errors
 				log.Warnf("kiro: 403 error, returning immediately (no endpoint switch)")
-				return nil, statusErr{code: httpResp.StatusCode, msg: string(respBody)}
+				return nil, newHTTPStatusErr(httpResp, respBody)
 			}
 
 			if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
@@ -863,7 +874,7 @@ func (e *KiroExecutor) executeStreamWithRetry(ctx context.Context, auth *cliprox
 				if errClose := httpResp.Body.Close(); errClose != nil {
 					log.Errorf("response body close error: %v", errClose)
 				}
-				return nil, statusErr{code: httpResp.StatusCode, msg: string(b)}
+				return nil, newHTTPStatusErr(httpResp, b)
 			}
 
 			out := make(chan cliproxyexecutor.StreamChunk)
@@ -1921,6 +1932,9 @@ func (e *KiroExecutor) streamToChannel(ctx context.Context, body io.Reader, out
 	// IMPORTANT: This must persist across all TranslateStream calls
 	var translatorParam any
 
+	// Thinking tags are passed through as text when extraction is switched off
+	extractThinking := features.Enabled(features.ThinkingExtraction)
+
 	// Thinking mode state tracking - tag-based parsing for <thinking> tags in content
 	inThinkBlock := false                          // Whether we're currently inside a <thinking> block
 	isThinkingBlockOpen := false                   // Track if thinking content block SSE event is open
@@ -2438,7 +2452,10 @@ func (e *KiroExecutor) streamToChannel(ctx context.Context, body io.Reader, out
 						}
 					} else {
 						// Not in thinking block, look for <thinking>
-						startIdx := strings.Index(processContent, kirocommon.ThinkingStartTag)
+						startIdx := -1
+						if extractThinking {
+							startIdx = strings.Index(processContent, kirocommon.ThinkingStartTag)
+						}
 						if startIdx >= 0 {
 							// Found start tag - emit text content before the tag
 							textBefore := processContent[:startIdx]
@@ -2492,7 +2509,7 @@ func (e *KiroExecutor) streamToChannel(ctx context.Context, body io.Reader, out
 						} else {
 							// No start tag found - check for partial match at end
 							partialMatch := false
-							for i := 1; i < len(kirocommon.ThinkingStartTag) && i <= len(processContent); i++ {
+							for i := 1; extractThinking && i < len(kirocommon.ThinkingStartTag) && i <= len(processContent); i++ {
 								if strings.HasSuffix(processContent, kirocommon.ThinkingStartTag[:i]) {
 									// Possible partial tag at end, buffer it
 									pendingContent.WriteString(processContent[len(processContent)-i:])
@@ -3055,6 +3072,14 @@ func (e *KiroExecutor) CountTokens(ctx context.Context, auth *cliproxyauth.Auth,
 	}, nil
 }
 
+// refreshConfig returns the proactive refresh settings for Kiro tokens.
+func (e *KiroExecutor) refreshConfig() config.KiroRefreshConfig {
+	if e.cfg == nil {
+		return config.KiroRefreshConfig{}
+	}
+	return e.cfg.KiroRefresh
+}
+
 // Refresh refreshes the Kiro OAuth token.
 // Supports both AWS Builder ID (SSO OIDC) and Google OAuth (social login).
 // Uses mutex to prevent race conditions when multiple concurrent requests try to refresh.
@@ -3094,14 +3119,14 @@ func (e *KiroExecutor) Refresh(ctx context.Context, auth *cliproxyauth.Auth) (*c
 		// Also check if expires_at is now in the future with sufficient buffer
 		if expiresAt, ok := auth.Metadata["expires_at"].(string); ok {
 			if expTime, err := time.Parse(time.RFC3339, expiresAt); err == nil {
-				// If token expires more than 5 minutes from now, it's still valid
-				if time.Until(expTime) > 5*time.Minute {
+				// If token expires after the refresh window (lead plus jitter), it's still valid
+				if refreshCfg := e.refreshConfig(); !cliproxyauth.TokenExpired(expTime.Add(-(refreshCfg.Lead() + refreshCfg.Jitter())), time.Now()) {
 					log.Debugf("kiro executor: token is still valid (expires in %v), skipping refresh", time.Until(expTime))
 					// CRITICAL FIX: Set NextRefreshAfter to prevent frequent refresh checks
 					// Without this, shouldRefresh() will return true again in 5 seconds
 					updated := auth.Clone()
-					// Set next refresh to 5 minutes before expiry, or at least 30 seconds from now
-					nextRefresh := expTime.Add(-5 * time.Minute)
+					// Set next refresh to the refresh lead before expiry, or at least 30 seconds from now
+					nextRefresh := expTime.Add(-e.refreshConfig().Lead())
 					minNextRefresh := time.Now().Add(30 * time.Second)
 					if nextRefresh.Before(minNextRefresh) {
 						nextRefresh = minNextRefresh
@@ -3167,6 +3192,11 @@ func (e *KiroExecutor) Refresh(ctx context.Context, auth *cliproxyauth.Auth) (*c
 	}
 
 	if err != nil {
+		if errors.Is(err, kiroauth.ErrRefreshTokenRevoked) {
+			// Refreshing again with the same token keeps failing; surface it for re-login instead.
+			log.Warnf("kiro executor: refresh token for auth %s was revoked or reused, re-login required", authID)
+			return nil, fmt.Errorf("kiro executor: %w: %w", cliproxyauth.ErrReauthRequired, err)
+		}
 		return nil, fmt.Errorf("kiro executor: token refresh failed: %w", err)
 	}
 
@@ -3207,9 +3237,17 @@ func (e *KiroExecutor) Refresh(ctx context.Context, auth *cliproxyauth.Auth) (*c
 		updated.Attributes["profile_arn"] = tokenData.ProfileArn
 	}
 
-	// NextRefreshAfter is aligned with RefreshLead (5min)
+	// NextRefreshAfter is aligned with the configured refresh lead
 	if expiresAt, parseErr := time.Parse(time.RFC3339, tokenData.ExpiresAt); parseErr == nil {
-		updated.NextRefreshAfter = expiresAt.Add(-5 * time.Minute)
+		updated.NextRefreshAfter = expiresAt.Add(-e.refreshConfig().Lead())
+	}
+
+	// A rotated refresh token invalidates the old one, so write it out before anything else can
+	// fail; losing it would leave only a revoked token on disk.
+	if tokenData.RefreshToken != refreshToken {
+		if persistErr := e.persistRefreshedAuth(updated); persistErr != nil {
+			log.Warnf("kiro executor: failed to persist rotated refresh token for auth %s: %v", authID, persistErr)
+		}
 	}
 
 	log.Infof("kiro executor: token refreshed successfully, expires at %s", tokenData.ExpiresAt)
@@ -3250,13 +3288,8 @@ func (e *KiroExecutor) persistRefreshedAuth(auth *cliproxyauth.Auth) error {
 		return fmt.Errorf("kiro executor: marshal metadata failed: %w", err)
 	}
 
-	// Write to temp file first, then rename (atomic write)
-	tmp := authPath + ".tmp"
-	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
-		return fmt.Errorf("kiro executor: write temp auth file failed: %w", err)
-	}
-	if err := os.Rename(tmp, authPath); err != nil {
-		return fmt.Errorf("kiro executor: rename auth file failed: %w", err)
+	if err := misc.WriteCredentialFile(authPath, raw); err != nil {
+		return fmt.Errorf("kiro executor: write auth file failed: %w", err)
 	}
 
 	log.Debugf("kiro executor: persisted refreshed auth to %s", authPath)
@@ -3312,8 +3345,8 @@ func (e *KiroExecutor) isTokenExpired(accessToken string) bool {
 	expTime := time.Unix(claims.Exp, 0)
 	now := time.Now()
 
-	// Consider token expired if it expires within 1 minute (buffer for clock skew)
-	isExpired := now.After(expTime) || expTime.Sub(now) < time.Minute
+	// exp is set by the upstream clock; compare against it with the measured skew and margin.
+	isExpired := cliproxyauth.UpstreamTokenExpired(expTime, now)
 	if isExpired {
 		log.Debugf("kiro: token expired at %s (now: %s)", expTime.Format(time.RFC3339), now.Format(time.RFC3339))
 	}
@@ -154,7 +154,7 @@ func FetchKiroUsageLimits(ctx context.Context, auth *cliproxyauth.Auth, cfg *con
 		if json.Unmarshal(raw, &reasonHolder) == nil && strings.TrimSpace(reasonHolder.Reason) != "" {
 			return nil, fmt.Errorf("kiro quota: banned: %s", strings.TrimSpace(reasonHolder.Reason))
 		}
-		return nil, statusErr{code: httpResp.StatusCode, msg: string(raw)}
+		return nil, newHTTPStatusErr(httpResp, raw)
 	}
 
 	var resp kiroUsageLimitsResponse
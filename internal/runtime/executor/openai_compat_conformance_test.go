@@ -0,0 +1,32 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/conformance"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+)
+
+func TestOpenAICompatExecutorConformance(t *testing.T) {
+	responses := conformance.Responses{
+		JSON: []byte(`{"id":"c1","object":"chat.completion","model":"compat-model","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}`),
+		Events: []string{
+			`{"id":"c1","object":"chat.completion.chunk","model":"compat-model","choices":[{"index":0,"delta":{"role":"assistant","content":"hi"}}]}`,
+			`{"id":"c1","object":"chat.completion.chunk","model":"compat-model","choices":[{"index":0,"delta":{},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}`,
+			`[DONE]`,
+		},
+	}
+	conformance.Run(t, conformance.Provider{
+		Setup: func(t *testing.T, scenario conformance.Scenario) (cliproxyauth.ProviderExecutor, *cliproxyauth.Auth) {
+			upstream := conformance.NewUpstream(t, scenario, responses)
+			auth := &cliproxyauth.Auth{Provider: "compat", Attributes: map[string]string{"base_url": upstream.URL, "api_key": "sk-test"}}
+			return NewOpenAICompatExecutor("compat", &config.Config{}), auth
+		},
+		Model:         "compat-model",
+		Payload:       []byte(`{"model":"compat-model","messages":[{"role":"user","content":"hello"}]}`),
+		SourceFormat:  sdktranslator.FromString("openai"),
+		ReportsTokens: true,
+	})
+}
@@ -36,7 +36,21 @@ var (
 //
 // Returns:
 //   - *http.Client: An HTTP client with configured proxy or transport
+//
+// Auths with a fingerprint profile get a client that adds the profile headers to each request.
 func newProxyAwareHTTPClient(ctx context.Context, cfg *config.Config, auth *cliproxyauth.Auth, timeout time.Duration) *http.Client {
+	httpClient := proxyHTTPClient(ctx, cfg, auth, timeout)
+	if headers := fingerprintHeaders(cfg, auth); len(headers) > 0 {
+		return &http.Client{
+			Transport: fingerprintTransport{base: httpClient.Transport, headers: headers},
+			Timeout:   httpClient.Timeout,
+		}
+	}
+	return httpClient
+}
+
+// proxyHTTPClient returns the cached proxy-aware client shared by auths with the same proxy.
+func proxyHTTPClient(ctx context.Context, cfg *config.Config, auth *cliproxyauth.Auth, timeout time.Duration) *http.Client {
 	// Priority 1: Use auth.ProxyURL if configured
 	var proxyURL string
 	if auth != nil {
@@ -76,7 +90,7 @@ func newProxyAwareHTTPClient(ctx context.Context, cfg *config.Config, auth *clip
 	if proxyURL != "" {
 		transport := buildProxyTransport(proxyURL)
 		if transport != nil {
-			httpClient.Transport = transport
+			httpClient.Transport = clockSkewTransport{base: transport}
 			// Cache the client
 			httpClientCacheMutex.Lock()
 			httpClientCache[cacheKey] = httpClient
@@ -91,6 +105,7 @@ func newProxyAwareHTTPClient(ctx context.Context, cfg *config.Config, auth *clip
 	if rt, ok := ctx.Value("cliproxy.roundtripper").(http.RoundTripper); ok && rt != nil {
 		httpClient.Transport = rt
 	}
+	httpClient.Transport = clockSkewTransport{base: httpClient.Transport}
 
 	// Cache the client for no-proxy case
 	if proxyURL == "" {
@@ -153,3 +168,21 @@ func buildProxyTransport(proxyURL string) *http.Transport {
 
 	return transport
 }
+
+// clockSkewTransport feeds the Date header of every upstream response into the clock skew
+// estimate used by token expiry checks.
+type clockSkewTransport struct {
+	base http.RoundTripper
+}
+
+func (t clockSkewTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err == nil && resp != nil {
+		cliproxyauth.ObserveUpstreamDate(resp.Header.Get("Date"), time.Now())
+	}
+	return resp, err
+}
@@ -0,0 +1,87 @@
+package executor
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rateLimitResetHeaders are reset hints sent alongside rate limit responses. The longest one wins
+// because the request stays blocked until every exhausted limit has recovered.
+var rateLimitResetHeaders = []string{
+	"x-ratelimit-reset",
+	"x-ratelimit-reset-requests",
+	"x-ratelimit-reset-tokens",
+	"anthropic-ratelimit-requests-reset",
+	"anthropic-ratelimit-tokens-reset",
+	"anthropic-ratelimit-input-tokens-reset",
+	"anthropic-ratelimit-output-tokens-reset",
+}
+
+// newHTTPStatusErr builds a statusErr for an upstream error response, carrying any retry hint
+// found in the response headers.
+func newHTTPStatusErr(resp *http.Response, body []byte) statusErr {
+	err := statusErr{code: resp.StatusCode, msg: string(body)}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == 529 {
+		err.retryAfter = parseRetryAfterHeaders(resp.Header, time.Now())
+	}
+	return err
+}
+
+// parseRetryAfterHeaders reads Retry-After (seconds or HTTP date), retry-after-ms and the
+// provider specific rate limit reset headers. It returns nil when no usable hint is present.
+func parseRetryAfterHeaders(header http.Header, now time.Time) *time.Duration {
+	if header == nil {
+		return nil
+	}
+	if raw := strings.TrimSpace(header.Get("retry-after-ms")); raw != "" {
+		if ms, err := strconv.ParseFloat(raw, 64); err == nil && ms >= 0 {
+			wait := time.Duration(ms * float64(time.Millisecond))
+			return &wait
+		}
+	}
+	if raw := strings.TrimSpace(header.Get("Retry-After")); raw != "" {
+		if seconds, err := strconv.ParseFloat(raw, 64); err == nil && seconds >= 0 {
+			wait := time.Duration(seconds * float64(time.Second))
+			return &wait
+		}
+		if at, err := http.ParseTime(raw); err == nil {
+			wait := max(at.Sub(now), 0)
+			return &wait
+		}
+	}
+	var longest *time.Duration
+	for _, name := range rateLimitResetHeaders {
+		wait, ok := parseResetValue(header.Get(name), now)
+		if ok && (longest == nil || wait > *longest) {
+			longest = &wait
+		}
+	}
+	return longest
+}
+
+// parseResetValue accepts a delay in seconds, a Unix timestamp, a Go duration such as "6m0s"
+// or an RFC 3339 timestamp.
+func parseResetValue(raw string, now time.Time) (time.Duration, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, false
+	}
+	if value, err := strconv.ParseFloat(raw, 64); err == nil && value >= 0 {
+		// Values this large are epoch seconds rather than delays.
+		if value > 1e9 {
+			sec, frac := math.Modf(value)
+			return max(time.Unix(int64(sec), int64(frac*1e9)).Sub(now), 0), true
+		}
+		return time.Duration(value * float64(time.Second)), true
+	}
+	if wait, err := time.ParseDuration(raw); err == nil && wait >= 0 {
+		return wait, true
+	}
+	if at, err := time.Parse(time.RFC3339, raw); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,49 @@
+package executor
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfterHeaders(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name   string
+		header http.Header
+		want   *time.Duration
+	}{
+		{"none", http.Header{}, nil},
+		{"seconds", http.Header{"Retry-After": {"7"}}, durationPtr(7 * time.Second)},
+		{"http date", http.Header{"Retry-After": {now.Add(90 * time.Second).Format(http.TimeFormat)}}, durationPtr(90 * time.Second)},
+		{"milliseconds", http.Header{"Retry-After-Ms": {"1500"}}, durationPtr(1500 * time.Millisecond)},
+		{"go duration", http.Header{"X-Ratelimit-Reset-Requests": {"6m0s"}}, durationPtr(6 * time.Minute)},
+		{"epoch", http.Header{"X-Ratelimit-Reset": {"1735732830"}}, durationPtr(30 * time.Second)},
+		{"longest reset wins", http.Header{
+			"X-Ratelimit-Reset-Requests": {"2s"},
+			"X-Ratelimit-Reset-Tokens":   {"45s"},
+		}, durationPtr(45 * time.Second)},
+		{"anthropic rfc3339", http.Header{"Anthropic-Ratelimit-Tokens-Reset": {now.Add(time.Minute).Format(time.RFC3339)}}, durationPtr(time.Minute)},
+	}
+	for _, tc := range cases {
+		got := parseRetryAfterHeaders(tc.header, now)
+		if (got == nil) != (tc.want == nil) || (got != nil && *got != *tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestStatusErrHeadersExposeRetryAfter(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"2.5"}}}
+	err := newHTTPStatusErr(resp, []byte("rate limited"))
+	if got := err.Headers().Get("Retry-After"); got != "3" {
+		t.Fatalf("Retry-After = %q, want 3", got)
+	}
+
+	resp = &http.Response{StatusCode: http.StatusBadRequest, Header: http.Header{"Retry-After": {"2"}}}
+	if err = newHTTPStatusErr(resp, nil); err.Headers() != nil {
+		t.Fatalf("non rate limit errors must not carry a retry hint")
+	}
+}
+
+func durationPtr(d time.Duration) *time.Duration { return &d }
@@ -1,6 +1,8 @@
 package executor
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -19,6 +21,8 @@ var tokenizerCache sync.Map
 type TokenizerWrapper struct {
 	Codec            tokenizer.Codec
 	AdjustmentFactor float64 // 1.0 means no adjustment, >1.0 means tiktoken underestimates
+	// ScriptFactors replace AdjustmentFactor when the dominant script of the text has an entry.
+	ScriptFactors map[string]float64
 }
 
 // Count returns the token count with adjustment factor applied
@@ -27,8 +31,14 @@ func (tw *TokenizerWrapper) Count(text string) (int, error) {
 	if err != nil {
 		return 0, err
 	}
-	if tw.AdjustmentFactor != 1.0 && tw.AdjustmentFactor > 0 {
-		return int(float64(count) * tw.AdjustmentFactor), nil
+	factor := tw.AdjustmentFactor
+	if len(tw.ScriptFactors) > 0 {
+		if scriptFactor, ok := tw.ScriptFactors[dominantScript(text)]; ok {
+			factor = scriptFactor
+		}
+	}
+	if factor != 1.0 && factor > 0 {
+		return int(float64(count) * factor), nil
 	}
 	return count, nil
 }
@@ -53,7 +63,8 @@ func getTokenizer(model string) (*TokenizerWrapper, error) {
 }
 
 // tokenizerForModel returns a tokenizer codec suitable for an OpenAI-style model id.
-// For Claude models, applies a 1.1 adjustment factor since tiktoken may underestimate.
+// For Claude models, applies a 1.1 adjustment factor since tiktoken may underestimate, refined
+// per dominant script of the counted text.
 func tokenizerForModel(model string) (*TokenizerWrapper, error) {
 	sanitized := strings.ToLower(strings.TrimSpace(model))
 
@@ -64,7 +75,7 @@ func tokenizerForModel(model string) (*TokenizerWrapper, error) {
 		if err != nil {
 			return nil, err
 		}
-		return &TokenizerWrapper{Codec: enc, AdjustmentFactor: 1.1}, nil
+		return &TokenizerWrapper{Codec: enc, AdjustmentFactor: 1.1, ScriptFactors: scriptFactorsForModel(sanitized, true)}, nil
 	}
 
 	var enc tokenizer.Codec
@@ -100,7 +111,7 @@ func tokenizerForModel(model string) (*TokenizerWrapper, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &TokenizerWrapper{Codec: enc, AdjustmentFactor: 1.0}, nil
+	return &TokenizerWrapper{Codec: enc, AdjustmentFactor: 1.0, ScriptFactors: scriptFactorsForModel(sanitized, false)}, nil
 }
 
 // countOpenAIChatTokens approximates prompt tokens for OpenAI chat completions payloads.
@@ -180,15 +191,25 @@ func countClaudeChatTokens(enc *TokenizerWrapper, payload []byte) (int64, error)
 	return int64(count) + int64(imageTokens), nil
 }
 
-// imageTokenPattern matches [IMAGE:xxx tokens] format for extracting estimated image tokens
-var imageTokenPattern = regexp.MustCompile(`\[IMAGE:(\d+) tokens\]`)
+// imageTokenPattern matches [IMAGE:xxx tokens] format for extracting estimated image tokens,
+// optionally followed by a content hash identifying inline image data.
+var imageTokenPattern = regexp.MustCompile(`\[IMAGE:(\d+) tokens(?: ([0-9a-f]+))?\]`)
 
 // extractImageTokens extracts image token estimates from placeholder text.
 // Placeholders are in the format [IMAGE:xxx tokens] where xxx is the estimated token count.
+// Placeholders carrying the same content hash are counted once, so an image resent on every
+// turn of a conversation does not inflate the estimate.
 func extractImageTokens(text string) int {
 	matches := imageTokenPattern.FindAllStringSubmatch(text, -1)
 	total := 0
+	seen := make(map[string]bool)
 	for _, match := range matches {
+		if len(match) > 2 && match[2] != "" {
+			if seen[match[2]] {
+				continue
+			}
+			seen[match[2]] = true
+		}
 		if len(match) > 1 {
 			if tokens, err := strconv.Atoi(match[1]); err == nil {
 				total += tokens
@@ -198,6 +219,16 @@ func extractImageTokens(text string) int {
 	return total
 }
 
+// imagePlaceholder returns the token placeholder for an image, tagged with a hash of its inline
+// data when available so duplicates can be recognized.
+func imagePlaceholder(tokens int, data string) string {
+	if data == "" {
+		return fmt.Sprintf("[IMAGE:%d tokens]", tokens)
+	}
+	sum := sha256.Sum256([]byte(data))
+	return fmt.Sprintf("[IMAGE:%d tokens %s]", tokens, hex.EncodeToString(sum[:6]))
+}
+
 // estimateImageTokens calculates estimated tokens for an image based on dimensions.
 // Based on Claude's image token calculation: tokens ≈ (width * height) / 750
 // Minimum 85 tokens, maximum 1590 tokens (for 1568x1568 images).
@@ -280,12 +311,13 @@ func collectClaudeContent(content gjson.Result, segments *[]string) {
 				if source.Exists() {
 					width := source.Get("width").Float()
 					height := source.Get("height").Float()
+					data := source.Get("data").String()
 					if width > 0 && height > 0 {
 						tokens := estimateImageTokens(width, height)
-						addIfNotEmpty(segments, fmt.Sprintf("[IMAGE:%d tokens]", tokens))
+						addIfNotEmpty(segments, imagePlaceholder(tokens, data))
 					} else {
 						// No dimensions available, use default estimate
-						addIfNotEmpty(segments, "[IMAGE:1000 tokens]")
+						addIfNotEmpty(segments, imagePlaceholder(1000, data))
 					}
 				} else {
 					// No source info, use default estimate
@@ -364,7 +396,12 @@ func collectOpenAIContent(content gjson.Result, segments *[]string) {
 			case "text", "input_text", "output_text":
 				addIfNotEmpty(segments, part.Get("text").String())
 			case "image_url":
-				addIfNotEmpty(segments, part.Get("image_url.url").String())
+				// Inline images are estimated rather than tokenized as base64 text
+				if url := part.Get("image_url.url").String(); strings.HasPrefix(url, "data:") {
+					addIfNotEmpty(segments, imagePlaceholder(1000, url))
+				} else {
+					addIfNotEmpty(segments, url)
+				}
 			case "input_audio", "output_audio", "audio":
 				addIfNotEmpty(segments, part.Get("id").String())
 			case "tool_result":
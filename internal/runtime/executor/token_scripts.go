@@ -0,0 +1,118 @@
+package executor
+
+import (
+	"strings"
+	"sync/atomic"
+	"unicode"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+// Scripts used to pick token estimate adjustment factors.
+const (
+	scriptLatin    = "latin"
+	scriptChinese  = "chinese"
+	scriptJapanese = "japanese"
+	scriptKorean   = "korean"
+	scriptCyrillic = "cyrillic"
+	scriptOther    = "other"
+)
+
+// claudeScriptFactors correct cl100k_base counts for Claude-family models. CJK text is split into
+// noticeably more tokens by Claude's tokenizer than by tiktoken, so the latin factor alone
+// underestimates CJK-heavy prompts.
+var claudeScriptFactors = map[string]float64{
+	scriptLatin:    1.1,
+	scriptChinese:  1.3,
+	scriptJapanese: 1.3,
+	scriptKorean:   1.2,
+	scriptCyrillic: 1.15,
+	scriptOther:    1.1,
+}
+
+var tokenEstimation atomic.Pointer[config.TokenEstimationConfig]
+
+// SetTokenEstimation applies configured token estimate factors and drops cached tokenizers so
+// the next estimate picks them up.
+func SetTokenEstimation(cfg config.TokenEstimationConfig) {
+	tokenEstimation.Store(&cfg)
+	tokenizerCache.Range(func(key, _ any) bool {
+		tokenizerCache.Delete(key)
+		return true
+	})
+}
+
+// scriptFactorsForModel resolves per-script factors for a model: built-in Claude factors, then
+// configured script factors for Claude-family models, then the longest matching model prefix.
+func scriptFactorsForModel(sanitized string, claudeFamily bool) map[string]float64 {
+	factors := make(map[string]float64)
+	if claudeFamily {
+		for script, factor := range claudeScriptFactors {
+			factors[script] = factor
+		}
+	}
+	cfg := tokenEstimation.Load()
+	if cfg == nil {
+		return factors
+	}
+	if claudeFamily {
+		for script, factor := range cfg.ScriptFactors {
+			if factor > 0 {
+				factors[strings.ToLower(script)] = factor
+			}
+		}
+	}
+	bestPrefix := ""
+	for prefix := range cfg.ModelFactors {
+		lower := strings.ToLower(prefix)
+		if strings.HasPrefix(sanitized, lower) && len(lower) > len(bestPrefix) {
+			bestPrefix = prefix
+		}
+	}
+	if bestPrefix != "" {
+		for script, factor := range cfg.ModelFactors[bestPrefix] {
+			if factor > 0 {
+				factors[strings.ToLower(script)] = factor
+			}
+		}
+	}
+	return factors
+}
+
+// dominantScript returns the script with the most letters in text. Text with kana next to Han
+// characters is Japanese; text without letters counts as latin.
+func dominantScript(text string) string {
+	counts := make(map[string]int, 6)
+	kana := 0
+	for _, r := range text {
+		switch {
+		case r < unicode.MaxASCII:
+			if unicode.IsLetter(r) {
+				counts[scriptLatin]++
+			}
+		case unicode.Is(unicode.Han, r):
+			counts[scriptChinese]++
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Hangul, r):
+			counts[scriptKorean]++
+		case unicode.Is(unicode.Cyrillic, r):
+			counts[scriptCyrillic]++
+		case unicode.Is(unicode.Latin, r):
+			counts[scriptLatin]++
+		case unicode.IsLetter(r):
+			counts[scriptOther]++
+		}
+	}
+	if kana > 0 {
+		counts[scriptJapanese] = kana + counts[scriptChinese]
+		delete(counts, scriptChinese)
+	}
+	best, bestCount := scriptLatin, 0
+	for _, script := range []string{scriptLatin, scriptChinese, scriptJapanese, scriptKorean, scriptCyrillic, scriptOther} {
+		if counts[script] > bestCount {
+			best, bestCount = script, counts[script]
+		}
+	}
+	return best
+}
@@ -0,0 +1,58 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestDominantScript(t *testing.T) {
+	cases := map[string]string{
+		"":                  scriptLatin,
+		"Hello, world! 123": scriptLatin,
+		"请帮我总结这篇文章 please":  scriptChinese,
+		"この文章を要約してください":                       scriptJapanese,
+		"이 글을 요약해 주세요":                        scriptKorean,
+		"Привет, как дела?":                   scriptCyrillic,
+		"مرحبا كيف حالك":                      scriptOther,
+		"func main() { fmt.Println(\"你好\") }": scriptLatin,
+	}
+	for text, want := range cases {
+		if got := dominantScript(text); got != want {
+			t.Errorf("dominantScript(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestTokenizerScriptFactors(t *testing.T) {
+	t.Cleanup(func() { SetTokenEstimation(config.TokenEstimationConfig{}) })
+
+	SetTokenEstimation(config.TokenEstimationConfig{
+		ScriptFactors: map[string]float64{"chinese": 2},
+		ModelFactors:  map[string]map[string]float64{"gpt-4o": {"Japanese": 3}},
+	})
+
+	claude, err := getTokenizer("claude-sonnet-4-5")
+	if err != nil {
+		t.Fatalf("getTokenizer: %v", err)
+	}
+	if claude.ScriptFactors[scriptChinese] != 2 || claude.ScriptFactors[scriptLatin] != 1.1 {
+		t.Fatalf("unexpected claude factors: %v", claude.ScriptFactors)
+	}
+	raw, _ := claude.Codec.Count("请帮我总结这篇文章")
+	if got, _ := claude.Count("请帮我总结这篇文章"); got != raw*2 {
+		t.Fatalf("Count() = %d, want %d", got, raw*2)
+	}
+
+	gpt, err := getTokenizer("gpt-4o-mini")
+	if err != nil {
+		t.Fatalf("getTokenizer: %v", err)
+	}
+	if gpt.ScriptFactors[scriptJapanese] != 3 || gpt.ScriptFactors[scriptChinese] != 0 {
+		t.Fatalf("unexpected gpt factors: %v", gpt.ScriptFactors)
+	}
+	raw, _ = gpt.Codec.Count("Hello there")
+	if got, _ := gpt.Count("Hello there"); got != raw {
+		t.Fatalf("latin text must not be adjusted for gpt, got %d want %d", got, raw)
+	}
+}
@@ -0,0 +1,56 @@
+package executor
+
+import (
+	"fmt"
+
+	"github.com/tidwall/sjson"
+)
+
+// TokenCount is the result of tokenizing text with the cached tokenizer of a model.
+type TokenCount struct {
+	// Encoding names the tiktoken encoding used.
+	Encoding string `json:"encoding"`
+	// Tokens is the estimate after the model's adjustment factors.
+	Tokens int `json:"tokens"`
+	// RawTokens is the unadjusted tokenizer count.
+	RawTokens int `json:"raw_tokens"`
+	// IDs holds the raw token ids when requested.
+	IDs []uint `json:"ids,omitempty"`
+}
+
+// TokenizeText counts text using the cached tokenizer for model, optionally returning token ids.
+func TokenizeText(model, text string, withIDs bool) (TokenCount, error) {
+	enc, err := getTokenizer(model)
+	if err != nil {
+		return TokenCount{}, err
+	}
+	result := TokenCount{Encoding: enc.Codec.GetName()}
+	if withIDs {
+		ids, _, errEncode := enc.Codec.Encode(text)
+		if errEncode != nil {
+			return TokenCount{}, errEncode
+		}
+		result.IDs = ids
+		result.RawTokens = len(ids)
+	} else if result.RawTokens, err = enc.Codec.Count(text); err != nil {
+		return TokenCount{}, err
+	}
+	if result.Tokens, err = enc.Count(text); err != nil {
+		return TokenCount{}, err
+	}
+	return result, nil
+}
+
+// CountMessageTokens estimates the prompt tokens of OpenAI chat completion messages for model,
+// the same way the count_tokens paths do.
+func CountMessageTokens(model string, messages []byte) (int64, error) {
+	enc, err := getTokenizer(model)
+	if err != nil {
+		return 0, err
+	}
+	payload, err := sjson.SetRawBytes([]byte(`{}`), "messages", messages)
+	if err != nil {
+		return 0, fmt.Errorf("invalid messages: %w", err)
+	}
+	return countOpenAIChatTokens(enc, payload)
+}
@@ -0,0 +1,256 @@
+// Package sampling stores a configurable fraction of prompt/response pairs for offline quality
+// evaluation. Each sample is tagged with the model and the credential that served it, so the
+// answers of different backends to the same workload can be compared. Credentials and e-mail
+// addresses are redacted before a sample is written to disk.
+package sampling
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+)
+
+const (
+	defaultDirName    = "samples"
+	defaultMaxEntries = 1000
+)
+
+// Sample is a captured prompt/response pair.
+type Sample struct {
+	ID          string    `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	HandlerType string    `json:"handler_type"`
+	// Model is the model the client requested; UpstreamModel is the one sent to the provider.
+	Model         string `json:"model"`
+	UpstreamModel string `json:"upstream_model,omitempty"`
+	Provider      string `json:"provider,omitempty"`
+	// AuthIndex identifies the serving credential the same way the management auth-files list does.
+	AuthIndex string          `json:"auth_index,omitempty"`
+	Stream    bool            `json:"stream"`
+	LatencyMS int64           `json:"latency_ms"`
+	Request   json.RawMessage `json:"request"`
+	Response  json.RawMessage `json:"response"`
+}
+
+// Sampler decides which requests are sampled and stores the samples on disk.
+type Sampler struct {
+	mu     sync.Mutex
+	rate   float64
+	dir    string
+	max    int
+	random func() float64
+}
+
+var defaultSampler = &Sampler{random: rand.Float64}
+
+// Default returns the process-wide sampler.
+func Default() *Sampler { return defaultSampler }
+
+// Configure applies cfg. Samples are stored in a "samples" directory under dataDir unless cfg
+// names a directory.
+func (s *Sampler) Configure(cfg config.SamplingConfig, dataDir string) {
+	if s == nil {
+		return
+	}
+	rate := cfg.Rate
+	if rate < 0 {
+		rate = 0
+	}
+	if rate > 1 {
+		rate = 1
+	}
+	dir := strings.TrimSpace(cfg.Dir)
+	if dir == "" && strings.TrimSpace(dataDir) != "" {
+		dir = filepath.Join(dataDir, defaultDirName)
+	}
+	maxEntries := cfg.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultMaxEntries
+	}
+	s.mu.Lock()
+	s.rate = rate
+	s.dir = dir
+	s.max = maxEntries
+	s.mu.Unlock()
+}
+
+// Rate returns the configured sampling rate.
+func (s *Sampler) Rate() float64 {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rate
+}
+
+// ShouldSample reports whether the next request is picked for sampling.
+func (s *Sampler) ShouldSample() bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rate <= 0 || s.dir == "" {
+		return false
+	}
+	return s.random() < s.rate
+}
+
+// Capture redacts and persists sample, assigning an ID and creation time. The file is written and
+// old samples are trimmed without holding the sampler lock.
+func (s *Sampler) Capture(sample Sample) (Sample, error) {
+	if s == nil {
+		return sample, errors.New("sampler unavailable")
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return sample, err
+	}
+	sample.ID = id.String()
+	sample.CreatedAt = time.Now().UTC()
+	sample.Request = Redact(sample.Request)
+	sample.Response = Redact(sample.Response)
+	data, err := json.Marshal(sample)
+	if err != nil {
+		return sample, fmt.Errorf("encode sample: %w", err)
+	}
+
+	s.mu.Lock()
+	dir, maxEntries := s.dir, s.max
+	s.mu.Unlock()
+	if dir == "" {
+		return sample, errors.New("sampling directory is not configured")
+	}
+	if err = os.MkdirAll(dir, 0o700); err != nil {
+		return sample, fmt.Errorf("create sampling directory: %w", err)
+	}
+	path := filepath.Join(dir, sample.ID+".json")
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		return sample, fmt.Errorf("write sample: %w", err)
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return sample, fmt.Errorf("write sample: %w", err)
+	}
+	trimSamples(dir, maxEntries)
+	return sample, nil
+}
+
+// List returns every stored sample, oldest first.
+func (s *Sampler) List() ([]Sample, error) {
+	if s == nil {
+		return nil, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names, err := sampleNames(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]Sample, 0, len(names))
+	for _, name := range names {
+		data, errRead := os.ReadFile(filepath.Join(s.dir, name))
+		if errRead != nil {
+			continue
+		}
+		var sample Sample
+		if errUnmarshal := json.Unmarshal(data, &sample); errUnmarshal != nil || sample.ID == "" {
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	return samples, nil
+}
+
+// Clear removes every stored sample and returns how many were removed.
+func (s *Sampler) Clear() (int, error) {
+	if s == nil {
+		return 0, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names, err := sampleNames(s.dir)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, name := range names {
+		if errRemove := os.Remove(filepath.Join(s.dir, name)); errRemove == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// sampleNames returns the sample file names in dir, oldest first. Version 7 UUIDs sort by
+// creation time.
+func sampleNames(dir string) ([]string, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || filepath.Ext(name) != ".json" {
+			continue
+		}
+		if _, errParse := uuid.Parse(strings.TrimSuffix(name, ".json")); errParse != nil {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// trimSamples drops the oldest samples in dir beyond maxEntries.
+func trimSamples(dir string, maxEntries int) {
+	names, err := sampleNames(dir)
+	if err != nil || len(names) <= maxEntries {
+		return
+	}
+	for _, name := range names[:len(names)-maxEntries] {
+		_ = os.Remove(filepath.Join(dir, name))
+	}
+}
+
+// emailPattern matches e-mail addresses in prompts and responses.
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+// Redact masks credentials and e-mail addresses in a request or response body. The result is
+// the redacted JSON, or a JSON string holding the redacted text when data is not JSON (such as
+// a captured event stream).
+func Redact(data []byte) json.RawMessage {
+	if len(data) == 0 {
+		return nil
+	}
+	text := logging.RedactSecrets(string(data))
+	text = emailPattern.ReplaceAllStringFunc(text, util.HideAPIKey)
+	if json.Valid([]byte(text)) {
+		return json.RawMessage(text)
+	}
+	raw, _ := json.Marshal(text)
+	return raw
+}
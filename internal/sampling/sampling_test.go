@@ -0,0 +1,49 @@
+package sampling
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestSamplerCaptureRedactsAndTrims(t *testing.T) {
+	s := &Sampler{random: func() float64 { return 0.3 }}
+	if s.ShouldSample() {
+		t.Fatal("an unconfigured sampler must not sample")
+	}
+	s.Configure(config.SamplingConfig{Rate: 0.25, MaxEntries: 2}, t.TempDir())
+	if s.ShouldSample() {
+		t.Fatal("a draw above the rate must not be sampled")
+	}
+	s.Configure(config.SamplingConfig{Rate: 0.5, MaxEntries: 2, Dir: s.dir}, "")
+	if !s.ShouldSample() {
+		t.Fatal("a draw below the rate must be sampled")
+	}
+
+	request := []byte(`{"messages":[{"role":"user","content":"my key is sk-abcdefghijklmnopqrstuvwxyz, mail me at jane.doe@example.com"}]}`)
+	for i := 0; i < 3; i++ {
+		if _, err := s.Capture(Sample{Model: "gpt-5", Provider: "codex", AuthIndex: "idx", Request: request, Response: []byte("data: {\"ok\":true}\n\n")}); err != nil {
+			t.Fatalf("capture: %v", err)
+		}
+	}
+
+	samples, err := s.List()
+	if err != nil || len(samples) != 2 {
+		t.Fatalf("List = %d samples, %v; want 2 after trimming", len(samples), err)
+	}
+	if samples[0].ID >= samples[1].ID {
+		t.Fatalf("samples must be listed oldest first: %s, %s", samples[0].ID, samples[1].ID)
+	}
+	stored := string(samples[1].Request)
+	if strings.Contains(stored, "sk-abcdefghijklmnopqrstuvwxyz") || strings.Contains(stored, "jane.doe@example.com") {
+		t.Fatalf("sample was not redacted: %s", stored)
+	}
+	if string(samples[1].Response) != `"data: {\"ok\":true}\n\n"` {
+		t.Fatalf("non-JSON responses must be stored as a string, got %s", samples[1].Response)
+	}
+
+	if removed, errClear := s.Clear(); errClear != nil || removed != 2 {
+		t.Fatalf("Clear = %d, %v; want 2", removed, errClear)
+	}
+}
@@ -255,7 +255,7 @@ func ConvertClaudeRequestToAntigravity(modelName string, inputRawJSON []byte, _
 							partJSON, _ = sjson.SetRaw(partJSON, "functionResponse", functionResponseJSON)
 							clientContentJSON, _ = sjson.SetRaw(clientContentJSON, "parts.-1", partJSON)
 						}
-					} else if contentTypeResult.Type == gjson.String && contentTypeResult.String() == "image" {
+					} else if contentTypeResult.Type == gjson.String && (contentTypeResult.String() == "image" || contentTypeResult.String() == "document") {
 						sourceResult := contentResult.Get("source")
 						if sourceResult.Get("type").String() == "base64" {
 							inlineDataJSON := `{}`
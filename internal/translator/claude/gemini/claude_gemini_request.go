@@ -7,14 +7,12 @@ package gemini
 
 import (
 	"bytes"
-	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
-	"math/big"
 	"strings"
 
-	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/idgen"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
@@ -48,12 +46,10 @@ func ConvertGeminiRequestToClaude(modelName string, inputRawJSON []byte, stream
 	rawJSON := bytes.Clone(inputRawJSON)
 
 	if account == "" {
-		u, _ := uuid.NewRandom()
-		account = u.String()
+		account = idgen.UUID()
 	}
 	if session == "" {
-		u, _ := uuid.NewRandom()
-		session = u.String()
+		session = idgen.UUID()
 	}
 	if user == "" {
 		sum := sha256.Sum256([]byte(account + session))
@@ -69,14 +65,7 @@ func ConvertGeminiRequestToClaude(modelName string, inputRawJSON []byte, stream
 	// Helper for generating tool call IDs in the form: toolu_<alphanum>
 	// This ensures unique identifiers for tool calls in the Claude Code format
 	genToolCallID := func() string {
-		const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-		var b strings.Builder
-		// 24 chars random suffix for uniqueness
-		for i := 0; i < 24; i++ {
-			n, _ := rand.Int(rand.Reader, big.NewInt(int64(len(letters))))
-			b.WriteByte(letters[n.Int64()])
-		}
-		return "toolu_" + b.String()
+		return "toolu_" + idgen.Alphanumeric(24)
 	}
 
 	// FIFO queue to store tool call IDs for matching with tool results
@@ -185,9 +174,12 @@ func ConvertGeminiRequestToClaude(modelName string, inputRawJSON []byte, stream
 					if fc := part.Get("functionCall"); fc.Exists() && role == "assistant" {
 						toolUse := `{"type":"tool_use","id":"","name":"","input":{}}`
 
-						// Generate a unique tool ID and enqueue it for later matching
-						// with the corresponding functionResponse
-						toolID := genToolCallID()
+						// Use the call's id when present, otherwise generate a unique tool ID,
+						// and enqueue it for later matching with the corresponding functionResponse
+						toolID := fc.Get("id").String()
+						if toolID == "" {
+							toolID = genToolCallID()
+						}
 						pendingToolIDs = append(pendingToolIDs, toolID)
 						toolUse, _ = sjson.Set(toolUse, "id", toolID)
 
@@ -205,10 +197,17 @@ func ConvertGeminiRequestToClaude(modelName string, inputRawJSON []byte, stream
 					if fr := part.Get("functionResponse"); fr.Exists() {
 						toolResult := `{"type":"tool_result","tool_use_id":"","content":""}`
 
-						// Attach the oldest queued tool_id to pair the response
-						// with its call. If the queue is empty, generate a new id.
-						var toolID string
-						if len(pendingToolIDs) > 0 {
+						// Pair the response with the call carrying the same id, otherwise attach
+						// the oldest queued tool_id. If the queue is empty, generate a new id.
+						toolID := fr.Get("id").String()
+						if toolID != "" {
+							for i, pendingID := range pendingToolIDs {
+								if pendingID == toolID {
+									pendingToolIDs = append(pendingToolIDs[:i], pendingToolIDs[i+1:]...)
+									break
+								}
+							}
+						} else if len(pendingToolIDs) > 0 {
 							toolID = pendingToolIDs[0]
 							// Pop the first element from the queue
 							pendingToolIDs = pendingToolIDs[1:]
@@ -13,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/idgen"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -86,7 +87,7 @@ func ConvertClaudeResponseToGemini(_ context.Context, modelName string, original
 
 	// Set creation time to current time if not provided
 	if (*param).(*ConvertAnthropicResponseToGeminiParams).CreatedAt == 0 {
-		(*param).(*ConvertAnthropicResponseToGeminiParams).CreatedAt = time.Now().Unix()
+		(*param).(*ConvertAnthropicResponseToGeminiParams).CreatedAt = idgen.Now().Unix()
 	}
 	template, _ = sjson.Set(template, "createTime", time.Unix((*param).(*ConvertAnthropicResponseToGeminiParams).CreatedAt, 0).Format(time.RFC3339Nano))
 
@@ -333,7 +334,7 @@ func ConvertClaudeResponseToGeminiNonStream(_ context.Context, modelName string,
 				newParam.Model = message.Get("model").String()
 
 				// Set creation time to current time if not provided
-				createdAt = time.Now().Unix()
+				createdAt = idgen.Now().Unix()
 				newParam.CreatedAt = createdAt
 			}
 
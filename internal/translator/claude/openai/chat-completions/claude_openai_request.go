@@ -7,14 +7,12 @@ package chat_completions
 
 import (
 	"bytes"
-	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
-	"math/big"
 	"strings"
 
-	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/idgen"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
@@ -47,12 +45,10 @@ func ConvertOpenAIRequestToClaude(modelName string, inputRawJSON []byte, stream
 	rawJSON := bytes.Clone(inputRawJSON)
 
 	if account == "" {
-		u, _ := uuid.NewRandom()
-		account = u.String()
+		account = idgen.UUID()
 	}
 	if session == "" {
-		u, _ := uuid.NewRandom()
-		session = u.String()
+		session = idgen.UUID()
 	}
 	if user == "" {
 		sum := sha256.Sum256([]byte(account + session))
@@ -88,14 +84,7 @@ func ConvertOpenAIRequestToClaude(modelName string, inputRawJSON []byte, stream
 	// Helper for generating tool call IDs in the form: toolu_<alphanum>
 	// This ensures unique identifiers for tool calls in the Claude Code format
 	genToolCallID := func() string {
-		const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-		var b strings.Builder
-		// 24 chars random suffix for uniqueness
-		for i := 0; i < 24; i++ {
-			n, _ := rand.Int(rand.Reader, big.NewInt(int64(len(letters))))
-			b.WriteByte(letters[n.Int64()])
-		}
-		return "toolu_" + b.String()
+		return "toolu_" + idgen.Alphanumeric(24)
 	}
 
 	// Model mapping to specify which Claude Code model to use
@@ -151,6 +140,29 @@ func ConvertOpenAIRequestToClaude(modelName string, inputRawJSON []byte, stream
 				msg := `{"role":"","content":[]}`
 				msg, _ = sjson.Set(msg, "role", role)
 
+				// Replay thinking blocks from a previous response first; Claude verifies their
+				// signatures when extended thinking is combined with tool use.
+				if role == "assistant" {
+					message.Get("thinking_blocks").ForEach(func(_, block gjson.Result) bool {
+						switch block.Get("type").String() {
+						case "thinking":
+							if signature := block.Get("signature").String(); signature != "" {
+								thinkingPart := `{"type":"thinking","thinking":"","signature":""}`
+								thinkingPart, _ = sjson.Set(thinkingPart, "thinking", block.Get("thinking").String())
+								thinkingPart, _ = sjson.Set(thinkingPart, "signature", signature)
+								msg, _ = sjson.SetRaw(msg, "content.-1", thinkingPart)
+							}
+						case "redacted_thinking":
+							if data := block.Get("data").String(); data != "" {
+								redactedPart := `{"type":"redacted_thinking","data":""}`
+								redactedPart, _ = sjson.Set(redactedPart, "data", data)
+								msg, _ = sjson.SetRaw(msg, "content.-1", redactedPart)
+							}
+						}
+						return true
+					})
+				}
+
 				// Handle content based on its type (string or array)
 				if contentResult.Exists() && contentResult.Type == gjson.String && contentResult.String() != "" {
 					part := `{"type":"text","text":""}`
@@ -10,8 +10,8 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/idgen"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -27,6 +27,8 @@ type ConvertAnthropicResponseToOpenAIParams struct {
 	FinishReason string
 	// Tool calls accumulator for streaming
 	ToolCallsAccumulator map[int]*ToolCallAccumulator
+	// ThinkingText accumulates the current thinking block so it can be returned whole with its signature
+	ThinkingText strings.Builder
 }
 
 // ToolCallAccumulator holds the state for accumulating tool call data
@@ -91,7 +93,7 @@ func ConvertClaudeResponseToOpenAI(_ context.Context, modelName string, original
 		// Initialize response with message metadata when a new message begins
 		if message := root.Get("message"); message.Exists() {
 			(*param).(*ConvertAnthropicResponseToOpenAIParams).ResponseID = message.Get("id").String()
-			(*param).(*ConvertAnthropicResponseToOpenAIParams).CreatedAt = time.Now().Unix()
+			(*param).(*ConvertAnthropicResponseToOpenAIParams).CreatedAt = idgen.Now().Unix()
 
 			template, _ = sjson.Set(template, "id", (*param).(*ConvertAnthropicResponseToOpenAIParams).ResponseID)
 			template, _ = sjson.Set(template, "model", modelName)
@@ -112,6 +114,17 @@ func ConvertClaudeResponseToOpenAI(_ context.Context, modelName string, original
 		if contentBlock := root.Get("content_block"); contentBlock.Exists() {
 			blockType := contentBlock.Get("type").String()
 
+			switch blockType {
+			case "thinking":
+				(*param).(*ConvertAnthropicResponseToOpenAIParams).ThinkingText.Reset()
+				return []string{}
+			case "redacted_thinking":
+				block := `{"type":"redacted_thinking","data":""}`
+				block, _ = sjson.Set(block, "data", contentBlock.Get("data").String())
+				template, _ = sjson.SetRaw(template, "choices.0.delta.thinking_blocks", "["+block+"]")
+				return []string{template}
+			}
+
 			if blockType == "tool_use" {
 				// Start of tool call - initialize accumulator to track arguments
 				toolCallID := contentBlock.Get("id").String()
@@ -150,6 +163,16 @@ func ConvertClaudeResponseToOpenAI(_ context.Context, modelName string, original
 				// Accumulate reasoning/thinking content
 				if thinking := delta.Get("thinking"); thinking.Exists() {
 					template, _ = sjson.Set(template, "choices.0.delta.reasoning_content", thinking.String())
+					(*param).(*ConvertAnthropicResponseToOpenAIParams).ThinkingText.WriteString(thinking.String())
+					hasContent = true
+				}
+			case "signature_delta":
+				// Return the completed thinking block with its signature so clients can replay it
+				if signature := delta.Get("signature").String(); signature != "" {
+					block := `{"type":"thinking","thinking":"","signature":""}`
+					block, _ = sjson.Set(block, "thinking", (*param).(*ConvertAnthropicResponseToOpenAIParams).ThinkingText.String())
+					block, _ = sjson.Set(block, "signature", signature)
+					template, _ = sjson.SetRaw(template, "choices.0.delta.thinking_blocks", "["+block+"]")
 					hasContent = true
 				}
 			case "input_json_delta":
@@ -291,6 +314,9 @@ func ConvertClaudeResponseToOpenAINonStream(_ context.Context, _ string, origina
 	var stopReason string
 	var contentParts []string
 	var reasoningParts []string
+	var thinkingBlocks []string
+	var thinkingText strings.Builder
+	thinkingBlockIndex := -1
 	toolCallsAccumulator := make(map[int]*ToolCallAccumulator)
 
 	for _, chunk := range chunks {
@@ -303,7 +329,7 @@ func ConvertClaudeResponseToOpenAINonStream(_ context.Context, _ string, origina
 			if message := root.Get("message"); message.Exists() {
 				messageID = message.Get("id").String()
 				model = message.Get("model").String()
-				createdAt = time.Now().Unix()
+				createdAt = idgen.Now().Unix()
 			}
 
 		case "content_block_start":
@@ -311,8 +337,15 @@ func ConvertClaudeResponseToOpenAINonStream(_ context.Context, _ string, origina
 			if contentBlock := root.Get("content_block"); contentBlock.Exists() {
 				blockType := contentBlock.Get("type").String()
 				if blockType == "thinking" {
-					// Start of thinking/reasoning content - skip for now as it's handled in delta
+					// Thinking text is handled in deltas; the block is kept for its signature
+					thinkingBlocks = append(thinkingBlocks, `{"type":"thinking","thinking":"","signature":""}`)
+					thinkingBlockIndex = len(thinkingBlocks) - 1
+					thinkingText.Reset()
 					continue
+				} else if blockType == "redacted_thinking" {
+					block, _ := sjson.Set(`{"type":"redacted_thinking","data":""}`, "data", contentBlock.Get("data").String())
+					thinkingBlocks = append(thinkingBlocks, block)
+					thinkingBlockIndex = -1
 				} else if blockType == "tool_use" {
 					// Initialize tool call accumulator for this index
 					index := int(root.Get("index").Int())
@@ -337,6 +370,11 @@ func ConvertClaudeResponseToOpenAINonStream(_ context.Context, _ string, origina
 					// Accumulate reasoning/thinking content
 					if thinking := delta.Get("thinking"); thinking.Exists() {
 						reasoningParts = append(reasoningParts, thinking.String())
+						thinkingText.WriteString(thinking.String())
+					}
+				case "signature_delta":
+					if thinkingBlockIndex >= 0 {
+						thinkingBlocks[thinkingBlockIndex], _ = sjson.Set(thinkingBlocks[thinkingBlockIndex], "signature", delta.Get("signature").String())
 					}
 				case "input_json_delta":
 					// Accumulate tool call arguments
@@ -356,6 +394,9 @@ func ConvertClaudeResponseToOpenAINonStream(_ context.Context, _ string, origina
 				if accumulator.Arguments.Len() == 0 {
 					accumulator.Arguments.WriteString("{}")
 				}
+			} else if thinkingBlockIndex >= 0 {
+				thinkingBlocks[thinkingBlockIndex], _ = sjson.Set(thinkingBlocks[thinkingBlockIndex], "thinking", thinkingText.String())
+				thinkingBlockIndex = -1
 			}
 
 		case "message_delta":
@@ -394,6 +435,11 @@ func ConvertClaudeResponseToOpenAINonStream(_ context.Context, _ string, origina
 		out, _ = sjson.Set(out, "choices.0.message.reasoning", reasoningContent)
 	}
 
+	// Return thinking blocks with their signatures so clients can replay them on the next turn
+	if len(thinkingBlocks) > 0 {
+		out, _ = sjson.SetRaw(out, "choices.0.message.thinking_blocks", "["+strings.Join(thinkingBlocks, ",")+"]")
+	}
+
 	// Set tool calls if any were accumulated during processing
 	if len(toolCallsAccumulator) > 0 {
 		toolCallsCount := 0
@@ -0,0 +1,65 @@
+package chat_completions
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+var thinkingEvents = []string{
+	`data: {"type":"message_start","message":{"id":"msg_1","model":"claude"}}`,
+	`data: {"type":"content_block_start","index":0,"content_block":{"type":"thinking","thinking":""}}`,
+	`data: {"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"Let me "}}`,
+	`data: {"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"think."}}`,
+	`data: {"type":"content_block_delta","index":0,"delta":{"type":"signature_delta","signature":"sig-1"}}`,
+	`data: {"type":"content_block_stop","index":0}`,
+	`data: {"type":"content_block_start","index":1,"content_block":{"type":"redacted_thinking","data":"opaque"}}`,
+	`data: {"type":"content_block_stop","index":1}`,
+	`data: {"type":"message_delta","delta":{"stop_reason":"end_turn"}}`,
+}
+
+func TestConvertClaudeResponseToOpenAI_ThinkingBlocks(t *testing.T) {
+	out := ConvertClaudeResponseToOpenAINonStream(context.Background(), "m", nil, nil, []byte(strings.Join(thinkingEvents, "\n")), nil)
+	blocks := gjson.Get(out, "choices.0.message.thinking_blocks").Array()
+	if len(blocks) != 2 {
+		t.Fatalf("expected 2 thinking blocks, got %s", out)
+	}
+	if blocks[0].Get("thinking").String() != "Let me think." || blocks[0].Get("signature").String() != "sig-1" {
+		t.Errorf("unexpected thinking block %s", blocks[0].Raw)
+	}
+	if blocks[1].Get("type").String() != "redacted_thinking" || blocks[1].Get("data").String() != "opaque" {
+		t.Errorf("unexpected redacted block %s", blocks[1].Raw)
+	}
+
+	var param any
+	var streamed []gjson.Result
+	for _, event := range thinkingEvents {
+		for _, chunk := range ConvertClaudeResponseToOpenAI(context.Background(), "m", nil, nil, []byte(event), &param) {
+			streamed = append(streamed, gjson.Get(chunk, "choices.0.delta.thinking_blocks").Array()...)
+		}
+	}
+	if len(streamed) != 2 || streamed[0].Get("thinking").String() != "Let me think." || streamed[0].Get("signature").String() != "sig-1" {
+		t.Fatalf("unexpected streamed thinking blocks %v", streamed)
+	}
+}
+
+func TestConvertOpenAIRequestToClaude_ReplaysThinkingBlocks(t *testing.T) {
+	input := `{"model":"m","messages":[
+		{"role":"user","content":"hi"},
+		{"role":"assistant","content":"","thinking_blocks":[{"type":"thinking","thinking":"plan","signature":"sig-1"},{"type":"redacted_thinking","data":"opaque"},{"type":"thinking","thinking":"unsigned"}],
+		 "tool_calls":[{"id":"call_1","type":"function","function":{"name":"ls","arguments":"{}"}}]},
+		{"role":"tool","tool_call_id":"call_1","content":"files"}]}`
+	out := ConvertOpenAIRequestToClaude("m", []byte(input), false)
+	content := gjson.GetBytes(out, "messages.1.content").Array()
+	if len(content) != 3 {
+		t.Fatalf("expected thinking, redacted_thinking and tool_use blocks, got %s", gjson.GetBytes(out, "messages.1.content").Raw)
+	}
+	if content[0].Get("type").String() != "thinking" || content[0].Get("signature").String() != "sig-1" {
+		t.Errorf("unexpected first block %s", content[0].Raw)
+	}
+	if content[1].Get("type").String() != "redacted_thinking" || content[2].Get("type").String() != "tool_use" {
+		t.Errorf("unexpected block order %v", content)
+	}
+}
@@ -2,14 +2,12 @@ package responses
 
 import (
 	"bytes"
-	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
-	"math/big"
 	"strings"
 
-	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/idgen"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
@@ -35,12 +33,10 @@ func ConvertOpenAIResponsesRequestToClaude(modelName string, inputRawJSON []byte
 	rawJSON := bytes.Clone(inputRawJSON)
 
 	if account == "" {
-		u, _ := uuid.NewRandom()
-		account = u.String()
+		account = idgen.UUID()
 	}
 	if session == "" {
-		u, _ := uuid.NewRandom()
-		session = u.String()
+		session = idgen.UUID()
 	}
 	if user == "" {
 		sum := sha256.Sum256([]byte(account + session))
@@ -75,13 +71,7 @@ func ConvertOpenAIResponsesRequestToClaude(modelName string, inputRawJSON []byte
 
 	// Helper for generating tool call IDs when missing
 	genToolCallID := func() string {
-		const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-		var b strings.Builder
-		for i := 0; i < 24; i++ {
-			n, _ := rand.Int(rand.Reader, big.NewInt(int64(len(letters))))
-			b.WriteByte(letters[n.Int64()])
-		}
-		return "toolu_" + b.String()
+		return "toolu_" + idgen.Alphanumeric(24)
 	}
 
 	// Model
@@ -6,8 +6,8 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/idgen"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -76,7 +76,7 @@ func ConvertClaudeResponseToOpenAIResponses(ctx context.Context, modelName strin
 	case "message_start":
 		if msg := root.Get("message"); msg.Exists() {
 			st.ResponseID = msg.Get("id").String()
-			st.CreatedAt = time.Now().Unix()
+			st.CreatedAt = idgen.Now().Unix()
 			// Reset per-message aggregation state
 			st.TextBuf.Reset()
 			st.ReasoningBuf.Reset()
@@ -488,7 +488,7 @@ func ConvertClaudeResponseToOpenAIResponsesNonStream(_ context.Context, _ string
 		case "message_start":
 			if msg := root.Get("message"); msg.Exists() {
 				responseID = msg.Get("id").String()
-				createdAt = time.Now().Unix()
+				createdAt = idgen.Now().Unix()
 				if usage := msg.Get("usage"); usage.Exists() {
 					inputTokens = usage.Get("input_tokens").Int()
 				}
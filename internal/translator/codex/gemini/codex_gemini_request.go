@@ -7,13 +7,12 @@ package gemini
 
 import (
 	"bytes"
-	"crypto/rand"
 	"fmt"
-	"math/big"
 	"strconv"
 	"strings"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/idgen"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
@@ -77,14 +76,7 @@ func ConvertGeminiRequestToCodex(modelName string, inputRawJSON []byte, _ bool)
 
 	// genCallID creates a random call id like: call_<8chars>
 	genCallID := func() string {
-		const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-		var b strings.Builder
-		// 8 chars random suffix
-		for i := 0; i < 24; i++ {
-			n, _ := rand.Int(rand.Reader, big.NewInt(int64(len(letters))))
-			b.WriteByte(letters[n.Int64()])
-		}
-		return "call_" + b.String()
+		return "call_" + idgen.Alphanumeric(24)
 	}
 
 	// Model
@@ -158,10 +150,13 @@ func ConvertGeminiRequestToCodex(modelName string, inputRawJSON []byte, _ bool)
 					if args := fc.Get("args"); args.Exists() {
 						fn, _ = sjson.Set(fn, "arguments", args.Raw)
 					}
-					// generate a paired random call_id and enqueue it so the
-					// corresponding functionResponse can pop the earliest id
-					// to preserve ordering when multiple calls are present.
-					id := genCallID()
+					// use the call's id or generate a paired random call_id and
+					// enqueue it so the corresponding functionResponse can pop the
+					// earliest id to preserve ordering when multiple calls are present.
+					id := fc.Get("id").String()
+					if id == "" {
+						id = genCallID()
+					}
 					fn, _ = sjson.Set(fn, "call_id", id)
 					pendingCallIDs = append(pendingCallIDs, id)
 					out, _ = sjson.SetRaw(out, "input.-1", fn)
@@ -178,10 +173,17 @@ func ConvertGeminiRequestToCodex(modelName string, inputRawJSON []byte, _ bool)
 						fno, _ = sjson.Set(fno, "output", resp.Raw)
 					}
 					// fno, _ = sjson.Set(fno, "call_id", "call_W6nRJzFXyPM2LFBbfo98qAbq")
-					// attach the oldest queued call_id to pair the response
-					// with its call. If the queue is empty, generate a new id.
-					var id string
-					if len(pendingCallIDs) > 0 {
+					// pair the response with the call carrying the same id, otherwise
+					// attach the oldest queued call_id. If the queue is empty, generate a new id.
+					id := fr.Get("id").String()
+					if id != "" {
+						for i, pendingID := range pendingCallIDs {
+							if pendingID == id {
+								pendingCallIDs = append(pendingCallIDs[:i], pendingCallIDs[i+1:]...)
+								break
+							}
+						}
+					} else if len(pendingCallIDs) > 0 {
 						id = pendingCallIDs[0]
 						// pop the first element
 						pendingCallIDs = pendingCallIDs[1:]
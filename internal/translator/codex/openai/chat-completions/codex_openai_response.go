@@ -8,8 +8,8 @@ package chat_completions
 import (
 	"bytes"
 	"context"
-	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/idgen"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -171,7 +171,7 @@ func ConvertCodexResponseToOpenAINonStream(_ context.Context, _ string, original
 		return ""
 	}
 
-	unixTimestamp := time.Now().Unix()
+	unixTimestamp := idgen.Now().Unix()
 
 	responseResult := rootResult.Get("response")
 
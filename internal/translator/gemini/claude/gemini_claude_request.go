@@ -83,6 +83,24 @@ func ConvertClaudeRequestToGemini(modelName string, inputRawJSON []byte, _ bool)
 						part, _ = sjson.Set(part, "text", contentResult.Get("text").String())
 						contentJSON, _ = sjson.SetRaw(contentJSON, "parts.-1", part)
 
+					case "document":
+						source := contentResult.Get("source")
+						switch source.Get("type").String() {
+						case "base64":
+							mimeType := source.Get("media_type").String()
+							if mimeType == "" {
+								mimeType = "application/pdf"
+							}
+							part := `{"inlineData":{"mimeType":"","data":""}}`
+							part, _ = sjson.Set(part, "inlineData.mimeType", mimeType)
+							part, _ = sjson.Set(part, "inlineData.data", source.Get("data").String())
+							contentJSON, _ = sjson.SetRaw(contentJSON, "parts.-1", part)
+						case "text":
+							part := `{"text":""}`
+							part, _ = sjson.Set(part, "text", source.Get("data").String())
+							contentJSON, _ = sjson.SetRaw(contentJSON, "parts.-1", part)
+						}
+
 					case "tool_use":
 						functionName := contentResult.Get("name").String()
 						functionArgs := contentResult.Get("input").String()
@@ -5,9 +5,9 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"sync/atomic"
 	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/idgen"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -39,12 +39,6 @@ type geminiToResponsesState struct {
 	FuncCallIDs map[int]string
 }
 
-// responseIDCounter provides a process-wide unique counter for synthesized response identifiers.
-var responseIDCounter uint64
-
-// funcCallIDCounter provides a process-wide unique counter for function call identifiers.
-var funcCallIDCounter uint64
-
 func emitEvent(event string, payload string) string {
 	return fmt.Sprintf("event: %s\ndata: %s", event, payload)
 }
@@ -115,7 +109,7 @@ func ConvertGeminiResponseToOpenAIResponses(_ context.Context, modelName string,
 			}
 		}
 		if st.CreatedAt == 0 {
-			st.CreatedAt = time.Now().Unix()
+			st.CreatedAt = idgen.Now().Unix()
 		}
 
 		created := `{"type":"response.created","sequence_number":0,"response":{"id":"","object":"response","created_at":0,"status":"in_progress","background":false,"error":null,"output":[]}}`
@@ -215,7 +209,7 @@ func ConvertGeminiResponseToOpenAIResponses(_ context.Context, modelName string,
 					st.FuncArgsBuf[idx] = &strings.Builder{}
 				}
 				if st.FuncCallIDs[idx] == "" {
-					st.FuncCallIDs[idx] = fmt.Sprintf("call_%d_%d", time.Now().UnixNano(), atomic.AddUint64(&funcCallIDCounter, 1))
+					st.FuncCallIDs[idx] = fmt.Sprintf("call_%d_%d", idgen.Now().UnixNano(), idgen.Seq())
 				}
 				st.FuncNames[idx] = name
 
@@ -467,7 +461,7 @@ func ConvertGeminiResponseToOpenAIResponsesNonStream(_ context.Context, _ string
 	// id: prefer provider responseId, otherwise synthesize
 	id := root.Get("responseId").String()
 	if id == "" {
-		id = fmt.Sprintf("resp_%x_%d", time.Now().UnixNano(), atomic.AddUint64(&responseIDCounter, 1))
+		id = fmt.Sprintf("resp_%x_%d", idgen.Now().UnixNano(), idgen.Seq())
 	}
 	// Normalize to response-style id (prefix resp_ if missing)
 	if !strings.HasPrefix(id, "resp_") {
@@ -476,7 +470,7 @@ func ConvertGeminiResponseToOpenAIResponsesNonStream(_ context.Context, _ string
 	resp, _ = sjson.Set(resp, "id", id)
 
 	// created_at: map from createTime if available
-	createdAt := time.Now().Unix()
+	createdAt := idgen.Now().Unix()
 	if v := root.Get("createTime"); v.Exists() {
 		if t, err := time.Parse(time.RFC3339Nano, v.String()); err == nil {
 			createdAt = t.Unix()
@@ -591,7 +585,7 @@ func ConvertGeminiResponseToOpenAIResponsesNonStream(_ context.Context, _ string
 			if fc := p.Get("functionCall"); fc.Exists() {
 				name := fc.Get("name").String()
 				args := fc.Get("args")
-				callID := fmt.Sprintf("call_%x_%d", time.Now().UnixNano(), atomic.AddUint64(&funcCallIDCounter, 1))
+				callID := fmt.Sprintf("call_%x_%d", idgen.Now().UnixNano(), idgen.Seq())
 				itemJSON := `{"id":"","type":"function_call","status":"completed","arguments":"","call_id":"","name":""}`
 				itemJSON, _ = sjson.Set(itemJSON, "id", fmt.Sprintf("fc_%s", callID))
 				itemJSON, _ = sjson.Set(itemJSON, "call_id", callID)
@@ -0,0 +1,20 @@
+package responses
+
+import (
+	"bytes"
+
+	"github.com/tidwall/sjson"
+)
+
+// ConvertOpenAIResponsesRequestToGitHubCopilot prepares an OpenAI Responses request for the
+// Copilot responses endpoint. Copilot speaks the Responses API natively but does not store
+// responses, so requests are always streamed and never stored; the executor rejects requests that
+// chain on a stored response.
+func ConvertOpenAIResponsesRequestToGitHubCopilot(modelName string, inputRawJSON []byte, _ bool) []byte {
+	rawJSON := bytes.Clone(inputRawJSON)
+	rawJSON, _ = sjson.SetBytes(rawJSON, "model", modelName)
+	rawJSON, _ = sjson.SetBytes(rawJSON, "stream", true)
+	rawJSON, _ = sjson.SetBytes(rawJSON, "store", false)
+	rawJSON, _ = sjson.DeleteBytes(rawJSON, "service_tier")
+	return rawJSON
+}
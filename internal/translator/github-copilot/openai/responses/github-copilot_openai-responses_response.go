@@ -0,0 +1,37 @@
+package responses
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/tidwall/gjson"
+)
+
+// ConvertGitHubCopilotResponseToOpenAIResponses forwards the Responses SSE events of the Copilot
+// API. Each data line becomes one named event; event and keep-alive lines are dropped since the
+// event name is carried by the payload type.
+func ConvertGitHubCopilotResponseToOpenAIResponses(_ context.Context, _ string, _, _, rawJSON []byte, _ *any) []string {
+	if !bytes.HasPrefix(rawJSON, []byte("data:")) {
+		return nil
+	}
+	rawJSON = bytes.TrimSpace(rawJSON[5:])
+	if len(rawJSON) == 0 || bytes.Equal(rawJSON, []byte("[DONE]")) {
+		return nil
+	}
+	eventType := gjson.GetBytes(rawJSON, "type").String()
+	if eventType == "" {
+		return []string{fmt.Sprintf("data: %s", rawJSON)}
+	}
+	return []string{fmt.Sprintf("event: %s\ndata: %s", eventType, rawJSON)}
+}
+
+// ConvertGitHubCopilotResponseToOpenAIResponsesNonStream returns the response carried by a
+// response.completed event.
+func ConvertGitHubCopilotResponseToOpenAIResponsesNonStream(_ context.Context, _ string, _, _, rawJSON []byte, _ *any) string {
+	rootResult := gjson.ParseBytes(rawJSON)
+	if rootResult.Get("type").String() != "response.completed" {
+		return ""
+	}
+	return rootResult.Get("response").Raw
+}
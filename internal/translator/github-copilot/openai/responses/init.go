@@ -0,0 +1,19 @@
+package responses
+
+import (
+	. "github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/translator"
+)
+
+func init() {
+	translator.Register(
+		OpenaiResponse,
+		GitHubCopilotResponses,
+		ConvertOpenAIResponsesRequestToGitHubCopilot,
+		interfaces.TranslateResponse{
+			Stream:    ConvertGitHubCopilotResponseToOpenAIResponses,
+			NonStream: ConvertGitHubCopilotResponseToOpenAIResponsesNonStream,
+		},
+	)
+}
@@ -0,0 +1,149 @@
+// Package idgen supplies the identifiers and timestamps translators stamp into synthesized
+// payloads: chatcmpl-, msg_, resp_ and tool call IDs, created timestamps and session UUIDs.
+// Production uses random values and the wall clock. Tests and replay harnesses install a
+// deterministic Source so translated output is reproducible byte for byte.
+package idgen
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	mrand "math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeterministicEnv enables the deterministic source for the whole process when set to a true
+// value, so recorded sessions can be replayed against a running server and diffed.
+const DeterministicEnv = "CLIPROXY_DETERMINISTIC_IDS"
+
+const alphanumeric = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// Source produces identifiers and timestamps.
+type Source interface {
+	// Now returns the current time.
+	Now() time.Time
+	// UUID returns a UUID in canonical string form.
+	UUID() string
+	// Seq returns the next value of a process-wide counter, starting at 1.
+	Seq() uint64
+	// Intn returns a uniformly distributed integer in [0, n).
+	Intn(n int) int
+}
+
+type sourceHolder struct{ Source }
+
+var current atomic.Value
+
+func init() {
+	current.Store(sourceHolder{newRandomSource()})
+	if enabled, _ := strconv.ParseBool(strings.TrimSpace(os.Getenv(DeterministicEnv))); enabled {
+		SetSource(NewDeterministic(time.Time{}))
+	}
+}
+
+// SetSource installs source for all subsequent calls and returns a function restoring the
+// previous one, typically deferred or registered with t.Cleanup.
+func SetSource(source Source) (restore func()) {
+	previous := current.Load().(sourceHolder)
+	current.Store(sourceHolder{source})
+	return func() { current.Store(previous) }
+}
+
+func load() Source {
+	return current.Load().(sourceHolder).Source
+}
+
+// Now returns the current time of the installed source.
+func Now() time.Time { return load().Now() }
+
+// UUID returns a UUID string from the installed source.
+func UUID() string { return load().UUID() }
+
+// Seq returns the next counter value of the installed source.
+func Seq() uint64 { return load().Seq() }
+
+// Alphanumeric returns n characters drawn from [a-zA-Z0-9].
+func Alphanumeric(n int) string {
+	source := load()
+	var b strings.Builder
+	b.Grow(n)
+	for i := 0; i < n; i++ {
+		b.WriteByte(alphanumeric[source.Intn(len(alphanumeric))])
+	}
+	return b.String()
+}
+
+// randomSource is the production source.
+type randomSource struct {
+	seq atomic.Uint64
+}
+
+func newRandomSource() *randomSource { return &randomSource{} }
+
+func (s *randomSource) Now() time.Time { return time.Now() }
+
+func (s *randomSource) UUID() string { return uuid.NewString() }
+
+func (s *randomSource) Seq() uint64 { return s.seq.Add(1) }
+
+func (s *randomSource) Intn(n int) int {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return mrand.Intn(n)
+	}
+	return int(v.Int64())
+}
+
+// DefaultDeterministicTime is the clock of a deterministic source created with a zero start.
+var DefaultDeterministicTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// deterministicSource returns a fixed time, a sequential counter and UUIDs and random values
+// from a fixed-seed stream.
+type deterministicSource struct {
+	now time.Time
+
+	mu   sync.Mutex
+	seq  uint64
+	rand *mrand.Rand
+}
+
+// NewDeterministic returns a Source whose clock is frozen at start (DefaultDeterministicTime
+// when zero) and whose IDs depend only on the order of calls.
+func NewDeterministic(start time.Time) Source {
+	if start.IsZero() {
+		start = DefaultDeterministicTime
+	}
+	return &deterministicSource{now: start, rand: mrand.New(mrand.NewSource(1))}
+}
+
+func (s *deterministicSource) Now() time.Time { return s.now }
+
+func (s *deterministicSource) UUID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, err := uuid.NewRandomFromReader(s.rand)
+	if err != nil {
+		panic(fmt.Sprintf("idgen: deterministic uuid: %v", err))
+	}
+	return id.String()
+}
+
+func (s *deterministicSource) Seq() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	return s.seq
+}
+
+func (s *deterministicSource) Intn(n int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rand.Intn(n)
+}
@@ -0,0 +1,57 @@
+package idgen_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	geminiopenai "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/openai/chat-completions"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/idgen"
+	kiroopenai "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/kiro/openai"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+func TestDeterministicSourceIsReproducible(t *testing.T) {
+	draw := func() []string {
+		defer idgen.SetSource(idgen.NewDeterministic(idgen.DefaultDeterministicTime))()
+		return []string{idgen.UUID(), idgen.UUID(), idgen.Alphanumeric(24), idgen.Now().String()}
+	}
+	first, second := draw(), draw()
+	if strings.Join(first, "|") != strings.Join(second, "|") {
+		t.Fatalf("deterministic draws differ:\n%v\n%v", first, second)
+	}
+	if first[0] == first[1] || first[0][:24] == first[1][:24] {
+		t.Fatalf("successive UUIDs must differ, including their prefixes: %v", first[:2])
+	}
+}
+
+func TestTranslatorOutputIsStableInDeterministicMode(t *testing.T) {
+	translate := func() []byte {
+		defer idgen.SetSource(idgen.NewDeterministic(idgen.DefaultDeterministicTime))()
+		var out bytes.Buffer
+		out.Write(kiroopenai.BuildOpenAIResponse("hello", nil, "kiro-model", usage.Detail{}, "end_turn"))
+		var param any
+		chunk := []byte(`{"response":{"candidates":[{"content":{"parts":[{"functionCall":{"name":"lookup","args":{"q":"x"}}}]}}]}}`)
+		for _, line := range geminiopenai.ConvertGeminiResponseToOpenAI(context.Background(), "gemini-model", nil, nil, chunk, &param) {
+			out.WriteString(line)
+		}
+		return out.Bytes()
+	}
+	first, second := translate(), translate()
+	if !bytes.Equal(first, second) {
+		t.Fatalf("translated output differs between runs:\n%s\n%s", first, second)
+	}
+	if !bytes.Contains(first, []byte(`"created":1735689600`)) {
+		t.Fatalf("created timestamp must come from the deterministic clock:\n%s", first)
+	}
+}
+
+func TestRandomSourceIsDefault(t *testing.T) {
+	if idgen.UUID() == idgen.UUID() {
+		t.Fatalf("random source must not repeat UUIDs")
+	}
+	if a, b := idgen.Seq(), idgen.Seq(); b <= a {
+		t.Fatalf("Seq must increase, got %d then %d", a, b)
+	}
+}
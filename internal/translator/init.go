@@ -34,6 +34,9 @@ import (
 	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/antigravity/openai/chat-completions"
 	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/antigravity/openai/responses"
 
+	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/github-copilot/openai/responses"
+
 	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/kiro/claude"
+	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/kiro/gemini"
 	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/kiro/openai"
 )
@@ -0,0 +1,51 @@
+package ir
+
+// Feature is a request capability a provider may or may not support.
+type Feature string
+
+const (
+	FeatureVision   Feature = "vision"
+	FeatureTools    Feature = "tools"
+	FeatureThinking Feature = "thinking"
+	FeatureJSONMode Feature = "json_mode"
+)
+
+// AllFeatures lists every feature in a stable order.
+var AllFeatures = []Feature{FeatureVision, FeatureTools, FeatureThinking, FeatureJSONMode}
+
+// Features returns the capabilities the request needs from the provider serving it.
+func (r *Request) Features() []Feature {
+	if r == nil {
+		return nil
+	}
+	var features []Feature
+	if r.hasPart(PartImage) {
+		features = append(features, FeatureVision)
+	}
+	if len(r.Tools) > 0 || r.hasPart(PartToolCall) || r.hasPart(PartToolResult) {
+		features = append(features, FeatureTools)
+	}
+	if r.Thinking != nil {
+		features = append(features, FeatureThinking)
+	}
+	if r.ResponseFormat == "json_object" || r.ResponseFormat == "json_schema" {
+		features = append(features, FeatureJSONMode)
+	}
+	return features
+}
+
+func (r *Request) hasPart(partType PartType) bool {
+	for _, part := range r.System {
+		if part.Type == partType {
+			return true
+		}
+	}
+	for _, message := range r.Messages {
+		for _, part := range message.Parts {
+			if part.Type == partType {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+// Package ir normalizes chat requests of every ingress format (OpenAI chat completions, OpenAI
+// responses, Claude messages and Gemini generateContent) into one Request, so the capability
+// checks that decide which providers can serve a request are written once instead of once per
+// format. Translation between formats does not use it.
+package ir
+
+// Role is the author of a message.
+type Role string
+
+const (
+	RoleSystem    Role = "system"
+	RoleUser      Role = "user"
+	RoleAssistant Role = "assistant"
+	RoleTool      Role = "tool"
+)
+
+// PartType identifies the kind of content carried by a Part.
+type PartType string
+
+const (
+	PartText       PartType = "text"
+	PartImage      PartType = "image"
+	PartDocument   PartType = "document"
+	PartThinking   PartType = "thinking"
+	PartToolCall   PartType = "tool_call"
+	PartToolResult PartType = "tool_result"
+)
+
+// Request is the normalized form of a chat request.
+type Request struct {
+	Model    string
+	Stream   bool
+	System   []Part
+	Messages []Message
+	Tools    []Tool
+	// ToolChoice is "auto", "none", "required" or the name of a forced function.
+	ToolChoice string
+	// ResponseFormat is "text", "json_object" or "json_schema".
+	ResponseFormat string
+	// Schema is the raw JSON schema for ResponseFormat "json_schema".
+	Schema string
+	// Thinking is set when the request asks for extended reasoning.
+	Thinking *Thinking
+
+	MaxTokens   int64
+	Temperature *float64
+	TopP        *float64
+	Stop        []string
+}
+
+// Message is one turn of the conversation.
+type Message struct {
+	Role  Role
+	Parts []Part
+}
+
+// Part is one content item of a message.
+type Part struct {
+	Type PartType
+	// Text holds the text of text and thinking parts, and the output of tool results.
+	Text string
+	// MediaType is the MIME type of image and document parts.
+	MediaType string
+	// Data is the base64 payload of inline image and document parts.
+	Data string
+	// URL references remote image and document content.
+	URL string
+	// Signature is the provider signature of a thinking part.
+	Signature string
+	// ToolCallID links tool calls and their results.
+	ToolCallID string
+	// Name is the function name of tool calls and results.
+	Name string
+	// Arguments is the raw JSON arguments of a tool call.
+	Arguments string
+	// IsError marks a failed tool result.
+	IsError bool
+}
+
+// Tool is a function the model may call.
+type Tool struct {
+	Name        string
+	Description string
+	// Parameters is the raw JSON schema of the function arguments.
+	Parameters string
+}
+
+// Thinking describes the requested reasoning effort.
+type Thinking struct {
+	// BudgetTokens is the token budget, 0 when only an effort level was given.
+	BudgetTokens int64
+	// Effort is a level such as "low", "medium" or "high", empty when a budget was given.
+	Effort string
+}
@@ -0,0 +1,84 @@
+package ir
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseNormalizesFormats(t *testing.T) {
+	cases := []struct {
+		format string
+		body   string
+	}{
+		{"openai", `{"model":"m","messages":[
+			{"role":"system","content":"be brief"},
+			{"role":"user","content":[{"type":"text","text":"look"},{"type":"image_url","image_url":{"url":"data:image/png;base64,AAAA"}}]},
+			{"role":"assistant","content":null,"tool_calls":[{"id":"c1","type":"function","function":{"name":"ls","arguments":"{}"}}]},
+			{"role":"tool","tool_call_id":"c1","content":"files"}],
+			"tools":[{"type":"function","function":{"name":"ls","parameters":{"type":"object"}}}]}`},
+		{"openai-response", `{"model":"m","instructions":"be brief","input":[
+			{"type":"message","role":"user","content":[{"type":"input_text","text":"look"},{"type":"input_image","image_url":"data:image/png;base64,AAAA"}]},
+			{"type":"function_call","call_id":"c1","name":"ls","arguments":"{}"},
+			{"type":"function_call_output","call_id":"c1","output":"files"}],
+			"tools":[{"type":"function","name":"ls","parameters":{"type":"object"}}]}`},
+		{"claude", `{"model":"m","system":"be brief","messages":[
+			{"role":"user","content":[{"type":"text","text":"look"},{"type":"image","source":{"type":"base64","media_type":"image/png","data":"AAAA"}}]},
+			{"role":"assistant","content":[{"type":"tool_use","id":"c1","name":"ls","input":{}}]},
+			{"role":"user","content":[{"type":"tool_result","tool_use_id":"c1","content":"files"}]}],
+			"tools":[{"name":"ls","input_schema":{"type":"object"}}]}`},
+		{"gemini", `{"systemInstruction":{"parts":[{"text":"be brief"}]},"contents":[
+			{"role":"user","parts":[{"text":"look"},{"inlineData":{"mimeType":"image/png","data":"AAAA"}}]},
+			{"role":"model","parts":[{"functionCall":{"id":"c1","name":"ls","args":{}}}]},
+			{"role":"user","parts":[{"functionResponse":{"id":"c1","name":"ls","response":{"result":"files"}}}]}],
+			"tools":[{"functionDeclarations":[{"name":"ls","parameters":{"type":"object"}}]}]}`},
+	}
+	for _, tc := range cases {
+		req, err := Parse(tc.format, []byte(tc.body))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.format, err)
+		}
+		if len(req.System) != 1 || req.System[0].Text != "be brief" {
+			t.Errorf("%s: system = %+v", tc.format, req.System)
+		}
+		if len(req.Messages) != 3 {
+			t.Fatalf("%s: expected 3 messages, got %+v", tc.format, req.Messages)
+		}
+		image := req.Messages[0].Parts[1]
+		if image.Type != PartImage || image.MediaType != "image/png" || image.Data != "AAAA" {
+			t.Errorf("%s: image part = %+v", tc.format, image)
+		}
+		if call := req.Messages[1].Parts[0]; call.Type != PartToolCall || call.Name != "ls" || call.ToolCallID != "c1" {
+			t.Errorf("%s: tool call = %+v", tc.format, call)
+		}
+		if result := req.Messages[2].Parts[0]; result.Type != PartToolResult || result.ToolCallID != "c1" {
+			t.Errorf("%s: tool result = %+v", tc.format, result)
+		}
+		if len(req.Tools) != 1 || req.Tools[0].Name != "ls" {
+			t.Errorf("%s: tools = %+v", tc.format, req.Tools)
+		}
+		if got, want := req.Features(), []Feature{FeatureVision, FeatureTools}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: features = %v, want %v", tc.format, got, want)
+		}
+	}
+}
+
+func TestFeaturesThinkingAndJSONMode(t *testing.T) {
+	cases := map[string]string{
+		"openai":          `{"messages":[{"role":"user","content":"hi"}],"reasoning_effort":"high","response_format":{"type":"json_object"}}`,
+		"openai-response": `{"input":"hi","reasoning":{"effort":"low"},"text":{"format":{"type":"json_schema","schema":{}}}}`,
+		"gemini":          `{"contents":[{"role":"user","parts":[{"text":"hi"}]}],"generationConfig":{"responseMimeType":"application/json","thinkingConfig":{"thinkingBudget":1024}}}`,
+	}
+	for format, body := range cases {
+		req, err := Parse(format, []byte(body))
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if got, want := req.Features(), []Feature{FeatureThinking, FeatureJSONMode}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: features = %v, want %v", format, got, want)
+		}
+	}
+
+	if _, err := Parse("unknown", []byte(`{}`)); err == nil {
+		t.Errorf("expected error for unknown format")
+	}
+}
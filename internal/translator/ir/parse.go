@@ -0,0 +1,426 @@
+package ir
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/tidwall/gjson"
+)
+
+// Parse normalizes a request body in the named ingress format: constant.OpenAI,
+// constant.OpenaiResponse, constant.Claude, constant.Gemini or constant.GeminiCLI.
+func Parse(format string, rawJSON []byte) (*Request, error) {
+	if !gjson.ValidBytes(rawJSON) {
+		return nil, fmt.Errorf("ir: invalid JSON request body")
+	}
+	root := gjson.ParseBytes(rawJSON)
+	switch format {
+	case constant.OpenAI:
+		return parseOpenAI(root), nil
+	case constant.OpenaiResponse:
+		return parseOpenAIResponses(root), nil
+	case constant.Claude:
+		return parseClaude(root), nil
+	case constant.Gemini:
+		return parseGemini(root), nil
+	case constant.GeminiCLI:
+		req := parseGemini(root.Get("request"))
+		req.Model = root.Get("model").String()
+		return req, nil
+	default:
+		return nil, fmt.Errorf("ir: unsupported format %q", format)
+	}
+}
+
+func parseOpenAI(root gjson.Result) *Request {
+	req := &Request{
+		Model:     root.Get("model").String(),
+		Stream:    root.Get("stream").Bool(),
+		MaxTokens: firstInt(root, "max_completion_tokens", "max_tokens"),
+	}
+	parseSampling(req, root, "temperature", "top_p")
+	req.Stop = stringList(root.Get("stop"))
+	req.ResponseFormat = root.Get("response_format.type").String()
+	req.Schema = root.Get("response_format.json_schema.schema").Raw
+	if effort := root.Get("reasoning_effort").String(); effort != "" && effort != "none" {
+		req.Thinking = &Thinking{Effort: effort}
+	}
+	root.Get("tools").ForEach(func(_, tool gjson.Result) bool {
+		if fn := tool.Get("function"); fn.Exists() {
+			req.Tools = append(req.Tools, Tool{Name: fn.Get("name").String(), Description: fn.Get("description").String(), Parameters: fn.Get("parameters").Raw})
+		}
+		return true
+	})
+	req.ToolChoice = openAIToolChoice(root.Get("tool_choice"))
+
+	root.Get("messages").ForEach(func(_, msg gjson.Result) bool {
+		role := Role(msg.Get("role").String())
+		if role == "developer" {
+			role = RoleSystem
+		}
+		var parts []Part
+		if reasoning := msg.Get("reasoning_content").String(); reasoning != "" {
+			parts = append(parts, Part{Type: PartThinking, Text: reasoning})
+		}
+		parts = append(parts, openAIContentParts(msg.Get("content"))...)
+		msg.Get("tool_calls").ForEach(func(_, call gjson.Result) bool {
+			parts = append(parts, Part{Type: PartToolCall, ToolCallID: call.Get("id").String(), Name: call.Get("function.name").String(), Arguments: call.Get("function.arguments").String()})
+			return true
+		})
+		if role == RoleTool {
+			parts = []Part{{Type: PartToolResult, ToolCallID: msg.Get("tool_call_id").String(), Text: contentText(msg.Get("content"))}}
+		}
+		req.addMessage(role, parts)
+		return true
+	})
+	return req
+}
+
+func openAIContentParts(content gjson.Result) []Part {
+	if content.Type == gjson.String {
+		return textParts(content.String())
+	}
+	var parts []Part
+	content.ForEach(func(_, item gjson.Result) bool {
+		switch item.Get("type").String() {
+		case "text", "input_text", "output_text":
+			parts = append(parts, Part{Type: PartText, Text: item.Get("text").String()})
+		case "image_url":
+			parts = append(parts, urlPart(PartImage, item.Get("image_url.url").String()))
+		case "input_image":
+			parts = append(parts, urlPart(PartImage, item.Get("image_url").String()))
+		case "file":
+			parts = append(parts, urlPart(PartDocument, item.Get("file.file_data").String()))
+		case "input_file":
+			parts = append(parts, urlPart(PartDocument, item.Get("file_data").String()))
+		}
+		return true
+	})
+	return parts
+}
+
+func openAIToolChoice(choice gjson.Result) string {
+	if choice.Type == gjson.String {
+		return choice.String()
+	}
+	if name := choice.Get("function.name").String(); name != "" {
+		return name
+	}
+	return choice.Get("name").String()
+}
+
+func parseOpenAIResponses(root gjson.Result) *Request {
+	req := &Request{
+		Model:     root.Get("model").String(),
+		Stream:    root.Get("stream").Bool(),
+		MaxTokens: root.Get("max_output_tokens").Int(),
+	}
+	parseSampling(req, root, "temperature", "top_p")
+	req.ResponseFormat = root.Get("text.format.type").String()
+	req.Schema = root.Get("text.format.schema").Raw
+	if effort := root.Get("reasoning.effort").String(); effort != "" && effort != "none" {
+		req.Thinking = &Thinking{Effort: effort}
+	}
+	if instructions := root.Get("instructions").String(); instructions != "" {
+		req.System = textParts(instructions)
+	}
+	root.Get("tools").ForEach(func(_, tool gjson.Result) bool {
+		if tool.Get("type").String() == "function" {
+			req.Tools = append(req.Tools, Tool{Name: tool.Get("name").String(), Description: tool.Get("description").String(), Parameters: tool.Get("parameters").Raw})
+		}
+		return true
+	})
+	req.ToolChoice = openAIToolChoice(root.Get("tool_choice"))
+
+	input := root.Get("input")
+	if input.Type == gjson.String {
+		req.addMessage(RoleUser, textParts(input.String()))
+		return req
+	}
+	input.ForEach(func(_, item gjson.Result) bool {
+		switch item.Get("type").String() {
+		case "function_call":
+			req.addMessage(RoleAssistant, []Part{{Type: PartToolCall, ToolCallID: item.Get("call_id").String(), Name: item.Get("name").String(), Arguments: item.Get("arguments").String()}})
+		case "function_call_output":
+			req.addMessage(RoleTool, []Part{{Type: PartToolResult, ToolCallID: item.Get("call_id").String(), Text: contentText(item.Get("output"))}})
+		case "reasoning":
+			var text []string
+			item.Get("summary").ForEach(func(_, s gjson.Result) bool {
+				text = append(text, s.Get("text").String())
+				return true
+			})
+			req.addMessage(RoleAssistant, []Part{{Type: PartThinking, Text: strings.Join(text, "\n")}})
+		case "message", "":
+			role := Role(item.Get("role").String())
+			if role == "developer" {
+				role = RoleSystem
+			}
+			req.addMessage(role, openAIContentParts(item.Get("content")))
+		}
+		return true
+	})
+	return req
+}
+
+func parseClaude(root gjson.Result) *Request {
+	req := &Request{
+		Model:     root.Get("model").String(),
+		Stream:    root.Get("stream").Bool(),
+		MaxTokens: root.Get("max_tokens").Int(),
+		Stop:      stringList(root.Get("stop_sequences")),
+	}
+	parseSampling(req, root, "temperature", "top_p")
+	if root.Get("thinking.type").String() == "enabled" {
+		req.Thinking = &Thinking{BudgetTokens: root.Get("thinking.budget_tokens").Int()}
+	}
+	if system := root.Get("system"); system.Type == gjson.String {
+		req.System = textParts(system.String())
+	} else {
+		req.System = claudeContentParts(system)
+	}
+	root.Get("tools").ForEach(func(_, tool gjson.Result) bool {
+		req.Tools = append(req.Tools, Tool{Name: tool.Get("name").String(), Description: tool.Get("description").String(), Parameters: tool.Get("input_schema").Raw})
+		return true
+	})
+	switch choice := root.Get("tool_choice"); choice.Get("type").String() {
+	case "any":
+		req.ToolChoice = "required"
+	case "tool":
+		req.ToolChoice = choice.Get("name").String()
+	case "auto", "none":
+		req.ToolChoice = choice.Get("type").String()
+	}
+
+	root.Get("messages").ForEach(func(_, msg gjson.Result) bool {
+		content := msg.Get("content")
+		parts := claudeContentParts(content)
+		if content.Type == gjson.String {
+			parts = textParts(content.String())
+		}
+		req.addMessage(Role(msg.Get("role").String()), parts)
+		return true
+	})
+	return req
+}
+
+func claudeContentParts(content gjson.Result) []Part {
+	var parts []Part
+	content.ForEach(func(_, block gjson.Result) bool {
+		switch block.Get("type").String() {
+		case "text":
+			parts = append(parts, Part{Type: PartText, Text: block.Get("text").String()})
+		case "image", "document":
+			partType := PartImage
+			if block.Get("type").String() == "document" {
+				partType = PartDocument
+			}
+			source := block.Get("source")
+			switch source.Get("type").String() {
+			case "base64":
+				parts = append(parts, Part{Type: partType, MediaType: source.Get("media_type").String(), Data: source.Get("data").String()})
+			case "url":
+				parts = append(parts, Part{Type: partType, URL: source.Get("url").String()})
+			case "text":
+				parts = append(parts, Part{Type: PartText, Text: source.Get("data").String()})
+			}
+		case "thinking":
+			parts = append(parts, Part{Type: PartThinking, Text: block.Get("thinking").String(), Signature: block.Get("signature").String()})
+		case "tool_use":
+			parts = append(parts, Part{Type: PartToolCall, ToolCallID: block.Get("id").String(), Name: block.Get("name").String(), Arguments: block.Get("input").Raw})
+		case "tool_result":
+			parts = append(parts, Part{Type: PartToolResult, ToolCallID: block.Get("tool_use_id").String(), Text: contentText(block.Get("content")), IsError: block.Get("is_error").Bool()})
+			// Images returned by tools still need a vision-capable provider.
+			for _, nested := range claudeContentParts(block.Get("content")) {
+				if nested.Type == PartImage {
+					parts = append(parts, nested)
+				}
+			}
+		}
+		return true
+	})
+	return parts
+}
+
+func parseGemini(root gjson.Result) *Request {
+	config := root.Get("generationConfig")
+	req := &Request{
+		Model:     root.Get("model").String(),
+		MaxTokens: config.Get("maxOutputTokens").Int(),
+		Stop:      stringList(config.Get("stopSequences")),
+	}
+	parseSampling(req, config, "temperature", "topP")
+	if mime := config.Get("responseMimeType").String(); mime == "application/json" {
+		req.ResponseFormat = "json_object"
+		if schema := config.Get("responseSchema"); schema.Exists() {
+			req.ResponseFormat, req.Schema = "json_schema", schema.Raw
+		} else if schema := config.Get("responseJsonSchema"); schema.Exists() {
+			req.ResponseFormat, req.Schema = "json_schema", schema.Raw
+		}
+	}
+	if thinking := config.Get("thinkingConfig"); thinking.Exists() {
+		budget := thinking.Get("thinkingBudget")
+		level := thinking.Get("thinkingLevel").String()
+		if (budget.Exists() && budget.Int() != 0) || level != "" {
+			req.Thinking = &Thinking{BudgetTokens: max(budget.Int(), 0), Effort: level}
+		}
+	}
+	system := root.Get("systemInstruction")
+	if !system.Exists() {
+		system = root.Get("system_instruction")
+	}
+	req.System = geminiParts(system.Get("parts"))
+	root.Get("tools").ForEach(func(_, tool gjson.Result) bool {
+		declarations := tool.Get("functionDeclarations")
+		if !declarations.Exists() {
+			declarations = tool.Get("function_declarations")
+		}
+		declarations.ForEach(func(_, fn gjson.Result) bool {
+			parameters := fn.Get("parameters")
+			if !parameters.Exists() {
+				parameters = fn.Get("parametersJsonSchema")
+			}
+			req.Tools = append(req.Tools, Tool{Name: fn.Get("name").String(), Description: fn.Get("description").String(), Parameters: parameters.Raw})
+			return true
+		})
+		return true
+	})
+	switch mode := strings.ToUpper(root.Get("toolConfig.functionCallingConfig.mode").String()); mode {
+	case "NONE":
+		req.ToolChoice = "none"
+	case "ANY":
+		req.ToolChoice = "required"
+		if names := root.Get("toolConfig.functionCallingConfig.allowedFunctionNames").Array(); len(names) == 1 {
+			req.ToolChoice = names[0].String()
+		}
+	case "AUTO":
+		req.ToolChoice = "auto"
+	}
+
+	root.Get("contents").ForEach(func(_, content gjson.Result) bool {
+		role := RoleUser
+		if content.Get("role").String() == "model" {
+			role = RoleAssistant
+		}
+		req.addMessage(role, geminiParts(content.Get("parts")))
+		return true
+	})
+	return req
+}
+
+func geminiParts(items gjson.Result) []Part {
+	var parts []Part
+	items.ForEach(func(_, item gjson.Result) bool {
+		switch {
+		case item.Get("functionCall").Exists():
+			call := item.Get("functionCall")
+			parts = append(parts, Part{Type: PartToolCall, ToolCallID: call.Get("id").String(), Name: call.Get("name").String(), Arguments: call.Get("args").Raw})
+		case item.Get("functionResponse").Exists():
+			response := item.Get("functionResponse")
+			parts = append(parts, Part{Type: PartToolResult, ToolCallID: response.Get("id").String(), Name: response.Get("name").String(), Text: response.Get("response").Raw})
+		case item.Get("inlineData").Exists() || item.Get("inline_data").Exists():
+			inline := item.Get("inlineData")
+			if !inline.Exists() {
+				inline = item.Get("inline_data")
+			}
+			mediaType := inline.Get("mimeType").String() + inline.Get("mime_type").String()
+			parts = append(parts, Part{Type: mediaPartType(mediaType), MediaType: mediaType, Data: inline.Get("data").String()})
+		case item.Get("fileData").Exists():
+			file := item.Get("fileData")
+			mediaType := file.Get("mimeType").String()
+			parts = append(parts, Part{Type: mediaPartType(mediaType), MediaType: mediaType, URL: file.Get("fileUri").String()})
+		case item.Get("thought").Bool():
+			parts = append(parts, Part{Type: PartThinking, Text: item.Get("text").String(), Signature: item.Get("thoughtSignature").String()})
+		case item.Get("text").Exists():
+			parts = append(parts, Part{Type: PartText, Text: item.Get("text").String()})
+		}
+		return true
+	})
+	return parts
+}
+
+// addMessage appends a message, folding system messages into the request's system parts.
+func (r *Request) addMessage(role Role, parts []Part) {
+	if role == RoleSystem {
+		r.System = append(r.System, parts...)
+		return
+	}
+	if len(parts) == 0 {
+		return
+	}
+	r.Messages = append(r.Messages, Message{Role: role, Parts: parts})
+}
+
+func mediaPartType(mediaType string) PartType {
+	if strings.HasPrefix(mediaType, "image/") {
+		return PartImage
+	}
+	return PartDocument
+}
+
+// urlPart builds an image or document part from a data: URL or remote URL.
+func urlPart(partType PartType, url string) Part {
+	if rest, ok := strings.CutPrefix(url, "data:"); ok {
+		header, data, _ := strings.Cut(rest, ",")
+		mediaType, _, _ := strings.Cut(header, ";")
+		return Part{Type: partType, MediaType: mediaType, Data: data}
+	}
+	return Part{Type: partType, URL: url}
+}
+
+func textParts(text string) []Part {
+	if text == "" {
+		return nil
+	}
+	return []Part{{Type: PartText, Text: text}}
+}
+
+// contentText flattens string or array content into plain text.
+func contentText(content gjson.Result) string {
+	if content.Type == gjson.String {
+		return content.String()
+	}
+	if !content.IsArray() {
+		return content.Raw
+	}
+	var text []string
+	content.ForEach(func(_, item gjson.Result) bool {
+		if t := item.Get("text"); t.Exists() {
+			text = append(text, t.String())
+		}
+		return true
+	})
+	return strings.Join(text, "\n")
+}
+
+func stringList(value gjson.Result) []string {
+	if value.Type == gjson.String {
+		return []string{value.String()}
+	}
+	var out []string
+	value.ForEach(func(_, item gjson.Result) bool {
+		out = append(out, item.String())
+		return true
+	})
+	return out
+}
+
+func firstInt(root gjson.Result, paths ...string) int64 {
+	for _, path := range paths {
+		if value := root.Get(path); value.Exists() {
+			return value.Int()
+		}
+	}
+	return 0
+}
+
+func parseSampling(req *Request, root gjson.Result, temperaturePath, topPPath string) {
+	if value := root.Get(temperaturePath); value.Exists() {
+		temperature := value.Float()
+		req.Temperature = &temperature
+	}
+	if value := root.Get(topPPath); value.Exists() {
+		topP := value.Float()
+		req.TopP = &topP
+	}
+}
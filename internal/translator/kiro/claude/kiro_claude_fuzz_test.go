@@ -0,0 +1,49 @@
+package claude
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+// Run with: go test ./internal/translator/kiro/claude -run '^$' -fuzz FuzzBuildKiroPayload
+func FuzzBuildKiroPayload(f *testing.F) {
+	seeds := []string{
+		`{"messages":[{"role":"user","content":"hi"}]}`,
+		`{"system":[{"type":"text","text":"s"}],"messages":[{"role":"user","content":[{"type":"image","source":{"type":"base64","media_type":"image/png","data":"AAAA"}}]}]}`,
+		`{"messages":[{"role":"user","content":[1,"two",null,{"type":"tool_result"},{"type":"image","source":7}]}]}`,
+		`{"messages":[{"role":"assistant","content":[{"type":"tool_use","id":null,"name":5,"input":"str"},{"type":"thinking"}]}]}`,
+		`{"messages":[{"role":"user","content":[{"type":"tool_result","tool_use_id":"x","content":[{"type":"text","text":"\u0000😀"},{"type":"image"}]}]}]}`,
+		`{"system":5,"tools":[{"name":"","input_schema":"str"},null],"thinking":{"type":"enabled","budget_tokens":"x"},"messages":[]}`,
+		`{"messages":[{"role":"assistant","content":"only assistant"}]}`,
+		`{"messages":{"role":"user"}}`,
+		`null`,
+		``,
+	}
+	for _, seed := range seeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, body []byte) {
+		payload, _ := BuildKiroPayload(body, "kiro-model", "arn", "CLI", false, false, nil, nil)
+		if payload != nil && !json.Valid(payload) {
+			t.Fatalf("invalid JSON payload for input %q", body)
+		}
+	})
+}
+
+// Run with: go test ./internal/translator/kiro/claude -run '^$' -fuzz FuzzBuildClaudeResponse
+func FuzzBuildClaudeResponse(f *testing.F) {
+	f.Add("hello", "Read", `{"path":"a"}`, "end_turn")
+	f.Add("<thinking>partial", "", `not json`, "tool_use")
+	f.Add("\u0000\xed\xa0\xbd</thinking>", "名前", `{"nested":{"deep":[1,{"x":null}]}}`, "")
+	f.Fuzz(func(t *testing.T, content, toolName, toolInput, stopReason string) {
+		var input map[string]interface{}
+		_ = json.Unmarshal([]byte(toolInput), &input)
+		toolUses := []KiroToolUse{{ToolUseID: "t1", Name: toolName, Input: input}}
+		out := BuildClaudeResponse(content, toolUses, "m", usage.Detail{InputTokens: 1, OutputTokens: 2}, stopReason)
+		if !json.Valid(out) {
+			t.Fatalf("invalid JSON response: %s", out)
+		}
+	})
+}
@@ -8,10 +8,9 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
-	"time"
 	"unicode/utf8"
 
-	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/idgen"
 	kirocommon "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/kiro/common"
 	log "github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
@@ -74,10 +73,18 @@ type KiroUserInputMessage struct {
 	UserInputMessageContext *KiroUserInputMessageContext `json:"userInputMessageContext,omitempty"`
 }
 
-// KiroUserInputMessageContext contains tool-related context
+// KiroUserInputMessageContext contains tool-related context and additional context entries
 type KiroUserInputMessageContext struct {
-	ToolResults []KiroToolResult  `json:"toolResults,omitempty"`
-	Tools       []KiroToolWrapper `json:"tools,omitempty"`
+	ToolResults       []KiroToolResult        `json:"toolResults,omitempty"`
+	Tools             []KiroToolWrapper       `json:"tools,omitempty"`
+	AdditionalContext []KiroAdditionalContext `json:"additionalContext,omitempty"`
+}
+
+// KiroAdditionalContext is a named context entry; the system prompt travels in one of these
+type KiroAdditionalContext struct {
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	InnerContext string `json:"innerContext"`
 }
 
 // KiroToolResult represents a tool execution result
@@ -137,7 +144,8 @@ func ConvertClaudeRequestToKiro(modelName string, inputRawJSON []byte, stream bo
 // isAgentic parameter enables chunked write optimization prompt for -agentic model variants.
 // isChatOnly parameter disables tool calling for -chat model variants (pure conversation mode).
 // headers parameter allows checking Anthropic-Beta header for thinking mode detection.
-// metadata parameter is kept for API compatibility but no longer used for thinking configuration.
+// metadata parameter carries builder options (see kirocommon.MetadataSystemPromptContext); it is no longer used for thinking configuration.
+// The system prompt is prepended to the current message unless the context option sends it as an additionalContext entry.
 // Supports thinking mode - when enabled, injects thinking tags into system prompt.
 // Returns the payload and a boolean indicating whether thinking mode was injected.
 func BuildKiroPayload(claudeBody []byte, modelID, profileArn, origin string, isAgentic, isChatOnly bool, headers http.Header, metadata map[string]any) ([]byte, bool) {
@@ -190,7 +198,7 @@ func BuildKiroPayload(claudeBody []byte, modelID, profileArn, origin string, isA
 	thinkingEnabled := IsThinkingEnabledWithHeaders(claudeBody, headers)
 
 	// Inject timestamp context
-	timestamp := time.Now().Format("2006-01-02 15:04:05 MST")
+	timestamp := idgen.Now().Format("2006-01-02 15:04:05 MST")
 	timestampContext := fmt.Sprintf("[Context: Current time is %s]", timestamp)
 	if systemPrompt != "" {
 		systemPrompt = timestampContext + "\n\n" + systemPrompt
@@ -240,18 +248,31 @@ func BuildKiroPayload(claudeBody []byte, modelID, profileArn, origin string, isA
 	// Process messages and build history
 	history, currentUserMsg, currentToolResults := processMessages(messages, modelID, origin)
 
+	// The system prompt goes into the current message only: prepended to its content or, with the
+	// context option, as a dedicated context entry. History turns never carry it.
+	inlineSystemPrompt := !kirocommon.SystemPromptAsContext(metadata)
+	var systemContext []KiroAdditionalContext
+	if !inlineSystemPrompt {
+		systemContext = buildSystemPromptContext(systemPrompt)
+	}
+
 	// Build content with system prompt
 	if currentUserMsg != nil {
-		currentUserMsg.Content = buildFinalContent(currentUserMsg.Content, systemPrompt, currentToolResults)
+		if inlineSystemPrompt {
+			currentUserMsg.Content = buildFinalContent(currentUserMsg.Content, systemPrompt, currentToolResults)
+		} else {
+			currentUserMsg.Content = buildFinalContent(currentUserMsg.Content, "", currentToolResults)
+		}
 
 		// Deduplicate currentToolResults
 		currentToolResults = deduplicateToolResults(currentToolResults)
 
-		// Build userInputMessageContext with tools and tool results
-		if len(kiroTools) > 0 || len(currentToolResults) > 0 {
+		// Build userInputMessageContext with tools, tool results and the system prompt context
+		if len(kiroTools) > 0 || len(currentToolResults) > 0 || len(systemContext) > 0 {
 			currentUserMsg.UserInputMessageContext = &KiroUserInputMessageContext{
-				Tools:       kiroTools,
-				ToolResults: currentToolResults,
+				Tools:             kiroTools,
+				ToolResults:       currentToolResults,
+				AdditionalContext: systemContext,
 			}
 		}
 	}
@@ -260,7 +281,7 @@ func BuildKiroPayload(claudeBody []byte, modelID, profileArn, origin string, isA
 	var currentMessage KiroCurrentMessage
 	if currentUserMsg != nil {
 		currentMessage = KiroCurrentMessage{UserInputMessage: *currentUserMsg}
-	} else {
+	} else if inlineSystemPrompt {
 		fallbackContent := ""
 		if systemPrompt != "" {
 			fallbackContent = "--- SYSTEM PROMPT ---\n" + systemPrompt + "\n--- END SYSTEM PROMPT ---\n"
@@ -270,6 +291,17 @@ func BuildKiroPayload(claudeBody []byte, modelID, profileArn, origin string, isA
 			ModelID: modelID,
 			Origin:  origin,
 		}}
+	} else {
+		currentMessage = KiroCurrentMessage{UserInputMessage: KiroUserInputMessage{
+			Content: "Continue",
+			ModelID: modelID,
+			Origin:  origin,
+		}}
+		if len(systemContext) > 0 {
+			currentMessage.UserInputMessage.UserInputMessageContext = &KiroUserInputMessageContext{
+				AdditionalContext: systemContext,
+			}
+		}
 	}
 
 	// Build inferenceConfig if we have any inference parameters
@@ -291,7 +323,7 @@ func BuildKiroPayload(claudeBody []byte, modelID, profileArn, origin string, isA
 	payload := KiroPayload{
 		ConversationState: KiroConversationState{
 			ChatTriggerType: "MANUAL",
-			ConversationID:  uuid.New().String(),
+			ConversationID:  idgen.UUID(),
 			CurrentMessage:  currentMessage,
 			History:         history,
 		},
@@ -600,6 +632,18 @@ func processMessages(messages gjson.Result, modelID, origin string) ([]KiroHisto
 	return history, currentUserMsg, currentToolResults
 }
 
+// buildSystemPromptContext wraps the system prompt in the additionalContext entry sent with the current message
+func buildSystemPromptContext(systemPrompt string) []KiroAdditionalContext {
+	if strings.TrimSpace(systemPrompt) == "" {
+		return nil
+	}
+	return []KiroAdditionalContext{{
+		Name:         kirocommon.SystemPromptContextName,
+		Description:  "System instructions for this conversation",
+		InnerContext: systemPrompt,
+	}}
+}
+
 // buildFinalContent builds the final content with system prompt
 func buildFinalContent(content, systemPrompt string, toolResults []KiroToolResult) string {
 	var contentBuilder strings.Builder
@@ -0,0 +1,93 @@
+package claude
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	kirocommon "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/kiro/common"
+)
+
+// TestSystemPromptNeverInHistory verifies that the Claude system prompt is sent as a context entry
+// of the current message by default, falls back to the current message content when inlined, and
+// never appears in history user turns either way.
+func TestSystemPromptNeverInHistory(t *testing.T) {
+	const marker = "SYSTEM-MARKER-7f3a"
+	input := []byte(`{
+		"model": "claude-sonnet-4-5",
+		"system": [{"type": "text", "text": "` + marker + `"}],
+		"messages": [
+			{"role": "user", "content": "first question"},
+			{"role": "assistant", "content": [{"type": "text", "text": "first answer"}]},
+			{"role": "user", "content": [{"type": "text", "text": "second question"}]}
+		]
+	}`)
+
+	for _, inline := range []bool{false, true} {
+		var metadata map[string]any
+		if !inline {
+			metadata = map[string]any{kirocommon.MetadataSystemPromptContext: true}
+		}
+		result, _ := BuildKiroPayload(input, "kiro-model", "", "CLI", false, false, nil, metadata)
+
+		var payload KiroPayload
+		if err := json.Unmarshal(result, &payload); err != nil {
+			t.Fatalf("Failed to unmarshal result: %v", err)
+		}
+		if len(payload.ConversationState.History) != 2 {
+			t.Fatalf("inline=%v: expected 2 history entries, got %d", inline, len(payload.ConversationState.History))
+		}
+		for i, msg := range payload.ConversationState.History {
+			if msg.UserInputMessage != nil && strings.Contains(msg.UserInputMessage.Content, marker) {
+				t.Errorf("inline=%v: system prompt leaked into history user turn %d", inline, i)
+			}
+		}
+
+		current := payload.ConversationState.CurrentMessage.UserInputMessage
+		if inline {
+			if !strings.Contains(current.Content, marker) {
+				t.Errorf("inline=true: expected system prompt in current content, got %q", current.Content)
+			}
+			continue
+		}
+		if current.Content != "second question" {
+			t.Errorf("inline=false: current content = %q, want %q", current.Content, "second question")
+		}
+		ctx := current.UserInputMessageContext
+		if ctx == nil || len(ctx.AdditionalContext) != 1 || ctx.AdditionalContext[0].Name != kirocommon.SystemPromptContextName || !strings.Contains(ctx.AdditionalContext[0].InnerContext, marker) {
+			t.Errorf("inline=false: expected system prompt context entry, got %+v", ctx)
+		}
+	}
+}
+
+// TestSystemPromptWithTrailingAssistant covers the synthesized "Continue" current message when the
+// system prompt is sent as context.
+func TestSystemPromptWithTrailingAssistant(t *testing.T) {
+	const marker = "SYSTEM-MARKER-9c1e"
+	input := []byte(`{
+		"system": "` + marker + `",
+		"messages": [
+			{"role": "user", "content": "question"},
+			{"role": "assistant", "content": "partial answer"}
+		]
+	}`)
+
+	metadata := map[string]any{kirocommon.MetadataSystemPromptContext: true}
+	result, _ := BuildKiroPayload(input, "kiro-model", "", "CLI", false, false, nil, metadata)
+	var payload KiroPayload
+	if err := json.Unmarshal(result, &payload); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	for i, msg := range payload.ConversationState.History {
+		if msg.UserInputMessage != nil && strings.Contains(msg.UserInputMessage.Content, marker) {
+			t.Errorf("system prompt leaked into history user turn %d", i)
+		}
+	}
+	current := payload.ConversationState.CurrentMessage.UserInputMessage
+	if current.Content != "Continue" {
+		t.Errorf("current content = %q, want Continue", current.Content)
+	}
+	if ctx := current.UserInputMessageContext; ctx == nil || len(ctx.AdditionalContext) != 1 || !strings.Contains(ctx.AdditionalContext[0].InnerContext, marker) {
+		t.Errorf("expected system prompt context entry, got %+v", ctx)
+	}
+}
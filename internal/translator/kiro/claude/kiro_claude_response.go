@@ -9,7 +9,8 @@ import (
 	"encoding/json"
 	"strings"
 
-	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/features"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/idgen"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
 	log "github.com/sirupsen/logrus"
 
@@ -88,7 +89,7 @@ func BuildClaudeResponse(content string, toolUses []KiroToolUse, model string, u
 	}
 
 	response := map[string]interface{}{
-		"id":          "msg_" + uuid.New().String()[:24],
+		"id":          "msg_" + idgen.UUID()[:24],
 		"type":        "message",
 		"role":        "assistant",
 		"model":       model,
@@ -114,8 +115,8 @@ func ExtractThinkingFromContent(content string) []map[string]interface{} {
 	}
 
 	// Check if content contains thinking tags at all
-	if !strings.Contains(content, thinkingStartTag) {
-		// No thinking tags, return as plain text
+	if !strings.Contains(content, thinkingStartTag) || !features.Enabled(features.ThinkingExtraction) {
+		// No thinking tags, or extraction is switched off: return as plain text
 		return []map[string]interface{}{
 			{
 				"type": "text",
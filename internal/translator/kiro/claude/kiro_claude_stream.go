@@ -6,7 +6,7 @@ package claude
 import (
 	"encoding/json"
 
-	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/idgen"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
 )
 
@@ -15,7 +15,7 @@ func BuildClaudeMessageStartEvent(model string, inputTokens int64) []byte {
 	event := map[string]interface{}{
 		"type": "message_start",
 		"message": map[string]interface{}{
-			"id":            "msg_" + uuid.New().String()[:24],
+			"id":            "msg_" + idgen.UUID()[:24],
 			"type":          "message",
 			"role":          "assistant",
 			"content":       []interface{}{},
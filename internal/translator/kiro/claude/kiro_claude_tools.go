@@ -7,7 +7,7 @@ import (
 	"regexp"
 	"strings"
 
-	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/idgen"
 	kirocommon "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/kiro/common"
 	log "github.com/sirupsen/logrus"
 )
@@ -102,7 +102,7 @@ func ParseEmbeddedToolCalls(text string, processedIDs map[string]bool) (string,
 		}
 
 		// Generate unique tool ID
-		toolUseID := "toolu_" + uuid.New().String()[:12]
+		toolUseID := "toolu_" + idgen.UUID()[:12]
 
 		// Check for duplicates using name+input as key
 		dedupeKey := toolName + ":" + repairedJSON
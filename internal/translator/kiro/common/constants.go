@@ -21,6 +21,13 @@ const (
 	// InlineCodeMarker is the markdown inline code marker (backtick).
 	InlineCodeMarker = "`"
 
+	// SystemPromptContextName names the additionalContext entry carrying the system prompt.
+	SystemPromptContextName = "system_prompt"
+
+	// MetadataSystemPromptContext is the payload builder metadata key that sends the system prompt
+	// as an additionalContext entry instead of prepending it to the current user message.
+	MetadataSystemPromptContext = "kiro_system_prompt_context"
+
 	// KiroAgenticSystemPrompt is injected only for -agentic models to prevent timeouts on large writes.
 	// AWS Kiro API has a 2-3 minute timeout for large file write operations.
 	KiroAgenticSystemPrompt = `
@@ -13,4 +13,11 @@ func GetString(m map[string]interface{}, key string) string {
 // GetStringValue is an alias for GetString for backward compatibility.
 func GetStringValue(m map[string]interface{}, key string) string {
 	return GetString(m, key)
-}
\ No newline at end of file
+}
+
+// SystemPromptAsContext reports whether the payload builder metadata asks for the system prompt
+// to be sent as an additionalContext entry instead of prepended to the current user message.
+func SystemPromptAsContext(metadata map[string]any) bool {
+	asContext, _ := metadata[MetadataSystemPromptContext].(bool)
+	return asContext
+}
@@ -0,0 +1,20 @@
+// Package gemini provides translation between Gemini and Kiro formats.
+package gemini
+
+import (
+	. "github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/translator"
+)
+
+func init() {
+	translator.Register(
+		Gemini,
+		Kiro,
+		ConvertGeminiRequestToKiro,
+		interfaces.TranslateResponse{
+			Stream:    ConvertKiroStreamToGemini,
+			NonStream: ConvertKiroNonStreamToGemini,
+		},
+	)
+}
@@ -0,0 +1,73 @@
+// Package gemini provides response translation from Kiro to Gemini format.
+// The Kiro executor emits Claude-compatible SSE events and responses, which are
+// converted to Gemini candidates with text, thought and functionCall parts.
+package gemini
+
+import (
+	"bytes"
+	"context"
+
+	claudegemini "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/claude/gemini"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ConvertKiroStreamToGemini converts a Kiro streaming event to Gemini format.
+// Kiro events carry an "event:" line before the data line; only the data line is
+// handed to the Claude to Gemini stream converter, which assembles functionCall parts
+// from tool_use blocks once their arguments are complete.
+func ConvertKiroStreamToGemini(ctx context.Context, model string, originalRequest, request, rawResponse []byte, param *any) []string {
+	for _, line := range bytes.Split(rawResponse, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if bytes.HasPrefix(line, []byte("data:")) {
+			return claudegemini.ConvertClaudeResponseToGemini(ctx, model, originalRequest, request, line, param)
+		}
+	}
+	return []string{}
+}
+
+// ConvertKiroNonStreamToGemini converts a Kiro non-streaming response to Gemini format.
+// The response is a complete Claude message, so its content blocks map directly to parts.
+func ConvertKiroNonStreamToGemini(ctx context.Context, model string, originalRequest, request, rawResponse []byte, param *any) string {
+	response := gjson.ParseBytes(rawResponse)
+	out := `{"candidates":[{"content":{"role":"model","parts":[]},"finishReason":"STOP"}],"usageMetadata":{"trafficType":"PROVISIONED_THROUGHPUT"},"modelVersion":"","responseId":""}`
+	out, _ = sjson.Set(out, "modelVersion", model)
+	out, _ = sjson.Set(out, "responseId", response.Get("id").String())
+
+	response.Get("content").ForEach(func(_, block gjson.Result) bool {
+		switch block.Get("type").String() {
+		case "text":
+			if text := block.Get("text").String(); text != "" {
+				part, _ := sjson.Set(`{"text":""}`, "text", text)
+				out, _ = sjson.SetRaw(out, "candidates.0.content.parts.-1", part)
+			}
+		case "thinking":
+			if thinking := block.Get("thinking").String(); thinking != "" {
+				part, _ := sjson.Set(`{"thought":true,"text":""}`, "text", thinking)
+				out, _ = sjson.SetRaw(out, "candidates.0.content.parts.-1", part)
+			}
+		case "tool_use":
+			part := `{"functionCall":{"name":"","args":{}}}`
+			if id := block.Get("id").String(); id != "" {
+				part, _ = sjson.Set(part, "functionCall.id", id)
+			}
+			part, _ = sjson.Set(part, "functionCall.name", block.Get("name").String())
+			if input := block.Get("input"); input.IsObject() {
+				part, _ = sjson.SetRaw(part, "functionCall.args", input.Raw)
+			}
+			out, _ = sjson.SetRaw(out, "candidates.0.content.parts.-1", part)
+		}
+		return true
+	})
+
+	if response.Get("stop_reason").String() == "max_tokens" {
+		out, _ = sjson.Set(out, "candidates.0.finishReason", "MAX_TOKENS")
+	}
+
+	inputTokens := response.Get("usage.input_tokens").Int()
+	outputTokens := response.Get("usage.output_tokens").Int()
+	out, _ = sjson.Set(out, "usageMetadata.promptTokenCount", inputTokens)
+	out, _ = sjson.Set(out, "usageMetadata.candidatesTokenCount", outputTokens)
+	out, _ = sjson.Set(out, "usageMetadata.totalTokenCount", inputTokens+outputTokens)
+	return out
+}
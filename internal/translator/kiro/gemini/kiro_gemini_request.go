@@ -0,0 +1,15 @@
+// Package gemini provides request translation from Gemini to Kiro format.
+// The Kiro executor builds its payload from Claude Messages requests, so Gemini requests,
+// including functionDeclarations tools and functionCall/functionResponse parts, are
+// converted to the Claude format first.
+package gemini
+
+import (
+	claudegemini "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/claude/gemini"
+)
+
+// ConvertGeminiRequestToKiro converts a Gemini generateContent request to the Claude format
+// consumed by the Kiro payload builder.
+func ConvertGeminiRequestToKiro(modelName string, inputRawJSON []byte, stream bool) []byte {
+	return claudegemini.ConvertGeminiRequestToClaude(modelName, inputRawJSON, stream)
+}
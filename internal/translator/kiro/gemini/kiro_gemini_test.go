@@ -0,0 +1,97 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	kiroclaude "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/kiro/claude"
+	"github.com/tidwall/gjson"
+)
+
+// TestGeminiToolsReachKiroPayload verifies functionDeclarations and functionCall/functionResponse
+// parts survive the translation into the Kiro payload.
+func TestGeminiToolsReachKiroPayload(t *testing.T) {
+	input := []byte(`{
+		"contents": [
+			{"role": "user", "parts": [{"text": "read the file"}]},
+			{"role": "model", "parts": [{"functionCall": {"id": "call_1", "name": "read_file", "args": {"path": "a.txt"}}}]},
+			{"role": "user", "parts": [{"functionResponse": {"id": "call_1", "name": "read_file", "response": {"result": "hello"}}}]}
+		],
+		"tools": [{"functionDeclarations": [{"name": "read_file", "description": "Read a file", "parameters": {"type": "object"}}]}]
+	}`)
+
+	claudeBody := ConvertGeminiRequestToKiro("kiro-model", input, false)
+	result, _ := kiroclaude.BuildKiroPayload(claudeBody, "kiro-model", "", "CLI", false, false, nil, nil)
+	var payload kiroclaude.KiroPayload
+	if err := json.Unmarshal(result, &payload); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+
+	ctx := payload.ConversationState.CurrentMessage.UserInputMessage.UserInputMessageContext
+	if ctx == nil || len(ctx.Tools) != 1 || ctx.Tools[0].ToolSpecification.Name != "read_file" {
+		t.Fatalf("expected read_file tool declaration, got %+v", ctx)
+	}
+	if len(ctx.ToolResults) != 1 || ctx.ToolResults[0].ToolUseID != "call_1" || ctx.ToolResults[0].Content[0].Text != "hello" {
+		t.Errorf("unexpected tool results: %+v", ctx.ToolResults)
+	}
+
+	var toolUse *kiroclaude.KiroToolUse
+	for _, msg := range payload.ConversationState.History {
+		if msg.AssistantResponseMessage != nil && len(msg.AssistantResponseMessage.ToolUses) > 0 {
+			toolUse = &msg.AssistantResponseMessage.ToolUses[0]
+		}
+	}
+	if toolUse == nil || toolUse.ToolUseID != "call_1" || toolUse.Name != "read_file" || toolUse.Input["path"] != "a.txt" {
+		t.Errorf("expected read_file tool use in history, got %+v", toolUse)
+	}
+}
+
+// TestKiroStreamEmitsFunctionCall verifies tool_use blocks stream back as functionCall parts.
+func TestKiroStreamEmitsFunctionCall(t *testing.T) {
+	events := []string{
+		"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_1\",\"model\":\"m\"}}",
+		"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"tool_use\",\"id\":\"t1\",\"name\":\"read_file\",\"input\":{}}}",
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\"{\\\"path\\\":\"}}",
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\"\\\"a.txt\\\"}\"}}",
+		"event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":0}",
+	}
+
+	var param any
+	var functionCall gjson.Result
+	for _, event := range events {
+		for _, chunk := range ConvertKiroStreamToGemini(context.Background(), "m", nil, nil, []byte(event), &param) {
+			if fc := gjson.Get(chunk, "candidates.0.content.parts.0.functionCall"); fc.Exists() {
+				functionCall = fc
+			}
+		}
+	}
+	if functionCall.Get("name").String() != "read_file" || functionCall.Get("args.path").String() != "a.txt" {
+		t.Fatalf("expected read_file functionCall, got %s", functionCall.Raw)
+	}
+}
+
+// TestKiroNonStreamToGemini verifies a complete Kiro response maps to Gemini parts.
+func TestKiroNonStreamToGemini(t *testing.T) {
+	response := []byte(`{
+		"id": "msg_1",
+		"content": [
+			{"type": "text", "text": "Reading."},
+			{"type": "tool_use", "id": "t1", "name": "read_file", "input": {"path": "a.txt"}}
+		],
+		"stop_reason": "tool_use",
+		"usage": {"input_tokens": 10, "output_tokens": 5}
+	}`)
+
+	out := gjson.Parse(ConvertKiroNonStreamToGemini(context.Background(), "m", nil, nil, response, nil))
+	parts := out.Get("candidates.0.content.parts").Array()
+	if len(parts) != 2 || parts[0].Get("text").String() != "Reading." {
+		t.Fatalf("unexpected parts: %s", out.Get("candidates.0.content.parts").Raw)
+	}
+	if fc := parts[1].Get("functionCall"); fc.Get("id").String() != "t1" || fc.Get("name").String() != "read_file" || fc.Get("args.path").String() != "a.txt" {
+		t.Errorf("unexpected functionCall: %s", fc.Raw)
+	}
+	if out.Get("usageMetadata.totalTokenCount").Int() != 15 {
+		t.Errorf("unexpected usage: %s", out.Get("usageMetadata").Raw)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 	"strings"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/features"
 	kirocommon "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/kiro/common"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
 	log "github.com/sirupsen/logrus"
@@ -35,7 +36,9 @@ import (
 func ConvertKiroStreamToOpenAI(ctx context.Context, model string, originalRequest, request, rawResponse []byte, param *any) []string {
 	// Initialize state if needed
 	if *param == nil {
-		*param = NewOpenAIStreamState(model)
+		streamState := NewOpenAIStreamState(model)
+		streamState.toolPolicy = parseToolCallPolicy(originalRequest)
+		*param = streamState
 	}
 	state := (*param).(*OpenAIStreamState)
 
@@ -98,6 +101,16 @@ func ConvertKiroStreamToOpenAI(ctx context.Context, model string, originalReques
 			// Tool use block starting
 			toolUseID := eventJSON.Get("content_block.id").String()
 			toolName := eventJSON.Get("content_block.name").String()
+			if !state.toolPolicy.allowCall(toolName, state.ToolCallIndex) {
+				// tool_choice or parallel_tool_calls false forbids this call
+				state.suppressedToolCalls++
+				log.Debugf("kiro-openai: dropping tool call %s (%s) disallowed by tool_choice/parallel_tool_calls", toolName, toolUseID)
+				break
+			}
+			if state.toolCallBlocks == nil {
+				state.toolCallBlocks = make(map[int]int)
+			}
+			state.toolCallBlocks[int(eventJSON.Get("index").Int())] = state.ToolCallIndex
 			chunk := BuildOpenAISSEToolCallStart(state, toolUseID, toolName)
 			results = append(results, chunk)
 			state.ToolCallIndex++
@@ -122,10 +135,10 @@ func ConvertKiroStreamToOpenAI(ctx context.Context, model string, originalReques
 		case "input_json_delta":
 			// Tool call arguments delta
 			partialJSON := eventJSON.Get("delta.partial_json").String()
-			if partialJSON != "" {
-				// Get the tool index from content block index
-				blockIndex := int(eventJSON.Get("index").Int())
-				chunk := BuildOpenAISSEToolCallArgumentsDelta(state, partialJSON, blockIndex-1) // Adjust for 0-based tool index
+			// Resolve the tool call index from the content block index; dropped calls have none
+			toolIndex, forwarded := state.toolCallBlocks[int(eventJSON.Get("index").Int())]
+			if partialJSON != "" && forwarded {
+				chunk := BuildOpenAISSEToolCallArgumentsDelta(state, partialJSON, toolIndex)
 				results = append(results, chunk)
 			}
 		}
@@ -136,6 +149,10 @@ func ConvertKiroStreamToOpenAI(ctx context.Context, model string, originalReques
 	case "message_delta":
 		// Message delta with stop_reason
 		stopReason := eventJSON.Get("delta.stop_reason").String()
+		if stopReason == "tool_use" && state.suppressedToolCalls > 0 && state.ToolCallIndex == 0 {
+			// Every tool call was dropped, so the turn ends with text only
+			stopReason = "end_turn"
+		}
 		finishReason := mapKiroStopReasonToOpenAI(stopReason)
 		if finishReason != "" {
 			chunk := BuildOpenAISSEFinish(state, finishReason)
@@ -228,6 +245,15 @@ func ConvertKiroNonStreamToOpenAI(ctx context.Context, model string, originalReq
 		}
 	}
 
+	// Enforce tool_choice and parallel_tool_calls, which Kiro can only be asked to follow
+	if allowed := parseToolCallPolicy(originalRequest).filterCalls(toolUses); len(allowed) != len(toolUses) {
+		log.Debugf("kiro-openai: dropped %d tool calls disallowed by tool_choice/parallel_tool_calls", len(toolUses)-len(allowed))
+		toolUses = allowed
+		if len(toolUses) == 0 && stopReason == "tool_use" {
+			stopReason = "end_turn"
+		}
+	}
+
 	// Extract usage
 	usageInfo := usage.Detail{
 		InputTokens:  response.Get("usage.input_tokens").Int(),
@@ -257,7 +283,7 @@ func ParseClaudeEvent(rawEvent []byte) (eventType string, eventData []byte) {
 // ExtractThinkingFromContent parses content to extract thinking blocks.
 // Returns cleaned content (without thinking tags) and whether thinking was found.
 func ExtractThinkingFromContent(content string) (string, string, bool) {
-	if !strings.Contains(content, kirocommon.ThinkingStartTag) {
+	if !strings.Contains(content, kirocommon.ThinkingStartTag) || !features.Enabled(features.ThinkingExtraction) {
 		return content, "", false
 	}
 
@@ -0,0 +1,53 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+// Run with: go test ./internal/translator/kiro/openai -run '^$' -fuzz FuzzBuildKiroPayloadFromOpenAI
+func FuzzBuildKiroPayloadFromOpenAI(f *testing.F) {
+	seeds := []string{
+		`{"messages":[{"role":"user","content":"hi"}]}`,
+		`{"messages":[{"role":"user","content":[{"type":"text","text":"a"},{"type":"image_url","image_url":{"url":"data:image/png;base64,AAAA"}}]}]}`,
+		`{"messages":[{"role":"user","content":[1,"two",null,{"type":"text"},{"type":"image_url"}]}]}`,
+		`{"messages":[{"role":"assistant","content":{"text":"obj"},"tool_calls":[{"id":1,"function":{"name":null,"arguments":"{bad"}}]}]}`,
+		`{"messages":[{"role":"tool","tool_call_id":"x","content":[{"type":"text","text":"\u0000😀"}]},{"role":"user"}]}`,
+		`{"messages":[{"role":"system","content":"sys"},{"role":"system","content":["x"]}],"tools":[{"type":"function","function":{"name":"","parameters":"str"}}],"tool_choice":{"type":"function"}}`,
+		`{"messages":"not-an-array","tools":{},"response_format":{"type":"json_schema","json_schema":7}}`,
+		`{"messages":[{"role":"assistant","content":""},{"role":"assistant","content":"x"},{"role":"user","content":"\xff\xfe"}]}`,
+		`[]`,
+		``,
+	}
+	for _, seed := range seeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, body []byte) {
+		payload, _ := BuildKiroPayloadFromOpenAI(body, "kiro-model", "arn", "CLI", false, false, nil, nil)
+		if payload != nil && !json.Valid(payload) {
+			t.Fatalf("invalid JSON payload for input %q", body)
+		}
+		ConvertKiroNonStreamToOpenAI(context.Background(), "m", body, nil, body, nil)
+		var param any
+		ConvertKiroStreamToOpenAI(context.Background(), "m", body, nil, body, &param)
+	})
+}
+
+// Run with: go test ./internal/translator/kiro/openai -run '^$' -fuzz FuzzBuildOpenAIResponse
+func FuzzBuildOpenAIResponse(f *testing.F) {
+	f.Add("hello", "reasoning", "Read", `{"path":"a"}`, "end_turn")
+	f.Add("", "", "", `not json`, "tool_use")
+	f.Add("\u0000\xed\xa0\xbd", "\xff", "名前", `{"nested":{"deep":[1,{"x":null}]}}`, "max_tokens")
+	f.Fuzz(func(t *testing.T, content, reasoning, toolName, toolInput, stopReason string) {
+		var input map[string]interface{}
+		_ = json.Unmarshal([]byte(toolInput), &input)
+		toolUses := []KiroToolUse{{ToolUseID: "t1", Name: toolName, Input: input}}
+		out := BuildOpenAIResponseWithReasoning(content, reasoning, toolUses, "m", usage.Detail{InputTokens: 1, OutputTokens: 2}, stopReason)
+		if !json.Valid(out) {
+			t.Fatalf("invalid JSON response: %s", out)
+		}
+	})
+}
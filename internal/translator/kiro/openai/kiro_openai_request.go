@@ -8,10 +8,9 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
-	"time"
 	"unicode/utf8"
 
-	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/idgen"
 	kiroclaude "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/kiro/claude"
 	kirocommon "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/kiro/common"
 	log "github.com/sirupsen/logrus"
@@ -73,10 +72,18 @@ type KiroUserInputMessage struct {
 	UserInputMessageContext *KiroUserInputMessageContext `json:"userInputMessageContext,omitempty"`
 }
 
-// KiroUserInputMessageContext contains tool-related context
+// KiroUserInputMessageContext contains tool-related context and additional context entries
 type KiroUserInputMessageContext struct {
-	ToolResults []KiroToolResult  `json:"toolResults,omitempty"`
-	Tools       []KiroToolWrapper `json:"tools,omitempty"`
+	ToolResults       []KiroToolResult        `json:"toolResults,omitempty"`
+	Tools             []KiroToolWrapper       `json:"tools,omitempty"`
+	AdditionalContext []KiroAdditionalContext `json:"additionalContext,omitempty"`
+}
+
+// KiroAdditionalContext is a named context entry; the system prompt travels in one of these
+type KiroAdditionalContext struct {
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	InnerContext string `json:"innerContext"`
 }
 
 // KiroToolResult represents a tool execution result
@@ -136,7 +143,8 @@ func ConvertOpenAIRequestToKiro(modelName string, inputRawJSON []byte, stream bo
 // isAgentic parameter enables chunked write optimization prompt for -agentic model variants.
 // isChatOnly parameter disables tool calling for -chat model variants (pure conversation mode).
 // headers parameter allows checking Anthropic-Beta header for thinking mode detection.
-// metadata parameter is kept for API compatibility but no longer used for thinking configuration.
+// metadata parameter carries builder options (see kirocommon.MetadataSystemPromptContext); it is no longer used for thinking configuration.
+// The system prompt is prepended to the current message unless the context option sends it as an additionalContext entry.
 // Returns the payload and a boolean indicating whether thinking mode was injected.
 func BuildKiroPayloadFromOpenAI(openaiBody []byte, modelID, profileArn, origin string, isAgentic, isChatOnly bool, headers http.Header, metadata map[string]any) ([]byte, bool) {
 	// Extract max_tokens for potential use in inferenceConfig
@@ -184,7 +192,7 @@ func BuildKiroPayloadFromOpenAI(openaiBody []byte, modelID, profileArn, origin s
 	systemPrompt := extractSystemPromptFromOpenAI(messages)
 
 	// Inject timestamp context
-	timestamp := time.Now().Format("2006-01-02 15:04:05 MST")
+	timestamp := idgen.Now().Format("2006-01-02 15:04:05 MST")
 	timestampContext := fmt.Sprintf("[Context: Current time is %s]", timestamp)
 	if systemPrompt != "" {
 		systemPrompt = timestampContext + "\n\n" + systemPrompt
@@ -212,6 +220,15 @@ func BuildKiroPayloadFromOpenAI(openaiBody []byte, modelID, profileArn, origin s
 		log.Debugf("kiro-openai: injected tool_choice hint into system prompt")
 	}
 
+	// Handle parallel_tool_calls parameter - Kiro doesn't support it natively either
+	if parallelHint := extractParallelToolCallsHint(openaiBody); parallelHint != "" {
+		if systemPrompt != "" {
+			systemPrompt += "\n"
+		}
+		systemPrompt += parallelHint
+		log.Debugf("kiro-openai: injected parallel_tool_calls hint into system prompt")
+	}
+
 	// Handle response_format parameter - Kiro doesn't support it natively, so we inject system prompt hints
 	// OpenAI response_format: {"type": "json_object"} or {"type": "json_schema", "json_schema": {...}}
 	responseFormatHint := extractResponseFormatHint(openaiBody)
@@ -227,8 +244,8 @@ func BuildKiroPayloadFromOpenAI(openaiBody []byte, modelID, profileArn, origin s
 	// Supports OpenAI reasoning_effort parameter, model name hints, and Anthropic-Beta header
 	thinkingEnabled := checkThinkingModeFromOpenAIWithHeaders(openaiBody, headers)
 
-	// Convert OpenAI tools to Kiro format
-	kiroTools := convertOpenAIToolsToKiro(tools)
+	// Convert OpenAI tools to Kiro format, narrowed to what tool_choice allows
+	kiroTools := parseToolCallPolicy(openaiBody).filterTools(convertOpenAIToolsToKiro(tools), historyToolNames(messages))
 
 	// Thinking mode implementation:
 	// Kiro API supports official thinking/reasoning mode via <thinking_mode> tag.
@@ -249,18 +266,31 @@ func BuildKiroPayloadFromOpenAI(openaiBody []byte, modelID, profileArn, origin s
 	// Process messages and build history
 	history, currentUserMsg, currentToolResults := processOpenAIMessages(messages, modelID, origin)
 
+	// The system prompt goes into the current message only: prepended to its content or, with the
+	// context option, as a dedicated context entry. History turns never carry it.
+	inlineSystemPrompt := !kirocommon.SystemPromptAsContext(metadata)
+	var systemContext []KiroAdditionalContext
+	if !inlineSystemPrompt {
+		systemContext = buildSystemPromptContext(systemPrompt)
+	}
+
 	// Build content with system prompt
 	if currentUserMsg != nil {
-		currentUserMsg.Content = buildFinalContent(currentUserMsg.Content, systemPrompt, currentToolResults)
+		if inlineSystemPrompt {
+			currentUserMsg.Content = buildFinalContent(currentUserMsg.Content, systemPrompt, currentToolResults)
+		} else {
+			currentUserMsg.Content = buildFinalContent(currentUserMsg.Content, "", currentToolResults)
+		}
 
 		// Deduplicate currentToolResults
 		currentToolResults = deduplicateToolResults(currentToolResults)
 
-		// Build userInputMessageContext with tools and tool results
-		if len(kiroTools) > 0 || len(currentToolResults) > 0 {
+		// Build userInputMessageContext with tools, tool results and the system prompt context
+		if len(kiroTools) > 0 || len(currentToolResults) > 0 || len(systemContext) > 0 {
 			currentUserMsg.UserInputMessageContext = &KiroUserInputMessageContext{
-				Tools:       kiroTools,
-				ToolResults: currentToolResults,
+				Tools:             kiroTools,
+				ToolResults:       currentToolResults,
+				AdditionalContext: systemContext,
 			}
 		}
 	}
@@ -269,7 +299,7 @@ func BuildKiroPayloadFromOpenAI(openaiBody []byte, modelID, profileArn, origin s
 	var currentMessage KiroCurrentMessage
 	if currentUserMsg != nil {
 		currentMessage = KiroCurrentMessage{UserInputMessage: *currentUserMsg}
-	} else {
+	} else if inlineSystemPrompt {
 		fallbackContent := ""
 		if systemPrompt != "" {
 			fallbackContent = "--- SYSTEM PROMPT ---\n" + systemPrompt + "\n--- END SYSTEM PROMPT ---\n"
@@ -279,6 +309,17 @@ func BuildKiroPayloadFromOpenAI(openaiBody []byte, modelID, profileArn, origin s
 			ModelID: modelID,
 			Origin:  origin,
 		}}
+	} else {
+		currentMessage = KiroCurrentMessage{UserInputMessage: KiroUserInputMessage{
+			Content: "Continue",
+			ModelID: modelID,
+			Origin:  origin,
+		}}
+		if len(systemContext) > 0 {
+			currentMessage.UserInputMessage.UserInputMessageContext = &KiroUserInputMessageContext{
+				AdditionalContext: systemContext,
+			}
+		}
 	}
 
 	// Build inferenceConfig if we have any inference parameters
@@ -300,7 +341,7 @@ func BuildKiroPayloadFromOpenAI(openaiBody []byte, modelID, profileArn, origin s
 	payload := KiroPayload{
 		ConversationState: KiroConversationState{
 			ChatTriggerType: "MANUAL",
-			ConversationID:  uuid.New().String(),
+			ConversationID:  idgen.UUID(),
 			CurrentMessage:  currentMessage,
 			History:         history,
 		},
@@ -668,6 +709,18 @@ func buildAssistantMessageFromOpenAI(msg gjson.Result) KiroAssistantResponseMess
 	}
 }
 
+// buildSystemPromptContext wraps the system prompt in the additionalContext entry sent with the current message
+func buildSystemPromptContext(systemPrompt string) []KiroAdditionalContext {
+	if strings.TrimSpace(systemPrompt) == "" {
+		return nil
+	}
+	return []KiroAdditionalContext{{
+		Name:         kirocommon.SystemPromptContextName,
+		Description:  "System instructions for this conversation",
+		InnerContext: systemPrompt,
+	}}
+}
+
 // buildFinalContent builds the final content with system prompt
 func buildFinalContent(content, systemPrompt string, toolResults []KiroToolResult) string {
 	var contentBuilder strings.Builder
@@ -784,8 +837,8 @@ func extractToolChoiceHint(openaiBody []byte) string {
 	if toolChoice.Type == gjson.String {
 		switch toolChoice.String() {
 		case "none":
-			// Note: When tool_choice is "none", we should ideally not pass tools at all
-			// But since we can't modify tool passing here, we add a strong hint
+			// Tools are also withheld when possible (see toolCallPolicy.filterTools), but Kiro
+			// needs them declared once the history uses them, so add a strong hint as well
 			return "[INSTRUCTION: Do NOT use any tools. Respond with text only.]"
 		case "required":
 			return "[INSTRUCTION: You MUST use at least one of the available tools to respond. Do not respond with text only - always make a tool call.]"
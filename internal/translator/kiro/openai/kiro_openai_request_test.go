@@ -2,7 +2,10 @@ package openai
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
+
+	kirocommon "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/kiro/common"
 )
 
 // TestToolResultsAttachedToCurrentMessage verifies that tool results from "tool" role messages
@@ -384,3 +387,55 @@ func TestAssistantEndsConversation(t *testing.T) {
 		t.Error("Expected a 'Continue' message to be created when assistant is last")
 	}
 }
+
+// TestSystemPromptNeverInHistory verifies that the system prompt is sent as a context entry of the
+// current message by default, falls back to the current message content when inlined, and never
+// appears in history user turns either way.
+func TestSystemPromptNeverInHistory(t *testing.T) {
+	const marker = "SYSTEM-MARKER-7f3a"
+	input := []byte(`{
+		"model": "kiro-claude-sonnet-4-5",
+		"messages": [
+			{"role": "system", "content": "` + marker + `"},
+			{"role": "user", "content": "first question"},
+			{"role": "assistant", "content": "first answer"},
+			{"role": "user", "content": "second question"}
+		]
+	}`)
+
+	for _, inline := range []bool{false, true} {
+		var metadata map[string]any
+		if !inline {
+			metadata = map[string]any{kirocommon.MetadataSystemPromptContext: true}
+		}
+		result, _ := BuildKiroPayloadFromOpenAI(input, "kiro-model", "", "CLI", false, false, nil, metadata)
+
+		var payload KiroPayload
+		if err := json.Unmarshal(result, &payload); err != nil {
+			t.Fatalf("Failed to unmarshal result: %v", err)
+		}
+		for i, msg := range payload.ConversationState.History {
+			if msg.UserInputMessage != nil && strings.Contains(msg.UserInputMessage.Content, marker) {
+				t.Errorf("inline=%v: system prompt leaked into history user turn %d", inline, i)
+			}
+		}
+
+		current := payload.ConversationState.CurrentMessage.UserInputMessage
+		if inline {
+			if !strings.Contains(current.Content, marker) {
+				t.Errorf("inline=true: expected system prompt in current content, got %q", current.Content)
+			}
+			continue
+		}
+		if strings.Contains(current.Content, marker) {
+			t.Errorf("inline=false: system prompt must not be in current content, got %q", current.Content)
+		}
+		if current.Content != "second question" {
+			t.Errorf("inline=false: current content = %q, want %q", current.Content, "second question")
+		}
+		ctx := current.UserInputMessageContext
+		if ctx == nil || len(ctx.AdditionalContext) != 1 || ctx.AdditionalContext[0].Name != kirocommon.SystemPromptContextName || !strings.Contains(ctx.AdditionalContext[0].InnerContext, marker) {
+			t.Errorf("inline=false: expected system prompt context entry, got %+v", ctx)
+		}
+	}
+}
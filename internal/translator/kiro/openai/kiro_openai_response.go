@@ -6,17 +6,12 @@ package openai
 import (
 	"encoding/json"
 	"fmt"
-	"sync/atomic"
-	"time"
 
-	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/idgen"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
 	log "github.com/sirupsen/logrus"
 )
 
-// functionCallIDCounter provides a process-wide unique counter for function call identifiers.
-var functionCallIDCounter uint64
-
 // BuildOpenAIResponse constructs an OpenAI Chat Completions-compatible response.
 // Supports tool_calls when tools are present in the response.
 // stopReason is passed from upstream; fallback logic applied if empty.
@@ -73,9 +68,9 @@ func BuildOpenAIResponseWithReasoning(content, reasoningContent string, toolUses
 	}
 
 	response := map[string]interface{}{
-		"id":      "chatcmpl-" + uuid.New().String()[:24],
+		"id":      "chatcmpl-" + idgen.UUID()[:24],
 		"object":  "chat.completion",
-		"created": time.Now().Unix(),
+		"created": idgen.Now().Unix(),
 		"model":   model,
 		"choices": []map[string]interface{}{
 			{
@@ -113,159 +108,9 @@ func mapKiroStopReasonToOpenAI(stopReason string) string {
 	}
 }
 
-// BuildOpenAIStreamChunk constructs an OpenAI Chat Completions streaming chunk.
-// This is the delta format used in streaming responses.
-func BuildOpenAIStreamChunk(model string, deltaContent string, deltaToolCalls []map[string]interface{}, finishReason string, index int) []byte {
-	delta := map[string]interface{}{}
-
-	// First chunk should include role
-	if index == 0 && deltaContent == "" && len(deltaToolCalls) == 0 {
-		delta["role"] = "assistant"
-		delta["content"] = ""
-	} else if deltaContent != "" {
-		delta["content"] = deltaContent
-	}
-
-	// Add tool_calls delta if present
-	if len(deltaToolCalls) > 0 {
-		delta["tool_calls"] = deltaToolCalls
-	}
-
-	choice := map[string]interface{}{
-		"index": 0,
-		"delta": delta,
-	}
-
-	if finishReason != "" {
-		choice["finish_reason"] = finishReason
-	} else {
-		choice["finish_reason"] = nil
-	}
-
-	chunk := map[string]interface{}{
-		"id":      "chatcmpl-" + uuid.New().String()[:12],
-		"object":  "chat.completion.chunk",
-		"created": time.Now().Unix(),
-		"model":   model,
-		"choices": []map[string]interface{}{choice},
-	}
-
-	result, _ := json.Marshal(chunk)
-	return result
-}
-
-// BuildOpenAIStreamChunkWithToolCallStart creates a stream chunk for tool call start
-func BuildOpenAIStreamChunkWithToolCallStart(model string, toolUseID, toolName string, toolIndex int) []byte {
-	toolCall := map[string]interface{}{
-		"index": toolIndex,
-		"id":    toolUseID,
-		"type":  "function",
-		"function": map[string]interface{}{
-			"name":      toolName,
-			"arguments": "",
-		},
-	}
-
-	delta := map[string]interface{}{
-		"tool_calls": []map[string]interface{}{toolCall},
-	}
-
-	choice := map[string]interface{}{
-		"index":         0,
-		"delta":         delta,
-		"finish_reason": nil,
-	}
-
-	chunk := map[string]interface{}{
-		"id":      "chatcmpl-" + uuid.New().String()[:12],
-		"object":  "chat.completion.chunk",
-		"created": time.Now().Unix(),
-		"model":   model,
-		"choices": []map[string]interface{}{choice},
-	}
-
-	result, _ := json.Marshal(chunk)
-	return result
-}
-
-// BuildOpenAIStreamChunkWithToolCallDelta creates a stream chunk for tool call arguments delta
-func BuildOpenAIStreamChunkWithToolCallDelta(model string, argumentsDelta string, toolIndex int) []byte {
-	toolCall := map[string]interface{}{
-		"index": toolIndex,
-		"function": map[string]interface{}{
-			"arguments": argumentsDelta,
-		},
-	}
-
-	delta := map[string]interface{}{
-		"tool_calls": []map[string]interface{}{toolCall},
-	}
-
-	choice := map[string]interface{}{
-		"index":         0,
-		"delta":         delta,
-		"finish_reason": nil,
-	}
-
-	chunk := map[string]interface{}{
-		"id":      "chatcmpl-" + uuid.New().String()[:12],
-		"object":  "chat.completion.chunk",
-		"created": time.Now().Unix(),
-		"model":   model,
-		"choices": []map[string]interface{}{choice},
-	}
-
-	result, _ := json.Marshal(chunk)
-	return result
-}
-
-// BuildOpenAIStreamDoneChunk creates the final [DONE] stream event
-func BuildOpenAIStreamDoneChunk() []byte {
-	return []byte("data: [DONE]")
-}
-
-// BuildOpenAIStreamFinishChunk creates the final chunk with finish_reason
-func BuildOpenAIStreamFinishChunk(model string, finishReason string) []byte {
-	choice := map[string]interface{}{
-		"index":         0,
-		"delta":         map[string]interface{}{},
-		"finish_reason": finishReason,
-	}
-
-	chunk := map[string]interface{}{
-		"id":      "chatcmpl-" + uuid.New().String()[:12],
-		"object":  "chat.completion.chunk",
-		"created": time.Now().Unix(),
-		"model":   model,
-		"choices": []map[string]interface{}{choice},
-	}
-
-	result, _ := json.Marshal(chunk)
-	return result
-}
-
-// BuildOpenAIStreamUsageChunk creates a chunk with usage information (optional, for stream_options.include_usage)
-func BuildOpenAIStreamUsageChunk(model string, usageInfo usage.Detail) []byte {
-	chunk := map[string]interface{}{
-		"id":      "chatcmpl-" + uuid.New().String()[:12],
-		"object":  "chat.completion.chunk",
-		"created": time.Now().Unix(),
-		"model":   model,
-		"choices": []map[string]interface{}{},
-		"usage": map[string]interface{}{
-			"prompt_tokens":     usageInfo.InputTokens,
-			"completion_tokens": usageInfo.OutputTokens,
-			"total_tokens":      usageInfo.InputTokens + usageInfo.OutputTokens,
-		},
-	}
-
-	result, _ := json.Marshal(chunk)
-	return result
-}
-
 // GenerateToolCallID generates a unique tool call ID in OpenAI format
 func GenerateToolCallID(toolName string) string {
-	return fmt.Sprintf("call_%s_%d_%d", toolName[:min(8, len(toolName))], time.Now().UnixNano(), atomic.AddUint64(&functionCallIDCounter, 1))
+	return fmt.Sprintf("call_%s_%d_%d", toolName[:min(8, len(toolName))], idgen.Now().UnixNano(), idgen.Seq())
 }
 
 // min returns the minimum of two integers
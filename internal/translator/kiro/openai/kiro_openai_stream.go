@@ -5,9 +5,8 @@ package openai
 
 import (
 	"encoding/json"
-	"time"
 
-	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/idgen"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
 )
 
@@ -19,6 +18,14 @@ type OpenAIStreamState struct {
 	Model             string
 	ResponseID        string
 	Created           int64
+
+	// toolPolicy enforces tool_choice and parallel_tool_calls on the returned tool calls.
+	toolPolicy toolCallPolicy
+	// toolCallBlocks maps the content block index of every forwarded tool call to its OpenAI
+	// tool call index; blocks of tool calls dropped by toolPolicy are absent.
+	toolCallBlocks map[int]int
+	// suppressedToolCalls counts the tool calls dropped by toolPolicy.
+	suppressedToolCalls int
 }
 
 // NewOpenAIStreamState creates a new stream state for tracking
@@ -28,8 +35,8 @@ func NewOpenAIStreamState(model string) *OpenAIStreamState {
 		ToolCallIndex:     0,
 		HasSentFirstChunk: false,
 		Model:             model,
-		ResponseID:        "chatcmpl-" + uuid.New().String()[:24],
-		Created:           time.Now().Unix(),
+		ResponseID:        "chatcmpl-" + idgen.UUID()[:24],
+		Created:           idgen.Now().Unix(),
 	}
 }
 
@@ -116,17 +123,11 @@ func BuildOpenAISSEFinish(state *OpenAIStreamState, finishReason string) string
 
 // BuildOpenAISSEUsage creates an SSE event with usage information
 func BuildOpenAISSEUsage(state *OpenAIStreamState, usageInfo usage.Detail) string {
-	chunk := map[string]interface{}{
-		"id":      state.ResponseID,
-		"object":  "chat.completion.chunk",
-		"created": state.Created,
-		"model":   state.Model,
-		"choices": []map[string]interface{}{},
-		"usage": map[string]interface{}{
-			"prompt_tokens":     usageInfo.InputTokens,
-			"completion_tokens": usageInfo.OutputTokens,
-			"total_tokens":      usageInfo.InputTokens + usageInfo.OutputTokens,
-		},
+	chunk := buildChunkEnvelope(state, []map[string]interface{}{})
+	chunk["usage"] = map[string]interface{}{
+		"prompt_tokens":     usageInfo.InputTokens,
+		"completion_tokens": usageInfo.OutputTokens,
+		"total_tokens":      usageInfo.InputTokens + usageInfo.OutputTokens,
 	}
 	result, _ := json.Marshal(chunk)
 	return FormatSSEEvent(result)
@@ -153,12 +154,19 @@ func buildBaseChunk(state *OpenAIStreamState, delta map[string]interface{}, fini
 		choice["finish_reason"] = nil
 	}
 
+	return buildChunkEnvelope(state, []map[string]interface{}{choice})
+}
+
+// buildChunkEnvelope creates the chunk fields shared by every event of a response.
+// All chunks of one stream carry the state's ResponseID and Created, as OpenAI clients
+// group chunks by id.
+func buildChunkEnvelope(state *OpenAIStreamState, choices []map[string]interface{}) map[string]interface{} {
 	return map[string]interface{}{
 		"id":      state.ResponseID,
 		"object":  "chat.completion.chunk",
 		"created": state.Created,
 		"model":   state.Model,
-		"choices": []map[string]interface{}{choice},
+		"choices": choices,
 	}
 }
 
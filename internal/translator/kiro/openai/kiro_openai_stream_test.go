@@ -0,0 +1,45 @@
+package openai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+// TestStreamChunksShareResponseID verifies every chunk of one stream carries the same id and created.
+func TestStreamChunksShareResponseID(t *testing.T) {
+	events := []string{
+		`event: message_start` + "\n" + `data: {"type":"message_start"}`,
+		`event: content_block_start` + "\n" + `data: {"type":"content_block_start","index":0,"content_block":{"type":"text"}}`,
+		`event: content_block_delta` + "\n" + `data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"hello"}}`,
+		`event: content_block_start` + "\n" + `data: {"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"t1","name":"Read"}}`,
+		`event: content_block_delta` + "\n" + `data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{}"}}`,
+		`event: message_delta` + "\n" + `data: {"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"input_tokens":3,"output_tokens":5}}`,
+	}
+	var param any
+	var chunks []string
+	for _, event := range events {
+		for _, chunk := range ConvertKiroStreamToOpenAI(context.Background(), "m", nil, nil, []byte(event), &param) {
+			if gjson.Valid(chunk) {
+				chunks = append(chunks, chunk)
+			}
+		}
+	}
+	if len(chunks) < 4 {
+		t.Fatalf("expected at least 4 chunks, got %d: %v", len(chunks), chunks)
+	}
+	id := gjson.Get(chunks[0], "id").String()
+	created := gjson.Get(chunks[0], "created").Int()
+	if id == "" || created == 0 {
+		t.Fatalf("first chunk lacks id or created: %s", chunks[0])
+	}
+	for _, chunk := range chunks[1:] {
+		if got := gjson.Get(chunk, "id").String(); got != id {
+			t.Errorf("chunk id = %q, want %q: %s", got, id, chunk)
+		}
+		if got := gjson.Get(chunk, "created").Int(); got != created {
+			t.Errorf("chunk created = %d, want %d: %s", got, created, chunk)
+		}
+	}
+}
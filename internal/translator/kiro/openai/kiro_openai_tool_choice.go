@@ -0,0 +1,103 @@
+// Package openai provides tool_choice and parallel_tool_calls emulation for Kiro.
+// Kiro has no native controls for either, so the constraints are approximated by narrowing
+// the tool list and injecting prompt hints on the way in, and enforced on the tool calls
+// returned by the model on the way back.
+package openai
+
+import (
+	"github.com/tidwall/gjson"
+)
+
+// toolCallPolicy captures how an OpenAI request constrains tool calls.
+type toolCallPolicy struct {
+	// disabled is set by tool_choice "none".
+	disabled bool
+	// forced is the function named by tool_choice {"type":"function",...}.
+	forced string
+	// single is set by parallel_tool_calls false.
+	single bool
+}
+
+// parseToolCallPolicy reads tool_choice and parallel_tool_calls from an OpenAI request.
+func parseToolCallPolicy(openaiBody []byte) toolCallPolicy {
+	var policy toolCallPolicy
+	toolChoice := gjson.GetBytes(openaiBody, "tool_choice")
+	switch {
+	case toolChoice.Type == gjson.String:
+		policy.disabled = toolChoice.String() == "none"
+	case toolChoice.IsObject() && toolChoice.Get("type").String() == "function":
+		policy.forced = toolChoice.Get("function.name").String()
+	}
+	if parallel := gjson.GetBytes(openaiBody, "parallel_tool_calls"); parallel.Exists() && !parallel.Bool() {
+		policy.single = true
+	}
+	return policy
+}
+
+// filterTools narrows the tools sent to Kiro: none for tool_choice "none" and only the forced
+// function for a named tool_choice. Tools already called in the conversation stay declared,
+// since Kiro rejects histories referencing undeclared tools; the prompt hint and response-side
+// enforcement keep the model from calling them again.
+func (p toolCallPolicy) filterTools(tools []KiroToolWrapper, historyTools map[string]bool) []KiroToolWrapper {
+	if len(tools) == 0 || (!p.disabled && p.forced == "") {
+		return tools
+	}
+	var kept []KiroToolWrapper
+	forcedFound := false
+	for _, tool := range tools {
+		name := tool.ToolSpecification.Name
+		forced := !p.disabled && name == p.forced
+		forcedFound = forcedFound || forced
+		if forced || historyTools[name] {
+			kept = append(kept, tool)
+		}
+	}
+	if p.forced != "" && !forcedFound {
+		// An unknown forced tool cannot be honoured; leave the choice to the model.
+		return tools
+	}
+	return kept
+}
+
+// allowCall reports whether a call of the named tool may be returned after emitted calls were
+// already returned.
+func (p toolCallPolicy) allowCall(name string, emitted int) bool {
+	if p.disabled || (p.forced != "" && name != p.forced) {
+		return false
+	}
+	return !p.single || emitted == 0
+}
+
+// filterCalls drops the tool calls the policy does not allow, keeping the first ones.
+func (p toolCallPolicy) filterCalls(toolUses []KiroToolUse) []KiroToolUse {
+	kept := toolUses[:0:0]
+	for _, toolUse := range toolUses {
+		if p.allowCall(toolUse.Name, len(kept)) {
+			kept = append(kept, toolUse)
+		}
+	}
+	return kept
+}
+
+// extractParallelToolCallsHint returns a system prompt hint for parallel_tool_calls false.
+func extractParallelToolCallsHint(openaiBody []byte) string {
+	if !parseToolCallPolicy(openaiBody).single {
+		return ""
+	}
+	return "[INSTRUCTION: Call at most ONE tool per response. Wait for its result before making another tool call.]"
+}
+
+// historyToolNames returns the names of the tools the OpenAI messages already called.
+func historyToolNames(messages gjson.Result) map[string]bool {
+	names := make(map[string]bool)
+	messages.ForEach(func(_, msg gjson.Result) bool {
+		msg.Get("tool_calls").ForEach(func(_, call gjson.Result) bool {
+			if name := call.Get("function.name").String(); name != "" {
+				names[name] = true
+			}
+			return true
+		})
+		return true
+	})
+	return names
+}
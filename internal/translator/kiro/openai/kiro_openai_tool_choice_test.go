@@ -0,0 +1,231 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+const toolChoiceTools = `[
+	{"type": "function", "function": {"name": "Read", "description": "Read a file", "parameters": {"type": "object"}}},
+	{"type": "function", "function": {"name": "Write", "description": "Write a file", "parameters": {"type": "object"}}}
+]`
+
+func buildToolChoicePayload(t *testing.T, extra string) KiroPayload {
+	t.Helper()
+	input := []byte(`{"messages": [{"role": "user", "content": "hi"}], "tools": ` + toolChoiceTools + extra + `}`)
+	result, _ := BuildKiroPayloadFromOpenAI(input, "kiro-model", "", "CLI", false, false, nil, nil)
+	var payload KiroPayload
+	if err := json.Unmarshal(result, &payload); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	return payload
+}
+
+// TestToolChoiceNarrowsTools verifies that tool_choice restricts the tools sent to Kiro.
+func TestToolChoiceNarrowsTools(t *testing.T) {
+	payload := buildToolChoicePayload(t, `, "tool_choice": "none"`)
+	if ctx := payload.ConversationState.CurrentMessage.UserInputMessage.UserInputMessageContext; ctx != nil && len(ctx.Tools) != 0 {
+		t.Errorf("tool_choice none: expected no tools, got %d", len(ctx.Tools))
+	}
+
+	payload = buildToolChoicePayload(t, `, "tool_choice": {"type": "function", "function": {"name": "Write"}}`)
+	ctx := payload.ConversationState.CurrentMessage.UserInputMessage.UserInputMessageContext
+	if ctx == nil || len(ctx.Tools) != 1 || ctx.Tools[0].ToolSpecification.Name != "Write" {
+		t.Errorf("named tool_choice: expected only Write, got %+v", ctx)
+	}
+
+	payload = buildToolChoicePayload(t, `, "parallel_tool_calls": false`)
+	ctx = payload.ConversationState.CurrentMessage.UserInputMessage.UserInputMessageContext
+	if ctx == nil || len(ctx.Tools) != 2 {
+		t.Errorf("parallel_tool_calls false: expected both tools, got %+v", ctx)
+	}
+	if !strings.Contains(payload.ConversationState.CurrentMessage.UserInputMessage.Content, "at most ONE tool") {
+		t.Errorf("parallel_tool_calls false: expected single tool call hint in system prompt")
+	}
+}
+
+// TestToolChoiceKeepsToolsWithHistory verifies tools stay declared when the history uses them.
+func TestToolChoiceKeepsToolsWithHistory(t *testing.T) {
+	build := func(toolChoice string) []KiroToolWrapper {
+		input := []byte(`{
+			"messages": [
+				{"role": "user", "content": "read it"},
+				{"role": "assistant", "content": "", "tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "Read", "arguments": "{}"}}]},
+				{"role": "tool", "tool_call_id": "call_1", "content": "data"},
+				{"role": "user", "content": "now summarize"}
+			],
+			"tools": ` + toolChoiceTools + `,
+			"tool_choice": ` + toolChoice + `
+		}`)
+		result, _ := BuildKiroPayloadFromOpenAI(input, "kiro-model", "", "CLI", false, false, nil, nil)
+		var payload KiroPayload
+		if err := json.Unmarshal(result, &payload); err != nil {
+			t.Fatalf("Failed to unmarshal result: %v", err)
+		}
+		if ctx := payload.ConversationState.CurrentMessage.UserInputMessage.UserInputMessageContext; ctx != nil {
+			return ctx.Tools
+		}
+		return nil
+	}
+	names := func(tools []KiroToolWrapper) []string {
+		var out []string
+		for _, tool := range tools {
+			out = append(out, tool.ToolSpecification.Name)
+		}
+		return out
+	}
+
+	if got := names(build(`"none"`)); !reflect.DeepEqual(got, []string{"Read"}) {
+		t.Errorf("tool_choice none: expected only the tool used by the history, got %v", got)
+	}
+	if got := names(build(`{"type": "function", "function": {"name": "Write"}}`)); !reflect.DeepEqual(got, []string{"Read", "Write"}) {
+		t.Errorf("named tool_choice: expected the forced tool and the history tool, got %v", got)
+	}
+}
+
+// TestToolChoiceEnforcedNonStream verifies disallowed tool calls are dropped and finish_reason follows.
+func TestToolChoiceEnforcedNonStream(t *testing.T) {
+	response := []byte(`{
+		"stop_reason": "tool_use",
+		"content": [
+			{"type": "text", "text": "ok"},
+			{"type": "tool_use", "id": "t1", "name": "Read", "input": {"path": "a"}},
+			{"type": "tool_use", "id": "t2", "name": "Read", "input": {"path": "b"}}
+		],
+		"usage": {"input_tokens": 1, "output_tokens": 2}
+	}`)
+
+	out := ConvertKiroNonStreamToOpenAI(context.Background(), "m", []byte(`{"parallel_tool_calls": false}`), nil, response, nil)
+	if calls := gjson.Get(out, "choices.0.message.tool_calls").Array(); len(calls) != 1 || calls[0].Get("id").String() != "t1" {
+		t.Errorf("parallel_tool_calls false: expected only t1, got %s", out)
+	}
+	if got := gjson.Get(out, "choices.0.finish_reason").String(); got != "tool_calls" {
+		t.Errorf("parallel_tool_calls false: finish_reason = %q, want tool_calls", got)
+	}
+
+	forced := []byte(`{
+		"stop_reason": "tool_use",
+		"content": [
+			{"type": "tool_use", "id": "t1", "name": "Read", "input": {"path": "a"}},
+			{"type": "tool_use", "id": "t2", "name": "Write", "input": {"path": "b"}}
+		],
+		"usage": {"input_tokens": 1, "output_tokens": 2}
+	}`)
+	out = ConvertKiroNonStreamToOpenAI(context.Background(), "m", []byte(`{"tool_choice": {"type": "function", "function": {"name": "Write"}}}`), nil, forced, nil)
+	if calls := gjson.Get(out, "choices.0.message.tool_calls").Array(); len(calls) != 1 || calls[0].Get("id").String() != "t2" {
+		t.Errorf("named tool_choice: expected only the Write call, got %s", out)
+	}
+
+	out = ConvertKiroNonStreamToOpenAI(context.Background(), "m", []byte(`{"tool_choice": "none"}`), nil, response, nil)
+	if gjson.Get(out, "choices.0.message.tool_calls").Exists() {
+		t.Errorf("tool_choice none: expected no tool calls, got %s", out)
+	}
+	if got := gjson.Get(out, "choices.0.finish_reason").String(); got != "stop" {
+		t.Errorf("tool_choice none: finish_reason = %q, want stop", got)
+	}
+}
+
+// TestToolChoiceEnforcedStream verifies the streaming translator applies the same policy.
+func TestToolChoiceEnforcedStream(t *testing.T) {
+	events := []string{
+		`event: message_start` + "\n" + `data: {"type":"message_start"}`,
+		`event: content_block_start` + "\n" + `data: {"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"t1","name":"Read"}}`,
+		`event: content_block_delta` + "\n" + `data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"path\":\"a\"}"}}`,
+		`event: content_block_start` + "\n" + `data: {"type":"content_block_start","index":2,"content_block":{"type":"tool_use","id":"t2","name":"Read"}}`,
+		`event: content_block_delta` + "\n" + `data: {"type":"content_block_delta","index":2,"delta":{"type":"input_json_delta","partial_json":"{\"path\":\"b\"}"}}`,
+		`event: message_delta` + "\n" + `data: {"type":"message_delta","delta":{"stop_reason":"tool_use"}}`,
+	}
+	run := func(originalRequest string) (ids []string, argumentChunks int, finishReason string) {
+		var param any
+		for _, event := range events {
+			for _, chunk := range ConvertKiroStreamToOpenAI(context.Background(), "m", []byte(originalRequest), nil, []byte(event), &param) {
+				choice := gjson.Get(chunk, "choices.0")
+				choice.Get("delta.tool_calls").ForEach(func(_, call gjson.Result) bool {
+					if id := call.Get("id").String(); id != "" {
+						ids = append(ids, id)
+					} else {
+						argumentChunks++
+					}
+					return true
+				})
+				if reason := choice.Get("finish_reason").String(); reason != "" {
+					finishReason = reason
+				}
+			}
+		}
+		return ids, argumentChunks, finishReason
+	}
+
+	if ids, args, reason := run(`{"parallel_tool_calls": false}`); len(ids) != 1 || ids[0] != "t1" || args != 1 || reason != "tool_calls" {
+		t.Errorf("parallel_tool_calls false: ids=%v args=%d finish=%q", ids, args, reason)
+	}
+	if ids, args, reason := run(`{"tool_choice": "none"}`); len(ids) != 0 || args != 0 || reason != "stop" {
+		t.Errorf("tool_choice none: ids=%v args=%d finish=%q", ids, args, reason)
+	}
+	if ids, args, reason := run(`{"tool_choice": {"type": "function", "function": {"name": "Write"}}}`); len(ids) != 0 || args != 0 || reason != "stop" {
+		t.Errorf("named tool_choice: ids=%v args=%d finish=%q", ids, args, reason)
+	}
+	if ids, _, reason := run(`{}`); len(ids) != 2 || reason != "tool_calls" {
+		t.Errorf("no constraints: ids=%v finish=%q", ids, reason)
+	}
+}
+
+// TestStreamToolCallArgumentsFollowTheirCall verifies argument deltas of several tool calls land on
+// the tool call started by the same content block, with and without a leading text block and with
+// dropped calls in between.
+func TestStreamToolCallArgumentsFollowTheirCall(t *testing.T) {
+	toolBlocks := func(first int) []string {
+		var events []string
+		for i, id := range []string{"t1", "t2", "t3"} {
+			index := strconv.Itoa(first + i)
+			events = append(events,
+				`event: content_block_start`+"\n"+`data: {"type":"content_block_start","index":`+index+`,"content_block":{"type":"tool_use","id":"`+id+`","name":"Read"}}`,
+				`event: content_block_delta`+"\n"+`data: {"type":"content_block_delta","index":`+index+`,"delta":{"type":"input_json_delta","partial_json":"{\"path\":\"`+id+`\"}"}}`,
+			)
+		}
+		return events
+	}
+	withText := append([]string{
+		`event: content_block_start` + "\n" + `data: {"type":"content_block_start","index":0,"content_block":{"type":"text"}}`,
+		`event: content_block_delta` + "\n" + `data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"reading"}}`,
+	}, toolBlocks(1)...)
+
+	run := func(originalRequest string, events []string) map[int64]string {
+		var param any
+		starts := map[int64]string{}
+		arguments := map[int64]string{}
+		for _, event := range events {
+			for _, chunk := range ConvertKiroStreamToOpenAI(context.Background(), "m", []byte(originalRequest), nil, []byte(event), &param) {
+				gjson.Get(chunk, "choices.0.delta.tool_calls").ForEach(func(_, call gjson.Result) bool {
+					if id := call.Get("id").String(); id != "" {
+						starts[call.Get("index").Int()] = id
+					} else {
+						arguments[call.Get("index").Int()] += call.Get("function.arguments").String()
+					}
+					return true
+				})
+			}
+		}
+		calls := map[int64]string{}
+		for index, args := range arguments {
+			calls[index] = starts[index] + " " + gjson.Get(args, "path").String()
+		}
+		return calls
+	}
+
+	want := map[int64]string{0: "t1 t1", 1: "t2 t2", 2: "t3 t3"}
+	for name, events := range map[string][]string{"leading text": withText, "tools only": toolBlocks(0)} {
+		if got := run(`{}`, events); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: tool calls = %v, want %v", name, got, want)
+		}
+	}
+	if got := run(`{"parallel_tool_calls": false}`, withText); !reflect.DeepEqual(got, map[int64]string{0: "t1 t1"}) {
+		t.Errorf("parallel_tool_calls false: tool calls = %v, want only t1", got)
+	}
+}
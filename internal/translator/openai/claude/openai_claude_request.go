@@ -140,7 +140,7 @@ func ConvertClaudeRequestToOpenAI(modelName string, inputRawJSON []byte, stream
 					case "redacted_thinking":
 						// Explicitly ignore redacted_thinking - never map to reasoning_content (AC2)
 
-					case "text", "image":
+					case "text", "image", "document":
 						if contentItem, ok := convertClaudeContentPart(part); ok {
 							contentItems = append(contentItems, contentItem)
 						}
@@ -352,11 +352,42 @@ func convertClaudeContentPart(part gjson.Result) (string, bool) {
 
 		return imageContent, true
 
+	case "document":
+		source := part.Get("source")
+		switch source.Get("type").String() {
+		case "base64":
+			data := source.Get("data").String()
+			if data == "" {
+				return "", false
+			}
+			mediaType := source.Get("media_type").String()
+			if mediaType == "" {
+				mediaType = "application/pdf"
+			}
+			fileContent := `{"type":"file","file":{"filename":"","file_data":""}}`
+			fileContent, _ = sjson.Set(fileContent, "file.filename", claudeDocumentFilename(part))
+			fileContent, _ = sjson.Set(fileContent, "file.file_data", "data:"+mediaType+";base64,"+data)
+			return fileContent, true
+		case "text":
+			textContent := `{"type":"text","text":""}`
+			textContent, _ = sjson.Set(textContent, "text", source.Get("data").String())
+			return textContent, true
+		}
+		return "", false
+
 	default:
 		return "", false
 	}
 }
 
+// claudeDocumentFilename returns a filename for a Claude document block, from its title when set.
+func claudeDocumentFilename(part gjson.Result) string {
+	if title := strings.TrimSpace(part.Get("title").String()); title != "" {
+		return title
+	}
+	return "document.pdf"
+}
+
 func convertClaudeToolResultContentToString(content gjson.Result) string {
 	if !content.Exists() {
 		return ""
@@ -7,11 +7,10 @@ package gemini
 
 import (
 	"bytes"
-	"crypto/rand"
 	"fmt"
-	"math/big"
 	"strings"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/idgen"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
@@ -29,14 +28,7 @@ func ConvertGeminiRequestToOpenAI(modelName string, inputRawJSON []byte, stream
 
 	// Helper for generating tool call IDs in the form: call_<alphanum>
 	genToolCallID := func() string {
-		const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-		var b strings.Builder
-		// 24 chars random suffix
-		for i := 0; i < 24; i++ {
-			n, _ := rand.Int(rand.Reader, big.NewInt(int64(len(letters))))
-			b.WriteByte(letters[n.Int64()])
-		}
-		return "call_" + b.String()
+		return "call_" + idgen.Alphanumeric(24)
 	}
 
 	// Model mapping
@@ -93,7 +85,9 @@ func ConvertGeminiRequestToOpenAI(modelName string, inputRawJSON []byte, stream
 	out, _ = sjson.Set(out, "stream", stream)
 
 	// Process contents (Gemini messages) -> OpenAI messages
-	var toolCallIDs []string // Track tool call IDs for matching with tool results
+	// FIFO queue of tool call IDs awaiting results. Gemini pairs functionResponses with
+	// functionCalls in order unless both carry an explicit id.
+	var pendingToolCallIDs []string
 
 	// System instruction -> OpenAI system message
 	// Gemini may provide `systemInstruction` or `system_instruction`; support both keys.
@@ -190,8 +184,11 @@ func ConvertGeminiRequestToOpenAI(modelName string, inputRawJSON []byte, stream
 
 					// Handle function calls (Gemini) -> tool calls (OpenAI)
 					if functionCall := part.Get("functionCall"); functionCall.Exists() {
-						toolCallID := genToolCallID()
-						toolCallIDs = append(toolCallIDs, toolCallID)
+						toolCallID := functionCall.Get("id").String()
+						if toolCallID == "" {
+							toolCallID = genToolCallID()
+						}
+						pendingToolCallIDs = append(pendingToolCallIDs, toolCallID)
 
 						toolCall := `{"id":"","type":"function","function":{"name":"","arguments":""}}`
 						toolCall, _ = sjson.Set(toolCall, "id", toolCallID)
@@ -213,25 +210,39 @@ func ConvertGeminiRequestToOpenAI(modelName string, inputRawJSON []byte, stream
 						// Create tool message for function response
 						toolMsg := `{"role":"tool","tool_call_id":"","content":""}`
 
-						// Convert response.content to JSON string
+						// Convert the response to the tool message content, preferring a plain result
 						if response := functionResponse.Get("response"); response.Exists() {
-							if contentField := response.Get("content"); contentField.Exists() {
-								toolMsg, _ = sjson.Set(toolMsg, "content", contentField.Raw)
-							} else {
+							content := response.Get("result")
+							if !content.Exists() {
+								content = response.Get("content")
+							}
+							switch {
+							case content.Type == gjson.String:
+								toolMsg, _ = sjson.Set(toolMsg, "content", content.String())
+							case content.Exists():
+								toolMsg, _ = sjson.Set(toolMsg, "content", content.Raw)
+							default:
 								toolMsg, _ = sjson.Set(toolMsg, "content", response.Raw)
 							}
 						}
 
-						// Try to match with previous tool call ID
-						_ = functionResponse.Get("name").String() // functionName not used for now
-						if len(toolCallIDs) > 0 {
-							// Use the last tool call ID (simple matching by function name)
-							// In a real implementation, you might want more sophisticated matching
-							toolMsg, _ = sjson.Set(toolMsg, "tool_call_id", toolCallIDs[len(toolCallIDs)-1])
+						// Pair with the call carrying the same id, otherwise with the oldest pending call
+						toolCallID := functionResponse.Get("id").String()
+						if toolCallID != "" {
+							for i, pendingID := range pendingToolCallIDs {
+								if pendingID == toolCallID {
+									pendingToolCallIDs = append(pendingToolCallIDs[:i], pendingToolCallIDs[i+1:]...)
+									break
+								}
+							}
+						} else if len(pendingToolCallIDs) > 0 {
+							toolCallID = pendingToolCallIDs[0]
+							pendingToolCallIDs = pendingToolCallIDs[1:]
 						} else {
 							// Generate a tool call ID if none available
-							toolMsg, _ = sjson.Set(toolMsg, "tool_call_id", genToolCallID())
+							toolCallID = genToolCallID()
 						}
+						toolMsg, _ = sjson.Set(toolMsg, "tool_call_id", toolCallID)
 
 						out, _ = sjson.SetRaw(out, "messages.-1", toolMsg)
 					}
@@ -254,6 +265,11 @@ func ConvertGeminiRequestToOpenAI(modelName string, inputRawJSON []byte, stream
 				msg, _ = sjson.SetRaw(msg, "tool_calls", gjson.Get(toolCallsWrapper, "arr").Raw)
 			}
 
+			// Contents holding only functionResponse parts are fully represented by tool messages
+			if contentPartsCount == 0 && toolCallsCount == 0 && hasFunctionResponse(parts) {
+				return true
+			}
+
 			out, _ = sjson.SetRaw(out, "messages.-1", msg)
 			return true
 		})
@@ -300,3 +316,13 @@ func ConvertGeminiRequestToOpenAI(modelName string, inputRawJSON []byte, stream
 
 	return []byte(out)
 }
+
+// hasFunctionResponse reports whether any of the Gemini parts is a functionResponse.
+func hasFunctionResponse(parts gjson.Result) bool {
+	found := false
+	parts.ForEach(func(_, part gjson.Result) bool {
+		found = part.Get("functionResponse").Exists()
+		return !found
+	})
+	return found
+}
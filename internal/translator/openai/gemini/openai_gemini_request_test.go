@@ -0,0 +1,55 @@
+package gemini
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+// TestConvertGeminiRequestToOpenAI_FunctionResponsePairing verifies that parallel
+// functionResponses are paired with their functionCalls in order, or by explicit id.
+func TestConvertGeminiRequestToOpenAI_FunctionResponsePairing(t *testing.T) {
+	input := []byte(`{
+		"contents": [
+			{"role": "user", "parts": [{"text": "weather in two cities"}]},
+			{"role": "model", "parts": [
+				{"functionCall": {"name": "get_weather", "args": {"city": "Paris"}}},
+				{"functionCall": {"name": "get_weather", "args": {"city": "Rome"}}},
+				{"functionCall": {"id": "call_explicit", "name": "get_time", "args": {}}}
+			]},
+			{"role": "user", "parts": [
+				{"functionResponse": {"id": "call_explicit", "name": "get_time", "response": {"result": "noon"}}},
+				{"functionResponse": {"name": "get_weather", "response": {"result": "sunny"}}},
+				{"functionResponse": {"name": "get_weather", "response": {"result": {"temp": 20}}}}
+			]}
+		],
+		"tools": [{"functionDeclarations": [{"name": "get_weather", "description": "Weather", "parameters": {"type": "object"}}]}]
+	}`)
+
+	out := gjson.ParseBytes(ConvertGeminiRequestToOpenAI("gpt-4o", input, false))
+	messages := out.Get("messages").Array()
+	if len(messages) != 5 {
+		t.Fatalf("expected user, assistant and 3 tool messages, got %d: %s", len(messages), out.Get("messages").Raw)
+	}
+
+	calls := messages[1].Get("tool_calls").Array()
+	if len(calls) != 3 || calls[2].Get("id").String() != "call_explicit" {
+		t.Fatalf("unexpected tool calls: %s", messages[1].Get("tool_calls").Raw)
+	}
+
+	want := []struct{ id, content string }{
+		{"call_explicit", "noon"},
+		{calls[0].Get("id").String(), "sunny"},
+		{calls[1].Get("id").String(), `{"temp": 20}`},
+	}
+	for i, w := range want {
+		msg := messages[2+i]
+		if msg.Get("role").String() != "tool" || msg.Get("tool_call_id").String() != w.id || msg.Get("content").String() != w.content {
+			t.Errorf("tool message %d = %s, want id %s content %s", i, msg.Raw, w.id, w.content)
+		}
+	}
+
+	if name := out.Get("tools.0.function.name").String(); name != "get_weather" {
+		t.Errorf("tools.0.function.name = %q, want get_weather", name)
+	}
+}
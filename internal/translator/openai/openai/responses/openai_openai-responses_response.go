@@ -5,9 +5,8 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"sync/atomic"
-	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/idgen"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -42,9 +41,6 @@ type oaiToResponsesState struct {
 	UsageSeen        bool
 }
 
-// responseIDCounter provides a process-wide unique counter for synthesized response identifiers.
-var responseIDCounter uint64
-
 func emitRespEvent(event string, payload string) string {
 	return fmt.Sprintf("event: %s\ndata: %s", event, payload)
 }
@@ -580,14 +576,14 @@ func ConvertOpenAIChatCompletionsResponseToOpenAIResponsesNonStream(_ context.Co
 	// id: use provider id if present, otherwise synthesize
 	id := root.Get("id").String()
 	if id == "" {
-		id = fmt.Sprintf("resp_%x_%d", time.Now().UnixNano(), atomic.AddUint64(&responseIDCounter, 1))
+		id = fmt.Sprintf("resp_%x_%d", idgen.Now().UnixNano(), idgen.Seq())
 	}
 	resp, _ = sjson.Set(resp, "id", id)
 
 	// created_at: map from chat.completion created
 	created := root.Get("created").Int()
 	if created == 0 {
-		created = time.Now().Unix()
+		created = idgen.Now().Unix()
 	}
 	resp, _ = sjson.Set(resp, "created_at", created)
 
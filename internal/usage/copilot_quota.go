@@ -0,0 +1,166 @@
+package usage
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// CopilotQuotaSnapshot captures GitHub Copilot entitlement data returned by the
+// copilot_internal/v2/token exchange, together with the GitHub rate-limit headers of that response.
+// This is a best-effort in-memory snapshot for observability (it is not persisted).
+type CopilotQuotaSnapshot struct {
+	Plan        string `json:"plan,omitempty"`
+	ChatEnabled *bool  `json:"chat_enabled,omitempty"`
+
+	// Remaining quotas of limited (free) plans; absent for unlimited plans.
+	ChatQuota           *int   `json:"chat_quota,omitempty"`
+	CompletionsQuota    *int   `json:"completions_quota,omitempty"`
+	QuotaResetAtSeconds *int64 `json:"quota_reset_at_seconds,omitempty"`
+
+	TokenExpiresAtSeconds *int64 `json:"token_expires_at_seconds,omitempty"`
+	TokenRefreshInSeconds *int   `json:"token_refresh_in_seconds,omitempty"`
+
+	RateLimitLimit          *int   `json:"rate_limit_limit,omitempty"`
+	RateLimitRemaining      *int   `json:"rate_limit_remaining,omitempty"`
+	RateLimitUsed           *int   `json:"rate_limit_used,omitempty"`
+	RateLimitResetAtSeconds *int64 `json:"rate_limit_reset_at_seconds,omitempty"`
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+var copilotQuotaByAuth sync.Map // authID -> CopilotQuotaSnapshot
+
+// ParseCopilotQuotaSnapshot parses a copilot_internal/v2/token response body and its x-ratelimit-*
+// headers into a snapshot. Returns nil when neither carries relevant data.
+func ParseCopilotQuotaSnapshot(body []byte, headers http.Header) *CopilotQuotaSnapshot {
+	snapshot := &CopilotQuotaSnapshot{}
+	hasData := false
+
+	intOf := func(result gjson.Result) *int {
+		if result.Type != gjson.Number {
+			return nil
+		}
+		v := int(result.Int())
+		return &v
+	}
+	timeOf := func(result gjson.Result) *int64 {
+		switch result.Type {
+		case gjson.Number:
+			v := result.Int()
+			return &v
+		case gjson.String:
+			raw := strings.TrimSpace(result.String())
+			for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+				if parsed, err := time.Parse(layout, raw); err == nil {
+					v := parsed.Unix()
+					return &v
+				}
+			}
+		}
+		return nil
+	}
+
+	if gjson.ValidBytes(body) {
+		root := gjson.ParseBytes(body)
+		if v := strings.TrimSpace(root.Get("sku").String()); v != "" {
+			snapshot.Plan = v
+			hasData = true
+		}
+		if v := root.Get("chat_enabled"); v.IsBool() {
+			b := v.Bool()
+			snapshot.ChatEnabled = &b
+			hasData = true
+		}
+		if v := intOf(root.Get("limited_user_quotas.chat")); v != nil {
+			snapshot.ChatQuota = v
+			hasData = true
+		}
+		if v := intOf(root.Get("limited_user_quotas.completions")); v != nil {
+			snapshot.CompletionsQuota = v
+			hasData = true
+		}
+		if v := timeOf(root.Get("limited_user_reset_date")); v != nil {
+			snapshot.QuotaResetAtSeconds = v
+			hasData = true
+		}
+		if v := timeOf(root.Get("expires_at")); v != nil {
+			snapshot.TokenExpiresAtSeconds = v
+			hasData = true
+		}
+		if v := intOf(root.Get("refresh_in")); v != nil {
+			snapshot.TokenRefreshInSeconds = v
+			hasData = true
+		}
+	}
+
+	if headers != nil {
+		parseInt := func(key string) *int {
+			if v := strings.TrimSpace(headers.Get(key)); v != "" {
+				if i, err := strconv.Atoi(v); err == nil {
+					return &i
+				}
+			}
+			return nil
+		}
+		if v := parseInt("x-ratelimit-limit"); v != nil {
+			snapshot.RateLimitLimit = v
+			hasData = true
+		}
+		if v := parseInt("x-ratelimit-remaining"); v != nil {
+			snapshot.RateLimitRemaining = v
+			hasData = true
+		}
+		if v := parseInt("x-ratelimit-used"); v != nil {
+			snapshot.RateLimitUsed = v
+			hasData = true
+		}
+		if v := strings.TrimSpace(headers.Get("x-ratelimit-reset")); v != "" {
+			if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+				snapshot.RateLimitResetAtSeconds = &i
+				hasData = true
+			}
+		}
+	}
+
+	if !hasData {
+		return nil
+	}
+	snapshot.UpdatedAt = time.Now()
+	return snapshot
+}
+
+// UpdateCopilotQuotaSnapshot stores the latest snapshot for an authID (in-memory).
+func UpdateCopilotQuotaSnapshot(authID string, snapshot *CopilotQuotaSnapshot) {
+	if authID == "" || snapshot == nil {
+		return
+	}
+	copilotQuotaByAuth.Store(authID, *snapshot)
+}
+
+// DeleteCopilotQuotaSnapshot removes the cached snapshot for an authID (in-memory).
+// Primarily intended for tests to avoid shared global state across test cases.
+func DeleteCopilotQuotaSnapshot(authID string) {
+	if authID == "" {
+		return
+	}
+	copilotQuotaByAuth.Delete(authID)
+}
+
+// GetCopilotQuotaSnapshot returns the most recent snapshot for an authID, if any.
+func GetCopilotQuotaSnapshot(authID string) *CopilotQuotaSnapshot {
+	if authID == "" {
+		return nil
+	}
+	if v, ok := copilotQuotaByAuth.Load(authID); ok {
+		if snap, ok2 := v.(CopilotQuotaSnapshot); ok2 {
+			out := snap
+			return &out
+		}
+	}
+	return nil
+}
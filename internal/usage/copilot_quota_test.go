@@ -0,0 +1,59 @@
+package usage
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCopilotQuotaSnapshot_ParseAndStore(t *testing.T) {
+	body := []byte(`{
+  "token": "tid=abc",
+  "expires_at": 1760000000,
+  "refresh_in": 1500,
+  "sku": "free_limited_copilot",
+  "chat_enabled": true,
+  "limited_user_quotas": {"chat": 42, "completions": 1900},
+  "limited_user_reset_date": "2025-11-01"
+}`)
+	headers := make(http.Header)
+	headers.Set("x-ratelimit-limit", "5000")
+	headers.Set("x-ratelimit-remaining", "4990")
+	headers.Set("x-ratelimit-reset", "1760001234")
+
+	snap := ParseCopilotQuotaSnapshot(body, headers)
+	if snap == nil {
+		t.Fatal("expected snapshot to be parsed")
+	}
+	if snap.Plan != "free_limited_copilot" {
+		t.Fatalf("unexpected plan: %q", snap.Plan)
+	}
+	if snap.ChatEnabled == nil || !*snap.ChatEnabled {
+		t.Fatalf("unexpected chat_enabled: %#v", snap.ChatEnabled)
+	}
+	if snap.ChatQuota == nil || *snap.ChatQuota != 42 {
+		t.Fatalf("unexpected chat quota: %#v", snap.ChatQuota)
+	}
+	if snap.QuotaResetAtSeconds == nil || *snap.QuotaResetAtSeconds != 1761955200 {
+		t.Fatalf("unexpected quota reset: %#v", snap.QuotaResetAtSeconds)
+	}
+	if snap.TokenExpiresAtSeconds == nil || *snap.TokenExpiresAtSeconds != 1760000000 {
+		t.Fatalf("unexpected token expiry: %#v", snap.TokenExpiresAtSeconds)
+	}
+	if snap.RateLimitRemaining == nil || *snap.RateLimitRemaining != 4990 {
+		t.Fatalf("unexpected rate limit remaining: %#v", snap.RateLimitRemaining)
+	}
+	if snap.RateLimitResetAtSeconds == nil || *snap.RateLimitResetAtSeconds != 1760001234 {
+		t.Fatalf("unexpected rate limit reset: %#v", snap.RateLimitResetAtSeconds)
+	}
+
+	UpdateCopilotQuotaSnapshot("copilot-auth-1", snap)
+	defer DeleteCopilotQuotaSnapshot("copilot-auth-1")
+	got := GetCopilotQuotaSnapshot("copilot-auth-1")
+	if got == nil || got.CompletionsQuota == nil || *got.CompletionsQuota != 1900 {
+		t.Fatalf("unexpected stored snapshot: %#v", got)
+	}
+
+	if ParseCopilotQuotaSnapshot([]byte(`{"token":"tid=abc"}`), nil) != nil {
+		t.Fatal("expected nil snapshot without entitlement data")
+	}
+}
@@ -0,0 +1,41 @@
+package util
+
+import (
+	"context"
+	"net"
+)
+
+type localConnContextKey struct{}
+
+// WithLocalConnection marks the context as originating from a local-only transport
+// such as a unix domain socket, where file permissions already gate access.
+func WithLocalConnection(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, localConnContextKey{}, true)
+}
+
+// IsLocalConnection reports whether the request context was accepted on a local-only transport.
+func IsLocalConnection(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	local, _ := ctx.Value(localConnContextKey{}).(bool)
+	return local
+}
+
+// LocalConnContext is suitable for http.Server.ConnContext. It tags connections
+// accepted on unix domain sockets or named pipes so handlers can treat them as local clients.
+func LocalConnContext(ctx context.Context, conn net.Conn) context.Context {
+	if conn == nil {
+		return ctx
+	}
+	if addr := conn.LocalAddr(); addr != nil {
+		switch addr.Network() {
+		case "unix", "pipe":
+			return WithLocalConnection(ctx)
+		}
+	}
+	return ctx
+}
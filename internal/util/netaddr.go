@@ -0,0 +1,195 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// Listen address families accepted by the listen-family setting.
+const (
+	ListenFamilyDual = "dual"
+	ListenFamilyIPv4 = "ipv4"
+	ListenFamilyIPv6 = "ipv6"
+)
+
+// ValidListenFamily reports whether family is empty or one of the ListenFamily values.
+func ValidListenFamily(family string) bool {
+	switch strings.ToLower(strings.TrimSpace(family)) {
+	case "", ListenFamilyDual, ListenFamilyIPv4, ListenFamilyIPv6:
+		return true
+	}
+	return false
+}
+
+// ListenNetwork returns the network net.Listen uses for family: "tcp4" for ipv4, "tcp6" for ipv6
+// and "tcp", which accepts both on dual-stack hosts, otherwise.
+func ListenNetwork(family string) string {
+	switch strings.ToLower(strings.TrimSpace(family)) {
+	case ListenFamilyIPv4:
+		return "tcp4"
+	case ListenFamilyIPv6:
+		return "tcp6"
+	default:
+		return "tcp"
+	}
+}
+
+// TrimHostBrackets returns host without the brackets of a bracketed IPv6 literal such as "[::1]".
+func TrimHostBrackets(host string) string {
+	host = strings.TrimSpace(host)
+	if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		return host[1 : len(host)-1]
+	}
+	return host
+}
+
+// JoinHostPort formats host and port as an address, bracketing IPv6 literals. A host that is
+// already bracketed is accepted too.
+func JoinHostPort(host string, port int) string {
+	return net.JoinHostPort(TrimHostBrackets(host), strconv.Itoa(port))
+}
+
+// IsLoopbackAddr reports whether addr, a host or host:port as found in http.Request.RemoteAddr,
+// is a loopback address of either family.
+func IsLoopbackAddr(addr string) bool {
+	host := strings.TrimSpace(addr)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = TrimHostBrackets(host)
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// LoopbackHost returns the loopback address a local client reaches a server bound to host on:
+// host itself when it is a loopback address, "::1" when the server is IPv6 only or bound to an
+// IPv6 address, and "127.0.0.1" otherwise.
+func LoopbackHost(host, family string) string {
+	host = TrimHostBrackets(host)
+	ip := net.ParseIP(host)
+	switch {
+	case ip != nil && ip.IsLoopback():
+		return host
+	case ListenNetwork(family) == "tcp6", ip != nil && ip.To4() == nil:
+		return "::1"
+	default:
+		return "127.0.0.1"
+	}
+}
+
+// ListenLoopback listens on port on both 127.0.0.1 and [::1], so a browser reaching localhost
+// connects whichever address it resolves to. A family the host does not provide is skipped, so
+// the listener also works on IPv4-only and IPv6-only hosts; a port taken on either address is an
+// error. Port 0 picks a free port shared by both addresses.
+func ListenLoopback(port int) (net.Listener, error) {
+	v4, errV4 := net.Listen("tcp4", JoinHostPort("127.0.0.1", port))
+	if errV4 != nil && isAddrInUse(errV4) {
+		return nil, errV4
+	}
+	if v4 != nil && port == 0 {
+		port = v4.Addr().(*net.TCPAddr).Port
+	}
+	v6, errV6 := net.Listen("tcp6", JoinHostPort("::1", port))
+	if errV6 != nil && isAddrInUse(errV6) {
+		if v4 != nil {
+			_ = v4.Close()
+		}
+		return nil, errV6
+	}
+	switch {
+	case v4 != nil && v6 != nil:
+		return newMultiListener(v4, v6), nil
+	case v4 != nil:
+		return v4, nil
+	case v6 != nil:
+		return v6, nil
+	default:
+		return nil, fmt.Errorf("failed to listen on loopback port %d: %w", port, errors.Join(errV4, errV6))
+	}
+}
+
+// isAddrInUse reports whether err means the address is taken rather than unavailable on the host.
+func isAddrInUse(err error) bool {
+	if errors.Is(err, syscall.EADDRINUSE) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "address already in use") || strings.Contains(msg, "only one usage of each socket address")
+}
+
+// multiListener accepts connections from several listeners; its address is the first one's.
+type multiListener struct {
+	listeners []net.Listener
+	conns     chan net.Conn
+	errs      chan error
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newMultiListener(listeners ...net.Listener) *multiListener {
+	m := &multiListener{
+		listeners: listeners,
+		conns:     make(chan net.Conn),
+		errs:      make(chan error, len(listeners)),
+		done:      make(chan struct{}),
+	}
+	for _, ln := range listeners {
+		go m.serve(ln)
+	}
+	return m
+}
+
+func (m *multiListener) serve(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			select {
+			case m.errs <- err:
+			case <-m.done:
+			}
+			return
+		}
+		select {
+		case m.conns <- conn:
+		case <-m.done:
+			_ = conn.Close()
+			return
+		}
+	}
+}
+
+func (m *multiListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-m.conns:
+		return conn, nil
+	case err := <-m.errs:
+		return nil, err
+	case <-m.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (m *multiListener) Close() error {
+	var errs []error
+	m.closeOnce.Do(func() {
+		close(m.done)
+		for _, ln := range m.listeners {
+			if err := ln.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
+
+func (m *multiListener) Addr() net.Addr {
+	return m.listeners[0].Addr()
+}
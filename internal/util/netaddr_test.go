@@ -0,0 +1,96 @@
+package util
+
+import (
+	"net"
+	"strconv"
+	"testing"
+)
+
+func TestJoinHostPort(t *testing.T) {
+	cases := map[string]string{
+		"":            ":8317",
+		"127.0.0.1":   "127.0.0.1:8317",
+		"::1":         "[::1]:8317",
+		"[::1]":       "[::1]:8317",
+		" 2001:db8::": "[2001:db8::]:8317",
+		"example.com": "example.com:8317",
+	}
+	for host, want := range cases {
+		if got := JoinHostPort(host, 8317); got != want {
+			t.Fatalf("JoinHostPort(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
+
+func TestLoopbackAddresses(t *testing.T) {
+	for _, addr := range []string{"127.0.0.1:5000", "[::1]:5000", "::1", "localhost"} {
+		if !IsLoopbackAddr(addr) {
+			t.Fatalf("IsLoopbackAddr(%q) must be true", addr)
+		}
+	}
+	for _, addr := range []string{"10.0.0.1:5000", "[2001:db8::1]:5000", ""} {
+		if IsLoopbackAddr(addr) {
+			t.Fatalf("IsLoopbackAddr(%q) must be false", addr)
+		}
+	}
+
+	cases := []struct{ host, family, want string }{
+		{"", "", "127.0.0.1"},
+		{"0.0.0.0", "", "127.0.0.1"},
+		{"", ListenFamilyIPv6, "::1"},
+		{"::", "", "::1"},
+		{"[::1]", "", "::1"},
+		{"127.0.0.2", "", "127.0.0.2"},
+	}
+	for _, tc := range cases {
+		if got := LoopbackHost(tc.host, tc.family); got != tc.want {
+			t.Fatalf("LoopbackHost(%q, %q) = %q, want %q", tc.host, tc.family, got, tc.want)
+		}
+	}
+	if ListenNetwork("IPv4") != "tcp4" || ListenNetwork(ListenFamilyIPv6) != "tcp6" || ListenNetwork("") != "tcp" {
+		t.Fatal("ListenNetwork must map families to networks")
+	}
+	if ValidListenFamily("ipv5") {
+		t.Fatal("unknown families must be rejected")
+	}
+}
+
+func TestListenLoopbackServesBothFamilies(t *testing.T) {
+	ln, err := ListenLoopback(0)
+	if err != nil {
+		t.Skipf("loopback unavailable: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+	port := ln.Addr().(*net.TCPAddr).Port
+	go func() {
+		for {
+			conn, errAccept := ln.Accept()
+			if errAccept != nil {
+				return
+			}
+			_, _ = conn.Write([]byte("ok"))
+			_ = conn.Close()
+		}
+	}()
+
+	reached := 0
+	for _, host := range []string{"127.0.0.1", "::1"} {
+		conn, errDial := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+		if errDial != nil {
+			continue
+		}
+		buf := make([]byte, 2)
+		if _, errRead := conn.Read(buf); errRead != nil || string(buf) != "ok" {
+			t.Fatalf("connection over %s was not served: %v", host, errRead)
+		}
+		_ = conn.Close()
+		reached++
+	}
+	if reached == 0 {
+		t.Fatal("the listener must be reachable over at least one loopback address")
+	}
+
+	if _, err := ListenLoopback(port); err == nil {
+		t.Fatal("a port already taken must be reported")
+	}
+}
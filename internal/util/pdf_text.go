@@ -0,0 +1,234 @@
+package util
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// pdfStreamPattern matches a stream object's dictionary and the start of its data.
+var pdfStreamPattern = regexp.MustCompile(`(?s)<<((?:[^<>]|<[^<]|>[^>]|<<[^<>]*>>)*)>>\s*stream\r?\n`)
+
+// maxPDFStreamSize bounds the size of a single decompressed content stream.
+const maxPDFStreamSize = 16 << 20
+
+// ErrNoPDFText is returned when a PDF contains no extractable text, e.g. a scanned document.
+var ErrNoPDFText = errors.New("pdf contains no extractable text")
+
+// ExtractPDFText returns the text drawn by the content streams of a PDF. It is a best-effort
+// extractor: only uncompressed and Flate-compressed streams are read, and glyphs of fonts
+// without a direct byte-to-character mapping are skipped.
+func ExtractPDFText(data []byte) (string, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("%PDF")) {
+		return "", errors.New("not a pdf document")
+	}
+	var out strings.Builder
+	for _, loc := range pdfStreamPattern.FindAllSubmatchIndex(data, -1) {
+		dict := string(data[loc[2]:loc[3]])
+		end := bytes.Index(data[loc[1]:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		stream := data[loc[1] : loc[1]+end]
+		switch {
+		case strings.Contains(dict, "/FlateDecode"):
+			reader, err := zlib.NewReader(bytes.NewReader(stream))
+			if err != nil {
+				continue
+			}
+			// Truncated streams are common; keep whatever was decompressed.
+			stream, _ = io.ReadAll(io.LimitReader(reader, maxPDFStreamSize))
+			_ = reader.Close()
+		case strings.Contains(dict, "/Filter"):
+			continue
+		}
+		if !bytes.Contains(stream, []byte("BT")) {
+			continue
+		}
+		extractPDFContentText(stream, &out)
+	}
+	text := strings.TrimSpace(out.String())
+	if text == "" {
+		return "", ErrNoPDFText
+	}
+	return text, nil
+}
+
+// extractPDFContentText appends the strings shown by text operators in a content stream to out.
+func extractPDFContentText(content []byte, out *strings.Builder) {
+	var operands []string
+	inArray := false
+	newline := func() {
+		if out.Len() > 0 && !strings.HasSuffix(out.String(), "\n") {
+			out.WriteByte('\n')
+		}
+	}
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '(':
+			s, next := readPDFLiteralString(content, i)
+			operands = append(operands, s)
+			i = next
+		case c == '<' && i+1 < len(content) && content[i+1] != '<':
+			s, next := readPDFHexString(content, i)
+			operands = append(operands, s)
+			i = next
+		case c == '[':
+			inArray = true
+			i++
+		case c == ']':
+			inArray = false
+			i++
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case isPDFDelimiterOrSpace(c):
+			i++
+		default:
+			start := i
+			for i < len(content) && !isPDFDelimiterOrSpace(content[i]) {
+				i++
+			}
+			token := string(content[start:i])
+			if inArray {
+				// Large negative kerning inside TJ arrays usually separates words.
+				if strings.HasPrefix(token, "-") && len(token) > 3 {
+					operands = append(operands, " ")
+				}
+				continue
+			}
+			switch token {
+			case "Tj", "TJ":
+				out.WriteString(strings.Join(operands, ""))
+			case "'", "\"":
+				newline()
+				out.WriteString(strings.Join(operands, ""))
+			case "Td", "TD", "T*", "ET":
+				newline()
+			}
+			if !isPDFNumber(token) {
+				operands = operands[:0]
+			}
+		}
+	}
+	newline()
+}
+
+func isPDFDelimiterOrSpace(c byte) bool {
+	return strings.IndexByte(" \t\r\n\f\x00()<>[]{}/%", c) >= 0
+}
+
+func isPDFNumber(token string) bool {
+	return strings.Trim(token, "0123456789.+-") == ""
+}
+
+// readPDFLiteralString decodes the literal string starting at content[start] == '('.
+func readPDFLiteralString(content []byte, start int) (string, int) {
+	var buf []byte
+	depth := 0
+	i := start + 1
+	for ; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '\\' && i+1 < len(content):
+			i++
+			switch e := content[i]; e {
+			case 'n':
+				buf = append(buf, '\n')
+			case 'r':
+				buf = append(buf, '\r')
+			case 't':
+				buf = append(buf, '\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// Line continuation.
+			default:
+				if e >= '0' && e <= '7' {
+					value := 0
+					for n := 0; n < 3 && i < len(content) && content[i] >= '0' && content[i] <= '7'; n++ {
+						value = value*8 + int(content[i]-'0')
+						i++
+					}
+					i--
+					buf = append(buf, byte(value))
+				} else {
+					buf = append(buf, e)
+				}
+			}
+		case c == '(':
+			depth++
+			buf = append(buf, c)
+		case c == ')':
+			if depth == 0 {
+				return decodePDFString(buf), i + 1
+			}
+			depth--
+			buf = append(buf, c)
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return decodePDFString(buf), i
+}
+
+// readPDFHexString decodes the hex string starting at content[start] == '<'.
+func readPDFHexString(content []byte, start int) (string, int) {
+	var buf []byte
+	var digits []byte
+	i := start + 1
+	for ; i < len(content) && content[i] != '>'; i++ {
+		if c := content[i]; strings.IndexByte("0123456789abcdefABCDEF", c) >= 0 {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	for j := 0; j < len(digits); j += 2 {
+		buf = append(buf, hexNibble(digits[j])<<4|hexNibble(digits[j+1]))
+	}
+	return decodePDFString(buf), i + 1
+}
+
+func hexNibble(c byte) byte {
+	switch {
+	case c >= 'a':
+		return c - 'a' + 10
+	case c >= 'A':
+		return c - 'A' + 10
+	default:
+		return c - '0'
+	}
+}
+
+// decodePDFString converts a PDF string to UTF-8, handling UTF-16BE strings with a byte order
+// mark and dropping control bytes from glyph-indexed strings.
+func decodePDFString(raw []byte) string {
+	if len(raw) >= 2 && raw[0] == 0xfe && raw[1] == 0xff {
+		var b strings.Builder
+		for j := 2; j+1 < len(raw); j += 2 {
+			b.WriteRune(rune(raw[j])<<8 | rune(raw[j+1]))
+		}
+		return b.String()
+	}
+	var b strings.Builder
+	for _, c := range raw {
+		switch {
+		case c == '\n' || c == '\t' || (c >= 0x20 && c < 0x7f):
+			b.WriteByte(c)
+		case c >= 0xa0:
+			// Treat high bytes as Latin-1, close enough to PDFDocEncoding for text.
+			b.WriteRune(rune(c))
+		}
+	}
+	if !utf8.ValidString(b.String()) {
+		return ""
+	}
+	return b.String()
+}
@@ -0,0 +1,52 @@
+package util
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func buildTestPDF(t *testing.T, content string, compress bool) []byte {
+	t.Helper()
+	stream := []byte(content)
+	dict := fmt.Sprintf("<< /Length %d >>", len(stream))
+	if compress {
+		var buf bytes.Buffer
+		w := zlib.NewWriter(&buf)
+		_, _ = w.Write(stream)
+		_ = w.Close()
+		stream = buf.Bytes()
+		dict = fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>", len(stream))
+	}
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n4 0 obj\n")
+	pdf.WriteString(dict + "\nstream\n")
+	pdf.Write(stream)
+	pdf.WriteString("\nendstream\nendobj\n%%EOF\n")
+	return pdf.Bytes()
+}
+
+func TestExtractPDFText(t *testing.T) {
+	content := "BT /F1 12 Tf 72 712 Td (Hello \\(PDF\\) world) Tj 0 -14 Td [(Second) -250 (line)] TJ ET"
+	for _, compress := range []bool{false, true} {
+		text, err := ExtractPDFText(buildTestPDF(t, content, compress))
+		if err != nil {
+			t.Fatalf("compress=%v: unexpected error: %v", compress, err)
+		}
+		if text != "Hello (PDF) world\nSecond line" {
+			t.Fatalf("compress=%v: got %q", compress, text)
+		}
+	}
+}
+
+func TestExtractPDFTextErrors(t *testing.T) {
+	if _, err := ExtractPDFText([]byte("not a pdf")); err == nil {
+		t.Fatalf("expected error for non-pdf input")
+	}
+	_, err := ExtractPDFText(buildTestPDF(t, "q 100 0 0 100 0 0 cm /Im1 Do Q", true))
+	if !errors.Is(err, ErrNoPDFText) {
+		t.Fatalf("expected ErrNoPDFText for image-only pdf, got %v", err)
+	}
+}
@@ -5,6 +5,7 @@ package util
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -74,7 +75,11 @@ func getPublicIP() (string, error) {
 func getOutboundIP() (string, error) {
 	conn, err := net.Dial("udp", "8.8.8.8:80")
 	if err != nil {
-		return "", err
+		// IPv6-only hosts have no IPv4 route; ask for the IPv6 one instead.
+		var errV6 error
+		if conn, errV6 = net.Dial("udp", "[2001:4860:4860::8888]:80"); errV6 != nil {
+			return "", errors.Join(err, errV6)
+		}
 	}
 	defer func() {
 		if closeErr := conn.Close(); closeErr != nil {
@@ -90,6 +95,13 @@ func getOutboundIP() (string, error) {
 	return localAddr.IP.String(), nil
 }
 
+// OutboundIPAddress returns the address of the local interface used for outbound traffic. Unlike
+// GetIPAddress it never contacts an external service, so the result is reachable from the local
+// network but may differ from the public address behind NAT.
+func OutboundIPAddress() (string, error) {
+	return getOutboundIP()
+}
+
 // GetIPAddress attempts to find the best-available IP address.
 // It first tries to get the public IP address, and if that fails,
 // it falls back to getting the local outbound IP address.
@@ -118,18 +130,37 @@ func GetIPAddress() string {
 // Parameters:
 //   - port: The local port number for the SSH tunnel
 func PrintSSHTunnelInstructions(port int) {
-	ipAddress := GetIPAddress()
+	lines := SSHTunnelInstructions(port)
 	border := "================================================================================"
-	fmt.Println("To authenticate from a remote machine, an SSH tunnel may be required.")
+	fmt.Println(lines[0])
 	fmt.Println(border)
-	fmt.Println("  Run one of the following commands on your local machine (NOT the server):")
-	fmt.Println()
-	fmt.Printf("  # Standard SSH command (assumes SSH port 22):\n")
-	fmt.Printf("  ssh -L %d:127.0.0.1:%d root@%s -p 22\n", port, port, ipAddress)
-	fmt.Println()
-	fmt.Printf("  # If using an SSH key (assumes SSH port 22):\n")
-	fmt.Printf("  ssh -i <path_to_your_key> -L %d:127.0.0.1:%d root@%s -p 22\n", port, port, ipAddress)
-	fmt.Println()
-	fmt.Println("  NOTE: If your server's SSH port is not 22, please modify the '-p 22' part accordingly.")
+	for _, line := range lines[1:] {
+		if line == "" {
+			fmt.Println()
+			continue
+		}
+		fmt.Println("  " + line)
+	}
 	fmt.Println(border)
 }
+
+// SSHTunnelInstructions detects the IP address and returns the SSH tunnel instructions printed by
+// PrintSSHTunnelInstructions, one line per entry, for callers that render them themselves.
+//
+// Parameters:
+//   - port: The local port number for the SSH tunnel
+func SSHTunnelInstructions(port int) []string {
+	ipAddress := GetIPAddress()
+	return []string{
+		"To authenticate from a remote machine, an SSH tunnel may be required.",
+		"Run one of the following commands on your local machine (NOT the server):",
+		"",
+		"# Standard SSH command (assumes SSH port 22):",
+		fmt.Sprintf("ssh -L %d:localhost:%d root@%s -p 22", port, port, ipAddress),
+		"",
+		"# If using an SSH key (assumes SSH port 22):",
+		fmt.Sprintf("ssh -i <path_to_your_key> -L %d:localhost:%d root@%s -p 22", port, port, ipAddress),
+		"",
+		"NOTE: If your server's SSH port is not 22, please modify the '-p 22' part accordingly.",
+	}
+}
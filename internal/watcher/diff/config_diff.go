@@ -215,6 +215,9 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 	if entries, _ := DiffOAuthModelMappingChanges(oldCfg.OAuthModelMappings, newCfg.OAuthModelMappings); len(entries) > 0 {
 		changes = append(changes, entries...)
 	}
+	if !reflect.DeepEqual(oldCfg.AuthDefaults, newCfg.AuthDefaults) {
+		changes = append(changes, fmt.Sprintf("auth-defaults: updated (%d -> %d rules)", len(oldCfg.AuthDefaults), len(newCfg.AuthDefaults)))
+	}
 
 	// Remote management (never print the key)
 	if oldCfg.RemoteManagement.AllowRemote != newCfg.RemoteManagement.AllowRemote {
@@ -49,24 +49,39 @@ func (w *Watcher) start(ctx context.Context) error {
 }
 
 func (w *Watcher) watchKiroIDETokenFile() {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		log.Debugf("failed to get home directory for Kiro IDE token watch: %v", err)
-		return
+	w.clientsMutex.RLock()
+	var cfgTokenFiles []string
+	if w.config != nil {
+		for _, entry := range w.config.KiroKey {
+			cfgTokenFiles = append(cfgTokenFiles, entry.TokenFile)
+		}
 	}
+	w.clientsMutex.RUnlock()
 
-	kiroTokenDir := filepath.Join(homeDir, ".aws", "sso", "cache")
-
-	if _, statErr := os.Stat(kiroTokenDir); os.IsNotExist(statErr) {
-		log.Debugf("Kiro IDE token directory does not exist: %s", kiroTokenDir)
-		return
+	paths := kiroIDETokenPaths(cfgTokenFiles)
+	tokenFiles := make(map[string]struct{}, len(paths))
+	watchedDirs := make(map[string]struct{})
+	for _, path := range paths {
+		tokenFiles[path] = struct{}{}
+		kiroTokenDir := filepath.Dir(path)
+		if _, ok := watchedDirs[kiroTokenDir]; ok {
+			continue
+		}
+		if _, statErr := os.Stat(kiroTokenDir); os.IsNotExist(statErr) {
+			log.Debugf("Kiro IDE token directory does not exist: %s", kiroTokenDir)
+			continue
+		}
+		if errAdd := w.watcher.Add(kiroTokenDir); errAdd != nil {
+			log.Debugf("failed to watch Kiro IDE token directory %s: %v", kiroTokenDir, errAdd)
+			continue
+		}
+		watchedDirs[kiroTokenDir] = struct{}{}
+		log.Debugf("watching Kiro IDE token directory: %s", kiroTokenDir)
 	}
 
-	if errAdd := w.watcher.Add(kiroTokenDir); errAdd != nil {
-		log.Debugf("failed to watch Kiro IDE token directory %s: %v", kiroTokenDir, errAdd)
-		return
-	}
-	log.Debugf("watching Kiro IDE token directory: %s", kiroTokenDir)
+	w.clientsMutex.Lock()
+	w.kiroIDETokenFiles = tokenFiles
+	w.clientsMutex.Unlock()
 }
 
 func (w *Watcher) processEvents(ctx context.Context) {
@@ -155,6 +170,12 @@ func (w *Watcher) handleEvent(event fsnotify.Event) {
 }
 
 func (w *Watcher) isKiroIDETokenFile(path string) bool {
+	w.clientsMutex.RLock()
+	_, known := w.kiroIDETokenFiles[filepath.Clean(path)]
+	w.clientsMutex.RUnlock()
+	if known {
+		return true
+	}
 	normalized := filepath.ToSlash(path)
 	return strings.HasSuffix(normalized, "kiro-auth-token.json") && strings.Contains(normalized, ".aws/sso/cache")
 }
@@ -170,7 +191,7 @@ func (w *Watcher) handleKiroIDETokenChange(event fsnotify.Event) {
 		}
 	}
 
-	tokenData, err := kiroauth.LoadKiroIDEToken()
+	tokenData, err := kiroauth.LoadKiroTokenFromPath(event.Name)
 	if err != nil {
 		log.Debugf("failed to load Kiro IDE token after change: %v", err)
 		return
@@ -178,6 +199,10 @@ func (w *Watcher) handleKiroIDETokenChange(event fsnotify.Event) {
 
 	log.Infof("Kiro IDE token file updated, access token refreshed (provider: %s)", tokenData.Provider)
 
+	if synced := syncKiroIDEToken(w.authDir, event.Name, tokenData); synced > 0 {
+		log.Debugf("updated %d imported Kiro auth file(s) from %s", synced, event.Name)
+	}
+
 	w.refreshAuthState(true)
 
 	w.clientsMutex.RLock()
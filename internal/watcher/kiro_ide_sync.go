@@ -0,0 +1,140 @@
+// kiro_ide_sync.go keeps Kiro auth files imported from the Kiro IDE in sync with the IDE's
+// token file, which the IDE rewrites whenever it refreshes its session.
+package watcher
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	kiroauth "github.com/router-for-me/CLIProxyAPI/v6/internal/auth/kiro"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
+	log "github.com/sirupsen/logrus"
+)
+
+// kiroIDETokenPaths returns the IDE token files to watch: the platform defaults and WSL
+// candidates plus the token files named in the kiro config entries.
+func kiroIDETokenPaths(cfgTokenFiles []string) []string {
+	paths := kiroauth.KiroIDETokenPaths()
+	seen := make(map[string]struct{}, len(paths))
+	for _, path := range paths {
+		seen[path] = struct{}{}
+	}
+	for _, tokenFile := range cfgTokenFiles {
+		expanded, err := kiroauth.ExpandKiroTokenPath(strings.TrimSpace(tokenFile))
+		if err != nil || expanded == "" {
+			continue
+		}
+		expanded = filepath.Clean(expanded)
+		if _, ok := seen[expanded]; ok {
+			continue
+		}
+		seen[expanded] = struct{}{}
+		paths = append(paths, expanded)
+	}
+	return paths
+}
+
+// syncKiroIDEToken copies a refreshed IDE token into every auth file imported from tokenPath.
+// The rewritten files are picked up by the auth directory watch like any other change.
+// It returns the number of auth files updated.
+func syncKiroIDEToken(authDir, tokenPath string, token *kiroauth.KiroTokenData) int {
+	if token == nil || token.AccessToken == "" {
+		return 0
+	}
+	entries, err := os.ReadDir(authDir)
+	if err != nil {
+		log.Debugf("failed to read auth directory for Kiro IDE sync: %v", err)
+		return 0
+	}
+	tokenPath = filepath.Clean(tokenPath)
+	updated := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(strings.ToLower(entry.Name()), ".json") {
+			continue
+		}
+		path := filepath.Join(authDir, entry.Name())
+		data, errRead := os.ReadFile(path)
+		if errRead != nil || len(data) == 0 {
+			continue
+		}
+		var metadata map[string]any
+		if errUnmarshal := json.Unmarshal(data, &metadata); errUnmarshal != nil {
+			continue
+		}
+		if typ, _ := metadata["type"].(string); typ != "kiro" {
+			continue
+		}
+		source, _ := metadata["ide_token_file"].(string)
+		if source == "" || filepath.Clean(source) != tokenPath {
+			continue
+		}
+		if !applyKiroIDEToken(metadata, token) {
+			continue
+		}
+		raw, errMarshal := json.MarshalIndent(metadata, "", "  ")
+		if errMarshal != nil {
+			log.Errorf("failed to encode Kiro auth file %s: %v", entry.Name(), errMarshal)
+			continue
+		}
+		if errWrite := misc.WriteCredentialFile(path, raw); errWrite != nil {
+			log.Errorf("failed to write Kiro auth file %s: %v", entry.Name(), errWrite)
+			continue
+		}
+		log.Infof("synced Kiro auth file %s with the Kiro IDE token", entry.Name())
+		updated++
+	}
+	return updated
+}
+
+// applyKiroIDEToken copies the token fields into metadata and reports whether anything changed.
+// A token of another account, as when the IDE signed in as someone else, is ignored, and so is a
+// token that expires before the one already stored, so a stale IDE file never replaces
+// credentials the proxy refreshed on its own.
+func applyKiroIDEToken(metadata map[string]any, token *kiroauth.KiroTokenData) bool {
+	current, _ := metadata["access_token"].(string)
+	if current == token.AccessToken {
+		return false
+	}
+	if !sameKiroIdentity(metadata, token) {
+		return false
+	}
+	if stored, ok := metadata["expires_at"].(string); ok {
+		storedAt, errStored := time.Parse(time.RFC3339, stored)
+		tokenAt, errToken := time.Parse(time.RFC3339, token.ExpiresAt)
+		if errStored == nil && errToken == nil && tokenAt.Before(storedAt) {
+			return false
+		}
+	}
+	metadata["access_token"] = token.AccessToken
+	if token.RefreshToken != "" {
+		metadata["refresh_token"] = token.RefreshToken
+	}
+	if token.ExpiresAt != "" {
+		metadata["expires_at"] = token.ExpiresAt
+	}
+	if token.ProfileArn != "" {
+		metadata["profile_arn"] = token.ProfileArn
+	}
+	metadata["last_refresh"] = time.Now().Format(time.RFC3339)
+	return true
+}
+
+// sameKiroIdentity reports whether token belongs to the account of metadata. Email and profile ARN
+// are compared when both sides carry them; the token's email is read from its JWT when the IDE
+// file does not name it.
+func sameKiroIdentity(metadata map[string]any, token *kiroauth.KiroTokenData) bool {
+	tokenEmail := strings.TrimSpace(token.Email)
+	if tokenEmail == "" {
+		tokenEmail = kiroauth.ExtractEmailFromJWT(token.AccessToken)
+	}
+	if stored, _ := metadata["email"].(string); stored != "" && tokenEmail != "" && !strings.EqualFold(strings.TrimSpace(stored), tokenEmail) {
+		return false
+	}
+	if stored, _ := metadata["profile_arn"].(string); stored != "" && token.ProfileArn != "" && stored != token.ProfileArn {
+		return false
+	}
+	return true
+}
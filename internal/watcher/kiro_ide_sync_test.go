@@ -0,0 +1,95 @@
+package watcher
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	kiroauth "github.com/router-for-me/CLIProxyAPI/v6/internal/auth/kiro"
+)
+
+func TestSyncKiroIDETokenUpdatesImportedAuths(t *testing.T) {
+	authDir := t.TempDir()
+	tokenPath := filepath.Join(t.TempDir(), "kiro-auth-token.json")
+
+	write := func(name string, metadata map[string]any) string {
+		raw, err := json.Marshal(metadata)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(authDir, name)
+		if err = os.WriteFile(path, raw, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	read := func(path string) map[string]any {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var metadata map[string]any
+		if err = json.Unmarshal(raw, &metadata); err != nil {
+			t.Fatal(err)
+		}
+		return metadata
+	}
+
+	imported := write("kiro-import.json", map[string]any{
+		"type":           "kiro",
+		"access_token":   "old-access",
+		"refresh_token":  "old-refresh",
+		"expires_at":     "2025-01-01T00:00:00Z",
+		"ide_token_file": tokenPath,
+	})
+	other := write("kiro-other.json", map[string]any{
+		"type":           "kiro",
+		"access_token":   "other-access",
+		"ide_token_file": filepath.Join(authDir, "elsewhere.json"),
+	})
+	newer := write("kiro-newer.json", map[string]any{
+		"type":           "kiro",
+		"access_token":   "proxy-refreshed",
+		"expires_at":     "2025-06-01T00:00:00Z",
+		"ide_token_file": tokenPath,
+	})
+
+	otherAccount := write("kiro-other-account.json", map[string]any{
+		"type":           "kiro",
+		"access_token":   "someone-else",
+		"email":          "someone@example.com",
+		"expires_at":     "2025-01-01T00:00:00Z",
+		"ide_token_file": tokenPath,
+	})
+
+	token := &kiroauth.KiroTokenData{
+		Email:        "me@example.com",
+		AccessToken:  "new-access",
+		RefreshToken: "new-refresh",
+		ExpiresAt:    "2025-02-01T00:00:00Z",
+		ProfileArn:   "arn:aws:codewhisperer:us-east-1:123:profile/x",
+	}
+	if got := syncKiroIDEToken(authDir, tokenPath, token); got != 1 {
+		t.Fatalf("synced %d files, want 1", got)
+	}
+
+	metadata := read(imported)
+	if metadata["access_token"] != "new-access" || metadata["refresh_token"] != "new-refresh" ||
+		metadata["expires_at"] != "2025-02-01T00:00:00Z" || metadata["profile_arn"] != token.ProfileArn {
+		t.Fatalf("imported auth not synced: %+v", metadata)
+	}
+	if read(other)["access_token"] != "other-access" {
+		t.Fatal("auth imported from another token file must not change")
+	}
+	if read(otherAccount)["access_token"] != "someone-else" {
+		t.Fatal("a token of another account must not replace the stored one")
+	}
+	if read(newer)["access_token"] != "proxy-refreshed" {
+		t.Fatal("an older IDE token must not replace a newer stored one")
+	}
+
+	if got := syncKiroIDEToken(authDir, tokenPath, token); got != 0 {
+		t.Fatalf("unchanged token must not rewrite files, synced %d", got)
+	}
+}
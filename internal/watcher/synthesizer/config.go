@@ -120,6 +120,9 @@ func (s *ConfigSynthesizer) synthesizeClaudeKeys(ctx *SynthesisContext) []*corea
 		if hash := diff.ComputeClaudeModelsHash(ck.Models); hash != "" {
 			attrs["models_hash"] = hash
 		}
+		if ck.StripThinkingSignatures {
+			attrs["strip_thinking_signatures"] = "true"
+		}
 		addConfigHeadersToAttrs(ck.Headers, attrs)
 		proxyURL := strings.TrimSpace(ck.ProxyURL)
 		a := &coreauth.Auth{
@@ -222,6 +225,9 @@ func (s *ConfigSynthesizer) synthesizeOpenAICompat(ctx *SynthesisContext) []*cor
 			if key != "" {
 				attrs["api_key"] = key
 			}
+			if entry.DocumentTextExtraction {
+				attrs["document_text_extraction"] = "true"
+			}
 			if hash := diff.ComputeOpenAICompatModelsHash(compat.Models); hash != "" {
 				attrs["models_hash"] = hash
 			}
@@ -391,6 +397,9 @@ func (s *ConfigSynthesizer) synthesizeKiroKeys(ctx *SynthesisContext) []*coreaut
 		if refreshToken != "" {
 			attrs["refresh_token"] = refreshToken
 		}
+		if kk.DocumentTextExtraction {
+			attrs["document_text_extraction"] = "true"
+		}
 		proxyURL := strings.TrimSpace(kk.ProxyURL)
 		a := &coreauth.Auth{
 			ID:         id,
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/runtime/geminicli"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 )
@@ -34,9 +35,7 @@ func (s *FileSynthesizer) Synthesize(ctx *SynthesisContext) ([]*coreauth.Auth, e
 		return out, nil
 	}
 
-	now := ctx.Now
-	cfg := ctx.Config
-
+	files := make([]string, 0, len(entries))
 	for _, e := range entries {
 		if e.IsDir() {
 			continue
@@ -45,76 +44,98 @@ func (s *FileSynthesizer) Synthesize(ctx *SynthesisContext) ([]*coreauth.Auth, e
 		if !strings.HasSuffix(strings.ToLower(name), ".json") {
 			continue
 		}
-		full := filepath.Join(ctx.AuthDir, name)
-		data, errRead := os.ReadFile(full)
-		if errRead != nil || len(data) == 0 {
-			continue
-		}
-		var metadata map[string]any
-		if errUnmarshal := json.Unmarshal(data, &metadata); errUnmarshal != nil {
-			continue
-		}
-		t, _ := metadata["type"].(string)
-		if t == "" {
-			continue
-		}
-		provider := strings.ToLower(t)
-		if provider == "gemini" {
-			provider = "gemini-cli"
-		}
-		label := provider
-		if email, _ := metadata["email"].(string); email != "" {
-			label = email
-		}
-		// Use relative path under authDir as ID to stay consistent with the file-based token store
-		id := full
-		if rel, errRel := filepath.Rel(ctx.AuthDir, full); errRel == nil && rel != "" {
-			id = rel
-		}
+		files = append(files, filepath.Join(ctx.AuthDir, name))
+	}
 
-		proxyURL := ""
-		if p, ok := metadata["proxy_url"].(string); ok {
-			proxyURL = p
-		}
+	// Read and parse files concurrently; results keep directory order.
+	perFile := make([][]*coreauth.Auth, len(files))
+	misc.ParallelFor(len(files), misc.AuthLoadConcurrency, func(i int) {
+		perFile[i] = s.synthesizeFile(ctx, files[i])
+	})
+	for _, auths := range perFile {
+		out = append(out, auths...)
+	}
+	return out, nil
+}
 
-		prefix := ""
-		if rawPrefix, ok := metadata["prefix"].(string); ok {
-			trimmed := strings.TrimSpace(rawPrefix)
-			trimmed = strings.Trim(trimmed, "/")
-			if trimmed != "" && !strings.Contains(trimmed, "/") {
-				prefix = trimmed
-			}
-		}
+// synthesizeFile builds the auth entries for a single auth file, including Gemini virtual
+// auths. Unreadable or untyped files yield nothing.
+func (s *FileSynthesizer) synthesizeFile(ctx *SynthesisContext, full string) []*coreauth.Auth {
+	now := ctx.Now
+	cfg := ctx.Config
+
+	data, errRead := os.ReadFile(full)
+	if errRead != nil || len(data) == 0 {
+		return nil
+	}
+	var metadata map[string]any
+	if errUnmarshal := json.Unmarshal(data, &metadata); errUnmarshal != nil {
+		return nil
+	}
+	t, _ := metadata["type"].(string)
+	if t == "" {
+		return nil
+	}
+	provider := strings.ToLower(t)
+	if provider == "gemini" {
+		provider = "gemini-cli"
+	}
+	label := provider
+	if email, _ := metadata["email"].(string); email != "" {
+		label = email
+	}
+	// Use relative path under authDir as ID to stay consistent with the file-based token store
+	id := full
+	if rel, errRel := filepath.Rel(ctx.AuthDir, full); errRel == nil && rel != "" {
+		id = rel
+	}
+
+	proxyURL := ""
+	if p, ok := metadata["proxy_url"].(string); ok {
+		proxyURL = p
+	}
 
-		a := &coreauth.Auth{
-			ID:       id,
-			Provider: provider,
-			Label:    label,
-			Prefix:   prefix,
-			Status:   coreauth.StatusActive,
-			Attributes: map[string]string{
-				"source": full,
-				"path":   full,
-			},
-			ProxyURL:  proxyURL,
-			Metadata:  metadata,
-			CreatedAt: now,
-			UpdatedAt: now,
+	prefix := ""
+	if rawPrefix, ok := metadata["prefix"].(string); ok {
+		trimmed := strings.TrimSpace(rawPrefix)
+		trimmed = strings.Trim(trimmed, "/")
+		if trimmed != "" && !strings.Contains(trimmed, "/") {
+			prefix = trimmed
 		}
-		ApplyAuthExcludedModelsMeta(a, cfg, nil, "oauth")
-		if provider == "gemini-cli" {
-			if virtuals := SynthesizeGeminiVirtualAuths(a, metadata, now); len(virtuals) > 0 {
-				for _, v := range virtuals {
-					ApplyAuthExcludedModelsMeta(v, cfg, nil, "oauth")
+	}
+
+	a := &coreauth.Auth{
+		ID:       id,
+		Provider: provider,
+		Label:    label,
+		Prefix:   prefix,
+		Status:   coreauth.StatusActive,
+		Attributes: map[string]string{
+			"source": full,
+			"path":   full,
+		},
+		ProxyURL:  proxyURL,
+		Metadata:  metadata,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	ApplyAuthExcludedModelsMeta(a, cfg, nil, "oauth")
+	ApplyAuthDefaults(a, cfg, filepath.Base(full))
+	if provider == "gemini-cli" {
+		if virtuals := SynthesizeGeminiVirtualAuths(a, metadata, now); len(virtuals) > 0 {
+			for _, v := range virtuals {
+				ApplyAuthExcludedModelsMeta(v, cfg, nil, "oauth")
+				// Virtual auths share the selection settings of their file.
+				for _, key := range []string{"priority", "weight", "tags"} {
+					if value := a.Attributes[key]; value != "" {
+						v.Attributes[key] = value
+					}
 				}
-				out = append(out, a)
-				out = append(out, virtuals...)
-				continue
 			}
+			return append([]*coreauth.Auth{a}, virtuals...)
 		}
-		out = append(out, a)
 	}
-	return out, nil
+	return []*coreauth.Auth{a}
 }
 
 // SynthesizeGeminiVirtualAuths creates virtual Auth entries for multi-project Gemini credentials.
@@ -610,3 +610,51 @@ func TestBuildGeminiVirtualID(t *testing.T) {
 		})
 	}
 }
+
+func TestFileSynthesizer_Synthesize_AuthDefaults(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"kiro-google-a.json": `{"type":"kiro"}`,
+		"kiro-google-b.json": `{"type":"kiro","priority":3,"tags":["manual"]}`,
+		"kiro-aws-c.json":    `{"type":"kiro"}`,
+		"codex-d.json":       `{"type":"codex"}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write auth file: %v", err)
+		}
+	}
+
+	synth := NewFileSynthesizer()
+	ctx := &SynthesisContext{
+		Config: &config.Config{AuthDefaults: []config.AuthDefaultRule{
+			{Match: "kiro-google-*.json", Priority: 10, Weight: 2, Tags: []string{"tier-1", " google "}},
+			{Provider: "kiro", Priority: 5},
+		}},
+		AuthDir:     tempDir,
+		Now:         time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		IDGenerator: NewStableIDGenerator(),
+	}
+
+	auths, err := synth.Synthesize(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := make(map[string]map[string]string, len(auths))
+	for _, a := range auths {
+		got[a.ID] = a.Attributes
+	}
+
+	if attrs := got["kiro-google-a.json"]; attrs["priority"] != "10" || attrs["weight"] != "2" || attrs["tags"] != "tier-1,google" {
+		t.Errorf("first matching rule not applied: %v", attrs)
+	}
+	if attrs := got["kiro-google-b.json"]; attrs["priority"] != "3" || attrs["weight"] != "2" || attrs["tags"] != "manual" {
+		t.Errorf("values stored in the file must win over the rule: %v", attrs)
+	}
+	if attrs := got["kiro-aws-c.json"]; attrs["priority"] != "5" || attrs["weight"] != "" {
+		t.Errorf("provider rule not applied: %v", attrs)
+	}
+	if attrs := got["codex-d.json"]; attrs["priority"] != "" || attrs["tags"] != "" {
+		t.Errorf("unmatched file must keep defaults: %v", attrs)
+	}
+}
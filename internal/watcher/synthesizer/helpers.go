@@ -4,7 +4,9 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"path"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
@@ -93,6 +95,101 @@ func ApplyAuthExcludedModelsMeta(auth *coreauth.Auth, cfg *config.Config, perKey
 	}
 }
 
+// ApplyAuthDefaults sets the priority, weight and tags attributes of a file-backed auth. Values
+// stored in the auth file (as set through the management API) win over the first auth-defaults
+// rule matching the file name and provider.
+func ApplyAuthDefaults(auth *coreauth.Auth, cfg *config.Config, fileName string) {
+	if auth == nil {
+		return
+	}
+	var rule *config.AuthDefaultRule
+	if cfg != nil {
+		provider := strings.ToLower(strings.TrimSpace(auth.Provider))
+		for i := range cfg.AuthDefaults {
+			candidate := &cfg.AuthDefaults[i]
+			if p := strings.ToLower(strings.TrimSpace(candidate.Provider)); p != "" && p != provider {
+				continue
+			}
+			if pattern := strings.TrimSpace(candidate.Match); pattern != "" {
+				if matched, errMatch := path.Match(pattern, fileName); errMatch != nil || !matched {
+					continue
+				}
+			}
+			rule = candidate
+			break
+		}
+	}
+	if auth.Attributes == nil {
+		auth.Attributes = make(map[string]string)
+	}
+
+	priority, hasPriority := metadataInt(auth.Metadata, "priority")
+	if !hasPriority && rule != nil && rule.Priority != 0 {
+		priority, hasPriority = rule.Priority, true
+	}
+	if hasPriority {
+		auth.Attributes["priority"] = strconv.Itoa(priority)
+	}
+
+	weight, hasWeight := metadataInt(auth.Metadata, "weight")
+	if !hasWeight && rule != nil && rule.Weight > 1 {
+		weight, hasWeight = rule.Weight, true
+	}
+	if hasWeight && weight > 1 {
+		auth.Attributes["weight"] = strconv.Itoa(weight)
+	}
+
+	tags := metadataStrings(auth.Metadata, "tags")
+	if len(tags) == 0 && rule != nil {
+		tags = rule.Tags
+	}
+	cleaned := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if trimmed := strings.TrimSpace(tag); trimmed != "" {
+			cleaned = append(cleaned, trimmed)
+		}
+	}
+	if len(cleaned) > 0 {
+		auth.Attributes["tags"] = strings.Join(cleaned, ",")
+	}
+}
+
+// metadataInt reads an integer auth file field, which JSON decoding yields as float64.
+func metadataInt(metadata map[string]any, key string) (int, bool) {
+	switch v := metadata[key].(type) {
+	case float64:
+		return int(v), true
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case string:
+		if parsed, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			return parsed, true
+		}
+	}
+	return 0, false
+}
+
+// metadataStrings reads a string list auth file field, given as an array or a comma separated string.
+func metadataStrings(metadata map[string]any, key string) []string {
+	switch v := metadata[key].(type) {
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if str, ok := item.(string); ok {
+				out = append(out, str)
+			}
+		}
+		return out
+	case []string:
+		return v
+	case string:
+		return strings.Split(v, ",")
+	}
+	return nil
+}
+
 // addConfigHeadersToAttrs adds header configuration to auth attributes.
 // Headers are prefixed with "header:" in the attributes map.
 func addConfigHeadersToAttrs(headers map[string]string, attrs map[string]string) {
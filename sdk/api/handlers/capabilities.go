@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/i18n"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/ir"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// unsupportedFeatures lists features providers are known to lack. Anything not listed is assumed
+// supported.
+var unsupportedFeatures = map[string][]ir.Feature{
+	// The Claude Messages API has no response_format; translated requests lose it.
+	constant.Claude: {ir.FeatureJSONMode},
+}
+
+// applyCapabilityChecks drops providers that do not support every feature the request needs. It
+// fails with 400 when no provider remains, naming the features that could not be served.
+func applyCapabilityChecks(cfg *config.SDKConfig, handlerType, modelName string, rawJSON []byte, providers []string) ([]string, *interfaces.ErrorMessage) {
+	if cfg == nil || !cfg.CapabilityChecks.Enable || len(providers) == 0 {
+		return providers, nil
+	}
+	req, err := ir.Parse(handlerType, rawJSON)
+	if err != nil {
+		return providers, nil
+	}
+	needed := req.Features()
+	if len(needed) == 0 {
+		return providers, nil
+	}
+	allowed := make([]string, 0, len(providers))
+	missing := make(map[ir.Feature]bool)
+	for _, provider := range providers {
+		supported := true
+		for _, feature := range needed {
+			if !providerSupports(cfg.CapabilityChecks.Providers, provider, feature) {
+				missing[feature] = true
+				supported = false
+			}
+		}
+		if supported {
+			allowed = append(allowed, provider)
+		} else {
+			log.Debugf("capability check: skipping provider %s for model %s", provider, modelName)
+		}
+	}
+	if len(allowed) > 0 {
+		return allowed, nil
+	}
+	names := make([]string, 0, len(missing))
+	for _, feature := range ir.AllFeatures {
+		if missing[feature] {
+			names = append(names, string(feature))
+		}
+	}
+	return nil, &interfaces.ErrorMessage{
+		StatusCode: http.StatusBadRequest,
+		Error:      i18n.Errorf(i18n.MsgNoCapableProvider, modelName, strings.Join(names, ", ")),
+	}
+}
+
+// providerSupports resolves a feature flag from configuration, then the built-in defaults.
+func providerSupports(overrides map[string]config.ProviderFeatures, provider string, feature ir.Feature) bool {
+	if flags, ok := overrides[provider]; ok {
+		var flag *bool
+		switch feature {
+		case ir.FeatureVision:
+			flag = flags.Vision
+		case ir.FeatureTools:
+			flag = flags.Tools
+		case ir.FeatureThinking:
+			flag = flags.Thinking
+		case ir.FeatureJSONMode:
+			flag = flags.JSONMode
+		}
+		if flag != nil {
+			return *flag
+		}
+	}
+	for _, unsupported := range unsupportedFeatures[provider] {
+		if unsupported == feature {
+			return false
+		}
+	}
+	return true
+}
+
+// ModelCapabilities reports what the proxy honors for a model across the providers serving it.
+type ModelCapabilities struct {
+	Model     string   `json:"model"`
+	Providers []string `json:"providers"`
+	// Features maps each feature to whether a request using it will be honored.
+	Features map[ir.Feature]bool `json:"features"`
+	// MaxOutputTokens is the largest completion the model accepts, 0 when unknown.
+	MaxOutputTokens int `json:"max_output_tokens,omitempty"`
+	// ContextLength is the context window of the model, 0 when unknown.
+	ContextLength int                    `json:"context_length,omitempty"`
+	Reasoning     *ReasoningCapabilities `json:"reasoning,omitempty"`
+}
+
+// ReasoningCapabilities describes how reasoning can be controlled for a model.
+type ReasoningCapabilities struct {
+	Levels     []string `json:"levels,omitempty"`
+	MinBudget  int      `json:"min_budget,omitempty"`
+	MaxBudget  int      `json:"max_budget,omitempty"`
+	CanDisable bool     `json:"can_disable"`
+	Dynamic    bool     `json:"dynamic"`
+}
+
+// ModelCapabilities resolves the capabilities of a model. With capability checks enabled a
+// feature is honored when any provider supports it, since requests are routed accordingly;
+// otherwise every provider must support it.
+func (h *BaseAPIHandler) ModelCapabilities(modelName string) (*ModelCapabilities, *interfaces.ErrorMessage) {
+	providers, normalizedModel, _, errMsg := h.getRequestDetails(modelName)
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	var overrides map[string]config.ProviderFeatures
+	routed := false
+	if h.Cfg != nil {
+		overrides = h.Cfg.CapabilityChecks.Providers
+		routed = h.Cfg.CapabilityChecks.Enable
+	}
+	info := registry.GetGlobalRegistry().GetModelInfo(normalizedModel)
+	result := &ModelCapabilities{
+		Model:     normalizedModel,
+		Providers: providers,
+		Features:  make(map[ir.Feature]bool, len(ir.AllFeatures)),
+	}
+	for _, feature := range ir.AllFeatures {
+		supporting := 0
+		for _, provider := range providers {
+			if providerSupports(overrides, provider, feature) {
+				supporting++
+			}
+		}
+		result.Features[feature] = supporting == len(providers) || (routed && supporting > 0)
+	}
+	if info != nil {
+		result.MaxOutputTokens = info.MaxCompletionTokens
+		if result.MaxOutputTokens == 0 {
+			result.MaxOutputTokens = info.OutputTokenLimit
+		}
+		result.ContextLength = info.ContextLength
+		if result.ContextLength == 0 {
+			result.ContextLength = info.InputTokenLimit
+		}
+	}
+	if info == nil || info.Thinking == nil {
+		result.Features[ir.FeatureThinking] = false
+	} else if result.Features[ir.FeatureThinking] {
+		result.Reasoning = &ReasoningCapabilities{
+			Levels:     info.Thinking.Levels,
+			MinBudget:  info.Thinking.Min,
+			MaxBudget:  info.Thinking.Max,
+			CanDisable: info.Thinking.ZeroAllowed,
+			Dynamic:    info.Thinking.DynamicAllowed,
+		}
+	}
+	return result, nil
+}
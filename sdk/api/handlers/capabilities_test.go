@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/ir"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func TestApplyCapabilityChecks(t *testing.T) {
+	noVision := false
+	cfg := &config.SDKConfig{CapabilityChecks: config.CapabilityChecksConfig{
+		Enable:    true,
+		Providers: map[string]config.ProviderFeatures{"text-only": {Vision: &noVision}},
+	}}
+	vision := []byte(`{"messages":[{"role":"user","content":[{"type":"image_url","image_url":{"url":"https://example.com/a.png"}}]}]}`)
+
+	if got, errMsg := applyCapabilityChecks(&config.SDKConfig{}, constant.OpenAI, "m", vision, []string{"text-only"}); errMsg != nil || len(got) != 1 {
+		t.Fatalf("checks must be disabled by default, got %v %v", got, errMsg)
+	}
+
+	got, errMsg := applyCapabilityChecks(cfg, constant.OpenAI, "m", vision, []string{"text-only", "codex"})
+	if errMsg != nil || !reflect.DeepEqual(got, []string{"codex"}) {
+		t.Fatalf("expected only codex to remain, got %v %v", got, errMsg)
+	}
+
+	_, errMsg = applyCapabilityChecks(cfg, constant.OpenAI, "m", vision, []string{"text-only"})
+	if errMsg == nil || errMsg.StatusCode != http.StatusBadRequest || !strings.Contains(errMsg.Error.Error(), "vision") {
+		t.Fatalf("expected 400 naming vision, got %+v", errMsg)
+	}
+
+	jsonMode := []byte(`{"messages":[{"role":"user","content":"hi"}],"response_format":{"type":"json_object"}}`)
+	if _, errMsg = applyCapabilityChecks(cfg, constant.OpenAI, "m", jsonMode, []string{"claude"}); errMsg == nil {
+		t.Fatalf("expected built-in claude json_mode flag to reject the request")
+	}
+}
+
+func TestModelCapabilities(t *testing.T) {
+	registry.GetGlobalRegistry().RegisterClient("caps-claude", "claude", []*registry.ModelInfo{{
+		ID:                  "caps-model",
+		MaxCompletionTokens: 64000,
+		ContextLength:       200000,
+		Thinking:            &registry.ThinkingSupport{Min: 1024, Max: 32000, ZeroAllowed: true},
+	}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient("caps-claude") })
+
+	h := &BaseAPIHandler{Cfg: &config.SDKConfig{}}
+	got, errMsg := h.ModelCapabilities("caps-model")
+	if errMsg != nil {
+		t.Fatalf("unexpected error: %v", errMsg.Error)
+	}
+	if got.MaxOutputTokens != 64000 || got.ContextLength != 200000 {
+		t.Fatalf("unexpected limits: %+v", got)
+	}
+	if !got.Features[ir.FeatureTools] || !got.Features[ir.FeatureThinking] || got.Features[ir.FeatureJSONMode] {
+		t.Fatalf("unexpected features: %v", got.Features)
+	}
+	if got.Reasoning == nil || got.Reasoning.MaxBudget != 32000 || !got.Reasoning.CanDisable {
+		t.Fatalf("unexpected reasoning: %+v", got.Reasoning)
+	}
+
+	if _, errMsg = h.ModelCapabilities("caps-unknown"); errMsg == nil || errMsg.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown model, got %+v", errMsg)
+	}
+}
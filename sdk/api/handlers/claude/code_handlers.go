@@ -16,6 +16,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/batch"
 	. "github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
@@ -28,6 +29,9 @@ import (
 // It holds a pool of clients to interact with the backend service.
 type ClaudeCodeAPIHandler struct {
 	*handlers.BaseAPIHandler
+
+	// batches holds Message Batches API jobs created through this handler.
+	batches *batch.Store
 }
 
 // NewClaudeCodeAPIHandler creates a new Claude API handlers instance.
@@ -41,6 +45,7 @@ type ClaudeCodeAPIHandler struct {
 func NewClaudeCodeAPIHandler(apiHandlers *handlers.BaseAPIHandler) *ClaudeCodeAPIHandler {
 	return &ClaudeCodeAPIHandler{
 		BaseAPIHandler: apiHandlers,
+		batches:        batch.NewStore(messageBatchIDPrefix),
 	}
 }
 
@@ -158,31 +163,37 @@ func (h *ClaudeCodeAPIHandler) handleNonStreamingResponse(c *gin.Context, rawJSO
 		return
 	}
 
-	// Decompress gzipped responses - Claude API sometimes returns gzip without Content-Encoding header
-	// This fixes title generation and other non-streaming responses that arrive compressed
-	if len(resp) >= 2 && resp[0] == 0x1f && resp[1] == 0x8b {
-		gzReader, errGzip := gzip.NewReader(bytes.NewReader(resp))
-		if errGzip != nil {
-			log.Warnf("failed to decompress gzipped Claude response: %v", errGzip)
-		} else {
-			defer func() {
-				if errClose := gzReader.Close(); errClose != nil {
-					log.Warnf("failed to close Claude gzip reader: %v", errClose)
-				}
-			}()
-			decompressed, errRead := io.ReadAll(gzReader)
-			if errRead != nil {
-				log.Warnf("failed to read decompressed Claude response: %v", errRead)
-			} else {
-				resp = decompressed
-			}
-		}
-	}
+	resp = decompressClaudeResponse(resp)
 
 	_, _ = c.Writer.Write(resp)
 	cliCancel()
 }
 
+// decompressClaudeResponse decompresses gzipped responses - Claude API sometimes returns gzip
+// without Content-Encoding header. This fixes title generation and other non-streaming responses
+// that arrive compressed.
+func decompressClaudeResponse(resp []byte) []byte {
+	if len(resp) < 2 || resp[0] != 0x1f || resp[1] != 0x8b {
+		return resp
+	}
+	gzReader, errGzip := gzip.NewReader(bytes.NewReader(resp))
+	if errGzip != nil {
+		log.Warnf("failed to decompress gzipped Claude response: %v", errGzip)
+		return resp
+	}
+	defer func() {
+		if errClose := gzReader.Close(); errClose != nil {
+			log.Warnf("failed to close Claude gzip reader: %v", errClose)
+		}
+	}()
+	decompressed, errRead := io.ReadAll(gzReader)
+	if errRead != nil {
+		log.Warnf("failed to read decompressed Claude response: %v", errRead)
+		return resp
+	}
+	return decompressed
+}
+
 // handleStreamingResponse streams Claude-compatible responses backed by Gemini.
 // It sets up SSE, selects a backend client with rotation/quota logic,
 // forwards chunks, and translates them to Claude CLI format.
@@ -211,12 +222,8 @@ func (h *ClaudeCodeAPIHandler) handleStreamingResponse(c *gin.Context, rawJSON [
 	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
 
 	dataChan, errChan := h.ExecuteStreamWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON, "")
-	setSSEHeaders := func() {
-		c.Header("Content-Type", "text/event-stream")
-		c.Header("Cache-Control", "no-cache")
-		c.Header("Connection", "keep-alive")
-		c.Header("Access-Control-Allow-Origin", "*")
-	}
+	sse := handlers.NewSSEWriter(c.Writer)
+	salvage := handlers.NewClaudeSalvager()
 
 	// Peek at the first chunk to determine success or failure before setting headers
 	for {
@@ -241,48 +248,44 @@ func (h *ClaudeCodeAPIHandler) handleStreamingResponse(c *gin.Context, rawJSON [
 		case chunk, ok := <-dataChan:
 			if !ok {
 				// Stream closed without data? Send DONE or just headers.
-				setSSEHeaders()
+				handlers.SetSSEHeaders(c)
 				flusher.Flush()
 				cliCancel(nil)
 				return
 			}
 
 			// Success! Set headers now.
-			setSSEHeaders()
+			handlers.SetSSEHeaders(c)
 
 			// Write the first chunk
 			if len(chunk) > 0 {
-				_, _ = c.Writer.Write(chunk)
-				flusher.Flush()
+				sse.Event(chunk)
+				salvage.Observe(chunk)
+				sse.Flush()
 			}
 
 			// Continue streaming the rest
-			h.forwardClaudeStream(c, flusher, func(err error) { cliCancel(err) }, dataChan, errChan)
+			h.forwardClaudeStream(c, flusher, func(err error) { cliCancel(err) }, dataChan, errChan, salvage)
 			return
 		}
 	}
 }
 
-func (h *ClaudeCodeAPIHandler) forwardClaudeStream(c *gin.Context, flusher http.Flusher, cancel func(error), data <-chan []byte, errs <-chan *interfaces.ErrorMessage) {
+func (h *ClaudeCodeAPIHandler) forwardClaudeStream(c *gin.Context, flusher http.Flusher, cancel func(error), data <-chan []byte, errs <-chan *interfaces.ErrorMessage, salvage handlers.StreamSalvager) {
+	sse := handlers.NewSSEWriter(c.Writer)
 	h.ForwardStream(c, flusher, cancel, data, errs, handlers.StreamForwardOptions{
+		Salvage: salvage,
 		WriteChunk: func(chunk []byte) {
-			if len(chunk) == 0 {
-				return
-			}
-			_, _ = c.Writer.Write(chunk)
+			sse.Event(chunk)
 		},
 		WriteTerminalError: func(errMsg *interfaces.ErrorMessage) {
 			if errMsg == nil {
 				return
 			}
-			status := http.StatusInternalServerError
-			if errMsg.StatusCode > 0 {
-				status = errMsg.StatusCode
-			}
-			c.Status(status)
-
-			errorBytes, _ := json.Marshal(h.toClaudeError(errMsg))
-			_, _ = fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", errorBytes)
+			// Headers are committed, so the failure travels as an Anthropic error event that
+			// clients render instead of seeing the connection drop.
+			errorBytes, _ := json.Marshal(h.toClaudeError(c, errMsg))
+			sse.NamedEvent("error", errorBytes)
 		},
 	})
 }
@@ -297,12 +300,36 @@ type claudeErrorResponse struct {
 	Error claudeErrorDetail `json:"error"`
 }
 
-func (h *ClaudeCodeAPIHandler) toClaudeError(msg *interfaces.ErrorMessage) claudeErrorResponse {
+// toClaudeError converts an upstream failure into Anthropic's error schema. The error type
+// follows the status code; upstream bodies carrying an error object contribute their message,
+// and their type when they are Anthropic errors themselves. Proxy errors are localized for c.
+func (h *ClaudeCodeAPIHandler) toClaudeError(c *gin.Context, msg *interfaces.ErrorMessage) claudeErrorResponse {
+	status := http.StatusInternalServerError
+	if msg.StatusCode > 0 {
+		status = msg.StatusCode
+	}
+	errType := claudeErrorType(status)
+	message := handlers.ErrorText(c, status, msg.Error)
+	if gjson.Valid(message) {
+		body := gjson.Parse(message)
+		detail := body.Get("error")
+		if body.Get("type").String() == "error" && detail.Get("type").String() != "" {
+			errType = detail.Get("type").String()
+		}
+		switch {
+		case detail.Get("message").String() != "":
+			message = detail.Get("message").String()
+		case detail.Type == gjson.String && detail.String() != "":
+			message = detail.String()
+		case body.Get("message").String() != "":
+			message = body.Get("message").String()
+		}
+	}
 	return claudeErrorResponse{
 		Type: "error",
 		Error: claudeErrorDetail{
-			Type:    "api_error",
-			Message: msg.Error.Error(),
+			Type:    errType,
+			Message: message,
 		},
 	}
 }
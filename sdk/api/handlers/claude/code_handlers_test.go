@@ -0,0 +1,103 @@
+package claude
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func TestForwardClaudeStreamEmitsErrorEvent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+
+	data := make(chan []byte, 1)
+	errs := make(chan *interfaces.ErrorMessage, 1)
+	data <- []byte("event: message_start\ndata: {\"type\":\"message_start\"}\n\n")
+	close(data)
+	errs <- &interfaces.ErrorMessage{
+		StatusCode: 529,
+		Error:      errors.New(`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`),
+	}
+
+	h := NewClaudeCodeAPIHandler(handlers.NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, nil))
+	var cancelErr error
+	h.forwardClaudeStream(c, c.Writer, func(err error) { cancelErr = err }, data, errs, handlers.NewClaudeSalvager())
+
+	events := strings.Split(strings.TrimSuffix(rec.Body.String(), "\n\n"), "\n\n")
+	last := events[len(events)-1]
+	if !strings.HasPrefix(last, "event: error\ndata: ") {
+		t.Fatalf("stream must end with an error event, got %q", rec.Body.String())
+	}
+	payload := gjson.Parse(strings.TrimPrefix(last, "event: error\ndata: "))
+	if payload.Get("type").String() != "error" || payload.Get("error.type").String() != "overloaded_error" || payload.Get("error.message").String() != "Overloaded" {
+		t.Fatalf("unexpected error payload: %s", payload.Raw)
+	}
+	if cancelErr == nil {
+		t.Fatalf("stream must be cancelled with the upstream error")
+	}
+}
+
+func TestToClaudeErrorMapsStatusAndMessage(t *testing.T) {
+	h := &ClaudeCodeAPIHandler{}
+	cases := []struct {
+		msg         *interfaces.ErrorMessage
+		wantType    string
+		wantMessage string
+	}{
+		{&interfaces.ErrorMessage{StatusCode: http.StatusTooManyRequests, Error: errors.New("quota exhausted")}, "rate_limit_error", "quota exhausted"},
+		{&interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: errors.New(`{"error":{"message":"bad tool schema","type":"invalid_request_error"}}`)}, "invalid_request_error", "bad tool schema"},
+		{&interfaces.ErrorMessage{StatusCode: http.StatusBadGateway, Error: errors.New(`{"error":"upstream reset"}`)}, "api_error", "upstream reset"},
+		{&interfaces.ErrorMessage{}, "api_error", http.StatusText(http.StatusInternalServerError)},
+	}
+	for _, tc := range cases {
+		got := h.toClaudeError(nil, tc.msg)
+		if got.Type != "error" || got.Error.Type != tc.wantType || got.Error.Message != tc.wantMessage {
+			t.Errorf("toClaudeError(%d, %v) = %+v, want %s %q", tc.msg.StatusCode, tc.msg.Error, got, tc.wantType, tc.wantMessage)
+		}
+	}
+}
+
+func TestForwardClaudeStreamSalvagesTruncatedStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	c.Set("apiKey", "agent-key")
+
+	data := make(chan []byte)
+	errs := make(chan *interfaces.ErrorMessage, 1)
+	close(data)
+	errs <- &interfaces.ErrorMessage{StatusCode: http.StatusBadGateway, Error: errors.New("connection reset")}
+
+	cfg := &sdkconfig.SDKConfig{}
+	cfg.Streaming.PartialSalvage = sdkconfig.PartialSalvageConfig{APIKeys: []string{"agent-key"}, MinBytes: 64}
+	h := NewClaudeCodeAPIHandler(handlers.NewBaseAPIHandlers(cfg, nil))
+	salvage := handlers.NewClaudeSalvager()
+	// Chunks the handler wrote before forwarding began.
+	salvage.Observe([]byte("event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n"))
+	salvage.Observe([]byte("event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"" + strings.Repeat("x", 64) + "\"}}\n\n"))
+	h.forwardClaudeStream(c, c.Writer, func(error) {}, data, errs, salvage)
+
+	body := rec.Body.String()
+	if strings.Contains(body, "event: error") {
+		t.Fatalf("salvaged stream must not carry an error event: %q", body)
+	}
+	for _, want := range []string{"event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":0}", `"stop_reason":"max_tokens"`, "event: message_stop"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("salvaged stream lacks %q: %q", want, body)
+		}
+	}
+	if rec.Header().Get(http.TrailerPrefix+handlers.PartialSalvageHeader) == "" {
+		t.Fatalf("salvaged stream must announce truncation in a trailer")
+	}
+}
@@ -0,0 +1,320 @@
+package claude
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/batch"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	messageBatchIDPrefix    = "msgbatch_"
+	maxMessageBatchRequests = 100000
+	maxCustomIDLength       = 64
+	defaultBatchListLimit   = 20
+	maxBatchListLimit       = 1000
+)
+
+// messageBatchForwardHeaders are copied from the create request onto every batched request.
+var messageBatchForwardHeaders = []string{"Anthropic-Version", "Anthropic-Beta", "User-Agent"}
+
+// messageBatch is the Anthropic message_batch object.
+type messageBatch struct {
+	ID                string       `json:"id"`
+	Type              string       `json:"type"`
+	ProcessingStatus  string       `json:"processing_status"`
+	RequestCounts     batch.Counts `json:"request_counts"`
+	EndedAt           *string      `json:"ended_at"`
+	CreatedAt         string       `json:"created_at"`
+	ExpiresAt         string       `json:"expires_at"`
+	ArchivedAt        *string      `json:"archived_at"`
+	CancelInitiatedAt *string      `json:"cancel_initiated_at"`
+	ResultsURL        *string      `json:"results_url"`
+}
+
+type messageBatchCreateRequest struct {
+	Requests []struct {
+		CustomID string          `json:"custom_id"`
+		Params   json.RawMessage `json:"params"`
+	} `json:"requests"`
+}
+
+// CreateMessageBatch handles POST /v1/messages/batches. Requests are executed in the background
+// as batch-priority work through the same routing and credential pool as /v1/messages.
+func (h *ClaudeCodeAPIHandler) CreateMessageBatch(c *gin.Context) {
+	var body messageBatchCreateRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		writeClaudeError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("Invalid request: %v", err))
+		return
+	}
+	if len(body.Requests) == 0 {
+		writeClaudeError(c, http.StatusBadRequest, "invalid_request_error", "requests: at least one request is required")
+		return
+	}
+	if len(body.Requests) > maxMessageBatchRequests {
+		writeClaudeError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("requests: a batch may contain at most %d requests", maxMessageBatchRequests))
+		return
+	}
+	seen := make(map[string]struct{}, len(body.Requests))
+	requests := make([]batch.Request, 0, len(body.Requests))
+	for i, item := range body.Requests {
+		if item.CustomID == "" || len(item.CustomID) > maxCustomIDLength {
+			writeClaudeError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("requests.%d.custom_id: must be between 1 and %d characters", i, maxCustomIDLength))
+			return
+		}
+		if _, dup := seen[item.CustomID]; dup {
+			writeClaudeError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("requests.%d.custom_id: duplicate custom_id %q", i, item.CustomID))
+			return
+		}
+		seen[item.CustomID] = struct{}{}
+		if !gjson.ValidBytes(item.Params) || !gjson.GetBytes(item.Params, "model").Exists() {
+			writeClaudeError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("requests.%d.params: a Messages API request with a model is required", i))
+			return
+		}
+		requests = append(requests, batch.Request{CustomID: item.CustomID, Params: item.Params})
+	}
+
+	headers := make(http.Header)
+	for _, name := range messageBatchForwardHeaders {
+		if value := c.GetHeader(name); value != "" {
+			headers.Set(name, value)
+		}
+	}
+	snapshot := h.batches.Create(c.GetString("apiKey"), requests, h.messageBatchExecutor(headers))
+	c.JSON(http.StatusOK, toMessageBatch(c, snapshot))
+}
+
+// ListMessageBatches handles GET /v1/messages/batches, newest first.
+func (h *ClaudeCodeAPIHandler) ListMessageBatches(c *gin.Context) {
+	limit := defaultBatchListLimit
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxBatchListLimit {
+			writeClaudeError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("limit: must be between 1 and %d", maxBatchListLimit))
+			return
+		}
+		limit = parsed
+	}
+	all := h.batches.List(c.GetString("apiKey"))
+	indexOf := func(id string) int {
+		for i, snapshot := range all {
+			if snapshot.ID == id {
+				return i
+			}
+		}
+		return -1
+	}
+	var start, end int
+	var hasMore bool
+	if beforeID := c.Query("before_id"); beforeID != "" {
+		if end = indexOf(beforeID); end < 0 {
+			writeClaudeError(c, http.StatusBadRequest, "invalid_request_error", "before_id: invalid cursor")
+			return
+		}
+		start = max(end-limit, 0)
+		hasMore = start > 0
+	} else {
+		if afterID := c.Query("after_id"); afterID != "" {
+			if start = indexOf(afterID) + 1; start == 0 {
+				writeClaudeError(c, http.StatusBadRequest, "invalid_request_error", "after_id: invalid cursor")
+				return
+			}
+		}
+		end = min(start+limit, len(all))
+		hasMore = end < len(all)
+	}
+	page := all[start:end]
+
+	data := make([]messageBatch, 0, len(page))
+	for _, snapshot := range page {
+		data = append(data, toMessageBatch(c, snapshot))
+	}
+	resp := gin.H{"data": data, "has_more": hasMore, "first_id": nil, "last_id": nil}
+	if len(data) > 0 {
+		resp["first_id"] = data[0].ID
+		resp["last_id"] = data[len(data)-1].ID
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetMessageBatch handles GET /v1/messages/batches/:id.
+func (h *ClaudeCodeAPIHandler) GetMessageBatch(c *gin.Context) {
+	snapshot, err := h.batches.Get(c.GetString("apiKey"), c.Param("id"))
+	if err != nil {
+		writeMessageBatchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toMessageBatch(c, snapshot))
+}
+
+// CancelMessageBatch handles POST /v1/messages/batches/:id/cancel.
+func (h *ClaudeCodeAPIHandler) CancelMessageBatch(c *gin.Context) {
+	snapshot, err := h.batches.Cancel(c.GetString("apiKey"), c.Param("id"))
+	if err != nil {
+		writeMessageBatchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toMessageBatch(c, snapshot))
+}
+
+// DeleteMessageBatch handles DELETE /v1/messages/batches/:id. Only ended batches can be deleted.
+func (h *ClaudeCodeAPIHandler) DeleteMessageBatch(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.batches.Delete(c.GetString("apiKey"), id); err != nil {
+		writeMessageBatchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": id, "type": "message_batch_deleted"})
+}
+
+// MessageBatchResults handles GET /v1/messages/batches/:id/results, streaming one JSON line per
+// request in submission order.
+func (h *ClaudeCodeAPIHandler) MessageBatchResults(c *gin.Context) {
+	outcomes, err := h.batches.Results(c.GetString("apiKey"), c.Param("id"))
+	if err != nil {
+		writeMessageBatchError(c, err)
+		return
+	}
+	c.Header("Content-Type", "application/x-jsonl")
+	c.Status(http.StatusOK)
+	for _, outcome := range outcomes {
+		line, errLine := messageBatchResultLine(outcome)
+		if errLine != nil {
+			continue
+		}
+		_, _ = c.Writer.Write(line)
+		_, _ = c.Writer.Write([]byte("\n"))
+	}
+}
+
+// messageBatchExecutor runs one batched request as a non-streaming Messages API call.
+func (h *ClaudeCodeAPIHandler) messageBatchExecutor(headers http.Header) batch.Executor {
+	return func(ctx context.Context, owner string, req batch.Request) batch.Outcome {
+		params := []byte(req.Params)
+		if gjson.GetBytes(params, "stream").Exists() {
+			params, _ = sjson.DeleteBytes(params, "stream")
+		}
+		modelName := gjson.GetBytes(params, "model").String()
+		resp, errMsg := h.ExecuteDetached(handlers.WithBatchPriority(ctx), owner, "/v1/messages", headers, h.HandlerType(), modelName, params, "")
+		if errMsg != nil {
+			status := errMsg.StatusCode
+			if status <= 0 {
+				status = http.StatusInternalServerError
+			}
+			return batch.Outcome{Type: batch.OutcomeErrored, StatusCode: status, Error: handlers.ErrorText(nil, status, errMsg.Error)}
+		}
+		return batch.Outcome{Type: batch.OutcomeSucceeded, StatusCode: http.StatusOK, Body: decompressClaudeResponse(resp)}
+	}
+}
+
+// messageBatchResultLine renders an outcome in the Anthropic results JSONL format.
+func messageBatchResultLine(outcome batch.Outcome) ([]byte, error) {
+	line := []byte(`{}`)
+	line, _ = sjson.SetBytes(line, "custom_id", outcome.CustomID)
+	line, _ = sjson.SetBytes(line, "result.type", outcome.Type)
+	switch outcome.Type {
+	case batch.OutcomeSucceeded:
+		body := bytes.TrimSpace(outcome.Body)
+		if json.Valid(body) {
+			return sjson.SetRawBytes(line, "result.message", body)
+		}
+		line, _ = sjson.SetBytes(line, "result.type", batch.OutcomeErrored)
+		outcome.StatusCode = http.StatusBadGateway
+		outcome.Error = "upstream returned an invalid message"
+		fallthrough
+	case batch.OutcomeErrored:
+		message := strings.TrimSpace(outcome.Error)
+		// Upstream Claude errors already carry the {"type":"error","error":{...}} envelope.
+		if json.Valid([]byte(message)) && gjson.Get(message, "type").String() == "error" {
+			return sjson.SetRawBytes(line, "result.error", []byte(message))
+		}
+		errBody, errMarshal := json.Marshal(claudeErrorResponse{
+			Type:  "error",
+			Error: claudeErrorDetail{Type: claudeErrorType(outcome.StatusCode), Message: message},
+		})
+		if errMarshal != nil {
+			return nil, errMarshal
+		}
+		return sjson.SetRawBytes(line, "result.error", errBody)
+	default:
+		return line, nil
+	}
+}
+
+func toMessageBatch(c *gin.Context, snapshot batch.Snapshot) messageBatch {
+	out := messageBatch{
+		ID:                snapshot.ID,
+		Type:              "message_batch",
+		ProcessingStatus:  snapshot.Status,
+		RequestCounts:     snapshot.Counts,
+		CreatedAt:         snapshot.CreatedAt.Format(time.RFC3339Nano),
+		ExpiresAt:         snapshot.ExpiresAt.Format(time.RFC3339Nano),
+		EndedAt:           formatBatchTime(snapshot.EndedAt),
+		CancelInitiatedAt: formatBatchTime(snapshot.CancelInitiatedAt),
+	}
+	if snapshot.Status == batch.StatusEnded {
+		scheme := "http"
+		if c.Request.TLS != nil {
+			scheme = "https"
+		}
+		resultsURL := fmt.Sprintf("%s://%s/v1/messages/batches/%s/results", scheme, c.Request.Host, snapshot.ID)
+		out.ResultsURL = &resultsURL
+	}
+	return out
+}
+
+func formatBatchTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format(time.RFC3339Nano)
+	return &formatted
+}
+
+func writeMessageBatchError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, batch.ErrNotFound):
+		writeClaudeError(c, http.StatusNotFound, "not_found_error", "message batch not found")
+	case errors.Is(err, batch.ErrNotEnded):
+		writeClaudeError(c, http.StatusBadRequest, "invalid_request_error", "message batch is still processing")
+	default:
+		writeClaudeError(c, http.StatusInternalServerError, "api_error", err.Error())
+	}
+}
+
+func writeClaudeError(c *gin.Context, status int, errType, message string) {
+	c.JSON(status, claudeErrorResponse{Type: "error", Error: claudeErrorDetail{Type: errType, Message: message}})
+}
+
+// claudeErrorType maps an HTTP status to the Anthropic error type.
+func claudeErrorType(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "invalid_request_error"
+	case http.StatusUnauthorized:
+		return "authentication_error"
+	case http.StatusForbidden:
+		return "permission_error"
+	case http.StatusNotFound:
+		return "not_found_error"
+	case http.StatusRequestEntityTooLarge:
+		return "request_too_large"
+	case http.StatusTooManyRequests:
+		return "rate_limit_error"
+	case 529:
+		return "overloaded_error"
+	default:
+		return "api_error"
+	}
+}
@@ -0,0 +1,42 @@
+package claude
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/batch"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func TestListMessageBatchesRejectsUnknownCursor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewClaudeCodeAPIHandler(handlers.NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, nil))
+	exec := func(context.Context, string, batch.Request) batch.Outcome {
+		return batch.Outcome{Type: batch.OutcomeSucceeded, Body: []byte(`{}`)}
+	}
+	created := h.batches.Create("k", []batch.Request{{CustomID: "a"}}, exec)
+
+	list := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = httptest.NewRequest(http.MethodGet, "/v1/messages/batches?"+query, nil)
+		c.Set("apiKey", "k")
+		h.ListMessageBatches(c)
+		return rec
+	}
+
+	if rec := list("after_id=" + created.ID); rec.Code != http.StatusOK || gjson.Get(rec.Body.String(), "data.#").Int() != 0 {
+		t.Fatalf("known cursor: got %d %s", rec.Code, rec.Body.String())
+	}
+	for _, query := range []string{"after_id=msgbatch_unknown", "before_id=msgbatch_unknown"} {
+		rec := list(query)
+		if rec.Code != http.StatusBadRequest || gjson.Get(rec.Body.String(), "error.message").String() == "" {
+			t.Fatalf("%s: got %d %s, want 400", query, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/conversation"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/i18n"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// applyConversationCap attaches the request's conversation to ctx for token accounting and
+// enforces the per-conversation cap by failing the request or injecting a system note.
+func applyConversationCap(ctx context.Context, handlerType string, rawJSON []byte) (context.Context, []byte, *interfaces.ErrorMessage) {
+	tracker := conversation.Default()
+	if !tracker.Enabled() {
+		return ctx, rawJSON, nil
+	}
+	key := conversation.Key(requestAPIKey(ctx), coreauth.ConversationKey(requestHeaders(ctx), rawJSON))
+	if key == "" {
+		return ctx, rawJSON, nil
+	}
+	ctx = conversation.WithKey(ctx, key)
+	used, limit, action, exceeded := tracker.Check(key)
+	if !exceeded {
+		return ctx, rawJSON, nil
+	}
+	if action == conversation.ActionWarn {
+		note := fmt.Sprintf("Note from the API proxy: this conversation has used %d tokens, exceeding its budget of %d. Finish the current task now with a final answer and do not start further tool calls.", used, limit)
+		if updated, ok := injectSystemNote(handlerType, rawJSON, note); ok {
+			return ctx, updated, nil
+		}
+		log.Warnf("conversation %s exceeded its token cap but %s requests do not support note injection", key, handlerType)
+		return ctx, rawJSON, nil
+	}
+	return ctx, rawJSON, &interfaces.ErrorMessage{
+		StatusCode: http.StatusBadRequest,
+		Error:      i18n.Errorf(i18n.MsgConversationCapExceeded, used, limit),
+	}
+}
+
+// injectSystemNote appends note to the system instructions of a request in handlerType's format.
+func injectSystemNote(handlerType string, rawJSON []byte, note string) ([]byte, bool) {
+	var (
+		out []byte
+		err error
+	)
+	switch handlerType {
+	case constant.OpenAI:
+		messages := gjson.GetBytes(rawJSON, "messages")
+		if !messages.IsArray() {
+			return rawJSON, false
+		}
+		out, err = sjson.SetBytes(rawJSON, "messages.-1", map[string]string{"role": "system", "content": note})
+	case constant.OpenaiResponse:
+		instructions := gjson.GetBytes(rawJSON, "instructions").String()
+		if instructions != "" {
+			note = instructions + "\n\n" + note
+		}
+		out, err = sjson.SetBytes(rawJSON, "instructions", note)
+	case constant.Claude:
+		system := gjson.GetBytes(rawJSON, "system")
+		switch {
+		case system.IsArray():
+			out, err = sjson.SetBytes(rawJSON, "system.-1", map[string]string{"type": "text", "text": note})
+		case system.Type == gjson.String && system.String() != "":
+			out, err = sjson.SetBytes(rawJSON, "system", system.String()+"\n\n"+note)
+		default:
+			out, err = sjson.SetBytes(rawJSON, "system", note)
+		}
+	case constant.Gemini:
+		out, err = sjson.SetBytes(rawJSON, "systemInstruction.parts.-1", map[string]string{"text": note})
+	case constant.GeminiCLI:
+		out, err = sjson.SetBytes(rawJSON, "request.systemInstruction.parts.-1", map[string]string{"text": note})
+	default:
+		return rawJSON, false
+	}
+	if err != nil {
+		return rawJSON, false
+	}
+	return out, true
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/tidwall/gjson"
+)
+
+func TestInjectSystemNote(t *testing.T) {
+	cases := []struct {
+		handlerType string
+		body        string
+		path        string
+		want        string
+	}{
+		{constant.OpenAI, `{"messages":[{"role":"user","content":"hi"}]}`, "messages.1.content", "note"},
+		{constant.OpenaiResponse, `{"instructions":"be brief"}`, "instructions", "be brief\n\nnote"},
+		{constant.Claude, `{"system":"sys"}`, "system", "sys\n\nnote"},
+		{constant.Claude, `{"system":[{"type":"text","text":"sys"}]}`, "system.1.text", "note"},
+		{constant.Claude, `{"messages":[]}`, "system", "note"},
+		{constant.Gemini, `{"contents":[]}`, "systemInstruction.parts.0.text", "note"},
+		{constant.GeminiCLI, `{"request":{"contents":[]}}`, "request.systemInstruction.parts.0.text", "note"},
+	}
+	for _, tc := range cases {
+		out, ok := injectSystemNote(tc.handlerType, []byte(tc.body), "note")
+		if !ok {
+			t.Fatalf("%s: injection failed", tc.handlerType)
+		}
+		if got := gjson.GetBytes(out, tc.path).String(); got != tc.want {
+			t.Fatalf("%s: %s = %q, want %q (body %s)", tc.handlerType, tc.path, got, tc.want, out)
+		}
+	}
+	if _, ok := injectSystemNote(constant.OpenAI, []byte(`{"prompt":"x"}`), "note"); ok {
+		t.Fatal("expected injection to be skipped without messages")
+	}
+}
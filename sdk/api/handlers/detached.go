@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+)
+
+// ExecuteDetached runs a non-streaming request outside of a live HTTP request on behalf of apiKey.
+// A synthetic gin context carries path and headers so executors, request logging and usage
+// accounting see the same request shape they would for a direct client call; response headers
+// set on it are discarded.
+func (h *BaseAPIHandler) ExecuteDetached(ctx context.Context, apiKey, path string, headers http.Header, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if path == "" {
+		path = "/"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, path, bytes.NewReader(rawJSON))
+	if err != nil {
+		return nil, &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: err}
+	}
+	for name, values := range headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	ginCtx := &gin.Context{Request: req, Writer: &detachedWriter{header: make(http.Header)}}
+	if apiKey != "" {
+		ginCtx.Set("apiKey", apiKey)
+	}
+
+	execCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	execCtx = context.WithValue(execCtx, "gin", ginCtx)
+	return h.ExecuteWithAuthManager(execCtx, handlerType, modelName, rawJSON, alt)
+}
+
+// detachedWriter is the response writer of a detached request. It records the status and the
+// number of body bytes so usage accounting can read them, and discards everything else.
+type detachedWriter struct {
+	header http.Header
+	status int
+	size   int
+}
+
+func (w *detachedWriter) Header() http.Header { return w.header }
+
+func (w *detachedWriter) Write(data []byte) (int, error) {
+	w.WriteHeaderNow()
+	w.size += len(data)
+	return len(data), nil
+}
+
+func (w *detachedWriter) WriteString(s string) (int, error) { return w.Write([]byte(s)) }
+
+func (w *detachedWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *detachedWriter) WriteHeaderNow() { w.WriteHeader(http.StatusOK) }
+
+func (w *detachedWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *detachedWriter) Size() int { return w.size }
+
+func (w *detachedWriter) Written() bool { return w.status != 0 }
+
+func (w *detachedWriter) Flush() {}
+
+func (w *detachedWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, http.ErrNotSupported
+}
+
+func (w *detachedWriter) CloseNotify() <-chan bool { return nil }
+
+func (w *detachedWriter) Pusher() http.Pusher { return nil }
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestExecuteDetachedRunsThroughTheFallbackChain(t *testing.T) {
+	quota := &chainExecutor{provider: "chain-quota", status: http.StatusTooManyRequests}
+	ok := &chainExecutor{provider: "chain-ok"}
+	handler := newChainHandler(t, quota, ok)
+
+	headers := http.Header{"X-Test": {"1"}}
+	resp, errMsg := handler.ExecuteDetached(context.Background(), "sk-detached", "/v1/chat/completions", headers, "openai", "smart", []byte(`{"model":"smart"}`), "")
+	if errMsg != nil {
+		t.Fatalf("unexpected error: %+v", errMsg)
+	}
+	if string(resp) != "chain-ok-model" {
+		t.Fatalf("response = %q, want the fallback model", resp)
+	}
+}
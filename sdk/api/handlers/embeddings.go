@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/i18n"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+)
+
+// embeddingProviders lists the providers whose executors serve OpenAI-format embeddings
+// requests. Other executors would treat the request as a chat completion.
+var embeddingProviders = map[string]bool{
+	"github-copilot": true,
+}
+
+// ExecuteEmbeddingsWithAuthManager executes an OpenAI-format embeddings request via the core
+// auth manager, restricted to providers that serve embeddings.
+func (h *BaseAPIHandler) ExecuteEmbeddingsWithAuthManager(ctx context.Context, modelName string, rawJSON []byte) ([]byte, *interfaces.ErrorMessage) {
+	modelName = applyModelRename(ctx, modelName)
+	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName)
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	if errMsg = applyModelPolicies(ctx, h.Cfg, modelName, normalizedModel, h.AuthManager.ModelAliasTargets(normalizedModel)...); errMsg != nil {
+		return nil, errMsg
+	}
+	if providers, errMsg = applyMaintenance(providers); errMsg != nil {
+		return nil, errMsg
+	}
+	if providers, errMsg = applyBudget(ctx, providers); errMsg != nil {
+		return nil, errMsg
+	}
+	supported := make([]string, 0, len(providers))
+	for _, provider := range providers {
+		if embeddingProviders[provider] {
+			supported = append(supported, provider)
+		}
+	}
+	if len(supported) == 0 {
+		return nil, &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: i18n.Errorf(i18n.MsgEmbeddingsUnsupported, modelName)}
+	}
+
+	req := coreexecutor.Request{
+		Model:   normalizedModel,
+		Payload: cloneBytes(rawJSON),
+		Format:  sdktranslator.FromString("openai"),
+	}
+	opts := coreexecutor.Options{
+		OriginalRequest: cloneBytes(rawJSON),
+		SourceFormat:    sdktranslator.FromString("openai"),
+	}
+	opts.Headers = requestHeaders(ctx)
+	opts.Metadata = mergeMetadata(cloneMetadata(metadata), requestExecutionMetadata(ctx))
+	if opts.Metadata == nil {
+		opts.Metadata = make(map[string]any, 1)
+	}
+	opts.Metadata[coreexecutor.OperationMetadataKey] = coreexecutor.OperationEmbeddings
+	release, _, errSlot := h.acquireExecutionSlot(ctx)
+	if errSlot != nil {
+		return nil, errSlot
+	}
+	defer release()
+	resp, err := h.AuthManager.Execute(ctx, supported, req, opts)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if se, ok := err.(interface{ StatusCode() int }); ok && se != nil {
+			if code := se.StatusCode(); code > 0 {
+				status = code
+			}
+		}
+		var addon http.Header
+		if he, ok := err.(interface{ Headers() http.Header }); ok && he != nil {
+			if hdr := he.Headers(); hdr != nil {
+				addon = hdr.Clone()
+			}
+		}
+		return nil, &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
+	}
+	return cloneBytes(resp.Payload), nil
+}
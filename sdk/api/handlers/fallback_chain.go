@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// ServedModelHeader names the model of a fallback chain that served the response.
+const ServedModelHeader = "X-CLIProxy-Served-Model"
+
+// fallbackChain returns the models the alias modelName is served by, in order, or nil when
+// modelName is not a fallback chain alias. Models no credential serves right now are left out
+// unless none is served, so that the request still fails with the usual error.
+func fallbackChain(cfg *config.SDKConfig, modelName string) []string {
+	if cfg == nil || len(cfg.FallbackChains) == 0 {
+		return nil
+	}
+	modelName = strings.TrimSpace(modelName)
+	for _, chain := range cfg.FallbackChains {
+		if !strings.EqualFold(strings.TrimSpace(chain.Alias), modelName) {
+			continue
+		}
+		models := make([]string, 0, len(chain.Models))
+		served := make([]string, 0, len(chain.Models))
+		for _, model := range chain.Models {
+			if model = strings.TrimSpace(model); model == "" {
+				continue
+			}
+			models = append(models, model)
+			if base, _ := normalizeModelMetadata(model); len(util.GetProviderName(base)) > 0 {
+				served = append(served, model)
+			}
+		}
+		if len(served) > 0 {
+			return served
+		}
+		return models
+	}
+	return nil
+}
+
+// fallbackChainEligible reports whether a chain moves on to its next model after errMsg: quota
+// exhaustion and server errors, including every credential of the model cooling down.
+func fallbackChainEligible(errMsg *interfaces.ErrorMessage) bool {
+	if errMsg == nil {
+		return false
+	}
+	return errMsg.StatusCode == http.StatusTooManyRequests || errMsg.StatusCode >= http.StatusInternalServerError
+}
+
+// executeFallbackChain executes a non-streaming request through the models of a fallback chain.
+// Only the last model may be served by the local fallback, and only its failure is captured for
+// the replay queue, since the chain moves on after the others.
+func (h *BaseAPIHandler) executeFallbackChain(ctx context.Context, handlerType string, chain []string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var errMsg *interfaces.ErrorMessage
+	for i, model := range chain {
+		last := i == len(chain)-1
+		var resp []byte
+		resp, errMsg = h.executeWithAuthManager(ctx, handlerType, model, rawJSON, alt, last, last)
+		if errMsg == nil {
+			markServedModel(ctx, model)
+			return resp, nil
+		}
+		if last || !fallbackChainEligible(errMsg) {
+			break
+		}
+		log.Warnf("fallback chain: model %s failed with status %d, trying %s", model, errMsg.StatusCode, chain[i+1])
+	}
+	return nil, errMsg
+}
+
+// executeStreamFallbackChain executes a streaming request through the models of a fallback
+// chain. It waits for the first chunk of each model, so a model is only replaced before the
+// client has received anything.
+func (h *BaseAPIHandler) executeStreamFallbackChain(ctx context.Context, handlerType string, chain []string, rawJSON []byte, alt string) (<-chan []byte, <-chan *interfaces.ErrorMessage) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for i, model := range chain {
+		last := i == len(chain)-1
+		dataChan, errChan := h.executeStreamWithAuthManager(ctx, handlerType, model, rawJSON, alt, last)
+		first, errMsg, ok := awaitFirstChunk(ctx, dataChan, errChan)
+		if !ok {
+			return dataChan, errChan
+		}
+		if errMsg == nil {
+			markServedModel(ctx, model)
+			return prependChunk(ctx, first, dataChan, errChan)
+		}
+		if last || !fallbackChainEligible(errMsg) {
+			failed := make(chan *interfaces.ErrorMessage, 1)
+			failed <- errMsg
+			close(failed)
+			return nil, failed
+		}
+		log.Warnf("fallback chain: model %s failed with status %d, trying %s", model, errMsg.StatusCode, chain[i+1])
+	}
+	return nil, nil
+}
+
+// awaitFirstChunk waits for the first chunk or the error of a stream. It returns false when ctx
+// ends first; a nil chunk without error means the stream ended empty.
+func awaitFirstChunk(ctx context.Context, dataChan <-chan []byte, errChan <-chan *interfaces.ErrorMessage) ([]byte, *interfaces.ErrorMessage, bool) {
+	select {
+	case <-ctx.Done():
+		return nil, nil, false
+	case chunk, ok := <-dataChan:
+		if ok {
+			return chunk, nil, true
+		}
+		// The error channel is closed before the data channel, so an error is already buffered.
+		errMsg := <-errChan
+		return nil, errMsg, true
+	case errMsg, ok := <-errChan:
+		if !ok {
+			return nil, nil, true
+		}
+		return nil, errMsg, true
+	}
+}
+
+// prependChunk returns a stream that yields first and then the rest of dataChan, followed by
+// the error of errChan.
+func prependChunk(ctx context.Context, first []byte, dataChan <-chan []byte, errChan <-chan *interfaces.ErrorMessage) (<-chan []byte, <-chan *interfaces.ErrorMessage) {
+	out := make(chan []byte)
+	outErr := make(chan *interfaces.ErrorMessage, 1)
+	go func() {
+		defer close(out)
+		defer close(outErr)
+		if first != nil {
+			select {
+			case out <- first:
+			case <-ctx.Done():
+				go drainStream(dataChan)
+				return
+			}
+		}
+		for chunk := range dataChan {
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				go drainStream(dataChan)
+				return
+			}
+		}
+		if errMsg, ok := <-errChan; ok && errMsg != nil {
+			outErr <- errMsg
+		}
+	}()
+	return out, outErr
+}
+
+// drainStream discards the rest of an abandoned stream so its producer can finish.
+func drainStream(dataChan <-chan []byte) {
+	for range dataChan {
+	}
+}
+
+// markServedModel names the chain model that served the response.
+func markServedModel(ctx context.Context, model string) {
+	if ginCtx, ok := ctx.Value("gin").(*gin.Context); ok && ginCtx != nil {
+		ginCtx.Header(ServedModelHeader, model)
+	}
+}
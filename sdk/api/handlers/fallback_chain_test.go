@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/replay"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+// chainExecutor answers every request with status, or with the requested model when status is 0.
+type chainExecutor struct {
+	provider string
+	status   int
+
+	mu     sync.Mutex
+	models []string
+}
+
+func (e *chainExecutor) Identifier() string { return e.provider }
+
+func (e *chainExecutor) record(model string) error {
+	e.mu.Lock()
+	e.models = append(e.models, model)
+	e.mu.Unlock()
+	if e.status == 0 {
+		return nil
+	}
+	return &coreauth.Error{Code: "upstream", Message: http.StatusText(e.status), HTTPStatus: e.status}
+}
+
+func (e *chainExecutor) Execute(_ context.Context, _ *coreauth.Auth, req coreexecutor.Request, _ coreexecutor.Options) (coreexecutor.Response, error) {
+	if err := e.record(req.Model); err != nil {
+		return coreexecutor.Response{}, err
+	}
+	return coreexecutor.Response{Payload: []byte(req.Model)}, nil
+}
+
+func (e *chainExecutor) ExecuteStream(_ context.Context, _ *coreauth.Auth, req coreexecutor.Request, _ coreexecutor.Options) (<-chan coreexecutor.StreamChunk, error) {
+	ch := make(chan coreexecutor.StreamChunk, 2)
+	if err := e.record(req.Model); err != nil {
+		ch <- coreexecutor.StreamChunk{Err: err}
+	} else {
+		ch <- coreexecutor.StreamChunk{Payload: []byte(req.Model)}
+		ch <- coreexecutor.StreamChunk{Payload: []byte("!")}
+	}
+	close(ch)
+	return ch, nil
+}
+
+func (e *chainExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (e *chainExecutor) CountTokens(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, &coreauth.Error{Code: "not_implemented", Message: "CountTokens not implemented"}
+}
+
+func (e *chainExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, &coreauth.Error{Code: "not_implemented", Message: "HttpRequest not implemented", HTTPStatus: http.StatusNotImplemented}
+}
+
+func (e *chainExecutor) calls() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.models...)
+}
+
+func newChainHandler(t *testing.T, executors ...*chainExecutor) *BaseAPIHandler {
+	t.Helper()
+	manager := coreauth.NewManager(nil, nil, nil)
+	for _, executor := range executors {
+		manager.RegisterExecutor(executor)
+		auth := &coreauth.Auth{ID: executor.provider + "-auth", Provider: executor.provider, Status: coreauth.StatusActive}
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("manager.Register(%s): %v", auth.ID, err)
+		}
+		registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: executor.provider + "-model"}})
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+	}
+	return NewBaseAPIHandlers(&sdkconfig.SDKConfig{
+		FallbackChains: []sdkconfig.FallbackChain{{
+			Alias:  "smart",
+			Models: []string{"chain-quota-model", "chain-unserved-model", "chain-bad-model", "chain-ok-model"},
+		}},
+	}, manager)
+}
+
+func TestFallbackChainSkipsQuotaErrors(t *testing.T) {
+	quota := &chainExecutor{provider: "chain-quota", status: http.StatusTooManyRequests}
+	ok := &chainExecutor{provider: "chain-ok"}
+	handler := newChainHandler(t, quota, ok)
+
+	resp, errMsg := handler.ExecuteWithAuthManager(context.Background(), "openai", "smart", []byte(`{"model":"smart"}`), "")
+	if errMsg != nil {
+		t.Fatalf("unexpected error: %+v", errMsg)
+	}
+	if string(resp) != "chain-ok-model" {
+		t.Fatalf("response = %q, want the last model of the chain", resp)
+	}
+	if calls := quota.calls(); len(calls) == 0 || calls[0] != "chain-quota-model" {
+		t.Fatalf("first model was not tried: %v", calls)
+	}
+
+	dataChan, errChan := handler.ExecuteStreamWithAuthManager(context.Background(), "openai", "smart", []byte(`{"model":"smart"}`), "")
+	var got []byte
+	for chunk := range dataChan {
+		got = append(got, chunk...)
+	}
+	for msg := range errChan {
+		if msg != nil {
+			t.Fatalf("unexpected stream error: %+v", msg)
+		}
+	}
+	if string(got) != "chain-ok-model!" {
+		t.Fatalf("stream = %q, want every chunk of the fallback model", got)
+	}
+}
+
+func TestFallbackChainStopsOnClientErrors(t *testing.T) {
+	quota := &chainExecutor{provider: "chain-quota", status: http.StatusTooManyRequests}
+	bad := &chainExecutor{provider: "chain-bad", status: http.StatusBadRequest}
+	ok := &chainExecutor{provider: "chain-ok"}
+	handler := newChainHandler(t, quota, bad, ok)
+
+	_, errMsg := handler.ExecuteWithAuthManager(context.Background(), "openai", "smart", []byte(`{"model":"smart"}`), "")
+	if errMsg == nil || errMsg.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected the 400 of the second model, got %+v", errMsg)
+	}
+	if calls := ok.calls(); len(calls) != 0 {
+		t.Fatalf("chain must stop at a client error, later model got %v", calls)
+	}
+}
+
+func TestFallbackChainIgnoresOtherModels(t *testing.T) {
+	cfg := &sdkconfig.SDKConfig{FallbackChains: []sdkconfig.FallbackChain{{Alias: "smart", Models: []string{"a", " ", "b"}}}}
+	if chain := fallbackChain(cfg, "gpt-4o"); chain != nil {
+		t.Fatalf("non-alias model got chain %v", chain)
+	}
+	if chain := fallbackChain(cfg, " Smart "); len(chain) != 2 || chain[0] != "a" || chain[1] != "b" {
+		t.Fatalf("unserved chain = %v, want every configured model", chain)
+	}
+}
+
+func TestFallbackChainCapturesOnlyFinalFailure(t *testing.T) {
+	queue := replay.Default()
+	queue.Configure(config.ReplayQueueConfig{APIKeys: []string{"replay-key"}}, t.TempDir())
+	t.Cleanup(func() { queue.Configure(config.ReplayQueueConfig{}, "") })
+
+	down := &chainExecutor{provider: "chain-quota", status: http.StatusServiceUnavailable}
+	ok := &chainExecutor{provider: "chain-ok"}
+	handler := newChainHandler(t, down, ok)
+
+	gin.SetMode(gin.TestMode)
+	ginCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ginCtx.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	ginCtx.Set("apiKey", "replay-key")
+	ctx := context.WithValue(context.Background(), "gin", ginCtx)
+
+	if _, errMsg := handler.ExecuteWithAuthManager(ctx, "openai", "smart", []byte(`{"model":"smart"}`), ""); errMsg != nil {
+		t.Fatalf("unexpected error: %+v", errMsg)
+	}
+	entries, err := queue.List()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("a failure the chain recovered from must not be captured, got %+v", entries)
+	}
+}
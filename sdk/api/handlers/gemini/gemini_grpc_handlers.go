@@ -0,0 +1,310 @@
+package gemini
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+)
+
+// GRPCServiceNames lists the fully-qualified GenerativeService names served by GRPCHandler.
+var GRPCServiceNames = []string{
+	"google.ai.generativelanguage.v1beta.GenerativeService",
+	"google.ai.generativelanguage.v1.GenerativeService",
+}
+
+// gRPC status codes used by this handler (see google.golang.org/grpc/codes).
+const (
+	grpcCodeOK                = 0
+	grpcCodeCanceled          = 1
+	grpcCodeUnknown           = 2
+	grpcCodeInvalidArgument   = 3
+	grpcCodeDeadlineExceeded  = 4
+	grpcCodeNotFound          = 5
+	grpcCodePermissionDenied  = 7
+	grpcCodeResourceExhausted = 8
+	grpcCodeUnimplemented     = 12
+	grpcCodeInternal          = 13
+	grpcCodeUnavailable       = 14
+	grpcCodeUnauthenticated   = 16
+)
+
+// grpcMaxMessageSize bounds a single request message, matching grpc-go's default receive limit.
+const grpcMaxMessageSize = 4 << 20
+
+// grpcCodec converts between gRPC message payloads and the REST JSON used by the pipeline.
+type grpcCodec struct {
+	contentType string
+	decode      func([]byte) (string, []byte, error)
+	encode      func([]byte) ([]byte, error)
+}
+
+var (
+	grpcProtoCodec = grpcCodec{contentType: "application/grpc", decode: grpcRequestToJSON, encode: grpcResponseFromJSON}
+	grpcJSONCodec  = grpcCodec{contentType: "application/grpc+json", decode: grpcJSONToRequest, encode: func(b []byte) ([]byte, error) { return b, nil }}
+)
+
+// GRPCHandler serves GenerativeService.GenerateContent and StreamGenerateContent over gRPC.
+// Requests are transcoded to the REST JSON shape and executed through the same auth manager,
+// routing, and translation pipeline as the HTTP endpoints. Both the protobuf codec and the
+// grpc+json codec are supported.
+func (h *GeminiAPIHandler) GRPCHandler(c *gin.Context) {
+	codec, ok := grpcCodecFor(c.GetHeader("Content-Type"))
+	if !ok {
+		c.AbortWithStatus(http.StatusUnsupportedMediaType)
+		return
+	}
+	c.Header("Content-Type", codec.contentType)
+
+	method := c.Param("method")
+	if method != "GenerateContent" && method != "StreamGenerateContent" {
+		h.writeGRPCStatus(c, grpcCodeUnimplemented, fmt.Sprintf("method %s is not implemented", method))
+		return
+	}
+
+	payload, err := readGRPCMessage(c.Request.Body, c.GetHeader("Grpc-Encoding"))
+	if err != nil {
+		h.writeGRPCStatus(c, grpcCodeInvalidArgument, err.Error())
+		return
+	}
+	modelName, rawJSON, err := codec.decode(payload)
+	if err != nil {
+		h.writeGRPCStatus(c, grpcCodeInvalidArgument, err.Error())
+		return
+	}
+	if modelName == "" {
+		h.writeGRPCStatus(c, grpcCodeInvalidArgument, "model is required")
+		return
+	}
+
+	parentCtx := context.Background()
+	if timeout, okTimeout := parseGRPCTimeout(c.GetHeader("Grpc-Timeout")); okTimeout {
+		var cancelTimeout context.CancelFunc
+		parentCtx, cancelTimeout = context.WithTimeout(parentCtx, timeout)
+		defer cancelTimeout()
+	}
+	cliCtx, cliCancel := h.GetContextWithCancel(h, c, parentCtx)
+
+	if method == "GenerateContent" {
+		resp, errMsg := h.ExecuteWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON, "")
+		if errMsg != nil {
+			h.writeGRPCError(c, errMsg)
+			cliCancel(errMsg.Error)
+			return
+		}
+		if errWrite := writeGRPCMessage(c.Writer, codec, resp); errWrite != nil {
+			h.writeGRPCStatus(c, grpcCodeInternal, errWrite.Error())
+			cliCancel(errWrite)
+			return
+		}
+		h.writeGRPCStatus(c, grpcCodeOK, "")
+		cliCancel(resp)
+		return
+	}
+
+	flusher, _ := c.Writer.(http.Flusher)
+	dataChan, errChan := h.ExecuteStreamWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON, "")
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			cliCancel(c.Request.Context().Err())
+			return
+		case errMsg, okErr := <-errChan:
+			if !okErr {
+				errChan = nil
+				continue
+			}
+			h.writeGRPCError(c, errMsg)
+			if errMsg != nil {
+				cliCancel(errMsg.Error)
+			} else {
+				cliCancel(nil)
+			}
+			return
+		case chunk, okData := <-dataChan:
+			if !okData {
+				h.writeGRPCStatus(c, grpcCodeOK, "")
+				cliCancel(nil)
+				return
+			}
+			if errWrite := writeGRPCMessage(c.Writer, codec, chunk); errWrite != nil {
+				h.writeGRPCStatus(c, grpcCodeInternal, errWrite.Error())
+				cliCancel(errWrite)
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+func grpcCodecFor(contentType string) (grpcCodec, bool) {
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	if idx := strings.Index(contentType, ";"); idx >= 0 {
+		contentType = strings.TrimSpace(contentType[:idx])
+	}
+	switch contentType {
+	case "application/grpc", "application/grpc+proto":
+		return grpcProtoCodec, true
+	case "application/grpc+json":
+		return grpcJSONCodec, true
+	default:
+		return grpcCodec{}, false
+	}
+}
+
+// readGRPCMessage reads the single length-prefixed message of a unary or server-streaming call.
+func readGRPCMessage(body io.Reader, encoding string) ([]byte, error) {
+	if body == nil {
+		return nil, errors.New("missing request message")
+	}
+	var header [5]byte
+	if _, err := io.ReadFull(body, header[:]); err != nil {
+		return nil, fmt.Errorf("failed to read request message: %w", err)
+	}
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > grpcMaxMessageSize {
+		return nil, fmt.Errorf("request message too large: %d bytes", size)
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(body, payload); err != nil {
+		return nil, fmt.Errorf("failed to read request message: %w", err)
+	}
+	if header[0] == 0 {
+		return payload, nil
+	}
+	if !strings.EqualFold(strings.TrimSpace(encoding), "gzip") {
+		return nil, fmt.Errorf("unsupported grpc-encoding %q", encoding)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress request message: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+	decompressed, err := io.ReadAll(io.LimitReader(reader, grpcMaxMessageSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress request message: %w", err)
+	}
+	if len(decompressed) > grpcMaxMessageSize {
+		return nil, fmt.Errorf("request message too large")
+	}
+	return decompressed, nil
+}
+
+// writeGRPCMessage encodes a JSON response chunk with the codec and writes it as an uncompressed frame.
+func writeGRPCMessage(w io.Writer, codec grpcCodec, jsonChunk []byte) error {
+	jsonChunk = bytes.TrimSpace(jsonChunk)
+	if len(jsonChunk) == 0 {
+		return nil
+	}
+	payload, err := codec.encode(jsonChunk)
+	if err != nil {
+		return err
+	}
+	frame := make([]byte, 5+len(payload))
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(payload)))
+	copy(frame[5:], payload)
+	_, err = w.Write(frame)
+	return err
+}
+
+func (h *GeminiAPIHandler) writeGRPCError(c *gin.Context, errMsg *interfaces.ErrorMessage) {
+	status := http.StatusInternalServerError
+	var err error
+	if errMsg != nil {
+		if errMsg.StatusCode > 0 {
+			status = errMsg.StatusCode
+		}
+		err = errMsg.Error
+	}
+	h.writeGRPCStatus(c, grpcCodeFromHTTPStatus(status), handlers.ErrorText(c, status, err))
+}
+
+// writeGRPCStatus finishes the call by emitting grpc-status and grpc-message trailers.
+func (h *GeminiAPIHandler) writeGRPCStatus(c *gin.Context, code int, message string) {
+	if !c.Writer.Written() {
+		c.Status(http.StatusOK)
+		c.Writer.WriteHeaderNow()
+	}
+	c.Writer.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(code))
+	if message != "" {
+		c.Writer.Header().Set(http.TrailerPrefix+"Grpc-Message", encodeGRPCMessage(message))
+	}
+}
+
+// encodeGRPCMessage percent-encodes the status message as required by the gRPC HTTP/2 protocol.
+func encodeGRPCMessage(message string) string {
+	return strings.ReplaceAll(url.PathEscape(message), "+", "%2B")
+}
+
+func grpcCodeFromHTTPStatus(status int) int {
+	switch status {
+	case http.StatusOK:
+		return grpcCodeOK
+	case http.StatusBadRequest:
+		return grpcCodeInvalidArgument
+	case http.StatusUnauthorized:
+		return grpcCodeUnauthenticated
+	case http.StatusForbidden:
+		return grpcCodePermissionDenied
+	case http.StatusNotFound:
+		return grpcCodeNotFound
+	case http.StatusTooManyRequests:
+		return grpcCodeResourceExhausted
+	case 499:
+		return grpcCodeCanceled
+	case http.StatusNotImplemented:
+		return grpcCodeUnimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return grpcCodeUnavailable
+	case http.StatusGatewayTimeout, http.StatusRequestTimeout:
+		return grpcCodeDeadlineExceeded
+	}
+	if status >= 500 {
+		return grpcCodeInternal
+	}
+	return grpcCodeUnknown
+}
+
+// parseGRPCTimeout parses the grpc-timeout header, e.g. "30S" or "1500m".
+func parseGRPCTimeout(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if len(value) < 2 {
+		return 0, false
+	}
+	amount, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+	if err != nil || amount <= 0 {
+		return 0, false
+	}
+	var unit time.Duration
+	switch value[len(value)-1] {
+	case 'H':
+		unit = time.Hour
+	case 'M':
+		unit = time.Minute
+	case 'S':
+		unit = time.Second
+	case 'm':
+		unit = time.Millisecond
+	case 'u':
+		unit = time.Microsecond
+	case 'n':
+		unit = time.Nanosecond
+	default:
+		return 0, false
+	}
+	return time.Duration(amount) * unit, true
+}
@@ -0,0 +1,342 @@
+package gemini
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// grpcProtoPackage mirrors the package of Google's generative language v1beta protos. Only the
+// messages needed by GenerateContent and StreamGenerateContent are described; fields outside this
+// subset are dropped when a binary request is converted to JSON.
+const grpcProtoPackage = "google.ai.generativelanguage.v1beta"
+
+var (
+	grpcSchemaOnce sync.Once
+	grpcSchemaErr  error
+	grpcRequestMsg protoreflect.MessageDescriptor
+	grpcRespMsg    protoreflect.MessageDescriptor
+)
+
+// grpcSchema lazily builds the dynamic descriptors used to transcode protobuf payloads.
+func grpcSchema() (request, response protoreflect.MessageDescriptor, err error) {
+	grpcSchemaOnce.Do(func() {
+		files := new(protoregistry.Files)
+		if errRegister := files.RegisterFile(structpb.File_google_protobuf_struct_proto); errRegister != nil {
+			grpcSchemaErr = errRegister
+			return
+		}
+		fd, errBuild := protodesc.NewFile(grpcFileDescriptor(), files)
+		if errBuild != nil {
+			grpcSchemaErr = fmt.Errorf("build gemini grpc schema: %w", errBuild)
+			return
+		}
+		grpcRequestMsg = fd.Messages().ByName("GenerateContentRequest")
+		grpcRespMsg = fd.Messages().ByName("GenerateContentResponse")
+	})
+	return grpcRequestMsg, grpcRespMsg, grpcSchemaErr
+}
+
+// grpcRequestToJSON decodes a binary GenerateContentRequest into the REST JSON body understood by the
+// translation pipeline and returns the model name without its "models/" prefix.
+func grpcRequestToJSON(payload []byte) (string, []byte, error) {
+	reqDesc, _, err := grpcSchema()
+	if err != nil {
+		return "", nil, err
+	}
+	msg := dynamicpb.NewMessage(reqDesc)
+	if err = proto.Unmarshal(payload, msg); err != nil {
+		return "", nil, fmt.Errorf("invalid GenerateContentRequest: %w", err)
+	}
+	modelField := reqDesc.Fields().ByName("model")
+	model := strings.TrimPrefix(msg.Get(modelField).String(), "models/")
+	msg.Clear(modelField)
+	body, err := protojson.Marshal(msg)
+	if err != nil {
+		return "", nil, err
+	}
+	return model, body, nil
+}
+
+// grpcJSONToRequest extracts the model from a JSON-encoded request sent with the grpc+json codec.
+func grpcJSONToRequest(payload []byte) (string, []byte, error) {
+	reqDesc, _, err := grpcSchema()
+	if err != nil {
+		return "", nil, err
+	}
+	msg := dynamicpb.NewMessage(reqDesc)
+	if err = (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(payload, msg); err != nil {
+		return "", nil, fmt.Errorf("invalid GenerateContentRequest: %w", err)
+	}
+	return strings.TrimPrefix(msg.Get(reqDesc.Fields().ByName("model")).String(), "models/"), payload, nil
+}
+
+// grpcResponseFromJSON encodes a REST JSON GenerateContentResponse as protobuf.
+// Unknown fields and enum values are discarded so newer upstream responses still transcode.
+func grpcResponseFromJSON(body []byte) ([]byte, error) {
+	_, respDesc, err := grpcSchema()
+	if err != nil {
+		return nil, err
+	}
+	msg := dynamicpb.NewMessage(respDesc)
+	if err = (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, msg); err != nil {
+		return nil, fmt.Errorf("invalid GenerateContentResponse: %w", err)
+	}
+	return proto.Marshal(msg)
+}
+
+func grpcFileDescriptor() *descriptorpb.FileDescriptorProto {
+	return &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("cliproxy/gemini_grpc.proto"),
+		Package: proto.String(grpcProtoPackage),
+		// proto2 keeps explicit presence for scalars such as temperature=0; the wire format is
+		// identical to the proto3 definitions Google publishes.
+		Syntax:     proto.String("proto2"),
+		Dependency: []string{"google/protobuf/struct.proto"},
+		EnumType: []*descriptorpb.EnumDescriptorProto{
+			pbEnum("HarmCategory", "HARM_CATEGORY_UNSPECIFIED", "HARM_CATEGORY_DEROGATORY", "HARM_CATEGORY_TOXICITY",
+				"HARM_CATEGORY_VIOLENCE", "HARM_CATEGORY_SEXUAL", "HARM_CATEGORY_MEDICAL", "HARM_CATEGORY_DANGEROUS",
+				"HARM_CATEGORY_HARASSMENT", "HARM_CATEGORY_HATE_SPEECH", "HARM_CATEGORY_SEXUALLY_EXPLICIT",
+				"HARM_CATEGORY_DANGEROUS_CONTENT", "HARM_CATEGORY_CIVIC_INTEGRITY"),
+			pbEnum("Type", "TYPE_UNSPECIFIED", "STRING", "NUMBER", "INTEGER", "BOOLEAN", "ARRAY", "OBJECT", "NULL"),
+		},
+		MessageType: []*descriptorpb.DescriptorProto{
+			pbMessage("GenerateContentRequest",
+				pbField("model", 1, pbString),
+				pbRepeated(pbField("contents", 2, pbMsg("Content"))),
+				pbRepeated(pbField("safety_settings", 3, pbMsg("SafetySetting"))),
+				pbField("generation_config", 4, pbMsg("GenerationConfig")),
+				pbRepeated(pbField("tools", 5, pbMsg("Tool"))),
+				pbField("tool_config", 7, pbMsg("ToolConfig")),
+				pbField("system_instruction", 8, pbMsg("Content")),
+				pbField("cached_content", 9, pbString),
+			),
+			pbMessage("Content",
+				pbRepeated(pbField("parts", 1, pbMsg("Part"))),
+				pbField("role", 2, pbString),
+			),
+			pbMessage("Part",
+				pbField("text", 2, pbString),
+				pbField("inline_data", 3, pbMsg("Blob")),
+				pbField("function_call", 4, pbMsg("FunctionCall")),
+				pbField("function_response", 5, pbMsg("FunctionResponse")),
+				pbField("file_data", 6, pbMsg("FileData")),
+				pbField("executable_code", 9, pbMsg("ExecutableCode")),
+				pbField("code_execution_result", 10, pbMsg("CodeExecutionResult")),
+				pbField("thought", 11, pbBool),
+				pbField("thought_signature", 13, pbBytes),
+			),
+			pbMessage("Blob", pbField("mime_type", 1, pbString), pbField("data", 2, pbBytes)),
+			pbMessage("FileData", pbField("mime_type", 1, pbString), pbField("file_uri", 2, pbString)),
+			pbMessage("FunctionCall",
+				pbField("name", 1, pbString),
+				pbField("args", 2, pbMsg(".google.protobuf.Struct")),
+				pbField("id", 3, pbString),
+			),
+			pbMessage("FunctionResponse",
+				pbField("id", 1, pbString),
+				pbField("name", 2, pbString),
+				pbField("response", 3, pbMsg(".google.protobuf.Struct")),
+			),
+			pbMessageWithEnums("ExecutableCode", []*descriptorpb.EnumDescriptorProto{pbEnum("Language", "LANGUAGE_UNSPECIFIED", "PYTHON")},
+				pbField("language", 1, pbEnumRef("ExecutableCode.Language")),
+				pbField("code", 2, pbString),
+			),
+			pbMessageWithEnums("CodeExecutionResult", []*descriptorpb.EnumDescriptorProto{
+				pbEnum("Outcome", "OUTCOME_UNSPECIFIED", "OUTCOME_OK", "OUTCOME_FAILED", "OUTCOME_DEADLINE_EXCEEDED"),
+			},
+				pbField("outcome", 1, pbEnumRef("CodeExecutionResult.Outcome")),
+				pbField("output", 2, pbString),
+			),
+			pbMessageWithEnums("SafetySetting", []*descriptorpb.EnumDescriptorProto{
+				pbEnum("HarmBlockThreshold", "HARM_BLOCK_THRESHOLD_UNSPECIFIED", "BLOCK_LOW_AND_ABOVE",
+					"BLOCK_MEDIUM_AND_ABOVE", "BLOCK_ONLY_HIGH", "BLOCK_NONE", "OFF"),
+			},
+				pbField("category", 3, pbEnumRef("HarmCategory")),
+				pbField("threshold", 4, pbEnumRef("SafetySetting.HarmBlockThreshold")),
+			),
+			pbMessage("GenerationConfig",
+				pbField("candidate_count", 1, pbInt32),
+				pbRepeated(pbField("stop_sequences", 2, pbString)),
+				pbField("max_output_tokens", 4, pbInt32),
+				pbField("temperature", 5, pbFloat),
+				pbField("top_p", 6, pbFloat),
+				pbField("top_k", 7, pbInt32),
+				pbField("seed", 8, pbInt32),
+				pbField("response_mime_type", 13, pbString),
+				pbField("response_schema", 14, pbMsg("Schema")),
+				pbField("presence_penalty", 15, pbFloat),
+				pbField("frequency_penalty", 16, pbFloat),
+				pbField("response_logprobs", 17, pbBool),
+				pbField("logprobs", 18, pbInt32),
+				pbField("thinking_config", 22, pbMsg("ThinkingConfig")),
+			),
+			pbMessage("ThinkingConfig",
+				pbField("include_thoughts", 1, pbBool),
+				pbField("thinking_budget", 2, pbInt32),
+			),
+			pbMessage("Tool",
+				pbRepeated(pbField("function_declarations", 1, pbMsg("FunctionDeclaration"))),
+				pbField("code_execution", 3, pbMsg("CodeExecution")),
+				pbField("google_search", 4, pbMsg("GoogleSearch")),
+			),
+			pbMessage("CodeExecution"),
+			pbMessage("GoogleSearch"),
+			pbMessage("FunctionDeclaration",
+				pbField("name", 1, pbString),
+				pbField("description", 2, pbString),
+				pbField("parameters", 3, pbMsg("Schema")),
+				pbField("response", 4, pbMsg("Schema")),
+			),
+			pbSchemaMessage(),
+			pbMessageWithEnums("FunctionCallingConfig", []*descriptorpb.EnumDescriptorProto{
+				pbEnum("Mode", "MODE_UNSPECIFIED", "AUTO", "ANY", "NONE", "VALIDATED"),
+			},
+				pbField("mode", 1, pbEnumRef("FunctionCallingConfig.Mode")),
+				pbRepeated(pbField("allowed_function_names", 2, pbString)),
+			),
+			pbMessage("ToolConfig", pbField("function_calling_config", 1, pbMsg("FunctionCallingConfig"))),
+			pbMessage("GenerateContentResponse",
+				pbRepeated(pbField("candidates", 1, pbMsg("Candidate"))),
+				pbField("prompt_feedback", 2, pbMsg("PromptFeedback")),
+				pbField("usage_metadata", 3, pbMsg("UsageMetadata")),
+				pbField("model_version", 4, pbString),
+			),
+			pbMessageWithEnums("Candidate", []*descriptorpb.EnumDescriptorProto{
+				pbEnum("FinishReason", "FINISH_REASON_UNSPECIFIED", "STOP", "MAX_TOKENS", "SAFETY", "RECITATION",
+					"OTHER", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII", "MALFORMED_FUNCTION_CALL"),
+			},
+				pbField("content", 1, pbMsg("Content")),
+				pbField("finish_reason", 2, pbEnumRef("Candidate.FinishReason")),
+				pbField("index", 3, pbInt32),
+				pbRepeated(pbField("safety_ratings", 5, pbMsg("SafetyRating"))),
+				pbField("token_count", 7, pbInt32),
+				pbField("avg_logprobs", 10, pbDouble),
+			),
+			pbMessageWithEnums("SafetyRating", []*descriptorpb.EnumDescriptorProto{
+				pbEnum("HarmProbability", "HARM_PROBABILITY_UNSPECIFIED", "NEGLIGIBLE", "LOW", "MEDIUM", "HIGH"),
+			},
+				pbField("category", 3, pbEnumRef("HarmCategory")),
+				pbField("probability", 4, pbEnumRef("SafetyRating.HarmProbability")),
+				pbField("blocked", 5, pbBool),
+			),
+			pbMessageWithEnums("PromptFeedback", []*descriptorpb.EnumDescriptorProto{
+				pbEnum("BlockReason", "BLOCK_REASON_UNSPECIFIED", "SAFETY", "OTHER", "BLOCKLIST", "PROHIBITED_CONTENT"),
+			},
+				pbField("block_reason", 1, pbEnumRef("PromptFeedback.BlockReason")),
+				pbRepeated(pbField("safety_ratings", 2, pbMsg("SafetyRating"))),
+			),
+			pbMessage("UsageMetadata",
+				pbField("prompt_token_count", 1, pbInt32),
+				pbField("candidates_token_count", 2, pbInt32),
+				pbField("total_token_count", 3, pbInt32),
+				pbField("cached_content_token_count", 4, pbInt32),
+				pbField("tool_use_prompt_token_count", 8, pbInt32),
+				pbField("thoughts_token_count", 10, pbInt32),
+			),
+		},
+	}
+}
+
+// pbSchemaMessage describes the OpenAPI-style Schema message, including its properties map entry.
+func pbSchemaMessage() *descriptorpb.DescriptorProto {
+	schema := pbMessage("Schema",
+		pbField("type", 1, pbEnumRef("Type")),
+		pbField("format", 2, pbString),
+		pbField("description", 3, pbString),
+		pbField("nullable", 4, pbBool),
+		pbRepeated(pbField("enum", 5, pbString)),
+		pbField("items", 6, pbMsg("Schema")),
+		pbRepeated(pbField("properties", 7, pbMsg("Schema.PropertiesEntry"))),
+		pbRepeated(pbField("required", 8, pbString)),
+		pbRepeated(pbField("any_of", 18, pbMsg("Schema"))),
+		pbField("max_items", 21, pbInt64),
+		pbField("min_items", 22, pbInt64),
+		pbRepeated(pbField("property_ordering", 23, pbString)),
+		pbField("title", 24, pbString),
+	)
+	entry := pbMessage("PropertiesEntry", pbField("key", 1, pbString), pbField("value", 2, pbMsg("Schema")))
+	entry.Options = &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)}
+	schema.NestedType = []*descriptorpb.DescriptorProto{entry}
+	return schema
+}
+
+type pbFieldType struct {
+	kind     descriptorpb.FieldDescriptorProto_Type
+	typeName string
+}
+
+var (
+	pbString = pbFieldType{kind: descriptorpb.FieldDescriptorProto_TYPE_STRING}
+	pbBytes  = pbFieldType{kind: descriptorpb.FieldDescriptorProto_TYPE_BYTES}
+	pbBool   = pbFieldType{kind: descriptorpb.FieldDescriptorProto_TYPE_BOOL}
+	pbInt32  = pbFieldType{kind: descriptorpb.FieldDescriptorProto_TYPE_INT32}
+	pbInt64  = pbFieldType{kind: descriptorpb.FieldDescriptorProto_TYPE_INT64}
+	pbFloat  = pbFieldType{kind: descriptorpb.FieldDescriptorProto_TYPE_FLOAT}
+	pbDouble = pbFieldType{kind: descriptorpb.FieldDescriptorProto_TYPE_DOUBLE}
+)
+
+// pbMsg references a message type; relative names resolve inside grpcProtoPackage.
+func pbMsg(name string) pbFieldType {
+	return pbFieldType{kind: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, typeName: pbQualify(name)}
+}
+
+func pbEnumRef(name string) pbFieldType {
+	return pbFieldType{kind: descriptorpb.FieldDescriptorProto_TYPE_ENUM, typeName: pbQualify(name)}
+}
+
+func pbQualify(name string) string {
+	if strings.HasPrefix(name, ".") {
+		return name
+	}
+	return "." + grpcProtoPackage + "." + name
+}
+
+func pbField(name string, number int32, typ pbFieldType) *descriptorpb.FieldDescriptorProto {
+	field := &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(number),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:   typ.kind.Enum(),
+	}
+	if typ.typeName != "" {
+		field.TypeName = proto.String(typ.typeName)
+	}
+	return field
+}
+
+func pbRepeated(field *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldDescriptorProto {
+	field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	return field
+}
+
+func pbMessage(name string, fields ...*descriptorpb.FieldDescriptorProto) *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{Name: proto.String(name), Field: fields}
+}
+
+func pbMessageWithEnums(name string, enums []*descriptorpb.EnumDescriptorProto, fields ...*descriptorpb.FieldDescriptorProto) *descriptorpb.DescriptorProto {
+	msg := pbMessage(name, fields...)
+	msg.EnumType = enums
+	return msg
+}
+
+// pbEnum declares an enum whose values are numbered in declaration order starting at zero.
+func pbEnum(name string, values ...string) *descriptorpb.EnumDescriptorProto {
+	enum := &descriptorpb.EnumDescriptorProto{Name: proto.String(name)}
+	for i, value := range values {
+		enum.Value = append(enum.Value, &descriptorpb.EnumValueDescriptorProto{
+			Name:   proto.String(value),
+			Number: proto.Int32(int32(i)),
+		})
+	}
+	return enum
+}
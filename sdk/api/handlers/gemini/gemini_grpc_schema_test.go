@@ -0,0 +1,98 @@
+package gemini
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/tidwall/gjson"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestGRPCRequestToJSON(t *testing.T) {
+	reqDesc, _, err := grpcSchema()
+	if err != nil {
+		t.Fatalf("grpcSchema: %v", err)
+	}
+	msg := dynamicpb.NewMessage(reqDesc)
+	input := `{"model":"models/gemini-2.5-pro","contents":[{"role":"user","parts":[{"text":"hi"}]}],` +
+		`"generationConfig":{"temperature":0,"thinkingConfig":{"thinkingBudget":128}},` +
+		`"tools":[{"functionDeclarations":[{"name":"lookup","parameters":{"type":"OBJECT","properties":{"q":{"type":"STRING"}}}}]}]}`
+	if err = protojson.Unmarshal([]byte(input), msg); err != nil {
+		t.Fatalf("protojson.Unmarshal: %v", err)
+	}
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatalf("proto.Marshal: %v", err)
+	}
+
+	model, body, err := grpcRequestToJSON(payload)
+	if err != nil {
+		t.Fatalf("grpcRequestToJSON: %v", err)
+	}
+	if model != "gemini-2.5-pro" {
+		t.Fatalf("model = %q, want %q", model, "gemini-2.5-pro")
+	}
+	result := gjson.ParseBytes(body)
+	if result.Get("model").Exists() {
+		t.Fatalf("model should be removed from the JSON body: %s", body)
+	}
+	if got := result.Get("contents.0.parts.0.text").String(); got != "hi" {
+		t.Fatalf("text = %q, want %q; body=%s", got, "hi", body)
+	}
+	if !result.Get("generationConfig.temperature").Exists() {
+		t.Fatalf("explicit zero temperature was dropped: %s", body)
+	}
+	if got := result.Get("generationConfig.thinkingConfig.thinkingBudget").Int(); got != 128 {
+		t.Fatalf("thinkingBudget = %d, want 128", got)
+	}
+	if got := result.Get("tools.0.functionDeclarations.0.parameters.properties.q.type").String(); got != "STRING" {
+		t.Fatalf("schema type = %q, want STRING; body=%s", got, body)
+	}
+}
+
+func TestGRPCResponseFromJSON(t *testing.T) {
+	chunk := []byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"hello","thoughtSignature":"c2ln"}]},` +
+		`"finishReason":"STOP","futureField":1}],"usageMetadata":{"promptTokenCount":3,"totalTokenCount":5},"modelVersion":"gemini-2.5-pro"}`)
+	var buf bytes.Buffer
+	if err := writeGRPCMessage(&buf, grpcProtoCodec, chunk); err != nil {
+		t.Fatalf("writeGRPCMessage: %v", err)
+	}
+	frame := buf.Bytes()
+	if frame[0] != 0 || int(binary.BigEndian.Uint32(frame[1:5])) != len(frame)-5 {
+		t.Fatalf("malformed frame header: %v", frame[:5])
+	}
+
+	_, respDesc, _ := grpcSchema()
+	msg := dynamicpb.NewMessage(respDesc)
+	if err := proto.Unmarshal(frame[5:], msg); err != nil {
+		t.Fatalf("proto.Unmarshal: %v", err)
+	}
+	decoded, err := protojson.Marshal(msg)
+	if err != nil {
+		t.Fatalf("protojson.Marshal: %v", err)
+	}
+	result := gjson.ParseBytes(decoded)
+	if got := result.Get("candidates.0.content.parts.0.text").String(); got != "hello" {
+		t.Fatalf("text = %q; body=%s", got, decoded)
+	}
+	if got := result.Get("candidates.0.content.parts.0.thoughtSignature").String(); got != "c2ln" {
+		t.Fatalf("thoughtSignature = %q; body=%s", got, decoded)
+	}
+	if got := result.Get("candidates.0.finishReason").String(); got != "STOP" {
+		t.Fatalf("finishReason = %q; body=%s", got, decoded)
+	}
+	if got := result.Get("usageMetadata.totalTokenCount").Int(); got != 5 {
+		t.Fatalf("totalTokenCount = %d; body=%s", got, decoded)
+	}
+}
+
+func TestReadGRPCMessageRejectsOversizedFrames(t *testing.T) {
+	header := []byte{0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(header[1:], grpcMaxMessageSize+1)
+	if _, err := readGRPCMessage(bytes.NewReader(header), ""); err == nil {
+		t.Fatal("expected oversized frame to be rejected")
+	}
+}
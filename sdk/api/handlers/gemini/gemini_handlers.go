@@ -241,12 +241,7 @@ func (h *GeminiAPIHandler) handleStreamGenerateContent(c *gin.Context, modelName
 	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
 	dataChan, errChan := h.ExecuteStreamWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON, alt)
 
-	setSSEHeaders := func() {
-		c.Header("Content-Type", "text/event-stream")
-		c.Header("Cache-Control", "no-cache")
-		c.Header("Connection", "keep-alive")
-		c.Header("Access-Control-Allow-Origin", "*")
-	}
+	sse := handlers.NewSSEWriter(c.Writer)
 
 	// Peek at the first chunk
 	for {
@@ -272,7 +267,7 @@ func (h *GeminiAPIHandler) handleStreamGenerateContent(c *gin.Context, modelName
 			if !ok {
 				// Closed without data
 				if alt == "" {
-					setSSEHeaders()
+					handlers.SetSSEHeaders(c)
 				}
 				flusher.Flush()
 				cliCancel(nil)
@@ -281,21 +276,22 @@ func (h *GeminiAPIHandler) handleStreamGenerateContent(c *gin.Context, modelName
 
 			// Success! Set headers.
 			if alt == "" {
-				setSSEHeaders()
+				handlers.SetSSEHeaders(c)
 			}
 
 			// Write first chunk
+			var salvage handlers.StreamSalvager
 			if alt == "" {
-				_, _ = c.Writer.Write([]byte("data: "))
-				_, _ = c.Writer.Write(chunk)
-				_, _ = c.Writer.Write([]byte("\n\n"))
+				sse.Data(chunk)
+				salvage = handlers.NewGeminiSalvager()
+				salvage.Observe(chunk)
 			} else {
 				_, _ = c.Writer.Write(chunk)
 			}
 			flusher.Flush()
 
 			// Continue
-			h.forwardGeminiStream(c, flusher, alt, func(err error) { cliCancel(err) }, dataChan, errChan)
+			h.forwardGeminiStream(c, flusher, alt, func(err error) { cliCancel(err) }, dataChan, errChan, salvage)
 			return
 		}
 	}
@@ -348,20 +344,20 @@ func (h *GeminiAPIHandler) handleGenerateContent(c *gin.Context, modelName strin
 	cliCancel()
 }
 
-func (h *GeminiAPIHandler) forwardGeminiStream(c *gin.Context, flusher http.Flusher, alt string, cancel func(error), data <-chan []byte, errs <-chan *interfaces.ErrorMessage) {
+func (h *GeminiAPIHandler) forwardGeminiStream(c *gin.Context, flusher http.Flusher, alt string, cancel func(error), data <-chan []byte, errs <-chan *interfaces.ErrorMessage, salvage handlers.StreamSalvager) {
 	var keepAliveInterval *time.Duration
 	if alt != "" {
 		disabled := time.Duration(0)
 		keepAliveInterval = &disabled
 	}
 
+	sse := handlers.NewSSEWriter(c.Writer)
 	h.ForwardStream(c, flusher, cancel, data, errs, handlers.StreamForwardOptions{
 		KeepAliveInterval: keepAliveInterval,
+		Salvage:           salvage,
 		WriteChunk: func(chunk []byte) {
 			if alt == "" {
-				_, _ = c.Writer.Write([]byte("data: "))
-				_, _ = c.Writer.Write(chunk)
-				_, _ = c.Writer.Write([]byte("\n\n"))
+				sse.Data(chunk)
 			} else {
 				_, _ = c.Writer.Write(chunk)
 			}
@@ -374,13 +370,9 @@ func (h *GeminiAPIHandler) forwardGeminiStream(c *gin.Context, flusher http.Flus
 			if errMsg.StatusCode > 0 {
 				status = errMsg.StatusCode
 			}
-			errText := http.StatusText(status)
-			if errMsg.Error != nil && errMsg.Error.Error() != "" {
-				errText = errMsg.Error.Error()
-			}
-			body := handlers.BuildErrorResponseBody(status, errText)
+			body := handlers.BuildErrorResponseBody(status, handlers.ErrorText(c, status, errMsg.Error))
 			if alt == "" {
-				_, _ = fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", string(body))
+				sse.NamedEvent("error", body)
 			} else {
 				_, _ = c.Writer.Write(body)
 			}
@@ -8,19 +8,27 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/budget"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/conversation"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/features"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/i18n"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/maintenance"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/modelrename"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
 	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	log "github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
 )
 
@@ -188,6 +196,9 @@ type BaseAPIHandler struct {
 
 	// Cfg holds the current application configuration.
 	Cfg *config.SDKConfig
+
+	// priority admits requests by priority class when max concurrency is configured.
+	priority *priorityGate
 }
 
 // NewBaseAPIHandlers creates a new API handlers instance.
@@ -203,6 +214,15 @@ func NewBaseAPIHandlers(cfg *config.SDKConfig, authManager *coreauth.Manager) *B
 	h := &BaseAPIHandler{
 		Cfg:         cfg,
 		AuthManager: authManager,
+		priority:    newPriorityGate(),
+	}
+	if cfg != nil {
+		h.priority.configure(cfg.Priority)
+		budget.Default().Configure(cfg.Budget)
+		conversation.Default().Configure(cfg.ConversationCap)
+		maintenance.Default().Configure(cfg.MaintenanceWindows)
+		features.Default().Configure(cfg.FeatureFlags)
+		modelrename.Default().Configure(cfg.ModelRenames)
 	}
 	return h
 }
@@ -213,7 +233,86 @@ func NewBaseAPIHandlers(cfg *config.SDKConfig, authManager *coreauth.Manager) *B
 // Parameters:
 //   - clients: The new slice of AI service clients
 //   - cfg: The new application configuration
-func (h *BaseAPIHandler) UpdateClients(cfg *config.SDKConfig) { h.Cfg = cfg }
+func (h *BaseAPIHandler) UpdateClients(cfg *config.SDKConfig) {
+	h.Cfg = cfg
+	if cfg != nil {
+		h.priority.configure(cfg.Priority)
+		budget.Default().Configure(cfg.Budget)
+		conversation.Default().Configure(cfg.ConversationCap)
+		maintenance.Default().Configure(cfg.MaintenanceWindows)
+		features.Default().Configure(cfg.FeatureFlags)
+		modelrename.Default().Configure(cfg.ModelRenames)
+	}
+}
+
+// applyBudget drops providers whose daily capacity is exhausted for the requesting API key.
+// It fails with 429 and a Retry-After until the next reset when no provider remains.
+func applyBudget(ctx context.Context, providers []string) ([]string, *interfaces.ErrorMessage) {
+	tracker := budget.Default()
+	apiKey := requestAPIKey(ctx)
+	allowed := make([]string, 0, len(providers))
+	for _, provider := range providers {
+		if tracker.Allow(apiKey, provider) {
+			allowed = append(allowed, provider)
+		}
+	}
+	if len(allowed) > 0 || len(providers) == 0 {
+		return allowed, nil
+	}
+	addon := http.Header{}
+	addon.Set("Retry-After", strconv.Itoa(int(tracker.ResetIn().Seconds())+1))
+	return nil, &interfaces.ErrorMessage{
+		StatusCode: http.StatusTooManyRequests,
+		Error:      i18n.Errorf(i18n.MsgDailyCapacityExhausted, strings.Join(providers, ", ")),
+		Addon:      addon,
+	}
+}
+
+// applyMaintenance drops providers that are in a maintenance window. It fails with 503 and a
+// Retry-After until the earliest expected resumption when no provider remains.
+func applyMaintenance(providers []string) ([]string, *interfaces.ErrorMessage) {
+	schedule := maintenance.Default()
+	available := make([]string, 0, len(providers))
+	reasons := make([]string, 0, len(providers))
+	var resume time.Time
+	for _, provider := range providers {
+		reason, until, ok := schedule.InMaintenance(provider)
+		if !ok {
+			available = append(available, provider)
+			continue
+		}
+		reasons = append(reasons, fmt.Sprintf("%s: %s", provider, reason))
+		if !until.IsZero() && (resume.IsZero() || until.Before(resume)) {
+			resume = until
+		}
+	}
+	if len(available) > 0 || len(providers) == 0 {
+		return available, nil
+	}
+	var addon http.Header
+	if !resume.IsZero() {
+		addon = http.Header{}
+		addon.Set("Retry-After", strconv.Itoa(int(time.Until(resume).Seconds())+1))
+	}
+	return nil, &interfaces.ErrorMessage{
+		StatusCode: http.StatusServiceUnavailable,
+		Error:      i18n.Errorf(i18n.MsgProviderMaintenance, strings.Join(reasons, "; ")),
+		Addon:      addon,
+	}
+}
+
+// acquireExecutionSlot waits for upstream capacity according to the request's priority class.
+func (h *BaseAPIHandler) acquireExecutionSlot(ctx context.Context) (func(), PriorityClass, *interfaces.ErrorMessage) {
+	class := requestPriorityClass(ctx, h.Cfg)
+	release, err := h.priority.acquire(ctx, class)
+	if err != nil {
+		return nil, class, &interfaces.ErrorMessage{
+			StatusCode: http.StatusServiceUnavailable,
+			Error:      i18n.Errorf(i18n.MsgCapacityWaitCanceled, err),
+		}
+	}
+	return release, class, nil
+}
 
 // GetAlt extracts the 'alt' parameter from the request query string.
 // It checks both 'alt' and '$alt' parameters and returns the appropriate value.
@@ -391,10 +490,42 @@ func appendAPIResponse(c *gin.Context, data []byte) {
 // ExecuteWithAuthManager executes a non-streaming request via the core auth manager.
 // This path is the only supported execution route.
 func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
+	if chain := fallbackChain(h.Cfg, modelName); len(chain) > 0 {
+		return h.executeFallbackChain(ctx, handlerType, chain, rawJSON, alt)
+	}
+	return h.executeWithAuthManager(ctx, handlerType, modelName, rawJSON, alt, true, true)
+}
+
+// executeWithAuthManager executes a non-streaming request for modelName. localFallback lets the
+// local fallback model serve it once every remote credential is exhausted; captureReplay lets a
+// transient failure be captured for the replay queue.
+func (h *BaseAPIHandler) executeWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string, localFallback, captureReplay bool) ([]byte, *interfaces.ErrorMessage) {
+	modelName = applyModelRename(ctx, modelName)
 	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName)
 	if errMsg != nil {
 		return nil, errMsg
 	}
+	if errMsg = applyModelPolicies(ctx, h.Cfg, modelName, normalizedModel, h.AuthManager.ModelAliasTargets(normalizedModel)...); errMsg != nil {
+		return nil, errMsg
+	}
+	if providers, errMsg = applyMaintenance(providers); errMsg != nil {
+		return nil, errMsg
+	}
+	if providers, errMsg = applyBudget(ctx, providers); errMsg != nil {
+		return nil, errMsg
+	}
+	if providers, errMsg = applyCapabilityChecks(h.Cfg, handlerType, modelName, rawJSON, providers); errMsg != nil {
+		return nil, errMsg
+	}
+	if errMsg = applyStrictFields(h.Cfg, handlerType, rawJSON, providers); errMsg != nil {
+		return nil, errMsg
+	}
+	if ctx, rawJSON, errMsg = applyConversationCap(ctx, handlerType, rawJSON); errMsg != nil {
+		return nil, errMsg
+	}
+	rawJSON = applyLoopDetection(ctx, h.Cfg, handlerType, rawJSON)
+	rawJSON = applyImageDedup(h.Cfg, handlerType, rawJSON)
+	rawJSON = applyImageDownscale(h.Cfg, handlerType, providers, rawJSON)
 	reqMeta := requestExecutionMetadata(ctx)
 	req := coreexecutor.Request{
 		Model:   normalizedModel,
@@ -411,7 +542,22 @@ func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType
 	}
 	opts.Headers = requestHeaders(ctx)
 	opts.Metadata = mergeMetadata(cloneMetadata(metadata), reqMeta)
+	release, _, errSlot := h.acquireExecutionSlot(ctx)
+	if errSlot != nil {
+		return nil, errSlot
+	}
+	defer release()
+	ctx, sample := startSample(ctx, handlerType, modelName, rawJSON, false)
+	ctx, provenance := startProvenance(ctx, h.Cfg)
 	resp, err := h.AuthManager.Execute(ctx, providers, req, opts)
+	if err != nil && localFallback && h.AuthManager.ShouldUseLocalFallback(err) {
+		if fallbackResp, errFallback := h.AuthManager.ExecuteLocalFallback(ctx, req, opts); errFallback == nil {
+			markLocalFallback(ctx, modelName)
+			resp, err = fallbackResp, nil
+		} else {
+			log.Warnf("local fallback failed for model %s: %v", modelName, errFallback)
+		}
+	}
 	if err != nil {
 		status := http.StatusInternalServerError
 		if se, ok := err.(interface{ StatusCode() int }); ok && se != nil {
@@ -425,18 +571,35 @@ func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType
 				addon = hdr.Clone()
 			}
 		}
-		return nil, &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
+		errMsg = &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
+		if captureReplay {
+			captureForReplay(ctx, handlerType, modelName, rawJSON, alt, errMsg)
+		}
+		return nil, errMsg
 	}
-	return cloneBytes(resp.Payload), nil
+	sample.finish(resp.Payload)
+	return provenance.annotate(cloneBytes(resp.Payload)), nil
 }
 
 // ExecuteCountWithAuthManager executes a non-streaming request via the core auth manager.
 // This path is the only supported execution route.
 func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
+	if chain := fallbackChain(h.Cfg, modelName); len(chain) > 0 {
+		modelName = chain[0]
+	}
+	modelName = applyModelRename(ctx, modelName)
 	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName)
 	if errMsg != nil {
 		return nil, errMsg
 	}
+	if errMsg = applyModelPolicies(ctx, h.Cfg, modelName, normalizedModel, h.AuthManager.ModelAliasTargets(normalizedModel)...); errMsg != nil {
+		return nil, errMsg
+	}
+	if providers, errMsg = applyMaintenance(providers); errMsg != nil {
+		return nil, errMsg
+	}
+	rawJSON = applyImageDedup(h.Cfg, handlerType, rawJSON)
+	rawJSON = applyImageDownscale(h.Cfg, handlerType, providers, rawJSON)
 	reqMeta := requestExecutionMetadata(ctx)
 	req := coreexecutor.Request{
 		Model:   normalizedModel,
@@ -475,7 +638,40 @@ func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handle
 // ExecuteStreamWithAuthManager executes a streaming request via the core auth manager.
 // This path is the only supported execution route.
 func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) (<-chan []byte, <-chan *interfaces.ErrorMessage) {
+	if chain := fallbackChain(h.Cfg, modelName); len(chain) > 0 {
+		return h.executeStreamFallbackChain(ctx, handlerType, chain, rawJSON, alt)
+	}
+	return h.executeStreamWithAuthManager(ctx, handlerType, modelName, rawJSON, alt, true)
+}
+
+// executeStreamWithAuthManager executes a streaming request for modelName. localFallback lets
+// the local fallback model serve it once every remote credential is exhausted.
+func (h *BaseAPIHandler) executeStreamWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string, localFallback bool) (<-chan []byte, <-chan *interfaces.ErrorMessage) {
+	modelName = applyModelRename(ctx, modelName)
 	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName)
+	if errMsg == nil {
+		errMsg = applyModelPolicies(ctx, h.Cfg, modelName, normalizedModel, h.AuthManager.ModelAliasTargets(normalizedModel)...)
+	}
+	if errMsg == nil {
+		providers, errMsg = applyMaintenance(providers)
+	}
+	if errMsg == nil {
+		providers, errMsg = applyBudget(ctx, providers)
+	}
+	if errMsg == nil {
+		providers, errMsg = applyCapabilityChecks(h.Cfg, handlerType, modelName, rawJSON, providers)
+	}
+	if errMsg == nil {
+		errMsg = applyStrictFields(h.Cfg, handlerType, rawJSON, providers)
+	}
+	if errMsg == nil {
+		ctx, rawJSON, errMsg = applyConversationCap(ctx, handlerType, rawJSON)
+	}
+	if errMsg == nil {
+		rawJSON = applyLoopDetection(ctx, h.Cfg, handlerType, rawJSON)
+		rawJSON = applyImageDedup(h.Cfg, handlerType, rawJSON)
+		rawJSON = applyImageDownscale(h.Cfg, handlerType, providers, rawJSON)
+	}
 	if errMsg != nil {
 		errChan := make(chan *interfaces.ErrorMessage, 1)
 		errChan <- errMsg
@@ -498,8 +694,26 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 	}
 	opts.Headers = requestHeaders(ctx)
 	opts.Metadata = mergeMetadata(cloneMetadata(metadata), reqMeta)
+	release, class, errSlot := h.acquireExecutionSlot(ctx)
+	if errSlot != nil {
+		errChan := make(chan *interfaces.ErrorMessage, 1)
+		errChan <- errSlot
+		close(errChan)
+		return nil, errChan
+	}
+	ctx, sample := startSample(ctx, handlerType, modelName, rawJSON, true)
+	ctx, _ = startProvenance(ctx, h.Cfg)
 	chunks, err := h.AuthManager.ExecuteStream(ctx, providers, req, opts)
+	if err != nil && localFallback && h.AuthManager.ShouldUseLocalFallback(err) {
+		if fallbackChunks, errFallback := h.AuthManager.ExecuteStreamLocalFallback(ctx, req, opts); errFallback == nil {
+			markLocalFallback(ctx, modelName)
+			chunks, err = fallbackChunks, nil
+		} else {
+			log.Warnf("local fallback failed for model %s: %v", modelName, errFallback)
+		}
+	}
 	if err != nil {
+		release()
 		errChan := make(chan *interfaces.ErrorMessage, 1)
 		status := http.StatusInternalServerError
 		if se, ok := err.(interface{ StatusCode() int }); ok && se != nil {
@@ -519,9 +733,15 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 	}
 	dataChan := make(chan []byte)
 	errChan := make(chan *interfaces.ErrorMessage, 1)
+	throttle := time.Duration(0)
+	if class == PriorityBatch {
+		throttle = batchStreamThrottle(h.Cfg)
+	}
+	validator := newStructuredStreamValidator(h.Cfg, handlerType, rawJSON)
 	go func() {
 		defer close(dataChan)
 		defer close(errChan)
+		defer release()
 		sentPayload := false
 		bootstrapRetries := 0
 		maxBootstrapRetries := StreamingBootstrapRetries(h.Cfg)
@@ -555,6 +775,13 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 					chunk, ok = <-chunks
 				}
 				if !ok {
+					if validator != nil {
+						for _, held := range validator.flush() {
+							sample.addChunk(held)
+							dataChan <- held
+						}
+					}
+					sample.finishStream()
 					return
 				}
 				if chunk.Err != nil {
@@ -566,6 +793,9 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 							bootstrapRetries++
 							retryChunks, retryErr := h.AuthManager.ExecuteStream(ctx, providers, req, opts)
 							if retryErr == nil {
+								if validator != nil {
+									validator.reset()
+								}
 								chunks = retryChunks
 								continue outer
 							}
@@ -589,8 +819,28 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 					return
 				}
 				if len(chunk.Payload) > 0 {
-					sentPayload = true
-					dataChan <- cloneBytes(chunk.Payload)
+					if throttle > 0 && sentPayload && h.priority.interactiveWaiting() {
+						// Yield upstream bandwidth to queued interactive requests.
+						if !sleepWithContext(ctx, throttle) {
+							return
+						}
+					}
+					if validator == nil {
+						sentPayload = true
+						sample.addChunk(chunk.Payload)
+						dataChan <- cloneBytes(chunk.Payload)
+						continue
+					}
+					ready, errValidate := validator.push(cloneBytes(chunk.Payload))
+					if errValidate != nil {
+						errChan <- &interfaces.ErrorMessage{StatusCode: http.StatusBadGateway, Error: errValidate}
+						return
+					}
+					for _, payload := range ready {
+						sentPayload = true
+						sample.addChunk(payload)
+						dataChan <- payload
+					}
 				}
 			}
 		}
@@ -634,7 +884,7 @@ func (h *BaseAPIHandler) getRequestDetails(modelName string) (providers []string
 	}
 
 	if len(providers) == 0 {
-		return nil, "", nil, &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: fmt.Errorf("unknown provider for model %s", modelName)}
+		return nil, "", nil, &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: i18n.Errorf(i18n.MsgUnknownProvider, modelName)}
 	}
 
 	// If it's a dynamic model, the normalizedModel was already set to extractedModelName.
@@ -644,6 +894,14 @@ func (h *BaseAPIHandler) getRequestDetails(modelName string) (providers []string
 	return providers, normalizedModel, metadata, nil
 }
 
+// markLocalFallback tags the response as degraded because the local fallback model served it.
+func markLocalFallback(ctx context.Context, modelName string) {
+	log.Infof("remote credentials exhausted for model %s, served by local fallback", modelName)
+	if ginCtx, ok := ctx.Value("gin").(*gin.Context); ok && ginCtx != nil {
+		ginCtx.Header(coreauth.LocalFallbackHeader, "local-fallback")
+	}
+}
+
 func cloneBytes(src []byte) []byte {
 	if len(src) == 0 {
 		return nil
@@ -668,6 +926,21 @@ func cloneMetadata(src map[string]any) map[string]any {
 	return dst
 }
 
+// ErrorText returns the message of err for the client of c: in the language its Accept-Language
+// header prefers when err is translatable, or the status text when err has no message.
+func ErrorText(c *gin.Context, status int, err error) string {
+	if err != nil {
+		lang := i18n.Default()
+		if c != nil && c.Request != nil {
+			lang = i18n.FromAcceptLanguage(c.GetHeader("Accept-Language"), lang)
+		}
+		if text := strings.TrimSpace(i18n.Localize(err, lang)); text != "" {
+			return text
+		}
+	}
+	return http.StatusText(status)
+}
+
 // WriteErrorResponse writes an error message to the response writer using the HTTP status embedded in the message.
 func (h *BaseAPIHandler) WriteErrorResponse(c *gin.Context, msg *interfaces.ErrorMessage) {
 	status := http.StatusInternalServerError
@@ -686,12 +959,11 @@ func (h *BaseAPIHandler) WriteErrorResponse(c *gin.Context, msg *interfaces.Erro
 		}
 	}
 
-	errText := http.StatusText(status)
-	if msg != nil && msg.Error != nil {
-		if v := strings.TrimSpace(msg.Error.Error()); v != "" {
-			errText = v
-		}
+	var err error
+	if msg != nil {
+		err = msg.Error
 	}
+	errText := ErrorText(c, status, err)
 
 	body := BuildErrorResponseBody(status, errText)
 	// Append first to preserve upstream response logs, then drop duplicate payloads if already recorded.
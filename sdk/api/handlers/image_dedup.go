@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// inlineImage is a base64 image found in the request history.
+type inlineImage struct {
+	path string
+	data string
+}
+
+// applyImageDedup replaces repeated copies of the same inline image in a conversation with a short
+// text reference to its first occurrence. Clients that resend a screenshot on every turn otherwise
+// upload it again and again as part of the history.
+func applyImageDedup(cfg *config.SDKConfig, handlerType string, rawJSON []byte) []byte {
+	if cfg == nil || !cfg.ImageDedup {
+		return rawJSON
+	}
+	images := extractInlineImages(handlerType, rawJSON)
+	if len(images) < 2 {
+		return rawJSON
+	}
+	seen := make(map[string]bool, len(images))
+	out := rawJSON
+	replaced, saved := 0, 0
+	for _, image := range images {
+		hash := imageHash(image.data)
+		if !seen[hash] {
+			seen[hash] = true
+			continue
+		}
+		reference := fmt.Sprintf("[image %s: identical to an image shown earlier in this conversation]", hash)
+		updated, err := sjson.SetBytes(out, image.path, imageReferencePart(handlerType, reference))
+		if err != nil {
+			log.Warnf("failed to replace repeated image at %s: %v", image.path, err)
+			continue
+		}
+		out = updated
+		replaced++
+		saved += len(image.data)
+	}
+	if replaced > 0 {
+		log.Debugf("image dedup: replaced %d repeated images, saving %d bytes", replaced, saved)
+	}
+	return out
+}
+
+// imageHash returns a short stable identifier for base64 image data.
+func imageHash(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:6])
+}
+
+// imageReferencePart builds the text part standing in for a repeated image in handlerType's format.
+func imageReferencePart(handlerType, text string) map[string]string {
+	switch handlerType {
+	case constant.OpenaiResponse:
+		return map[string]string{"type": "input_text", "text": text}
+	case constant.Gemini, constant.GeminiCLI:
+		return map[string]string{"text": text}
+	default:
+		return map[string]string{"type": "text", "text": text}
+	}
+}
+
+// extractInlineImages lists base64 images in the request history in order, in handlerType's format.
+func extractInlineImages(handlerType string, rawJSON []byte) []inlineImage {
+	var images []inlineImage
+	switch handlerType {
+	case constant.Claude:
+		var walk func(prefix string, content gjson.Result)
+		walk = func(prefix string, content gjson.Result) {
+			content.ForEach(func(ci, block gjson.Result) bool {
+				path := fmt.Sprintf("%s.%d", prefix, ci.Int())
+				switch block.Get("type").String() {
+				case "image":
+					if block.Get("source.type").String() == "base64" {
+						images = append(images, inlineImage{path: path, data: block.Get("source.data").String()})
+					}
+				case "tool_result":
+					if nested := block.Get("content"); nested.IsArray() {
+						walk(path+".content", nested)
+					}
+				}
+				return true
+			})
+		}
+		gjson.GetBytes(rawJSON, "messages").ForEach(func(mi, message gjson.Result) bool {
+			if content := message.Get("content"); content.IsArray() {
+				walk(fmt.Sprintf("messages.%d.content", mi.Int()), content)
+			}
+			return true
+		})
+	case constant.OpenAI:
+		gjson.GetBytes(rawJSON, "messages").ForEach(func(mi, message gjson.Result) bool {
+			message.Get("content").ForEach(func(ci, part gjson.Result) bool {
+				if url := part.Get("image_url.url").String(); part.Get("type").String() == "image_url" && strings.HasPrefix(url, "data:") {
+					images = append(images, inlineImage{path: fmt.Sprintf("messages.%d.content.%d", mi.Int(), ci.Int()), data: url})
+				}
+				return true
+			})
+			return true
+		})
+	case constant.OpenaiResponse:
+		gjson.GetBytes(rawJSON, "input").ForEach(func(ii, item gjson.Result) bool {
+			item.Get("content").ForEach(func(ci, part gjson.Result) bool {
+				if url := part.Get("image_url").String(); part.Get("type").String() == "input_image" && strings.HasPrefix(url, "data:") {
+					images = append(images, inlineImage{path: fmt.Sprintf("input.%d.content.%d", ii.Int(), ci.Int()), data: url})
+				}
+				return true
+			})
+			return true
+		})
+	case constant.Gemini, constant.GeminiCLI:
+		root := "contents"
+		if handlerType == constant.GeminiCLI {
+			root = "request.contents"
+		}
+		gjson.GetBytes(rawJSON, root).ForEach(func(ci, content gjson.Result) bool {
+			content.Get("parts").ForEach(func(pi, part gjson.Result) bool {
+				inline := part.Get("inlineData")
+				if !inline.Exists() {
+					inline = part.Get("inline_data")
+				}
+				if data := inline.Get("data").String(); data != "" && strings.HasPrefix(inline.Get("mimeType").String()+inline.Get("mime_type").String(), "image/") {
+					images = append(images, inlineImage{path: fmt.Sprintf("%s.%d.parts.%d", root, ci.Int(), pi.Int()), data: data})
+				}
+				return true
+			})
+			return true
+		})
+	}
+	return images
+}
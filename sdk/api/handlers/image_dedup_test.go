@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func TestApplyImageDedupClaude(t *testing.T) {
+	cfg := &config.SDKConfig{ImageDedup: true}
+	body := []byte(`{"messages":[
+		{"role":"user","content":[{"type":"image","source":{"type":"base64","media_type":"image/png","data":"AAAA"}},{"type":"text","text":"look"}]},
+		{"role":"assistant","content":"ok"},
+		{"role":"user","content":[{"type":"tool_result","tool_use_id":"t","content":[{"type":"image","source":{"type":"base64","media_type":"image/png","data":"AAAA"}}]}]},
+		{"role":"user","content":[{"type":"image","source":{"type":"base64","media_type":"image/png","data":"BBBB"}}]}]}`)
+
+	if out := applyImageDedup(&config.SDKConfig{}, constant.Claude, body); string(out) != string(body) {
+		t.Fatalf("dedup must be disabled by default")
+	}
+
+	out := applyImageDedup(cfg, constant.Claude, body)
+	if gjson.GetBytes(out, "messages.0.content.0.type").String() != "image" {
+		t.Fatalf("first occurrence must be kept, got %s", out)
+	}
+	repeat := gjson.GetBytes(out, "messages.2.content.0.content.0")
+	if repeat.Get("type").String() != "text" || !strings.Contains(repeat.Get("text").String(), imageHash("AAAA")) {
+		t.Fatalf("expected repeated image to be replaced by a reference, got %s", repeat.Raw)
+	}
+	if gjson.GetBytes(out, "messages.3.content.0.type").String() != "image" {
+		t.Fatalf("distinct images must be kept, got %s", out)
+	}
+}
+
+func TestApplyImageDedupOpenAI(t *testing.T) {
+	cfg := &config.SDKConfig{ImageDedup: true}
+	body := []byte(`{"messages":[
+		{"role":"user","content":[{"type":"image_url","image_url":{"url":"data:image/png;base64,AAAA"}}]},
+		{"role":"user","content":[{"type":"image_url","image_url":{"url":"https://example.com/a.png"}}]},
+		{"role":"user","content":[{"type":"text","text":"again"},{"type":"image_url","image_url":{"url":"data:image/png;base64,AAAA"}}]}]}`)
+
+	out := applyImageDedup(cfg, constant.OpenAI, body)
+	if gjson.GetBytes(out, "messages.0.content.0.type").String() != "image_url" || gjson.GetBytes(out, "messages.1.content.0.type").String() != "image_url" {
+		t.Fatalf("first occurrence and remote images must be kept, got %s", out)
+	}
+	if got := gjson.GetBytes(out, "messages.2.content.1.type").String(); got != "text" {
+		t.Fatalf("expected repeated image to be replaced, got %s", out)
+	}
+}
@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+
+	// Register the GIF decoder for image.Decode.
+	_ "image/gif"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	defaultImageQuality  = 85
+	defaultImageLimitKey = "default"
+	// maxDownscalePasses bounds how often an image is shrunk further to meet a byte limit.
+	maxDownscalePasses = 6
+	// maxDecodePixels bounds the images decoded for downscaling; a small file may declare huge
+	// dimensions, and decoding it would allocate gigabytes.
+	maxDecodePixels = 50_000_000
+)
+
+// errUnsupportedImage marks images whose header is not a format the downscaler decodes, such as
+// WebP. They are passed through unchanged.
+var errUnsupportedImage = errors.New("unsupported image")
+
+// builtinImageLimits are the documented per-image limits of the upstream providers.
+var builtinImageLimits = map[string]config.ImageLimit{
+	"claude":      {MaxDimension: 8000, MaxBytes: 5 << 20},
+	"kiro":        {MaxDimension: 8000, MaxBytes: 3750 << 10},
+	"codex":       {MaxDimension: 2048, MaxBytes: 20 << 20},
+	"gemini":      {MaxDimension: 3072, MaxBytes: 20 << 20},
+	"gemini-cli":  {MaxDimension: 3072, MaxBytes: 20 << 20},
+	"vertex":      {MaxDimension: 3072, MaxBytes: 20 << 20},
+	"aistudio":    {MaxDimension: 3072, MaxBytes: 20 << 20},
+	"antigravity": {MaxDimension: 3072, MaxBytes: 20 << 20},
+}
+
+// applyImageDownscale resizes and recompresses inline images exceeding the image limit of the
+// providers that may serve the request. When several providers are candidates the strictest
+// limit applies, since the auth picked by the manager is not known yet.
+func applyImageDownscale(cfg *config.SDKConfig, handlerType string, providers []string, rawJSON []byte) []byte {
+	if cfg == nil || !cfg.ImageDownscale.Enable {
+		return rawJSON
+	}
+	limit := resolveImageLimit(cfg.ImageDownscale.Limits, providers)
+	if limit.MaxDimension <= 0 && limit.MaxBytes <= 0 {
+		return rawJSON
+	}
+	quality := cfg.ImageDownscale.Quality
+	if quality <= 0 || quality > 100 {
+		quality = defaultImageQuality
+	}
+	out := rawJSON
+	for _, img := range extractInlineImages(handlerType, rawJSON) {
+		data := img.data
+		if strings.HasPrefix(data, "data:") {
+			_, data, _ = strings.Cut(data, ",")
+		}
+		mimeType, resized, err := downscaleImage(data, limit, quality)
+		if errors.Is(err, errUnsupportedImage) {
+			log.Debugf("image downscale: skipping image at %s: %v", img.path, err)
+			continue
+		}
+		if err != nil {
+			log.Warnf("image downscale: leaving image at %s unchanged: %v", img.path, err)
+			continue
+		}
+		if resized == "" {
+			continue
+		}
+		updated, err := writeInlineImage(handlerType, out, img.path, mimeType, resized)
+		if err != nil {
+			log.Warnf("image downscale: failed to replace image at %s: %v", img.path, err)
+			continue
+		}
+		out = updated
+	}
+	return out
+}
+
+// resolveImageLimit returns the strictest image limit across providers.
+func resolveImageLimit(overrides map[string]config.ImageLimit, providers []string) config.ImageLimit {
+	var limit config.ImageLimit
+	for _, provider := range providers {
+		current, ok := overrides[provider]
+		if !ok {
+			current, ok = builtinImageLimits[provider]
+		}
+		if !ok {
+			current = overrides[defaultImageLimitKey]
+		}
+		limit.MaxDimension = minPositive(limit.MaxDimension, current.MaxDimension)
+		limit.MaxBytes = minPositive(limit.MaxBytes, current.MaxBytes)
+	}
+	return limit
+}
+
+// minPositive returns the smaller of two bounds, where zero means unbounded.
+func minPositive(a, b int) int {
+	if a <= 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// downscaleImage shrinks base64 image data until it fits limit. It returns an empty result when
+// the image already fits. Format and dimensions are read from the image header, decoding only
+// as much base64 as the header needs, so images that fit or cannot be decoded cost little.
+func downscaleImage(data string, limit config.ImageLimit, quality int) (string, string, error) {
+	header, format, err := image.DecodeConfig(base64.NewDecoder(base64.StdEncoding, strings.NewReader(data)))
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %v", errUnsupportedImage, err)
+	}
+	tooLarge := limit.MaxDimension > 0 && (header.Width > limit.MaxDimension || header.Height > limit.MaxDimension)
+	if !tooLarge && (limit.MaxBytes <= 0 || len(data) <= limit.MaxBytes) {
+		return "", "", nil
+	}
+	if int64(header.Width)*int64(header.Height) > maxDecodePixels {
+		return "", "", fmt.Errorf("%dx%d image exceeds the %d pixel decode budget", header.Width, header.Height, maxDecodePixels)
+	}
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid base64 data: %w", err)
+	}
+	src, _, err := image.Decode(bytes.NewReader(raw))
+	if err != nil {
+		return "", "", fmt.Errorf("decode %s: %w", format, err)
+	}
+
+	width, height := header.Width, header.Height
+	if tooLarge {
+		width, height = fitDimensions(width, height, limit.MaxDimension)
+	}
+	for pass := 0; pass < maxDownscalePasses; pass++ {
+		resized := src
+		if width != header.Width || height != header.Height {
+			resized = resizeImage(src, width, height)
+		}
+		mimeType, encoded, errEncode := encodeImage(resized, format == "png" && tooLarge && pass == 0, quality)
+		if errEncode != nil {
+			return "", "", errEncode
+		}
+		if limit.MaxBytes <= 0 || len(encoded) <= limit.MaxBytes {
+			log.Infof("image downscale: %s %dx%d (%d bytes) -> %s %dx%d (%d bytes)", format, header.Width, header.Height, len(data), mimeType, width, height, len(encoded))
+			return mimeType, encoded, nil
+		}
+		width, height = max(1, width*3/4), max(1, height*3/4)
+	}
+	return "", "", fmt.Errorf("could not fit %dx%d image within %d bytes", header.Width, header.Height, limit.MaxBytes)
+}
+
+// fitDimensions scales width and height down proportionally so neither exceeds maxDimension.
+func fitDimensions(width, height, maxDimension int) (int, int) {
+	if width >= height {
+		return maxDimension, max(1, height*maxDimension/width)
+	}
+	return max(1, width*maxDimension/height), maxDimension
+}
+
+// encodeImage encodes img as base64 PNG when keepPNG is set and as JPEG otherwise.
+func encodeImage(img image.Image, keepPNG bool, quality int) (string, string, error) {
+	var buf bytes.Buffer
+	mimeType := "image/jpeg"
+	var err error
+	if keepPNG {
+		mimeType = "image/png"
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, flattenImage(img), &jpeg.Options{Quality: quality})
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("encode %s: %w", mimeType, err)
+	}
+	return mimeType, base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// resizeImage scales src to width x height by averaging the source pixels covered by each
+// destination pixel.
+func resizeImage(src image.Image, width, height int) *image.NRGBA {
+	bounds := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*bounds.Dy()/height)
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := max(x0+1, bounds.Min.X+(x+1)*bounds.Dx()/width)
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, b, a, n = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca), n+1
+				}
+			}
+			// Averages are premultiplied; convert back to straight alpha for NRGBA.
+			if a == 0 {
+				continue
+			}
+			dst.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(r * 0xff / a),
+				G: uint8(g * 0xff / a),
+				B: uint8(b * 0xff / a),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+	return dst
+}
+
+// flattenImage composites img onto a white background, since JPEG has no alpha channel.
+func flattenImage(img image.Image) image.Image {
+	if img.ColorModel() == color.YCbCrModel || img.ColorModel() == color.GrayModel {
+		return img
+	}
+	bounds := img.Bounds()
+	dst := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			white := 0xffff - a
+			dst.SetRGBA(x, y, color.RGBA{R: uint8((r + white) >> 8), G: uint8((g + white) >> 8), B: uint8((b + white) >> 8), A: 0xff})
+		}
+	}
+	return dst
+}
+
+// writeInlineImage replaces the image at path with new base64 data in handlerType's format.
+func writeInlineImage(handlerType string, rawJSON []byte, path, mimeType, data string) ([]byte, error) {
+	switch handlerType {
+	case constant.Claude:
+		out, err := sjson.SetBytes(rawJSON, path+".source.media_type", mimeType)
+		if err != nil {
+			return rawJSON, err
+		}
+		return sjson.SetBytes(out, path+".source.data", data)
+	case constant.OpenAI:
+		return sjson.SetBytes(rawJSON, path+".image_url.url", "data:"+mimeType+";base64,"+data)
+	case constant.OpenaiResponse:
+		return sjson.SetBytes(rawJSON, path+".image_url", "data:"+mimeType+";base64,"+data)
+	case constant.Gemini, constant.GeminiCLI:
+		key, mimeKey := "inlineData", "mimeType"
+		if !gjson.GetBytes(rawJSON, path+".inlineData").Exists() {
+			key, mimeKey = "inline_data", "mime_type"
+		}
+		out, err := sjson.SetBytes(rawJSON, path+"."+key+"."+mimeKey, mimeType)
+		if err != nil {
+			return rawJSON, err
+		}
+		return sjson.SetBytes(out, path+"."+key+".data", data)
+	default:
+		return rawJSON, fmt.Errorf("unsupported handler type %s", handlerType)
+	}
+}
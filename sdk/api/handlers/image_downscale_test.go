@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func testPNG(t *testing.T, width, height int) string {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 7), G: uint8(y * 13), B: uint8((x*x + y*31) ^ (x * y)), A: 0xff})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func decodedSize(t *testing.T, data string) (int, int) {
+	t.Helper()
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		t.Fatalf("decode base64: %v", err)
+	}
+	header, _, err := image.DecodeConfig(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("decode image: %v", err)
+	}
+	return header.Width, header.Height
+}
+
+func TestApplyImageDownscaleDimensions(t *testing.T) {
+	cfg := &config.SDKConfig{ImageDownscale: config.ImageDownscaleConfig{
+		Enable: true,
+		Limits: map[string]config.ImageLimit{"claude": {MaxDimension: 40}, "codex": {MaxDimension: 20}},
+	}}
+	body := []byte(`{"messages":[{"role":"user","content":[{"type":"image","source":{"type":"base64","media_type":"image/png","data":"` + testPNG(t, 100, 50) + `"}}]}]}`)
+
+	if out := applyImageDownscale(&config.SDKConfig{}, constant.Claude, []string{"claude"}, body); string(out) != string(body) {
+		t.Fatalf("downscaling must be disabled by default")
+	}
+
+	out := applyImageDownscale(cfg, constant.Claude, []string{"claude", "codex"}, body)
+	source := gjson.GetBytes(out, "messages.0.content.0.source")
+	if source.Get("media_type").String() != "image/png" {
+		t.Fatalf("expected png to stay png, got %s", source.Get("media_type").String())
+	}
+	if w, h := decodedSize(t, source.Get("data").String()); w != 20 || h != 10 {
+		t.Fatalf("expected strictest limit 20x10, got %dx%d", w, h)
+	}
+
+	small := []byte(`{"messages":[{"role":"user","content":[{"type":"image","source":{"type":"base64","media_type":"image/png","data":"` + testPNG(t, 10, 10) + `"}}]}]}`)
+	if out = applyImageDownscale(cfg, constant.Claude, []string{"claude"}, small); string(out) != string(small) {
+		t.Fatalf("images within limits must be left untouched")
+	}
+}
+
+func TestApplyImageDownscaleBytes(t *testing.T) {
+	data := testPNG(t, 200, 200)
+	cfg := &config.SDKConfig{ImageDownscale: config.ImageDownscaleConfig{
+		Enable: true,
+		Limits: map[string]config.ImageLimit{"default": {MaxBytes: len(data) / 4}},
+	}}
+	body := []byte(`{"messages":[{"role":"user","content":[{"type":"image_url","image_url":{"url":"data:image/png;base64,` + data + `"}}]}]}`)
+
+	out := applyImageDownscale(cfg, constant.OpenAI, []string{"openai-compat"}, body)
+	url := gjson.GetBytes(out, "messages.0.content.0.image_url.url").String()
+	if !strings.HasPrefix(url, "data:image/jpeg;base64,") {
+		t.Fatalf("expected recompressed jpeg, got %.40s", url)
+	}
+	if encoded := strings.TrimPrefix(url, "data:image/jpeg;base64,"); len(encoded) > len(data)/4 {
+		t.Fatalf("expected at most %d bytes, got %d", len(data)/4, len(encoded))
+	}
+}
+
+func TestDownscaleImageRejectsOversizedHeader(t *testing.T) {
+	raw, err := base64.StdEncoding.DecodeString(testPNG(t, 1, 1))
+	if err != nil {
+		t.Fatalf("decode base64: %v", err)
+	}
+	// The IHDR chunk follows the 8-byte signature: length, type, width, height, ..., CRC.
+	binary.BigEndian.PutUint32(raw[16:20], 60000)
+	binary.BigEndian.PutUint32(raw[20:24], 60000)
+	binary.BigEndian.PutUint32(raw[29:33], crc32.ChecksumIEEE(raw[12:29]))
+	data := base64.StdEncoding.EncodeToString(raw)
+	if w, h := decodedSize(t, data); w != 60000 || h != 60000 {
+		t.Fatalf("test image must declare 60000x60000, got %dx%d", w, h)
+	}
+
+	if _, _, err := downscaleImage(data, config.ImageLimit{MaxDimension: 2048}, defaultImageQuality); err == nil || !strings.Contains(err.Error(), "pixel decode budget") {
+		t.Fatalf("an image over the pixel budget must not be decoded, got %v", err)
+	}
+	cfg := &config.SDKConfig{ImageDownscale: config.ImageDownscaleConfig{Enable: true}}
+	body := []byte(`{"messages":[{"role":"user","content":[{"type":"image","source":{"type":"base64","media_type":"image/png","data":"` + data + `"}}]}]}`)
+	if out := applyImageDownscale(cfg, constant.Claude, []string{"codex"}, body); string(out) != string(body) {
+		t.Fatal("an image over the pixel budget must pass through untouched")
+	}
+}
+
+func TestDownscaleImageSkipsUnsupportedFormats(t *testing.T) {
+	webp := base64.StdEncoding.EncodeToString(append([]byte("RIFF\x24\x00\x00\x00WEBPVP8 "), make([]byte, 64)...))
+	if _, _, err := downscaleImage(webp, config.ImageLimit{MaxBytes: 1}, defaultImageQuality); !errors.Is(err, errUnsupportedImage) {
+		t.Fatalf("a WebP image must be skipped as unsupported, got %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	defaultLoopThreshold = 3
+	loopActionToolError  = "tool-error"
+)
+
+// toolCall is one tool invocation found in the request history.
+type toolCall struct {
+	id   string
+	name string
+	args string
+	// resultPath locates the tool result answering this call, when present.
+	resultPath string
+}
+
+// applyLoopDetection inspects the conversation history for a run of identical tool calls at its
+// tail. When the run reaches the threshold it logs an alert and, for the "tool-error" action,
+// replaces the latest tool result with an error instructing the model to change course.
+func applyLoopDetection(ctx context.Context, cfg *config.SDKConfig, handlerType string, rawJSON []byte) []byte {
+	if cfg == nil || !cfg.LoopDetection.Enable {
+		return rawJSON
+	}
+	threshold := cfg.LoopDetection.Threshold
+	if threshold <= 0 {
+		threshold = defaultLoopThreshold
+	}
+	calls := extractToolCalls(handlerType, rawJSON)
+	if len(calls) < threshold {
+		return rawJSON
+	}
+	last := calls[len(calls)-1]
+	run := 1
+	for i := len(calls) - 2; i >= 0 && calls[i].name == last.name && calls[i].args == last.args; i-- {
+		run++
+	}
+	if run < threshold {
+		return rawJSON
+	}
+
+	log.Warnf("tool-call loop detected: %s called %d times in a row with identical arguments (client key %s)", last.name, run, util.HideAPIKey(requestAPIKey(ctx)))
+	if !strings.EqualFold(strings.TrimSpace(cfg.LoopDetection.Action), loopActionToolError) || last.resultPath == "" {
+		return rawJSON
+	}
+	message := fmt.Sprintf("Error: loop detected. The tool %q has been called %d times in a row with identical arguments and the result will not change. Do not call it again with these arguments; try a different approach or report the problem to the user.", last.name, run)
+	updated, err := rewriteToolResult(handlerType, rawJSON, last.resultPath, message)
+	if err != nil {
+		log.Warnf("failed to inject loop-detection tool result: %v", err)
+		return rawJSON
+	}
+	return updated
+}
+
+// extractToolCalls lists the tool calls in the request history in order, in handlerType's format.
+func extractToolCalls(handlerType string, rawJSON []byte) []toolCall {
+	var calls []toolCall
+	results := make(map[string]string)
+	switch handlerType {
+	case constant.Claude:
+		gjson.GetBytes(rawJSON, "messages").ForEach(func(mi, message gjson.Result) bool {
+			message.Get("content").ForEach(func(ci, block gjson.Result) bool {
+				switch block.Get("type").String() {
+				case "tool_use":
+					calls = append(calls, toolCall{id: block.Get("id").String(), name: block.Get("name").String(), args: canonicalJSON(block.Get("input").Raw)})
+				case "tool_result":
+					results[block.Get("tool_use_id").String()] = fmt.Sprintf("messages.%d.content.%d", mi.Int(), ci.Int())
+				}
+				return true
+			})
+			return true
+		})
+	case constant.OpenAI:
+		gjson.GetBytes(rawJSON, "messages").ForEach(func(mi, message gjson.Result) bool {
+			message.Get("tool_calls").ForEach(func(_, call gjson.Result) bool {
+				calls = append(calls, toolCall{id: call.Get("id").String(), name: call.Get("function.name").String(), args: canonicalJSON(call.Get("function.arguments").String())})
+				return true
+			})
+			if message.Get("role").String() == "tool" {
+				results[message.Get("tool_call_id").String()] = fmt.Sprintf("messages.%d", mi.Int())
+			}
+			return true
+		})
+	case constant.OpenaiResponse:
+		gjson.GetBytes(rawJSON, "input").ForEach(func(ii, item gjson.Result) bool {
+			switch item.Get("type").String() {
+			case "function_call":
+				calls = append(calls, toolCall{id: item.Get("call_id").String(), name: item.Get("name").String(), args: canonicalJSON(item.Get("arguments").String())})
+			case "function_call_output":
+				results[item.Get("call_id").String()] = fmt.Sprintf("input.%d", ii.Int())
+			}
+			return true
+		})
+	case constant.Gemini, constant.GeminiCLI:
+		root := "contents"
+		if handlerType == constant.GeminiCLI {
+			root = "request.contents"
+		}
+		// Gemini has no call IDs; responses are matched to the most recent unanswered call by name.
+		pending := make(map[string]int)
+		gjson.GetBytes(rawJSON, root).ForEach(func(ci, content gjson.Result) bool {
+			content.Get("parts").ForEach(func(pi, part gjson.Result) bool {
+				if call := part.Get("functionCall"); call.Exists() {
+					id := fmt.Sprintf("%d", len(calls))
+					name := call.Get("name").String()
+					pending[name] = len(calls)
+					calls = append(calls, toolCall{id: id, name: name, args: canonicalJSON(call.Get("args").Raw)})
+				} else if response := part.Get("functionResponse"); response.Exists() {
+					if idx, ok := pending[response.Get("name").String()]; ok {
+						results[calls[idx].id] = fmt.Sprintf("%s.%d.parts.%d", root, ci.Int(), pi.Int())
+						delete(pending, response.Get("name").String())
+					}
+				}
+				return true
+			})
+			return true
+		})
+	default:
+		return nil
+	}
+	for i := range calls {
+		calls[i].resultPath = results[calls[i].id]
+	}
+	return calls
+}
+
+// rewriteToolResult replaces the tool result at path with an error message.
+func rewriteToolResult(handlerType string, rawJSON []byte, path, message string) ([]byte, error) {
+	switch handlerType {
+	case constant.Claude:
+		out, err := sjson.SetBytes(rawJSON, path+".content", message)
+		if err != nil {
+			return rawJSON, err
+		}
+		return sjson.SetBytes(out, path+".is_error", true)
+	case constant.OpenAI:
+		return sjson.SetBytes(rawJSON, path+".content", message)
+	case constant.OpenaiResponse:
+		return sjson.SetBytes(rawJSON, path+".output", message)
+	case constant.Gemini, constant.GeminiCLI:
+		return sjson.SetBytes(rawJSON, path+".functionResponse.response", map[string]string{"error": message})
+	default:
+		return rawJSON, fmt.Errorf("unsupported handler type %s", handlerType)
+	}
+}
+
+// canonicalJSON normalizes JSON arguments so key order and whitespace do not affect comparison.
+func canonicalJSON(raw string) string {
+	raw = strings.TrimSpace(raw)
+	var value any
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		return raw
+	}
+	normalized, err := json.Marshal(value)
+	if err != nil {
+		return raw
+	}
+	return string(normalized)
+}
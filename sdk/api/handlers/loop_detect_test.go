@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func claudeLoopBody(calls int) string {
+	var b strings.Builder
+	b.WriteString(`{"messages":[{"role":"user","content":"go"}`)
+	for i := 0; i < calls; i++ {
+		id := string(rune('a' + i))
+		b.WriteString(`,{"role":"assistant","content":[{"type":"tool_use","id":"` + id + `","name":"read","input":{"path":"x","n":1}}]}`)
+		b.WriteString(`,{"role":"user","content":[{"type":"tool_result","tool_use_id":"` + id + `","content":"same"}]}`)
+	}
+	b.WriteString(`]}`)
+	return b.String()
+}
+
+func TestApplyLoopDetectionClaude(t *testing.T) {
+	cfg := &config.SDKConfig{LoopDetection: config.LoopDetectionConfig{Enable: true, Threshold: 3, Action: "tool-error"}}
+
+	body := []byte(claudeLoopBody(2))
+	if out := applyLoopDetection(context.Background(), cfg, constant.Claude, body); string(out) != string(body) {
+		t.Fatalf("two identical calls must not trigger the detector")
+	}
+
+	out := applyLoopDetection(context.Background(), cfg, constant.Claude, []byte(claudeLoopBody(3)))
+	last := gjson.GetBytes(out, "messages.6.content.0")
+	if !last.Get("is_error").Bool() || !strings.Contains(last.Get("content").String(), "loop detected") {
+		t.Fatalf("expected synthetic error tool_result, got %s", last.Raw)
+	}
+	if gjson.GetBytes(out, "messages.4.content.0.content").String() != "same" {
+		t.Fatalf("earlier tool results must be left untouched")
+	}
+
+	cfg.LoopDetection.Action = "alert"
+	body = []byte(claudeLoopBody(3))
+	if out = applyLoopDetection(context.Background(), cfg, constant.Claude, body); string(out) != string(body) {
+		t.Fatalf("alert action must not modify the request")
+	}
+}
+
+func TestApplyLoopDetectionOpenAIArgumentOrder(t *testing.T) {
+	cfg := &config.SDKConfig{LoopDetection: config.LoopDetectionConfig{Enable: true, Threshold: 2, Action: "tool-error"}}
+	body := []byte(`{"messages":[
+		{"role":"assistant","tool_calls":[{"id":"1","function":{"name":"ls","arguments":"{\"a\":1,\"b\":2}"}}]},
+		{"role":"tool","tool_call_id":"1","content":"out"},
+		{"role":"assistant","tool_calls":[{"id":"2","function":{"name":"ls","arguments":"{\"b\":2, \"a\":1}"}}]},
+		{"role":"tool","tool_call_id":"2","content":"out"}]}`)
+	out := applyLoopDetection(context.Background(), cfg, constant.OpenAI, body)
+	if !strings.Contains(gjson.GetBytes(out, "messages.3.content").String(), "loop detected") {
+		t.Fatalf("expected the latest tool message to be replaced, got %s", out)
+	}
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/maintenance"
+)
+
+func TestCountTokensSkipsProvidersInMaintenance(t *testing.T) {
+	executor := &chainExecutor{provider: "maint-count"}
+	handler := newChainHandler(t, executor)
+	maintenance.Default().SetOverride("maint-count", maintenance.StateMaintenance, time.Now().Add(time.Hour), "upgrade")
+	t.Cleanup(func() { maintenance.Default().ClearOverride("maint-count") })
+
+	_, errMsg := handler.ExecuteCountWithAuthManager(context.Background(), "claude", "maint-count-model", []byte(`{"model":"maint-count-model"}`), "")
+	if errMsg == nil || errMsg.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("count tokens must respect the maintenance window, got %+v", errMsg)
+	}
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/i18n"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// applyModelPolicies rejects the request with 403 when a model policy covering the client key
+// denies the model or does not allow it. The requested name, the resolved name and every alias
+// target are checked against deny rules, so aliases such as "auto" or configured model aliases
+// cannot bypass a policy. Allow rules must cover the model the request finally reaches: the
+// alias targets when the resolved model is an alias, the resolved model otherwise.
+func applyModelPolicies(ctx context.Context, cfg *config.SDKConfig, modelName, resolvedModel string, aliasTargets ...string) *interfaces.ErrorMessage {
+	if cfg == nil || len(cfg.ModelPolicies) == 0 {
+		return nil
+	}
+	apiKey := requestAPIKey(ctx)
+	if apiKey == "" {
+		return nil
+	}
+	names := appendModelName(appendModelName(nil, modelName), resolvedModel)
+	var targets []string
+	for _, target := range aliasTargets {
+		targets = appendModelName(targets, target)
+	}
+	if len(targets) == 0 {
+		targets = appendModelName(nil, resolvedModel)
+	}
+	if len(targets) == 0 {
+		targets = names
+	}
+	for _, target := range targets {
+		names = appendModelName(names, target)
+	}
+	for _, policy := range cfg.ModelPolicies {
+		if !policyCoversKey(policy, apiKey) {
+			continue
+		}
+		var reason i18n.Key
+		if matchesAnyModelPattern(policy.Models.Deny, names) {
+			reason = i18n.MsgModelPolicyDenied
+		} else if len(policy.Models.Allow) > 0 && !matchesEveryModel(policy.Models.Allow, targets) {
+			reason = i18n.MsgModelPolicyNotAllowed
+		}
+		if reason == "" {
+			continue
+		}
+		log.Infof("model policy %q rejected model %s", policy.Name, modelName)
+		return &interfaces.ErrorMessage{
+			StatusCode: http.StatusForbidden,
+			Error:      i18n.Errorf(reason, policy.Name, modelName),
+		}
+	}
+	return nil
+}
+
+// appendModelName appends the normalised model name to names unless it is empty or present.
+func appendModelName(names []string, model string) []string {
+	model = strings.ToLower(strings.TrimSpace(model))
+	if model == "" || slices.Contains(names, model) {
+		return names
+	}
+	return append(names, model)
+}
+
+func policyCoversKey(policy config.ModelPolicy, apiKey string) bool {
+	for _, key := range policy.APIKeys {
+		if strings.TrimSpace(key) == apiKey {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAnyModelPattern(patterns, names []string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		for _, name := range names {
+			if matchModelGlob(pattern, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchesEveryModel(patterns, names []string) bool {
+	for _, name := range names {
+		if !matchesAnyModelPattern(patterns, []string{name}) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchModelGlob matches name against pattern, where '*' matches any run of characters.
+func matchModelGlob(pattern, name string) bool {
+	if pattern == "" {
+		return false
+	}
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == name
+	}
+	if !strings.HasPrefix(name, parts[0]) {
+		return false
+	}
+	name = name[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		idx := strings.Index(name, part)
+		if idx < 0 {
+			return false
+		}
+		name = name[idx+len(part):]
+	}
+	return strings.HasSuffix(name, last)
+}
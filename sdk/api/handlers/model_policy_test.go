@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func modelPolicyContext(apiKey string) context.Context {
+	ginCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ginCtx.Set("apiKey", apiKey)
+	return context.WithValue(context.Background(), "gin", ginCtx)
+}
+
+func TestModelPoliciesEnforcePerKeyRules(t *testing.T) {
+	cfg := &config.SDKConfig{ModelPolicies: []config.ModelPolicy{{
+		Name:    "junior",
+		APIKeys: []string{"junior-key"},
+		Models: config.ModelAccessRules{
+			Allow: []string{"claude-*", "gpt-4o*"},
+			Deny:  []string{"*OPUS*"},
+		},
+	}}}
+	junior := modelPolicyContext("junior-key")
+
+	if errMsg := applyModelPolicies(junior, cfg, "claude-sonnet-4-5", "claude-sonnet-4-5"); errMsg != nil {
+		t.Fatalf("allowed model rejected: %v", errMsg.Error)
+	}
+	errMsg := applyModelPolicies(junior, cfg, "claude-opus-4-1", "claude-opus-4-1")
+	if errMsg == nil || errMsg.StatusCode != http.StatusForbidden || !strings.Contains(errMsg.Error.Error(), `"junior"`) {
+		t.Fatalf("denied model must fail with 403 naming the policy, got %+v", errMsg)
+	}
+	if errMsg = applyModelPolicies(junior, cfg, "gemini-2.5-pro", "gemini-2.5-pro"); errMsg == nil || !strings.Contains(errMsg.Error.Error(), "does not allow") {
+		t.Fatalf("model outside the allow list must be rejected, got %+v", errMsg)
+	}
+	// Aliases are checked by their resolved model as well.
+	if errMsg = applyModelPolicies(junior, cfg, "auto", "claude-opus-4-1"); errMsg == nil {
+		t.Fatalf("alias resolving to a denied model must be rejected")
+	}
+	if errMsg = applyModelPolicies(modelPolicyContext("senior-key"), cfg, "claude-opus-4-1", "claude-opus-4-1"); errMsg != nil {
+		t.Fatalf("keys without a policy must be unrestricted: %v", errMsg.Error)
+	}
+}
+
+func TestModelPoliciesCheckAliasTargets(t *testing.T) {
+	cfg := &config.SDKConfig{ModelPolicies: []config.ModelPolicy{{
+		Name:    "junior",
+		APIKeys: []string{"junior-key"},
+		Models: config.ModelAccessRules{
+			Allow: []string{"cheap", "claude-haiku*"},
+			Deny:  []string{"claude-opus*"},
+		},
+	}}}
+	junior := modelPolicyContext("junior-key")
+
+	if errMsg := applyModelPolicies(junior, cfg, "fast", "fast", "claude-opus-4-1"); errMsg == nil || errMsg.StatusCode != http.StatusForbidden {
+		t.Fatalf("alias of a denied model must be rejected, got %+v", errMsg)
+	}
+	if errMsg := applyModelPolicies(junior, cfg, "cheap", "cheap", "claude-sonnet-4-5"); errMsg == nil || !strings.Contains(errMsg.Error.Error(), "does not allow") {
+		t.Fatalf("allowed alias of a model outside the allow list must be rejected, got %+v", errMsg)
+	}
+	if errMsg := applyModelPolicies(junior, cfg, "cheap", "cheap", "claude-haiku-4-5"); errMsg != nil {
+		t.Fatalf("alias of an allowed model rejected: %v", errMsg.Error)
+	}
+}
+
+func TestMatchModelGlob(t *testing.T) {
+	cases := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"claude-opus-4-1", "claude-opus-4-1", true},
+		{"claude-*", "claude-sonnet", true},
+		{"*opus*", "claude-opus-4", true},
+		{"gpt-*-mini", "gpt-4o-mini", true},
+		{"gpt-*-mini", "gpt-4o-mini-high", false},
+		{"a*a", "a", false},
+		{"", "x", false},
+	}
+	for _, tc := range cases {
+		if got := matchModelGlob(tc.pattern, tc.name); got != tc.want {
+			t.Errorf("matchModelGlob(%q, %q) = %v, want %v", tc.pattern, tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestModelPolicyErrorFollowsAcceptLanguage(t *testing.T) {
+	cfg := &config.SDKConfig{ModelPolicies: []config.ModelPolicy{{
+		Name:    "junior",
+		APIKeys: []string{"junior-key"},
+		Models:  config.ModelAccessRules{Deny: []string{"*opus*"}},
+	}}}
+	errMsg := applyModelPolicies(modelPolicyContext("junior-key"), cfg, "claude-opus-4-1", "claude-opus-4-1")
+	if errMsg == nil {
+		t.Fatal("denied model must be rejected")
+	}
+
+	for header, want := range map[string]string{
+		"":                        `model policy \"junior\" denies model claude-opus-4-1 for this API key`,
+		"zh-CN,zh;q=0.9,en;q=0.8": `模型策略 \"junior\" 禁止此 API 密钥使用模型 claude-opus-4-1`,
+	} {
+		recorder := httptest.NewRecorder()
+		ginCtx, _ := gin.CreateTestContext(recorder)
+		ginCtx.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		ginCtx.Request.Header.Set("Accept-Language", header)
+		(&BaseAPIHandler{}).WriteErrorResponse(ginCtx, errMsg)
+		if recorder.Code != http.StatusForbidden || !strings.Contains(recorder.Body.String(), want) {
+			t.Fatalf("Accept-Language %q: got %d %s, want message %s", header, recorder.Code, recorder.Body.String(), want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/modelrename"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// applyModelRename replaces a deprecated model with its successor when no credential serves the
+// deprecated model anymore, keeping any thinking suffix. The client is told through a Warning
+// header so it can update its configuration.
+func applyModelRename(ctx context.Context, modelName string) string {
+	base, _ := normalizeModelMetadata(modelName)
+	if base == "" || len(util.GetProviderName(base)) > 0 {
+		return modelName
+	}
+	successor, reason, ok := modelrename.Default().Successor(base)
+	if !ok {
+		return modelName
+	}
+	renamed := successor
+	if suffix, found := strings.CutPrefix(modelName, base); found {
+		renamed += suffix
+	}
+	log.Infof("model %s is deprecated, serving %s", modelName, renamed)
+	if ginCtx, okGin := ctx.Value("gin").(*gin.Context); okGin && ginCtx != nil {
+		warning := fmt.Sprintf("model %s is deprecated and was replaced by %s", base, successor)
+		if reason != "" {
+			warning += ": " + reason
+		}
+		ginCtx.Header("Warning", fmt.Sprintf("299 - %q", warning))
+	}
+	return renamed
+}
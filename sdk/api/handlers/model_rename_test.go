@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/modelrename"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+)
+
+func TestApplyModelRename(t *testing.T) {
+	modelrename.Default().SetOverride("retired-model", "current-model", "renamed upstream")
+	defer modelrename.Default().ClearOverride("retired-model")
+
+	recorder := httptest.NewRecorder()
+	ginCtx, _ := gin.CreateTestContext(recorder)
+	ctx := context.WithValue(context.Background(), "gin", ginCtx)
+
+	if got := applyModelRename(ctx, "retired-model"); got != "current-model" {
+		t.Fatalf("applyModelRename = %q, want current-model", got)
+	}
+	if warning := recorder.Header().Get("Warning"); !strings.HasPrefix(warning, "299 - ") || !strings.Contains(warning, "renamed upstream") {
+		t.Fatalf("unexpected Warning header %q", warning)
+	}
+	if got := applyModelRename(context.Background(), "unknown-model"); got != "unknown-model" {
+		t.Fatalf("models without a rename must be kept, got %q", got)
+	}
+
+	registry.GetGlobalRegistry().RegisterClient("rename-test-client", "openai", []*registry.ModelInfo{{ID: "retired-model"}})
+	defer registry.GetGlobalRegistry().UnregisterClient("rename-test-client")
+	if got := applyModelRename(context.Background(), "retired-model"); got != "retired-model" {
+		t.Fatalf("a deprecated model still served by a credential must be kept, got %q", got)
+	}
+}
@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+// PriorityClass identifies how a request competes for upstream concurrency.
+type PriorityClass string
+
+const (
+	// PriorityInteractive requests are admitted ahead of any queued batch work.
+	PriorityInteractive PriorityClass = "interactive"
+	// PriorityBatch requests only take slots no interactive request is waiting for.
+	PriorityBatch PriorityClass = "batch"
+)
+
+// priorityWaiter is a queued request; ready is closed once a slot has been granted.
+type priorityWaiter struct {
+	class   PriorityClass
+	ready   chan struct{}
+	granted bool
+}
+
+// priorityGate is a two-class admission queue. Interactive waiters are always served first,
+// and batch requests never hold more than batchLimit slots when that limit is set.
+type priorityGate struct {
+	mu          sync.Mutex
+	limit       int
+	batchLimit  int
+	active      int
+	activeBatch int
+	interactive *list.List
+	batch       *list.List
+}
+
+func newPriorityGate() *priorityGate {
+	return &priorityGate{interactive: list.New(), batch: list.New()}
+}
+
+// configure applies new limits and admits any waiters the new capacity allows.
+func (g *priorityGate) configure(cfg config.PriorityConfig) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.limit = cfg.MaxConcurrency
+	g.batchLimit = cfg.BatchMaxConcurrency
+	g.dispatchLocked()
+}
+
+// acquire blocks until the request may proceed or ctx ends. The returned release must be called
+// exactly once when the upstream call has finished.
+func (g *priorityGate) acquire(ctx context.Context, class PriorityClass) (func(), error) {
+	if g == nil {
+		return func() {}, nil
+	}
+	g.mu.Lock()
+	if g.limit <= 0 {
+		// Draining after the gate was disabled: admit without tracking.
+		g.mu.Unlock()
+		return func() {}, nil
+	}
+	if g.canAdmitLocked(class) {
+		g.admitLocked(class)
+		g.mu.Unlock()
+		return g.releaseFunc(class), nil
+	}
+	waiter := &priorityWaiter{class: class, ready: make(chan struct{})}
+	queue := g.queueFor(class)
+	elem := queue.PushBack(waiter)
+	g.mu.Unlock()
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	select {
+	case <-waiter.ready:
+		return g.releaseFunc(class), nil
+	case <-ctx.Done():
+		g.mu.Lock()
+		if waiter.granted {
+			// The slot was granted while we were giving up; hand it back.
+			g.releaseLocked(class)
+		} else {
+			queue.Remove(elem)
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// interactiveWaiting reports whether interactive requests are queued for a slot.
+func (g *priorityGate) interactiveWaiting() bool {
+	if g == nil {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.interactive.Len() > 0
+}
+
+func (g *priorityGate) queueFor(class PriorityClass) *list.List {
+	if class == PriorityBatch {
+		return g.batch
+	}
+	return g.interactive
+}
+
+func (g *priorityGate) canAdmitLocked(class PriorityClass) bool {
+	if g.active >= g.limit {
+		return false
+	}
+	if class != PriorityBatch {
+		return true
+	}
+	if g.interactive.Len() > 0 || g.batch.Len() > 0 {
+		return false
+	}
+	return g.batchLimit <= 0 || g.activeBatch < g.batchLimit
+}
+
+func (g *priorityGate) admitLocked(class PriorityClass) {
+	g.active++
+	if class == PriorityBatch {
+		g.activeBatch++
+	}
+}
+
+func (g *priorityGate) releaseFunc(class PriorityClass) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			g.mu.Lock()
+			g.releaseLocked(class)
+			g.mu.Unlock()
+		})
+	}
+}
+
+func (g *priorityGate) releaseLocked(class PriorityClass) {
+	if g.active > 0 {
+		g.active--
+	}
+	if class == PriorityBatch && g.activeBatch > 0 {
+		g.activeBatch--
+	}
+	g.dispatchLocked()
+}
+
+// dispatchLocked hands free slots to queued waiters, interactive first.
+func (g *priorityGate) dispatchLocked() {
+	for {
+		unlimited := g.limit <= 0
+		if !unlimited && g.active >= g.limit {
+			return
+		}
+		queue := g.interactive
+		if queue.Len() == 0 {
+			if g.batch.Len() == 0 {
+				return
+			}
+			if !unlimited && g.batchLimit > 0 && g.activeBatch >= g.batchLimit {
+				return
+			}
+			queue = g.batch
+		}
+		waiter := queue.Remove(queue.Front()).(*priorityWaiter)
+		waiter.granted = true
+		g.admitLocked(waiter.class)
+		close(waiter.ready)
+	}
+}
+
+// batchPriorityContextKey marks contexts whose requests always run in the batch class.
+type batchPriorityContextKey struct{}
+
+// WithBatchPriority returns a context whose upstream requests are admitted as batch work
+// regardless of the client key, e.g. for requests submitted through a batch API.
+func WithBatchPriority(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, batchPriorityContextKey{}, true)
+}
+
+// requestPriorityClass classifies the request by the authenticated client API key.
+func requestPriorityClass(ctx context.Context, cfg *config.SDKConfig) PriorityClass {
+	if ctx != nil {
+		if batch, _ := ctx.Value(batchPriorityContextKey{}).(bool); batch {
+			return PriorityBatch
+		}
+	}
+	if cfg == nil || len(cfg.Priority.BatchAPIKeys) == 0 {
+		return PriorityInteractive
+	}
+	apiKey := requestAPIKey(ctx)
+	if apiKey == "" {
+		return PriorityInteractive
+	}
+	for _, key := range cfg.Priority.BatchAPIKeys {
+		if strings.TrimSpace(key) == apiKey {
+			return PriorityBatch
+		}
+	}
+	return PriorityInteractive
+}
+
+// requestAPIKey returns the client API key the request was authenticated with, if any.
+func requestAPIKey(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil {
+		return ""
+	}
+	return strings.TrimSpace(ginCtx.GetString("apiKey"))
+}
+
+// batchStreamThrottle returns the per-chunk delay for batch streams, or zero when disabled.
+func batchStreamThrottle(cfg *config.SDKConfig) time.Duration {
+	if cfg == nil || cfg.Priority.BatchStreamThrottleMS <= 0 {
+		return 0
+	}
+	return time.Duration(cfg.Priority.BatchStreamThrottleMS) * time.Millisecond
+}
+
+// sleepWithContext waits for d and reports false if ctx ended first.
+func sleepWithContext(ctx context.Context, d time.Duration) bool {
+	if ctx == nil {
+		time.Sleep(d)
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func TestPriorityGateAdmitsInteractiveBeforeBatch(t *testing.T) {
+	gate := newPriorityGate()
+	gate.configure(config.PriorityConfig{MaxConcurrency: 1})
+
+	release, err := gate.acquire(context.Background(), PriorityBatch)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	order := make(chan PriorityClass, 2)
+	waitFor := func(class PriorityClass) {
+		r, errAcquire := gate.acquire(context.Background(), class)
+		if errAcquire != nil {
+			t.Errorf("acquire %s: %v", class, errAcquire)
+			return
+		}
+		order <- class
+		r()
+	}
+	go waitFor(PriorityBatch)
+	waitForQueued(t, gate, 1)
+	go waitFor(PriorityInteractive)
+	waitForQueued(t, gate, 2)
+
+	release()
+	if first := <-order; first != PriorityInteractive {
+		t.Fatalf("first admitted class = %s, want %s", first, PriorityInteractive)
+	}
+	if second := <-order; second != PriorityBatch {
+		t.Fatalf("second admitted class = %s, want %s", second, PriorityBatch)
+	}
+}
+
+func TestPriorityGateBatchLimitKeepsInteractiveCapacity(t *testing.T) {
+	gate := newPriorityGate()
+	gate.configure(config.PriorityConfig{MaxConcurrency: 2, BatchMaxConcurrency: 1})
+
+	releaseBatch, err := gate.acquire(context.Background(), PriorityBatch)
+	if err != nil {
+		t.Fatalf("acquire batch: %v", err)
+	}
+	defer releaseBatch()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err = gate.acquire(ctx, PriorityBatch); err == nil {
+		t.Fatal("second batch request should wait for the batch limit")
+	}
+
+	releaseInteractive, err := gate.acquire(context.Background(), PriorityInteractive)
+	if err != nil {
+		t.Fatalf("acquire interactive: %v", err)
+	}
+	releaseInteractive()
+
+	gate.mu.Lock()
+	defer gate.mu.Unlock()
+	if gate.batch.Len() != 0 {
+		t.Fatalf("canceled waiter left in queue: %d", gate.batch.Len())
+	}
+}
+
+func waitForQueued(t *testing.T, gate *priorityGate, want int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		gate.mu.Lock()
+		queued := gate.interactive.Len() + gate.batch.Len()
+		gate.mu.Unlock()
+		if queued == want {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d queued requests", want)
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	// ProvenanceField names the vendor-extension field added to non-streaming JSON responses and
+	// the prefix of the final SSE comment of streams.
+	ProvenanceField = "cliproxy"
+
+	provenanceContextKey = "cliproxy.provenance"
+)
+
+// Provenance describes how a response was served.
+type Provenance struct {
+	Provider  string `json:"provider,omitempty"`
+	AuthLabel string `json:"auth_label,omitempty"`
+	LatencyMS int64  `json:"latency_ms"`
+	// Retries counts the credentials that failed before the serving one.
+	Retries int `json:"retries"`
+}
+
+// responseProvenance collects the provenance of a request whose client key opted in.
+type responseProvenance struct {
+	route   *coreauth.RouteInfo
+	started time.Time
+}
+
+// startProvenance returns a context in which the serving credential is recorded when the client
+// key of the request opted into provenance, and nil otherwise. The tracker is also stored on the
+// gin context so ForwardStream can report it once the stream ends.
+func startProvenance(ctx context.Context, cfg *config.SDKConfig) (context.Context, *responseProvenance) {
+	if cfg == nil || len(cfg.Provenance.APIKeys) == 0 {
+		return ctx, nil
+	}
+	apiKey := requestAPIKey(ctx)
+	if apiKey == "" {
+		return ctx, nil
+	}
+	enabled := false
+	for _, key := range cfg.Provenance.APIKeys {
+		if strings.TrimSpace(key) == apiKey {
+			enabled = true
+			break
+		}
+	}
+	if !enabled {
+		return ctx, nil
+	}
+	ctx, route := coreauth.WithRouteRecorder(ctx)
+	p := &responseProvenance{route: route, started: time.Now()}
+	if ginCtx, ok := ctx.Value("gin").(*gin.Context); ok && ginCtx != nil {
+		ginCtx.Set(provenanceContextKey, p)
+	}
+	return ctx, p
+}
+
+// snapshot returns the provenance observed so far.
+func (p *responseProvenance) snapshot() Provenance {
+	out := Provenance{
+		Provider:  p.route.Provider,
+		AuthLabel: p.route.Label,
+		LatencyMS: time.Since(p.started).Milliseconds(),
+	}
+	if p.route.Attempts > 1 {
+		out.Retries = p.route.Attempts - 1
+	}
+	return out
+}
+
+// annotate adds the provenance field to a JSON object response. Other payloads are returned as is.
+func (p *responseProvenance) annotate(payload []byte) []byte {
+	if p == nil || !gjson.ParseBytes(payload).IsObject() {
+		return payload
+	}
+	raw, err := json.Marshal(p.snapshot())
+	if err != nil {
+		return payload
+	}
+	out, err := sjson.SetRawBytes(payload, ProvenanceField, raw)
+	if err != nil {
+		return payload
+	}
+	return out
+}
+
+// writeProvenanceComment ends an SSE stream with a provenance comment when the request opted in.
+func writeProvenanceComment(c *gin.Context) {
+	value, ok := c.Get(provenanceContextKey)
+	if !ok {
+		return
+	}
+	p, ok := value.(*responseProvenance)
+	if !ok || p == nil {
+		return
+	}
+	if !strings.HasPrefix(c.Writer.Header().Get("Content-Type"), "text/event-stream") {
+		return
+	}
+	raw, err := json.Marshal(p.snapshot())
+	if err != nil {
+		return
+	}
+	NewSSEWriter(c.Writer).Comment(ProvenanceField + " " + string(raw))
+}
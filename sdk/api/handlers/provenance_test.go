@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func TestProvenanceIsAddedForOptedInKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &sdkconfig.SDKConfig{}
+	cfg.Provenance.APIKeys = []string{"key-a"}
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	c.Set("apiKey", "key-b")
+	ctx := context.WithValue(context.Background(), "gin", c)
+	if _, p := startProvenance(ctx, cfg); p != nil {
+		t.Fatal("keys that did not opt in must not get provenance")
+	}
+
+	c.Set("apiKey", "key-a")
+	_, p := startProvenance(ctx, cfg)
+	if p == nil {
+		t.Fatal("expected provenance for an opted-in key")
+	}
+	p.route.Provider = "codex"
+	p.route.Label = "team-account"
+	p.route.Attempts = 2
+
+	body := p.annotate([]byte(`{"id":"chatcmpl-1"}`))
+	if got := gjson.GetBytes(body, "cliproxy.provider").String(); got != "codex" {
+		t.Fatalf("provider = %q in %s", got, body)
+	}
+	if gjson.GetBytes(body, "cliproxy.auth_label").String() != "team-account" || gjson.GetBytes(body, "cliproxy.retries").Int() != 1 {
+		t.Fatalf("unexpected provenance block: %s", body)
+	}
+	if got := string(p.annotate([]byte(`[1,2]`))); got != `[1,2]` {
+		t.Fatalf("non-object payloads must be left unchanged, got %s", got)
+	}
+
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	data := make(chan []byte, 1)
+	data <- []byte(`{"id":"1"}`)
+	close(data)
+	interval := time.Hour
+	sse := NewSSEWriter(c.Writer)
+	NewBaseAPIHandlers(cfg, nil).ForwardStream(c, c.Writer, func(error) {}, data, make(chan *interfaces.ErrorMessage), StreamForwardOptions{
+		KeepAliveInterval: &interval,
+		WriteChunk:        sse.Data,
+		WriteDone:         sse.Done,
+	})
+	stream := rec.Body.String()
+	_, comment, found := strings.Cut(stream, "data: [DONE]\n\n: cliproxy ")
+	if !found || !strings.HasSuffix(comment, "\n\n") {
+		t.Fatalf("stream must end with a provenance comment: %q", stream)
+	}
+	if got := gjson.Get(comment, "auth_label").String(); got != "team-account" {
+		t.Fatalf("unexpected provenance comment: %q", comment)
+	}
+	assertConformingSSE(t, stream)
+}
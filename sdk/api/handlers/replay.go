@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/replay"
+	log "github.com/sirupsen/logrus"
+)
+
+// replayContextKey marks contexts created by ExecuteReplay so failed replays are not re-captured.
+type replayContextKey struct{}
+
+// captureForReplay persists a failed non-streaming request when the client key opted into the
+// replay queue and the failure looks like a transient provider outage.
+func captureForReplay(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string, errMsg *interfaces.ErrorMessage) {
+	if ctx == nil || errMsg == nil || !replay.IsTransient(errMsg.StatusCode) {
+		return
+	}
+	if replaying, _ := ctx.Value(replayContextKey{}).(bool); replaying {
+		return
+	}
+	queue := replay.Default()
+	apiKey := requestAPIKey(ctx)
+	if !queue.Enabled(apiKey) {
+		return
+	}
+	entry := replay.Entry{
+		APIKey:      apiKey,
+		HandlerType: handlerType,
+		Model:       modelName,
+		Alt:         alt,
+		Headers:     requestHeaders(ctx),
+		Payload:     cloneBytes(rawJSON),
+		StatusCode:  errMsg.StatusCode,
+	}
+	if errMsg.Error != nil {
+		entry.Error = errMsg.Error.Error()
+	}
+	if ginCtx, ok := ctx.Value("gin").(*gin.Context); ok && ginCtx != nil && ginCtx.Request != nil && ginCtx.Request.URL != nil {
+		entry.Path = ginCtx.Request.URL.Path
+	}
+	stored, err := queue.Capture(entry)
+	if err != nil {
+		log.Warnf("replay queue: failed to capture request: %v", err)
+		return
+	}
+	log.Infof("replay queue: captured failed %s request for model %s as %s", handlerType, modelName, stored.ID)
+}
+
+// ExecuteReplay re-runs a captured request through the auth manager on behalf of the original
+// client key. It implements replay.Executor.
+func (h *BaseAPIHandler) ExecuteReplay(ctx context.Context, entry replay.Entry) replay.Result {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx = context.WithValue(ctx, replayContextKey{}, true)
+	resp, errMsg := h.ExecuteDetached(ctx, entry.APIKey, entry.Path, entry.Headers, entry.HandlerType, entry.Model, entry.Payload, entry.Alt)
+	if errMsg != nil {
+		errReplay := errMsg.Error
+		if errReplay == nil {
+			errReplay = errors.New(http.StatusText(errMsg.StatusCode))
+		}
+		return replay.Result{StatusCode: errMsg.StatusCode, Err: errReplay}
+	}
+	return replay.Result{Body: resp, StatusCode: http.StatusOK}
+}
@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	// PartialSalvageHeader is sent as a trailer on streams that were finished as truncated after
+	// the upstream aborted.
+	PartialSalvageHeader = "X-CLIProxy-Truncated"
+	// PartialSalvageWarning is added as the "warning" field of the synthesized final event.
+	PartialSalvageWarning = "upstream stream aborted; response truncated"
+
+	defaultPartialSalvageMinBytes = 2048
+)
+
+// StreamSalvager observes the chunks of a stream and, when the upstream aborts after partial
+// output, writes the events that end the stream the way a length-limited response would.
+// Chunks written before ForwardStream takes over must be passed to Observe by the caller.
+type StreamSalvager interface {
+	// Observe records a chunk that was sent to the client.
+	Observe(chunk []byte)
+	// Forwarded returns the number of payload bytes observed.
+	Forwarded() int
+	// Finish writes the closing events of a truncated response.
+	Finish(sse *SSEWriter)
+}
+
+// partialSalvageEnabled reports whether the client key of the request opted into salvage.
+func (h *BaseAPIHandler) partialSalvageEnabled(c *gin.Context) bool {
+	if h == nil || h.Cfg == nil || c == nil || len(h.Cfg.Streaming.PartialSalvage.APIKeys) == 0 {
+		return false
+	}
+	apiKey := strings.TrimSpace(c.GetString("apiKey"))
+	if apiKey == "" {
+		return false
+	}
+	for _, key := range h.Cfg.Streaming.PartialSalvage.APIKeys {
+		if strings.TrimSpace(key) == apiKey {
+			return true
+		}
+	}
+	return false
+}
+
+// salvageStream finishes the stream as truncated when salvage is active and enough output was
+// sent, and reports whether it did.
+func (h *BaseAPIHandler) salvageStream(c *gin.Context, salvage StreamSalvager, errMsg *interfaces.ErrorMessage) bool {
+	if salvage == nil {
+		return false
+	}
+	minBytes := h.Cfg.Streaming.PartialSalvage.MinBytes
+	if minBytes <= 0 {
+		minBytes = defaultPartialSalvageMinBytes
+	}
+	if salvage.Forwarded() < minBytes {
+		return false
+	}
+	var cause error
+	if errMsg != nil {
+		cause = errMsg.Error
+	}
+	log.Warnf("upstream stream aborted after %d bytes, finishing response as truncated: %v", salvage.Forwarded(), cause)
+	c.Writer.Header().Set(http.TrailerPrefix+PartialSalvageHeader, "upstream-aborted")
+	salvage.Finish(NewSSEWriter(c.Writer))
+	return true
+}
+
+// ssePayloads returns the JSON payloads carried by a chunk, which is either bare JSON or one or
+// more SSE events.
+func ssePayloads(chunk []byte) [][]byte {
+	trimmed := bytes.TrimSpace(chunk)
+	if gjson.ValidBytes(trimmed) {
+		return [][]byte{trimmed}
+	}
+	var payloads [][]byte
+	for _, line := range bytes.Split(trimmed, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if !bytes.HasPrefix(line, sseDataPrefix) {
+			continue
+		}
+		if payload := trimSSEDataPrefix(line); gjson.ValidBytes(payload) {
+			payloads = append(payloads, payload)
+		}
+	}
+	return payloads
+}
+
+type salvageCounter struct{ forwarded int }
+
+func (s *salvageCounter) Forwarded() int { return s.forwarded }
+
+// openAISalvager ends OpenAI chat and text completion streams with finish_reason "length".
+type openAISalvager struct {
+	salvageCounter
+	last []byte
+}
+
+// NewOpenAISalvager returns a StreamSalvager for OpenAI Chat Completions and Completions streams.
+func NewOpenAISalvager() StreamSalvager { return &openAISalvager{} }
+
+func (s *openAISalvager) Observe(chunk []byte) {
+	s.forwarded += len(chunk)
+	for _, payload := range ssePayloads(chunk) {
+		if gjson.GetBytes(payload, "id").Exists() {
+			s.last = payload
+		}
+	}
+}
+
+func (s *openAISalvager) Finish(sse *SSEWriter) {
+	out := []byte(`{"object":"chat.completion.chunk"}`)
+	for _, field := range []string{"id", "object", "created", "model"} {
+		if value := gjson.GetBytes(s.last, field); value.Exists() {
+			out, _ = sjson.SetRawBytes(out, field, []byte(value.Raw))
+		}
+	}
+	choice := `{"index":0,"delta":{},"finish_reason":"length"}`
+	if gjson.GetBytes(out, "object").String() == "text_completion" {
+		choice = `{"index":0,"text":"","finish_reason":"length"}`
+	}
+	out, _ = sjson.SetRawBytes(out, "choices", []byte("["+choice+"]"))
+	out, _ = sjson.SetBytes(out, "warning", PartialSalvageWarning)
+	sse.Data(out)
+	sse.Done()
+}
+
+// claudeSalvager closes open content blocks and ends Anthropic streams with stop_reason "max_tokens".
+type claudeSalvager struct {
+	salvageCounter
+	open         map[int64]bool
+	outputTokens int64
+}
+
+// NewClaudeSalvager returns a StreamSalvager for Anthropic Messages streams.
+func NewClaudeSalvager() StreamSalvager { return &claudeSalvager{open: make(map[int64]bool)} }
+
+func (s *claudeSalvager) Observe(chunk []byte) {
+	s.forwarded += len(chunk)
+	for _, payload := range ssePayloads(chunk) {
+		event := gjson.ParseBytes(payload)
+		switch event.Get("type").String() {
+		case "content_block_start":
+			s.open[event.Get("index").Int()] = true
+		case "content_block_stop":
+			delete(s.open, event.Get("index").Int())
+		case "message_start":
+			s.outputTokens = event.Get("message.usage.output_tokens").Int()
+		case "message_delta":
+			if tokens := event.Get("usage.output_tokens"); tokens.Exists() {
+				s.outputTokens = tokens.Int()
+			}
+		}
+	}
+}
+
+func (s *claudeSalvager) Finish(sse *SSEWriter) {
+	indexes := make([]int64, 0, len(s.open))
+	for index := range s.open {
+		indexes = append(indexes, index)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+	for _, index := range indexes {
+		stop, _ := sjson.SetBytes([]byte(`{"type":"content_block_stop"}`), "index", index)
+		sse.NamedEvent("content_block_stop", stop)
+	}
+	delta := []byte(`{"type":"message_delta","delta":{"stop_reason":"max_tokens","stop_sequence":null}}`)
+	delta, _ = sjson.SetBytes(delta, "usage.output_tokens", s.outputTokens)
+	delta, _ = sjson.SetBytes(delta, "warning", PartialSalvageWarning)
+	sse.NamedEvent("message_delta", delta)
+	sse.NamedEvent("message_stop", []byte(`{"type":"message_stop"}`))
+}
+
+// responsesSalvager ends OpenAI Responses streams with a response.incomplete event.
+type responsesSalvager struct {
+	salvageCounter
+	response []byte
+	sequence int64
+}
+
+// NewResponsesSalvager returns a StreamSalvager for OpenAI Responses streams.
+func NewResponsesSalvager() StreamSalvager { return &responsesSalvager{} }
+
+func (s *responsesSalvager) Observe(chunk []byte) {
+	s.forwarded += len(chunk)
+	for _, payload := range ssePayloads(chunk) {
+		event := gjson.ParseBytes(payload)
+		if response := event.Get("response"); response.IsObject() {
+			s.response = []byte(response.Raw)
+		}
+		if seq := event.Get("sequence_number").Int(); seq > s.sequence {
+			s.sequence = seq
+		}
+	}
+}
+
+func (s *responsesSalvager) Finish(sse *SSEWriter) {
+	response := s.response
+	if response == nil {
+		response = []byte(`{"object":"response"}`)
+	}
+	response, _ = sjson.SetBytes(response, "status", "incomplete")
+	response, _ = sjson.SetRawBytes(response, "incomplete_details", []byte(`{"reason":"max_output_tokens"}`))
+	event := []byte(`{"type":"response.incomplete"}`)
+	event, _ = sjson.SetBytes(event, "sequence_number", s.sequence+1)
+	event, _ = sjson.SetRawBytes(event, "response", response)
+	event, _ = sjson.SetBytes(event, "warning", PartialSalvageWarning)
+	sse.NamedEvent("response.incomplete", event)
+}
+
+// geminiSalvager ends Gemini streams with a MAX_TOKENS candidate.
+type geminiSalvager struct {
+	salvageCounter
+	last []byte
+}
+
+// NewGeminiSalvager returns a StreamSalvager for Gemini and Gemini CLI streams.
+func NewGeminiSalvager() StreamSalvager { return &geminiSalvager{} }
+
+func (s *geminiSalvager) Observe(chunk []byte) {
+	s.forwarded += len(chunk)
+	if payloads := ssePayloads(chunk); len(payloads) > 0 {
+		s.last = payloads[len(payloads)-1]
+	}
+}
+
+func (s *geminiSalvager) Finish(sse *SSEWriter) {
+	root := ""
+	if gjson.GetBytes(s.last, "response").IsObject() {
+		root = "response."
+	}
+	out := []byte(`{}`)
+	out, _ = sjson.SetRawBytes(out, root+"candidates", []byte(`[{"content":{"role":"model","parts":[{"text":""}]},"finishReason":"MAX_TOKENS","index":0}]`))
+	for _, field := range []string{"modelVersion", "responseId"} {
+		if value := gjson.GetBytes(s.last, root+field); value.Exists() {
+			out, _ = sjson.SetRawBytes(out, root+field, []byte(value.Raw))
+		}
+	}
+	out, _ = sjson.SetBytes(out, "warning", PartialSalvageWarning)
+	sse.Data(out)
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func forwardAbortedStream(t *testing.T, apiKey string, salvage StreamSalvager, chunks ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	c.Set("apiKey", apiKey)
+
+	cfg := &sdkconfig.SDKConfig{}
+	cfg.Streaming.PartialSalvage = sdkconfig.PartialSalvageConfig{APIKeys: []string{"agent-key"}, MinBytes: 32}
+	h := NewBaseAPIHandlers(cfg, nil)
+
+	sse := NewSSEWriter(c.Writer)
+	for _, chunk := range chunks {
+		sse.Event([]byte(chunk))
+		salvage.Observe([]byte(chunk))
+	}
+	data := make(chan []byte)
+	close(data)
+	errs := make(chan *interfaces.ErrorMessage, 1)
+	errs <- &interfaces.ErrorMessage{StatusCode: http.StatusBadGateway, Error: errors.New("upstream reset")}
+	h.ForwardStream(c, c.Writer, func(error) {}, data, errs, StreamForwardOptions{
+		Salvage: salvage,
+		WriteTerminalError: func(errMsg *interfaces.ErrorMessage) {
+			sse.NamedEvent("error", []byte(`{"error":"aborted"}`))
+		},
+		WriteDone: sse.Done,
+	})
+	assertConformingSSE(t, rec.Body.String())
+	return rec
+}
+
+func lastEventData(t *testing.T, stream string) gjson.Result {
+	t.Helper()
+	events := strings.Split(strings.TrimSuffix(stream, "\n\n"), "\n\n")
+	for i := len(events) - 1; i >= 0; i-- {
+		for _, line := range strings.Split(events[i], "\n") {
+			if payload := strings.TrimPrefix(line, "data: "); payload != line && payload != "[DONE]" {
+				return gjson.Parse(payload)
+			}
+		}
+	}
+	t.Fatalf("no data event in %q", stream)
+	return gjson.Result{}
+}
+
+func TestPartialSalvageOpenAI(t *testing.T) {
+	chunk := `data: {"id":"chatcmpl-1","object":"chat.completion.chunk","created":7,"model":"m","choices":[{"index":0,"delta":{"content":"partial answer"}}]}`
+	rec := forwardAbortedStream(t, "agent-key", NewOpenAISalvager(), chunk)
+	body := rec.Body.String()
+	if !strings.HasSuffix(body, "data: [DONE]\n\n") {
+		t.Fatalf("salvaged OpenAI stream must end with [DONE]: %q", body)
+	}
+	final := lastEventData(t, body)
+	if final.Get("id").String() != "chatcmpl-1" || final.Get("created").Int() != 7 || final.Get("choices.0.finish_reason").String() != "length" {
+		t.Fatalf("unexpected final chunk: %s", final.Raw)
+	}
+	if final.Get("warning").String() != PartialSalvageWarning {
+		t.Fatalf("final chunk lacks the warning field: %s", final.Raw)
+	}
+	if rec.Header().Get(http.TrailerPrefix+PartialSalvageHeader) == "" {
+		t.Fatalf("salvaged stream must announce truncation in a trailer")
+	}
+}
+
+func TestPartialSalvageResponses(t *testing.T) {
+	rec := forwardAbortedStream(t, "agent-key", NewResponsesSalvager(),
+		`event: response.created`+"\n"+`data: {"type":"response.created","sequence_number":0,"response":{"id":"resp_1","object":"response","status":"in_progress"}}`,
+		`event: response.output_text.delta`+"\n"+`data: {"type":"response.output_text.delta","sequence_number":1,"delta":"partial"}`,
+	)
+	final := lastEventData(t, rec.Body.String())
+	if final.Get("type").String() != "response.incomplete" || final.Get("sequence_number").Int() != 2 {
+		t.Fatalf("unexpected final event: %s", final.Raw)
+	}
+	if final.Get("response.id").String() != "resp_1" || final.Get("response.status").String() != "incomplete" || final.Get("response.incomplete_details.reason").String() != "max_output_tokens" {
+		t.Fatalf("unexpected final response: %s", final.Raw)
+	}
+}
+
+func TestPartialSalvageGeminiCLI(t *testing.T) {
+	rec := forwardAbortedStream(t, "agent-key", NewGeminiSalvager(),
+		`data: {"response":{"candidates":[{"content":{"role":"model","parts":[{"text":"partial answer"}]}}],"modelVersion":"gemini-x"}}`)
+	final := lastEventData(t, rec.Body.String())
+	if final.Get("response.candidates.0.finishReason").String() != "MAX_TOKENS" || final.Get("response.modelVersion").String() != "gemini-x" {
+		t.Fatalf("unexpected final chunk: %s", final.Raw)
+	}
+}
+
+func TestPartialSalvageRequiresOptInAndOutput(t *testing.T) {
+	chunk := `data: {"id":"chatcmpl-1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"partial answer"}}]}`
+	if body := forwardAbortedStream(t, "other-key", NewOpenAISalvager(), chunk).Body.String(); !strings.Contains(body, "event: error") {
+		t.Fatalf("keys that did not opt in must receive the error: %q", body)
+	}
+	if body := forwardAbortedStream(t, "agent-key", NewOpenAISalvager(), `data: {"id":"x"}`).Body.String(); !strings.Contains(body, "event: error") {
+		t.Fatalf("streams below min-bytes must receive the error: %q", body)
+	}
+}
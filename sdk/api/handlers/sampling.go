@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/archive"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/conversation"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/sampling"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+// pendingSample tracks a request picked for quality sampling or archived for compliance until its
+// response is complete.
+type pendingSample struct {
+	sampled     bool
+	archived    bool
+	apiKey      string
+	handlerType string
+	model       string
+	stream      bool
+	request     []byte
+	headers     http.Header
+	route       *coreauth.RouteInfo
+	started     time.Time
+	streamed    bytes.Buffer
+}
+
+// startSample decides whether the request is sampled or archived. Such a request gets a context
+// in which the auth manager records the credential that serves it.
+func startSample(ctx context.Context, handlerType, modelName string, rawJSON []byte, stream bool) (context.Context, *pendingSample) {
+	apiKey := requestAPIKey(ctx)
+	sampled := sampling.Default().ShouldSample()
+	archived := archive.Default().Enabled(apiKey)
+	if !sampled && !archived {
+		return ctx, nil
+	}
+	ctx, route := coreauth.WithRouteRecorder(ctx)
+	return ctx, &pendingSample{
+		sampled:     sampled,
+		archived:    archived,
+		apiKey:      apiKey,
+		handlerType: handlerType,
+		model:       modelName,
+		stream:      stream,
+		request:     cloneBytes(rawJSON),
+		headers:     requestHeaders(ctx),
+		route:       route,
+		started:     time.Now(),
+	}
+}
+
+// finish stores the sample and queues the archive record with the complete response. It is a
+// no-op for requests that are neither sampled nor archived.
+func (p *pendingSample) finish(response []byte) {
+	if p == nil {
+		return
+	}
+	latency := time.Since(p.started).Milliseconds()
+	if p.archived {
+		archive.Default().Enqueue(archive.Record{
+			ConversationID: conversation.Key(p.apiKey, coreauth.ConversationKey(p.headers, p.request)),
+			APIKey:         p.apiKey,
+			HandlerType:    p.handlerType,
+			Model:          p.model,
+			UpstreamModel:  p.route.Model,
+			Provider:       p.route.Provider,
+			AuthIndex:      p.route.AuthIndex,
+			Stream:         p.stream,
+			LatencyMS:      latency,
+			Request:        p.request,
+			Response:       cloneBytes(response),
+		})
+	}
+	if !p.sampled {
+		return
+	}
+	sample := sampling.Sample{
+		HandlerType:   p.handlerType,
+		Model:         p.model,
+		UpstreamModel: p.route.Model,
+		Provider:      p.route.Provider,
+		AuthIndex:     p.route.AuthIndex,
+		Stream:        p.stream,
+		LatencyMS:     latency,
+		Request:       p.request,
+		Response:      cloneBytes(response),
+	}
+	// Writing the sample and trimming old ones touch the disk, so they stay off the request path.
+	go func() {
+		if _, err := sampling.Default().Capture(sample); err != nil {
+			log.Warnf("sampling: failed to store sample for model %s: %v", sample.Model, err)
+		}
+	}()
+}
+
+// addChunk appends a streamed chunk to the sampled response, one chunk per line.
+func (p *pendingSample) addChunk(chunk []byte) {
+	if p == nil || len(chunk) == 0 {
+		return
+	}
+	p.streamed.Write(chunk)
+	if chunk[len(chunk)-1] != '\n' {
+		p.streamed.WriteByte('\n')
+	}
+}
+
+// finishStream stores the sample with the chunks collected by addChunk.
+func (p *pendingSample) finishStream() {
+	if p == nil {
+		return
+	}
+	p.finish(p.streamed.Bytes())
+}
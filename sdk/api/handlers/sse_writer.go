@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	sseDataPrefix = []byte("data:")
+	sseDoneMarker = []byte("[DONE]")
+)
+
+// SSEWriter owns Server-Sent Events framing for streaming handlers.
+// Translators return payloads either as bare JSON or as pre-framed event blocks, with or
+// without a "data:" prefix and trailing blank line; SSEWriter normalizes all of them so
+// every event on the wire has exactly one field prefix per line and ends with one blank line.
+type SSEWriter struct {
+	w       io.Writer
+	flusher http.Flusher
+	done    bool
+	// open is set while an event whose fields arrived without data awaits its data line.
+	open bool
+}
+
+// NewSSEWriter returns an SSEWriter writing to w. Flush is a no-op when w is not an http.Flusher.
+func NewSSEWriter(w io.Writer) *SSEWriter {
+	flusher, _ := w.(http.Flusher)
+	return &SSEWriter{w: w, flusher: flusher}
+}
+
+// SetSSEHeaders sets the response headers of an event stream.
+func SetSSEHeaders(c *gin.Context) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("Access-Control-Allow-Origin", "*")
+}
+
+// Data writes payload as a data-only event. A "data:" prefix already present on a line is
+// not repeated, multi-line payloads get one prefix per line, and empty payloads are dropped.
+// [DONE] markers are dropped as well: protocols terminated by [DONE] emit it through Done,
+// so a marker returned by a translator is never sent twice.
+func (s *SSEWriter) Data(payload []byte) {
+	payload = bytes.Trim(payload, "\r\n")
+	if len(bytes.TrimSpace(payload)) == 0 || isSSEDoneMarker(payload) {
+		return
+	}
+	var buf bytes.Buffer
+	open := false
+	for _, line := range bytes.Split(payload, []byte("\n")) {
+		line = bytes.TrimRight(line, "\r")
+		if len(line) == 0 {
+			// A blank line separates events that a translator returned in one chunk.
+			if open {
+				buf.WriteByte('\n')
+				open = false
+			}
+			continue
+		}
+		buf.WriteString("data: ")
+		buf.Write(trimSSEDataPrefix(line))
+		buf.WriteByte('\n')
+		open = true
+	}
+	buf.WriteByte('\n')
+	s.open = false
+	_, _ = s.w.Write(buf.Bytes())
+}
+
+// Event writes a pre-framed event block such as "event: x\ndata: {...}", which may hold
+// several events separated by blank lines. Lines that are not SSE fields are treated as
+// data. Upstream streams are often forwarded one line per chunk, so an event ends at a blank
+// line, before an "event:" line that follows data, or at the end of a block that wrote data;
+// a block holding only an "event:" line stays open for the data of the next block.
+func (s *SSEWriter) Event(block []byte) {
+	block = bytes.Trim(block, "\r\n")
+	if len(bytes.TrimSpace(block)) == 0 || isSSEDoneMarker(block) {
+		return
+	}
+	var buf bytes.Buffer
+	hasData := false
+	for _, line := range bytes.Split(block, []byte("\n")) {
+		line = bytes.TrimRight(line, "\r")
+		if len(line) == 0 {
+			if s.open {
+				buf.WriteByte('\n')
+				s.open, hasData = false, false
+			}
+			continue
+		}
+		if hasData && bytes.HasPrefix(line, []byte("event:")) {
+			buf.WriteByte('\n')
+			hasData = false
+		}
+		if bytes.HasPrefix(line, sseDataPrefix) || !isSSEField(line) {
+			buf.WriteString("data: ")
+			line = trimSSEDataPrefix(line)
+			hasData = true
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+		s.open = true
+	}
+	if hasData {
+		buf.WriteByte('\n')
+		s.open = false
+	}
+	_, _ = s.w.Write(buf.Bytes())
+}
+
+// closeOpen ends an event left open by Event before another event is written.
+func (s *SSEWriter) closeOpen() {
+	if s.open {
+		_, _ = io.WriteString(s.w, "\n")
+		s.open = false
+	}
+}
+
+// NamedEvent writes payload as a data event with the given event name.
+func (s *SSEWriter) NamedEvent(name string, payload []byte) {
+	s.closeOpen()
+	_, _ = io.WriteString(s.w, "event: "+name+"\n")
+	s.Data(payload)
+}
+
+// Comment writes an SSE comment line, which clients ignore; used for keep-alives.
+func (s *SSEWriter) Comment(text string) {
+	s.closeOpen()
+	_, _ = io.WriteString(s.w, ": "+text+"\n\n")
+}
+
+// Done writes the OpenAI-style "data: [DONE]" terminator. Repeated calls write it once.
+func (s *SSEWriter) Done() {
+	if s.done {
+		return
+	}
+	s.done = true
+	s.closeOpen()
+	_, _ = io.WriteString(s.w, "data: [DONE]\n\n")
+}
+
+// Flush sends buffered events to the client.
+func (s *SSEWriter) Flush() {
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+}
+
+func trimSSEDataPrefix(line []byte) []byte {
+	for bytes.HasPrefix(line, sseDataPrefix) {
+		line = bytes.TrimPrefix(line[len(sseDataPrefix):], []byte(" "))
+	}
+	return line
+}
+
+func isSSEDoneMarker(payload []byte) bool {
+	return bytes.Equal(trimSSEDataPrefix(bytes.TrimSpace(payload)), sseDoneMarker)
+}
+
+func isSSEField(line []byte) bool {
+	if line[0] == ':' {
+		return true
+	}
+	for _, field := range []string{"event:", "data:", "id:", "retry:"} {
+		if bytes.HasPrefix(line, []byte(field)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+// assertConformingSSE checks that stream consists of complete events: every line is a field
+// or a comment, no data line repeats its prefix, and the stream ends with a blank line.
+func assertConformingSSE(t *testing.T, stream string) {
+	t.Helper()
+	if stream == "" {
+		return
+	}
+	if !strings.HasSuffix(stream, "\n\n") {
+		t.Fatalf("stream does not end with a blank line: %q", stream)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(stream, "\n"), "\n") {
+		if line == "" || strings.HasPrefix(line, ":") {
+			continue
+		}
+		if !isSSEField([]byte(line)) {
+			t.Fatalf("line %q is not an SSE field in %q", line, stream)
+		}
+		if strings.HasPrefix(strings.TrimPrefix(line, "data: "), "data:") {
+			t.Fatalf("double data prefix in line %q", line)
+		}
+	}
+}
+
+func TestSSEWriterDataFraming(t *testing.T) {
+	cases := []struct {
+		name    string
+		payload string
+		want    string
+	}{
+		{"bare json", `{"a":1}`, "data: {\"a\":1}\n\n"},
+		{"prefixed", `data: {"a":1}`, "data: {\"a\":1}\n\n"},
+		{"prefixed without space", `data:{"a":1}`, "data: {\"a\":1}\n\n"},
+		{"double prefix", `data: data: {"a":1}`, "data: {\"a\":1}\n\n"},
+		{"trailing blank line", "data: {\"a\":1}\n\n", "data: {\"a\":1}\n\n"},
+		{"multi line", "line one\nline two", "data: line one\ndata: line two\n\n"},
+		{"several events", "data: {\"a\":1}\n\ndata: {\"b\":2}\n\n", "data: {\"a\":1}\n\ndata: {\"b\":2}\n\n"},
+		{"crlf", "{\"a\":1}\r\n", "data: {\"a\":1}\n\n"},
+		{"empty", "\n\n", ""},
+		{"done marker", "data: [DONE]", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			NewSSEWriter(rec).Data([]byte(tc.payload))
+			if got := rec.Body.String(); got != tc.want {
+				t.Fatalf("Data(%q) = %q, want %q", tc.payload, got, tc.want)
+			}
+			assertConformingSSE(t, rec.Body.String())
+		})
+	}
+}
+
+func TestSSEWriterEventFraming(t *testing.T) {
+	cases := []struct {
+		name  string
+		block string
+		want  string
+	}{
+		{"unterminated", "event: ping\ndata: {}", "event: ping\ndata: {}\n\n"},
+		{"terminated", "event: ping\ndata: {}\n\n", "event: ping\ndata: {}\n\n"},
+		{"leading newline", "\nevent: ping\ndata: {}\n", "event: ping\ndata: {}\n\n"},
+		{"several events", "event: a\ndata: 1\n\nevent: b\ndata: 2\n\n", "event: a\ndata: 1\n\nevent: b\ndata: 2\n\n"},
+		{"bare payload", `{"a":1}`, "data: {\"a\":1}\n\n"},
+		{"double prefix", "event: a\ndata: data: 1", "event: a\ndata: 1\n\n"},
+		{"comment", ": note\nevent: a\ndata: 1", ": note\nevent: a\ndata: 1\n\n"},
+		{"empty", "", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			NewSSEWriter(rec).Event([]byte(tc.block))
+			if got := rec.Body.String(); got != tc.want {
+				t.Fatalf("Event(%q) = %q, want %q", tc.block, got, tc.want)
+			}
+			assertConformingSSE(t, rec.Body.String())
+		})
+	}
+}
+
+func TestSSEWriterEventFramesLineSplitChunks(t *testing.T) {
+	rec := httptest.NewRecorder()
+	sse := NewSSEWriter(rec)
+	for _, chunk := range []string{
+		"event: message_start", `data: {"type":"message_start"}`, "",
+		"event: ping", `data: {"type":"ping"}`,
+		"event: message_stop\n", `data: {"type":"message_stop"}` + "\n",
+	} {
+		sse.Event([]byte(chunk))
+	}
+	sse.NamedEvent("error", []byte(`{}`))
+
+	want := "event: message_start\ndata: {\"type\":\"message_start\"}\n\n" +
+		"event: ping\ndata: {\"type\":\"ping\"}\n\n" +
+		"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n" +
+		"event: error\ndata: {}\n\n"
+	if got := rec.Body.String(); got != want {
+		t.Fatalf("stream = %q, want %q", got, want)
+	}
+	assertConformingSSE(t, rec.Body.String())
+}
+
+func TestSSEWriterControlEvents(t *testing.T) {
+	rec := httptest.NewRecorder()
+	sse := NewSSEWriter(rec)
+	sse.Data([]byte("[DONE]"))
+	sse.NamedEvent("error", []byte(`{"error":"x"}`))
+	sse.Comment("keep-alive")
+	sse.Done()
+	sse.Done()
+	sse.Flush()
+
+	want := "event: error\ndata: {\"error\":\"x\"}\n\n: keep-alive\n\ndata: [DONE]\n\n"
+	if got := rec.Body.String(); got != want {
+		t.Fatalf("stream = %q, want %q", got, want)
+	}
+	if !rec.Flushed {
+		t.Fatalf("Flush must reach the underlying http.Flusher")
+	}
+	assertConformingSSE(t, rec.Body.String())
+}
+
+func TestForwardStreamEmitsConformingEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+	data := make(chan []byte, 4)
+	errs := make(chan *interfaces.ErrorMessage)
+	data <- []byte(`{"id":"1"}`)
+	data <- []byte("data: {\"id\":\"2\"}\n\n")
+	data <- []byte("[DONE]")
+	close(data)
+
+	h := NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, nil)
+	interval := time.Hour
+	sse := NewSSEWriter(c.Writer)
+	h.ForwardStream(c, c.Writer, func(error) {}, data, errs, StreamForwardOptions{
+		KeepAliveInterval: &interval,
+		WriteChunk:        sse.Data,
+		WriteDone:         sse.Done,
+	})
+
+	want := "data: {\"id\":\"1\"}\n\ndata: {\"id\":\"2\"}\n\ndata: [DONE]\n\n"
+	if got := rec.Body.String(); got != want {
+		t.Fatalf("stream = %q, want %q", got, want)
+	}
+	assertConformingSSE(t, rec.Body.String())
+}
@@ -27,6 +27,10 @@ type StreamForwardOptions struct {
 	// WriteKeepAlive optionally writes a keep-alive heartbeat. It should not flush.
 	// When nil, a standard SSE comment heartbeat is used.
 	WriteKeepAlive func()
+
+	// Salvage, when set and the client key opted into partial salvage, ends the stream as
+	// truncated instead of writing a terminal error once enough output was sent.
+	Salvage StreamSalvager
 }
 
 func (h *BaseAPIHandler) ForwardStream(c *gin.Context, flusher http.Flusher, cancel func(error), data <-chan []byte, errs <-chan *interfaces.ErrorMessage, opts StreamForwardOptions) {
@@ -44,8 +48,9 @@ func (h *BaseAPIHandler) ForwardStream(c *gin.Context, flusher http.Flusher, can
 
 	writeKeepAlive := opts.WriteKeepAlive
 	if writeKeepAlive == nil {
+		sse := NewSSEWriter(c.Writer)
 		writeKeepAlive = func() {
-			_, _ = c.Writer.Write([]byte(": keep-alive\n\n"))
+			sse.Comment("keep-alive")
 		}
 	}
 
@@ -61,6 +66,11 @@ func (h *BaseAPIHandler) ForwardStream(c *gin.Context, flusher http.Flusher, can
 		keepAliveC = keepAlive.C
 	}
 
+	salvage := opts.Salvage
+	if salvage != nil && !h.partialSalvageEnabled(c) {
+		salvage = nil
+	}
+
 	var terminalErr *interfaces.ErrorMessage
 	for {
 		select {
@@ -80,6 +90,11 @@ func (h *BaseAPIHandler) ForwardStream(c *gin.Context, flusher http.Flusher, can
 					}
 				}
 				if terminalErr != nil {
+					if h.salvageStream(c, salvage, terminalErr) {
+						flusher.Flush()
+						cancel(terminalErr.Error)
+						return
+					}
 					if opts.WriteTerminalError != nil {
 						opts.WriteTerminalError(terminalErr)
 					}
@@ -90,16 +105,25 @@ func (h *BaseAPIHandler) ForwardStream(c *gin.Context, flusher http.Flusher, can
 				if opts.WriteDone != nil {
 					opts.WriteDone()
 				}
+				writeProvenanceComment(c)
 				flusher.Flush()
 				cancel(nil)
 				return
 			}
 			writeChunk(chunk)
+			if salvage != nil {
+				salvage.Observe(chunk)
+			}
 			flusher.Flush()
 		case errMsg, ok := <-errs:
 			if !ok {
 				continue
 			}
+			if errMsg != nil && h.salvageStream(c, salvage, errMsg) {
+				flusher.Flush()
+				cancel(errMsg.Error)
+				return
+			}
 			if errMsg != nil {
 				terminalErr = errMsg
 				if opts.WriteTerminalError != nil {
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/i18n"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+// toleratedFields are accepted for every provider even when not forwarded: they carry routing,
+// bookkeeping or output limits the upstream enforces on its own, not content semantics.
+var toleratedFields = map[string][]string{
+	constant.OpenAI: {"model", "messages", "stream", "stream_options", "user", "metadata", "store", "service_tier", "max_tokens", "max_completion_tokens"},
+	constant.Claude: {"model", "messages", "stream", "metadata", "max_tokens"},
+}
+
+// honoredFields lists, per ingress format and upstream provider class, the top-level request
+// fields the translators carry over. Native passthrough targets are not listed; they honor
+// everything.
+var honoredFields = map[string]map[string][]string{
+	constant.OpenAI: {
+		"claude":      {"stop", "temperature", "top_p", "tools", "tool_choice", "reasoning_effort"},
+		"codex":       {"tools", "tool_choice", "reasoning_effort", "response_format", "text"},
+		"gemini":      {"temperature", "top_p", "top_k", "tools", "reasoning_effort", "modalities", "image_config", "extra_body"},
+		"antigravity": {"temperature", "top_p", "top_k", "tools", "reasoning_effort", "modalities", "image_config", "extra_body", "thinking"},
+		"kiro":        {"temperature", "top_p", "tools", "tool_choice", "parallel_tool_calls", "reasoning_effort", "response_format"},
+	},
+	constant.Claude: {
+		"openai":      {"system", "stop_sequences", "temperature", "top_p", "thinking", "tools", "tool_choice", "user"},
+		"codex":       {"system", "thinking", "tools"},
+		"gemini":      {"system", "temperature", "top_p", "top_k", "thinking", "tools"},
+		"antigravity": {"system", "temperature", "top_p", "top_k", "thinking", "tools"},
+	},
+}
+
+// providerClass maps a provider name to the upstream format its executor translates into.
+// Unknown providers are OpenAI-compatible endpoints.
+func providerClass(provider string) string {
+	switch provider {
+	case "claude", "codex", "kiro", "antigravity":
+		return provider
+	case "gemini", "gemini-cli", "vertex", "aistudio":
+		return "gemini"
+	default:
+		return "openai"
+	}
+}
+
+// applyStrictFields rejects requests with top-level fields that a candidate provider would drop
+// during translation. Only OpenAI chat completions and Claude messages ingress are checked.
+func applyStrictFields(cfg *config.SDKConfig, handlerType string, rawJSON []byte, providers []string) *interfaces.ErrorMessage {
+	if cfg == nil || !cfg.StrictRequestFields {
+		return nil
+	}
+	targets, ok := honoredFields[handlerType]
+	if !ok {
+		return nil
+	}
+	dropped := make(map[string][]string)
+	root := gjson.ParseBytes(rawJSON)
+	for _, provider := range providers {
+		honored, translated := targets[providerClass(provider)]
+		if !translated {
+			continue
+		}
+		root.ForEach(func(key, _ gjson.Result) bool {
+			field := key.String()
+			if !slices.Contains(toleratedFields[handlerType], field) && !slices.Contains(honored, field) && !slices.Contains(dropped[field], provider) {
+				dropped[field] = append(dropped[field], provider)
+			}
+			return true
+		})
+	}
+	if len(dropped) == 0 {
+		return nil
+	}
+	fields := make([]string, 0, len(dropped))
+	for field, by := range dropped {
+		fields = append(fields, fmt.Sprintf("%s (%s)", field, strings.Join(by, ", ")))
+	}
+	sort.Strings(fields)
+	return &interfaces.ErrorMessage{
+		StatusCode: http.StatusBadRequest,
+		Error:      i18n.Errorf(i18n.MsgIgnoredFields, strings.Join(fields, "; ")),
+	}
+}
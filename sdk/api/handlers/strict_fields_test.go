@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func TestApplyStrictFields(t *testing.T) {
+	cfg := &config.SDKConfig{StrictRequestFields: true}
+	body := []byte(`{"model":"m","messages":[],"temperature":0.2,"logit_bias":{"1":5},"modalities":["text"]}`)
+
+	if errMsg := applyStrictFields(&config.SDKConfig{}, constant.OpenAI, body, []string{"claude"}); errMsg != nil {
+		t.Fatalf("strict mode must be disabled by default, got %v", errMsg)
+	}
+
+	errMsg := applyStrictFields(cfg, constant.OpenAI, body, []string{"claude"})
+	if errMsg == nil || errMsg.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %+v", errMsg)
+	}
+	msg := errMsg.Error.Error()
+	if !strings.Contains(msg, "logit_bias (claude)") || !strings.Contains(msg, "modalities (claude)") || strings.Contains(msg, "temperature") {
+		t.Fatalf("unexpected error message: %s", msg)
+	}
+
+	if errMsg = applyStrictFields(cfg, constant.OpenAI, body, []string{"openai-compatibility"}); errMsg != nil {
+		t.Fatalf("native passthrough must not be checked, got %v", errMsg)
+	}
+
+	gemini := []byte(`{"model":"m","messages":[],"modalities":["text","image"],"max_tokens":10}`)
+	if errMsg = applyStrictFields(cfg, constant.OpenAI, gemini, []string{"gemini"}); errMsg != nil {
+		t.Fatalf("gemini honors modalities, got %v", errMsg)
+	}
+
+	codex := []byte(`{"model":"m","messages":[],"temperature":0.2,"tools":[]}`)
+	errMsg = applyStrictFields(cfg, constant.OpenAI, codex, []string{"codex"})
+	if errMsg == nil || !strings.Contains(errMsg.Error.Error(), "temperature (codex)") || strings.Contains(errMsg.Error.Error(), "tools") {
+		t.Fatalf("codex drops temperature, got %+v", errMsg)
+	}
+}
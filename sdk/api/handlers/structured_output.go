@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+// structuredStreamValidator checks streamed output of a request asking for a JSON response_format.
+// Chunks are held back until the output text starts as JSON, so a non-JSON answer can still be
+// reported as a plain error response, and the stream is aborted as soon as the text stops being a
+// valid JSON prefix.
+type structuredStreamValidator struct {
+	handlerType string
+	json        jsonPrefixValidator
+	pending     [][]byte
+	confirmed   bool
+}
+
+// newStructuredStreamValidator returns a validator for the request, or nil when validation is
+// disabled or the request does not ask for JSON output.
+func newStructuredStreamValidator(cfg *config.SDKConfig, handlerType string, rawJSON []byte) *structuredStreamValidator {
+	if cfg == nil || !cfg.StructuredOutputValidation {
+		return nil
+	}
+	var format string
+	switch handlerType {
+	case constant.OpenAI:
+		format = gjson.GetBytes(rawJSON, "response_format.type").String()
+	case constant.OpenaiResponse:
+		format = gjson.GetBytes(rawJSON, "text.format.type").String()
+	default:
+		return nil
+	}
+	if format != "json_schema" && format != "json_object" {
+		return nil
+	}
+	return &structuredStreamValidator{handlerType: handlerType}
+}
+
+// push validates chunk and returns the chunks that may be forwarded to the client.
+func (v *structuredStreamValidator) push(chunk []byte) ([][]byte, error) {
+	text := v.outputText(chunk)
+	if !v.confirmed && len(v.pending) == 0 && text == "" {
+		return [][]byte{chunk}, nil
+	}
+	if err := v.json.feed(text); err != nil {
+		return nil, err
+	}
+	if v.confirmed {
+		return [][]byte{chunk}, nil
+	}
+	v.pending = append(v.pending, chunk)
+	if !v.json.started {
+		return nil, nil
+	}
+	v.confirmed = true
+	return v.flush(), nil
+}
+
+// flush returns and clears the chunks held back so far.
+func (v *structuredStreamValidator) flush() [][]byte {
+	out := v.pending
+	v.pending = nil
+	return out
+}
+
+// reset discards all state, for a stream restarted before any chunk was forwarded.
+func (v *structuredStreamValidator) reset() {
+	*v = structuredStreamValidator{handlerType: v.handlerType}
+}
+
+// outputText extracts the model output text carried by a chunk in the handler's format.
+func (v *structuredStreamValidator) outputText(chunk []byte) string {
+	var text strings.Builder
+	for _, line := range bytes.Split(chunk, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if bytes.HasPrefix(line, []byte("event:")) {
+			continue
+		}
+		line = bytes.TrimSpace(bytes.TrimPrefix(line, []byte("data:")))
+		if len(line) == 0 || !gjson.ValidBytes(line) {
+			continue
+		}
+		switch v.handlerType {
+		case constant.OpenAI:
+			text.WriteString(gjson.GetBytes(line, "choices.0.delta.content").String())
+		case constant.OpenaiResponse:
+			if gjson.GetBytes(line, "type").String() == "response.output_text.delta" {
+				text.WriteString(gjson.GetBytes(line, "delta").String())
+			}
+		}
+	}
+	return text.String()
+}
+
+const (
+	jsonExpectValue = iota
+	jsonExpectValueOrArrayEnd
+	jsonExpectKeyOrObjectEnd
+	jsonExpectKey
+	jsonExpectColon
+	jsonAfterValue
+	jsonInString
+	jsonInStringEscape
+	jsonInStringUnicode
+	jsonInNumber
+	jsonInLiteral
+	jsonDone
+)
+
+// jsonPrefixValidator checks incrementally that the text fed so far is a prefix of a single JSON
+// value. Numbers are checked loosely; everything else follows the JSON grammar.
+type jsonPrefixValidator struct {
+	state   int
+	stack   []byte
+	isKey   bool
+	hex     int
+	literal string
+	offset  int
+	// started is set once the first byte of the value has been accepted.
+	started bool
+}
+
+func (p *jsonPrefixValidator) feed(text string) error {
+	for i := 0; i < len(text); i++ {
+		if err := p.step(text[i]); err != nil {
+			return err
+		}
+		p.offset++
+	}
+	return nil
+}
+
+func (p *jsonPrefixValidator) fail(c byte) error {
+	return fmt.Errorf("structured output validation failed: model output is not valid JSON (unexpected %q at offset %d)", c, p.offset)
+}
+
+func isJSONSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func (p *jsonPrefixValidator) step(c byte) error {
+	switch p.state {
+	case jsonInString:
+		switch {
+		case c == '\\':
+			p.state = jsonInStringEscape
+		case c == '"':
+			if p.isKey {
+				p.state = jsonExpectColon
+			} else {
+				p.endValue()
+			}
+		case c < 0x20:
+			return p.fail(c)
+		}
+		return nil
+	case jsonInStringEscape:
+		switch c {
+		case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+			p.state = jsonInString
+		case 'u':
+			p.state, p.hex = jsonInStringUnicode, 0
+		default:
+			return p.fail(c)
+		}
+		return nil
+	case jsonInStringUnicode:
+		if !strings.ContainsRune("0123456789abcdefABCDEF", rune(c)) {
+			return p.fail(c)
+		}
+		if p.hex++; p.hex == 4 {
+			p.state = jsonInString
+		}
+		return nil
+	case jsonInNumber:
+		if strings.ContainsRune("0123456789.eE+-", rune(c)) {
+			return nil
+		}
+		p.endValue()
+		return p.step(c)
+	case jsonInLiteral:
+		if c != p.literal[0] {
+			return p.fail(c)
+		}
+		if p.literal = p.literal[1:]; p.literal == "" {
+			p.endValue()
+		}
+		return nil
+	}
+
+	if isJSONSpace(c) {
+		return nil
+	}
+	switch p.state {
+	case jsonExpectValueOrArrayEnd:
+		if c == ']' {
+			p.stack = p.stack[:len(p.stack)-1]
+			p.endValue()
+			return nil
+		}
+		return p.startValue(c)
+	case jsonExpectValue:
+		return p.startValue(c)
+	case jsonExpectKeyOrObjectEnd, jsonExpectKey:
+		if c == '}' && p.state == jsonExpectKeyOrObjectEnd {
+			p.stack = p.stack[:len(p.stack)-1]
+			p.endValue()
+			return nil
+		}
+		if c != '"' {
+			return p.fail(c)
+		}
+		p.state, p.isKey = jsonInString, true
+		return nil
+	case jsonExpectColon:
+		if c != ':' {
+			return p.fail(c)
+		}
+		p.state = jsonExpectValue
+		return nil
+	case jsonAfterValue:
+		top := p.stack[len(p.stack)-1]
+		switch {
+		case c == ',' && top == '{':
+			p.state = jsonExpectKey
+		case c == ',' && top == '[':
+			p.state = jsonExpectValue
+		case c == '}' && top == '{', c == ']' && top == '[':
+			p.stack = p.stack[:len(p.stack)-1]
+			p.endValue()
+		default:
+			return p.fail(c)
+		}
+		return nil
+	}
+	// jsonDone: only whitespace may follow the value.
+	return p.fail(c)
+}
+
+func (p *jsonPrefixValidator) startValue(c byte) error {
+	p.started = true
+	switch {
+	case c == '{':
+		p.stack = append(p.stack, '{')
+		p.state = jsonExpectKeyOrObjectEnd
+	case c == '[':
+		p.stack = append(p.stack, '[')
+		p.state = jsonExpectValueOrArrayEnd
+	case c == '"':
+		p.state, p.isKey = jsonInString, false
+	case c == '-' || (c >= '0' && c <= '9'):
+		p.state = jsonInNumber
+	case c == 't':
+		p.state, p.literal = jsonInLiteral, "rue"
+	case c == 'f':
+		p.state, p.literal = jsonInLiteral, "alse"
+	case c == 'n':
+		p.state, p.literal = jsonInLiteral, "ull"
+	default:
+		return p.fail(c)
+	}
+	return nil
+}
+
+func (p *jsonPrefixValidator) endValue() {
+	if len(p.stack) == 0 {
+		p.state = jsonDone
+		return
+	}
+	p.state = jsonAfterValue
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func TestJSONPrefixValidator(t *testing.T) {
+	valid := []string{
+		`{"a": [1, -2.5e3, true, false, null, "x\"é"], "b": {}}`,
+		`  [ ]  `,
+		`"just a string"`,
+		`{"partial": [1, 2`,
+	}
+	for _, input := range valid {
+		var p jsonPrefixValidator
+		// Feed one byte at a time to exercise chunk boundaries.
+		for i := 0; i < len(input); i++ {
+			if err := p.feed(input[i : i+1]); err != nil {
+				t.Fatalf("feed(%q) failed at %d: %v", input, i, err)
+			}
+		}
+	}
+
+	invalid := []string{
+		"Sure! Here is the JSON:",
+		"```json\n{}",
+		`{"a" 1}`,
+		`{"a": tru }`,
+		`[1, 2]]`,
+		`{"a": 1} trailing`,
+		`{a: 1}`,
+	}
+	for _, input := range invalid {
+		var p jsonPrefixValidator
+		if err := p.feed(input); err == nil {
+			t.Errorf("feed(%q) succeeded, want error", input)
+		}
+	}
+}
+
+func TestStructuredStreamValidatorBuffersAndAborts(t *testing.T) {
+	cfg := &config.SDKConfig{StructuredOutputValidation: true}
+	request := []byte(`{"response_format": {"type": "json_schema", "json_schema": {"name": "x", "schema": {}}}}`)
+
+	if v := newStructuredStreamValidator(&config.SDKConfig{}, constant.OpenAI, request); v != nil {
+		t.Fatal("validator must be disabled by default")
+	}
+	if v := newStructuredStreamValidator(cfg, constant.OpenAI, []byte(`{}`)); v != nil {
+		t.Fatal("validator must only apply to JSON response formats")
+	}
+
+	v := newStructuredStreamValidator(cfg, constant.OpenAI, request)
+	chunk := func(content string) []byte {
+		return []byte(`{"choices":[{"delta":{"content":` + strconv.Quote(content) + `}}]}`)
+	}
+
+	if out, err := v.push([]byte(`{"choices":[{"delta":{"role":"assistant"}}]}`)); err != nil || len(out) != 1 {
+		t.Fatalf("chunk without text should pass through, got %d chunks, err %v", len(out), err)
+	}
+	if out, err := v.push(chunk("  \n")); err != nil || len(out) != 0 {
+		t.Fatalf("whitespace should be held back, got %d chunks, err %v", len(out), err)
+	}
+	if out, err := v.push(chunk(`{"name"`)); err != nil || len(out) != 2 {
+		t.Fatalf("JSON start should release held chunks, got %d chunks, err %v", len(out), err)
+	}
+	if out, err := v.push(chunk(`: "ok"}`)); err != nil || len(out) != 1 {
+		t.Fatalf("valid continuation should pass, got %d chunks, err %v", len(out), err)
+	}
+	if _, err := v.push(chunk(" and more")); err == nil || !strings.Contains(err.Error(), "not valid JSON") {
+		t.Fatalf("divergence should abort, got %v", err)
+	}
+
+	v = newStructuredStreamValidator(cfg, constant.OpenaiResponse, []byte(`{"text": {"format": {"type": "json_schema"}}}`))
+	if _, err := v.push([]byte("event: response.output_text.delta\ndata: {\"type\":\"response.output_text.delta\",\"delta\":\"I cannot\"}")); err == nil {
+		t.Fatal("non-JSON responses output should abort")
+	}
+}
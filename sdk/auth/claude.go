@@ -84,6 +84,7 @@ func (a *ClaudeAuthenticator) Login(ctx context.Context, cfg *config.Config, opt
 		return nil, fmt.Errorf("claude authorization url generation failed: %w", err)
 	}
 	state = returnedState
+	SavePendingLogin(cfg, PendingLogin{Provider: "claude", State: state, CodeVerifier: pkceCodes.CodeVerifier, CodeChallenge: pkceCodes.CodeChallenge})
 
 	if !opts.NoBrowser {
 		fmt.Println("Opening browser for Claude authentication")
@@ -108,7 +109,7 @@ func (a *ClaudeAuthenticator) Login(ctx context.Context, cfg *config.Config, opt
 	manualDescription := ""
 
 	go func() {
-		result, errWait := oauthServer.WaitForCallback(5 * time.Minute)
+		result, errWait := oauthServer.WaitForCallback(cfg.LoginTimeout(a.Provider(), defaultLoginTimeout))
 		if errWait != nil {
 			callbackErrCh <- errWait
 			return
@@ -181,7 +182,13 @@ waitForCallback:
 
 	log.Debug("Claude authorization code received; exchanging for tokens")
 
-	authBundle, err := authSvc.ExchangeCodeForTokens(ctx, result.Code, state, pkceCodes)
+	RemovePendingLogin(cfg, state)
+	return claudeAuthRecord(ctx, authSvc, result.Code, state, pkceCodes)
+}
+
+// claudeAuthRecord exchanges the authorization code and builds the auth record of the account.
+func claudeAuthRecord(ctx context.Context, authSvc *claude.ClaudeAuth, code, state string, pkceCodes *claude.PKCECodes) (*coreauth.Auth, error) {
+	authBundle, err := authSvc.ExchangeCodeForTokens(ctx, code, state, pkceCodes)
 	if err != nil {
 		return nil, claude.NewAuthenticationError(claude.ErrCodeExchangeFailed, err)
 	}
@@ -204,7 +211,7 @@ waitForCallback:
 
 	return &coreauth.Auth{
 		ID:       fileName,
-		Provider: a.Provider(),
+		Provider: "claude",
 		FileName: fileName,
 		Storage:  tokenStorage,
 		Metadata: metadata,
@@ -11,6 +11,7 @@ import (
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/browser"
 	// legacy client removed
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/loginui"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
@@ -83,31 +84,34 @@ func (a *CodexAuthenticator) Login(ctx context.Context, cfg *config.Config, opts
 	if err != nil {
 		return nil, fmt.Errorf("codex authorization url generation failed: %w", err)
 	}
+	SavePendingLogin(cfg, PendingLogin{Provider: "codex", State: state, CodeVerifier: pkceCodes.CodeVerifier, CodeChallenge: pkceCodes.CodeChallenge})
 
+	progress := loginui.FromContext(ctx)
+	manualURL := func() {
+		progress.Info(util.SSHTunnelInstructions(callbackPort)...)
+		progress.Action("Open this URL to continue authentication", authURL)
+	}
 	if !opts.NoBrowser {
-		fmt.Println("Opening browser for Codex authentication")
+		progress.Step("Opening browser for Codex authentication")
 		if !browser.IsAvailable() {
-			log.Warn("No browser available; please open the URL manually")
-			util.PrintSSHTunnelInstructions(callbackPort)
-			fmt.Printf("Visit the following URL to continue authentication:\n%s\n", authURL)
+			progress.Warn("No browser available; please open the URL manually")
+			manualURL()
 		} else if err = browser.OpenURL(authURL); err != nil {
-			log.Warnf("Failed to open browser automatically: %v", err)
-			util.PrintSSHTunnelInstructions(callbackPort)
-			fmt.Printf("Visit the following URL to continue authentication:\n%s\n", authURL)
+			progress.Warn("Failed to open browser automatically: %v", err)
+			manualURL()
 		}
 	} else {
-		util.PrintSSHTunnelInstructions(callbackPort)
-		fmt.Printf("Visit the following URL to continue authentication:\n%s\n", authURL)
+		manualURL()
 	}
 
-	fmt.Println("Waiting for Codex authentication callback...")
+	progress.Wait("Waiting for Codex authentication callback...")
 
 	callbackCh := make(chan *codex.OAuthResult, 1)
 	callbackErrCh := make(chan error, 1)
 	manualDescription := ""
 
 	go func() {
-		result, errWait := oauthServer.WaitForCallback(5 * time.Minute)
+		result, errWait := oauthServer.WaitForCallback(cfg.LoginTimeout(a.Provider(), defaultLoginTimeout))
 		if errWait != nil {
 			callbackErrCh <- errWait
 			return
@@ -118,7 +122,8 @@ func (a *CodexAuthenticator) Login(ctx context.Context, cfg *config.Config, opts
 	var result *codex.OAuthResult
 	var manualPromptTimer *time.Timer
 	var manualPromptC <-chan time.Time
-	if opts.Prompt != nil {
+	prompt := progress.WrapPrompt(opts.Prompt)
+	if prompt != nil {
 		manualPromptTimer = time.NewTimer(15 * time.Second)
 		manualPromptC = manualPromptTimer.C
 		defer manualPromptTimer.Stop()
@@ -149,7 +154,7 @@ waitForCallback:
 				return nil, err
 			default:
 			}
-			input, errPrompt := opts.Prompt("Paste the Codex callback URL (or press Enter to keep waiting): ")
+			input, errPrompt := prompt("Paste the Codex callback URL (or press Enter to keep waiting): ")
 			if errPrompt != nil {
 				return nil, errPrompt
 			}
@@ -180,7 +185,15 @@ waitForCallback:
 
 	log.Debug("Codex authorization code received; exchanging for tokens")
 
-	authBundle, err := authSvc.ExchangeCodeForTokens(ctx, result.Code, pkceCodes)
+	RemovePendingLogin(cfg, state)
+	return codexAuthRecord(ctx, authSvc, result.Code, pkceCodes)
+}
+
+// codexAuthRecord exchanges the authorization code and builds the auth record of the account.
+func codexAuthRecord(ctx context.Context, authSvc *codex.CodexAuth, code string, pkceCodes *codex.PKCECodes) (*coreauth.Auth, error) {
+	progress := loginui.FromContext(ctx)
+	progress.Step("Exchanging authorization code for tokens")
+	authBundle, err := authSvc.ExchangeCodeForTokens(ctx, code, pkceCodes)
 	if err != nil {
 		return nil, codex.NewAuthenticationError(codex.ErrCodeExchangeFailed, err)
 	}
@@ -196,14 +209,14 @@ waitForCallback:
 		"email": tokenStorage.Email,
 	}
 
-	fmt.Println("Codex authentication successful")
+	progress.Step("Codex tokens received")
 	if authBundle.APIKey != "" {
-		fmt.Println("Codex API key obtained and stored")
+		progress.Step("Codex API key obtained and stored")
 	}
 
 	return &coreauth.Auth{
 		ID:       fileName,
-		Provider: a.Provider(),
+		Provider: "codex",
 		FileName: fileName,
 		Storage:  tokenStorage,
 		Metadata: metadata,
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	kiroauth "github.com/router-for-me/CLIProxyAPI/v6/internal/auth/kiro"
+)
+
+// Native file formats ExportCredential can write.
+const (
+	ExportFormatKiroIDE   = "kiro-ide"
+	ExportFormatGeminiCLI = "gemini-cli"
+)
+
+// ExportFormats lists the native formats credentials can be exported to.
+func ExportFormats() []string {
+	return []string{ExportFormatKiroIDE, ExportFormatGeminiCLI}
+}
+
+// DefaultExportPath returns where the native tool of format reads its credentials.
+func DefaultExportPath(format string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	switch format {
+	case ExportFormatKiroIDE:
+		if override := strings.TrimSpace(os.Getenv("KIRO_TOKEN_FILE")); override != "" {
+			return kiroauth.ExpandKiroTokenPath(override)
+		}
+		return filepath.Join(homeDir, kiroauth.KiroIDETokenFile), nil
+	case ExportFormatGeminiCLI:
+		return filepath.Join(homeDir, ".gemini", "oauth_creds.json"), nil
+	default:
+		return "", fmt.Errorf("unknown export format %q (supported: %s)", format, strings.Join(ExportFormats(), ", "))
+	}
+}
+
+// ExportCredential converts the metadata of a stored auth file into the credentials file of the
+// native tool of format.
+func ExportCredential(metadata map[string]any, format string) ([]byte, error) {
+	typ, _ := metadata["type"].(string)
+	switch format {
+	case ExportFormatKiroIDE:
+		if typ != "kiro" {
+			return nil, fmt.Errorf("%s export needs a kiro credential, got %q", format, typ)
+		}
+		return exportKiroIDE(metadata)
+	case ExportFormatGeminiCLI:
+		if typ != "gemini" {
+			return nil, fmt.Errorf("%s export needs a gemini credential, got %q", format, typ)
+		}
+		return exportGeminiCLI(metadata)
+	default:
+		return nil, fmt.Errorf("unknown export format %q (supported: %s)", format, strings.Join(ExportFormats(), ", "))
+	}
+}
+
+func exportKiroIDE(metadata map[string]any) ([]byte, error) {
+	str := func(key string) string {
+		value, _ := metadata[key].(string)
+		return value
+	}
+	token := kiroauth.KiroTokenData{
+		AccessToken:  str("access_token"),
+		RefreshToken: str("refresh_token"),
+		ProfileArn:   str("profile_arn"),
+		ExpiresAt:    str("expires_at"),
+		AuthMethod:   str("auth_method"),
+		Provider:     str("provider"),
+		ClientID:     str("client_id"),
+		ClientSecret: str("client_secret"),
+		Email:        str("email"),
+		StartURL:     str("start_url"),
+		Region:       str("region"),
+	}
+	if token.RefreshToken == "" {
+		return nil, fmt.Errorf("kiro credential has no refresh token")
+	}
+	return json.MarshalIndent(token, "", "  ")
+}
+
+func exportGeminiCLI(metadata map[string]any) ([]byte, error) {
+	token, _ := metadata["token"].(map[string]any)
+	refreshToken, _ := token["refresh_token"].(string)
+	if refreshToken == "" {
+		return nil, fmt.Errorf("gemini credential has no refresh token")
+	}
+	creds := map[string]any{
+		"access_token":  token["access_token"],
+		"refresh_token": refreshToken,
+		"token_type":    token["token_type"],
+	}
+	if expiry, ok := token["expiry"].(string); ok {
+		if parsed, err := time.Parse(time.RFC3339, expiry); err == nil {
+			creds["expiry_date"] = parsed.UnixMilli()
+		}
+	}
+	if scopes, ok := token["scopes"].([]any); ok {
+		parts := make([]string, 0, len(scopes))
+		for _, scope := range scopes {
+			if s, okScope := scope.(string); okScope {
+				parts = append(parts, s)
+			}
+		}
+		creds["scope"] = strings.Join(parts, " ")
+	}
+	return json.MarshalIndent(creds, "", "  ")
+}
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestExportKiroIDE(t *testing.T) {
+	metadata := map[string]any{
+		"type":          "kiro",
+		"access_token":  "at",
+		"refresh_token": "rt",
+		"profile_arn":   "arn:aws:codewhisperer:us-east-1:1:profile/x",
+		"expires_at":    "2025-01-01T00:00:00Z",
+		"auth_method":   "social",
+		"provider":      "Google",
+	}
+	data, err := ExportCredential(metadata, ExportFormatKiroIDE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err = json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["accessToken"] != "at" || got["refreshToken"] != "rt" || got["profileArn"] != metadata["profile_arn"] ||
+		got["expiresAt"] != "2025-01-01T00:00:00Z" || got["authMethod"] != "social" || got["provider"] != "Google" {
+		t.Fatalf("unexpected Kiro IDE token: %s", data)
+	}
+
+	if _, err = ExportCredential(map[string]any{"type": "gemini"}, ExportFormatKiroIDE); err == nil {
+		t.Fatal("exporting another provider must fail")
+	}
+}
+
+func TestExportGeminiCLIRoundTrip(t *testing.T) {
+	metadata := map[string]any{
+		"type": "gemini",
+		"token": map[string]any{
+			"access_token":  "at",
+			"refresh_token": "rt",
+			"token_type":    "Bearer",
+			"expiry":        "2023-11-14T22:13:20Z",
+			"scopes":        []any{"a", "b"},
+		},
+	}
+	data, err := ExportCredential(metadata, ExportFormatGeminiCLI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err = json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["refresh_token"] != "rt" || got["scope"] != "a b" || got["expiry_date"] != float64(1700000000000) {
+		t.Fatalf("unexpected Gemini CLI credentials: %s", data)
+	}
+
+	record, err := importGeminiCLI(data, ImportOptions{ProjectID: "proj"})
+	if err != nil {
+		t.Fatalf("exported credentials must import again: %v", err)
+	}
+	if record.Metadata["project_id"] != "proj" {
+		t.Fatalf("unexpected record: %+v", record)
+	}
+}
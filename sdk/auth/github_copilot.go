@@ -3,16 +3,25 @@ package auth
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/copilot"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/browser"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/loginui"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	log "github.com/sirupsen/logrus"
 )
 
-// GitHubCopilotAuthenticator implements the OAuth device flow login for GitHub Copilot.
+// GitHubCopilotTokenEnv names the environment variable holding a pre-provisioned GitHub token
+// for logins that cannot complete the device flow.
+const GitHubCopilotTokenEnv = "GITHUB_COPILOT_TOKEN"
+
+// GitHubCopilotAuthenticator implements the OAuth device flow login for GitHub Copilot, with a
+// pre-provisioned token login for non-interactive environments.
 type GitHubCopilotAuthenticator struct{}
 
 // NewGitHubCopilotAuthenticator constructs a new GitHub Copilot authenticator.
@@ -31,7 +40,8 @@ func (GitHubCopilotAuthenticator) RefreshLead() *time.Duration {
 	return nil
 }
 
-// Login initiates the GitHub device flow authentication for Copilot access.
+// Login initiates the GitHub device flow authentication for Copilot access. When a GitHub token
+// is provided through the github_token metadata or GitHubCopilotTokenEnv, it is used instead.
 func (a GitHubCopilotAuthenticator) Login(ctx context.Context, cfg *config.Config, opts *LoginOptions) (*coreauth.Auth, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("cliproxy auth: configuration is required")
@@ -40,30 +50,47 @@ func (a GitHubCopilotAuthenticator) Login(ctx context.Context, cfg *config.Confi
 		opts = &LoginOptions{}
 	}
 
-	authSvc := copilot.NewCopilotAuth(cfg)
+	authSvc := copilot.NewCopilotAuthWithBaseURL(cfg, opts.Metadata["github_base_url"])
+
+	// A pre-provisioned token skips the device flow, for environments without a browser or user.
+	if token := gitHubCopilotLoginToken(opts); token != "" {
+		return a.loginWithToken(ctx, authSvc, token, strings.TrimSpace(opts.Metadata["github_org"]))
+	}
+
+	progress := loginui.FromContext(ctx)
+	organization := gitHubCopilotOrganization(ctx, opts)
 
 	// Start the device flow
-	fmt.Println("Starting GitHub Copilot authentication...")
-	deviceCode, err := authSvc.StartDeviceFlow(ctx)
+	if base := authSvc.GitHubBaseURL(); base != "" {
+		progress.Step("Starting GitHub device flow against %s", base)
+	} else {
+		progress.Step("Starting GitHub device flow")
+	}
+	var deviceCode *copilot.DeviceCodeResponse
+	var err error
+	if organization != "" {
+		deviceCode, err = authSvc.StartOrganizationDeviceFlow(ctx)
+	} else {
+		deviceCode, err = authSvc.StartDeviceFlow(ctx)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("github-copilot: failed to start device flow: %w", err)
 	}
 
 	// Display the user code and verification URL
-	fmt.Printf("\nTo authenticate, please visit: %s\n", deviceCode.VerificationURI)
-	fmt.Printf("And enter the code: %s\n\n", deviceCode.UserCode)
+	progress.Action("Visit", deviceCode.VerificationURI)
+	progress.Action("Enter the code", deviceCode.UserCode)
 
 	// Try to open the browser automatically
 	if !opts.NoBrowser {
 		if browser.IsAvailable() {
 			if errOpen := browser.OpenURL(deviceCode.VerificationURI); errOpen != nil {
-				log.Warnf("Failed to open browser automatically: %v", errOpen)
+				progress.Warn("Failed to open browser automatically: %v", errOpen)
 			}
 		}
 	}
 
-	fmt.Println("Waiting for GitHub authorization...")
-	fmt.Printf("(This will timeout in %d seconds if not authorized)\n", deviceCode.ExpiresIn)
+	progress.Wait("Waiting for GitHub authorization (times out in %d seconds)...", deviceCode.ExpiresIn)
 
 	// Wait for user authorization
 	authBundle, err := authSvc.WaitForAuthorization(ctx, deviceCode)
@@ -71,14 +98,55 @@ func (a GitHubCopilotAuthenticator) Login(ctx context.Context, cfg *config.Confi
 		errMsg := copilot.GetUserFriendlyMessage(err)
 		return nil, fmt.Errorf("github-copilot: %s", errMsg)
 	}
+	if organization != "" {
+		progress.Step("Verifying membership in %s", organization)
+		if authBundle.Organization, err = authSvc.VerifyOrganization(ctx, authBundle.TokenData.AccessToken, organization); err != nil {
+			return nil, fmt.Errorf("github-copilot: %w", err)
+		}
+	}
 
 	// Verify the token can get a Copilot API token
-	fmt.Println("Verifying Copilot access...")
+	progress.Step("Verifying Copilot access")
 	apiToken, err := authSvc.GetCopilotAPIToken(ctx, authBundle.TokenData.AccessToken)
 	if err != nil {
 		return nil, fmt.Errorf("github-copilot: failed to verify Copilot access - you may not have an active Copilot subscription: %w", err)
 	}
 
+	return BuildGitHubCopilotAuth(authSvc, authBundle, apiToken), nil
+}
+
+// loginWithToken logs in with a pre-provisioned GitHub token, such as a fine-grained personal
+// access token, instead of running the device flow. The token is validated by exchanging it for a
+// Copilot API token and is stored exactly like a device flow token.
+func (a GitHubCopilotAuthenticator) loginWithToken(ctx context.Context, authSvc *copilot.CopilotAuth, token, organization string) (*coreauth.Auth, error) {
+	loginui.FromContext(ctx).Step("Verifying Copilot access with the provided GitHub token")
+	apiToken, err := authSvc.GetCopilotAPIToken(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("github-copilot: the provided GitHub token cannot access Copilot - check that it belongs to a user with an active Copilot subscription: %w", err)
+	}
+
+	_, username, err := authSvc.ValidateToken(ctx, token)
+	if err != nil || username == "" {
+		log.Warnf("copilot: failed to fetch user info for the provided token: %v", err)
+		username = "unknown"
+	}
+
+	authBundle := &copilot.CopilotAuthBundle{
+		TokenData: &copilot.CopilotTokenData{AccessToken: token, TokenType: "bearer"},
+		Username:  username,
+	}
+	if organization != "" {
+		if authBundle.Organization, err = authSvc.VerifyOrganization(ctx, token, organization); err != nil {
+			return nil, fmt.Errorf("github-copilot: the provided GitHub token cannot confirm membership in %s - it needs the read:org scope: %w", organization, err)
+		}
+	}
+
+	return BuildGitHubCopilotAuth(authSvc, authBundle, apiToken), nil
+}
+
+// BuildGitHubCopilotAuth creates the auth record stored for a verified GitHub token. The CLI
+// login and the management device flow both save logins through it.
+func BuildGitHubCopilotAuth(authSvc *copilot.CopilotAuth, authBundle *copilot.CopilotAuthBundle, apiToken *copilot.CopilotAPIToken) *coreauth.Auth {
 	// Create the token storage
 	tokenStorage := authSvc.CreateTokenStorage(authBundle)
 
@@ -95,19 +163,52 @@ func (a GitHubCopilotAuthenticator) Login(ctx context.Context, cfg *config.Confi
 	if apiToken.ExpiresAt > 0 {
 		metadata["api_token_expires_at"] = apiToken.ExpiresAt
 	}
+	if base := authSvc.GitHubBaseURL(); base != "" {
+		metadata["github_base_url"] = base
+	}
+	if authBundle.Organization != "" {
+		metadata["organization"] = authBundle.Organization
+	}
 
-	fileName := fmt.Sprintf("github-copilot-%s.json", authBundle.Username)
-
-	fmt.Printf("\nGitHub Copilot authentication successful for user: %s\n", authBundle.Username)
+	fileName := GitHubCopilotFileName(authSvc.GitHubBaseURL(), authBundle.Username, authBundle.Organization)
 
 	return &coreauth.Auth{
 		ID:       fileName,
-		Provider: a.Provider(),
+		Provider: GitHubCopilotAuthenticator{}.Provider(),
 		FileName: fileName,
 		Label:    authBundle.Username,
 		Storage:  tokenStorage,
 		Metadata: metadata,
-	}, nil
+	}
+}
+
+// gitHubCopilotOrganization returns the organization a login is bound to: the github_org login
+// metadata set by --github-org or, when the login is interactive, the organization the user names.
+// It is asked before the device flow because only logins bound to an organization request the
+// read:org scope. An empty result keeps the user's own Copilot seat.
+func gitHubCopilotOrganization(ctx context.Context, opts *LoginOptions) string {
+	if org := strings.TrimSpace(opts.Metadata["github_org"]); org != "" {
+		return org
+	}
+	if opts.Prompt == nil {
+		return ""
+	}
+	prompt := loginui.FromContext(ctx).WrapPrompt(opts.Prompt)
+	input, err := prompt("GitHub organization whose Copilot seat to use (leave empty for your own seat): ")
+	if err != nil {
+		log.Warnf("copilot: organization selection failed: %v", err)
+		return ""
+	}
+	return strings.TrimSpace(input)
+}
+
+// gitHubCopilotLoginToken returns the pre-provisioned GitHub token for a login: the github_token
+// login metadata set by --github-token, falling back to the GitHubCopilotTokenEnv variable.
+func gitHubCopilotLoginToken(opts *LoginOptions) string {
+	if token := strings.TrimSpace(opts.Metadata["github_token"]); token != "" {
+		return token
+	}
+	return strings.TrimSpace(os.Getenv(GitHubCopilotTokenEnv))
 }
 
 // RefreshGitHubCopilotToken validates and returns the current token status.
@@ -117,7 +218,7 @@ func RefreshGitHubCopilotToken(ctx context.Context, cfg *config.Config, storage
 		return fmt.Errorf("no token available")
 	}
 
-	authSvc := copilot.NewCopilotAuth(cfg)
+	authSvc := copilot.NewCopilotAuthWithBaseURL(cfg, storage.GitHubBaseURL)
 
 	// Validate the token can still get a Copilot API token
 	_, err := authSvc.GetCopilotAPIToken(ctx, storage.AccessToken)
@@ -127,3 +228,23 @@ func RefreshGitHubCopilotToken(ctx context.Context, cfg *config.Config, storage
 
 	return nil
 }
+
+// GitHubCopilotFileName returns the auth file name of a Copilot login. Logins against a GitHub
+// Enterprise instance include its host so they cannot collide with a github.com account, and
+// logins bound to an organization's seat append the organization after a dot, which GitHub names
+// cannot contain, so they sit next to the user's personal login.
+func GitHubCopilotFileName(githubBaseURL, username, organization string) string {
+	name := username
+	if organization != "" {
+		name += "." + organization
+	}
+	if githubBaseURL == "" {
+		return fmt.Sprintf("github-copilot-%s.json", name)
+	}
+	host := githubBaseURL
+	if parsed, err := url.Parse(githubBaseURL); err == nil && parsed.Host != "" {
+		host = parsed.Host
+	}
+	host = strings.NewReplacer(":", "-", "/", "-").Replace(host)
+	return fmt.Sprintf("github-copilot-%s-%s.json", host, name)
+}
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/copilot"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestGitHubCopilotTokenLogin(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasSuffix(auth, " github_pat_valid") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v3/copilot_internal/v2/token":
+			_, _ = w.Write([]byte(`{"token":"tid=copilot","expires_at":1760000000}`))
+		case "/api/v3/user":
+			_, _ = w.Write([]byte(`{"login":"octocat"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv(GitHubCopilotTokenEnv, "github_pat_valid")
+	cfg := &config.Config{}
+	opts := &LoginOptions{Metadata: map[string]string{"github_base_url": server.URL}}
+
+	record, err := NewGitHubCopilotAuthenticator().Login(context.Background(), cfg, opts)
+	if err != nil {
+		t.Fatalf("login with environment token: %v", err)
+	}
+	storage, ok := record.Storage.(*copilot.CopilotTokenStorage)
+	if !ok || storage.AccessToken != "github_pat_valid" || storage.Username != "octocat" {
+		t.Fatalf("unexpected storage: %#v", record.Storage)
+	}
+	if record.Label != "octocat" || record.Metadata["api_token_expires_at"] != int64(1760000000) {
+		t.Fatalf("unexpected auth record: %+v", record)
+	}
+
+	opts.Metadata["github_token"] = "github_pat_revoked"
+	if _, err = NewGitHubCopilotAuthenticator().Login(context.Background(), cfg, opts); err == nil {
+		t.Fatal("the --github-token value must take precedence and fail validation")
+	}
+}
+
+func TestGitHubCopilotOrganizationLogin(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/copilot_internal/v2/token":
+			_, _ = w.Write([]byte(`{"token":"tid=copilot","expires_at":1760000000}`))
+		case "/api/v3/user":
+			_, _ = w.Write([]byte(`{"login":"octocat"}`))
+		case "/api/v3/user/memberships/orgs/acme":
+			_, _ = w.Write([]byte(`{"state":"active","organization":{"login":"Acme"}}`))
+		case "/api/v3/user/memberships/orgs/pending":
+			_, _ = w.Write([]byte(`{"state":"pending","organization":{"login":"pending"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	opts := &LoginOptions{Metadata: map[string]string{"github_base_url": server.URL, "github_token": "github_pat_valid", "github_org": "acme"}}
+	record, err := NewGitHubCopilotAuthenticator().Login(context.Background(), cfg, opts)
+	if err != nil {
+		t.Fatalf("login bound to an organization: %v", err)
+	}
+	host := strings.TrimPrefix(server.URL, "http://")
+	host = strings.ReplaceAll(host, ":", "-")
+	if record.Metadata["organization"] != "Acme" || record.FileName != "github-copilot-"+host+"-octocat.Acme.json" {
+		t.Fatalf("unexpected organization login: %s %v", record.FileName, record.Metadata)
+	}
+
+	for _, org := range []string{"initech", "pending"} {
+		opts.Metadata["github_org"] = org
+		if _, err = NewGitHubCopilotAuthenticator().Login(context.Background(), cfg, opts); err == nil {
+			t.Fatalf("a login bound to %s must fail without an active membership", org)
+		}
+	}
+}
+
+func TestGitHubCopilotOrganizationPrompt(t *testing.T) {
+	opts := &LoginOptions{Metadata: map[string]string{}, Prompt: func(string) (string, error) { return " globex ", nil }}
+	if got := gitHubCopilotOrganization(context.Background(), opts); got != "globex" {
+		t.Fatalf("interactive logins must use the named organization, got %q", got)
+	}
+	opts.Metadata["github_org"] = "initech"
+	if got := gitHubCopilotOrganization(context.Background(), opts); got != "initech" {
+		t.Fatalf("--github-org must skip the prompt, got %q", got)
+	}
+	if got := gitHubCopilotOrganization(context.Background(), &LoginOptions{}); got != "" {
+		t.Fatalf("non-interactive logins must keep the user's own seat, got %q", got)
+	}
+	if GitHubCopilotFileName("", "octocat", "") == GitHubCopilotFileName("", "octocat", "acme") {
+		t.Fatal("organization and personal logins must not share a file")
+	}
+}
@@ -93,7 +93,7 @@ func (a *IFlowAuthenticator) Login(ctx context.Context, cfg *config.Config, opts
 	callbackErrCh := make(chan error, 1)
 
 	go func() {
-		result, errWait := oauthServer.WaitForCallback(5 * time.Minute)
+		result, errWait := oauthServer.WaitForCallback(cfg.LoginTimeout(a.Provider(), defaultLoginTimeout))
 		if errWait != nil {
 			callbackErrCh <- errWait
 			return
@@ -0,0 +1,254 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/claude"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/codex"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/gemini"
+	kiroauth "github.com/router-for-me/CLIProxyAPI/v6/internal/auth/kiro"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	"golang.org/x/oauth2"
+)
+
+// Tools whose stored credentials ImportCredentials understands.
+const (
+	ImportSourceGeminiCLI  = "gemini-cli"
+	ImportSourceClaudeCode = "claude-code"
+	ImportSourceCodex      = "codex"
+	ImportSourceAWSSSO     = "aws-sso"
+)
+
+// ImportSources lists the tools credentials can be imported from.
+func ImportSources() []string {
+	return []string{ImportSourceGeminiCLI, ImportSourceClaudeCode, ImportSourceCodex, ImportSourceAWSSSO}
+}
+
+// ImportOptions carries values the source file may not contain.
+type ImportOptions struct {
+	// ProjectID is the Google Cloud project of imported Gemini CLI credentials.
+	ProjectID string
+}
+
+// DefaultImportPath returns where source keeps its credentials on this machine.
+func DefaultImportPath(source string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	switch source {
+	case ImportSourceGeminiCLI:
+		return filepath.Join(homeDir, ".gemini", "oauth_creds.json"), nil
+	case ImportSourceClaudeCode:
+		return filepath.Join(homeDir, ".claude", ".credentials.json"), nil
+	case ImportSourceCodex:
+		if codexHome := strings.TrimSpace(os.Getenv("CODEX_HOME")); codexHome != "" {
+			return filepath.Join(codexHome, "auth.json"), nil
+		}
+		return filepath.Join(homeDir, ".codex", "auth.json"), nil
+	case ImportSourceAWSSSO:
+		return kiroauth.ResolveKiroIDETokenPath()
+	default:
+		return "", fmt.Errorf("unknown import source %q (supported: %s)", source, strings.Join(ImportSources(), ", "))
+	}
+}
+
+// ImportCredentials converts the credentials source stores at path into an auth record of this
+// proxy. An empty path reads the tool's default location.
+func ImportCredentials(ctx context.Context, source, path string, opts ImportOptions) (*coreauth.Auth, error) {
+	source = strings.ToLower(strings.TrimSpace(source))
+	path = strings.TrimSpace(path)
+	if path == "" {
+		defaultPath, err := DefaultImportPath(source)
+		if err != nil {
+			return nil, err
+		}
+		path = defaultPath
+	}
+	if source == ImportSourceAWSSSO {
+		return NewKiroAuthenticator().ImportFromKiroTokenFile(ctx, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s credentials: %w", source, err)
+	}
+	switch source {
+	case ImportSourceGeminiCLI:
+		return importGeminiCLI(data, opts)
+	case ImportSourceClaudeCode:
+		return importClaudeCode(data)
+	case ImportSourceCodex:
+		return importCodex(data)
+	default:
+		return nil, fmt.Errorf("unknown import source %q (supported: %s)", source, strings.Join(ImportSources(), ", "))
+	}
+}
+
+// importGeminiCLI converts ~/.gemini/oauth_creds.json. The Gemini CLI uses the same OAuth client
+// as this proxy, so its refresh token keeps working here.
+func importGeminiCLI(data []byte, opts ImportOptions) (*coreauth.Auth, error) {
+	var creds struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		TokenType    string `json:"token_type"`
+		IDToken      string `json:"id_token"`
+		ExpiryDate   int64  `json:"expiry_date"`
+	}
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("invalid Gemini CLI credentials: %w", err)
+	}
+	if creds.RefreshToken == "" {
+		return nil, fmt.Errorf("gemini CLI credentials have no refresh token")
+	}
+	projectID := strings.TrimSpace(opts.ProjectID)
+	if projectID == "" {
+		projectID = strings.TrimSpace(os.Getenv("GOOGLE_CLOUD_PROJECT"))
+	}
+	if projectID == "" {
+		return nil, fmt.Errorf("gemini CLI credentials do not name a project; pass --project_id")
+	}
+
+	token := &oauth2.Token{AccessToken: creds.AccessToken, RefreshToken: creds.RefreshToken, TokenType: creds.TokenType}
+	if creds.ExpiryDate > 0 {
+		token.Expiry = time.UnixMilli(creds.ExpiryDate)
+	}
+	tokenMap, err := gemini.TokenMap(token)
+	if err != nil {
+		return nil, err
+	}
+	ts := &gemini.GeminiTokenStorage{
+		Token:     tokenMap,
+		ProjectID: projectID,
+		Email:     jwtEmail(creds.IDToken),
+	}
+	if ts.Email == "" {
+		ts.Email = "gemini-cli-" + shortHash(creds.RefreshToken)
+	}
+
+	fileName := fmt.Sprintf("%s-%s.json", ts.Email, ts.ProjectID)
+	return &coreauth.Auth{
+		ID:       fileName,
+		Provider: "gemini",
+		FileName: fileName,
+		Storage:  ts,
+		Metadata: map[string]any{"email": ts.Email, "project_id": ts.ProjectID},
+	}, nil
+}
+
+// importClaudeCode converts ~/.claude/.credentials.json. The file does not record the account
+// email, so the record is named after a hash of the refresh token.
+func importClaudeCode(data []byte) (*coreauth.Auth, error) {
+	var creds struct {
+		ClaudeAIOAuth struct {
+			AccessToken  string `json:"accessToken"`
+			RefreshToken string `json:"refreshToken"`
+			ExpiresAt    int64  `json:"expiresAt"`
+		} `json:"claudeAiOauth"`
+	}
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("invalid Claude Code credentials: %w", err)
+	}
+	oauth := creds.ClaudeAIOAuth
+	if oauth.RefreshToken == "" {
+		return nil, fmt.Errorf("claude Code credentials have no OAuth refresh token")
+	}
+	ts := &claude.ClaudeTokenStorage{
+		AccessToken:  oauth.AccessToken,
+		RefreshToken: oauth.RefreshToken,
+		LastRefresh:  time.Now().Format(time.RFC3339),
+		Email:        "claude-code-" + shortHash(oauth.RefreshToken),
+	}
+	if oauth.ExpiresAt > 0 {
+		ts.Expire = time.UnixMilli(oauth.ExpiresAt).Format(time.RFC3339)
+	}
+
+	fileName := fmt.Sprintf("claude-%s.json", ts.Email)
+	return &coreauth.Auth{
+		ID:       fileName,
+		Provider: "claude",
+		FileName: fileName,
+		Storage:  ts,
+		Metadata: map[string]any{"email": ts.Email},
+	}, nil
+}
+
+// importCodex converts the ChatGPT login stored in ~/.codex/auth.json.
+func importCodex(data []byte) (*coreauth.Auth, error) {
+	var creds struct {
+		Tokens *struct {
+			IDToken      string `json:"id_token"`
+			AccessToken  string `json:"access_token"`
+			RefreshToken string `json:"refresh_token"`
+			AccountID    string `json:"account_id"`
+		} `json:"tokens"`
+		LastRefresh string `json:"last_refresh"`
+	}
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("invalid Codex credentials: %w", err)
+	}
+	if creds.Tokens == nil || creds.Tokens.RefreshToken == "" {
+		return nil, fmt.Errorf("codex credentials have no ChatGPT login; API key logins cannot be imported")
+	}
+	ts := &codex.CodexTokenStorage{
+		IDToken:      creds.Tokens.IDToken,
+		AccessToken:  creds.Tokens.AccessToken,
+		RefreshToken: creds.Tokens.RefreshToken,
+		AccountID:    creds.Tokens.AccountID,
+		LastRefresh:  creds.LastRefresh,
+	}
+	if claims, err := codex.ParseJWTToken(creds.Tokens.IDToken); err == nil {
+		ts.Email = claims.GetUserEmail()
+		if ts.AccountID == "" {
+			ts.AccountID = claims.GetAccountID()
+		}
+	}
+	if claims, err := codex.ParseJWTToken(creds.Tokens.AccessToken); err == nil && claims.Exp > 0 {
+		ts.Expire = time.Unix(int64(claims.Exp), 0).Format(time.RFC3339)
+	}
+	if ts.Email == "" {
+		ts.Email = "codex-cli-" + shortHash(creds.Tokens.RefreshToken)
+	}
+
+	fileName := fmt.Sprintf("codex-%s.json", ts.Email)
+	return &coreauth.Auth{
+		ID:       fileName,
+		Provider: "codex",
+		FileName: fileName,
+		Storage:  ts,
+		Metadata: map[string]any{"email": ts.Email},
+	}, nil
+}
+
+// jwtEmail returns the email claim of an unverified JWT, or "" when it has none.
+func jwtEmail(token string) string {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+	var claims struct {
+		Email string `json:"email"`
+	}
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(claims.Email)
+}
+
+func shortHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:4])
+}
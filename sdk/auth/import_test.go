@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/claude"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/codex"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/gemini"
+)
+
+func testJWT(payload string) string {
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(`{"alg":"none"}`)) + "." + enc.EncodeToString([]byte(payload)) + ".sig"
+}
+
+func writeImportFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "creds.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestImportGeminiCLI(t *testing.T) {
+	path := writeImportFile(t, `{"access_token":"at","refresh_token":"rt","token_type":"Bearer","expiry_date":1700000000000,"id_token":"`+testJWT(`{"email":"user@example.com"}`)+`"}`)
+
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "")
+	if _, err := ImportCredentials(context.Background(), ImportSourceGeminiCLI, path, ImportOptions{}); err == nil {
+		t.Fatal("import without a project must fail")
+	}
+	record, err := ImportCredentials(context.Background(), "Gemini-CLI", path, ImportOptions{ProjectID: "proj"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Provider != "gemini" || record.FileName != "user@example.com-proj.json" {
+		t.Fatalf("unexpected record: %+v", record)
+	}
+	ts := record.Storage.(*gemini.GeminiTokenStorage)
+	token := ts.Token.(map[string]any)
+	if token["refresh_token"] != "rt" || token["client_id"] == nil || token["expiry"] == nil {
+		t.Fatalf("token map missing refresh details: %v", token)
+	}
+}
+
+func TestImportClaudeCode(t *testing.T) {
+	path := writeImportFile(t, `{"claudeAiOauth":{"accessToken":"at","refreshToken":"rt","expiresAt":1700000000000}}`)
+
+	record, err := ImportCredentials(context.Background(), ImportSourceClaudeCode, path, ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := record.Storage.(*claude.ClaudeTokenStorage)
+	if record.Provider != "claude" || ts.RefreshToken != "rt" || ts.Expire == "" || !strings.HasPrefix(record.FileName, "claude-claude-code-") {
+		t.Fatalf("unexpected record: %+v %+v", record, ts)
+	}
+}
+
+func TestImportCodex(t *testing.T) {
+	idToken := testJWT(`{"email":"dev@example.com","https://api.openai.com/auth":{"chatgpt_account_id":"acct"}}`)
+	path := writeImportFile(t, `{"OPENAI_API_KEY":null,"tokens":{"id_token":"`+idToken+`","access_token":"at","refresh_token":"rt"},"last_refresh":"2025-01-01T00:00:00Z"}`)
+
+	record, err := ImportCredentials(context.Background(), ImportSourceCodex, path, ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := record.Storage.(*codex.CodexTokenStorage)
+	if record.FileName != "codex-dev@example.com.json" || ts.AccountID != "acct" || ts.RefreshToken != "rt" {
+		t.Fatalf("unexpected record: %+v %+v", record, ts)
+	}
+
+	apiKeyOnly := writeImportFile(t, `{"OPENAI_API_KEY":"sk-test"}`)
+	if _, err = ImportCredentials(context.Background(), ImportSourceCodex, apiKeyOnly, ImportOptions{}); err == nil {
+		t.Fatal("API key logins must be rejected")
+	}
+}
+
+func TestImportUnknownSource(t *testing.T) {
+	if _, err := ImportCredentials(context.Background(), "cursor", "/nonexistent", ImportOptions{}); err == nil {
+		t.Fatal("unknown sources must be rejected")
+	}
+}
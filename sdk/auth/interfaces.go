@@ -11,6 +11,10 @@ import (
 
 var ErrRefreshNotSupported = errors.New("cliproxy auth: refresh not supported")
 
+// defaultLoginTimeout is how long browser logins wait for the OAuth callback unless
+// login-timeouts overrides it for the provider.
+const defaultLoginTimeout = 5 * time.Minute
+
 // LoginOptions captures generic knobs shared across authenticators.
 // Provider-specific logic can inspect Metadata for extra parameters.
 type LoginOptions struct {
@@ -2,12 +2,15 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
 	kiroauth "github.com/router-for-me/CLIProxyAPI/v6/internal/auth/kiro"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/loginui"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 )
 
@@ -125,25 +128,33 @@ func (a *KiroAuthenticator) createAuthRecord(tokenData *kiroauth.KiroTokenData,
 		NextRefreshAfter: expiresAt.Add(-5 * time.Minute),
 	}
 
-	if tokenData.Email != "" {
-		fmt.Printf("\n✓ Kiro authentication completed successfully! (Account: %s)\n", tokenData.Email)
-	} else {
-		fmt.Println("\n✓ Kiro authentication completed successfully!")
-	}
-
 	return record, nil
 }
 
 // Login performs OAuth login for Kiro with AWS (Builder ID or IDC).
-// This shows a method selection prompt and handles both flows.
+// When opts.Metadata carries a "start_url" (and optionally "region"), it logs in to that IAM
+// Identity Center directly; otherwise it asks through opts.Prompt which method to use and
+// handles both flows.
 func (a *KiroAuthenticator) Login(ctx context.Context, cfg *config.Config, opts *LoginOptions) (*coreauth.Auth, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("kiro auth: configuration is required")
 	}
 
-	// Use the unified method selection flow (Builder ID or IDC)
 	ssoClient := kiroauth.NewSSOOIDCClient(cfg)
-	tokenData, err := ssoClient.LoginWithMethodSelection(ctx)
+	var (
+		tokenData *kiroauth.KiroTokenData
+		err       error
+	)
+	if startURL := kiroLoginMetadata(opts, "start_url"); startURL != "" {
+		tokenData, err = ssoClient.LoginWithIDC(ctx, startURL, kiroLoginMetadata(opts, "region"))
+	} else {
+		// Use the unified method selection flow (Builder ID or IDC)
+		var prompt func(string) (string, error)
+		if opts != nil {
+			prompt = opts.Prompt
+		}
+		tokenData, err = ssoClient.LoginWithMethodSelection(ctx, prompt)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("login failed: %w", err)
 	}
@@ -151,6 +162,13 @@ func (a *KiroAuthenticator) Login(ctx context.Context, cfg *config.Config, opts
 	return a.createAuthRecord(tokenData, "aws")
 }
 
+func kiroLoginMetadata(opts *LoginOptions, key string) string {
+	if opts == nil || opts.Metadata == nil {
+		return ""
+	}
+	return strings.TrimSpace(opts.Metadata[key])
+}
+
 // LoginWithAuthCode performs OAuth login for Kiro with AWS Builder ID using authorization code flow.
 // This provides a better UX than device code flow as it uses automatic browser callback.
 func (a *KiroAuthenticator) LoginWithAuthCode(ctx context.Context, cfg *config.Config, opts *LoginOptions) (*coreauth.Auth, error) {
@@ -214,17 +232,13 @@ func (a *KiroAuthenticator) LoginWithAuthCode(ctx context.Context, cfg *config.C
 		NextRefreshAfter: expiresAt.Add(-5 * time.Minute),
 	}
 
-	if tokenData.Email != "" {
-		fmt.Printf("\n✓ Kiro authentication completed successfully! (Account: %s)\n", tokenData.Email)
-	} else {
-		fmt.Println("\n✓ Kiro authentication completed successfully!")
-	}
-
 	return record, nil
 }
 
 // LoginWithGoogle performs OAuth login for Kiro with Google.
-// This uses a custom protocol handler (kiro://) to receive the callback.
+// This uses a custom protocol handler (kiro://) to receive the callback, or the local callback
+// server directly when NoBrowser is set or the host has no display. opts.Metadata["invitation_code"]
+// completes registration for waitlisted accounts.
 func (a *KiroAuthenticator) LoginWithGoogle(ctx context.Context, cfg *config.Config, opts *LoginOptions) (*coreauth.Auth, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("kiro auth: configuration is required")
@@ -236,8 +250,10 @@ func (a *KiroAuthenticator) LoginWithGoogle(ctx context.Context, cfg *config.Con
 	var interactiveOpts *kiroauth.InteractiveLoginOptions
 	if opts != nil {
 		interactiveOpts = &kiroauth.InteractiveLoginOptions{
-			NoBrowser: opts.NoBrowser,
-			Prompt:    opts.Prompt,
+			NoBrowser:      opts.NoBrowser,
+			Headless:       opts.NoBrowser,
+			InvitationCode: kiroLoginMetadata(opts, "invitation_code"),
+			Prompt:         opts.Prompt,
 		}
 	}
 	tokenData, err := oauth.LoginWithGoogle(ctx, interactiveOpts)
@@ -284,17 +300,12 @@ func (a *KiroAuthenticator) LoginWithGoogle(ctx context.Context, cfg *config.Con
 		NextRefreshAfter: expiresAt.Add(-5 * time.Minute),
 	}
 
-	if tokenData.Email != "" {
-		fmt.Printf("\n✓ Kiro Google authentication completed successfully! (Account: %s)\n", tokenData.Email)
-	} else {
-		fmt.Println("\n✓ Kiro Google authentication completed successfully!")
-	}
-
 	return record, nil
 }
 
 // LoginWithGitHub performs OAuth login for Kiro with GitHub.
-// This uses a custom protocol handler (kiro://) to receive the callback.
+// This uses a custom protocol handler (kiro://) to receive the callback, or the local callback
+// server directly when NoBrowser is set or the host has no display.
 func (a *KiroAuthenticator) LoginWithGitHub(ctx context.Context, cfg *config.Config, opts *LoginOptions) (*coreauth.Auth, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("kiro auth: configuration is required")
@@ -306,8 +317,10 @@ func (a *KiroAuthenticator) LoginWithGitHub(ctx context.Context, cfg *config.Con
 	var interactiveOpts *kiroauth.InteractiveLoginOptions
 	if opts != nil {
 		interactiveOpts = &kiroauth.InteractiveLoginOptions{
-			NoBrowser: opts.NoBrowser,
-			Prompt:    opts.Prompt,
+			NoBrowser:      opts.NoBrowser,
+			Headless:       opts.NoBrowser,
+			InvitationCode: kiroLoginMetadata(opts, "invitation_code"),
+			Prompt:         opts.Prompt,
 		}
 	}
 	tokenData, err := oauth.LoginWithGitHub(ctx, interactiveOpts)
@@ -354,10 +367,76 @@ func (a *KiroAuthenticator) LoginWithGitHub(ctx context.Context, cfg *config.Con
 		NextRefreshAfter: expiresAt.Add(-5 * time.Minute),
 	}
 
-	if tokenData.Email != "" {
-		fmt.Printf("\n✓ Kiro GitHub authentication completed successfully! (Account: %s)\n", tokenData.Email)
-	} else {
-		fmt.Println("\n✓ Kiro GitHub authentication completed successfully!")
+	return record, nil
+}
+
+// LoginWithCognito performs email/password login for Kiro with its Cognito user pool.
+// The account email in opts.Metadata["email"], when set, pre-fills the sign-in page.
+// opts.Metadata["invitation_code"] completes registration for waitlisted accounts.
+func (a *KiroAuthenticator) LoginWithCognito(ctx context.Context, cfg *config.Config, opts *LoginOptions) (*coreauth.Auth, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("kiro auth: configuration is required")
+	}
+
+	oauth := kiroauth.NewKiroOAuth(cfg)
+
+	var interactiveOpts *kiroauth.InteractiveLoginOptions
+	if opts != nil {
+		interactiveOpts = &kiroauth.InteractiveLoginOptions{
+			NoBrowser:      opts.NoBrowser,
+			Headless:       opts.NoBrowser,
+			LoginHint:      opts.Metadata["email"],
+			InvitationCode: kiroLoginMetadata(opts, "invitation_code"),
+			Prompt:         opts.Prompt,
+		}
+	}
+	tokenData, err := oauth.LoginWithCognito(ctx, interactiveOpts)
+	if err != nil {
+		return nil, fmt.Errorf("cognito login failed: %w", err)
+	}
+
+	// Parse expires_at
+	expiresAt, err := time.Parse(time.RFC3339, tokenData.ExpiresAt)
+	if err != nil {
+		expiresAt = time.Now().Add(1 * time.Hour)
+	}
+
+	email := tokenData.Email
+	if email == "" && interactiveOpts != nil {
+		email = strings.TrimSpace(interactiveOpts.LoginHint)
+	}
+
+	// Extract identifier for file naming
+	idPart := extractKiroIdentifier(email, tokenData.ProfileArn)
+
+	now := time.Now()
+	fileName := fmt.Sprintf("kiro-cognito-%s.json", idPart)
+
+	record := &coreauth.Auth{
+		ID:        fileName,
+		Provider:  "kiro",
+		FileName:  fileName,
+		Label:     "kiro-cognito",
+		Status:    coreauth.StatusActive,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Metadata: map[string]any{
+			"type":          "kiro",
+			"access_token":  tokenData.AccessToken,
+			"refresh_token": tokenData.RefreshToken,
+			"profile_arn":   tokenData.ProfileArn,
+			"expires_at":    tokenData.ExpiresAt,
+			"auth_method":   tokenData.AuthMethod,
+			"provider":      tokenData.Provider,
+			"email":         email,
+		},
+		Attributes: map[string]string{
+			"profile_arn": tokenData.ProfileArn,
+			"source":      "cognito-oauth",
+			"email":       email,
+		},
+		// NextRefreshAfter is aligned with RefreshLead (5min)
+		NextRefreshAfter: expiresAt.Add(-5 * time.Minute),
 	}
 
 	return record, nil
@@ -367,6 +446,7 @@ func (a *KiroAuthenticator) LoginWithGitHub(ctx context.Context, cfg *config.Con
 func (a *KiroAuthenticator) ImportFromKiroIDE(ctx context.Context, cfg *config.Config) (*coreauth.Auth, error) {
 	var (
 		tokenData *kiroauth.KiroTokenData
+		tokenPath string
 		err       error
 	)
 
@@ -378,17 +458,40 @@ func (a *KiroAuthenticator) ImportFromKiroIDE(ctx context.Context, cfg *config.C
 			}
 			tokenData, err = kiroauth.LoadKiroTokenFromPath(tokenFile)
 			if err == nil && tokenData != nil {
+				tokenPath = tokenFile
 				break
 			}
 		}
 	}
 
 	if tokenData == nil {
-		tokenData, err = kiroauth.LoadKiroIDEToken()
+		tokenPath, err = kiroauth.ResolveKiroIDETokenPath()
+		if err == nil {
+			tokenData, err = kiroauth.LoadKiroTokenFromPath(tokenPath)
+		}
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to load Kiro IDE token: %w", err)
 	}
+	loginui.FromContext(ctx).Step("Loaded Kiro token from %s (provider %s)", tokenPath, tokenData.Provider)
+	return kiroImportRecord(tokenPath, tokenData), nil
+}
+
+// ImportFromKiroTokenFile imports a Kiro or AWS SSO cache token file at tokenPath.
+func (a *KiroAuthenticator) ImportFromKiroTokenFile(ctx context.Context, tokenPath string) (*coreauth.Auth, error) {
+	tokenData, err := kiroauth.LoadKiroTokenFromPath(tokenPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Kiro token: %w", err)
+	}
+	return kiroImportRecord(tokenPath, tokenData), nil
+}
+
+// kiroImportRecord builds the auth record for a token imported from tokenPath.
+func kiroImportRecord(tokenPath string, tokenData *kiroauth.KiroTokenData) *coreauth.Auth {
+	// The watcher uses the source path to keep the imported auth in sync with the IDE session.
+	if expanded, errExpand := kiroauth.ExpandKiroTokenPath(tokenPath); errExpand == nil {
+		tokenPath = filepath.Clean(expanded)
+	}
 
 	// Parse expires_at
 	expiresAt, err := time.Parse(time.RFC3339, tokenData.ExpiresAt)
@@ -421,14 +524,15 @@ func (a *KiroAuthenticator) ImportFromKiroIDE(ctx context.Context, cfg *config.C
 		CreatedAt: now,
 		UpdatedAt: now,
 		Metadata: map[string]any{
-			"type":          "kiro",
-			"access_token":  tokenData.AccessToken,
-			"refresh_token": tokenData.RefreshToken,
-			"profile_arn":   tokenData.ProfileArn,
-			"expires_at":    tokenData.ExpiresAt,
-			"auth_method":   tokenData.AuthMethod,
-			"provider":      tokenData.Provider,
-			"email":         tokenData.Email,
+			"type":           "kiro",
+			"access_token":   tokenData.AccessToken,
+			"refresh_token":  tokenData.RefreshToken,
+			"profile_arn":    tokenData.ProfileArn,
+			"expires_at":     tokenData.ExpiresAt,
+			"auth_method":    tokenData.AuthMethod,
+			"provider":       tokenData.Provider,
+			"email":          tokenData.Email,
+			"ide_token_file": tokenPath,
 		},
 		Attributes: map[string]string{
 			"profile_arn": tokenData.ProfileArn,
@@ -439,14 +543,7 @@ func (a *KiroAuthenticator) ImportFromKiroIDE(ctx context.Context, cfg *config.C
 		NextRefreshAfter: expiresAt.Add(-5 * time.Minute),
 	}
 
-	// Display the email if extracted
-	if tokenData.Email != "" {
-		fmt.Printf("\n✓ Imported Kiro token from IDE (Provider: %s, Account: %s)\n", tokenData.Provider, tokenData.Email)
-	} else {
-		fmt.Printf("\n✓ Imported Kiro token from IDE (Provider: %s)\n", tokenData.Provider)
-	}
-
-	return record, nil
+	return record
 }
 
 // Refresh refreshes an expired Kiro token using AWS SSO OIDC.
@@ -486,6 +583,9 @@ func (a *KiroAuthenticator) Refresh(ctx context.Context, cfg *config.Config, aut
 	}
 
 	if err != nil {
+		if errors.Is(err, kiroauth.ErrRefreshTokenRevoked) {
+			return nil, fmt.Errorf("token refresh failed: %w: %w", coreauth.ErrReauthRequired, err)
+		}
 		return nil, fmt.Errorf("token refresh failed: %w", err)
 	}
 
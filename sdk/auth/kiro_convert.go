@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	kiroauth "github.com/router-for-me/CLIProxyAPI/v6/internal/auth/kiro"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+// ConvertKiroIDETokenToAuthRecord converts a Kiro IDE token file (kiro-auth-token.json) into a
+// kiro auth record. ok is false when data is not a Kiro IDE token.
+func ConvertKiroIDETokenToAuthRecord(data []byte) (*coreauth.Auth, bool, error) {
+	var token kiroauth.KiroTokenData
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, false, nil
+	}
+	if strings.TrimSpace(token.AccessToken) == "" {
+		return nil, false, nil
+	}
+
+	if strings.TrimSpace(token.Email) == "" {
+		token.Email = kiroauth.ExtractEmailFromJWT(token.AccessToken)
+	}
+
+	providerRaw := strings.TrimSpace(token.Provider)
+	provider := kiroauth.SanitizeEmailForFilename(strings.ToLower(providerRaw))
+	if provider == "" {
+		provider = "imported"
+	}
+
+	idPart := kiroauth.SanitizeEmailForFilename(strings.TrimSpace(token.Email))
+	if idPart == "" {
+		idPart = kiroauth.SanitizeEmailForFilename(strings.TrimSpace(token.ProfileArn))
+	}
+	if idPart == "" {
+		idPart = fmt.Sprintf("%d", time.Now().UnixNano()%100000)
+	}
+
+	fileName := fmt.Sprintf("kiro-%s-%s.json", provider, idPart)
+	now := time.Now()
+	source := "kiro-ide-import"
+
+	expiresAt, err := time.Parse(time.RFC3339, token.ExpiresAt)
+	if err != nil {
+		expiresAt = now.Add(1 * time.Hour)
+	}
+	record := &coreauth.Auth{
+		ID:        fileName,
+		Provider:  "kiro",
+		FileName:  fileName,
+		Label:     fmt.Sprintf("kiro-%s", provider),
+		Status:    coreauth.StatusActive,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Metadata: map[string]any{
+			"type":          "kiro",
+			"access_token":  token.AccessToken,
+			"refresh_token": token.RefreshToken,
+			"profile_arn":   token.ProfileArn,
+			"expires_at":    token.ExpiresAt,
+			"auth_method":   token.AuthMethod,
+			"provider":      token.Provider,
+			"email":         token.Email,
+			"last_refresh":  now.Format(time.RFC3339),
+		},
+		Attributes: map[string]string{
+			"profile_arn": token.ProfileArn,
+			"source":      source,
+			"email":       token.Email,
+		},
+		NextRefreshAfter: expiresAt.Add(-5 * time.Minute),
+	}
+
+	return record, true, nil
+}
+
+// ImportKiroTokenDir converts every Kiro token file in dir, either a Kiro IDE export or an auth
+// file written by this proxy, into an auth record. Tokens of the same account, matched by email
+// and otherwise by profile ARN, are imported once, keeping the one that expires last. Files that
+// are not Kiro tokens are skipped.
+func ImportKiroTokenDir(dir string) ([]*coreauth.Auth, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+	byAccount := make(map[string]*coreauth.Auth)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(strings.ToLower(entry.Name()), ".json") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, errRead := os.ReadFile(path)
+		if errRead != nil {
+			log.Warnf("kiro import: skipping %s: %v", entry.Name(), errRead)
+			continue
+		}
+		record, ok := kiroRecordFromFile(entry.Name(), data)
+		if !ok {
+			log.Debugf("kiro import: %s is not a Kiro token, skipping", entry.Name())
+			continue
+		}
+		key := kiroAccountKey(record)
+		if existing, seen := byAccount[key]; seen {
+			if !kiroExpiresAt(record).After(kiroExpiresAt(existing)) {
+				log.Infof("kiro import: %s duplicates an account already imported, skipping", entry.Name())
+				continue
+			}
+			log.Infof("kiro import: %s replaces an older token of the same account", entry.Name())
+		}
+		byAccount[key] = record
+	}
+	records := make([]*coreauth.Auth, 0, len(byAccount))
+	for _, record := range byAccount {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].FileName < records[j].FileName })
+	return records, nil
+}
+
+// kiroRecordFromFile converts a Kiro IDE export, or reuses an auth file written by this proxy
+// as is.
+func kiroRecordFromFile(name string, data []byte) (*coreauth.Auth, bool) {
+	var metadata map[string]any
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, false
+	}
+	if typ, _ := metadata["type"].(string); typ == "kiro" {
+		if accessToken, _ := metadata["access_token"].(string); strings.TrimSpace(accessToken) == "" {
+			return nil, false
+		}
+		email, _ := metadata["email"].(string)
+		profileArn, _ := metadata["profile_arn"].(string)
+		provider, _ := metadata["provider"].(string)
+		label := "kiro-imported"
+		if p := kiroauth.SanitizeEmailForFilename(strings.ToLower(strings.TrimSpace(provider))); p != "" {
+			label = "kiro-" + p
+		}
+		now := time.Now()
+		return &coreauth.Auth{
+			ID:        name,
+			Provider:  "kiro",
+			FileName:  name,
+			Label:     label,
+			Status:    coreauth.StatusActive,
+			CreatedAt: now,
+			UpdatedAt: now,
+			Metadata:  metadata,
+			Attributes: map[string]string{
+				"profile_arn": profileArn,
+				"source":      "kiro-dir-import",
+				"email":       email,
+			},
+		}, true
+	}
+	record, ok, err := ConvertKiroIDETokenToAuthRecord(data)
+	if err != nil || !ok {
+		return nil, false
+	}
+	return record, true
+}
+
+// kiroAccountKey identifies the account of record for deduplication: the email when known, else
+// the refresh token, else the file name. The profile ARN is shared by every account of an
+// organization, so it does not identify one.
+func kiroAccountKey(record *coreauth.Auth) string {
+	if email, _ := record.Metadata["email"].(string); strings.TrimSpace(email) != "" {
+		return "email:" + strings.ToLower(strings.TrimSpace(email))
+	}
+	if token, _ := record.Metadata["refresh_token"].(string); strings.TrimSpace(token) != "" {
+		return "refresh_token:" + strings.TrimSpace(token)
+	}
+	return "file:" + record.FileName
+}
+
+func kiroExpiresAt(record *coreauth.Auth) time.Time {
+	raw, _ := record.Metadata["expires_at"].(string)
+	expiresAt, _ := time.Parse(time.RFC3339, raw)
+	return expiresAt
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestImportKiroTokenDirDedupesAccounts(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		// Two IDE exports of the same account; the later expiry wins.
+		"a-old.json": `{"accessToken":"old","refreshToken":"r1","email":"a@example.com","provider":"Google","expiresAt":"2025-01-01T00:00:00Z"}`,
+		"a-new.json": `{"accessToken":"new","refreshToken":"r2","email":"A@example.com","provider":"Google","expiresAt":"2025-02-01T00:00:00Z"}`,
+		// A proxy auth file is kept as is, including fields the IDE format lacks.
+		"kiro-aws-b.json": `{"type":"kiro","access_token":"b","refresh_token":"rb","profile_arn":"arn:b","provider":"AWS","client_id":"cid","auth_method":"builder-id"}`,
+		// Another account of the same organization shares the profile ARN but is not a duplicate.
+		"kiro-aws-c.json": `{"type":"kiro","access_token":"c","refresh_token":"rc","profile_arn":"arn:b","provider":"AWS","client_id":"cid","auth_method":"builder-id"}`,
+		"other.json":      `{"type":"claude","access_token":"x"}`,
+		"notes.txt":       `ignored`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err := ImportKiroTokenDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3: %+v", len(records), records)
+	}
+	byAccess := map[string]string{}
+	for _, record := range records {
+		access, _ := record.Metadata["access_token"].(string)
+		byAccess[access] = record.FileName
+		if record.Provider != "kiro" {
+			t.Fatalf("unexpected provider: %+v", record)
+		}
+	}
+	if _, ok := byAccess["new"]; !ok {
+		t.Fatalf("the newer duplicate must win, got %v", byAccess)
+	}
+	if byAccess["b"] != "kiro-aws-b.json" {
+		t.Fatalf("proxy auth files must keep their name, got %v", byAccess)
+	}
+	for _, record := range records {
+		if record.FileName == "kiro-aws-b.json" && record.Metadata["client_id"] != "cid" {
+			t.Fatalf("proxy auth metadata must be preserved: %+v", record.Metadata)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	kiroauth "github.com/router-for-me/CLIProxyAPI/v6/internal/auth/kiro"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// StartKiroRemoteLogin begins a Kiro social login whose browser runs on another device. The login
+// is persisted as pending so its callback can be completed by this process, by --resume, or
+// through the management oauth-callback endpoint of a server sharing the auth directory.
+func StartKiroRemoteLogin(cfg *config.Config, provider kiroauth.SocialProvider) (*kiroauth.RemoteLogin, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("kiro auth: configuration is required")
+	}
+	login, err := kiroauth.NewSocialAuthClient(cfg).StartRemoteLogin(provider)
+	if err != nil {
+		return nil, err
+	}
+	SavePendingLogin(cfg, PendingLogin{
+		Provider:         "kiro",
+		IdentityProvider: string(provider),
+		State:            login.State,
+		CodeVerifier:     login.CodeVerifier,
+	})
+	return login, nil
+}
+
+// CompleteKiroRemoteLogin exchanges the authorization code of a remote login and builds its auth
+// record.
+func CompleteKiroRemoteLogin(ctx context.Context, cfg *config.Config, login *kiroauth.RemoteLogin, code, invitationCode string) (*coreauth.Auth, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("kiro auth: configuration is required")
+	}
+	if login == nil {
+		return nil, fmt.Errorf("kiro auth: remote login is required")
+	}
+	defer RemovePendingLogin(cfg, login.State)
+
+	tokenData, err := kiroauth.NewSocialAuthClient(cfg).CompleteRemoteLogin(ctx, login, code, invitationCode)
+	if err != nil {
+		return nil, fmt.Errorf("kiro remote login failed: %w", err)
+	}
+	return NewKiroAuthenticator().createAuthRecord(tokenData, strings.ToLower(string(login.Provider)))
+}
@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/claude"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/codex"
+	kiroauth "github.com/router-for-me/CLIProxyAPI/v6/internal/auth/kiro"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+// pendingLoginTTL bounds how long a login can be resumed; authorization codes expire soon after.
+const pendingLoginTTL = 10 * time.Minute
+
+// pendingLoginStatePattern restricts states to characters that are safe in a file name.
+var pendingLoginStatePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,128}$`)
+
+// ErrNoPendingLogin is returned by ResumeLogin when no login is waiting for the pasted callback.
+var ErrNoPendingLogin = errors.New("no pending login for this callback; it expired or already completed")
+
+// PendingLogin is an OAuth login waiting for its callback. It is kept in the auth directory so a
+// login interrupted after the browser step can be completed with the pasted callback URL.
+type PendingLogin struct {
+	Provider string `json:"provider"`
+	// IdentityProvider is the Kiro social provider (Google, Github) of a Kiro login.
+	IdentityProvider string    `json:"identity_provider,omitempty"`
+	State            string    `json:"state"`
+	CodeVerifier     string    `json:"code_verifier"`
+	CodeChallenge    string    `json:"code_challenge,omitempty"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+func pendingLoginPath(cfg *config.Config, state string) (string, error) {
+	if !pendingLoginStatePattern.MatchString(state) {
+		return "", fmt.Errorf("invalid oauth state")
+	}
+	authDir, err := util.ResolveAuthDir(cfg.AuthDir)
+	if err != nil {
+		return "", err
+	}
+	if authDir == "" {
+		return "", fmt.Errorf("auth dir is not configured")
+	}
+	return filepath.Join(authDir, fmt.Sprintf(".login-%s.pending", state)), nil
+}
+
+// SavePendingLogin persists login until it completes. Failures only cost the ability to resume,
+// so they are logged rather than returned.
+func SavePendingLogin(cfg *config.Config, login PendingLogin) {
+	path, err := pendingLoginPath(cfg, login.State)
+	if err != nil {
+		log.Debugf("pending login not saved: %v", err)
+		return
+	}
+	login.ExpiresAt = time.Now().Add(pendingLoginTTL)
+	data, err := json.Marshal(login)
+	if err != nil {
+		log.Debugf("pending login not saved: %v", err)
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err == nil {
+		err = os.WriteFile(path, data, 0o600)
+	}
+	if err != nil {
+		log.Debugf("pending login not saved: %v", err)
+	}
+}
+
+// RemovePendingLogin forgets the pending login of state.
+func RemovePendingLogin(cfg *config.Config, state string) {
+	if path, err := pendingLoginPath(cfg, state); err == nil {
+		_ = os.Remove(path)
+	}
+}
+
+// LoadPendingLogin returns the unexpired pending login of state, or ErrNoPendingLogin.
+func LoadPendingLogin(cfg *config.Config, state string) (*PendingLogin, error) {
+	path, err := pendingLoginPath(cfg, state)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoPendingLogin
+	}
+	if err != nil {
+		return nil, err
+	}
+	var login PendingLogin
+	if err = json.Unmarshal(data, &login); err != nil {
+		return nil, fmt.Errorf("invalid pending login file: %w", err)
+	}
+	if login.State != state || time.Now().After(login.ExpiresAt) {
+		_ = os.Remove(path)
+		return nil, ErrNoPendingLogin
+	}
+	return &login, nil
+}
+
+// ResumeLogin completes an interrupted Claude, Codex or Kiro social login from its pasted callback
+// URL, using the PKCE verifier saved when the login started.
+func ResumeLogin(ctx context.Context, cfg *config.Config, callbackInput string) (*coreauth.Auth, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("cliproxy auth: configuration is required")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	callback, err := misc.ParseOAuthCallback(callbackInput)
+	if err != nil {
+		return nil, err
+	}
+	if callback == nil {
+		return nil, fmt.Errorf("callback URL is empty")
+	}
+	if callback.Error != "" {
+		return nil, fmt.Errorf("authorization failed: %s %s", callback.Error, callback.ErrorDescription)
+	}
+	login, err := LoadPendingLogin(cfg, callback.State)
+	if err != nil {
+		return nil, err
+	}
+	defer RemovePendingLogin(cfg, login.State)
+
+	switch login.Provider {
+	case "claude":
+		pkceCodes := &claude.PKCECodes{CodeVerifier: login.CodeVerifier, CodeChallenge: login.CodeChallenge}
+		return claudeAuthRecord(ctx, claude.NewClaudeAuth(cfg), callback.Code, login.State, pkceCodes)
+	case "codex":
+		pkceCodes := &codex.PKCECodes{CodeVerifier: login.CodeVerifier, CodeChallenge: login.CodeChallenge}
+		return codexAuthRecord(ctx, codex.NewCodexAuth(cfg), callback.Code, pkceCodes)
+	case "kiro":
+		remote := &kiroauth.RemoteLogin{Provider: kiroauth.SocialProvider(login.IdentityProvider), State: login.State, CodeVerifier: login.CodeVerifier}
+		return CompleteKiroRemoteLogin(ctx, cfg, remote, callback.Code, "")
+	default:
+		return nil, fmt.Errorf("resuming %s logins is not supported", strings.TrimSpace(login.Provider))
+	}
+}
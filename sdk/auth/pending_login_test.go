@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestPendingLoginRoundTrip(t *testing.T) {
+	cfg := &config.Config{AuthDir: t.TempDir()}
+	SavePendingLogin(cfg, PendingLogin{Provider: "codex", State: "abc123", CodeVerifier: "verifier", CodeChallenge: "challenge"})
+
+	login, err := LoadPendingLogin(cfg, "abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if login.Provider != "codex" || login.CodeVerifier != "verifier" || time.Until(login.ExpiresAt) <= 0 {
+		t.Fatalf("unexpected pending login: %+v", login)
+	}
+
+	RemovePendingLogin(cfg, "abc123")
+	if _, err = LoadPendingLogin(cfg, "abc123"); !errors.Is(err, ErrNoPendingLogin) {
+		t.Fatalf("removed login must be gone, got %v", err)
+	}
+	if _, err = LoadPendingLogin(cfg, "../escape"); err == nil || errors.Is(err, ErrNoPendingLogin) {
+		t.Fatalf("unsafe state must be rejected, got %v", err)
+	}
+}
+
+func TestResumeLoginRejectsExpiredSession(t *testing.T) {
+	cfg := &config.Config{AuthDir: t.TempDir()}
+	SavePendingLogin(cfg, PendingLogin{Provider: "claude", State: "old", CodeVerifier: "v"})
+	path := filepath.Join(cfg.AuthDir, ".login-old.pending")
+	if err := os.WriteFile(path, []byte(`{"provider":"claude","state":"old","expires_at":"2000-01-01T00:00:00Z"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := ResumeLogin(context.Background(), cfg, "http://localhost:54545/callback?code=c&state=old")
+	if !errors.Is(err, ErrNoPendingLogin) {
+		t.Fatalf("expired login must not resume, got %v", err)
+	}
+	if _, errStat := os.Stat(path); !os.IsNotExist(errStat) {
+		t.Fatal("expired pending login must be deleted")
+	}
+}
@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+)
+
+// maxIndexedModels bounds the per-snapshot model cache, since model names come from requests.
+const maxIndexedModels = 512
+
+// authIndex is an immutable snapshot of the registered auths grouped by provider. The manager
+// publishes a new snapshot on every membership change, copying only the provider buckets that
+// changed, so selection scans the candidates of one provider instead of the whole pool.
+type authIndex struct {
+	byProvider map[string][]*Auth
+
+	// models caches, per provider and model, the auths the registry reports as supporting the
+	// model. Entries are valid for the registry generation they were computed at.
+	modelsMu sync.Mutex
+	models   map[modelIndexKey]modelIndexEntry
+}
+
+type modelIndexKey struct {
+	provider string
+	model    string
+}
+
+type modelIndexEntry struct {
+	generation uint64
+	auths      []*Auth
+}
+
+// providerIndexKey normalizes a provider name the way mixed routing compares them.
+func providerIndexKey(provider string) string {
+	return strings.ToLower(strings.TrimSpace(provider))
+}
+
+func newAuthIndex(byProvider map[string][]*Auth) *authIndex {
+	return &authIndex{byProvider: byProvider, models: make(map[modelIndexKey]modelIndexEntry)}
+}
+
+// buildAuthIndex indexes every auth in auths.
+func buildAuthIndex(auths map[string]*Auth) *authIndex {
+	byProvider := make(map[string][]*Auth)
+	for _, auth := range auths {
+		if auth == nil {
+			continue
+		}
+		key := providerIndexKey(auth.Provider)
+		byProvider[key] = append(byProvider[key], auth)
+	}
+	return newAuthIndex(byProvider)
+}
+
+// withAuth returns a snapshot in which prev is replaced by next. Either may be nil to express an
+// insertion or a removal. The receiver is left untouched.
+func (idx *authIndex) withAuth(prev, next *Auth) *authIndex {
+	byProvider := make(map[string][]*Auth, len(idx.byProvider)+1)
+	for key, bucket := range idx.byProvider {
+		byProvider[key] = bucket
+	}
+	if prev != nil {
+		key := providerIndexKey(prev.Provider)
+		if bucket := withoutAuth(byProvider[key], prev.ID, 0); len(bucket) > 0 {
+			byProvider[key] = bucket
+		} else {
+			delete(byProvider, key)
+		}
+	}
+	if next != nil {
+		key := providerIndexKey(next.Provider)
+		byProvider[key] = append(withoutAuth(byProvider[key], next.ID, 1), next)
+	}
+	return newAuthIndex(byProvider)
+}
+
+// withoutAuth copies bucket without the auth identified by id, reserving extra spare capacity.
+func withoutAuth(bucket []*Auth, id string, extra int) []*Auth {
+	out := make([]*Auth, 0, len(bucket)+extra)
+	for _, auth := range bucket {
+		if auth.ID != id {
+			out = append(out, auth)
+		}
+	}
+	return out
+}
+
+// candidates returns the auths of provider that can serve model. An empty model or a nil
+// registry skips the model check. The result is shared and must not be modified.
+func (idx *authIndex) candidates(provider, model string, reg *registry.ModelRegistry) []*Auth {
+	bucket := idx.byProvider[providerIndexKey(provider)]
+	if model == "" || reg == nil || len(bucket) == 0 {
+		return bucket
+	}
+	key := modelIndexKey{provider: providerIndexKey(provider), model: model}
+	// Read the generation before consulting the registry so a concurrent change invalidates
+	// the entry stored below.
+	generation := reg.Generation()
+	idx.modelsMu.Lock()
+	entry, ok := idx.models[key]
+	idx.modelsMu.Unlock()
+	if ok && entry.generation == generation {
+		return entry.auths
+	}
+
+	supported := make([]*Auth, 0, len(bucket))
+	for _, auth := range bucket {
+		if reg.ClientSupportsModel(auth.ID, model) {
+			supported = append(supported, auth)
+		}
+	}
+	idx.modelsMu.Lock()
+	if _, exists := idx.models[key]; exists || len(idx.models) < maxIndexedModels {
+		idx.models[key] = modelIndexEntry{generation: generation, auths: supported}
+	}
+	idx.modelsMu.Unlock()
+	return supported
+}
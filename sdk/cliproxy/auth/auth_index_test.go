@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+type providerExecutor struct {
+	recordingExecutor
+	provider string
+}
+
+func (e *providerExecutor) Identifier() string { return e.provider }
+
+func registerIndexedAuth(t testing.TB, m *Manager, id, provider string, models ...string) {
+	t.Helper()
+	if _, err := m.Register(context.Background(), &Auth{ID: id, Provider: provider}); err != nil {
+		t.Fatalf("register %s: %v", id, err)
+	}
+	infos := make([]*registry.ModelInfo, 0, len(models))
+	for _, model := range models {
+		infos = append(infos, &registry.ModelInfo{ID: model})
+	}
+	registry.GetGlobalRegistry().RegisterClient(id, provider, infos)
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+}
+
+func TestAuthIndexFollowsUpdatesAndRegistry(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&providerExecutor{provider: "index-a"})
+	m.RegisterExecutor(&providerExecutor{provider: "index-b"})
+	registerIndexedAuth(t, m, "index-a-1", "index-a", "index-model")
+	registerIndexedAuth(t, m, "index-b-1", "index-b", "other-model")
+
+	ctx := context.Background()
+	picked, _, err := m.pickNext(ctx, "index-a", "index-model", cliproxyexecutor.Options{}, nil)
+	if err != nil || picked.ID != "index-a-1" {
+		t.Fatalf("pickNext = %v, %v; want index-a-1", picked, err)
+	}
+	if _, _, err = m.pickNext(ctx, "index-b", "index-model", cliproxyexecutor.Options{}, nil); err == nil {
+		t.Fatalf("index-b has no auth for index-model")
+	}
+
+	// A registry change must invalidate the cached model lookup.
+	registry.GetGlobalRegistry().RegisterClient("index-b-1", "index-b", []*registry.ModelInfo{{ID: "index-model"}})
+	picked, _, err = m.pickNext(ctx, "index-b", "index-model", cliproxyexecutor.Options{}, nil)
+	if err != nil || picked.ID != "index-b-1" {
+		t.Fatalf("pickNext after registry change = %v, %v; want index-b-1", picked, err)
+	}
+
+	// Moving an auth to another provider must move it between buckets.
+	if _, err = m.Update(ctx, &Auth{ID: "index-a-1", Provider: "index-b"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if _, _, err = m.pickNext(ctx, "index-a", "", cliproxyexecutor.Options{}, nil); err == nil {
+		t.Fatalf("index-a must be empty after the provider change")
+	}
+	tried := map[string]struct{}{"index-b-1": {}}
+	picked, _, _, err = m.pickNextMixed(ctx, []string{"index-a", "index-b"}, "", cliproxyexecutor.Options{}, tried)
+	if err != nil || picked.ID != "index-a-1" {
+		t.Fatalf("pickNextMixed = %v, %v; want index-a-1", picked, err)
+	}
+}
+
+// BenchmarkPickNext selects among a fixed number of candidates while the rest of the pool grows;
+// with the provider index the cost should stay flat.
+func BenchmarkPickNext(b *testing.B) {
+	for _, pool := range []int{100, 1000, 5000} {
+		b.Run(fmt.Sprintf("pool=%d", pool), func(b *testing.B) {
+			m := NewManager(nil, nil, nil)
+			m.RegisterExecutor(&providerExecutor{provider: "bench-target"})
+			for i := 0; i < 20; i++ {
+				registerIndexedAuth(b, m, fmt.Sprintf("bench-target-%d", i), "bench-target", "bench-model")
+			}
+			for i := 0; i < pool; i++ {
+				registerIndexedAuth(b, m, fmt.Sprintf("bench-other-%d-%d", pool, i), "bench-other", "bench-model")
+			}
+			ctx := context.Background()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := m.pickNext(ctx, "bench-target", "bench-model", cliproxyexecutor.Options{}, nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// Circuit states reported in CircuitState.State; the zero value is a closed circuit.
+const (
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open"
+)
+
+const defaultCircuitCooldown = time.Minute
+
+// CircuitState captures the circuit breaker of a credential.
+type CircuitState struct {
+	// State is CircuitOpen while the credential is skipped and CircuitHalfOpen while a single
+	// probe request is in flight; empty means closed.
+	State string `json:"state,omitempty"`
+	// ConsecutiveFailures counts upstream failures since the last healthy response.
+	ConsecutiveFailures int `json:"consecutive_failures,omitempty"`
+	// FailedModels lists the distinct models among those failures.
+	FailedModels []string `json:"failed_models,omitempty"`
+	// OpenedAt is when the circuit last opened.
+	OpenedAt time.Time `json:"opened_at"`
+	// NextProbeAt is when an open circuit lets a probe through, or when an unanswered probe is
+	// given up so that another one may be sent.
+	NextProbeAt time.Time `json:"next_probe_at"`
+}
+
+// Tripped reports whether the circuit is open or half-open.
+func (c CircuitState) Tripped() bool {
+	return c.State == CircuitOpen || c.State == CircuitHalfOpen
+}
+
+// blocks reports whether the circuit keeps the credential from being selected at now.
+func (c CircuitState) blocks(now time.Time) bool {
+	return c.Tripped() && now.Before(c.NextProbeAt)
+}
+
+// circuitBreaker opens the circuit of a credential after consecutive upstream failures.
+type circuitBreaker struct {
+	mu        sync.RWMutex
+	threshold int
+	cooldown  time.Duration
+}
+
+func newCircuitBreaker() *circuitBreaker {
+	return &circuitBreaker{cooldown: defaultCircuitCooldown}
+}
+
+func (b *circuitBreaker) configure(cfg internalconfig.CircuitBreakerConfig) {
+	cooldown := time.Duration(cfg.CooldownSeconds) * time.Second
+	if cooldown <= 0 {
+		cooldown = defaultCircuitCooldown
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.threshold = max(cfg.FailureThreshold, 0)
+	b.cooldown = cooldown
+}
+
+func (b *circuitBreaker) settings() (int, time.Duration) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.threshold, b.cooldown
+}
+
+// record updates the circuit of auth with the outcome of one request. The circuit only opens when
+// the failures span models, or carry none: a single failing model is already cooled down on its
+// own through ModelState and must not block the credential's other models. A failed probe
+// reopens the circuit; any response showing the upstream is reachable closes it.
+func (b *circuitBreaker) record(auth *Auth, result Result, now time.Time) {
+	threshold, cooldown := b.settings()
+	if threshold == 0 {
+		auth.Circuit = CircuitState{}
+		return
+	}
+	if result.Success || !countsAgainstCircuit(statusCodeFromResult(result.Error)) {
+		if auth.Circuit.Tripped() {
+			log.Infof("auth %s circuit closed after a healthy response", auth.ID)
+		}
+		auth.Circuit = CircuitState{}
+		return
+	}
+	auth.Circuit.ConsecutiveFailures++
+	if result.Model != "" && !slices.Contains(auth.Circuit.FailedModels, result.Model) {
+		// Clip so that appending never writes into a slice shared with a clone of auth.
+		auth.Circuit.FailedModels = append(slices.Clip(auth.Circuit.FailedModels), result.Model)
+	}
+	spansModels := result.Model == "" || len(auth.Circuit.FailedModels) > 1
+	if !auth.Circuit.Tripped() && (auth.Circuit.ConsecutiveFailures < threshold || !spansModels) {
+		return
+	}
+	if auth.Circuit.State == CircuitHalfOpen {
+		log.Warnf("auth %s circuit probe failed, open for another %s", auth.ID, cooldown)
+	} else if auth.Circuit.State != CircuitOpen {
+		log.Warnf("auth %s circuit opened after %d consecutive upstream failures, probing again in %s", auth.ID, auth.Circuit.ConsecutiveFailures, cooldown)
+	}
+	auth.Circuit.State = CircuitOpen
+	auth.Circuit.OpenedAt = now
+	auth.Circuit.NextProbeAt = now.Add(cooldown)
+}
+
+// claimProbe moves a tripped circuit whose wait is over to half-open and reports whether the
+// caller may send the probe. Requests racing for the same probe get false.
+func (b *circuitBreaker) claimProbe(auth *Auth, now time.Time) bool {
+	if !auth.Circuit.Tripped() {
+		return true
+	}
+	if auth.Circuit.blocks(now) {
+		return false
+	}
+	_, cooldown := b.settings()
+	auth.Circuit.State = CircuitHalfOpen
+	auth.Circuit.NextProbeAt = now.Add(cooldown)
+	return true
+}
+
+// countsAgainstCircuit reports whether a failed request with status points at an unhealthy
+// upstream: transport errors, timeouts and server errors. Quota, auth and request errors have
+// their own handling.
+func countsAgainstCircuit(status int) bool {
+	return status == 0 || status == http.StatusRequestTimeout || status >= http.StatusInternalServerError
+}
+
+// SetCircuitBreaker applies the circuit breaker settings. A zero failure threshold disables the
+// breaker and closes every circuit on the next result.
+func (m *Manager) SetCircuitBreaker(cfg internalconfig.CircuitBreakerConfig) {
+	if m == nil || m.circuit == nil {
+		return
+	}
+	m.circuit.configure(cfg)
+}
+
+// claimCircuitProbe lets the picked auth through its circuit. It returns false when another
+// request already claimed the half-open probe.
+func (m *Manager) claimCircuitProbe(authID string) (*Auth, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	current, ok := m.auths[authID]
+	if !ok || current == nil {
+		return nil, true
+	}
+	if !current.Circuit.Tripped() {
+		return nil, true
+	}
+	if !m.circuit.claimProbe(current, time.Now()) {
+		return nil, false
+	}
+	log.Debugf("auth %s circuit half-open, sending probe request", authID)
+	return current.Clone(), true
+}
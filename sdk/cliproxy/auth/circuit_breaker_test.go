@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestCircuitBreakerOpensAndProbes(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(nil, nil, nil)
+	m.SetCircuitBreaker(internalconfig.CircuitBreakerConfig{FailureThreshold: 2, CooldownSeconds: 30})
+	auth := &Auth{ID: "a", Provider: "claude"}
+	m.auths[auth.ID] = auth
+
+	serverError := Result{AuthID: "a", Provider: "claude", Error: &Error{HTTPStatus: http.StatusBadGateway, Message: "bad gateway"}}
+	m.MarkResult(context.Background(), serverError)
+	if auth.Circuit.Tripped() || auth.Circuit.ConsecutiveFailures != 1 {
+		t.Fatalf("circuit must stay closed below the threshold, got %+v", auth.Circuit)
+	}
+	m.MarkResult(context.Background(), serverError)
+	if auth.Circuit.State != CircuitOpen {
+		t.Fatalf("circuit must open at the threshold, got %+v", auth.Circuit)
+	}
+
+	now := time.Now()
+	blocked, reason, next := isAuthBlockedForModel(auth, "", now)
+	if !blocked || reason != blockReasonCooldown || !next.Equal(auth.Circuit.NextProbeAt) {
+		t.Fatalf("open circuit must block the auth, got %v %v %v", blocked, reason, next)
+	}
+	var cooldown *modelCooldownError
+	if _, err := getAvailableAuths([]*Auth{auth}, "claude", "m", now); !errors.As(err, &cooldown) || cooldown.resetIn <= 0 || cooldown.resetIn > 30*time.Second {
+		t.Fatalf("open circuit must surface as a cooldown until the next probe, got %v", err)
+	}
+	if _, ok := m.claimCircuitProbe("a"); ok {
+		t.Fatal("probe must not be claimed before the cooldown ends")
+	}
+
+	auth.Circuit.NextProbeAt = now.Add(-time.Second)
+	probe, ok := m.claimCircuitProbe("a")
+	if !ok || probe == nil || probe.Circuit.State != CircuitHalfOpen {
+		t.Fatalf("first caller must claim the probe, got %v %+v", ok, probe)
+	}
+	if _, ok = m.claimCircuitProbe("a"); ok {
+		t.Fatal("only one request may claim the half-open probe")
+	}
+
+	m.MarkResult(context.Background(), serverError)
+	if auth.Circuit.State != CircuitOpen || !auth.Circuit.NextProbeAt.After(now) {
+		t.Fatalf("failed probe must reopen the circuit, got %+v", auth.Circuit)
+	}
+
+	auth.Circuit.NextProbeAt = now.Add(-time.Second)
+	if _, ok = m.claimCircuitProbe("a"); !ok {
+		t.Fatal("probe must be claimable after the second cooldown")
+	}
+	m.MarkResult(context.Background(), Result{AuthID: "a", Provider: "claude", Success: true})
+	if auth.Circuit.Tripped() || auth.Circuit.ConsecutiveFailures != 0 {
+		t.Fatalf("successful probe must close the circuit, got %+v", auth.Circuit)
+	}
+}
+
+func TestCircuitBreakerIgnoresClientSideFailures(t *testing.T) {
+	t.Parallel()
+
+	breaker := newCircuitBreaker()
+	auth := &Auth{ID: "a"}
+	now := time.Now()
+	timeout := Result{Error: &Error{HTTPStatus: http.StatusRequestTimeout}}
+
+	breaker.record(auth, timeout, now)
+	if auth.Circuit.ConsecutiveFailures != 0 {
+		t.Fatalf("disabled breaker must not count failures, got %+v", auth.Circuit)
+	}
+
+	breaker.configure(internalconfig.CircuitBreakerConfig{FailureThreshold: 2})
+	breaker.record(auth, timeout, now)
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusUnauthorized, http.StatusBadRequest} {
+		breaker.record(auth, Result{Error: &Error{HTTPStatus: status}}, now)
+		if auth.Circuit.ConsecutiveFailures != 0 {
+			t.Fatalf("status %d must reset the failure count, got %+v", status, auth.Circuit)
+		}
+	}
+	breaker.record(auth, timeout, now)
+	breaker.record(auth, Result{Error: &Error{Message: "connection reset"}}, now)
+	if auth.Circuit.State != CircuitOpen || !auth.Circuit.NextProbeAt.Equal(now.Add(defaultCircuitCooldown)) {
+		t.Fatalf("timeouts and transport errors must open the circuit, got %+v", auth.Circuit)
+	}
+}
+
+func TestCircuitBreakerOpensOnlyForFailuresSpanningModels(t *testing.T) {
+	t.Parallel()
+
+	breaker := newCircuitBreaker()
+	breaker.configure(internalconfig.CircuitBreakerConfig{FailureThreshold: 2})
+	auth := &Auth{ID: "a"}
+	now := time.Now()
+	failure := func(model string) Result {
+		return Result{Model: model, Error: &Error{HTTPStatus: http.StatusInternalServerError}}
+	}
+
+	for i := 0; i < 5; i++ {
+		breaker.record(auth, failure("broken-model"), now)
+	}
+	if auth.Circuit.Tripped() {
+		t.Fatalf("failures of a single model must not open the circuit, got %+v", auth.Circuit)
+	}
+	if blocked, _, _ := isAuthBlockedForModel(auth, "other-model", now); blocked {
+		t.Fatal("other models must stay available")
+	}
+
+	breaker.record(auth, failure("other-model"), now)
+	if auth.Circuit.State != CircuitOpen || len(auth.Circuit.FailedModels) != 2 {
+		t.Fatalf("failures across models must open the circuit, got %+v", auth.Circuit)
+	}
+
+	breaker.record(auth, Result{Model: "broken-model", Success: true}, now)
+	if auth.Circuit.Tripped() || len(auth.Circuit.FailedModels) != 0 {
+		t.Fatalf("a healthy response must reset the circuit, got %+v", auth.Circuit)
+	}
+}
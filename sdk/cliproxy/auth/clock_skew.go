@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// ExpirySafetyMargin is taken off token expiry times before comparing them with the local
+	// clock, so tokens are renewed slightly early and small clock drift never lets an expired
+	// token through.
+	ExpirySafetyMargin = time.Minute
+
+	// clockSkewWarnThreshold is the measured offset from upstream clocks that triggers a warning.
+	clockSkewWarnThreshold = 30 * time.Second
+	// clockSkewWarnInterval spaces repeated warnings while the skew persists.
+	clockSkewWarnInterval = 10 * time.Minute
+	// clockSkewSamples is the number of recent Date observations the estimate is taken from.
+	clockSkewSamples = 16
+	// clockSkewMinSamples is how many observations are needed before the estimate is trusted.
+	clockSkewMinSamples = 3
+	// expiredOnArrivalBackoff delays the next refresh when a freshly refreshed token is already
+	// expired by the local clock, which happens when the clock is skewed and would otherwise loop.
+	expiredOnArrivalBackoff = 5 * time.Minute
+)
+
+// clockSkewTracker estimates the offset between the local clock and upstream clocks from the
+// Date headers of upstream responses, NTP style: the median of recent samples filters out
+// cached responses and proxies with stale dates.
+type clockSkewTracker struct {
+	mu       sync.Mutex
+	samples  []time.Duration
+	next     int
+	warnedAt time.Time
+}
+
+var defaultClockSkew = &clockSkewTracker{}
+
+// ObserveUpstreamDate records the Date header of an upstream response received at the local
+// time received. Missing or malformed headers are ignored.
+func ObserveUpstreamDate(date string, received time.Time) {
+	if date == "" {
+		return
+	}
+	upstream, err := http.ParseTime(date)
+	if err != nil {
+		return
+	}
+	// Date has one second resolution; the upstream time lies within the following second.
+	defaultClockSkew.observe(upstream.Add(500*time.Millisecond).Sub(received), received)
+}
+
+// ClockSkew returns the estimated offset of upstream clocks relative to the local clock,
+// positive when the local clock is behind, and whether enough responses were observed.
+func ClockSkew() (time.Duration, bool) {
+	return defaultClockSkew.estimate()
+}
+
+// TokenExpired reports whether a token expiring at expiry must be treated as expired at now,
+// applying ExpirySafetyMargin.
+func TokenExpired(expiry, now time.Time) bool {
+	return !expiry.Add(-ExpirySafetyMargin).After(now)
+}
+
+// UpstreamTokenExpired is TokenExpired for expiry times issued by an upstream clock, such as the
+// exp claim of a JWT. The local clock is first corrected by the measured skew, so a skewed host
+// does not treat every freshly issued token as expired.
+func UpstreamTokenExpired(expiry, now time.Time) bool {
+	if skew, ok := ClockSkew(); ok {
+		now = now.Add(skew)
+	}
+	return TokenExpired(expiry, now)
+}
+
+func (t *clockSkewTracker) observe(offset time.Duration, now time.Time) {
+	t.mu.Lock()
+	if len(t.samples) < clockSkewSamples {
+		t.samples = append(t.samples, offset)
+	} else {
+		t.samples[t.next] = offset
+		t.next = (t.next + 1) % clockSkewSamples
+	}
+	skew, ok := t.estimateLocked()
+	observed := len(t.samples)
+	warn := ok && absDuration(skew) > clockSkewWarnThreshold && (t.warnedAt.IsZero() || now.Sub(t.warnedAt) >= clockSkewWarnInterval)
+	if warn {
+		t.warnedAt = now
+	}
+	t.mu.Unlock()
+	if warn {
+		direction := "behind"
+		if skew < 0 {
+			direction = "ahead of"
+		}
+		log.Warnf("local clock is %s %s upstream servers (measured from %d response Date headers); token expiry checks will misfire until the system clock is synchronized (NTP)", absDuration(skew).Round(time.Second), direction, observed)
+	}
+}
+
+func (t *clockSkewTracker) estimate() (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.estimateLocked()
+}
+
+func (t *clockSkewTracker) estimateLocked() (time.Duration, bool) {
+	if len(t.samples) < clockSkewMinSamples {
+		return 0, false
+	}
+	sorted := append([]time.Duration(nil), t.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2], true
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func withClockSkewTracker(t *testing.T) *clockSkewTracker {
+	t.Helper()
+	previous := defaultClockSkew
+	defaultClockSkew = &clockSkewTracker{}
+	t.Cleanup(func() { defaultClockSkew = previous })
+	return defaultClockSkew
+}
+
+func TestClockSkewEstimateFromDateHeaders(t *testing.T) {
+	withClockSkewTracker(t)
+	now := time.Now()
+	if _, ok := ClockSkew(); ok {
+		t.Fatalf("skew must be unknown without observations")
+	}
+	// Upstream clocks run ten minutes ahead; one cached response carries a stale date.
+	ahead := now.Add(10 * time.Minute).UTC().Format(http.TimeFormat)
+	ObserveUpstreamDate(ahead, now)
+	ObserveUpstreamDate(now.Add(-3*time.Hour).UTC().Format(http.TimeFormat), now)
+	ObserveUpstreamDate(ahead, now)
+	ObserveUpstreamDate("not a date", now)
+	ObserveUpstreamDate(ahead, now)
+
+	skew, ok := ClockSkew()
+	if !ok {
+		t.Fatalf("skew must be known after three valid observations")
+	}
+	if skew < 9*time.Minute || skew > 11*time.Minute {
+		t.Fatalf("skew = %s, want about 10m", skew)
+	}
+
+	// The JWT was issued by the upstream clock with ten minutes left; locally it looks expired.
+	exp := now.Add(10 * time.Minute).Add(-time.Second)
+	if !TokenExpired(exp, now.Add(10*time.Minute)) {
+		t.Fatalf("TokenExpired must apply the local clock")
+	}
+	if UpstreamTokenExpired(now.Add(15*time.Minute), now) {
+		t.Fatalf("token with five upstream minutes left must not be expired")
+	}
+	if !UpstreamTokenExpired(now.Add(10*time.Minute), now) {
+		t.Fatalf("token expiring at the upstream now must be expired")
+	}
+}
+
+func TestTokenExpiredAppliesSafetyMargin(t *testing.T) {
+	now := time.Now()
+	if !TokenExpired(now.Add(ExpirySafetyMargin/2), now) {
+		t.Fatalf("tokens inside the safety margin must count as expired")
+	}
+	if TokenExpired(now.Add(2*ExpirySafetyMargin), now) {
+		t.Fatalf("tokens beyond the safety margin must be valid")
+	}
+}
+
+type expiredOnArrivalExecutor struct {
+	providerExecutor
+}
+
+func (e *expiredOnArrivalExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) {
+	updated := auth.Clone()
+	updated.Metadata = map[string]any{"expired": time.Now().Add(-time.Hour).Format(time.RFC3339)}
+	return updated, nil
+}
+
+func TestRefreshBacksOffWhenTokenExpiredOnArrival(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&expiredOnArrivalExecutor{providerExecutor{provider: "skewed"}})
+	if _, err := m.Register(context.Background(), &Auth{ID: "skewed-1", Provider: "skewed"}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	m.refreshAuth(context.Background(), "skewed-1")
+
+	auth, _ := m.GetByID("skewed-1")
+	if wait := time.Until(auth.NextRefreshAfter); wait < expiredOnArrivalBackoff-time.Minute {
+		t.Fatalf("next refresh in %s, want about %s", wait, expiredOnArrivalBackoff)
+	}
+	if m.shouldRefresh(auth, time.Now()) {
+		t.Fatalf("expired-on-arrival token must not be refreshed again immediately")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"math"
 	"net/http"
 	"path/filepath"
 	"strconv"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/maintenance"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
@@ -73,6 +75,9 @@ type Result struct {
 	RetryAfter *time.Duration
 	// Error describes the failure when Success is false.
 	Error *Error
+	// FirstTokenLatency is the time until the first chunk of a streamed response arrived. It is
+	// zero for non-streaming requests, whose latency includes the whole generation.
+	FirstTokenLatency time.Duration
 }
 
 // Selector chooses an auth candidate for execution.
@@ -80,6 +85,12 @@ type Selector interface {
 	Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error)
 }
 
+// ResultObserver is implemented by selectors that adapt to execution outcomes; MarkResult
+// forwards every recorded result to the active selector when it implements it.
+type ResultObserver interface {
+	ObserveResult(result Result)
+}
+
 // Hook captures lifecycle callbacks for observing auth changes.
 type Hook interface {
 	// OnAuthRegistered fires when a new auth is registered.
@@ -110,6 +121,8 @@ type Manager struct {
 	hook      Hook
 	mu        sync.RWMutex
 	auths     map[string]*Auth
+	// index groups auths by provider; it is replaced, never mutated, when auths change.
+	index atomic.Pointer[authIndex]
 	// providerOffsets tracks per-model provider rotation state for multi-provider routing.
 	providerOffsets map[string]int
 
@@ -119,10 +132,29 @@ type Manager struct {
 
 	// modelNameMappings stores global model name alias mappings (alias -> upstream name) keyed by channel.
 	modelNameMappings atomic.Value
+	// configModelAliases stores API key model aliases (alias -> upstream names) from the config.
+	configModelAliases atomic.Value
 
 	// Optional HTTP RoundTripper provider injected by host.
 	rtProvider RoundTripperProvider
 
+	// slo tracks first-token latency against the configured objective.
+	slo *latencySLO
+	// overloads counts overloaded upstream responses.
+	overloads *overloadStats
+	// health tracks recent request outcomes per provider.
+	health *healthTracker
+	// dailyCap enforces per-auth daily request and token caps.
+	dailyCap *dailyCapTracker
+	// risk scores credentials and paces risky ones.
+	risk *riskTracker
+	// circuit opens the circuit of credentials whose upstream keeps failing.
+	circuit *circuitBreaker
+	// localFallback serves requests locally once remote credentials are exhausted.
+	localFallback atomic.Pointer[LocalFallback]
+	// refreshSchedule times the proactive refresh of Kiro credentials.
+	refreshSchedule *refreshSchedule
+
 	// Auto refresh state
 	refreshCancel context.CancelFunc
 }
@@ -135,14 +167,23 @@ func NewManager(store Store, selector Selector, hook Hook) *Manager {
 	if hook == nil {
 		hook = NoopHook{}
 	}
-	return &Manager{
+	m := &Manager{
 		store:           store,
 		executors:       make(map[string]ProviderExecutor),
 		selector:        selector,
 		hook:            hook,
 		auths:           make(map[string]*Auth),
 		providerOffsets: make(map[string]int),
+		slo:             newLatencySLO(),
+		overloads:       newOverloadStats(),
+		health:          newHealthTracker(),
+		dailyCap:        newDailyCapTracker(),
+		risk:            newRiskTracker(),
+		circuit:         newCircuitBreaker(),
+		refreshSchedule: newRefreshSchedule(),
 	}
+	m.index.Store(buildAuthIndex(m.auths))
+	return m
 }
 
 // Selector returns the currently configured auth selector.
@@ -228,7 +269,7 @@ func (m *Manager) Register(ctx context.Context, auth *Auth) (*Auth, error) {
 	}
 	auth.EnsureIndex()
 	m.mu.Lock()
-	m.auths[auth.ID] = auth.Clone()
+	m.setAuthLocked(auth.Clone())
 	m.mu.Unlock()
 	_ = m.persist(ctx, auth)
 	m.hook.OnAuthRegistered(ctx, auth.Clone())
@@ -246,7 +287,7 @@ func (m *Manager) Update(ctx context.Context, auth *Auth) (*Auth, error) {
 		auth.indexAssigned = existing.indexAssigned
 	}
 	auth.EnsureIndex()
-	m.auths[auth.ID] = auth.Clone()
+	m.setAuthLocked(auth.Clone())
 	m.mu.Unlock()
 	_ = m.persist(ctx, auth)
 	m.hook.OnAuthUpdated(ctx, auth.Clone())
@@ -272,9 +313,17 @@ func (m *Manager) Load(ctx context.Context) error {
 		auth.EnsureIndex()
 		m.auths[auth.ID] = auth.Clone()
 	}
+	m.index.Store(buildAuthIndex(m.auths))
 	return nil
 }
 
+// setAuthLocked stores auth and publishes an index snapshot reflecting it. Callers hold m.mu.
+func (m *Manager) setAuthLocked(auth *Auth) {
+	prev := m.auths[auth.ID]
+	m.auths[auth.ID] = auth
+	m.index.Store(m.index.Load().withAuth(prev, auth))
+}
+
 // Execute performs a non-streaming execution using the configured selector and executor.
 // It supports multiple providers for the same model and round-robins the starting provider per model.
 func (m *Manager) Execute(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
@@ -289,9 +338,10 @@ func (m *Manager) Execute(ctx context.Context, providers []string, req cliproxye
 		attempts = 1
 	}
 
+	pin := newProviderPin()
 	var lastErr error
 	for attempt := 0; attempt < attempts; attempt++ {
-		resp, errExec := m.executeMixedOnce(ctx, normalized, req, opts)
+		resp, errExec := m.executeMixedOnce(ctx, normalized, pin, req, opts)
 		if errExec == nil {
 			return resp, nil
 		}
@@ -305,7 +355,7 @@ func (m *Manager) Execute(ctx context.Context, providers []string, req cliproxye
 		}
 	}
 	if lastErr != nil {
-		return cliproxyexecutor.Response{}, lastErr
+		return cliproxyexecutor.Response{}, m.withPoolRetryAfter(lastErr, normalized, req.Model)
 	}
 	return cliproxyexecutor.Response{}, &Error{Code: "auth_not_found", Message: "no auth available"}
 }
@@ -324,9 +374,10 @@ func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req clip
 		attempts = 1
 	}
 
+	pin := newProviderPin()
 	var lastErr error
 	for attempt := 0; attempt < attempts; attempt++ {
-		resp, errExec := m.executeCountMixedOnce(ctx, normalized, req, opts)
+		resp, errExec := m.executeCountMixedOnce(ctx, normalized, pin, req, opts)
 		if errExec == nil {
 			return resp, nil
 		}
@@ -340,7 +391,7 @@ func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req clip
 		}
 	}
 	if lastErr != nil {
-		return cliproxyexecutor.Response{}, lastErr
+		return cliproxyexecutor.Response{}, m.withPoolRetryAfter(lastErr, normalized, req.Model)
 	}
 	return cliproxyexecutor.Response{}, &Error{Code: "auth_not_found", Message: "no auth available"}
 }
@@ -359,9 +410,10 @@ func (m *Manager) ExecuteStream(ctx context.Context, providers []string, req cli
 		attempts = 1
 	}
 
+	pin := newProviderPin()
 	var lastErr error
 	for attempt := 0; attempt < attempts; attempt++ {
-		chunks, errStream := m.executeStreamMixedOnce(ctx, normalized, req, opts)
+		chunks, errStream := m.executeStreamMixedOnce(ctx, normalized, pin, req, opts)
 		if errStream == nil {
 			return chunks, nil
 		}
@@ -375,12 +427,12 @@ func (m *Manager) ExecuteStream(ctx context.Context, providers []string, req cli
 		}
 	}
 	if lastErr != nil {
-		return nil, lastErr
+		return nil, m.withPoolRetryAfter(lastErr, normalized, req.Model)
 	}
 	return nil, &Error{Code: "auth_not_found", Message: "no auth available"}
 }
 
-func (m *Manager) executeMixedOnce(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+func (m *Manager) executeMixedOnce(ctx context.Context, providers []string, pin *providerPin, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
 	if len(providers) == 0 {
 		return cliproxyexecutor.Response{}, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
@@ -388,7 +440,7 @@ func (m *Manager) executeMixedOnce(ctx context.Context, providers []string, req
 	tried := make(map[string]struct{})
 	var lastErr error
 	for {
-		auth, executor, provider, errPick := m.pickNextMixed(ctx, providers, routeModel, opts, tried)
+		auth, executor, provider, errPick := m.pickNextMixed(ctx, pin.restrict(providers), routeModel, opts, tried)
 		if errPick != nil {
 			if lastErr != nil {
 				return cliproxyexecutor.Response{}, lastErr
@@ -400,6 +452,8 @@ func (m *Manager) executeMixedOnce(ctx context.Context, providers []string, req
 		debugLogAuthSelection(entry, auth, provider, req.Model)
 
 		tried[auth.ID] = struct{}{}
+		pin.record(provider)
+		countRouteAttempt(ctx)
 		execCtx := ctx
 		if rt := m.roundTripperFor(auth); rt != nil {
 			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
@@ -424,11 +478,12 @@ func (m *Manager) executeMixedOnce(ctx context.Context, providers []string, req
 			continue
 		}
 		m.MarkResult(execCtx, result)
+		recordRoute(ctx, auth, provider, execReq.Model)
 		return resp, nil
 	}
 }
 
-func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string, pin *providerPin, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
 	if len(providers) == 0 {
 		return cliproxyexecutor.Response{}, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
@@ -436,7 +491,7 @@ func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string,
 	tried := make(map[string]struct{})
 	var lastErr error
 	for {
-		auth, executor, provider, errPick := m.pickNextMixed(ctx, providers, routeModel, opts, tried)
+		auth, executor, provider, errPick := m.pickNextMixed(ctx, pin.restrict(providers), routeModel, opts, tried)
 		if errPick != nil {
 			if lastErr != nil {
 				return cliproxyexecutor.Response{}, lastErr
@@ -448,6 +503,8 @@ func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string,
 		debugLogAuthSelection(entry, auth, provider, req.Model)
 
 		tried[auth.ID] = struct{}{}
+		pin.record(provider)
+		countRouteAttempt(ctx)
 		execCtx := ctx
 		if rt := m.roundTripperFor(auth); rt != nil {
 			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
@@ -472,11 +529,12 @@ func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string,
 			continue
 		}
 		m.MarkResult(execCtx, result)
+		recordRoute(ctx, auth, provider, execReq.Model)
 		return resp, nil
 	}
 }
 
-func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (<-chan cliproxyexecutor.StreamChunk, error) {
+func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string, pin *providerPin, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (<-chan cliproxyexecutor.StreamChunk, error) {
 	if len(providers) == 0 {
 		return nil, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
@@ -484,7 +542,7 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 	tried := make(map[string]struct{})
 	var lastErr error
 	for {
-		auth, executor, provider, errPick := m.pickNextMixed(ctx, providers, routeModel, opts, tried)
+		auth, executor, provider, errPick := m.pickNextMixed(ctx, pin.restrict(providers), routeModel, opts, tried)
 		if errPick != nil {
 			if lastErr != nil {
 				return nil, lastErr
@@ -496,6 +554,8 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 		debugLogAuthSelection(entry, auth, provider, req.Model)
 
 		tried[auth.ID] = struct{}{}
+		pin.record(provider)
+		countRouteAttempt(ctx)
 		execCtx := ctx
 		if rt := m.roundTripperFor(auth); rt != nil {
 			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
@@ -504,6 +564,7 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 		execReq := req
 		execReq.Model, execReq.Metadata = rewriteModelForAuth(routeModel, req.Metadata, auth)
 		execReq.Model, execReq.Metadata = m.applyOAuthModelMapping(auth, execReq.Model, execReq.Metadata)
+		started := time.Now()
 		chunks, errStream := executor.ExecuteStream(execCtx, auth, execReq, opts)
 		if errStream != nil {
 			rerr := &Error{Message: errStream.Error()}
@@ -521,7 +582,11 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 		go func(streamCtx context.Context, streamAuth *Auth, streamProvider string, streamChunks <-chan cliproxyexecutor.StreamChunk) {
 			defer close(out)
 			var failed bool
+			var firstToken time.Duration
 			for chunk := range streamChunks {
+				if firstToken == 0 && chunk.Err == nil && len(chunk.Payload) > 0 {
+					firstToken = time.Since(started)
+				}
 				if chunk.Err != nil && !failed {
 					failed = true
 					rerr := &Error{Message: chunk.Err.Error()}
@@ -534,9 +599,10 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 				out <- chunk
 			}
 			if !failed {
-				m.MarkResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: routeModel, Success: true})
+				m.MarkResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: routeModel, Success: true, FirstTokenLatency: firstToken})
 			}
 		}(execCtx, auth.Clone(), provider, chunks)
+		recordRoute(ctx, auth, provider, execReq.Model)
 		return out, nil
 	}
 }
@@ -665,6 +731,7 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 		execReq := req
 		execReq.Model, execReq.Metadata = rewriteModelForAuth(routeModel, req.Metadata, auth)
 		execReq.Model, execReq.Metadata = m.applyOAuthModelMapping(auth, execReq.Model, execReq.Metadata)
+		started := time.Now()
 		chunks, errStream := executor.ExecuteStream(execCtx, auth, execReq, opts)
 		if errStream != nil {
 			rerr := &Error{Message: errStream.Error()}
@@ -682,7 +749,11 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 		go func(streamCtx context.Context, streamAuth *Auth, streamProvider string, streamChunks <-chan cliproxyexecutor.StreamChunk) {
 			defer close(out)
 			var failed bool
+			var firstToken time.Duration
 			for chunk := range streamChunks {
+				if firstToken == 0 && chunk.Err == nil && len(chunk.Payload) > 0 {
+					firstToken = time.Since(started)
+				}
 				if chunk.Err != nil && !failed {
 					failed = true
 					rerr := &Error{Message: chunk.Err.Error()}
@@ -695,7 +766,7 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 				out <- chunk
 			}
 			if !failed {
-				m.MarkResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: routeModel, Success: true})
+				m.MarkResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: routeModel, Success: true, FirstTokenLatency: firstToken})
 			}
 		}(execCtx, auth.Clone(), provider, chunks)
 		return out, nil
@@ -854,10 +925,16 @@ func (m *Manager) shouldRetryAfterError(err error, attempt, maxAttempts int, pro
 	if err == nil || attempt >= maxAttempts-1 {
 		return 0, false
 	}
+	status := statusCodeFromError(err)
+	if maxWait > 0 && maxWait < overloadBackoffMax && isOverloaded(status, err.Error()) {
+		// Overload backoff is short by design; do not let a tight max-retry-interval veto it.
+		// A zero max-retry-interval still disables waiting altogether.
+		maxWait = overloadBackoffMax
+	}
 	if maxWait <= 0 {
 		return 0, false
 	}
-	if status := statusCodeFromError(err); status == http.StatusOK {
+	if status == http.StatusOK {
 		return 0, false
 	}
 	wait, found := m.closestCooldownWait(providers, model)
@@ -928,6 +1005,7 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 	suspendReason := ""
 	clearModelQuota := false
 	setModelQuota := false
+	overloaded := false
 
 	m.mu.Lock()
 	if auth, ok := m.auths[result.AuthID]; ok && auth != nil {
@@ -963,6 +1041,10 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 				}
 
 				statusCode := statusCodeFromResult(result.Error)
+				if result.Error != nil && isOverloaded(statusCode, result.Error.Message) {
+					statusCode = statusOverloaded
+					overloaded = true
+				}
 				switch statusCode {
 				case 401:
 					next := now.Add(30 * time.Minute)
@@ -1001,8 +1083,20 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 					suspendReason = "quota"
 					shouldSuspendModel = true
 					setModelQuota = true
+				case statusOverloaded:
+					// Overload is transient; back off briefly instead of entering quota cooldown.
+					cooldown, nextLevel := nextOverloadCooldown(state.OverloadLevel)
+					if result.RetryAfter != nil {
+						cooldown = *result.RetryAfter
+					}
+					state.OverloadLevel = nextLevel
+					state.NextRetryAfter = now.Add(cooldown)
+					state.StatusMessage = "overloaded"
 				case 408, 500, 502, 503, 504:
 					next := now.Add(1 * time.Minute)
+					if result.RetryAfter != nil {
+						next = now.Add(*result.RetryAfter)
+					}
 					state.NextRetryAfter = next
 				default:
 					state.NextRetryAfter = time.Time{}
@@ -1012,9 +1106,16 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 				auth.UpdatedAt = now
 				updateAggregatedAvailability(auth, now)
 			} else {
+				overloaded = result.Error != nil && isOverloaded(statusCodeFromResult(result.Error), result.Error.Message)
 				applyAuthFailureState(auth, result.Error, result.RetryAfter, now)
 			}
 		}
+		// Success resets clear QuotaState; keep a reached daily cap in force.
+		m.dailyCap.apply(auth)
+		// A request abandoned by the client says nothing about the upstream.
+		if ctx == nil || !errors.Is(ctx.Err(), context.Canceled) {
+			m.circuit.record(auth, result, now)
+		}
 
 		_ = m.persist(ctx, auth)
 	}
@@ -1032,6 +1133,21 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 		registry.GetGlobalRegistry().SuspendClientModel(result.AuthID, result.Model, suspendReason)
 	}
 
+	if result.Success {
+		m.slo.record(result.Provider, result.AuthID, result.Model, result.FirstTokenLatency)
+	}
+	if overloaded {
+		m.overloads.record(result.Provider, result.Model, time.Now())
+	}
+	errMsg := ""
+	if result.Error != nil {
+		errMsg = result.Error.Message
+	}
+	m.health.record(result.Provider, result.Success, errMsg)
+	m.risk.recordResult(result)
+	if observer, ok := m.Selector().(ResultObserver); ok {
+		observer.ObserveResult(result)
+	}
 	m.hook.OnResult(ctx, result)
 }
 
@@ -1060,6 +1176,7 @@ func resetModelState(state *ModelState, now time.Time) {
 	state.NextRetryAfter = time.Time{}
 	state.LastError = nil
 	state.Quota = QuotaState{}
+	state.OverloadLevel = 0
 	state.UpdatedAt = now
 }
 
@@ -1155,6 +1272,7 @@ func clearAuthStateOnSuccess(auth *Auth, now time.Time) {
 	auth.Quota.Reason = ""
 	auth.Quota.NextRecoverAt = time.Time{}
 	auth.Quota.BackoffLevel = 0
+	auth.OverloadLevel = 0
 	auth.LastError = nil
 	auth.NextRetryAfter = time.Time{}
 	auth.UpdatedAt = now
@@ -1186,6 +1304,49 @@ func statusCodeFromError(err error) int {
 	return 0
 }
 
+// withPoolRetryAfter attaches the soonest credential recovery time to a rate limited error, so
+// clients receive a Retry-After covering the whole pool rather than the last credential tried.
+func (m *Manager) withPoolRetryAfter(err error, providers []string, model string) error {
+	status := statusCodeFromError(err)
+	if status != http.StatusTooManyRequests && status != http.StatusServiceUnavailable && status != statusOverloaded {
+		return err
+	}
+	wait, found := m.closestCooldownWait(providers, model)
+	if !found {
+		return err
+	}
+	return &retryAfterError{err: err, status: status, wait: wait}
+}
+
+// retryAfterError decorates an upstream error with a Retry-After header.
+type retryAfterError struct {
+	err    error
+	status int
+	wait   time.Duration
+}
+
+func (e *retryAfterError) Error() string { return e.err.Error() }
+
+func (e *retryAfterError) Unwrap() error { return e.err }
+
+func (e *retryAfterError) StatusCode() int { return e.status }
+
+func (e *retryAfterError) RetryAfter() *time.Duration {
+	wait := e.wait
+	return &wait
+}
+
+func (e *retryAfterError) Headers() http.Header {
+	headers := make(http.Header)
+	if he, ok := e.err.(interface{ Headers() http.Header }); ok {
+		for key, values := range he.Headers() {
+			headers[key] = append([]string(nil), values...)
+		}
+	}
+	headers.Set("Retry-After", strconv.Itoa(int(math.Ceil(e.wait.Seconds()))))
+	return headers
+}
+
 func retryAfterFromError(err error) *time.Duration {
 	if err == nil {
 		return nil
@@ -1226,6 +1387,9 @@ func applyAuthFailureState(auth *Auth, resultErr *Error, retryAfter *time.Durati
 		}
 	}
 	statusCode := statusCodeFromResult(resultErr)
+	if resultErr != nil && isOverloaded(statusCode, resultErr.Message) {
+		statusCode = statusOverloaded
+	}
 	switch statusCode {
 	case 401:
 		auth.StatusMessage = "unauthorized"
@@ -1252,6 +1416,11 @@ func applyAuthFailureState(auth *Auth, resultErr *Error, retryAfter *time.Durati
 		}
 		auth.Quota.NextRecoverAt = next
 		auth.NextRetryAfter = next
+	case statusOverloaded:
+		cooldown, nextLevel := nextOverloadCooldown(auth.OverloadLevel)
+		auth.OverloadLevel = nextLevel
+		auth.StatusMessage = "overloaded"
+		auth.NextRetryAfter = now.Add(cooldown)
 	case 408, 500, 502, 503, 504:
 		auth.StatusMessage = "transient upstream error"
 		auth.NextRetryAfter = now.Add(1 * time.Minute)
@@ -1313,25 +1482,24 @@ func (m *Manager) pickNext(ctx context.Context, provider, model string, opts cli
 		m.mu.RUnlock()
 		return nil, nil, &Error{Code: "executor_not_found", Message: "executor not registered"}
 	}
-	candidates := make([]*Auth, 0, len(m.auths))
 	modelKey := strings.TrimSpace(model)
 	registryRef := registry.GetGlobalRegistry()
-	for _, candidate := range m.auths {
+	indexed := m.index.Load().candidates(provider, modelKey, registryRef)
+	candidates := make([]*Auth, 0, len(indexed))
+	for _, candidate := range indexed {
 		if candidate.Provider != provider || candidate.Disabled {
 			continue
 		}
 		if _, used := tried[candidate.ID]; used {
 			continue
 		}
-		if modelKey != "" && registryRef != nil && !registryRef.ClientSupportsModel(candidate.ID, modelKey) {
-			continue
-		}
 		candidates = append(candidates, candidate)
 	}
 	if len(candidates) == 0 {
 		m.mu.RUnlock()
 		return nil, nil, &Error{Code: "auth_not_found", Message: "no auth available"}
 	}
+	candidates = m.slo.filterViolators(model, candidates)
 	selected, errPick := m.selector.Pick(ctx, provider, model, opts, candidates)
 	if errPick != nil {
 		m.mu.RUnlock()
@@ -1351,6 +1519,20 @@ func (m *Manager) pickNext(ctx context.Context, provider, model string, opts cli
 		}
 		m.mu.Unlock()
 	}
+	if authCopy.Circuit.Tripped() {
+		probe, claimed := m.claimCircuitProbe(authCopy.ID)
+		if !claimed {
+			// Another request is already probing this auth; pick among the others.
+			tried[authCopy.ID] = struct{}{}
+			return m.pickNext(ctx, provider, model, opts, tried)
+		}
+		if probe != nil {
+			authCopy = probe
+		}
+	}
+	if errPace := m.risk.pace(ctx, authCopy.ID); errPace != nil {
+		return nil, nil, errPace
+	}
 	return authCopy, executor, nil
 }
 
@@ -1368,35 +1550,29 @@ func (m *Manager) pickNextMixed(ctx context.Context, providers []string, model s
 	}
 
 	m.mu.RLock()
-	candidates := make([]*Auth, 0, len(m.auths))
 	modelKey := strings.TrimSpace(model)
 	registryRef := registry.GetGlobalRegistry()
-	for _, candidate := range m.auths {
-		if candidate == nil || candidate.Disabled {
-			continue
-		}
-		providerKey := strings.TrimSpace(strings.ToLower(candidate.Provider))
-		if providerKey == "" {
-			continue
-		}
-		if _, ok := providerSet[providerKey]; !ok {
-			continue
-		}
-		if _, used := tried[candidate.ID]; used {
-			continue
-		}
+	index := m.index.Load()
+	var candidates []*Auth
+	for providerKey := range providerSet {
 		if _, ok := m.executors[providerKey]; !ok {
 			continue
 		}
-		if modelKey != "" && registryRef != nil && !registryRef.ClientSupportsModel(candidate.ID, modelKey) {
-			continue
+		for _, candidate := range index.candidates(providerKey, modelKey, registryRef) {
+			if candidate.Disabled {
+				continue
+			}
+			if _, used := tried[candidate.ID]; used {
+				continue
+			}
+			candidates = append(candidates, candidate)
 		}
-		candidates = append(candidates, candidate)
 	}
 	if len(candidates) == 0 {
 		m.mu.RUnlock()
 		return nil, nil, "", &Error{Code: "auth_not_found", Message: "no auth available"}
 	}
+	candidates = m.slo.filterViolators(model, candidates)
 	selected, errPick := m.selector.Pick(ctx, "mixed", model, opts, candidates)
 	if errPick != nil {
 		m.mu.RUnlock()
@@ -1422,6 +1598,20 @@ func (m *Manager) pickNextMixed(ctx context.Context, providers []string, model s
 		}
 		m.mu.Unlock()
 	}
+	if authCopy.Circuit.Tripped() {
+		probe, claimed := m.claimCircuitProbe(authCopy.ID)
+		if !claimed {
+			// Another request is already probing this auth; pick among the others.
+			tried[authCopy.ID] = struct{}{}
+			return m.pickNextMixed(ctx, providers, model, opts, tried)
+		}
+		if probe != nil {
+			authCopy = probe
+		}
+	}
+	if errPace := m.risk.pace(ctx, authCopy.ID); errPace != nil {
+		return nil, nil, "", errPace
+	}
 	return authCopy, executor, providerKey, nil
 }
 
@@ -1490,6 +1680,9 @@ func (m *Manager) checkRefreshes(ctx context.Context) {
 			if !m.shouldRefresh(a, now) {
 				continue
 			}
+			if _, _, inMaintenance := maintenance.Default().InMaintenance(a.Provider); inMaintenance {
+				continue
+			}
 			log.Debugf("checking refresh for %s, %s, %s", a.Provider, a.ID, typ)
 
 			if exec := m.executorFor(a.Provider); exec == nil {
@@ -1535,7 +1728,7 @@ func (m *Manager) shouldRefresh(a *Auth, now time.Time) bool {
 
 	if interval := authPreferredInterval(a); interval > 0 {
 		if hasExpiry && !expiry.IsZero() {
-			if !expiry.After(now) {
+			if TokenExpired(expiry, now) {
 				return true
 			}
 			if expiry.Sub(now) <= interval {
@@ -1548,6 +1741,10 @@ func (m *Manager) shouldRefresh(a *Auth, now time.Time) bool {
 		return now.Sub(lastRefresh) >= interval
 	}
 
+	if due, ok := m.refreshSchedule.dueAt(a); ok {
+		return !now.Before(due)
+	}
+
 	provider := strings.ToLower(a.Provider)
 	lead := ProviderRefreshLead(provider, a.Runtime)
 	if lead == nil {
@@ -1555,12 +1752,12 @@ func (m *Manager) shouldRefresh(a *Auth, now time.Time) bool {
 	}
 	if *lead <= 0 {
 		if hasExpiry && !expiry.IsZero() {
-			return now.After(expiry)
+			return TokenExpired(expiry, now)
 		}
 		return false
 	}
 	if hasExpiry && !expiry.IsZero() {
-		return time.Until(expiry) <= *lead
+		return expiry.Sub(now) <= *lead || TokenExpired(expiry, now)
 	}
 	if !lastRefresh.IsZero() {
 		return now.Sub(lastRefresh) >= *lead
@@ -1755,10 +1952,18 @@ func (m *Manager) refreshAuth(ctx context.Context, id string) {
 	}
 	log.Debugf("refreshed %s, %s, %v", auth.Provider, auth.ID, err)
 	now := time.Now()
+	if refreshNeedsReauth(err) {
+		m.markReauthRequired(ctx, id, err, now)
+		return
+	}
 	if err != nil {
+		backoff := refreshFailureBackoff
+		if m.refreshSchedule.applies(auth) {
+			backoff = m.refreshSchedule.failed(id)
+		}
 		m.mu.Lock()
 		if current := m.auths[id]; current != nil {
-			current.NextRefreshAfter = now.Add(refreshFailureBackoff)
+			current.NextRefreshAfter = now.Add(backoff)
 			current.LastError = &Error{Message: err.Error()}
 			m.auths[id] = current
 		}
@@ -1774,14 +1979,73 @@ func (m *Manager) refreshAuth(ctx context.Context, id string) {
 		updated.Runtime = auth.Runtime
 	}
 	updated.LastRefreshedAt = now
+	if due, ok := m.refreshSchedule.dueAt(updated); ok {
+		m.refreshSchedule.succeeded(id)
+		if due.After(now) {
+			updated.NextRefreshAfter = due
+		}
+	}
+	if expiry, ok := updated.ExpirationTime(); ok && !expiry.IsZero() && TokenExpired(expiry, now) {
+		// A token that is expired on arrival would be refreshed again on the next check; back off
+		// instead of looping, which happens when the local clock is skewed.
+		skew, measured := ClockSkew()
+		log.Warnf("refreshed %s auth %s already expires at %s (local time %s, measured upstream clock skew %s, known=%t); check the system clock", updated.Provider, updated.ID, expiry.Format(time.RFC3339), now.Format(time.RFC3339), skew.Round(time.Second), measured)
+		if updated.NextRefreshAfter.Before(now.Add(expiredOnArrivalBackoff)) {
+			updated.NextRefreshAfter = now.Add(expiredOnArrivalBackoff)
+		}
+	}
 	// Preserve NextRefreshAfter set by the Authenticator
 	// If the Authenticator set a reasonable refresh time, it should not be overwritten
 	// If the Authenticator did not set it (zero value), shouldRefresh will use default logic
+	if auth.NeedsReauth() {
+		// A re-check succeeded, e.g. after the grant was restored; return the credential to rotation.
+		log.Infof("auth %s (%s) refreshed again, leaving re-login quarantine", updated.ID, updated.Provider)
+		updated.Status = StatusActive
+		updated.StatusMessage = ""
+	}
+	updated.ReauthChecks = 0
 	updated.LastError = nil
 	updated.UpdatedAt = now
 	_, _ = m.Update(ctx, updated)
 }
 
+// markReauthRequired disables routing to a credential whose refresh can no longer succeed and
+// notifies hooks, so the state is visible until the user logs in again and replaces the record.
+func (m *Manager) markReauthRequired(ctx context.Context, id string, err error, now time.Time) {
+	m.mu.RLock()
+	current := m.auths[id]
+	m.mu.RUnlock()
+	if current == nil {
+		return
+	}
+	updated := current.Clone()
+	if current.NeedsReauth() {
+		updated.ReauthChecks++
+	} else {
+		updated.ReauthChecks = 0
+		log.Warnf("auth %s (%s) needs re-login: %v", updated.ID, updated.Provider, err)
+	}
+	updated.Status = StatusDisabled
+	updated.StatusMessage = err.Error()
+	updated.LastError = &Error{Code: ErrorCodeReauthRequired, Message: err.Error(), HTTPStatus: http.StatusUnauthorized}
+	updated.NextRefreshAfter = now.Add(reauthRecheckDelay(updated.ReauthChecks))
+	updated.UpdatedAt = now
+	_, _ = m.Update(ctx, updated)
+}
+
+// Executor returns the executor registered for provider. An executor registered lazily is built
+// on the way, so callers can reach provider-specific methods of the concrete type.
+func (m *Manager) Executor(provider string) (ProviderExecutor, bool) {
+	if m == nil {
+		return nil, false
+	}
+	exec := m.executorFor(provider)
+	if exec == nil {
+		return nil, false
+	}
+	return resolveExecutor(exec), true
+}
+
 func (m *Manager) executorFor(provider string) ProviderExecutor {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -1911,7 +2175,7 @@ func (m *Manager) InjectCredentials(req *http.Request, authID string) error {
 	if a == nil || exec == nil {
 		return nil
 	}
-	if p, ok := exec.(RequestPreparer); ok && p != nil {
+	if p, ok := resolveExecutor(exec).(RequestPreparer); ok && p != nil {
 		return p.PrepareRequest(req, a)
 	}
 	return nil
@@ -1939,7 +2203,7 @@ func (m *Manager) PrepareHttpRequest(ctx context.Context, auth *Auth, req *http.
 	if exec == nil {
 		return &Error{Code: "provider_not_found", Message: "executor not registered for provider: " + providerKey}
 	}
-	preparer, ok := exec.(RequestPreparer)
+	preparer, ok := resolveExecutor(exec).(RequestPreparer)
 	if !ok || preparer == nil {
 		return &Error{Code: "not_supported", Message: "executor does not support http request preparation"}
 	}
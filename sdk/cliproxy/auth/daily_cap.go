@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+	log "github.com/sirupsen/logrus"
+)
+
+// quotaReasonDailyCap marks a QuotaState set because the auth reached its daily cap.
+const quotaReasonDailyCap = "daily_cap"
+
+// dailyCapTracker counts upstream requests and tokens per auth for the current local day.
+type dailyCapTracker struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	requests  int64
+	tokens    int64
+	location  *time.Location
+	providers map[string]struct{}
+	day       string
+	usage     map[string]*dailyCapUsage
+}
+
+type dailyCapUsage struct {
+	requests int64
+	tokens   int64
+}
+
+func newDailyCapTracker() *dailyCapTracker {
+	return &dailyCapTracker{now: time.Now, location: time.Local, usage: map[string]*dailyCapUsage{}}
+}
+
+func (t *dailyCapTracker) configure(cfg internalconfig.AuthDailyCapConfig) {
+	location := time.Local
+	if zone := strings.TrimSpace(cfg.Timezone); zone != "" {
+		loaded, err := time.LoadLocation(zone)
+		if err != nil {
+			log.Warnf("auth-daily-cap: unknown timezone %q, using local time: %v", zone, err)
+		} else {
+			location = loaded
+		}
+	}
+	providers := make(map[string]struct{}, len(cfg.Providers))
+	for _, provider := range cfg.Providers {
+		if provider = strings.ToLower(strings.TrimSpace(provider)); provider != "" {
+			providers[provider] = struct{}{}
+		}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.requests = max(cfg.Requests, 0)
+	t.tokens = max(cfg.Tokens, 0)
+	t.providers = providers
+	if location.String() != t.location.String() {
+		// Day keys are relative to the zone; start over rather than mix windows.
+		t.day = ""
+	}
+	t.location = location
+}
+
+// applies reports whether the caps cover auth.
+func (t *dailyCapTracker) applies(auth *Auth) bool {
+	if auth == nil || (t.requests == 0 && t.tokens == 0) {
+		return false
+	}
+	if typ, _ := auth.AccountInfo(); typ == "api_key" {
+		return false
+	}
+	if len(t.providers) == 0 {
+		return true
+	}
+	_, ok := t.providers[strings.ToLower(strings.TrimSpace(auth.Provider))]
+	return ok
+}
+
+// record charges one request and its tokens to auth.
+func (t *dailyCapTracker) record(auth *Auth, tokens int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.applies(auth) {
+		return
+	}
+	used := t.usageLocked(auth.ID)
+	used.requests++
+	used.tokens += max(tokens, 0)
+}
+
+// apply marks auth as quota exceeded until the next reset when it has reached a cap.
+func (t *dailyCapTracker) apply(auth *Auth) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.applies(auth) {
+		return
+	}
+	used := t.usageLocked(auth.ID)
+	if (t.requests == 0 || used.requests < t.requests) && (t.tokens == 0 || used.tokens < t.tokens) {
+		return
+	}
+	reset := t.nextResetLocked()
+	if auth.Quota.Exceeded && auth.Quota.Reason == quotaReasonDailyCap && auth.Quota.NextRecoverAt.Equal(reset) {
+		return
+	}
+	log.Infof("auth %s reached its daily cap (%d requests, %d tokens), paused until %s", auth.ID, used.requests, used.tokens, reset.Format(time.RFC3339))
+	auth.Quota = QuotaState{Exceeded: true, Reason: quotaReasonDailyCap, NextRecoverAt: reset}
+	auth.Unavailable = true
+	auth.NextRetryAfter = reset
+	auth.StatusMessage = "daily cap reached"
+}
+
+// usageLocked returns the counters for authID, starting over when the local day changes.
+func (t *dailyCapTracker) usageLocked(authID string) *dailyCapUsage {
+	day := t.now().In(t.location).Format(time.DateOnly)
+	if day != t.day {
+		t.day = day
+		t.usage = map[string]*dailyCapUsage{}
+	}
+	used, ok := t.usage[authID]
+	if !ok {
+		used = &dailyCapUsage{}
+		t.usage[authID] = used
+	}
+	return used
+}
+
+func (t *dailyCapTracker) nextResetLocked() time.Time {
+	now := t.now().In(t.location)
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, t.location)
+}
+
+// isDailyCapped reports whether auth is paused by its daily cap at now.
+func isDailyCapped(auth *Auth, now time.Time) bool {
+	return auth.Quota.Exceeded && auth.Quota.Reason == quotaReasonDailyCap && auth.Quota.NextRecoverAt.After(now)
+}
+
+// SetAuthDailyCap applies the per-auth daily request and token caps. Counters for the current
+// day are kept.
+func (m *Manager) SetAuthDailyCap(cfg internalconfig.AuthDailyCapConfig) {
+	if m == nil || m.dailyCap == nil {
+		return
+	}
+	m.dailyCap.configure(cfg)
+}
+
+// HandleUsage implements coreusage.Plugin and charges each upstream attempt to the daily cap of
+// the auth that served it.
+func (m *Manager) HandleUsage(_ context.Context, record coreusage.Record) {
+	if m == nil || m.dailyCap == nil || record.AuthID == "" {
+		return
+	}
+	tokens := record.Detail.TotalTokens
+	if tokens == 0 {
+		tokens = record.Detail.InputTokens + record.Detail.OutputTokens
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	auth, ok := m.auths[record.AuthID]
+	if !ok || auth == nil {
+		return
+	}
+	m.dailyCap.record(auth, tokens)
+	m.dailyCap.apply(auth)
+}
@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+func TestDailyCapPausesAuthUntilLocalMidnight(t *testing.T) {
+	t.Parallel()
+
+	zone := time.FixedZone("UTC+8", 8*3600)
+	now := time.Date(2025, 3, 4, 22, 0, 0, 0, zone)
+	m := NewManager(nil, nil, nil)
+	m.dailyCap.now = func() time.Time { return now }
+	m.dailyCap.configure(internalconfig.AuthDailyCapConfig{Requests: 2, Tokens: 1000})
+	m.dailyCap.location = zone
+
+	capped := &Auth{ID: "a", Provider: "claude", Metadata: map[string]any{"email": "a@example.com"}}
+	other := &Auth{ID: "b", Provider: "claude", Metadata: map[string]any{"email": "b@example.com"}}
+	m.auths[capped.ID] = capped
+	m.auths[other.ID] = other
+
+	m.HandleUsage(context.Background(), coreusage.Record{AuthID: "a", Detail: coreusage.Detail{TotalTokens: 10}})
+	if isDailyCapped(capped, now) {
+		t.Fatal("auth must stay available below its cap")
+	}
+	m.HandleUsage(context.Background(), coreusage.Record{AuthID: "a", Detail: coreusage.Detail{TotalTokens: 10}})
+	if !isDailyCapped(capped, now) {
+		t.Fatalf("auth must be capped after reaching the request cap, quota = %+v", capped.Quota)
+	}
+	if want := time.Date(2025, 3, 5, 0, 0, 0, 0, zone); !capped.Quota.NextRecoverAt.Equal(want) {
+		t.Fatalf("NextRecoverAt = %v, want local midnight %v", capped.Quota.NextRecoverAt, want)
+	}
+
+	m.HandleUsage(context.Background(), coreusage.Record{AuthID: "b", Detail: coreusage.Detail{InputTokens: 600, OutputTokens: 400}})
+	if !isDailyCapped(other, now) {
+		t.Fatal("auth must be capped after reaching the token cap")
+	}
+
+	blocked, reason, next := isAuthBlockedForModel(capped, "claude-sonnet-4-5", now)
+	if !blocked || reason != blockReasonCooldown || !next.Equal(capped.Quota.NextRecoverAt) {
+		t.Fatalf("capped auth must be in cooldown for every model, got %v %v %v", blocked, reason, next)
+	}
+
+	// A later success resets QuotaState, but the cap stays in force for the day.
+	m.MarkResult(context.Background(), Result{AuthID: "a", Provider: "claude", Success: true})
+	if !isDailyCapped(capped, now) {
+		t.Fatal("success must not lift a reached daily cap")
+	}
+
+	now = now.Add(3 * time.Hour)
+	if blocked, _, _ = isAuthBlockedForModel(capped, "claude-sonnet-4-5", now); blocked {
+		t.Fatal("cap must lift after local midnight")
+	}
+	m.HandleUsage(context.Background(), coreusage.Record{AuthID: "a"})
+	if isDailyCapped(capped, now) {
+		t.Fatal("counters must reset on the new local day")
+	}
+}
+
+func TestDailyCapSkipsAPIKeysAndOtherProviders(t *testing.T) {
+	t.Parallel()
+
+	tracker := newDailyCapTracker()
+	tracker.configure(internalconfig.AuthDailyCapConfig{Requests: 1, Providers: []string{"Codex"}})
+
+	apiKey := &Auth{ID: "k", Provider: "codex", Attributes: map[string]string{"api_key": "sk-test"}}
+	gemini := &Auth{ID: "g", Provider: "gemini"}
+	codex := &Auth{ID: "c", Provider: "codex"}
+	for _, auth := range []*Auth{apiKey, gemini, codex} {
+		tracker.record(auth, 0)
+		tracker.apply(auth)
+	}
+	now := time.Now()
+	if isDailyCapped(apiKey, now) || isDailyCapped(gemini, now) {
+		t.Fatal("api keys and providers outside the list must not be capped")
+	}
+	if !isDailyCapped(codex, now) {
+		t.Fatal("listed provider must be capped")
+	}
+}
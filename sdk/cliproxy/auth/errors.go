@@ -1,5 +1,11 @@
 package auth
 
+import "errors"
+
+// ErrReauthRequired is wrapped by executors when a refresh failed in a way retrying cannot fix,
+// such as a revoked refresh token. The credential is taken out of rotation until replaced.
+var ErrReauthRequired = errors.New("re-login required")
+
 // Error describes an authentication related failure in a provider agnostic format.
 type Error struct {
 	// Code is a short machine readable identifier.
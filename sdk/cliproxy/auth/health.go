@@ -0,0 +1,304 @@
+package auth
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	healthWindow = 15 * time.Minute
+	// healthMinSamples is the number of results needed before error rates influence the status.
+	healthMinSamples        = 5
+	healthDegradedErrorRate = 0.25
+	healthDownErrorRate     = 0.9
+)
+
+// Provider health states reported by ProviderHealthReport.
+const (
+	HealthHealthy  = "healthy"
+	HealthDegraded = "degraded"
+	HealthDown     = "down"
+	HealthIdle     = "idle"
+)
+
+// ProviderHealth summarizes the recent state of one provider.
+type ProviderHealth struct {
+	Provider string `json:"provider"`
+	Status   string `json:"status"`
+	// Diagnosis explains a degraded or down status: "accounts_unavailable" when credentials are
+	// suspended or cooling down, "upstream_errors" when usable credentials keep failing.
+	Diagnosis        string        `json:"diagnosis,omitempty"`
+	Requests         int64         `json:"requests"`
+	Errors           int64         `json:"errors"`
+	ErrorRate        float64       `json:"error_rate"`
+	WindowSeconds    int64         `json:"window_seconds"`
+	LastSuccess      *time.Time    `json:"last_success,omitempty"`
+	LastError        *time.Time    `json:"last_error,omitempty"`
+	LastErrorMessage string        `json:"last_error_message,omitempty"`
+	Auths            ProviderAuths `json:"auths"`
+	Suspended        []HealthBlock `json:"suspended,omitempty"`
+	Cooldowns        []HealthBlock `json:"cooldowns,omitempty"`
+}
+
+// ProviderAuths counts credentials of a provider by availability.
+type ProviderAuths struct {
+	Total     int `json:"total"`
+	Available int `json:"available"`
+	Disabled  int `json:"disabled"`
+	Suspended int `json:"suspended"`
+	Cooling   int `json:"cooling"`
+}
+
+// HealthBlock describes a credential, or one of its models, that is currently not routable.
+type HealthBlock struct {
+	AuthID string    `json:"auth_id"`
+	Label  string    `json:"label,omitempty"`
+	Model  string    `json:"model,omitempty"`
+	Reason string    `json:"reason"`
+	Until  time.Time `json:"until"`
+}
+
+type healthBucket struct {
+	start  time.Time
+	total  int64
+	errors int64
+}
+
+type providerHealthStats struct {
+	buckets          []healthBucket
+	lastSuccess      time.Time
+	lastError        time.Time
+	lastErrorMessage string
+}
+
+// healthTracker records request outcomes per provider over a rolling window.
+type healthTracker struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	providers map[string]*providerHealthStats
+}
+
+func newHealthTracker() *healthTracker {
+	return &healthTracker{now: time.Now, providers: make(map[string]*providerHealthStats)}
+}
+
+func (t *healthTracker) record(provider string, success bool, errMsg string) {
+	if t == nil {
+		return
+	}
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	if provider == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	stats, ok := t.providers[provider]
+	if !ok {
+		stats = &providerHealthStats{}
+		t.providers[provider] = stats
+	}
+	stats.prune(now)
+	start := now.Truncate(sloBucketWidth)
+	if n := len(stats.buckets); n == 0 || !stats.buckets[n-1].start.Equal(start) {
+		stats.buckets = append(stats.buckets, healthBucket{start: start})
+	}
+	bucket := &stats.buckets[len(stats.buckets)-1]
+	bucket.total++
+	if success {
+		stats.lastSuccess = now
+		return
+	}
+	bucket.errors++
+	stats.lastError = now
+	stats.lastErrorMessage = errMsg
+}
+
+func (s *providerHealthStats) prune(now time.Time) {
+	cutoff := now.Add(-healthWindow)
+	drop := 0
+	for drop < len(s.buckets) && !s.buckets[drop].start.Add(sloBucketWidth).After(cutoff) {
+		drop++
+	}
+	if drop > 0 {
+		s.buckets = append(s.buckets[:0], s.buckets[drop:]...)
+	}
+}
+
+// fill copies the rolling counters of provider into health.
+func (t *healthTracker) fill(health *ProviderHealth, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats, ok := t.providers[health.Provider]
+	if !ok {
+		return
+	}
+	stats.prune(now)
+	for _, bucket := range stats.buckets {
+		health.Requests += bucket.total
+		health.Errors += bucket.errors
+	}
+	if health.Requests > 0 {
+		health.ErrorRate = float64(health.Errors) / float64(health.Requests)
+	}
+	if !stats.lastSuccess.IsZero() {
+		lastSuccess := stats.lastSuccess
+		health.LastSuccess = &lastSuccess
+	}
+	if !stats.lastError.IsZero() {
+		lastError := stats.lastError
+		health.LastError = &lastError
+		health.LastErrorMessage = stats.lastErrorMessage
+	}
+}
+
+func (t *healthTracker) providerNames() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	names := make([]string, 0, len(t.providers))
+	for name := range t.providers {
+		names = append(names, name)
+	}
+	return names
+}
+
+// ProviderHealthReport aggregates recent error rates, suspended credentials and cooldowns per
+// provider, telling upstream outages apart from unusable accounts.
+func (m *Manager) ProviderHealthReport() []ProviderHealth {
+	if m == nil {
+		return nil
+	}
+	now := m.health.now()
+	byProvider := make(map[string]*ProviderHealth)
+	get := func(provider string) *ProviderHealth {
+		health, ok := byProvider[provider]
+		if !ok {
+			health = &ProviderHealth{Provider: provider, WindowSeconds: int64(healthWindow.Seconds())}
+			byProvider[provider] = health
+		}
+		return health
+	}
+
+	m.mu.RLock()
+	for _, auth := range m.auths {
+		if auth == nil {
+			continue
+		}
+		provider := strings.ToLower(strings.TrimSpace(auth.Provider))
+		if provider == "" {
+			continue
+		}
+		health := get(provider)
+		health.Auths.Total++
+		if auth.Disabled || auth.Status == StatusDisabled {
+			health.Auths.Disabled++
+			continue
+		}
+		blocked := collectHealthBlocks(auth, now)
+		suspended, cooling := false, false
+		for _, block := range blocked {
+			if block.Reason == "quota" || block.Reason == "overloaded" || block.Reason == "transient" || block.Reason == "circuit_open" {
+				health.Cooldowns = append(health.Cooldowns, block)
+				cooling = true
+			} else {
+				health.Suspended = append(health.Suspended, block)
+				suspended = true
+			}
+		}
+		switch {
+		case suspended:
+			health.Auths.Suspended++
+		case cooling:
+			health.Auths.Cooling++
+		default:
+			health.Auths.Available++
+		}
+	}
+	m.mu.RUnlock()
+
+	for _, provider := range m.health.providerNames() {
+		get(provider)
+	}
+	report := make([]ProviderHealth, 0, len(byProvider))
+	for _, health := range byProvider {
+		m.health.fill(health, now)
+		health.Status, health.Diagnosis = classifyHealth(health)
+		sortHealthBlocks(health.Suspended)
+		sortHealthBlocks(health.Cooldowns)
+		report = append(report, *health)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Provider < report[j].Provider })
+	return report
+}
+
+// collectHealthBlocks lists the per-model blocks active at now, or the credential level block
+// when no model is blocked. Credential state aggregates model states, so both would repeat. An
+// open circuit blocks every model and is reported alone.
+func collectHealthBlocks(auth *Auth, now time.Time) []HealthBlock {
+	if auth.Circuit.blocks(now) {
+		return []HealthBlock{{AuthID: auth.ID, Label: auth.Label, Reason: "circuit_open", Until: auth.Circuit.NextProbeAt}}
+	}
+	var blocks []HealthBlock
+	for model, state := range auth.ModelStates {
+		if state == nil || !state.Unavailable || !state.NextRetryAfter.After(now) {
+			continue
+		}
+		blocks = append(blocks, HealthBlock{AuthID: auth.ID, Label: auth.Label, Model: model, Reason: blockReasonFor(state.LastError, state.Quota.Exceeded), Until: state.NextRetryAfter})
+	}
+	if len(blocks) == 0 && auth.Unavailable && auth.NextRetryAfter.After(now) {
+		blocks = append(blocks, HealthBlock{AuthID: auth.ID, Label: auth.Label, Reason: blockReasonFor(auth.LastError, auth.Quota.Exceeded), Until: auth.NextRetryAfter})
+	}
+	return blocks
+}
+
+// blockReasonFor names why a credential is blocked, mirroring the cooldowns set by MarkResult.
+func blockReasonFor(lastErr *Error, quotaExceeded bool) string {
+	if quotaExceeded {
+		return "quota"
+	}
+	status := statusCodeFromResult(lastErr)
+	if lastErr != nil && isOverloaded(status, lastErr.Message) {
+		return "overloaded"
+	}
+	switch status {
+	case http.StatusUnauthorized:
+		return "unauthorized"
+	case http.StatusPaymentRequired, http.StatusForbidden:
+		return "payment_required"
+	case http.StatusNotFound:
+		return "not_found"
+	default:
+		return "transient"
+	}
+}
+
+func classifyHealth(health *ProviderHealth) (string, string) {
+	accountsDown := health.Auths.Total > 0 && health.Auths.Available == 0
+	sampled := health.Requests >= healthMinSamples
+	switch {
+	case accountsDown:
+		return HealthDown, "accounts_unavailable"
+	case sampled && health.ErrorRate >= healthDownErrorRate:
+		return HealthDown, "upstream_errors"
+	case health.Auths.Suspended > 0 || health.Auths.Cooling > 0:
+		return HealthDegraded, "accounts_unavailable"
+	case sampled && health.ErrorRate >= healthDegradedErrorRate:
+		return HealthDegraded, "upstream_errors"
+	case health.Requests == 0:
+		return HealthIdle, ""
+	default:
+		return HealthHealthy, ""
+	}
+}
+
+func sortHealthBlocks(blocks []HealthBlock) {
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].AuthID != blocks[j].AuthID {
+			return blocks[i].AuthID < blocks[j].AuthID
+		}
+		return blocks[i].Model < blocks[j].Model
+	})
+}
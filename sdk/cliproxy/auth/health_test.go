@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestProviderHealthReport(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	ctx := context.Background()
+	for _, auth := range []*Auth{{ID: "k1", Provider: "kiro"}, {ID: "k2", Provider: "kiro"}, {ID: "c1", Provider: "claude"}} {
+		if _, err := m.Register(ctx, auth); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	m.MarkResult(ctx, Result{AuthID: "k1", Provider: "kiro", Model: "m", Error: &Error{HTTPStatus: http.StatusForbidden, Message: "suspended"}})
+	m.MarkResult(ctx, Result{AuthID: "k2", Provider: "kiro", Model: "m", Error: &Error{HTTPStatus: http.StatusTooManyRequests, Message: "slow down"}})
+	for i := 0; i < healthMinSamples; i++ {
+		m.MarkResult(ctx, Result{AuthID: "c1", Provider: "claude", Model: "m", Success: true})
+	}
+
+	report := m.ProviderHealthReport()
+	if len(report) != 2 {
+		t.Fatalf("expected two providers, got %+v", report)
+	}
+	claude, kiro := report[0], report[1]
+	if claude.Provider != "claude" || claude.Status != HealthHealthy || claude.Requests != healthMinSamples || claude.LastSuccess == nil {
+		t.Fatalf("unexpected claude health: %+v", claude)
+	}
+	if kiro.Status != HealthDown || kiro.Diagnosis != "accounts_unavailable" {
+		t.Fatalf("unexpected kiro status %q/%q", kiro.Status, kiro.Diagnosis)
+	}
+	if kiro.Auths.Suspended != 1 || kiro.Auths.Cooling != 1 || kiro.ErrorRate != 1 || kiro.LastErrorMessage != "slow down" {
+		t.Fatalf("unexpected kiro health: %+v", kiro)
+	}
+	if len(kiro.Suspended) != 1 || kiro.Suspended[0].Reason != "payment_required" || len(kiro.Cooldowns) != 1 || kiro.Cooldowns[0].Reason != "quota" {
+		t.Fatalf("unexpected kiro blocks: %+v %+v", kiro.Suspended, kiro.Cooldowns)
+	}
+}
+
+func TestClassifyHealthUpstreamErrors(t *testing.T) {
+	t.Parallel()
+
+	health := &ProviderHealth{Requests: 10, Errors: 9, ErrorRate: 0.9, Auths: ProviderAuths{Total: 1, Available: 1}}
+	if status, diagnosis := classifyHealth(health); status != HealthDown || diagnosis != "upstream_errors" {
+		t.Fatalf("classifyHealth() = %q/%q", status, diagnosis)
+	}
+	health = &ProviderHealth{Auths: ProviderAuths{Total: 1, Available: 1}}
+	if status, _ := classifyHealth(health); status != HealthIdle {
+		t.Fatalf("classifyHealth() = %q, want idle", status)
+	}
+}
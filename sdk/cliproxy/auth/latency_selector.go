@@ -0,0 +1,202 @@
+package auth
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+const (
+	// defaultLatencyAlpha is the EWMA smoothing factor used when LatencySelector.Alpha is unset.
+	defaultLatencyAlpha = 0.2
+	// latencyErrorHalfLife is how long it takes an auth's error rate to halve without new results,
+	// so an auth that failed a burst gets tried again once things calm down.
+	latencyErrorHalfLife = 5 * time.Minute
+	// latencyUnhealthyErrorRate is the decayed error rate from which an auth is avoided.
+	latencyUnhealthyErrorRate = 0.5
+	// latencyTolerance treats auths within this factor of the fastest one as equally fast, so
+	// the load spreads over them instead of piling onto a single credential.
+	latencyTolerance = 1.25
+)
+
+// LatencySelector prefers the fastest healthy auths of the best priority tier. It keeps an
+// exponentially weighted moving average of the first-token latency and the error rate of every
+// auth and model, fed back from execution results, so a slow model does not hold back the
+// credential's other models. Auths without measurements are tried first, unhealthy
+// auths are skipped while a healthy one remains, and auths within latencyTolerance of the
+// fastest are served in turn.
+type LatencySelector struct {
+	// Alpha is the weight of a new sample in the moving averages, in (0, 1]. Zero uses 0.2.
+	Alpha float64
+
+	mu      sync.Mutex
+	stats   map[latencyKey]*latencyStats
+	cursors map[string]int
+	now     func() time.Time
+}
+
+// latencyKey identifies the measurements of one auth serving one model.
+type latencyKey struct {
+	authID string
+	model  string
+}
+
+type latencyStats struct {
+	// latency is the EWMA of successful first-token latency; zero until a success is observed.
+	latency time.Duration
+	// errorRate is the EWMA of failures (1) and successes (0) as of updatedAt.
+	errorRate float64
+	updatedAt time.Time
+}
+
+// ObserveResult folds an execution result into the moving averages of the auth and model.
+// Non-streaming results carry no first-token latency and only update the error rate. Client
+// errors that do not reflect on the credential, such as a malformed request, are ignored.
+func (s *LatencySelector) ObserveResult(result Result) {
+	if s == nil || result.AuthID == "" {
+		return
+	}
+	if !result.Success && !countsAgainstAuth(result) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stats == nil {
+		s.stats = make(map[latencyKey]*latencyStats)
+	}
+	now := s.clock()
+	alpha := s.alpha()
+	key := latencyKey{authID: result.AuthID, model: result.Model}
+	stats, ok := s.stats[key]
+	if !ok {
+		stats = &latencyStats{}
+		s.stats[key] = stats
+	}
+	failure := 0.0
+	if !result.Success {
+		failure = 1
+	}
+	if ok {
+		previous := stats.decayedErrorRate(now)
+		stats.errorRate = previous + alpha*(failure-previous)
+	} else {
+		stats.errorRate = failure
+	}
+	stats.updatedAt = now
+	if result.Success && result.FirstTokenLatency > 0 {
+		if stats.latency <= 0 {
+			stats.latency = result.FirstTokenLatency
+		} else {
+			stats.latency += time.Duration(alpha * float64(result.FirstTokenLatency-stats.latency))
+		}
+	}
+}
+
+// Pick selects the fastest healthy auth for the provider and model.
+func (s *LatencySelector) Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	_ = ctx
+	_ = opts
+	available, err := getAvailableAuths(auths, provider, model, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if len(available) == 1 {
+		return available[0], nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.clock()
+	var unmeasured, healthy []*Auth
+	latency := make(map[string]time.Duration, len(available))
+	for _, candidate := range available {
+		stats := s.stats[latencyKey{authID: candidate.ID, model: model}]
+		switch {
+		case stats == nil:
+			unmeasured = append(unmeasured, candidate)
+		case stats.decayedErrorRate(now) >= latencyUnhealthyErrorRate:
+		case stats.latency <= 0:
+			// Healthy again but never timed, e.g. after failures decayed away.
+			unmeasured = append(unmeasured, candidate)
+		default:
+			healthy = append(healthy, candidate)
+			latency[candidate.ID] = stats.latency
+		}
+	}
+	key := provider + ":" + model
+	if len(unmeasured) > 0 {
+		return s.nextLocked(key, unmeasured), nil
+	}
+	if len(healthy) == 0 {
+		// Every auth is failing; keep rotating so the averages can recover.
+		return s.nextLocked(key, available), nil
+	}
+	fastest := latency[healthy[0].ID]
+	for _, candidate := range healthy[1:] {
+		fastest = min(fastest, latency[candidate.ID])
+	}
+	limit := time.Duration(float64(fastest) * latencyTolerance)
+	fast := healthy[:0]
+	for _, candidate := range healthy {
+		if latency[candidate.ID] <= limit {
+			fast = append(fast, candidate)
+		}
+	}
+	return s.nextLocked(key, fast), nil
+}
+
+// nextLocked rotates through candidates for key. The caller must hold s.mu.
+func (s *LatencySelector) nextLocked(key string, candidates []*Auth) *Auth {
+	if s.cursors == nil {
+		s.cursors = make(map[string]int)
+	}
+	index := s.cursors[key]
+	if index >= 2_147_483_640 {
+		index = 0
+	}
+	s.cursors[key] = index + 1
+	return candidates[index%len(candidates)]
+}
+
+func (s *LatencySelector) alpha() float64 {
+	if s.Alpha <= 0 || s.Alpha > 1 {
+		return defaultLatencyAlpha
+	}
+	return s.Alpha
+}
+
+func (s *LatencySelector) clock() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+// decayedErrorRate returns the error rate after halving it once per latencyErrorHalfLife
+// elapsed since the last result.
+func (l *latencyStats) decayedErrorRate(now time.Time) float64 {
+	elapsed := now.Sub(l.updatedAt)
+	if elapsed <= 0 {
+		return l.errorRate
+	}
+	return l.errorRate * math.Exp2(-float64(elapsed)/float64(latencyErrorHalfLife))
+}
+
+// countsAgainstAuth reports whether a failed result says something about the auth or its
+// upstream rather than about the request itself.
+func countsAgainstAuth(result Result) bool {
+	status := statusCodeFromResult(result.Error)
+	switch {
+	case status == 0, status >= http.StatusInternalServerError:
+		return true
+	case status == http.StatusUnauthorized, status == http.StatusForbidden,
+		status == http.StatusRequestTimeout, status == http.StatusTooManyRequests:
+		return true
+	default:
+		return false
+	}
+}
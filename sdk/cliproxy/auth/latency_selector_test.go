@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func pickLatencyCounts(t *testing.T, selector *LatencySelector, auths []*Auth, n int) map[string]int {
+	t.Helper()
+	counts := map[string]int{}
+	for i := 0; i < n; i++ {
+		got, err := selector.Pick(context.Background(), "kiro", "", cliproxyexecutor.Options{}, auths)
+		if err != nil {
+			t.Fatalf("Pick() #%d error = %v", i, err)
+		}
+		counts[got.ID]++
+	}
+	return counts
+}
+
+func TestLatencySelectorPick_PrefersFastest(t *testing.T) {
+	t.Parallel()
+
+	selector := &LatencySelector{}
+	auths := []*Auth{{ID: "slow"}, {ID: "fast"}, {ID: "close"}}
+	selector.ObserveResult(Result{AuthID: "slow", Success: true, FirstTokenLatency: 2 * time.Second})
+	selector.ObserveResult(Result{AuthID: "fast", Success: true, FirstTokenLatency: 400 * time.Millisecond})
+	selector.ObserveResult(Result{AuthID: "close", Success: true, FirstTokenLatency: 450 * time.Millisecond})
+
+	counts := pickLatencyCounts(t, selector, auths, 6)
+	if counts["slow"] != 0 || counts["fast"] != 3 || counts["close"] != 3 {
+		t.Fatalf("counts = %v, want fast and close sharing every pick", counts)
+	}
+}
+
+func TestLatencySelectorPick_ExploresUnmeasuredFirst(t *testing.T) {
+	t.Parallel()
+
+	selector := &LatencySelector{}
+	auths := []*Auth{{ID: "a"}, {ID: "b"}}
+	selector.ObserveResult(Result{AuthID: "a", Success: true, FirstTokenLatency: time.Second})
+
+	counts := pickLatencyCounts(t, selector, auths, 2)
+	if counts["b"] != 2 {
+		t.Fatalf("counts = %v, want the unmeasured auth picked", counts)
+	}
+}
+
+func TestLatencySelectorPick_SkipsFailingAuths(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	selector := &LatencySelector{now: func() time.Time { return now }}
+	auths := []*Auth{{ID: "fast"}, {ID: "slow"}}
+	selector.ObserveResult(Result{AuthID: "fast", Success: true, FirstTokenLatency: 100 * time.Millisecond})
+	selector.ObserveResult(Result{AuthID: "slow", Success: true, FirstTokenLatency: time.Second})
+	for i := 0; i < 4; i++ {
+		selector.ObserveResult(Result{AuthID: "fast", Error: &Error{HTTPStatus: http.StatusBadGateway}})
+	}
+	// Client errors say nothing about the auth.
+	selector.ObserveResult(Result{AuthID: "slow", Error: &Error{HTTPStatus: http.StatusBadRequest}})
+
+	if counts := pickLatencyCounts(t, selector, auths, 3); counts["slow"] != 3 {
+		t.Fatalf("counts = %v, want the failing auth skipped", counts)
+	}
+
+	// The error rate decays, so the fast auth comes back once its failures age out.
+	now = now.Add(20 * time.Minute)
+	if counts := pickLatencyCounts(t, selector, auths, 3); counts["fast"] != 3 {
+		t.Fatalf("counts after decay = %v, want the fast auth back", counts)
+	}
+}
+
+func TestLatencySelectorPick_RespectsPriority(t *testing.T) {
+	t.Parallel()
+
+	selector := &LatencySelector{}
+	low := &Auth{ID: "low"}
+	high := &Auth{ID: "high", Attributes: map[string]string{"priority": "1"}}
+	selector.ObserveResult(Result{AuthID: "low", Success: true, FirstTokenLatency: time.Millisecond})
+	selector.ObserveResult(Result{AuthID: "high", Success: true, FirstTokenLatency: time.Second})
+
+	if counts := pickLatencyCounts(t, selector, []*Auth{low, high}, 3); counts["high"] != 3 {
+		t.Fatalf("counts = %v, want the higher priority auth only", counts)
+	}
+}
+
+func TestManagerMarkResult_FeedsResultObserver(t *testing.T) {
+	t.Parallel()
+
+	selector := &LatencySelector{}
+	manager := NewManager(nil, selector, nil)
+	manager.MarkResult(context.Background(), Result{AuthID: "a", Provider: "kiro", Success: true, FirstTokenLatency: time.Second})
+
+	selector.mu.Lock()
+	defer selector.mu.Unlock()
+	if stats := selector.stats[latencyKey{authID: "a"}]; stats == nil || stats.latency != time.Second {
+		t.Fatalf("stats = %+v, want the result observed", stats)
+	}
+}
+
+func TestLatencySelectorPick_TracksModelsSeparately(t *testing.T) {
+	t.Parallel()
+
+	selector := &LatencySelector{}
+	auths := []*Auth{{ID: "a"}, {ID: "b"}}
+	selector.ObserveResult(Result{AuthID: "a", Model: "slow-model", Success: true, FirstTokenLatency: 5 * time.Second})
+	selector.ObserveResult(Result{AuthID: "b", Model: "slow-model", Success: true, FirstTokenLatency: time.Second})
+	selector.ObserveResult(Result{AuthID: "a", Model: "fast-model", Success: true, FirstTokenLatency: 100 * time.Millisecond})
+	selector.ObserveResult(Result{AuthID: "b", Model: "fast-model", Success: true, FirstTokenLatency: time.Second})
+
+	for model, want := range map[string]string{"slow-model": "b", "fast-model": "a"} {
+		for i := 0; i < 3; i++ {
+			got, err := selector.Pick(context.Background(), "kiro", model, cliproxyexecutor.Options{}, auths)
+			if err != nil {
+				t.Fatalf("Pick(%s) error = %v", model, err)
+			}
+			if got.ID != want {
+				t.Fatalf("Pick(%s) = %s, want %s", model, got.ID, want)
+			}
+		}
+	}
+}
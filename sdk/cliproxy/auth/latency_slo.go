@@ -0,0 +1,284 @@
+package auth
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+const (
+	defaultSLOTarget     = 0.95
+	defaultSLOWindow     = 15 * time.Minute
+	defaultSLOMinSamples = 20
+	// sloBucketWidth is the resolution of the rolling window.
+	sloBucketWidth = 10 * time.Second
+)
+
+// LatencySLOSeries reports first-token latency attainment for one provider/model or auth/model pair.
+type LatencySLOSeries struct {
+	Provider   string  `json:"provider,omitempty"`
+	AuthID     string  `json:"auth_id,omitempty"`
+	Model      string  `json:"model"`
+	Samples    int64   `json:"samples"`
+	Met        int64   `json:"met"`
+	Attainment float64 `json:"attainment"`
+	AvgMS      float64 `json:"avg_ms"`
+	MaxMS      int64   `json:"max_ms"`
+	Violating  bool    `json:"violating"`
+}
+
+// LatencySLOReport is a point-in-time view of the first-token latency objective.
+type LatencySLOReport struct {
+	Enabled        bool               `json:"enabled"`
+	ThresholdMS    int64              `json:"threshold_ms"`
+	Target         float64            `json:"target"`
+	WindowSeconds  int64              `json:"window_seconds"`
+	MinSamples     int64              `json:"min_samples"`
+	RoutingPenalty bool               `json:"routing_penalty"`
+	Providers      []LatencySLOSeries `json:"providers"`
+	Auths          []LatencySLOSeries `json:"auths"`
+}
+
+type sloKey struct {
+	owner string
+	model string
+}
+
+type sloBucket struct {
+	start time.Time
+	total int64
+	met   int64
+	sum   time.Duration
+	max   time.Duration
+}
+
+// sloSeries keeps fixed-width buckets covering the rolling window.
+type sloSeries struct {
+	buckets []sloBucket
+}
+
+// latencySLO tracks first-token latency samples against the configured objective.
+type latencySLO struct {
+	mu         sync.Mutex
+	now        func() time.Time
+	threshold  time.Duration
+	target     float64
+	window     time.Duration
+	minSamples int64
+	penalty    bool
+	providers  map[sloKey]*sloSeries
+	auths      map[sloKey]*sloSeries
+}
+
+func newLatencySLO() *latencySLO {
+	return &latencySLO{
+		now:       time.Now,
+		providers: make(map[sloKey]*sloSeries),
+		auths:     make(map[sloKey]*sloSeries),
+	}
+}
+
+func (s *latencySLO) configure(cfg internalconfig.LatencySLOConfig) {
+	target := cfg.Target
+	if target <= 0 || target > 1 {
+		target = defaultSLOTarget
+	}
+	window := time.Duration(cfg.WindowSeconds) * time.Second
+	if window <= 0 {
+		window = defaultSLOWindow
+	}
+	minSamples := int64(cfg.MinSamples)
+	if minSamples <= 0 {
+		minSamples = defaultSLOMinSamples
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.threshold = time.Duration(max(cfg.FirstTokenMS, 0)) * time.Millisecond
+	s.target = target
+	s.window = window
+	s.minSamples = minSamples
+	s.penalty = cfg.RoutingPenalty
+	if s.threshold == 0 {
+		s.providers = make(map[sloKey]*sloSeries)
+		s.auths = make(map[sloKey]*sloSeries)
+	}
+}
+
+// record adds one first-token latency sample.
+func (s *latencySLO) record(provider, authID, model string, ttfb time.Duration) {
+	if s == nil || ttfb <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.threshold <= 0 {
+		return
+	}
+	now := s.now()
+	met := ttfb <= s.threshold
+	model = strings.TrimSpace(model)
+	s.seriesLocked(s.providers, sloKey{owner: strings.ToLower(strings.TrimSpace(provider)), model: model}).add(now, s.window, ttfb, met)
+	if authID != "" {
+		s.seriesLocked(s.auths, sloKey{owner: authID, model: model}).add(now, s.window, ttfb, met)
+	}
+}
+
+// filterViolators drops candidates violating the objective for model, unless that would leave
+// no candidate at all.
+func (s *latencySLO) filterViolators(model string, candidates []*Auth) []*Auth {
+	if s == nil || len(candidates) < 2 {
+		return candidates
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.threshold <= 0 || !s.penalty || len(s.auths) == 0 {
+		return candidates
+	}
+	now := s.now()
+	model = strings.TrimSpace(model)
+	healthy := make([]*Auth, 0, len(candidates))
+	for _, candidate := range candidates {
+		series, ok := s.auths[sloKey{owner: candidate.ID, model: model}]
+		if ok && s.violatingLocked(series.summarize(now, s.window)) {
+			continue
+		}
+		healthy = append(healthy, candidate)
+	}
+	if len(healthy) == 0 {
+		return candidates
+	}
+	return healthy
+}
+
+func (s *latencySLO) report() LatencySLOReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	report := LatencySLOReport{
+		Enabled:        s.threshold > 0,
+		ThresholdMS:    s.threshold.Milliseconds(),
+		Target:         s.target,
+		WindowSeconds:  int64(s.window.Seconds()),
+		MinSamples:     s.minSamples,
+		RoutingPenalty: s.penalty,
+		Providers:      make([]LatencySLOSeries, 0, len(s.providers)),
+		Auths:          make([]LatencySLOSeries, 0, len(s.auths)),
+	}
+	now := s.now()
+	for key, series := range s.providers {
+		summary, ok := s.summaryLocked(series, now)
+		if !ok {
+			delete(s.providers, key)
+			continue
+		}
+		summary.Provider, summary.Model = key.owner, key.model
+		report.Providers = append(report.Providers, summary)
+	}
+	for key, series := range s.auths {
+		summary, ok := s.summaryLocked(series, now)
+		if !ok {
+			delete(s.auths, key)
+			continue
+		}
+		summary.AuthID, summary.Model = key.owner, key.model
+		report.Auths = append(report.Auths, summary)
+	}
+	sort.Slice(report.Providers, func(i, j int) bool {
+		if report.Providers[i].Provider != report.Providers[j].Provider {
+			return report.Providers[i].Provider < report.Providers[j].Provider
+		}
+		return report.Providers[i].Model < report.Providers[j].Model
+	})
+	sort.Slice(report.Auths, func(i, j int) bool {
+		if report.Auths[i].AuthID != report.Auths[j].AuthID {
+			return report.Auths[i].AuthID < report.Auths[j].AuthID
+		}
+		return report.Auths[i].Model < report.Auths[j].Model
+	})
+	return report
+}
+
+func (s *latencySLO) summaryLocked(series *sloSeries, now time.Time) (LatencySLOSeries, bool) {
+	summary := series.summarize(now, s.window)
+	if summary.Samples == 0 {
+		return summary, false
+	}
+	summary.Violating = s.violatingLocked(summary)
+	return summary, true
+}
+
+func (s *latencySLO) violatingLocked(summary LatencySLOSeries) bool {
+	return summary.Samples >= s.minSamples && summary.Attainment < s.target
+}
+
+func (s *latencySLO) seriesLocked(m map[sloKey]*sloSeries, key sloKey) *sloSeries {
+	series, ok := m[key]
+	if !ok {
+		series = &sloSeries{}
+		m[key] = series
+	}
+	return series
+}
+
+func (s *sloSeries) add(now time.Time, window, ttfb time.Duration, met bool) {
+	start := now.Truncate(sloBucketWidth)
+	s.prune(now, window)
+	if n := len(s.buckets); n == 0 || !s.buckets[n-1].start.Equal(start) {
+		s.buckets = append(s.buckets, sloBucket{start: start})
+	}
+	bucket := &s.buckets[len(s.buckets)-1]
+	bucket.total++
+	if met {
+		bucket.met++
+	}
+	bucket.sum += ttfb
+	bucket.max = max(bucket.max, ttfb)
+}
+
+func (s *sloSeries) prune(now time.Time, window time.Duration) {
+	cutoff := now.Add(-window)
+	drop := 0
+	for drop < len(s.buckets) && !s.buckets[drop].start.Add(sloBucketWidth).After(cutoff) {
+		drop++
+	}
+	if drop > 0 {
+		s.buckets = append(s.buckets[:0], s.buckets[drop:]...)
+	}
+}
+
+func (s *sloSeries) summarize(now time.Time, window time.Duration) LatencySLOSeries {
+	s.prune(now, window)
+	var summary LatencySLOSeries
+	var sum, maxLatency time.Duration
+	for _, bucket := range s.buckets {
+		summary.Samples += bucket.total
+		summary.Met += bucket.met
+		sum += bucket.sum
+		maxLatency = max(maxLatency, bucket.max)
+	}
+	if summary.Samples > 0 {
+		summary.Attainment = float64(summary.Met) / float64(summary.Samples)
+		summary.AvgMS = float64(sum) / float64(time.Millisecond) / float64(summary.Samples)
+	}
+	summary.MaxMS = maxLatency.Milliseconds()
+	return summary
+}
+
+// SetLatencySLO applies the first-token latency objective. A zero threshold disables tracking
+// and clears collected samples.
+func (m *Manager) SetLatencySLO(cfg internalconfig.LatencySLOConfig) {
+	if m == nil || m.slo == nil {
+		return
+	}
+	m.slo.configure(cfg)
+}
+
+// LatencySLOReport returns first-token latency attainment per provider/model and per auth/model.
+func (m *Manager) LatencySLOReport() LatencySLOReport {
+	if m == nil || m.slo == nil {
+		return LatencySLOReport{}
+	}
+	return m.slo.report()
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestLatencySLOReportAndPenalty(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+	slo := newLatencySLO()
+	slo.now = func() time.Time { return now }
+	slo.configure(internalconfig.LatencySLOConfig{FirstTokenMS: 1000, Target: 0.9, MinSamples: 4, RoutingPenalty: true})
+
+	for i := 0; i < 4; i++ {
+		slo.record("claude", "slow", "m", 2*time.Second)
+		slo.record("claude", "fast", "m", 200*time.Millisecond)
+	}
+
+	report := slo.report()
+	if len(report.Providers) != 1 || report.Providers[0].Samples != 8 || report.Providers[0].Attainment != 0.5 {
+		t.Fatalf("unexpected provider series: %+v", report.Providers)
+	}
+	if len(report.Auths) != 2 || !report.Auths[1].Violating || report.Auths[0].Violating {
+		t.Fatalf("unexpected auth series: %+v", report.Auths)
+	}
+
+	candidates := []*Auth{{ID: "slow"}, {ID: "fast"}}
+	if got := slo.filterViolators("m", candidates); len(got) != 1 || got[0].ID != "fast" {
+		t.Fatalf("filterViolators() = %+v, want only fast", got)
+	}
+	if got := slo.filterViolators("m", candidates[:1]); len(got) != 1 {
+		t.Fatalf("filterViolators() must keep the last candidate, got %+v", got)
+	}
+	if got := slo.filterViolators("other", candidates); len(got) != 2 {
+		t.Fatalf("filterViolators() must ignore other models, got %+v", got)
+	}
+
+	now = now.Add(defaultSLOWindow + sloBucketWidth)
+	if got := slo.filterViolators("m", candidates); len(got) != 2 {
+		t.Fatalf("violations should expire with the window, got %+v", got)
+	}
+	if report = slo.report(); len(report.Auths) != 0 {
+		t.Fatalf("expired series should be dropped, got %+v", report.Auths)
+	}
+}
+
+func TestLatencySLOIgnoresNonStreamingRequests(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.SetLatencySLO(internalconfig.LatencySLOConfig{FirstTokenMS: 1000})
+	m.RegisterExecutor(&providerExecutor{provider: "slo-nonstream"})
+	registerIndexedAuth(t, m, "slo-nonstream-1", "slo-nonstream", "slo-model")
+
+	if _, err := m.Execute(context.Background(), []string{"slo-nonstream"}, cliproxyexecutor.Request{Model: "slo-model"}, cliproxyexecutor.Options{}); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if report := m.LatencySLOReport(); len(report.Providers) != 0 || len(report.Auths) != 0 {
+		t.Fatalf("non-streaming requests must not record first-token samples, got %+v", report)
+	}
+}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// lazyExecutor registers a provider without constructing its executor. The real executor is
+// built on the first call, so startup with many providers does not pay for unused ones.
+type lazyExecutor struct {
+	provider string
+	once     sync.Once
+	build    func() ProviderExecutor
+	exec     ProviderExecutor
+}
+
+// NewLazyExecutor returns an executor for provider that calls build on first use and delegates
+// to the result from then on. build must return a non-nil executor.
+func NewLazyExecutor(provider string, build func() ProviderExecutor) ProviderExecutor {
+	return &lazyExecutor{provider: provider, build: build}
+}
+
+func (e *lazyExecutor) resolve() ProviderExecutor {
+	e.once.Do(func() {
+		e.exec = e.build()
+		e.build = nil
+	})
+	return e.exec
+}
+
+// resolveExecutor returns the concrete executor behind exec so optional interfaces such as
+// RequestPreparer can be detected.
+func resolveExecutor(exec ProviderExecutor) ProviderExecutor {
+	if lazy, ok := exec.(*lazyExecutor); ok {
+		return lazy.resolve()
+	}
+	return exec
+}
+
+func (e *lazyExecutor) Identifier() string { return e.provider }
+
+func (e *lazyExecutor) Execute(ctx context.Context, auth *Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return e.resolve().Execute(ctx, auth, req, opts)
+}
+
+func (e *lazyExecutor) ExecuteStream(ctx context.Context, auth *Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (<-chan cliproxyexecutor.StreamChunk, error) {
+	return e.resolve().ExecuteStream(ctx, auth, req, opts)
+}
+
+func (e *lazyExecutor) Refresh(ctx context.Context, auth *Auth) (*Auth, error) {
+	return e.resolve().Refresh(ctx, auth)
+}
+
+func (e *lazyExecutor) CountTokens(ctx context.Context, auth *Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return e.resolve().CountTokens(ctx, auth, req, opts)
+}
+
+func (e *lazyExecutor) HttpRequest(ctx context.Context, auth *Auth, req *http.Request) (*http.Response, error) {
+	return e.resolve().HttpRequest(ctx, auth, req)
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+type preparingExecutor struct {
+	providerExecutor
+}
+
+func (e *preparingExecutor) PrepareRequest(req *http.Request, _ *Auth) error {
+	req.Header.Set("X-Prepared", e.provider)
+	return nil
+}
+
+func TestLazyExecutorBuildsOnFirstUse(t *testing.T) {
+	builds := 0
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(NewLazyExecutor("lazy", func() ProviderExecutor {
+		builds++
+		return &preparingExecutor{providerExecutor{provider: "lazy"}}
+	}))
+	if _, err := m.Register(context.Background(), &Auth{ID: "lazy-1", Provider: "lazy"}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if builds != 0 {
+		t.Fatalf("executor built during registration")
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	if err := m.InjectCredentials(req, "lazy-1"); err != nil {
+		t.Fatalf("inject credentials: %v", err)
+	}
+	if req.Header.Get("X-Prepared") != "lazy" {
+		t.Fatalf("RequestPreparer of the built executor was not used")
+	}
+	if _, err := m.Execute(context.Background(), []string{"lazy"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{}); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if builds != 1 {
+		t.Fatalf("builds = %d, want 1", builds)
+	}
+	if exec, ok := m.Executor("lazy"); !ok {
+		t.Fatal("registered executor not found")
+	} else if _, concrete := exec.(*preparingExecutor); !concrete {
+		t.Fatalf("Executor must return the built executor, got %T", exec)
+	}
+	if _, ok := m.Executor("missing"); ok {
+		t.Fatal("unregistered provider must not have an executor")
+	}
+}
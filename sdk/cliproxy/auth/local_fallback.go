@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// LocalFallbackHeader tags responses served by the local fallback model.
+const LocalFallbackHeader = "X-CLIProxy-Degraded"
+
+// LocalFallback serves requests from a local model (for example a llama.cpp server) once every
+// remote credential is exhausted. It bypasses credential selection and model registration.
+type LocalFallback struct {
+	Executor ProviderExecutor
+	Auth     *Auth
+	// Model replaces the requested model; empty keeps it.
+	Model string
+}
+
+// SetLocalFallback installs or, with nil, removes the local fallback.
+func (m *Manager) SetLocalFallback(fallback *LocalFallback) {
+	if m == nil {
+		return
+	}
+	if fallback != nil && (fallback.Executor == nil || fallback.Auth == nil) {
+		fallback = nil
+	}
+	m.localFallback.Store(fallback)
+}
+
+// ShouldUseLocalFallback reports whether err means the remote credentials that serve the request
+// are exhausted or cooling down and a local fallback is configured. A model no credential serves
+// at all (auth_not_found) is not a fallback case, so unknown or misspelled models still fail.
+func (m *Manager) ShouldUseLocalFallback(err error) bool {
+	if m == nil || err == nil || m.localFallback.Load() == nil {
+		return false
+	}
+	var authErr *Error
+	if errors.As(err, &authErr) && authErr.Code == "auth_unavailable" {
+		return true
+	}
+	switch statusCodeFromError(err) {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable, statusOverloaded:
+		return true
+	}
+	return false
+}
+
+// ExecuteLocalFallback runs a non-streaming request against the local fallback.
+func (m *Manager) ExecuteLocalFallback(ctx context.Context, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	fallback := m.localFallback.Load()
+	if fallback == nil {
+		return cliproxyexecutor.Response{}, &Error{Code: "provider_not_found", Message: "local fallback not configured"}
+	}
+	return fallback.Executor.Execute(ctx, fallback.Auth, fallback.request(req), opts)
+}
+
+// ExecuteStreamLocalFallback runs a streaming request against the local fallback.
+func (m *Manager) ExecuteStreamLocalFallback(ctx context.Context, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (<-chan cliproxyexecutor.StreamChunk, error) {
+	fallback := m.localFallback.Load()
+	if fallback == nil {
+		return nil, &Error{Code: "provider_not_found", Message: "local fallback not configured"}
+	}
+	return fallback.Executor.ExecuteStream(ctx, fallback.Auth, fallback.request(req), opts)
+}
+
+func (f *LocalFallback) request(req cliproxyexecutor.Request) cliproxyexecutor.Request {
+	if f.Model != "" {
+		req.Model = f.Model
+	}
+	return req
+}
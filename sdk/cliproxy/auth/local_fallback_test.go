@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+type recordingExecutor struct {
+	model string
+}
+
+func (e *recordingExecutor) Identifier() string { return "local-fallback" }
+
+func (e *recordingExecutor) Execute(_ context.Context, _ *Auth, req cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	e.model = req.Model
+	return cliproxyexecutor.Response{Payload: []byte(`{"ok":true}`)}, nil
+}
+
+func (e *recordingExecutor) ExecuteStream(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (<-chan cliproxyexecutor.StreamChunk, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (e *recordingExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) { return auth, nil }
+
+func (e *recordingExecutor) CountTokens(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return cliproxyexecutor.Response{}, errors.New("not implemented")
+}
+
+func (e *recordingExecutor) HttpRequest(context.Context, *Auth, *http.Request) (*http.Response, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestLocalFallback(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	exhausted := newModelCooldownError("m", "claude", 0)
+	if m.ShouldUseLocalFallback(exhausted) {
+		t.Fatalf("fallback must be off until configured")
+	}
+
+	exec := &recordingExecutor{}
+	m.SetLocalFallback(&LocalFallback{Executor: exec, Auth: &Auth{ID: "local-fallback"}, Model: "local-model"})
+	if !m.ShouldUseLocalFallback(exhausted) {
+		t.Fatalf("cooldown errors must trigger the fallback")
+	}
+	if !m.ShouldUseLocalFallback(&Error{Code: "auth_unavailable", Message: "no auth available"}) {
+		t.Fatalf("unavailable credentials must trigger the fallback")
+	}
+	if m.ShouldUseLocalFallback(&Error{Code: "auth_not_found", Message: "no auth available"}) {
+		t.Fatalf("models no credential serves must not trigger the fallback")
+	}
+	if m.ShouldUseLocalFallback(&Error{HTTPStatus: http.StatusBadRequest, Message: "bad request"}) {
+		t.Fatalf("client errors must not trigger the fallback")
+	}
+
+	resp, err := m.ExecuteLocalFallback(context.Background(), cliproxyexecutor.Request{Model: "claude-sonnet"}, cliproxyexecutor.Options{})
+	if err != nil || string(resp.Payload) != `{"ok":true}` || exec.model != "local-model" {
+		t.Fatalf("unexpected fallback result: %s %v model=%s", resp.Payload, err, exec.model)
+	}
+
+	m.SetLocalFallback(nil)
+	if m.ShouldUseLocalFallback(exhausted) {
+		t.Fatalf("fallback must be removable")
+	}
+}
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"slices"
 	"strings"
 
 	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
@@ -65,6 +66,86 @@ func (m *Manager) SetOAuthModelMappings(mappings map[string][]internalconfig.Mod
 	m.modelNameMappings.Store(table)
 }
 
+// SetConfigModelAliases records the model aliases declared on API key entries in cfg, so
+// ModelAliasTargets can report the upstream models an alias routes to.
+func (m *Manager) SetConfigModelAliases(cfg *internalconfig.Config) {
+	if m == nil {
+		return
+	}
+	aliases := make(map[string][]string)
+	add := func(prefix, name, alias string) {
+		name, alias = strings.TrimSpace(name), strings.TrimSpace(alias)
+		if name == "" || alias == "" || strings.EqualFold(name, alias) {
+			return
+		}
+		keys := []string{strings.ToLower(alias)}
+		if prefix = strings.Trim(strings.TrimSpace(prefix), "/"); prefix != "" {
+			keys = append(keys, strings.ToLower(prefix+"/"+alias))
+		}
+		for _, key := range keys {
+			if !slices.Contains(aliases[key], name) {
+				aliases[key] = append(aliases[key], name)
+			}
+		}
+	}
+	if cfg != nil {
+		for _, entry := range cfg.ClaudeKey {
+			for _, model := range entry.Models {
+				add(entry.Prefix, model.Name, model.Alias)
+			}
+		}
+		for _, entry := range cfg.CodexKey {
+			for _, model := range entry.Models {
+				add(entry.Prefix, model.Name, model.Alias)
+			}
+		}
+		for _, entry := range cfg.GeminiKey {
+			for _, model := range entry.Models {
+				add(entry.Prefix, model.Name, model.Alias)
+			}
+		}
+		for _, entry := range cfg.VertexCompatAPIKey {
+			for _, model := range entry.Models {
+				add("", model.Name, model.Alias)
+			}
+		}
+		for _, entry := range cfg.OpenAICompatibility {
+			for _, model := range entry.Models {
+				add(entry.Prefix, model.Name, model.Alias)
+			}
+		}
+	}
+	m.configModelAliases.Store(aliases)
+}
+
+// ModelAliasTargets returns the upstream models that model is an alias for, across the OAuth
+// model mappings and the API key model aliases. It returns nil when model is not an alias.
+func (m *Manager) ModelAliasTargets(model string) []string {
+	if m == nil {
+		return nil
+	}
+	key := strings.ToLower(strings.TrimSpace(model))
+	if key == "" {
+		return nil
+	}
+	var targets []string
+	if table, _ := m.modelNameMappings.Load().(*modelNameMappingTable); table != nil {
+		for _, rev := range table.reverse {
+			if name := rev[key]; name != "" && !slices.Contains(targets, name) {
+				targets = append(targets, name)
+			}
+		}
+	}
+	if aliases, _ := m.configModelAliases.Load().(map[string][]string); aliases != nil {
+		for _, name := range aliases[key] {
+			if !slices.Contains(targets, name) {
+				targets = append(targets, name)
+			}
+		}
+	}
+	return targets
+}
+
 // applyOAuthModelMapping resolves the upstream model from OAuth model mappings
 // and returns the resolved model along with updated metadata. If a mapping exists,
 // the returned model is the upstream model and metadata contains the original
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"slices"
+	"testing"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestModelAliasTargets(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(nil, nil, nil)
+	m.SetOAuthModelMappings(map[string][]internalconfig.ModelNameMapping{
+		"claude": {{Name: "claude-opus-4-1", Alias: "fast"}},
+	})
+	m.SetConfigModelAliases(&internalconfig.Config{
+		ClaudeKey: []internalconfig.ClaudeKey{{
+			Prefix: "teamA",
+			Models: []internalconfig.ClaudeModel{{Name: "claude-sonnet-4-5", Alias: "Fast"}},
+		}},
+	})
+
+	if got := m.ModelAliasTargets("FAST"); !slices.Equal(got, []string{"claude-opus-4-1", "claude-sonnet-4-5"}) {
+		t.Fatalf("ModelAliasTargets(FAST) = %v", got)
+	}
+	if got := m.ModelAliasTargets("teamA/fast"); !slices.Equal(got, []string{"claude-sonnet-4-5"}) {
+		t.Fatalf("ModelAliasTargets(teamA/fast) = %v", got)
+	}
+	if got := m.ModelAliasTargets("claude-opus-4-1"); got != nil {
+		t.Fatalf("upstream model must not be an alias, got %v", got)
+	}
+}
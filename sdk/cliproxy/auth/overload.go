@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// statusOverloaded is the Anthropic status code for a temporarily overloaded upstream.
+const statusOverloaded = 529
+
+const (
+	overloadBackoffBase = 500 * time.Millisecond
+	overloadBackoffMax  = 8 * time.Second
+)
+
+// OverloadCount reports how often a provider/model pair answered as overloaded.
+type OverloadCount struct {
+	Provider string    `json:"provider"`
+	Model    string    `json:"model"`
+	Count    int64     `json:"count"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// overloadStats counts overloaded responses separately from quota errors.
+type overloadStats struct {
+	mu     sync.Mutex
+	counts map[sloKey]*OverloadCount
+}
+
+func newOverloadStats() *overloadStats {
+	return &overloadStats{counts: make(map[sloKey]*OverloadCount)}
+}
+
+func (s *overloadStats) record(provider, model string, now time.Time) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := sloKey{owner: provider, model: model}
+	entry, ok := s.counts[key]
+	if !ok {
+		entry = &OverloadCount{Provider: provider, Model: model}
+		s.counts[key] = entry
+	}
+	entry.Count++
+	entry.LastSeen = now
+}
+
+func (s *overloadStats) snapshot() []OverloadCount {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]OverloadCount, 0, len(s.counts))
+	for _, entry := range s.counts {
+		out = append(out, *entry)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Provider != out[j].Provider {
+			return out[i].Provider < out[j].Provider
+		}
+		return out[i].Model < out[j].Model
+	})
+	return out
+}
+
+// OverloadReport returns overloaded response counts per provider and model.
+func (m *Manager) OverloadReport() []OverloadCount {
+	if m == nil {
+		return nil
+	}
+	return m.overloads.snapshot()
+}
+
+// isOverloaded reports whether an error is an Anthropic-style overloaded_error, which signals
+// transient upstream capacity pressure rather than an exhausted quota.
+func isOverloaded(status int, message string) bool {
+	return status == statusOverloaded || strings.Contains(message, "overloaded_error")
+}
+
+// nextOverloadCooldown returns a short exponential cooldown and the updated backoff level.
+func nextOverloadCooldown(prevLevel int) (time.Duration, int) {
+	if prevLevel < 0 {
+		prevLevel = 0
+	}
+	cooldown := overloadBackoffBase * time.Duration(1<<prevLevel)
+	if cooldown >= overloadBackoffMax {
+		return overloadBackoffMax, prevLevel
+	}
+	return cooldown, prevLevel + 1
+}
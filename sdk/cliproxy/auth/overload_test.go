@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMarkResultOverloadedBacksOffBriefly(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	if _, err := m.Register(context.Background(), &Auth{ID: "a1", Provider: "claude"}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	overloaded := Result{AuthID: "a1", Provider: "claude", Model: "m", Error: &Error{HTTPStatus: statusOverloaded, Message: `{"type":"error","error":{"type":"overloaded_error"}}`}}
+
+	m.MarkResult(context.Background(), overloaded)
+	auth, _ := m.GetByID("a1")
+	state := auth.ModelStates["m"]
+	if state.Quota.Exceeded {
+		t.Fatalf("overload must not enter quota cooldown: %+v", state.Quota)
+	}
+	if wait := time.Until(state.NextRetryAfter); wait <= 0 || wait > overloadBackoffBase {
+		t.Fatalf("unexpected first overload cooldown %v", wait)
+	}
+
+	m.MarkResult(context.Background(), overloaded)
+	auth, _ = m.GetByID("a1")
+	if state = auth.ModelStates["m"]; state.OverloadLevel != 2 {
+		t.Fatalf("expected backoff level 2, got %d", state.OverloadLevel)
+	}
+
+	report := m.OverloadReport()
+	if len(report) != 1 || report[0].Count != 2 || report[0].Provider != "claude" {
+		t.Fatalf("unexpected overload report: %+v", report)
+	}
+
+	m.MarkResult(context.Background(), Result{AuthID: "a1", Provider: "claude", Model: "m", Success: true})
+	auth, _ = m.GetByID("a1")
+	if auth.ModelStates["m"].OverloadLevel != 0 {
+		t.Fatalf("success must reset the overload backoff")
+	}
+}
+
+func TestNextOverloadCooldownCaps(t *testing.T) {
+	t.Parallel()
+
+	level := 0
+	var cooldown time.Duration
+	for i := 0; i < 10; i++ {
+		cooldown, level = nextOverloadCooldown(level)
+	}
+	if cooldown != overloadBackoffMax {
+		t.Fatalf("cooldown = %v, want %v", cooldown, overloadBackoffMax)
+	}
+}
+
+func TestShouldRetryAfterOverloadHonorsDisabledRetryInterval(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	if _, err := m.Register(context.Background(), &Auth{ID: "a1", Provider: "claude"}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	overloaded := &Error{HTTPStatus: statusOverloaded, Message: `{"type":"error","error":{"type":"overloaded_error"}}`}
+	m.MarkResult(context.Background(), Result{AuthID: "a1", Provider: "claude", Model: "m", Error: overloaded})
+
+	if wait, retry := m.shouldRetryAfterError(overloaded, 0, 3, []string{"claude"}, "m", 0); retry {
+		t.Fatalf("max-retry-interval 0 must disable waiting, got retry after %v", wait)
+	}
+	if _, retry := m.shouldRetryAfterError(overloaded, 0, 3, []string{"claude"}, "m", time.Millisecond); !retry {
+		t.Fatal("a tight max-retry-interval must still wait out the overload backoff")
+	}
+}
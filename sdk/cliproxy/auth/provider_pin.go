@@ -0,0 +1,32 @@
+package auth
+
+import "github.com/router-for-me/CLIProxyAPI/v6/internal/features"
+
+// providerPin keeps a request on the provider of its first pick, across retries, while
+// cross-provider failover is disabled. A nil pin leaves the provider list untouched.
+type providerPin struct {
+	provider string
+}
+
+// newProviderPin returns a pin for a new request, or nil when cross-provider failover is enabled.
+func newProviderPin() *providerPin {
+	if features.Enabled(features.CrossProviderFailover) {
+		return nil
+	}
+	return &providerPin{}
+}
+
+// restrict narrows providers to the pinned provider once one has been picked.
+func (p *providerPin) restrict(providers []string) []string {
+	if p == nil || p.provider == "" {
+		return providers
+	}
+	return []string{p.provider}
+}
+
+// record pins the request to provider unless it is already pinned.
+func (p *providerPin) record(provider string) {
+	if p != nil && p.provider == "" {
+		p.provider = provider
+	}
+}
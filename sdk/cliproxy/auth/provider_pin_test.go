@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/features"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+type failingProviderExecutor struct {
+	providerExecutor
+	calls int
+}
+
+func (e *failingProviderExecutor) Execute(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	e.calls++
+	return cliproxyexecutor.Response{}, &Error{HTTPStatus: http.StatusInternalServerError, Message: "upstream failed"}
+}
+
+func TestCrossProviderFailoverFlag(t *testing.T) {
+	run := func() int {
+		m := NewManager(nil, nil, nil)
+		a := &failingProviderExecutor{providerExecutor: providerExecutor{provider: "pin-a"}}
+		b := &failingProviderExecutor{providerExecutor: providerExecutor{provider: "pin-b"}}
+		m.RegisterExecutor(a)
+		m.RegisterExecutor(b)
+		registerIndexedAuth(t, m, "pin-a-1", "pin-a", "pin-model")
+		registerIndexedAuth(t, m, "pin-b-1", "pin-b", "pin-model")
+		if _, err := m.Execute(context.Background(), []string{"pin-a", "pin-b"}, cliproxyexecutor.Request{Model: "pin-model"}, cliproxyexecutor.Options{}); err == nil {
+			t.Fatal("expected the upstream error")
+		}
+		return a.calls + b.calls
+	}
+
+	if calls := run(); calls != 2 {
+		t.Fatalf("with failover the request must reach both providers, got %d calls", calls)
+	}
+
+	features.Default().SetOverride(features.CrossProviderFailover, false)
+	t.Cleanup(func() { features.Default().ClearOverride(features.CrossProviderFailover) })
+	if calls := run(); calls != 1 {
+		t.Fatalf("without failover the request must stay on one provider, got %d calls", calls)
+	}
+}
@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrorCodeReauthRequired is the LastError code of a credential quarantined until the user logs in
+// again.
+const ErrorCodeReauthRequired = "reauth_required"
+
+const (
+	// reauthRecheckBase is the wait before the first refresh re-check of a quarantined credential.
+	reauthRecheckBase = 15 * time.Minute
+	// reauthRecheckMax caps the doubling re-check interval.
+	reauthRecheckMax = 24 * time.Hour
+)
+
+// NeedsReauth reports whether the credential is quarantined because its refresh can no longer
+// succeed without a new login.
+func (a *Auth) NeedsReauth() bool {
+	return a != nil && a.LastError != nil && a.LastError.Code == ErrorCodeReauthRequired
+}
+
+// reauthRecheckDelay returns the wait before the next refresh re-check of a credential whose
+// re-checks already failed checks times. It doubles from reauthRecheckBase up to reauthRecheckMax.
+func reauthRecheckDelay(checks int) time.Duration {
+	delay := reauthRecheckBase
+	for i := 0; i < checks && delay < reauthRecheckMax; i++ {
+		delay *= 2
+	}
+	return min(delay, reauthRecheckMax)
+}
+
+// refreshNeedsReauth reports whether a refresh error means the grant is gone, either flagged by
+// the executor or reported by the OAuth server as invalid_grant.
+func refreshNeedsReauth(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrReauthRequired) {
+		return true
+	}
+	return strings.Contains(strings.ToLower(err.Error()), "invalid_grant")
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
+)
+
+// quotaHeadroomThreshold stores the float64 bits of the remaining-quota percentage below which
+// auths are deprioritized; zero disables quota-aware routing.
+var quotaHeadroomThreshold atomic.Uint64
+
+// SetQuotaHeadroomThreshold sets the remaining quota percentage below which an auth is only
+// selected when every other available auth is below it too. Zero or less disables the check.
+func SetQuotaHeadroomThreshold(percent float64) {
+	if percent < 0 || math.IsNaN(percent) {
+		percent = 0
+	}
+	quotaHeadroomThreshold.Store(math.Float64bits(percent))
+}
+
+// preferQuotaHeadroom drops auths whose last usage snapshot reports less remaining quota than
+// the threshold, across all priority tiers, unless that would leave nothing to select.
+func preferQuotaHeadroom(availableByPriority map[int][]*Auth, now time.Time) map[int][]*Auth {
+	threshold := math.Float64frombits(quotaHeadroomThreshold.Load())
+	if threshold <= 0 {
+		return availableByPriority
+	}
+	var filtered map[int][]*Auth
+	dropped := false
+	for priority, auths := range availableByPriority {
+		for _, candidate := range auths {
+			if remaining, ok := quotaRemainingPercent(candidate, now); ok && remaining < threshold {
+				dropped = true
+				continue
+			}
+			if filtered == nil {
+				filtered = make(map[int][]*Auth)
+			}
+			filtered[priority] = append(filtered[priority], candidate)
+		}
+	}
+	if !dropped || len(filtered) == 0 {
+		return availableByPriority
+	}
+	return filtered
+}
+
+// quotaRemainingPercent returns the smallest remaining percentage across the quota windows of
+// the auth's latest Codex or Kiro usage snapshot. Windows whose reset time has passed count as
+// replenished; ok is false when no snapshot carries a usable window.
+func quotaRemainingPercent(auth *Auth, now time.Time) (remaining float64, ok bool) {
+	if auth == nil {
+		return 0, false
+	}
+	remaining = 100
+	observe := func(percent float64) {
+		if percent < remaining {
+			remaining = percent
+		}
+		ok = true
+	}
+	if snapshot := usage.GetCodexQuotaSnapshot(auth.ID); snapshot != nil {
+		windows := []struct {
+			used       *float64
+			resetAt    *int64
+			resetAfter *int
+		}{
+			{snapshot.PrimaryUsedPercent, snapshot.PrimaryResetAtSeconds, snapshot.PrimaryResetAfterSeconds},
+			{snapshot.SecondaryUsedPercent, snapshot.SecondaryResetAtSeconds, snapshot.SecondaryResetAfterSeconds},
+		}
+		for _, window := range windows {
+			if window.used == nil {
+				continue
+			}
+			if window.resetAt != nil && now.Unix() >= *window.resetAt {
+				continue
+			}
+			if window.resetAt == nil && window.resetAfter != nil && !now.Before(snapshot.UpdatedAt.Add(time.Duration(*window.resetAfter)*time.Second)) {
+				continue
+			}
+			observe(100 - *window.used)
+		}
+	}
+	if snapshot := usage.GetKiroUsageSnapshot(auth.ID); snapshot != nil {
+		if snapshot.NextDateReset == nil || float64(now.Unix()) < *snapshot.NextDateReset {
+			for _, breakdown := range snapshot.Breakdowns {
+				if breakdown.UsageLimit == nil || breakdown.CurrentUsage == nil || *breakdown.UsageLimit <= 0 {
+					continue
+				}
+				limit := float64(*breakdown.UsageLimit)
+				observe((limit - float64(*breakdown.CurrentUsage)) / limit * 100)
+			}
+		}
+	}
+	return remaining, ok
+}
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestQuotaRemainingPercent(t *testing.T) {
+	now := time.Now()
+	used := func(v float64) *float64 { return &v }
+	at := func(v time.Time) *int64 { unix := v.Unix(); return &unix }
+	limit, current := 100, 97
+
+	t.Cleanup(func() {
+		usage.DeleteCodexQuotaSnapshot("codex")
+		usage.DeleteKiroUsageSnapshot("kiro")
+	})
+	usage.UpdateCodexQuotaSnapshot("codex", &usage.CodexQuotaSnapshot{
+		PrimaryUsedPercent:      used(40),
+		SecondaryUsedPercent:    used(99),
+		SecondaryResetAtSeconds: at(now.Add(-time.Minute)),
+		UpdatedAt:               now,
+	})
+	usage.UpdateKiroUsageSnapshot("kiro", &usage.KiroUsageSnapshot{
+		Breakdowns: []usage.KiroUsageBreakdown{{UsageLimit: &limit, CurrentUsage: &current}},
+	})
+
+	// The secondary window already reset, so only the primary window counts.
+	if remaining, ok := quotaRemainingPercent(&Auth{ID: "codex"}, now); !ok || remaining != 60 {
+		t.Fatalf("codex remaining = %v, %v; want 60, true", remaining, ok)
+	}
+	if remaining, ok := quotaRemainingPercent(&Auth{ID: "kiro"}, now); !ok || remaining != 3 {
+		t.Fatalf("kiro remaining = %v, %v; want 3, true", remaining, ok)
+	}
+	if _, ok := quotaRemainingPercent(&Auth{ID: "unknown"}, now); ok {
+		t.Fatal("auth without snapshot must report no quota")
+	}
+}
+
+func TestGetAvailableAuths_PrefersQuotaHeadroom(t *testing.T) {
+	limit, low, high := 100, 95, 10
+	t.Cleanup(func() {
+		SetQuotaHeadroomThreshold(0)
+		usage.DeleteKiroUsageSnapshot("exhausted")
+		usage.DeleteKiroUsageSnapshot("fresh")
+	})
+	usage.UpdateKiroUsageSnapshot("exhausted", &usage.KiroUsageSnapshot{
+		Breakdowns: []usage.KiroUsageBreakdown{{UsageLimit: &limit, CurrentUsage: &low}},
+	})
+	usage.UpdateKiroUsageSnapshot("fresh", &usage.KiroUsageSnapshot{
+		Breakdowns: []usage.KiroUsageBreakdown{{UsageLimit: &limit, CurrentUsage: &high}},
+	})
+	exhausted := &Auth{ID: "exhausted", Attributes: map[string]string{"priority": "1"}}
+	fresh := &Auth{ID: "fresh"}
+	selector := &FillFirstSelector{}
+	pick := func(auths ...*Auth) string {
+		t.Helper()
+		got, err := selector.Pick(context.Background(), "kiro", "", cliproxyexecutor.Options{}, auths)
+		if err != nil {
+			t.Fatalf("Pick() error = %v", err)
+		}
+		return got.ID
+	}
+
+	if got := pick(exhausted, fresh); got != "exhausted" {
+		t.Fatalf("without threshold Pick() = %q, want the higher priority auth", got)
+	}
+	SetQuotaHeadroomThreshold(10)
+	if got := pick(exhausted, fresh); got != "fresh" {
+		t.Fatalf("with threshold Pick() = %q, want the auth with quota left", got)
+	}
+	if got := pick(exhausted); got != "exhausted" {
+		t.Fatalf("Pick() = %q, want the low auth when nothing else is left", got)
+	}
+}
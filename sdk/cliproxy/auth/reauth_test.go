@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type revokedRefreshExecutor struct {
+	recordingExecutor
+	calls int
+}
+
+func (e *revokedRefreshExecutor) Identifier() string { return "kiro" }
+
+func (e *revokedRefreshExecutor) Refresh(context.Context, *Auth) (*Auth, error) {
+	e.calls++
+	return nil, fmt.Errorf("kiro executor: %w: %w", ErrReauthRequired, errors.New("refresh token revoked"))
+}
+
+func TestRefreshAuthMarksReauthRequired(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	exec := &revokedRefreshExecutor{}
+	m.RegisterExecutor(exec)
+	if _, err := m.Register(context.Background(), &Auth{ID: "kiro-1", Provider: "kiro", Status: StatusActive}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	m.refreshAuth(context.Background(), "kiro-1")
+
+	auth, ok := m.GetByID("kiro-1")
+	if !ok {
+		t.Fatal("auth missing after refresh")
+	}
+	if auth.Status != StatusDisabled || auth.LastError == nil || auth.LastError.Code != "reauth_required" {
+		t.Fatalf("auth not marked for re-login: status=%s err=%+v", auth.Status, auth.LastError)
+	}
+	if blocked, _, _ := isAuthBlockedForModel(auth, "claude-sonnet", time.Now()); !blocked {
+		t.Fatal("auth needing re-login must not be routed")
+	}
+	if m.shouldRefresh(auth, time.Now()) {
+		t.Fatal("refresh must not be retried immediately")
+	}
+	if exec.calls != 1 {
+		t.Fatalf("refresh calls = %d, want 1", exec.calls)
+	}
+}
+
+type restoredRefreshExecutor struct {
+	recordingExecutor
+	revoked bool
+}
+
+func (e *restoredRefreshExecutor) Identifier() string { return "kiro" }
+
+func (e *restoredRefreshExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) {
+	if e.revoked {
+		return nil, errors.New("token endpoint returned 400: {\"error\":\"invalid_grant\"}")
+	}
+	return auth.Clone(), nil
+}
+
+func TestReauthQuarantineEscalatesAndRecovers(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	exec := &restoredRefreshExecutor{revoked: true}
+	m.RegisterExecutor(exec)
+	if _, err := m.Register(context.Background(), &Auth{ID: "kiro-1", Provider: "kiro", Status: StatusActive}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	var waits []time.Duration
+	for i := 0; i < 3; i++ {
+		before := time.Now()
+		m.refreshAuth(context.Background(), "kiro-1")
+		auth, _ := m.GetByID("kiro-1")
+		if !auth.NeedsReauth() {
+			t.Fatalf("invalid_grant must quarantine the auth: %+v", auth.LastError)
+		}
+		waits = append(waits, auth.NextRefreshAfter.Sub(before).Round(time.Minute))
+	}
+	want := []time.Duration{15 * time.Minute, 30 * time.Minute, time.Hour}
+	for i := range want {
+		if waits[i] != want[i] {
+			t.Fatalf("re-check waits = %v, want %v", waits, want)
+		}
+	}
+	if got := reauthRecheckDelay(20); got != reauthRecheckMax {
+		t.Fatalf("re-check wait must be capped, got %v", got)
+	}
+
+	exec.revoked = false
+	m.refreshAuth(context.Background(), "kiro-1")
+	auth, _ := m.GetByID("kiro-1")
+	if auth.NeedsReauth() || auth.Status != StatusActive || auth.ReauthChecks != 0 {
+		t.Fatalf("successful re-check must lift the quarantine: status=%s checks=%d err=%+v", auth.Status, auth.ReauthChecks, auth.LastError)
+	}
+}
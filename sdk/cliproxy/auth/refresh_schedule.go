@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"hash/fnv"
+	"strings"
+	"sync"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+// refreshSchedule refreshes Kiro OAuth credentials a configurable lead time before expiry. Each
+// credential gets a stable jitter within the configured window, and failed refreshes back off
+// exponentially per credential.
+type refreshSchedule struct {
+	mu         sync.Mutex
+	lead       time.Duration
+	jitter     time.Duration
+	maxBackoff time.Duration
+	failures   map[string]int
+}
+
+func newRefreshSchedule() *refreshSchedule {
+	s := &refreshSchedule{failures: map[string]int{}}
+	s.configure(internalconfig.KiroRefreshConfig{})
+	return s
+}
+
+func (s *refreshSchedule) configure(cfg internalconfig.KiroRefreshConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lead = cfg.Lead()
+	s.jitter = cfg.Jitter()
+	s.maxBackoff = cfg.MaxBackoff()
+}
+
+// applies reports whether the schedule covers auth: Kiro credentials refreshed over OAuth.
+func (s *refreshSchedule) applies(a *Auth) bool {
+	if a == nil || !strings.EqualFold(a.Provider, "kiro") || a.Metadata == nil {
+		return false
+	}
+	refreshToken, _ := a.Metadata["refresh_token"].(string)
+	return strings.TrimSpace(refreshToken) != ""
+}
+
+// dueAt returns when auth should be refreshed, or false when the schedule does not cover it.
+func (s *refreshSchedule) dueAt(a *Auth) (time.Time, bool) {
+	if !s.applies(a) {
+		return time.Time{}, false
+	}
+	expiry, ok := a.ExpirationTime()
+	if !ok || expiry.IsZero() {
+		return time.Time{}, false
+	}
+	s.mu.Lock()
+	lead, jitter := s.lead, s.jitter
+	s.mu.Unlock()
+	return expiry.Add(-lead - refreshJitter(a.ID, expiry, jitter)), true
+}
+
+// succeeded clears the failure count of id.
+func (s *refreshSchedule) succeeded(id string) {
+	s.mu.Lock()
+	delete(s.failures, id)
+	s.mu.Unlock()
+}
+
+// failed records a failed refresh of id and returns how long to wait before the next attempt.
+func (s *refreshSchedule) failed(id string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[id]++
+	backoff := refreshFailureBackoff
+	for i := 1; i < s.failures[id] && backoff < s.maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, s.maxBackoff)
+}
+
+// refreshJitter spreads refreshes over window. It depends only on the credential and its expiry,
+// so every check agrees on the due time while each new token gets a fresh offset.
+func refreshJitter(id string, expiry time.Time, window time.Duration) time.Duration {
+	if window <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(id))
+	_, _ = h.Write([]byte(expiry.UTC().Format(time.RFC3339)))
+	return time.Duration(h.Sum64() % uint64(window))
+}
+
+// SetKiroRefresh applies the lead, jitter and backoff of the proactive Kiro token refresh.
+func (m *Manager) SetKiroRefresh(cfg internalconfig.KiroRefreshConfig) {
+	if m == nil || m.refreshSchedule == nil {
+		return
+	}
+	m.refreshSchedule.configure(cfg)
+}
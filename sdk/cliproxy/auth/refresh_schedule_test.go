@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+type failingRefreshExecutor struct {
+	recordingExecutor
+}
+
+func (e *failingRefreshExecutor) Identifier() string { return "kiro" }
+
+func (e *failingRefreshExecutor) Refresh(context.Context, *Auth) (*Auth, error) {
+	return nil, errors.New("temporary failure")
+}
+
+func kiroScheduleAuth(id string, expiry time.Time) *Auth {
+	return &Auth{ID: id, Provider: "kiro", Metadata: map[string]any{
+		"refresh_token": "rt",
+		"expires_at":    expiry.Format(time.RFC3339),
+	}}
+}
+
+func TestRefreshScheduleLeadAndJitter(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.SetKiroRefresh(internalconfig.KiroRefreshConfig{LeadSeconds: 600, JitterSeconds: 120})
+
+	now := time.Now().Truncate(time.Second)
+	expiry := now.Add(time.Hour)
+	auth := kiroScheduleAuth("kiro-a", expiry)
+	due, ok := m.refreshSchedule.dueAt(auth)
+	if !ok {
+		t.Fatal("kiro auth with refresh token must be scheduled")
+	}
+	if due.After(expiry.Add(-10*time.Minute)) || due.Before(expiry.Add(-12*time.Minute)) {
+		t.Fatalf("due %s outside [expiry-12m, expiry-10m]", due)
+	}
+	if again, _ := m.refreshSchedule.dueAt(auth); !again.Equal(due) {
+		t.Fatal("due time must be stable between checks")
+	}
+	if m.shouldRefresh(auth, due.Add(-time.Second)) || !m.shouldRefresh(auth, due) {
+		t.Fatal("refresh must start exactly at the due time")
+	}
+
+	if _, ok = m.refreshSchedule.dueAt(&Auth{ID: "kiro-b", Provider: "kiro", Metadata: map[string]any{"expires_at": expiry.Format(time.RFC3339)}}); ok {
+		t.Fatal("kiro auth without refresh token must not be scheduled")
+	}
+}
+
+func TestRefreshScheduleBacksOffPerAuth(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.SetKiroRefresh(internalconfig.KiroRefreshConfig{MaxBackoffSeconds: 180})
+	m.RegisterExecutor(&failingRefreshExecutor{})
+	if _, err := m.Register(context.Background(), kiroScheduleAuth("kiro-1", time.Now().Add(time.Minute))); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	var waits []time.Duration
+	for i := 0; i < 4; i++ {
+		before := time.Now()
+		m.refreshAuth(context.Background(), "kiro-1")
+		auth, _ := m.GetByID("kiro-1")
+		waits = append(waits, auth.NextRefreshAfter.Sub(before).Round(time.Minute))
+	}
+	want := []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute}
+	for i := range want {
+		if waits[i] != want[i] {
+			t.Fatalf("backoff = %v, want %v", waits, want)
+		}
+	}
+
+	m.refreshSchedule.succeeded("kiro-1")
+	if got := m.refreshSchedule.failed("kiro-1"); got != time.Minute {
+		t.Fatalf("backoff after success = %v, want 1m", got)
+	}
+}
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type rateLimitErr struct{}
+
+func (rateLimitErr) Error() string   { return "rate limited" }
+func (rateLimitErr) StatusCode() int { return http.StatusTooManyRequests }
+
+func TestWithPoolRetryAfterUsesSoonestRecovery(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	ctx := context.Background()
+	for _, id := range []string{"a1", "a2"} {
+		if _, err := m.Register(ctx, &Auth{ID: id, Provider: "claude"}); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	long, short := 90*time.Second, 20*time.Second
+	m.MarkResult(ctx, Result{AuthID: "a1", Provider: "claude", Model: "m", Error: &Error{HTTPStatus: http.StatusTooManyRequests}, RetryAfter: &long})
+	m.MarkResult(ctx, Result{AuthID: "a2", Provider: "claude", Model: "m", Error: &Error{HTTPStatus: http.StatusTooManyRequests}, RetryAfter: &short})
+
+	err := m.withPoolRetryAfter(rateLimitErr{}, []string{"claude"}, "m")
+	var he interface{ Headers() http.Header }
+	if !errors.As(err, &he) {
+		t.Fatalf("expected headers on %T", err)
+	}
+	got, _ := strconv.Atoi(he.Headers().Get("Retry-After"))
+	if got < 19 || got > 20 {
+		t.Fatalf("Retry-After = %d, want about 20", got)
+	}
+	if !errors.Is(err, rateLimitErr{}) || statusCodeFromError(err) != http.StatusTooManyRequests {
+		t.Fatalf("wrapped error must keep the original error and status")
+	}
+
+	if err = m.withPoolRetryAfter(errors.New("boom"), []string{"claude"}, "m"); err.Error() != "boom" {
+		t.Fatalf("non rate limit errors must pass through, got %v", err)
+	}
+}
@@ -0,0 +1,298 @@
+package auth
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultRiskWindow        = time.Hour
+	defaultRiskPaceThreshold = 0.4
+	defaultRiskMaxDelay      = 10 * time.Second
+	defaultRiskMaxQueued     = 8
+
+	// Signal counts at which a component of the score saturates.
+	riskBanSaturation   = 3
+	riskQuotaSaturation = 5
+	// riskBurstGap is the spacing below which consecutive requests count as a burst.
+	riskBurstGap = 2 * time.Second
+	// riskMinBurstSamples is the number of requests needed before burstiness is scored.
+	riskMinBurstSamples = 5
+
+	riskBanWeight   = 0.5
+	riskQuotaWeight = 0.3
+	riskBurstWeight = 0.2
+)
+
+// AuthRisk reports the risk score of one credential and the pacing applied to it.
+type AuthRisk struct {
+	AuthID      string  `json:"auth_id"`
+	Provider    string  `json:"provider,omitempty"`
+	Label       string  `json:"label,omitempty"`
+	Score       float64 `json:"score"`
+	Requests    int     `json:"requests"`
+	BanSignals  int     `json:"ban_signals"`
+	QuotaErrors int     `json:"quota_errors"`
+	Burstiness  float64 `json:"burstiness"`
+	PaceDelayMS int64   `json:"pace_delay_ms"`
+}
+
+// AccountRiskReport is a point-in-time view of per-credential risk scores.
+type AccountRiskReport struct {
+	Enabled       bool       `json:"enabled"`
+	WindowSeconds int64      `json:"window_seconds"`
+	PaceThreshold float64    `json:"pace_threshold"`
+	MaxDelayMS    int64      `json:"max_delay_ms"`
+	Auths         []AuthRisk `json:"auths"`
+}
+
+type riskSignals struct {
+	provider string
+	starts   []time.Time
+	bans     []time.Time
+	quota    []time.Time
+	// queued counts requests currently waiting out their pacing delay.
+	queued int
+}
+
+// riskTracker scores credentials from recent results and request spacing and paces risky ones.
+type riskTracker struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	enabled   bool
+	window    time.Duration
+	threshold float64
+	maxDelay  time.Duration
+	maxQueued int
+	auths     map[string]*riskSignals
+}
+
+func newRiskTracker() *riskTracker {
+	return &riskTracker{
+		now:       time.Now,
+		window:    defaultRiskWindow,
+		threshold: defaultRiskPaceThreshold,
+		maxDelay:  defaultRiskMaxDelay,
+		maxQueued: defaultRiskMaxQueued,
+		auths:     make(map[string]*riskSignals),
+	}
+}
+
+func (t *riskTracker) configure(cfg internalconfig.AccountRiskConfig) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.enabled = cfg.Enabled
+	t.window = defaultRiskWindow
+	if cfg.WindowSeconds > 0 {
+		t.window = time.Duration(cfg.WindowSeconds) * time.Second
+	}
+	t.threshold = defaultRiskPaceThreshold
+	if cfg.PaceThreshold > 0 && cfg.PaceThreshold < 1 {
+		t.threshold = cfg.PaceThreshold
+	}
+	t.maxDelay = defaultRiskMaxDelay
+	if cfg.MaxDelayMS > 0 {
+		t.maxDelay = time.Duration(cfg.MaxDelayMS) * time.Millisecond
+	}
+	t.maxQueued = defaultRiskMaxQueued
+	if cfg.MaxQueued > 0 {
+		t.maxQueued = cfg.MaxQueued
+	}
+	if !t.enabled {
+		t.auths = make(map[string]*riskSignals)
+	}
+}
+
+// recordResult counts ban and quota signals from an execution result.
+func (t *riskTracker) recordResult(result Result) {
+	if result.Success || result.AuthID == "" {
+		return
+	}
+	status := statusCodeFromResult(result.Error)
+	if status != http.StatusUnauthorized && status != http.StatusForbidden && status != http.StatusTooManyRequests {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.enabled {
+		return
+	}
+	now := t.now()
+	signals := t.signalsLocked(result.AuthID, now)
+	if result.Provider != "" {
+		signals.provider = result.Provider
+	}
+	if status == http.StatusTooManyRequests {
+		signals.quota = append(signals.quota, now)
+	} else {
+		signals.bans = append(signals.bans, now)
+	}
+}
+
+// pace records a request start for authID and, when the credential scores above the threshold,
+// waits until the gap since its previous request reaches the delay for its score. A request that
+// would have to wait while the credential already has the maximum number of requests queued fails
+// with 429 instead.
+func (t *riskTracker) pace(ctx context.Context, authID string) error {
+	t.mu.Lock()
+	if !t.enabled || authID == "" {
+		t.mu.Unlock()
+		return nil
+	}
+	now := t.now()
+	signals := t.signalsLocked(authID, now)
+	delay := t.delayLocked(t.scoreLocked(signals))
+	start := now
+	if n := len(signals.starts); n > 0 {
+		// Starts are reserved in order, so concurrent requests queue behind each other.
+		if earliest := signals.starts[n-1].Add(delay); earliest.After(start) {
+			start = earliest
+		}
+	}
+	wait := start.Sub(now)
+	if wait > 0 {
+		if signals.queued >= t.maxQueued {
+			t.mu.Unlock()
+			return &Error{Code: "pacing_queue_full", Message: "too many requests are waiting for this credential", HTTPStatus: http.StatusTooManyRequests}
+		}
+		signals.queued++
+	}
+	signals.starts = append(signals.starts, start)
+	t.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	log.Debugf("pacing auth %s for %s", authID, wait)
+	err := waitForCooldown(ctx, wait)
+	t.mu.Lock()
+	signals.queued--
+	t.mu.Unlock()
+	return err
+}
+
+func (t *riskTracker) report() AccountRiskReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	report := AccountRiskReport{
+		Enabled:       t.enabled,
+		WindowSeconds: int64(t.window / time.Second),
+		PaceThreshold: t.threshold,
+		MaxDelayMS:    t.maxDelay.Milliseconds(),
+		Auths:         make([]AuthRisk, 0, len(t.auths)),
+	}
+	now := t.now()
+	for authID, signals := range t.auths {
+		signals.prune(now.Add(-t.window))
+		if len(signals.starts) == 0 && len(signals.bans) == 0 && len(signals.quota) == 0 {
+			delete(t.auths, authID)
+			continue
+		}
+		score := t.scoreLocked(signals)
+		report.Auths = append(report.Auths, AuthRisk{
+			AuthID:      authID,
+			Provider:    signals.provider,
+			Score:       math.Round(score*1000) / 1000,
+			Requests:    len(signals.starts),
+			BanSignals:  len(signals.bans),
+			QuotaErrors: len(signals.quota),
+			Burstiness:  math.Round(signals.burstiness()*1000) / 1000,
+			PaceDelayMS: t.delayLocked(score).Milliseconds(),
+		})
+	}
+	sort.Slice(report.Auths, func(i, j int) bool {
+		if report.Auths[i].Score != report.Auths[j].Score {
+			return report.Auths[i].Score > report.Auths[j].Score
+		}
+		return report.Auths[i].AuthID < report.Auths[j].AuthID
+	})
+	return report
+}
+
+func (t *riskTracker) signalsLocked(authID string, now time.Time) *riskSignals {
+	signals, ok := t.auths[authID]
+	if !ok {
+		signals = &riskSignals{}
+		t.auths[authID] = signals
+	}
+	signals.prune(now.Add(-t.window))
+	return signals
+}
+
+// scoreLocked combines the ban, quota and burst components into a score between 0 and 1.
+func (t *riskTracker) scoreLocked(signals *riskSignals) float64 {
+	ban := math.Min(1, float64(len(signals.bans))/riskBanSaturation)
+	quota := math.Min(1, float64(len(signals.quota))/riskQuotaSaturation)
+	return math.Min(1, riskBanWeight*ban+riskQuotaWeight*quota+riskBurstWeight*signals.burstiness())
+}
+
+func (t *riskTracker) delayLocked(score float64) time.Duration {
+	if score <= t.threshold {
+		return 0
+	}
+	return time.Duration(float64(t.maxDelay) * (score - t.threshold) / (1 - t.threshold))
+}
+
+// burstiness is the fraction of requests started within riskBurstGap of the previous one.
+func (s *riskSignals) burstiness() float64 {
+	if len(s.starts) < riskMinBurstSamples {
+		return 0
+	}
+	bursts := 0
+	for i := 1; i < len(s.starts); i++ {
+		if s.starts[i].Sub(s.starts[i-1]) < riskBurstGap {
+			bursts++
+		}
+	}
+	return float64(bursts) / float64(len(s.starts)-1)
+}
+
+func (s *riskSignals) prune(cutoff time.Time) {
+	s.starts = pruneBefore(s.starts, cutoff)
+	s.bans = pruneBefore(s.bans, cutoff)
+	s.quota = pruneBefore(s.quota, cutoff)
+}
+
+func pruneBefore(times []time.Time, cutoff time.Time) []time.Time {
+	idx := sort.Search(len(times), func(i int) bool { return !times[i].Before(cutoff) })
+	if idx == 0 {
+		return times
+	}
+	return append(times[:0], times[idx:]...)
+}
+
+// SetAccountRisk applies the account risk scoring and pacing settings. Disabling it drops the
+// collected signals.
+func (m *Manager) SetAccountRisk(cfg internalconfig.AccountRiskConfig) {
+	if m == nil || m.risk == nil {
+		return
+	}
+	m.risk.configure(cfg)
+}
+
+// AccountRiskReport returns the risk score and pacing delay of every recently used credential,
+// highest score first.
+func (m *Manager) AccountRiskReport() AccountRiskReport {
+	if m == nil || m.risk == nil {
+		return AccountRiskReport{}
+	}
+	report := m.risk.report()
+	m.mu.RLock()
+	for i := range report.Auths {
+		if auth := m.auths[report.Auths[i].AuthID]; auth != nil {
+			report.Auths[i].Label = auth.Label
+			if report.Auths[i].Provider == "" {
+				report.Auths[i].Provider = auth.Provider
+			}
+		}
+	}
+	m.mu.RUnlock()
+	return report
+}
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestRiskScoreAndPacing(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+	risk := newRiskTracker()
+	risk.now = func() time.Time { return now }
+	risk.configure(internalconfig.AccountRiskConfig{Enabled: true, PaceThreshold: 0.4, MaxDelayMS: 1000})
+
+	forbidden := Result{AuthID: "risky", Provider: "claude", Error: &Error{HTTPStatus: http.StatusForbidden}}
+	for i := 0; i < riskBanSaturation; i++ {
+		risk.recordResult(forbidden)
+	}
+	for i := 0; i < riskQuotaSaturation; i++ {
+		risk.recordResult(Result{AuthID: "risky", Error: &Error{HTTPStatus: http.StatusTooManyRequests}})
+	}
+	risk.recordResult(Result{AuthID: "calm", Error: &Error{HTTPStatus: http.StatusInternalServerError}})
+
+	report := risk.report()
+	if len(report.Auths) != 1 || report.Auths[0].AuthID != "risky" {
+		t.Fatalf("only ban and quota signals should be tracked, got %+v", report.Auths)
+	}
+	got := report.Auths[0]
+	if got.Score != 0.8 || got.BanSignals != 3 || got.QuotaErrors != 5 || got.Provider != "claude" {
+		t.Fatalf("unexpected risk entry: %+v", got)
+	}
+	if got.PaceDelayMS != 666 {
+		t.Fatalf("PaceDelayMS = %d, want 666", got.PaceDelayMS)
+	}
+
+	ctx := context.Background()
+	if err := risk.pace(ctx, "risky"); err != nil {
+		t.Fatalf("first request must not wait: %v", err)
+	}
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := risk.pace(canceled, "risky"); err == nil {
+		t.Fatal("second back-to-back request must be paced")
+	}
+	if err := risk.pace(canceled, "calm"); err != nil {
+		t.Fatalf("low-risk credentials must not be paced: %v", err)
+	}
+
+	now = now.Add(defaultRiskWindow + time.Second)
+	if report = risk.report(); len(report.Auths) != 0 {
+		t.Fatalf("signals must expire with the window, got %+v", report.Auths)
+	}
+}
+
+func TestRiskPacingQueueIsBounded(t *testing.T) {
+	t.Parallel()
+
+	risk := newRiskTracker()
+	risk.configure(internalconfig.AccountRiskConfig{Enabled: true, MaxDelayMS: 60_000, MaxQueued: 1})
+	for i := 0; i < riskBanSaturation; i++ {
+		risk.recordResult(Result{AuthID: "risky", Error: &Error{HTTPStatus: http.StatusForbidden}})
+	}
+	if err := risk.pace(context.Background(), "risky"); err != nil {
+		t.Fatalf("first request must not wait: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	waiting := make(chan error, 1)
+	go func() { waiting <- risk.pace(ctx, "risky") }()
+	deadline := time.Now().Add(time.Second)
+	for {
+		risk.mu.Lock()
+		queued := risk.auths["risky"].queued
+		risk.mu.Unlock()
+		if queued == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("second request was not queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	err := risk.pace(context.Background(), "risky")
+	if status := statusCodeFromError(err); status != http.StatusTooManyRequests {
+		t.Fatalf("request beyond the queue must fail with 429, got %v", err)
+	}
+	cancel()
+	if err = <-waiting; err == nil {
+		t.Fatal("canceled request must stop waiting")
+	}
+	risk.mu.Lock()
+	defer risk.mu.Unlock()
+	if queued := risk.auths["risky"].queued; queued != 0 {
+		t.Fatalf("queue must drain, got %d", queued)
+	}
+}
+
+func TestRiskBurstiness(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+	risk := newRiskTracker()
+	risk.now = func() time.Time { return now }
+	risk.configure(internalconfig.AccountRiskConfig{Enabled: true})
+
+	for i := 0; i < 6; i++ {
+		if err := risk.pace(context.Background(), "a"); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(500 * time.Millisecond)
+	}
+	report := risk.report()
+	if len(report.Auths) != 1 || report.Auths[0].Burstiness != 1 || report.Auths[0].Score != riskBurstWeight {
+		t.Fatalf("back-to-back requests must score as bursts, got %+v", report.Auths)
+	}
+}
+
+func TestRiskDisabledIsNoop(t *testing.T) {
+	t.Parallel()
+
+	risk := newRiskTracker()
+	risk.recordResult(Result{AuthID: "a", Error: &Error{HTTPStatus: http.StatusForbidden}})
+	if err := risk.pace(context.Background(), "a"); err != nil {
+		t.Fatal(err)
+	}
+	if report := risk.report(); report.Enabled || len(report.Auths) != 0 {
+		t.Fatalf("disabled tracker must not collect signals, got %+v", report)
+	}
+}
@@ -0,0 +1,62 @@
+package auth
+
+import "context"
+
+// RouteInfo identifies the credential that served a request.
+type RouteInfo struct {
+	AuthID    string
+	AuthIndex string
+	// Label is the display label of the serving credential, usually the account name.
+	Label    string
+	Provider string
+	// Model is the model requested from the provider after alias and OAuth model mapping.
+	Model string
+	// Attempts counts the credentials tried for the request, including the one that served it.
+	Attempts int
+}
+
+type routeRecorderKey struct{}
+
+// WithRouteRecorder returns a context in which the manager reports the credential that served
+// the request. The returned RouteInfo is filled once an execution succeeds or a stream is
+// established, and stays empty when every attempt fails. When ctx already carries a recorder,
+// ctx and that recorder are returned so every caller observes the same route.
+func WithRouteRecorder(ctx context.Context) (context.Context, *RouteInfo) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if info, ok := ctx.Value(routeRecorderKey{}).(*RouteInfo); ok && info != nil {
+		return ctx, info
+	}
+	info := &RouteInfo{}
+	return context.WithValue(ctx, routeRecorderKey{}, info), info
+}
+
+// routeRecorder returns the recorder of ctx, or nil.
+func routeRecorder(ctx context.Context) *RouteInfo {
+	if ctx == nil {
+		return nil
+	}
+	info, _ := ctx.Value(routeRecorderKey{}).(*RouteInfo)
+	return info
+}
+
+// countRouteAttempt notes that another credential is being tried for the request of ctx.
+func countRouteAttempt(ctx context.Context) {
+	if info := routeRecorder(ctx); info != nil {
+		info.Attempts++
+	}
+}
+
+// recordRoute stores the serving credential in the recorder of ctx, if any.
+func recordRoute(ctx context.Context, auth *Auth, provider, model string) {
+	info := routeRecorder(ctx)
+	if info == nil || auth == nil {
+		return
+	}
+	info.AuthID = auth.ID
+	info.AuthIndex = auth.Index
+	info.Label = auth.Label
+	info.Provider = provider
+	info.Model = model
+}
@@ -119,6 +119,35 @@ func authPriority(auth *Auth) int {
 	return parsed
 }
 
+// MaxAuthWeight bounds the weighted share of a single auth.
+const MaxAuthWeight = 100
+
+// authWeight returns the weight of auth. The metadata value, which the management API updates,
+// wins over the attribute synthesized when the auth was loaded.
+func authWeight(auth *Auth) int {
+	if auth == nil {
+		return 1
+	}
+	weight := 0
+	switch v := auth.Metadata["weight"].(type) {
+	case int:
+		weight = v
+	case int64:
+		weight = int(v)
+	case float64:
+		weight = int(v)
+	default:
+		weight, _ = strconv.Atoi(strings.TrimSpace(auth.Attributes["weight"]))
+	}
+	if weight < 1 {
+		return 1
+	}
+	if weight > MaxAuthWeight {
+		return MaxAuthWeight
+	}
+	return weight
+}
+
 func collectAvailableByPriority(auths []*Auth, model string, now time.Time) (available map[int][]*Auth, cooldownCount int, earliest time.Time) {
 	available = make(map[int][]*Auth)
 	for i := 0; i < len(auths); i++ {
@@ -145,6 +174,7 @@ func getAvailableAuths(auths []*Auth, provider, model string, now time.Time) ([]
 	}
 
 	availableByPriority, cooldownCount, earliest := collectAvailableByPriority(auths, model, now)
+	availableByPriority = preferQuotaHeadroom(availableByPriority, now)
 	if len(availableByPriority) == 0 {
 		if cooldownCount == len(auths) && !earliest.IsZero() {
 			providerForError := provider
@@ -221,6 +251,13 @@ func isAuthBlockedForModel(auth *Auth, model string, now time.Time) (bool, block
 	if auth.Disabled || auth.Status == StatusDisabled {
 		return true, blockReasonDisabled, time.Time{}
 	}
+	if isDailyCapped(auth, now) {
+		return true, blockReasonCooldown, auth.Quota.NextRecoverAt
+	}
+	if auth.Circuit.blocks(now) {
+		// An open circuit recovers at the next probe, so callers get a Retry-After like any cooldown.
+		return true, blockReasonCooldown, auth.Circuit.NextProbeAt
+	}
 	if model != "" {
 		if len(auth.ModelStates) > 0 {
 			if state, ok := auth.ModelStates[model]; ok && state != nil {
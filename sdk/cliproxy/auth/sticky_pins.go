@@ -0,0 +1,261 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// stickyPinPrefix prefixes the binding keys of pins.
+const stickyPinPrefix = "pin:"
+
+// SessionPinTarget names the requests a pin applies to. Exactly one of SessionKey and APIKey is
+// set.
+type SessionPinTarget struct {
+	// SessionKey is the session as the client sends it: a Codex session_id, a Claude Code session
+	// ID, an X-CLIProxy-Session-Key value, or the value of a configured header: or json: key
+	// source.
+	SessionKey string
+	// APIKey is a client API key; its pin applies to every request of the key without a pinned
+	// session.
+	APIKey string
+	// Model limits the pin to one model. It is required when the selector binds per model and
+	// ignored otherwise.
+	Model string
+}
+
+// SessionPin is a binding an operator pinned to an auth.
+type SessionPin struct {
+	SessionKey string `json:"session_key,omitempty"`
+	// APIKey is the masked client API key.
+	APIKey   string    `json:"api_key,omitempty"`
+	Provider string    `json:"provider"`
+	Model    string    `json:"model,omitempty"`
+	AuthID   string    `json:"auth_id"`
+	PinnedAt time.Time `json:"pinned_at"`
+}
+
+// sessionPin is shared by the bindings of one pin.
+type sessionPin struct {
+	id       string
+	session  string
+	apiKey   string
+	provider string
+	model    string
+	pinnedAt time.Time
+}
+
+// PinSession binds the target's requests to auth until UnpinSession, replacing any pin of the
+// target. While auth is unavailable its requests are routed as if unpinned.
+func (s *StickySelector) PinSession(target SessionPinTarget, auth *Auth) error {
+	if s == nil {
+		return errors.New("sticky selector unavailable")
+	}
+	if auth == nil || auth.ID == "" {
+		return errors.New("auth is required")
+	}
+	id, err := s.pinID(target)
+	if err != nil {
+		return err
+	}
+	provider := strings.TrimSpace(auth.Provider)
+	pin := &sessionPin{
+		id:       id,
+		session:  strings.TrimSpace(target.SessionKey),
+		apiKey:   maskPinnedAPIKey(strings.TrimSpace(target.APIKey)),
+		provider: provider,
+		model:    strings.TrimSpace(target.Model),
+		pinnedAt: time.Now(),
+	}
+	if !s.PerModel {
+		pin.model = ""
+	}
+	suffix := ""
+	if pin.model != "" {
+		suffix = "|" + pin.model
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.bindings == nil {
+		s.bindings = make(map[string]stickyBinding)
+	}
+	s.unpinLocked(id, pin.model, true)
+	for _, key := range s.pinSessionKeys(provider, target) {
+		s.bindings[stickyPinPrefix+key+suffix] = stickyBinding{authID: auth.ID, pin: pin}
+	}
+	return nil
+}
+
+// UnpinSession removes the pins of target on every provider and returns how many were removed.
+// An empty Model removes the pins of every model.
+func (s *StickySelector) UnpinSession(target SessionPinTarget) (int, error) {
+	if s == nil {
+		return 0, nil
+	}
+	id, err := s.pinIDAnyModel(target)
+	if err != nil {
+		return 0, err
+	}
+	model := strings.TrimSpace(target.Model)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.unpinLocked(id, model, model != ""), nil
+}
+
+// PinnedSessions lists the pins, oldest first.
+func (s *StickySelector) PinnedSessions() []SessionPin {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	seen := make(map[*sessionPin]string)
+	for _, binding := range s.bindings {
+		if binding.pin != nil {
+			seen[binding.pin] = binding.authID
+		}
+	}
+	s.mu.Unlock()
+
+	out := make([]SessionPin, 0, len(seen))
+	for pin, authID := range seen {
+		out = append(out, SessionPin{
+			SessionKey: pin.session,
+			APIKey:     pin.apiKey,
+			Provider:   pin.provider,
+			Model:      pin.model,
+			AuthID:     authID,
+			PinnedAt:   pin.pinnedAt,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].PinnedAt.Before(out[j].PinnedAt) })
+	return out
+}
+
+// TakePins copies the pins of from, so they survive the selector being rebuilt on a config reload.
+func (s *StickySelector) TakePins(from *StickySelector) {
+	if s == nil || from == nil || s == from {
+		return
+	}
+	from.mu.Lock()
+	pinned := make(map[string]stickyBinding)
+	for key, binding := range from.bindings {
+		if binding.pin != nil {
+			pinned[key] = binding
+		}
+	}
+	from.mu.Unlock()
+	if len(pinned) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.bindings == nil {
+		s.bindings = make(map[string]stickyBinding, len(pinned))
+	}
+	for key, binding := range pinned {
+		s.bindings[key] = binding
+	}
+}
+
+// pinnedAuthLocked returns the available auth the session or client API key of a request is
+// pinned to. A session pin wins over an API key pin. Pins are keyed without the provider a
+// request is routed on, so they apply whether it is picked per provider or across providers.
+func (s *StickySelector) pinnedAuthLocked(suffix, sessionKey string, headers http.Header, available []*Auth, now time.Time) *Auth {
+	keys := make([]string, 0, 2)
+	if sessionKey != "" {
+		keys = append(keys, stickyPinPrefix+sessionKey+suffix)
+	}
+	if apiKey := stickySessionKeyFromSource(StickyKeyAPIKey, headers, nil); apiKey != "" && apiKey != sessionKey {
+		keys = append(keys, stickyPinPrefix+apiKey+suffix)
+	}
+	for _, key := range keys {
+		binding, ok := s.bindings[key]
+		if !ok || binding.pin == nil {
+			continue
+		}
+		for _, candidate := range available {
+			if candidate != nil && candidate.ID == binding.authID {
+				binding.lastUsedAt = now
+				s.bindings[key] = binding
+				return candidate
+			}
+		}
+	}
+	return nil
+}
+
+// unpinLocked removes the bindings of the pin id; with matchModel only those of model.
+func (s *StickySelector) unpinLocked(id, model string, matchModel bool) int {
+	removed := make(map[*sessionPin]struct{})
+	for key, binding := range s.bindings {
+		if binding.pin == nil || binding.pin.id != id || (matchModel && binding.pin.model != model) {
+			continue
+		}
+		removed[binding.pin] = struct{}{}
+		delete(s.bindings, key)
+	}
+	return len(removed)
+}
+
+// pinID validates target and identifies its pin independently of provider and model.
+func (s *StickySelector) pinID(target SessionPinTarget) (string, error) {
+	if s.PerModel && strings.TrimSpace(target.Model) == "" {
+		return "", errors.New("model is required while sessions are bound per model")
+	}
+	return s.pinIDAnyModel(target)
+}
+
+func (s *StickySelector) pinIDAnyModel(target SessionPinTarget) (string, error) {
+	session := strings.TrimSpace(target.SessionKey)
+	apiKey := strings.TrimSpace(target.APIKey)
+	switch {
+	case session != "" && apiKey != "":
+		return "", errors.New("set either session_key or api_key, not both")
+	case session != "":
+		return "session:" + session, nil
+	case apiKey != "":
+		return "api-key:" + stableHash(apiKey), nil
+	default:
+		return "", errors.New("session_key or api_key is required")
+	}
+}
+
+// pinSessionKeys returns the session keys the target's requests may carry on provider: an API
+// key has one, a session key one per key source it may arrive by.
+func (s *StickySelector) pinSessionKeys(provider string, target SessionPinTarget) []string {
+	if apiKey := strings.TrimSpace(target.APIKey); apiKey != "" {
+		return []string{"apikey:" + stableHash(apiKey)}
+	}
+	session := strings.TrimSpace(target.SessionKey)
+	hashed := stableHash(session)
+	keys := []string{"codex:" + hashed, "claude:" + strings.ToLower(session), "header:" + hashed}
+	_, sources := s.optionsFor(provider)
+	for _, source := range sources {
+		source = strings.TrimSpace(source)
+		if !ValidStickyKeySource(source) {
+			continue
+		}
+		switch {
+		case strings.EqualFold(source[:min(len(source), len(StickyKeyHeaderPrefix))], StickyKeyHeaderPrefix):
+			name := strings.ToLower(strings.TrimSpace(source[len(StickyKeyHeaderPrefix):]))
+			keys = append(keys, "hdr:"+name+":"+hashed)
+		case strings.EqualFold(source[:min(len(source), len(StickyKeyJSONPrefix))], StickyKeyJSONPrefix):
+			keys = append(keys, "json:"+strings.TrimSpace(source[len(StickyKeyJSONPrefix):])+":"+hashed)
+		}
+	}
+	return keys
+}
+
+// maskPinnedAPIKey keeps the first and last four characters of a client API key for display.
+func maskPinnedAPIKey(apiKey string) string {
+	if apiKey == "" {
+		return ""
+	}
+	if len(apiKey) <= 8 {
+		return strings.Repeat("*", len(apiKey))
+	}
+	return apiKey[:4] + "..." + apiKey[len(apiKey)-4:]
+}
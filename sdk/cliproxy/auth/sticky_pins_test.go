@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func pinTestAuths() []*Auth {
+	return []*Auth{
+		{ID: "auth-a", Provider: "codex", Status: StatusActive},
+		{ID: "auth-b", Provider: "codex", Status: StatusActive},
+	}
+}
+
+func sessionOpts(session string) cliproxyexecutor.Options {
+	headers := make(http.Header)
+	headers.Set("session_id", session)
+	headers.Set("Authorization", "Bearer client-key-1234")
+	return cliproxyexecutor.Options{Headers: headers, OriginalRequest: []byte(`{}`)}
+}
+
+func TestStickySelectorPinOverridesBinding(t *testing.T) {
+	selector := &StickySelector{}
+	auths := pinTestAuths()
+	opts := sessionOpts("s1")
+
+	bound, err := selector.Pick(nil, "codex", "gpt-test", opts, auths)
+	if err != nil {
+		t.Fatalf("Pick: %v", err)
+	}
+	other := auths[0]
+	if other.ID == bound.ID {
+		other = auths[1]
+	}
+	if err := selector.PinSession(SessionPinTarget{SessionKey: "s1"}, other); err != nil {
+		t.Fatalf("PinSession: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if got, _ := selector.Pick(nil, "codex", "gpt-test", opts, auths); got.ID != other.ID {
+			t.Fatalf("pinned session must route to %s, got %s", other.ID, got.ID)
+		}
+	}
+	pins := selector.PinnedSessions()
+	if len(pins) != 1 || pins[0].SessionKey != "s1" || pins[0].AuthID != other.ID || pins[0].Provider != "codex" {
+		t.Fatalf("unexpected pins: %+v", pins)
+	}
+	statuses := selector.SessionBindingStatuses()
+	for _, status := range statuses {
+		if status.AuthID == other.ID && status.PinnedCount != 1 {
+			t.Fatalf("pinned auth must count one pin, got %+v", status)
+		}
+	}
+
+	if removed, err := selector.UnpinSession(SessionPinTarget{SessionKey: "s1"}); err != nil || removed != 1 {
+		t.Fatalf("UnpinSession = %d, %v", removed, err)
+	}
+	if len(selector.PinnedSessions()) != 0 {
+		t.Fatal("unpinned session must not be listed")
+	}
+	if _, err := selector.Pick(nil, "codex", "gpt-test", opts, auths); err != nil {
+		t.Fatalf("Pick after unpin: %v", err)
+	}
+}
+
+func TestStickySelectorPinAPIKey(t *testing.T) {
+	selector := &StickySelector{}
+	auths := pinTestAuths()
+	if err := selector.PinSession(SessionPinTarget{APIKey: "client-key-1234"}, auths[1]); err != nil {
+		t.Fatalf("PinSession: %v", err)
+	}
+	for _, session := range []string{"s1", "s2", "s3", "s4"} {
+		if got, _ := selector.Pick(nil, "codex", "gpt-test", sessionOpts(session), auths); got.ID != "auth-b" {
+			t.Fatalf("session %s of a pinned API key must route to auth-b, got %s", session, got.ID)
+		}
+	}
+	if err := selector.PinSession(SessionPinTarget{SessionKey: "s1"}, auths[0]); err != nil {
+		t.Fatalf("PinSession: %v", err)
+	}
+	if got, _ := selector.Pick(nil, "codex", "gpt-test", sessionOpts("s1"), auths); got.ID != "auth-a" {
+		t.Fatalf("a session pin must win over an API key pin, got %s", got.ID)
+	}
+	if pins := selector.PinnedSessions(); len(pins) != 2 || pins[0].APIKey == "client-key-1234" {
+		t.Fatalf("pins must be listed with masked API keys: %+v", pins)
+	}
+}
+
+func TestStickySelectorPinKeptWhileAuthUnavailable(t *testing.T) {
+	selector := &StickySelector{}
+	auths := pinTestAuths()
+	if err := selector.PinSession(SessionPinTarget{SessionKey: "s1"}, auths[0]); err != nil {
+		t.Fatalf("PinSession: %v", err)
+	}
+	auths[0].Circuit = CircuitState{State: CircuitOpen, NextProbeAt: time.Now().Add(time.Hour)}
+	if got, err := selector.Pick(nil, "codex", "gpt-test", sessionOpts("s1"), auths); err != nil || got.ID != "auth-b" {
+		t.Fatalf("an unavailable pinned auth must fall back to another auth, got %v, %v", got, err)
+	}
+	auths[0].Circuit = CircuitState{}
+	if got, _ := selector.Pick(nil, "codex", "gpt-test", sessionOpts("s1"), auths); got.ID != "auth-a" {
+		t.Fatalf("the pin must apply again once its auth recovers, got %s", got.ID)
+	}
+
+	rebuilt := &StickySelector{}
+	rebuilt.TakePins(selector)
+	if got, _ := rebuilt.Pick(nil, "codex", "gpt-test", sessionOpts("s1"), auths); got.ID != "auth-a" {
+		t.Fatalf("pins must survive a selector rebuild, got %s", got.ID)
+	}
+}
+
+func TestStickySelectorPinValidation(t *testing.T) {
+	selector := &StickySelector{PerModel: true}
+	auth := pinTestAuths()[0]
+	cases := []SessionPinTarget{
+		{},
+		{SessionKey: "s1", APIKey: "k", Model: "m"},
+		{SessionKey: "s1"},
+	}
+	for _, target := range cases {
+		if err := selector.PinSession(target, auth); err == nil {
+			t.Fatalf("PinSession(%+v) must fail", target)
+		}
+	}
+	if err := selector.PinSession(SessionPinTarget{SessionKey: "s1", Model: "gpt-test"}, auth); err != nil {
+		t.Fatalf("PinSession: %v", err)
+	}
+	if got, _ := selector.Pick(nil, "codex", "other-model", sessionOpts("s1"), pinTestAuths()); got == nil {
+		t.Fatal("Pick for another model must still route")
+	}
+	if removed, _ := selector.UnpinSession(SessionPinTarget{SessionKey: "s1"}); removed != 1 {
+		t.Fatalf("unpinning without a model must remove every model's pin, removed %d", removed)
+	}
+}
+
+// servingExecutor records the auth that served each request.
+type servingExecutor struct {
+	providerExecutor
+	served []string
+}
+
+func (e *servingExecutor) Execute(_ context.Context, auth *Auth, _ cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	e.served = append(e.served, auth.ID)
+	return cliproxyexecutor.Response{Payload: []byte(`{}`)}, nil
+}
+
+func TestManagerExecuteHonorsSessionPin(t *testing.T) {
+	selector := &StickySelector{}
+	m := NewManager(nil, selector, nil)
+	executor := &servingExecutor{providerExecutor: providerExecutor{provider: "pin-exec"}}
+	m.RegisterExecutor(executor)
+	registerIndexedAuth(t, m, "pin-exec-a", "pin-exec", "pin-exec-model")
+	registerIndexedAuth(t, m, "pin-exec-b", "pin-exec", "pin-exec-model")
+
+	execute := func() string {
+		t.Helper()
+		executor.served = nil
+		req := cliproxyexecutor.Request{Model: "pin-exec-model"}
+		if _, err := m.Execute(context.Background(), []string{"pin-exec"}, req, sessionOpts("s1")); err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+		return executor.served[0]
+	}
+
+	bound := execute()
+	pinned := "pin-exec-a"
+	if bound == pinned {
+		pinned = "pin-exec-b"
+	}
+	auth, _ := m.GetByID(pinned)
+	if err := selector.PinSession(SessionPinTarget{SessionKey: "s1"}, auth); err != nil {
+		t.Fatalf("PinSession: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if served := execute(); served != pinned {
+			t.Fatalf("bound=%s pinned=%s served=%s", bound, pinned, served)
+		}
+	}
+}
@@ -18,12 +18,41 @@ import (
 
 const stickySessionTTL = time.Hour
 
+// Sources of sticky session keys, see StickySelector.KeySources.
+const (
+	// StickyKeyConversation uses the Codex session_id header or the Claude Code session in
+	// metadata.user_id, see ConversationKey.
+	StickyKeyConversation = "conversation"
+	// StickyKeyAPIKey uses the client API key from Authorization, X-Api-Key or X-Goog-Api-Key.
+	StickyKeyAPIKey = "api-key"
+	// StickyKeyUserAgent uses the User-Agent header.
+	StickyKeyUserAgent = "user-agent"
+	// StickyKeyHeaderPrefix followed by a header name uses that request header.
+	StickyKeyHeaderPrefix = "header:"
+	// StickyKeyJSONPrefix followed by a gjson path uses that value of the request body.
+	StickyKeyJSONPrefix = "json:"
+)
+
+var defaultStickyKeySources = []string{StickyKeyConversation, StickyKeyAPIKey, StickyKeyUserAgent}
+
+// StickySessionKeyHeader names the sticky session of a request when the selector honors it.
+// Requests sharing a value share a binding, whichever process sent them.
+const StickySessionKeyHeader = "X-CLIProxy-Session-Key"
+
 var claudeSessionRegex = regexp.MustCompile(`session_([a-f0-9-]{36})`)
 
 type stickyBinding struct {
 	authID     string
 	expiresAt  time.Time
 	lastUsedAt time.Time
+	// pin is set on bindings an operator pinned; they never expire and are kept while their auth
+	// is unavailable.
+	pin *sessionPin
+}
+
+// live reports whether the binding still routes its session at now.
+func (b stickyBinding) live(now time.Time) bool {
+	return b.authID != "" && (b.pin != nil || !now.After(b.expiresAt))
 }
 
 const (
@@ -36,12 +65,66 @@ const (
 //
 // It falls back to round-robin when no session key is available.
 type StickySelector struct {
+	// PerModel binds a session separately for every model. By default one binding per provider
+	// serves all models of the session.
+	PerModel bool
+	// SessionHeader makes a StickySessionKeyHeader on the request override the derived session key.
+	SessionHeader bool
+	// TTL is how long a binding survives without requests; zero means one hour.
+	TTL time.Duration
+	// KeySources lists where the session key is taken from, first match wins. Empty means
+	// conversation, api-key, user-agent.
+	KeySources []string
+	// Providers overrides TTL and KeySources per provider; zero fields keep the selector's own.
+	Providers map[string]StickyOptions
+
 	mu       sync.Mutex
 	bindings map[string]stickyBinding
 	lastGC   time.Time
 	rr       RoundRobinSelector
 }
 
+// StickyOptions holds the per-provider overrides of a StickySelector.
+type StickyOptions struct {
+	TTL        time.Duration
+	KeySources []string
+}
+
+// ValidStickyKeySource reports whether source names a supported session key source.
+func ValidStickyKeySource(source string) bool {
+	source = strings.TrimSpace(source)
+	switch strings.ToLower(source) {
+	case StickyKeyConversation, StickyKeyAPIKey, StickyKeyUserAgent:
+		return true
+	}
+	for _, prefix := range []string{StickyKeyHeaderPrefix, StickyKeyJSONPrefix} {
+		if len(source) > len(prefix) && strings.EqualFold(source[:len(prefix)], prefix) {
+			return strings.TrimSpace(source[len(prefix):]) != ""
+		}
+	}
+	return false
+}
+
+// optionsFor resolves the TTL and key sources in effect for provider.
+func (s *StickySelector) optionsFor(provider string) (time.Duration, []string) {
+	ttl, sources := s.TTL, s.KeySources
+	if override, ok := s.Providers[strings.ToLower(strings.TrimSpace(provider))]; ok {
+		if override.TTL > 0 {
+			ttl = override.TTL
+		}
+		if len(override.KeySources) > 0 {
+			sources = override.KeySources
+		}
+	}
+	if ttl <= 0 {
+		ttl = stickySessionTTL
+	}
+	if len(sources) == 0 {
+		sources = defaultStickyKeySources
+	}
+	return ttl, sources
+}
+
 func (s *StickySelector) gcLocked(now time.Time) {
 	if s == nil {
 		return
@@ -51,7 +134,7 @@ func (s *StickySelector) gcLocked(now time.Time) {
 		return
 	}
 	for k, v := range s.bindings {
-		if v.authID == "" || now.After(v.expiresAt) {
+		if !v.live(now) {
 			delete(s.bindings, k)
 		}
 	}
@@ -59,9 +142,11 @@ func (s *StickySelector) gcLocked(now time.Time) {
 }
 
 type SessionBindingStatus struct {
-	AuthID       string    `json:"auth_id"`
-	SessionCount int       `json:"session_count"`
-	LastUsedAt   time.Time `json:"last_used_at"`
+	AuthID       string `json:"auth_id"`
+	SessionCount int    `json:"session_count"`
+	// PinnedCount counts the session keys and client API keys an operator pinned to the auth.
+	PinnedCount int       `json:"pinned_count,omitempty"`
+	LastUsedAt  time.Time `json:"last_used_at"`
 }
 
 func (s *StickySelector) SessionBindingStatuses() []SessionBindingStatus {
@@ -77,13 +162,20 @@ func (s *StickySelector) SessionBindingStatuses() []SessionBindingStatus {
 	}
 
 	stats := make(map[string]SessionBindingStatus, len(s.bindings))
+	pins := make(map[*sessionPin]struct{})
 	for _, binding := range s.bindings {
-		if binding.authID == "" || now.After(binding.expiresAt) {
+		if !binding.live(now) {
 			continue
 		}
 		entry := stats[binding.authID]
 		entry.AuthID = binding.authID
-		entry.SessionCount++
+		if binding.pin == nil {
+			entry.SessionCount++
+		} else if _, seen := pins[binding.pin]; !seen {
+			// A pinned session key is bound under every key source it may arrive by.
+			pins[binding.pin] = struct{}{}
+			entry.PinnedCount++
+		}
 		if entry.LastUsedAt.IsZero() || binding.lastUsedAt.After(entry.LastUsedAt) {
 			entry.LastUsedAt = binding.lastUsedAt
 		}
@@ -122,12 +214,10 @@ func stableHash(input string) string {
 	return hex.EncodeToString(sum[:16])
 }
 
-func extractStickySessionKey(opts cliproxyexecutor.Options) string {
-	var headers http.Header
-	if opts.Headers != nil {
-		headers = opts.Headers
-	}
-
+// ConversationKey identifies the conversation a request belongs to, using the Codex session_id
+// header or the Claude Code session embedded in metadata.user_id. It returns "" when the request
+// carries no conversation identity.
+func ConversationKey(headers http.Header, rawJSON []byte) string {
 	if headers != nil {
 		if sid := strings.TrimSpace(headers.Get("session_id")); sid != "" {
 			if hashed := stableHash(sid); hashed != "" {
@@ -136,16 +226,36 @@ func extractStickySessionKey(opts cliproxyexecutor.Options) string {
 		}
 	}
 
-	if len(opts.OriginalRequest) > 0 {
-		userID := strings.TrimSpace(gjson.GetBytes(opts.OriginalRequest, "metadata.user_id").String())
+	if len(rawJSON) > 0 {
+		userID := strings.TrimSpace(gjson.GetBytes(rawJSON, "metadata.user_id").String())
 		if userID != "" {
 			if match := claudeSessionRegex.FindStringSubmatch(strings.ToLower(userID)); len(match) == 2 {
 				return "claude:" + match[1]
 			}
 		}
 	}
+	return ""
+}
 
-	if headers != nil {
+// extractStickySessionKeyFrom returns the session key of the first source that yields one.
+// Unknown sources are skipped.
+func extractStickySessionKeyFrom(sources []string, opts cliproxyexecutor.Options) string {
+	for _, source := range sources {
+		if key := stickySessionKeyFromSource(strings.TrimSpace(source), opts.Headers, opts.OriginalRequest); key != "" {
+			return key
+		}
+	}
+	return ""
+}
+
+func stickySessionKeyFromSource(source string, headers http.Header, rawJSON []byte) string {
+	switch strings.ToLower(source) {
+	case StickyKeyConversation:
+		return ConversationKey(headers, rawJSON)
+	case StickyKeyAPIKey:
+		if headers == nil {
+			return ""
+		}
 		if tok := extractBearerToken(headers.Get("authorization")); tok != "" {
 			if hashed := stableHash(tok); hashed != "" {
 				return "apikey:" + hashed
@@ -161,17 +271,71 @@ func extractStickySessionKey(opts cliproxyexecutor.Options) string {
 				return "apikey:" + hashed
 			}
 		}
-
-		if ua := strings.TrimSpace(headers.Get("user-agent")); ua != "" {
-			if hashed := stableHash(ua); hashed != "" {
-				return "ua:" + hashed
-			}
+		return ""
+	case StickyKeyUserAgent:
+		if headers == nil {
+			return ""
 		}
+		if hashed := stableHash(headers.Get("user-agent")); hashed != "" {
+			return "ua:" + hashed
+		}
+		return ""
+	}
+	if !ValidStickyKeySource(source) {
+		return ""
+	}
+	if strings.EqualFold(source[:len(StickyKeyHeaderPrefix)], StickyKeyHeaderPrefix) {
+		name := strings.TrimSpace(source[len(StickyKeyHeaderPrefix):])
+		if headers == nil {
+			return ""
+		}
+		if hashed := stableHash(headers.Get(name)); hashed != "" {
+			return "hdr:" + strings.ToLower(name) + ":" + hashed
+		}
+		return ""
+	}
+	path := strings.TrimSpace(source[len(StickyKeyJSONPrefix):])
+	if len(rawJSON) == 0 {
+		return ""
+	}
+	if hashed := stableHash(gjson.GetBytes(rawJSON, path).String()); hashed != "" {
+		return "json:" + path + ":" + hashed
 	}
+	return ""
+}
 
+// headerStickySessionKey returns the session key a client set with StickySessionKeyHeader, or ""
+// when the header is absent.
+func headerStickySessionKey(headers http.Header) string {
+	if headers == nil {
+		return ""
+	}
+	if hashed := stableHash(headers.Get(StickySessionKeyHeader)); hashed != "" {
+		return "header:" + hashed
+	}
 	return ""
 }
 
+// candidatesProvider returns the provider every candidate belongs to, or provider when the
+// candidates span several providers.
+func candidatesProvider(provider string, candidates []*Auth) string {
+	common := ""
+	for _, candidate := range candidates {
+		if candidate == nil {
+			continue
+		}
+		if common == "" {
+			common = candidate.Provider
+		} else if !strings.EqualFold(common, candidate.Provider) {
+			return provider
+		}
+	}
+	if common == "" {
+		return provider
+	}
+	return common
+}
+
 func rendezvousScore(sessionKey, authID string) uint64 {
 	h := sha256.New()
 	_, _ = h.Write([]byte(sessionKey))
@@ -209,12 +373,20 @@ func (s *StickySelector) Pick(ctx context.Context, provider, model string, opts
 		return nil, err
 	}
 
-	sessionKey := extractStickySessionKey(opts)
+	sessionKey := ""
+	if s.SessionHeader {
+		sessionKey = headerStickySessionKey(opts.Headers)
+	}
+	// Requests are picked across providers, so the overrides follow the provider of the
+	// candidates rather than the provider Pick is called with.
+	_, sources := s.optionsFor(candidatesProvider(provider, available))
 	if sessionKey == "" {
-		return s.rr.Pick(ctx, provider, model, opts, auths)
+		sessionKey = extractStickySessionKeyFrom(sources, opts)
+	}
+	suffix := ""
+	if s.PerModel && model != "" {
+		suffix = "|" + model
 	}
-
-	bindingKey := provider + ":" + sessionKey
 
 	s.mu.Lock()
 	if s.bindings == nil {
@@ -223,14 +395,27 @@ func (s *StickySelector) Pick(ctx context.Context, provider, model string, opts
 	if len(s.bindings) > 0 && (len(s.bindings) >= stickyGCMinEntries || s.lastGC.IsZero() || now.Sub(s.lastGC) >= stickyGCInterval) {
 		s.gcLocked(now)
 	}
+	if pinned := s.pinnedAuthLocked(suffix, sessionKey, opts.Headers, available, now); pinned != nil {
+		s.mu.Unlock()
+		return pinned, nil
+	}
+	if sessionKey == "" {
+		s.mu.Unlock()
+		return s.rr.Pick(ctx, provider, model, opts, auths)
+	}
+
+	sessionKey += suffix
+	bindingKey := provider + ":" + sessionKey
 
-	if existing, ok := s.bindings[bindingKey]; ok {
+	existing, ok := s.bindings[bindingKey]
+	if ok {
 		if existing.authID != "" && now.Before(existing.expiresAt) {
 			for _, candidate := range available {
 				if candidate != nil && candidate.ID == existing.authID {
+					ttl, _ := s.optionsFor(candidate.Provider)
 					s.bindings[bindingKey] = stickyBinding{
 						authID:     candidate.ID,
-						expiresAt:  now.Add(stickySessionTTL),
+						expiresAt:  now.Add(ttl),
 						lastUsedAt: now,
 					}
 					s.mu.Unlock()
@@ -263,7 +448,7 @@ func (s *StickySelector) Pick(ctx context.Context, provider, model string, opts
 		if !strings.HasPrefix(k, provider+":") {
 			continue
 		}
-		if binding.authID == "" || now.After(binding.expiresAt) {
+		if !binding.live(now) {
 			continue
 		}
 		loadByAuthID[binding.authID]++
@@ -293,9 +478,10 @@ func (s *StickySelector) Pick(ctx context.Context, provider, model string, opts
 		s.mu.Unlock()
 		return nil, &Error{Code: "auth_not_found", Message: "no auth available"}
 	}
+	ttl, _ := s.optionsFor(selected.Provider)
 	s.bindings[bindingKey] = stickyBinding{
 		authID:     selected.ID,
-		expiresAt:  now.Add(stickySessionTTL),
+		expiresAt:  now.Add(ttl),
 		lastUsedAt: now,
 	}
 	s.mu.Unlock()
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 	"strings"
@@ -20,7 +21,7 @@ func TestExtractStickySessionKey_PriorityOrder(t *testing.T) {
 		OriginalRequest: []byte(`{"metadata":{"user_id":"user_aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa_account__session_11111111-2222-3333-4444-555555555555"}}`),
 	}
 
-	key := extractStickySessionKey(opts)
+	key := extractStickySessionKeyFrom(defaultStickyKeySources, opts)
 	if key == "" {
 		t.Fatal("expected non-empty session key")
 	}
@@ -29,19 +30,19 @@ func TestExtractStickySessionKey_PriorityOrder(t *testing.T) {
 	}
 
 	opts.Headers.Del("session_id")
-	key = extractStickySessionKey(opts)
+	key = extractStickySessionKeyFrom(defaultStickyKeySources, opts)
 	if !strings.HasPrefix(key, "claude:") {
 		t.Fatalf("expected claude metadata.user_id to be used, got %q", key)
 	}
 
 	opts.OriginalRequest = []byte(`{"metadata":{"user_id":"not-a-match"}}`)
-	key = extractStickySessionKey(opts)
+	key = extractStickySessionKeyFrom(defaultStickyKeySources, opts)
 	if !strings.HasPrefix(key, "apikey:") {
 		t.Fatalf("expected api key fallback, got %q", key)
 	}
 
 	opts.Headers.Del("authorization")
-	key = extractStickySessionKey(opts)
+	key = extractStickySessionKeyFrom(defaultStickyKeySources, opts)
 	if !strings.HasPrefix(key, "ua:") {
 		t.Fatalf("expected user-agent fallback, got %q", key)
 	}
@@ -180,7 +181,7 @@ func TestStickySelector_LoadBalancingPrefersLeastLoaded(t *testing.T) {
 	pickID := func(sessionID string) string {
 		headers := make(http.Header)
 		headers.Set("session_id", sessionID)
-		key := extractStickySessionKey(cliproxyexecutor.Options{Headers: headers, OriginalRequest: []byte(`{}`)})
+		key := extractStickySessionKeyFrom(defaultStickyKeySources, cliproxyexecutor.Options{Headers: headers, OriginalRequest: []byte(`{}`)})
 		picked := pickRendezvous(key, []*Auth{auth1, auth2})
 		if picked == nil {
 			return ""
@@ -236,3 +237,162 @@ func TestStickySelector_LoadBalancingPrefersLeastLoaded(t *testing.T) {
 		t.Fatalf("expected second session to pick a different auth from first; got %q for both", second.ID)
 	}
 }
+
+func TestStickySelector_PerModelBindings(t *testing.T) {
+	provider := "claude"
+	auths := []*Auth{
+		{ID: "a", Provider: provider, Status: StatusActive},
+		{ID: "b", Provider: provider, Status: StatusActive},
+	}
+	headers := make(http.Header)
+	headers.Set("session_id", "s123")
+	opts := cliproxyexecutor.Options{Headers: headers}
+
+	shared := &StickySelector{}
+	main, err := shared.Pick(nil, provider, "big-model", opts, auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	background, err := shared.Pick(nil, provider, "small-model", opts, auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	if background.ID != main.ID {
+		t.Fatalf("provider-scoped binding must serve every model, got %q and %q", main.ID, background.ID)
+	}
+
+	perModel := &StickySelector{PerModel: true}
+	main, err = perModel.Pick(nil, provider, "big-model", opts, auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	background, err = perModel.Pick(nil, provider, "small-model", opts, auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	if background.ID == main.ID {
+		t.Fatalf("per-model bindings must spread to the less loaded auth, both got %q", main.ID)
+	}
+	again, err := perModel.Pick(nil, provider, "big-model", opts, auths)
+	if err != nil || again.ID != main.ID {
+		t.Fatalf("main model binding must stay pinned, got %v, %v", again, err)
+	}
+}
+
+func TestStickySelector_SessionKeyHeader(t *testing.T) {
+	provider := "claude"
+	auths := []*Auth{
+		{ID: "a", Provider: provider, Status: StatusActive},
+		{ID: "b", Provider: provider, Status: StatusActive},
+	}
+	optsFor := func(sessionID, sessionKey string) cliproxyexecutor.Options {
+		headers := make(http.Header)
+		headers.Set("session_id", sessionID)
+		if sessionKey != "" {
+			headers.Set(StickySessionKeyHeader, sessionKey)
+		}
+		return cliproxyexecutor.Options{Headers: headers}
+	}
+
+	sel := &StickySelector{SessionHeader: true}
+	first, err := sel.Pick(nil, provider, "m", optsFor("proc-1", "job-42"), auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	grouped, err := sel.Pick(nil, provider, "m", optsFor("proc-2", "job-42"), auths)
+	if err != nil || grouped.ID != first.ID {
+		t.Fatalf("processes sharing a session key must share a binding, got %v, %v", grouped, err)
+	}
+	separated, err := sel.Pick(nil, provider, "m", optsFor("proc-1", "job-43"), auths)
+	if err != nil || separated.ID == first.ID {
+		t.Fatalf("a different session key must get its own binding, got %v, %v", separated, err)
+	}
+
+	ignored := &StickySelector{}
+	first, _ = ignored.Pick(nil, provider, "m", optsFor("proc-1", "job-42"), auths)
+	other, _ := ignored.Pick(nil, provider, "m", optsFor("proc-2", "job-42"), auths)
+	if other.ID == first.ID {
+		t.Fatalf("the header must be ignored unless enabled, both sessions got %q", first.ID)
+	}
+}
+
+func TestExtractStickySessionKeyFrom_ConfiguredSources(t *testing.T) {
+	headers := make(http.Header)
+	headers.Set("session_id", "s123")
+	headers.Set("X-Chat-Id", "chat-1")
+	opts := cliproxyexecutor.Options{
+		Headers:         headers,
+		OriginalRequest: []byte(`{"metadata":{"conversation_id":"conv-1"}}`),
+	}
+
+	if key := extractStickySessionKeyFrom([]string{"header:X-Chat-Id", "conversation"}, opts); !strings.HasPrefix(key, "hdr:x-chat-id:") {
+		t.Fatalf("expected the configured header to win, got %q", key)
+	}
+	if key := extractStickySessionKeyFrom([]string{"bogus", "json:metadata.conversation_id"}, opts); !strings.HasPrefix(key, "json:metadata.conversation_id:") {
+		t.Fatalf("expected the JSON path key after skipping the unknown source, got %q", key)
+	}
+	if key := extractStickySessionKeyFrom([]string{"json:metadata.missing", "user-agent"}, opts); key != "" {
+		t.Fatalf("expected no key without matching sources, got %q", key)
+	}
+	for source, want := range map[string]bool{"Conversation": true, "header:": false, "json:a.b": true, "cookie:x": false} {
+		if got := ValidStickyKeySource(source); got != want {
+			t.Fatalf("ValidStickyKeySource(%q) = %v, want %v", source, got, want)
+		}
+	}
+}
+
+func TestStickySelector_ProviderOverrides(t *testing.T) {
+	sel := &StickySelector{
+		TTL:        time.Minute,
+		KeySources: []string{"header:X-Chat-Id"},
+		Providers:  map[string]StickyOptions{"codex": {TTL: time.Hour}},
+	}
+	ttl, sources := sel.optionsFor("codex")
+	if ttl != time.Hour || len(sources) != 1 || sources[0] != "header:X-Chat-Id" {
+		t.Fatalf("codex options = %v, %v; want the TTL override and the shared sources", ttl, sources)
+	}
+	ttl, _ = sel.optionsFor("claude")
+	if ttl != time.Minute {
+		t.Fatalf("claude TTL = %v, want the selector TTL", ttl)
+	}
+	if ttl, sources = (&StickySelector{}).optionsFor("claude"); ttl != stickySessionTTL || len(sources) != 3 {
+		t.Fatalf("default options = %v, %v", ttl, sources)
+	}
+
+	auths := []*Auth{{ID: "a", Provider: "codex", Status: StatusActive}, {ID: "b", Provider: "codex", Status: StatusActive}}
+	headers := make(http.Header)
+	headers.Set("X-Chat-Id", "chat-1")
+	first, err := sel.Pick(nil, "codex", "m", cliproxyexecutor.Options{Headers: headers}, auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	sel.mu.Lock()
+	binding := sel.bindings["codex:hdr:x-chat-id:"+stableHash("chat-1")]
+	sel.mu.Unlock()
+	if binding.authID != first.ID || time.Until(binding.expiresAt) < 59*time.Minute {
+		t.Fatalf("binding = %+v, want %q bound for an hour", binding, first.ID)
+	}
+}
+
+func TestManagerExecuteAppliesStickyProviderOverrides(t *testing.T) {
+	selector := &StickySelector{TTL: time.Minute, Providers: map[string]StickyOptions{"sticky-prov": {TTL: 3 * time.Hour}}}
+	m := NewManager(nil, selector, nil)
+	m.RegisterExecutor(&servingExecutor{providerExecutor: providerExecutor{provider: "sticky-prov"}})
+	registerIndexedAuth(t, m, "sticky-prov-a", "sticky-prov", "sticky-prov-model")
+	registerIndexedAuth(t, m, "sticky-prov-b", "sticky-prov", "sticky-prov-model")
+
+	req := cliproxyexecutor.Request{Model: "sticky-prov-model"}
+	if _, err := m.Execute(context.Background(), []string{"sticky-prov"}, req, sessionOpts("s1")); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	selector.mu.Lock()
+	defer selector.mu.Unlock()
+	if len(selector.bindings) != 1 {
+		t.Fatalf("bindings = %+v, want one", selector.bindings)
+	}
+	for key, binding := range selector.bindings {
+		if time.Until(binding.expiresAt) < 2*time.Hour {
+			t.Fatalf("binding %s expires at %v, want the provider TTL of 3h", key, binding.expiresAt)
+		}
+	}
+}
@@ -44,8 +44,13 @@ type Auth struct {
 	Metadata map[string]any `json:"metadata,omitempty"`
 	// Quota captures recent quota information for load balancers.
 	Quota QuotaState `json:"quota"`
+	// OverloadLevel stores the backoff exponent for consecutive overloaded responses.
+	OverloadLevel int `json:"overload_level,omitempty"`
 	// LastError stores the last failure encountered while executing or refreshing.
 	LastError *Error `json:"last_error,omitempty"`
+	// ReauthChecks counts failed refresh re-checks while the auth waits for a re-login; it spaces
+	// out the next re-check.
+	ReauthChecks int `json:"reauth_checks,omitempty"`
 	// CreatedAt is the creation timestamp in UTC.
 	CreatedAt time.Time `json:"created_at"`
 	// UpdatedAt is the last modification timestamp in UTC.
@@ -58,6 +63,8 @@ type Auth struct {
 	NextRetryAfter time.Time `json:"next_retry_after"`
 	// ModelStates tracks per-model runtime availability data.
 	ModelStates map[string]*ModelState `json:"model_states,omitempty"`
+	// Circuit tracks the circuit breaker opened by consecutive upstream failures.
+	Circuit CircuitState `json:"circuit"`
 
 	// Runtime carries non-serialisable data used during execution (in-memory only).
 	Runtime any `json:"-"`
@@ -91,6 +98,8 @@ type ModelState struct {
 	LastError *Error `json:"last_error,omitempty"`
 	// Quota retains quota information if this model hit rate limits.
 	Quota QuotaState `json:"quota"`
+	// OverloadLevel stores the backoff exponent for consecutive overloaded responses.
+	OverloadLevel int `json:"overload_level,omitempty"`
 	// UpdatedAt tracks the last update timestamp for this model state.
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// WeightedSelector distributes requests across the auths of the best priority tier in proportion
+// to their weight, using smooth weighted round-robin: an auth of weight 3 next to one of weight 1
+// serves three of every four requests, spread out instead of in a run. Auths without a weight
+// count as 1, which makes the selector a plain round-robin when no weights are set.
+type WeightedSelector struct {
+	mu sync.Mutex
+	// current holds the running weight of every auth, keyed by provider and model.
+	current map[string]map[string]int
+}
+
+// Pick selects the next auth for the provider and model by weight.
+func (s *WeightedSelector) Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	_ = ctx
+	_ = opts
+	available, err := getAvailableAuths(auths, provider, model, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if len(available) == 1 {
+		return available[0], nil
+	}
+	key := provider + ":" + model
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current == nil {
+		s.current = make(map[string]map[string]int)
+	}
+	previous := s.current[key]
+	// Rebuilding the state from the candidates forgets auths that left the tier.
+	current := make(map[string]int, len(available))
+	total := 0
+	var best *Auth
+	for _, candidate := range available {
+		weight := authWeight(candidate)
+		total += weight
+		current[candidate.ID] = previous[candidate.ID] + weight
+		if best == nil || current[candidate.ID] > current[best.ID] {
+			best = candidate
+		}
+	}
+	current[best.ID] -= total
+	s.current[key] = current
+	return best, nil
+}
@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestWeightedSelectorPick_Smooth(t *testing.T) {
+	t.Parallel()
+
+	selector := &WeightedSelector{}
+	auths := []*Auth{
+		{ID: "c"},
+		{ID: "a", Attributes: map[string]string{"weight": "5"}},
+		{ID: "b", Metadata: map[string]any{"weight": float64(2)}, Attributes: map[string]string{"weight": "9"}},
+	}
+
+	// Weights 5, 2 and 1 over eight picks, with "a" never picked more than twice in a row.
+	want := []string{"a", "b", "a", "a", "c", "a", "b", "a"}
+	for round := 0; round < 2; round++ {
+		for i, id := range want {
+			got, err := selector.Pick(context.Background(), "kiro", "", cliproxyexecutor.Options{}, auths)
+			if err != nil {
+				t.Fatalf("Pick() #%d error = %v", i, err)
+			}
+			if got.ID != id {
+				t.Fatalf("round %d Pick() #%d auth.ID = %q, want %q", round, i, got.ID, id)
+			}
+		}
+	}
+}
+
+func TestWeightedSelectorPick_PriorityAndMembership(t *testing.T) {
+	t.Parallel()
+
+	selector := &WeightedSelector{}
+	low := &Auth{ID: "low", Attributes: map[string]string{"weight": "50"}}
+	a := &Auth{ID: "a", Attributes: map[string]string{"priority": "1", "weight": "3"}}
+	b := &Auth{ID: "b", Attributes: map[string]string{"priority": "1"}}
+
+	counts := map[string]int{}
+	for i := 0; i < 8; i++ {
+		got, err := selector.Pick(context.Background(), "kiro", "", cliproxyexecutor.Options{}, []*Auth{low, a, b})
+		if err != nil {
+			t.Fatalf("Pick() #%d error = %v", i, err)
+		}
+		counts[got.ID]++
+	}
+	if counts["a"] != 6 || counts["b"] != 2 || counts["low"] != 0 {
+		t.Fatalf("counts = %v, want a=6 b=2 and the lower tier unused", counts)
+	}
+
+	b.Disabled = true
+	for i := 0; i < 3; i++ {
+		got, err := selector.Pick(context.Background(), "kiro", "", cliproxyexecutor.Options{}, []*Auth{low, a, b})
+		if err != nil {
+			t.Fatalf("Pick() error = %v", err)
+		}
+		if got.ID != "a" {
+			t.Fatalf("Pick() auth.ID = %q, want the only available auth of the tier", got.ID)
+		}
+	}
+}
+
+func TestWeightedSelectorPick_Distribution(t *testing.T) {
+	t.Parallel()
+
+	selector := &WeightedSelector{}
+	auths := []*Auth{
+		{ID: "light"},
+		{ID: "medium", Attributes: map[string]string{"weight": "3"}},
+		{ID: "heavy", Metadata: map[string]any{"weight": 6}},
+		{ID: "capped", Attributes: map[string]string{"weight": "1000"}},
+	}
+	total := 1 + 3 + 6 + MaxAuthWeight
+
+	counts := map[string]int{}
+	for i := 0; i < total*10; i++ {
+		got, err := selector.Pick(context.Background(), "kiro", "m", cliproxyexecutor.Options{}, auths)
+		if err != nil {
+			t.Fatalf("Pick() #%d error = %v", i, err)
+		}
+		counts[got.ID]++
+	}
+	want := map[string]int{"light": 10, "medium": 30, "heavy": 60, "capped": MaxAuthWeight * 10}
+	for id, n := range want {
+		if counts[id] != n {
+			t.Fatalf("counts = %v, want %v", counts, want)
+		}
+	}
+}
@@ -5,13 +5,17 @@ package cliproxy
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
+	"time"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/api"
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
 	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	log "github.com/sirupsen/logrus"
 )
 
 // Builder constructs a Service instance with customizable providers.
@@ -203,15 +207,22 @@ func (b *Builder) Build() (*Service, error) {
 		if b.cfg != nil {
 			strategy = strings.ToLower(strings.TrimSpace(b.cfg.Routing.Strategy))
 		}
+		newSticky := func() coreauth.Selector { return newStickySelector(b.cfg) }
 		var selector coreauth.Selector
 		selectorFactories := map[string]func() coreauth.Selector{
-			"sticky":         func() coreauth.Selector { return &coreauth.StickySelector{} },
-			"sticky-session": func() coreauth.Selector { return &coreauth.StickySelector{} },
-			"stickysession":  func() coreauth.Selector { return &coreauth.StickySelector{} },
-			"ss":             func() coreauth.Selector { return &coreauth.StickySelector{} },
-			"fill-first":     func() coreauth.Selector { return &coreauth.FillFirstSelector{} },
-			"fillfirst":      func() coreauth.Selector { return &coreauth.FillFirstSelector{} },
-			"ff":             func() coreauth.Selector { return &coreauth.FillFirstSelector{} },
+			"sticky":               newSticky,
+			"sticky-session":       newSticky,
+			"stickysession":        newSticky,
+			"ss":                   newSticky,
+			"fill-first":           func() coreauth.Selector { return &coreauth.FillFirstSelector{} },
+			"fillfirst":            func() coreauth.Selector { return &coreauth.FillFirstSelector{} },
+			"ff":                   func() coreauth.Selector { return &coreauth.FillFirstSelector{} },
+			"weighted":             func() coreauth.Selector { return &coreauth.WeightedSelector{} },
+			"weighted-round-robin": func() coreauth.Selector { return &coreauth.WeightedSelector{} },
+			"wrr":                  func() coreauth.Selector { return &coreauth.WeightedSelector{} },
+			"latency":              func() coreauth.Selector { return &coreauth.LatencySelector{} },
+			"latency-aware":        func() coreauth.Selector { return &coreauth.LatencySelector{} },
+			"fastest":              func() coreauth.Selector { return &coreauth.LatencySelector{} },
 		}
 		if factory, ok := selectorFactories[strategy]; ok {
 			selector = factory()
@@ -224,6 +235,13 @@ func (b *Builder) Build() (*Service, error) {
 	// Attach a default RoundTripper provider so providers can opt-in per-auth transports.
 	coreManager.SetRoundTripperProvider(newDefaultRoundTripperProvider())
 	coreManager.SetOAuthModelMappings(b.cfg.OAuthModelMappings)
+	coreManager.SetConfigModelAliases(b.cfg)
+	coreManager.SetLatencySLO(b.cfg.LatencySLO)
+	coreManager.SetAuthDailyCap(b.cfg.AuthDailyCap)
+	coreManager.SetCircuitBreaker(b.cfg.CircuitBreaker)
+	coreManager.SetAccountRisk(b.cfg.AccountRisk)
+	coreManager.SetKiroRefresh(b.cfg.KiroRefresh)
+	coreusage.RegisterPlugin(coreManager)
 
 	service := &Service{
 		cfg:            b.cfg,
@@ -239,3 +257,56 @@ func (b *Builder) Build() (*Service, error) {
 	}
 	return service, nil
 }
+
+// newStickySelector builds the sticky selector from the routing config, skipping unknown
+// session key sources with a warning.
+func newStickySelector(cfg *config.Config) *coreauth.StickySelector {
+	selector := &coreauth.StickySelector{}
+	if cfg == nil {
+		return selector
+	}
+	routing := cfg.Routing
+	selector.PerModel = routing.StickyPerModel
+	selector.SessionHeader = routing.StickySessionHeader
+	selector.TTL = time.Duration(routing.StickyTTLSeconds) * time.Second
+	selector.KeySources = stickyKeySources(routing.StickySessionKeys, "sticky-session-keys")
+	for provider, override := range routing.StickyProviders {
+		provider = strings.ToLower(strings.TrimSpace(provider))
+		if provider == "" {
+			continue
+		}
+		if selector.Providers == nil {
+			selector.Providers = make(map[string]coreauth.StickyOptions)
+		}
+		selector.Providers[provider] = coreauth.StickyOptions{
+			TTL:        time.Duration(override.TTLSeconds) * time.Second,
+			KeySources: stickyKeySources(override.SessionKeys, "sticky-providers."+provider+".session-keys"),
+		}
+	}
+	return selector
+}
+
+// stickyRoutingChanged reports whether the sticky selector settings differ between two configs.
+func stickyRoutingChanged(previous, next *config.Config) bool {
+	if previous == nil || next == nil {
+		return previous != next
+	}
+	a, b := previous.Routing, next.Routing
+	return a.StickyPerModel != b.StickyPerModel ||
+		a.StickySessionHeader != b.StickySessionHeader ||
+		a.StickyTTLSeconds != b.StickyTTLSeconds ||
+		!reflect.DeepEqual(a.StickySessionKeys, b.StickySessionKeys) ||
+		!reflect.DeepEqual(a.StickyProviders, b.StickyProviders)
+}
+
+func stickyKeySources(sources []string, field string) []string {
+	var valid []string
+	for _, source := range sources {
+		if !coreauth.ValidStickyKeySource(source) {
+			log.Warnf("routing: ignoring unknown %s entry %q", field, source)
+			continue
+		}
+		valid = append(valid, strings.TrimSpace(source))
+	}
+	return valid
+}
@@ -0,0 +1,334 @@
+// Package conformance is a test kit for provider executors. A provider runs it from its own tests
+// to certify that the executor integrates with the auth manager's selection and quota cooldowns
+// and with the usage pipeline: it streams, stops on cancellation, reports usage and maps upstream
+// errors to status codes the manager understands.
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+)
+
+// waitTimeout bounds how long a behavior waits for a stream to close or a usage record to arrive.
+const waitTimeout = 5 * time.Second
+
+// Scenario describes how the upstream of one check behaves.
+type Scenario struct {
+	// Status is the HTTP status the upstream answers with; 0 means a successful response.
+	Status int
+	// RetryAfter is sent as a Retry-After header on error responses when positive.
+	RetryAfter time.Duration
+	// Hang makes a streaming upstream send its first event and then block until the request is
+	// canceled.
+	Hang bool
+}
+
+// Provider describes the executor under test.
+type Provider struct {
+	// Setup returns an executor and an auth whose upstream behaves as scenario describes. It is
+	// called once per check; NewUpstream serves most HTTP providers.
+	Setup func(t *testing.T, scenario Scenario) (coreauth.ProviderExecutor, *coreauth.Auth)
+	// Model is the model requested in every check.
+	Model string
+	// Payload is the request body in SourceFormat.
+	Payload []byte
+	// SourceFormat is the inbound schema of Payload.
+	SourceFormat sdktranslator.Format
+	// ReportsTokens requires successful requests to report token counts, not only the request.
+	ReportsTokens bool
+}
+
+// Behavior is one required behavior of a provider executor.
+type Behavior struct {
+	// Name identifies the behavior in test output.
+	Name string
+	// Description states what the executor must do.
+	Description string
+	run         func(t *testing.T, p Provider)
+}
+
+// Behaviors lists the behaviors Run checks, in order.
+var Behaviors = []Behavior{
+	{
+		Name:        "execute",
+		Description: "Execute returns the upstream response and publishes one successful usage record for the auth",
+		run:         checkExecute,
+	},
+	{
+		Name:        "stream",
+		Description: "ExecuteStream emits chunks, closes the channel and publishes one successful usage record",
+		run:         checkStream,
+	},
+	{
+		Name:        "cancellation",
+		Description: "Canceling the context of a stream closes its channel while the consumer drains it",
+		run:         checkCancellation,
+	},
+	{
+		Name:        "error-taxonomy",
+		Description: "Upstream 401, 403, 429, 500 and 503 responses surface as StatusError with the same code, 429 carries its Retry-After, and a failed usage record is published",
+		run:         checkErrorTaxonomy,
+	},
+	{
+		Name:        "manager-cooldown",
+		Description: "Through the auth manager, a 429 puts the model of the auth in cooldown until its Retry-After",
+		run:         checkManagerCooldown,
+	},
+}
+
+// Run checks every behavior of Behaviors against the provider.
+func Run(t *testing.T, p Provider) {
+	t.Helper()
+	if p.Setup == nil {
+		t.Fatal("conformance: Provider.Setup is required")
+	}
+	if p.Model == "" {
+		t.Fatal("conformance: Provider.Model is required")
+	}
+	collector()
+	for _, behavior := range Behaviors {
+		t.Run(behavior.Name, func(t *testing.T) {
+			behavior.run(t, p)
+		})
+	}
+}
+
+var authSeq atomic.Int64
+
+// setup builds the executor of scenario and gives its auth a unique ID, so usage records and
+// manager state of different checks cannot be confused.
+func (p Provider) setup(t *testing.T, scenario Scenario) (coreauth.ProviderExecutor, *coreauth.Auth) {
+	t.Helper()
+	exec, auth := p.Setup(t, scenario)
+	if exec == nil || auth == nil {
+		t.Fatal("conformance: Setup returned no executor or auth")
+	}
+	auth = auth.Clone()
+	auth.ID = fmt.Sprintf("conformance-%s-%d", exec.Identifier(), authSeq.Add(1))
+	if auth.Provider == "" {
+		auth.Provider = exec.Identifier()
+	}
+	return exec, auth
+}
+
+func (p Provider) request(stream bool) (cliproxyexecutor.Request, cliproxyexecutor.Options) {
+	req := cliproxyexecutor.Request{Model: p.Model, Payload: p.Payload, Format: p.SourceFormat}
+	opts := cliproxyexecutor.Options{Stream: stream, OriginalRequest: p.Payload, SourceFormat: p.SourceFormat}
+	return req, opts
+}
+
+func checkExecute(t *testing.T, p Provider) {
+	exec, auth := p.setup(t, Scenario{})
+	req, opts := p.request(false)
+	resp, err := exec.Execute(context.Background(), auth, req, opts)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if len(resp.Payload) == 0 {
+		t.Fatal("Execute returned an empty payload")
+	}
+	p.expectUsage(t, exec, auth, false)
+}
+
+func checkStream(t *testing.T, p Provider) {
+	exec, auth := p.setup(t, Scenario{})
+	req, opts := p.request(true)
+	stream, err := exec.ExecuteStream(context.Background(), auth, req, opts)
+	if err != nil {
+		t.Fatalf("ExecuteStream: %v", err)
+	}
+	chunks, errStream := drain(t, stream)
+	if errStream != nil {
+		t.Fatalf("stream failed: %v", errStream)
+	}
+	if chunks == 0 {
+		t.Fatal("stream emitted no chunks")
+	}
+	p.expectUsage(t, exec, auth, false)
+}
+
+func checkCancellation(t *testing.T, p Provider) {
+	exec, auth := p.setup(t, Scenario{Hang: true})
+	req, opts := p.request(true)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := exec.ExecuteStream(ctx, auth, req, opts)
+	if err != nil {
+		t.Fatalf("ExecuteStream: %v", err)
+	}
+	cancel()
+	// The error, if any, is the cancellation itself; only the channel closing matters.
+	_, _ = drain(t, stream)
+}
+
+func checkErrorTaxonomy(t *testing.T, p Provider) {
+	for _, status := range []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable} {
+		t.Run(fmt.Sprint(status), func(t *testing.T) {
+			scenario := Scenario{Status: status}
+			if status == http.StatusTooManyRequests {
+				scenario.RetryAfter = 30 * time.Second
+			}
+			exec, auth := p.setup(t, scenario)
+			req, opts := p.request(false)
+			_, err := exec.Execute(context.Background(), auth, req, opts)
+			expectStatus(t, "Execute", err, scenario)
+			p.expectUsage(t, exec, auth, true)
+
+			exec, auth = p.setup(t, scenario)
+			req, opts = p.request(true)
+			stream, err := exec.ExecuteStream(context.Background(), auth, req, opts)
+			if err == nil {
+				_, err = drain(t, stream)
+			}
+			expectStatus(t, "ExecuteStream", err, scenario)
+		})
+	}
+}
+
+func checkManagerCooldown(t *testing.T, p Provider) {
+	scenario := Scenario{Status: http.StatusTooManyRequests, RetryAfter: 30 * time.Second}
+	exec, auth := p.setup(t, scenario)
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(exec)
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("register auth: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: p.Model}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+
+	req, opts := p.request(false)
+	if _, err := manager.Execute(context.Background(), []string{auth.Provider}, req, opts); err == nil {
+		t.Fatal("manager Execute succeeded against a rate limited upstream")
+	}
+	updated, ok := manager.GetByID(auth.ID)
+	if !ok {
+		t.Fatal("auth missing from the manager")
+	}
+	state := updated.ModelStates[p.Model]
+	if state == nil || !state.Unavailable {
+		t.Fatalf("model %s of the auth must be in cooldown after a 429, state=%+v", p.Model, state)
+	}
+	if until := time.Until(state.NextRetryAfter); until < 20*time.Second || until > 31*time.Second {
+		t.Fatalf("cooldown ends in %s, want the upstream Retry-After of %s", until, scenario.RetryAfter)
+	}
+}
+
+// drain reads stream until it closes and returns the number of payload chunks and the first
+// error chunk.
+func drain(t *testing.T, stream <-chan cliproxyexecutor.StreamChunk) (int, error) {
+	t.Helper()
+	if stream == nil {
+		t.Fatal("ExecuteStream returned a nil channel without an error")
+	}
+	timeout := time.After(waitTimeout)
+	chunks := 0
+	var firstErr error
+	for {
+		select {
+		case chunk, ok := <-stream:
+			if !ok {
+				return chunks, firstErr
+			}
+			if chunk.Err != nil && firstErr == nil {
+				firstErr = chunk.Err
+			}
+			if len(chunk.Payload) > 0 {
+				chunks++
+			}
+		case <-timeout:
+			t.Fatalf("stream did not close within %s", waitTimeout)
+		}
+	}
+}
+
+func expectStatus(t *testing.T, call string, err error, scenario Scenario) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("%s succeeded against an upstream answering %d", call, scenario.Status)
+	}
+	var se cliproxyexecutor.StatusError
+	if !errors.As(err, &se) || se.StatusCode() != scenario.Status {
+		t.Fatalf("%s error %T (%v) must be a StatusError with code %d", call, err, err, scenario.Status)
+	}
+	if scenario.RetryAfter <= 0 {
+		return
+	}
+	var ra interface{ RetryAfter() *time.Duration }
+	if !errors.As(err, &ra) || ra.RetryAfter() == nil {
+		t.Fatalf("%s error must carry the upstream Retry-After", call)
+	}
+	if got := *ra.RetryAfter(); got < scenario.RetryAfter-time.Second || got > scenario.RetryAfter {
+		t.Fatalf("%s Retry-After = %s, want %s", call, got, scenario.RetryAfter)
+	}
+}
+
+// expectUsage waits for the usage record of auth and checks it.
+func (p Provider) expectUsage(t *testing.T, exec coreauth.ProviderExecutor, auth *coreauth.Auth, failed bool) {
+	t.Helper()
+	record, ok := collector().wait(auth.ID)
+	if !ok {
+		t.Fatalf("no usage record published for auth %s", auth.ID)
+	}
+	if record.Provider != exec.Identifier() || record.Model != p.Model {
+		t.Fatalf("usage record provider/model = %s/%s, want %s/%s", record.Provider, record.Model, exec.Identifier(), p.Model)
+	}
+	if record.Failed != failed {
+		t.Fatalf("usage record failed = %v, want %v", record.Failed, failed)
+	}
+	if !failed && p.ReportsTokens && record.Detail.TotalTokens == 0 {
+		t.Fatal("usage record of a successful request must report token counts")
+	}
+}
+
+// usageCollector records the usage published through the default usage manager.
+type usageCollector struct {
+	mu      sync.Mutex
+	records map[string]usage.Record
+}
+
+var (
+	collectorOnce sync.Once
+	sharedUsage   *usageCollector
+)
+
+func collector() *usageCollector {
+	collectorOnce.Do(func() {
+		sharedUsage = &usageCollector{records: map[string]usage.Record{}}
+		usage.RegisterPlugin(sharedUsage)
+	})
+	return sharedUsage
+}
+
+// HandleUsage implements usage.Plugin.
+func (c *usageCollector) HandleUsage(_ context.Context, record usage.Record) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, seen := c.records[record.AuthID]; !seen {
+		c.records[record.AuthID] = record
+	}
+}
+
+func (c *usageCollector) wait(authID string) (usage.Record, bool) {
+	deadline := time.Now().Add(waitTimeout)
+	for {
+		c.mu.Lock()
+		record, ok := c.records[authID]
+		c.mu.Unlock()
+		if ok || time.Now().After(deadline) {
+			return record, ok
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
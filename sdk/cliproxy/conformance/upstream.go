@@ -0,0 +1,59 @@
+package conformance
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+// Responses are the successful upstream answers of a provider.
+type Responses struct {
+	// JSON is the body of a non-streaming response.
+	JSON []byte
+	// Events are the data payloads of a streaming response, each sent as one SSE event.
+	Events []string
+}
+
+// NewUpstream starts an HTTP server answering every request as scenario describes, with the
+// successful bodies taken from responses. A request is answered as a stream when it accepts
+// text/event-stream or its JSON body sets "stream": true. The server is closed when the test ends.
+func NewUpstream(t *testing.T, scenario Scenario, responses Responses) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if scenario.Status != 0 && scenario.Status != http.StatusOK {
+			if scenario.RetryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(scenario.RetryAfter.Seconds())))
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(scenario.Status)
+			_, _ = w.Write([]byte(`{"error":{"message":"conformance upstream error","code":` + strconv.Itoa(scenario.Status) + `}}`))
+			return
+		}
+		if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") && !gjson.GetBytes(body, "stream").Bool() {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(responses.JSON)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher, _ := w.(http.Flusher)
+		for i, event := range responses.Events {
+			_, _ = io.WriteString(w, "data: "+event+"\n\n")
+			if flusher != nil {
+				flusher.Flush()
+			}
+			if scenario.Hang && i == 0 {
+				<-r.Context().Done()
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
@@ -60,3 +60,16 @@ type StatusError interface {
 	error
 	StatusCode() int
 }
+
+// OperationMetadataKey names the Options.Metadata entry that selects a non-generation operation.
+// Requests without it are generation requests in the source format.
+const OperationMetadataKey = "operation"
+
+// OperationEmbeddings marks an OpenAI-format embeddings request.
+const OperationEmbeddings = "embeddings"
+
+// IsEmbeddings reports whether opts describe an embeddings request.
+func (o Options) IsEmbeddings() bool {
+	operation, _ := o.Metadata[OperationMetadataKey].(string)
+	return operation == OperationEmbeddings
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/runtime/executor"
 	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/watcher"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/wsrelay"
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
@@ -86,6 +87,11 @@ type Service struct {
 
 	// wsGateway manages websocket Gemini providers.
 	wsGateway *wsrelay.Manager
+
+	// boundExecutors records the config each provider executor was registered with, so
+	// executors are registered once per provider instead of once per auth.
+	boundExecutors map[string]*config.Config
+	executorMu     sync.Mutex
 }
 
 // RegisterUsagePlugin registers a usage plugin on the global usage manager.
@@ -281,7 +287,10 @@ func (s *Service) applyCoreAuthAddOrUpdate(ctx context.Context, auth *coreauth.A
 	if existing, ok := s.coreManager.GetByID(auth.ID); ok && existing != nil {
 		auth.CreatedAt = existing.CreatedAt
 		auth.LastRefreshedAt = existing.LastRefreshedAt
-		auth.NextRefreshAfter = existing.NextRefreshAfter
+		// A quarantined credential replaced by a new login must not inherit its re-check delay.
+		if !existing.NeedsReauth() {
+			auth.NextRefreshAfter = existing.NextRefreshAfter
+		}
 		if _, err := s.coreManager.Update(ctx, auth); err != nil {
 			log.Errorf("failed to update auth %s: %v", auth.ID, err)
 		}
@@ -300,6 +309,7 @@ func (s *Service) applyCoreAuthRemoval(ctx context.Context, id string) {
 		return
 	}
 	GlobalModelRegistry().UnregisterClient(id)
+	executor.ForgetGitHubCopilotModels(id)
 	if existing, ok := s.coreManager.GetByID(id); ok && existing != nil {
 		existing.Disabled = true
 		existing.Status = coreauth.StatusDisabled
@@ -317,6 +327,33 @@ func (s *Service) applyRetryConfig(cfg *config.Config) {
 	s.coreManager.SetRetryConfig(cfg.RequestRetry, maxInterval)
 }
 
+// applyLocalFallback installs the local fallback provider described by cfg, or removes it.
+func (s *Service) applyLocalFallback(cfg *config.Config) {
+	if s == nil || s.coreManager == nil || cfg == nil {
+		return
+	}
+	baseURL := strings.TrimSpace(cfg.LocalFallback.BaseURL)
+	if baseURL == "" {
+		s.coreManager.SetLocalFallback(nil)
+		return
+	}
+	auth := &coreauth.Auth{
+		ID:       "local-fallback",
+		Provider: "local-fallback",
+		Label:    "local-fallback",
+		Status:   coreauth.StatusActive,
+		Attributes: map[string]string{
+			"base_url": baseURL,
+			"api_key":  strings.TrimSpace(cfg.LocalFallback.APIKey),
+		},
+	}
+	s.coreManager.SetLocalFallback(&coreauth.LocalFallback{
+		Executor: executor.NewOpenAICompatExecutor("local-fallback", cfg),
+		Auth:     auth,
+		Model:    strings.TrimSpace(cfg.LocalFallback.Model),
+	})
+}
+
 func openAICompatInfoFromAuth(a *coreauth.Auth) (providerKey string, compatName string, ok bool) {
 	if a == nil {
 		return "", "", false
@@ -347,6 +384,7 @@ func (s *Service) ensureExecutorsForAuth(a *coreauth.Auth) {
 	if a.Disabled {
 		return
 	}
+	cfg := s.cfg
 	if compatProviderKey, _, isCompat := openAICompatInfoFromAuth(a); isCompat {
 		if compatProviderKey == "" {
 			compatProviderKey = strings.ToLower(strings.TrimSpace(a.Provider))
@@ -354,42 +392,62 @@ func (s *Service) ensureExecutorsForAuth(a *coreauth.Auth) {
 		if compatProviderKey == "" {
 			compatProviderKey = "openai-compatibility"
 		}
-		s.coreManager.RegisterExecutor(executor.NewOpenAICompatExecutor(compatProviderKey, s.cfg))
+		s.bindExecutor(compatProviderKey, cfg, func() coreauth.ProviderExecutor {
+			return executor.NewOpenAICompatExecutor(compatProviderKey, cfg)
+		})
 		return
 	}
 	switch strings.ToLower(a.Provider) {
 	case "gemini":
-		s.coreManager.RegisterExecutor(executor.NewGeminiExecutor(s.cfg))
+		s.bindExecutor("gemini", cfg, func() coreauth.ProviderExecutor { return executor.NewGeminiExecutor(cfg) })
 	case "vertex":
-		s.coreManager.RegisterExecutor(executor.NewGeminiVertexExecutor(s.cfg))
+		s.bindExecutor("vertex", cfg, func() coreauth.ProviderExecutor { return executor.NewGeminiVertexExecutor(cfg) })
 	case "gemini-cli":
-		s.coreManager.RegisterExecutor(executor.NewGeminiCLIExecutor(s.cfg))
+		s.bindExecutor("gemini-cli", cfg, func() coreauth.ProviderExecutor { return executor.NewGeminiCLIExecutor(cfg) })
 	case "aistudio":
 		if s.wsGateway != nil {
-			s.coreManager.RegisterExecutor(executor.NewAIStudioExecutor(s.cfg, a.ID, s.wsGateway))
+			gateway, authID := s.wsGateway, a.ID
+			s.bindExecutor("aistudio", cfg, func() coreauth.ProviderExecutor { return executor.NewAIStudioExecutor(cfg, authID, gateway) })
 		}
 		return
 	case "antigravity":
-		s.coreManager.RegisterExecutor(executor.NewAntigravityExecutor(s.cfg))
+		s.bindExecutor("antigravity", cfg, func() coreauth.ProviderExecutor { return executor.NewAntigravityExecutor(cfg) })
 	case "claude":
-		s.coreManager.RegisterExecutor(executor.NewClaudeExecutor(s.cfg))
+		s.bindExecutor("claude", cfg, func() coreauth.ProviderExecutor { return executor.NewClaudeExecutor(cfg) })
 	case "codex":
-		s.coreManager.RegisterExecutor(executor.NewCodexExecutor(s.cfg))
+		s.bindExecutor("codex", cfg, func() coreauth.ProviderExecutor { return executor.NewCodexExecutor(cfg) })
 	case "qwen":
-		s.coreManager.RegisterExecutor(executor.NewQwenExecutor(s.cfg))
+		s.bindExecutor("qwen", cfg, func() coreauth.ProviderExecutor { return executor.NewQwenExecutor(cfg) })
 	case "iflow":
-		s.coreManager.RegisterExecutor(executor.NewIFlowExecutor(s.cfg))
+		s.bindExecutor("iflow", cfg, func() coreauth.ProviderExecutor { return executor.NewIFlowExecutor(cfg) })
 	case "kiro":
-		s.coreManager.RegisterExecutor(executor.NewKiroExecutor(s.cfg))
+		s.bindExecutor("kiro", cfg, func() coreauth.ProviderExecutor { return executor.NewKiroExecutor(cfg) })
 	case "github-copilot":
-		s.coreManager.RegisterExecutor(executor.NewGitHubCopilotExecutor(s.cfg))
+		s.bindExecutor("github-copilot", cfg, func() coreauth.ProviderExecutor { return executor.NewGitHubCopilotExecutor(cfg) })
 	default:
 		providerKey := strings.ToLower(strings.TrimSpace(a.Provider))
 		if providerKey == "" {
 			providerKey = "openai-compatibility"
 		}
-		s.coreManager.RegisterExecutor(executor.NewOpenAICompatExecutor(providerKey, s.cfg))
+		s.bindExecutor(providerKey, cfg, func() coreauth.ProviderExecutor {
+			return executor.NewOpenAICompatExecutor(providerKey, cfg)
+		})
+	}
+}
+
+// bindExecutor registers a lazily built executor for provider unless one was already
+// registered with the same config. Construction happens on the provider's first request.
+func (s *Service) bindExecutor(provider string, cfg *config.Config, build func() coreauth.ProviderExecutor) {
+	s.executorMu.Lock()
+	defer s.executorMu.Unlock()
+	if bound, ok := s.boundExecutors[provider]; ok && bound == cfg {
+		return
 	}
+	if s.boundExecutors == nil {
+		s.boundExecutors = make(map[string]*config.Config)
+	}
+	s.boundExecutors[provider] = cfg
+	s.coreManager.RegisterExecutor(coreauth.NewLazyExecutor(provider, build))
 }
 
 // rebindExecutors refreshes provider executors so they observe the latest configuration.
@@ -420,6 +478,7 @@ func (s *Service) Run(ctx context.Context) error {
 		ctx = context.Background()
 	}
 
+	timer := newStartupTimer()
 	usage.StartDefault(ctx)
 
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -435,12 +494,15 @@ func (s *Service) Run(ctx context.Context) error {
 	}
 
 	s.applyRetryConfig(s.cfg)
+	s.applyLocalFallback(s.cfg)
+	executor.SetTokenEstimation(s.cfg.TokenEstimation)
 
 	if s.coreManager != nil {
 		if errLoad := s.coreManager.Load(ctx); errLoad != nil {
 			log.Warnf("failed to load auth store: %v", errLoad)
 		}
 	}
+	timer.mark("auth-store")
 
 	tokenResult, err := s.tokenProvider.Load(ctx, s.cfg)
 	if err != nil && !errors.Is(err, context.Canceled) {
@@ -457,6 +519,7 @@ func (s *Service) Run(ctx context.Context) error {
 	if apiKeyResult == nil {
 		apiKeyResult = &APIKeyClientResult{}
 	}
+	timer.mark("clients")
 
 	// legacy clients removed; no caches to refresh
 
@@ -488,6 +551,8 @@ func (s *Service) Run(ctx context.Context) error {
 		})
 	}
 
+	timer.mark("server-setup")
+
 	if s.hooks.OnBeforeStart != nil {
 		s.hooks.OnBeforeStart(s.cfg)
 	}
@@ -502,18 +567,20 @@ func (s *Service) Run(ctx context.Context) error {
 	}()
 
 	time.Sleep(100 * time.Millisecond)
-	fmt.Printf("API server started successfully on: %s:%d\n", s.cfg.Host, s.cfg.Port)
+	fmt.Printf("API server started successfully on: %s\n", util.JoinHostPort(s.cfg.Host, s.cfg.Port))
 
 	if s.hooks.OnAfterStart != nil {
 		s.hooks.OnAfterStart(s)
 	}
+	timer.mark("listen")
 
 	var watcherWrapper *WatcherWrapper
 	reloadCallback := func(newCfg *config.Config) {
 		previousStrategy := ""
 		s.cfgMu.RLock()
-		if s.cfg != nil {
-			previousStrategy = strings.ToLower(strings.TrimSpace(s.cfg.Routing.Strategy))
+		previousCfg := s.cfg
+		if previousCfg != nil {
+			previousStrategy = strings.ToLower(strings.TrimSpace(previousCfg.Routing.Strategy))
 		}
 		s.cfgMu.RUnlock()
 
@@ -533,19 +600,32 @@ func (s *Service) Run(ctx context.Context) error {
 				return "sticky"
 			case "fill-first", "fillfirst", "ff":
 				return "fill-first"
+			case "weighted", "weighted-round-robin", "wrr":
+				return "weighted"
+			case "latency", "latency-aware", "fastest":
+				return "latency"
 			default:
 				return "round-robin"
 			}
 		}
 		previousStrategy = normalizeStrategy(previousStrategy)
 		nextStrategy = normalizeStrategy(nextStrategy)
-		if s.coreManager != nil && previousStrategy != nextStrategy {
+		stickyOptionsChanged := nextStrategy == "sticky" && stickyRoutingChanged(previousCfg, newCfg)
+		if s.coreManager != nil && (previousStrategy != nextStrategy || stickyOptionsChanged) {
 			var selector coreauth.Selector
 			switch nextStrategy {
 			case "fill-first":
 				selector = &coreauth.FillFirstSelector{}
+			case "weighted":
+				selector = &coreauth.WeightedSelector{}
+			case "latency":
+				selector = &coreauth.LatencySelector{}
 			case "sticky":
-				selector = &coreauth.StickySelector{}
+				sticky := newStickySelector(newCfg)
+				if previous, ok := s.coreManager.Selector().(*coreauth.StickySelector); ok {
+					sticky.TakePins(previous)
+				}
+				selector = sticky
 			default:
 				selector = &coreauth.RoundRobinSelector{}
 			}
@@ -554,6 +634,7 @@ func (s *Service) Run(ctx context.Context) error {
 		}
 
 		s.applyRetryConfig(newCfg)
+		s.applyLocalFallback(newCfg)
 		if s.server != nil {
 			s.server.UpdateClients(newCfg)
 		}
@@ -562,7 +643,14 @@ func (s *Service) Run(ctx context.Context) error {
 		s.cfgMu.Unlock()
 		if s.coreManager != nil {
 			s.coreManager.SetOAuthModelMappings(newCfg.OAuthModelMappings)
-		}
+			s.coreManager.SetConfigModelAliases(newCfg)
+			s.coreManager.SetLatencySLO(newCfg.LatencySLO)
+			s.coreManager.SetAuthDailyCap(newCfg.AuthDailyCap)
+			s.coreManager.SetCircuitBreaker(newCfg.CircuitBreaker)
+			s.coreManager.SetAccountRisk(newCfg.AccountRisk)
+			s.coreManager.SetKiroRefresh(newCfg.KiroRefresh)
+		}
+		executor.SetTokenEstimation(newCfg.TokenEstimation)
 		s.rebindExecutors()
 	}
 
@@ -583,6 +671,7 @@ func (s *Service) Run(ctx context.Context) error {
 		return fmt.Errorf("cliproxy: failed to start watcher: %w", err)
 	}
 	log.Info("file watcher started for config and auth directory changes")
+	timer.mark("auth-sync")
 
 	// Prefer core auth manager auto refresh if available.
 	if s.coreManager != nil {
@@ -590,6 +679,7 @@ func (s *Service) Run(ctx context.Context) error {
 		s.coreManager.StartAutoRefresh(context.Background(), interval)
 		log.Infof("core auth auto-refresh started (interval=%s)", interval)
 	}
+	timer.report()
 
 	select {
 	case <-ctx.Done():
@@ -774,7 +864,16 @@ func (s *Service) registerModelsForAuth(a *coreauth.Auth) {
 	case "iflow":
 		models = registry.GetIFlowModels()
 	case "github-copilot":
-		models = registry.GetGitHubCopilotModels()
+		// Registration never waits for the Copilot API: the cached or static catalog is registered
+		// now and the auth is registered again once a fresh catalog arrives.
+		var fresh bool
+		models, fresh = executor.CachedGitHubCopilotModels(a.ID)
+		if !fresh {
+			s.refreshGitHubCopilotModels(a)
+		}
+		if len(models) == 0 {
+			models = registry.GetGitHubCopilotModels()
+		}
 		models = applyExcludedModels(models, excluded)
 	case "kiro":
 		models = registry.GetKiroModels()
@@ -873,6 +972,36 @@ func (s *Service) registerModelsForAuth(a *coreauth.Auth) {
 	GlobalModelRegistry().UnregisterClient(a.ID)
 }
 
+// refreshGitHubCopilotModels fetches the Copilot model catalog of a in the background with the
+// registered executor, reusing its API token, and registers the auth's models again once the
+// catalog is cached, provided the auth is still registered.
+func (s *Service) refreshGitHubCopilotModels(a *coreauth.Auth) {
+	if s == nil || s.coreManager == nil || a == nil {
+		return
+	}
+	registered, ok := s.coreManager.Executor("github-copilot")
+	if !ok {
+		return
+	}
+	copilot, ok := registered.(*executor.GitHubCopilotExecutor)
+	if !ok {
+		return
+	}
+	auth := a.Clone()
+	authID := auth.ID
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		if err := copilot.RefreshModels(ctx, auth); err != nil {
+			log.Debugf("github-copilot: refresh models for %s: %v", authID, err)
+			return
+		}
+		if current, stillRegistered := s.coreManager.GetByID(authID); stillRegistered && current != nil && !current.Disabled {
+			s.registerModelsForAuth(current)
+		}
+	}()
+}
+
 func (s *Service) resolveConfigClaudeKey(auth *coreauth.Auth) *config.ClaudeKey {
 	if auth == nil || s.cfg == nil {
 		return nil
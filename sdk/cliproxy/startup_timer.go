@@ -0,0 +1,34 @@
+package cliproxy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// startupTimer records how long each startup phase takes so slow starts with large auth
+// directories can be attributed to a phase.
+type startupTimer struct {
+	start  time.Time
+	last   time.Time
+	phases []string
+}
+
+func newStartupTimer() *startupTimer {
+	now := time.Now()
+	return &startupTimer{start: now, last: now}
+}
+
+// mark closes the current phase under name and starts the next one.
+func (t *startupTimer) mark(name string) {
+	now := time.Now()
+	t.phases = append(t.phases, fmt.Sprintf("%s=%s", name, now.Sub(t.last).Round(100*time.Microsecond)))
+	t.last = now
+}
+
+// report logs the total startup time with the per-phase breakdown.
+func (t *startupTimer) report() {
+	log.Infof("startup completed in %s (%s)", time.Since(t.start).Round(100*time.Microsecond), strings.Join(t.phases, ", "))
+}
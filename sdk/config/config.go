@@ -13,6 +13,21 @@ type AccessProvider = internalconfig.AccessProvider
 type Config = internalconfig.Config
 
 type StreamingConfig = internalconfig.StreamingConfig
+type PartialSalvageConfig = internalconfig.PartialSalvageConfig
+type PriorityConfig = internalconfig.PriorityConfig
+type BudgetConfig = internalconfig.BudgetConfig
+type BudgetReservation = internalconfig.BudgetReservation
+type MaintenanceWindow = internalconfig.MaintenanceWindow
+type ModelPolicy = internalconfig.ModelPolicy
+type ModelAccessRules = internalconfig.ModelAccessRules
+type FallbackChain = internalconfig.FallbackChain
+type ReplayQueueConfig = internalconfig.ReplayQueueConfig
+type ConversationCapConfig = internalconfig.ConversationCapConfig
+type LoopDetectionConfig = internalconfig.LoopDetectionConfig
+type ImageDownscaleConfig = internalconfig.ImageDownscaleConfig
+type ImageLimit = internalconfig.ImageLimit
+type CapabilityChecksConfig = internalconfig.CapabilityChecksConfig
+type ProviderFeatures = internalconfig.ProviderFeatures
 type TLSConfig = internalconfig.TLSConfig
 type RemoteManagement = internalconfig.RemoteManagement
 type AmpCode = internalconfig.AmpCode
@@ -38,6 +53,14 @@ const (
 	DefaultPanelGitHubRepository   = internalconfig.DefaultPanelGitHubRepository
 )
 
+// HashAPIKey returns the salted hash form of a client API key for the api-keys list.
+func HashAPIKey(key string) (string, error) { return internalconfig.HashAPIKey(key) }
+
+// VerifyAPIKey reports whether presented matches a plaintext or hashed api-keys entry.
+func VerifyAPIKey(stored, presented string) bool {
+	return internalconfig.VerifyAPIKey(stored, presented)
+}
+
 func MakeInlineAPIKeyProvider(keys []string) *AccessProvider {
 	return internalconfig.MakeInlineAPIKeyProvider(keys)
 }
@@ -0,0 +1,24 @@
+// Command mockupstream serves the integration test mock provider on its own, for running the
+// integration tests against containers (see test/integration/docker-compose.yml).
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/test/integration/mockupstream"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "listen address")
+	models := flag.String("models", "mock-upstream-model", "comma separated model IDs to serve")
+	flag.Parse()
+
+	server := mockupstream.New(strings.Split(*models, ",")...)
+	log.Printf("mock upstream listening on %s", *addr)
+	if err := http.ListenAndServe(*addr, server); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,392 @@
+//go:build integration
+
+// Package integration boots the complete proxy service against a mock OpenAI-compatible provider
+// and drives it over HTTP the way clients do, catching wiring regressions between the ingress
+// handlers, translators, executors, usage accounting and management API that unit tests miss.
+//
+// Run it with:
+//
+//	go test -tags integration ./test/integration/...
+//
+// The mock provider runs in-process unless CLIPROXY_INTEGRATION_UPSTREAM points at one started
+// separately, e.g. by test/integration/docker-compose.yml.
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	configaccess "github.com/router-for-me/CLIProxyAPI/v6/internal/access/config_access"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy"
+	"github.com/router-for-me/CLIProxyAPI/v6/test/integration/mockupstream"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	clientKey     = "integration-client-key"
+	managementKey = "integration-management-key"
+	upstreamKey   = "integration-upstream-key"
+	clientModel   = "mock-model"
+	upstreamModel = "mock-upstream-model"
+)
+
+// harness is a running proxy service wired to the mock provider. Usage statistics and the model
+// registry are process-wide, so a test binary runs a single service shared by all tests.
+type harness struct {
+	baseURL  string
+	upstream string
+	stop     func()
+}
+
+var shared *harness
+
+func TestMain(m *testing.M) {
+	h, err := startHarness()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "integration: %v\n", err)
+		os.Exit(1)
+	}
+	shared = h
+	code := m.Run()
+	h.stop()
+	os.Exit(code)
+}
+
+// startHarness starts the proxy on a free local port.
+func startHarness() (*harness, error) {
+	var cleanups []func()
+	stop := func() {
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cleanups[i]()
+		}
+	}
+	upstream := strings.TrimRight(os.Getenv("CLIPROXY_INTEGRATION_UPSTREAM"), "/")
+	if upstream == "" {
+		mock := httptest.NewServer(mockupstream.New(upstreamModel))
+		cleanups = append(cleanups, mock.Close)
+		upstream = mock.URL
+	}
+
+	port, err := freePort()
+	if err != nil {
+		stop()
+		return nil, fmt.Errorf("reserve port: %w", err)
+	}
+	dir, err := os.MkdirTemp("", "cliproxy-integration-")
+	if err != nil {
+		stop()
+		return nil, err
+	}
+	cleanups = append(cleanups, func() { _ = os.RemoveAll(dir) })
+	configPath := filepath.Join(dir, "config.yaml")
+	configYAML := fmt.Sprintf(`host: 127.0.0.1
+port: %d
+auth-dir: %q
+usage-statistics-enabled: true
+remote-management:
+  secret-key: %s
+api-keys:
+  - %s
+openai-compatibility:
+  - name: mock
+    base-url: %s/v1
+    api-key-entries:
+      - api-key: %s
+    models:
+      - name: %s
+        alias: %s
+`, port, filepath.Join(dir, "auths"), managementKey, clientKey, upstream, upstreamKey, upstreamModel, clientModel)
+	if err = os.WriteFile(configPath, []byte(configYAML), 0o600); err != nil {
+		stop()
+		return nil, fmt.Errorf("write config: %w", err)
+	}
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		stop()
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+
+	configaccess.Register()
+	service, err := cliproxy.NewBuilder().
+		WithConfig(cfg).
+		WithConfigPath(configPath).
+		Build()
+	if err != nil {
+		stop()
+		return nil, fmt.Errorf("build service: %w", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- service.Run(ctx) }()
+	cleanups = append(cleanups, func() {
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(30 * time.Second):
+			fmt.Fprintln(os.Stderr, "integration: service did not stop")
+		}
+	})
+
+	h := &harness{baseURL: fmt.Sprintf("http://127.0.0.1:%d", port), upstream: upstream, stop: stop}
+	if err = h.waitReady(done); err != nil {
+		stop()
+		return nil, err
+	}
+	return h, nil
+}
+
+// waitReady blocks until the service serves the client model.
+func (h *harness) waitReady(done <-chan error) error {
+	deadline := time.Now().Add(15 * time.Second)
+	for time.Now().Before(deadline) {
+		select {
+		case err := <-done:
+			return fmt.Errorf("service stopped during startup: %v", err)
+		default:
+		}
+		req, _ := http.NewRequest(http.MethodGet, h.baseURL+"/v1/models", nil)
+		req.Header.Set("Authorization", "Bearer "+clientKey)
+		if resp, err := http.DefaultClient.Do(req); err == nil {
+			body, _ := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			if resp.StatusCode == http.StatusOK && gjson.GetBytes(body, fmt.Sprintf(`data.#(id==%q)`, clientModel)).Exists() {
+				return nil
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return fmt.Errorf("service did not become ready")
+}
+
+// request sends a request to the proxy and returns the status and body.
+func (h *harness) request(t *testing.T, method, path, body string, headers map[string]string) (int, []byte) {
+	t.Helper()
+	return h.do(t, method, h.baseURL+path, body, headers)
+}
+
+func (h *harness) do(t *testing.T, method, url, body string, headers map[string]string) (int, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, data
+}
+
+// upstreamRequests returns what the mock provider received since the harness started.
+func (h *harness) upstreamRequests(t *testing.T) []mockupstream.Request {
+	t.Helper()
+	status, body := h.do(t, http.MethodGet, h.upstream+mockupstream.RequestsPath, "", nil)
+	if status != http.StatusOK {
+		t.Fatalf("list upstream requests: status %d", status)
+	}
+	var requests []mockupstream.Request
+	if err := json.Unmarshal(body, &requests); err != nil {
+		t.Fatalf("decode upstream requests: %v", err)
+	}
+	return requests
+}
+
+func clientHeaders() map[string]string {
+	return map[string]string{"Authorization": "Bearer " + clientKey}
+}
+
+func managementHeaders() map[string]string {
+	return map[string]string{"Authorization": "Bearer " + managementKey}
+}
+
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = listener.Close() }()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+func TestIntegration(t *testing.T) {
+	h := shared
+	t.Run("ingress formats", func(t *testing.T) { testIngressFormats(t, h) })
+	t.Run("usage and management", func(t *testing.T) { testUsageAndManagement(t, h) })
+}
+
+// testIngressFormats sends one request per client format and checks that each reached the mock
+// provider with the mapped model and came back translated.
+func testIngressFormats(t *testing.T, h *harness) {
+	cases := []struct {
+		name   string
+		path   string
+		body   string
+		stream bool
+		// reply extracts the assistant text from the response.
+		reply func(body []byte) string
+	}{
+		{
+			name:  "openai chat completions",
+			path:  "/v1/chat/completions",
+			body:  fmt.Sprintf(`{"model":%q,"messages":[{"role":"user","content":"ping"}]}`, clientModel),
+			reply: func(body []byte) string { return gjson.GetBytes(body, "choices.0.message.content").String() },
+		},
+		{
+			name:   "openai chat completions stream",
+			path:   "/v1/chat/completions",
+			body:   fmt.Sprintf(`{"model":%q,"stream":true,"messages":[{"role":"user","content":"ping"}]}`, clientModel),
+			stream: true,
+			reply:  sseText("", "choices.0.delta.content"),
+		},
+		{
+			name: "openai responses",
+			path: "/v1/responses",
+			body: fmt.Sprintf(`{"model":%q,"input":"ping"}`, clientModel),
+			reply: func(body []byte) string {
+				return gjson.GetBytes(body, "output.#(type==message).content.0.text").String()
+			},
+		},
+		{
+			name:   "openai responses stream",
+			path:   "/v1/responses",
+			body:   fmt.Sprintf(`{"model":%q,"stream":true,"input":"ping"}`, clientModel),
+			stream: true,
+			reply:  sseText("response.output_text.delta", "delta"),
+		},
+		{
+			name:  "claude messages",
+			path:  "/v1/messages",
+			body:  fmt.Sprintf(`{"model":%q,"max_tokens":64,"messages":[{"role":"user","content":"ping"}]}`, clientModel),
+			reply: func(body []byte) string { return gjson.GetBytes(body, "content.#(type==text).text").String() },
+		},
+		{
+			name:   "claude messages stream",
+			path:   "/v1/messages",
+			body:   fmt.Sprintf(`{"model":%q,"max_tokens":64,"stream":true,"messages":[{"role":"user","content":"ping"}]}`, clientModel),
+			stream: true,
+			reply:  sseText("content_block_delta", "delta.text"),
+		},
+		{
+			name:  "gemini generateContent",
+			path:  "/v1beta/models/" + clientModel + ":generateContent",
+			body:  `{"contents":[{"role":"user","parts":[{"text":"ping"}]}]}`,
+			reply: func(body []byte) string { return gjson.GetBytes(body, "candidates.0.content.parts.0.text").String() },
+		},
+		{
+			name:   "gemini streamGenerateContent",
+			path:   "/v1beta/models/" + clientModel + ":streamGenerateContent?alt=sse",
+			body:   `{"contents":[{"role":"user","parts":[{"text":"ping"}]}]}`,
+			stream: true,
+			reply:  sseText("", "candidates.0.content.parts.0.text"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			before := len(h.upstreamRequests(t))
+			status, body := h.request(t, http.MethodPost, tc.path, tc.body, clientHeaders())
+			if status != http.StatusOK {
+				t.Fatalf("status %d, body %s", status, body)
+			}
+			if got := tc.reply(body); strings.TrimSpace(got) != mockupstream.Reply {
+				t.Fatalf("reply %q, want %q; body %s", got, mockupstream.Reply, body)
+			}
+			requests := h.upstreamRequests(t)
+			if len(requests) != before+1 {
+				t.Fatalf("upstream received %d requests, want 1", len(requests)-before)
+			}
+			got := requests[len(requests)-1]
+			if got.Model != upstreamModel || got.Stream != tc.stream || got.Authorization != "Bearer "+upstreamKey {
+				t.Fatalf("upstream request %+v, want model %s, stream %t and the provider key", got, upstreamModel, tc.stream)
+			}
+		})
+	}
+}
+
+// testUsageAndManagement checks authentication, the management API and usage accounting.
+func testUsageAndManagement(t *testing.T, h *harness) {
+	if status, _ := h.request(t, http.MethodGet, "/v0/management/usage", "", nil); status != http.StatusUnauthorized {
+		t.Fatalf("management without a key: status %d, want 401", status)
+	}
+	if status, _ := h.request(t, http.MethodPost, "/v1/chat/completions", `{"model":"mock-model","messages":[]}`, nil); status != http.StatusUnauthorized {
+		t.Fatalf("client request without a key: status %d, want 401", status)
+	}
+
+	status, body := h.request(t, http.MethodGet, "/v0/management/openai-compatibility", "", managementHeaders())
+	if status != http.StatusOK || gjson.GetBytes(body, `openai-compatibility.#(name=="mock").models.0.alias`).String() != clientModel {
+		t.Fatalf("openai-compatibility: status %d, body %s", status, body)
+	}
+
+	_, before := h.request(t, http.MethodGet, "/v0/management/usage", "", managementHeaders())
+	const requests = 3
+	for i := 0; i < requests; i++ {
+		payload := fmt.Sprintf(`{"model":%q,"messages":[{"role":"user","content":"ping %d"}]}`, clientModel, i)
+		if status, body := h.request(t, http.MethodPost, "/v1/chat/completions", payload, clientHeaders()); status != http.StatusOK {
+			t.Fatalf("chat completion %d: status %d, body %s", i, status, body)
+		}
+	}
+
+	// Usage records are aggregated asynchronously.
+	wantRequests := gjson.GetBytes(before, "usage.total_requests").Int() + requests
+	wantTokens := gjson.GetBytes(before, "usage.total_tokens").Int() + requests*(mockupstream.PromptTokens+mockupstream.CompletionTokens)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		status, body = h.request(t, http.MethodGet, "/v0/management/usage", "", managementHeaders())
+		if status != http.StatusOK {
+			t.Fatalf("usage: status %d, body %s", status, body)
+		}
+		gotRequests := gjson.GetBytes(body, "usage.total_requests").Int()
+		gotTokens := gjson.GetBytes(body, "usage.total_tokens").Int()
+		if gotRequests == wantRequests && gotTokens == wantTokens {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("usage reports %d requests and %d tokens, want %d and %d", gotRequests, gotTokens, wantRequests, wantTokens)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if !bytes.Contains(body, []byte(clientModel)) {
+		t.Fatalf("usage does not break down requests by model: %s", body)
+	}
+}
+
+// sseText concatenates the values at path of the data events of an SSE body, keeping only events
+// whose type is eventType when it is set.
+func sseText(eventType, path string) func([]byte) string {
+	return func(body []byte) string {
+		var text strings.Builder
+		for _, line := range strings.Split(string(body), "\n") {
+			data, ok := strings.CutPrefix(strings.TrimSpace(line), "data:")
+			if !ok {
+				continue
+			}
+			data = strings.TrimSpace(data)
+			if eventType != "" && gjson.Get(data, "type").String() != eventType {
+				continue
+			}
+			text.WriteString(gjson.Get(data, path).String())
+		}
+		return text.String()
+	}
+}
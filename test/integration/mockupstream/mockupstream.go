@@ -0,0 +1,152 @@
+// Package mockupstream implements a deterministic OpenAI-compatible provider for integration
+// tests. It answers chat completions with a fixed reply and usage, and records every request it
+// receives so tests can assert how the proxy routed them.
+//
+// Recorded requests are served at GET /_mock/requests and cleared with DELETE /_mock/requests, so
+// a test can inspect a mock running in another process or container the same way it inspects an
+// in-process one.
+package mockupstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// Reply is the assistant text of every completion.
+	Reply = "pong from mock upstream"
+	// PromptTokens and CompletionTokens are the usage reported for every completion.
+	PromptTokens     = 7
+	CompletionTokens = 5
+	// RequestsPath lists and clears the recorded requests.
+	RequestsPath = "/_mock/requests"
+)
+
+// Request is one request received by the mock.
+type Request struct {
+	Path          string `json:"path"`
+	Model         string `json:"model"`
+	Stream        bool   `json:"stream"`
+	Authorization string `json:"authorization"`
+}
+
+// Server is an http.Handler serving the mock provider.
+type Server struct {
+	// Models lists the model IDs served by GET /v1/models.
+	Models []string
+
+	mu       sync.Mutex
+	requests []Request
+}
+
+// New creates a mock serving models.
+func New(models ...string) *Server {
+	return &Server{Models: models}
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Reset forgets the recorded requests.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	s.requests = nil
+	s.mu.Unlock()
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == RequestsPath && r.Method == http.MethodGet:
+		writeJSON(w, s.Requests())
+	case r.URL.Path == RequestsPath && r.Method == http.MethodDelete:
+		s.Reset()
+		w.WriteHeader(http.StatusNoContent)
+	case strings.HasSuffix(r.URL.Path, "/models") && r.Method == http.MethodGet:
+		data := make([]map[string]any, 0, len(s.Models))
+		for _, model := range s.Models {
+			data = append(data, map[string]any{"id": model, "object": "model", "owned_by": "mock"})
+		}
+		writeJSON(w, map[string]any{"object": "list", "data": data})
+	case strings.HasSuffix(r.URL.Path, "/chat/completions") && r.Method == http.MethodPost:
+		s.chatCompletions(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) chatCompletions(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil || !gjson.ValidBytes(body) {
+		http.Error(w, `{"error":{"message":"invalid request body"}}`, http.StatusBadRequest)
+		return
+	}
+	req := Request{
+		Path:          r.URL.Path,
+		Model:         gjson.GetBytes(body, "model").String(),
+		Stream:        gjson.GetBytes(body, "stream").Bool(),
+		Authorization: r.Header.Get("Authorization"),
+	}
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	s.mu.Unlock()
+
+	usage := map[string]any{
+		"prompt_tokens":     PromptTokens,
+		"completion_tokens": CompletionTokens,
+		"total_tokens":      PromptTokens + CompletionTokens,
+	}
+	if !req.Stream {
+		writeJSON(w, map[string]any{
+			"id":      "chatcmpl-mock",
+			"object":  "chat.completion",
+			"created": 1700000000,
+			"model":   req.Model,
+			"choices": []map[string]any{{
+				"index":         0,
+				"message":       map[string]any{"role": "assistant", "content": Reply},
+				"finish_reason": "stop",
+			}},
+			"usage": usage,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	chunk := func(delta map[string]any, finish any, usage any) {
+		payload, _ := json.Marshal(map[string]any{
+			"id":      "chatcmpl-mock",
+			"object":  "chat.completion.chunk",
+			"created": 1700000000,
+			"model":   req.Model,
+			"choices": []map[string]any{{"index": 0, "delta": delta, "finish_reason": finish}},
+			"usage":   usage,
+		})
+		_, _ = fmt.Fprintf(w, "data: %s\n\n", payload)
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+	}
+	chunk(map[string]any{"role": "assistant", "content": ""}, nil, nil)
+	for _, word := range strings.SplitAfter(Reply, " ") {
+		chunk(map[string]any{"content": word}, nil, nil)
+	}
+	chunk(map[string]any{}, "stop", usage)
+	_, _ = fmt.Fprint(w, "data: [DONE]\n\n")
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}